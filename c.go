@@ -1,6 +1,10 @@
 package colfer
 
 import (
+	"bytes"
+	"fmt"
+	"go/constant"
+	"math"
 	"os"
 	"path/filepath"
 	"strings"
@@ -21,16 +25,59 @@ func IsCKeyword(s string) bool {
 	return false
 }
 
+// cName returns the C identifier for a field or union member name.
+func cName(s string) string {
+	s = name.SnakeCase(s)
+	if IsCKeyword(s) {
+		s += "_"
+	}
+	return s
+}
+
 // GenerateC writes the code into file "Colfer.h" and "Colfer.c".
 func GenerateC(basedir string, packages Packages) error {
+	if err := lintC(packages).Err(); err != nil {
+		return err
+	}
+
 	for _, p := range packages {
+		for _, e := range p.Enums {
+			e.NameNative = name.SnakeCase(p.Name + "_" + e.Name)
+			e.TypeNative = e.Type + "_t"
+			for _, v := range e.Values {
+				v.NameNative = strings.ToUpper(name.SnakeCase(p.Name + "_" + e.Name + "_" + v.Name))
+			}
+		}
+
+		for _, c := range p.Consts {
+			c.NameNative = strings.ToUpper(name.SnakeCase(p.Name + "_" + c.Name))
+			c.ValueNative = cConstValue(c)
+		}
+
+		for _, u := range p.Unions {
+			u.NameNative = name.SnakeCase(p.Name + "_" + u.Name)
+			for _, m := range u.Members {
+				m.NameNative = cName(m.Name)
+				m.ValueNative = strings.ToUpper(name.SnakeCase(p.Name + "_" + u.Name + "_" + m.Name))
+			}
+		}
+
 		for _, s := range p.Structs {
 			s.NameNative = name.SnakeCase(p.Name + "_" + s.Name)
 
 			for _, f := range s.Fields {
-				f.NameNative = name.SnakeCase(f.Name)
-				if IsCKeyword(f.NameNative) {
-					f.NameNative += "_"
+				f.NameNative = cName(f.Name)
+
+				switch f.TypeKey {
+				case "text":
+					f.TypeKeyNative = "colfer_text"
+				case "uint8", "uint16", "uint32", "uint64", "int32", "int64":
+					f.TypeKeyNative = f.TypeKey + "_t"
+				}
+
+				if f.TypeUnion != nil {
+					f.TypeNative = name.SnakeCase(f.TypeUnion.Pkg.Name + "_" + f.TypeUnion.Name)
+					continue
 				}
 
 				switch f.Type {
@@ -38,6 +85,9 @@ func GenerateC(basedir string, packages Packages) error {
 					f.TypeNative = "char"
 				case "uint8", "uint16", "uint32", "uint64", "int32", "int64":
 					f.TypeNative = f.Type + "_t"
+					if f.TypeEnum != nil {
+						f.TypeNative = name.SnakeCase(f.TypeEnum.Pkg.Name + "_" + f.TypeEnum.Name)
+					}
 				case "float32":
 					f.TypeNative = "float"
 				case "float64":
@@ -70,12 +120,65 @@ func GenerateC(basedir string, packages Packages) error {
 	if err != nil {
 		return err
 	}
-	if err := template.Must(template.New("C").Parse(cTemplate)).Execute(f, packages); err != nil {
+	t := template.New("C")
+	template.Must(t.Parse(cTemplate))
+	template.Must(t.New("map-marshal-len").Parse(cMapMarshalLen))
+	template.Must(t.New("map-marshal").Parse(cMapMarshal))
+	template.Must(t.New("map-unmarshal").Parse(cMapUnmarshal))
+	if err := t.Execute(f, packages); err != nil {
 		return err
 	}
 	return f.Close()
 }
 
+// cConstValue returns the C expression for c.
+func cConstValue(c *Const) string {
+	switch c.Type {
+	case "bool":
+		if constant.BoolVal(c.Value) {
+			return "1"
+		}
+		return "0"
+	case "float32":
+		return floatLiteral(c.Value, 32) + "f"
+	case "float64":
+		return floatLiteral(c.Value, 64)
+	case "text":
+		return cString(constant.StringVal(c.Value))
+	case "int32", "int64":
+		// the lowest value can't be written as a negated literal
+		switch x, _ := constant.Int64Val(c.Value); x {
+		case math.MinInt32:
+			if c.Type == "int32" {
+				return "INT32_MIN"
+			}
+		case math.MinInt64:
+			return "INT64_MIN"
+		}
+	}
+	return fmt.Sprintf("%s_C(%s)", strings.ToUpper(c.Type), c.Value.ExactString())
+}
+
+// cString returns a string literal of s with octal escapes for anything
+// other than printable ASCII. Question marks are escaped to prevent trigraphs.
+func cString(s string) string {
+	var buf bytes.Buffer
+	buf.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' || c == '\\' || c == '?':
+			buf.WriteByte('\\')
+			buf.WriteByte(c)
+		case c < ' ' || c > '~':
+			fmt.Fprintf(&buf, "\\%03o", c)
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	buf.WriteByte('"')
+	return buf.String()
+}
+
 const cHeaderTemplate = `// Code generated by colf(1); DO NOT EDIT.
 {{- range .}}
 // The compiler used schema file {{.SchemaFileList}} for package {{.Name}}.
@@ -106,6 +209,29 @@ extern size_t colfer_size_max;
 // colfer_list_max is the upper limit for the number of elements in a list.
 extern size_t colfer_list_max;
 
+// colfer_depth_max is the upper limit for the nesting depth of data
+// structures.
+extern size_t colfer_depth_max;
+
+// colfer_limits has the upper boundaries for marshalling and unmarshalling,
+// which apply to nested data structures too.
+typedef struct {
+	// size_max is the upper limit for serial octet sizes.
+	size_t size_max;
+	// list_max is the upper limit for the number of elements in a list or
+	// in a map.
+	size_t list_max;
+	// depth_max is the upper limit for the nesting depth of data
+	// structures, with one for a struct without any nested values.
+	size_t depth_max;
+} colfer_limits;
+
+// colfer_defaults returns the limits of colfer_size_max, colfer_list_max and
+// colfer_depth_max, which apply when none are given. A change to any of the
+// globals races with any (un)marshalling in progress. The functions with
+// explicit limits need no such change.
+colfer_limits colfer_defaults(void);
+
 
 // colfer_text is a UTF-8 CLOB.
 typedef struct {
@@ -118,16 +244,61 @@ typedef struct {
 	uint8_t* octets;
 	size_t   len;
 } colfer_binary;
-
+{{range .}}{{range .Consts}}
+{{.DocText "// "}}
+#define {{.NameNative}} {{.ValueNative}}
+{{end}}{{end}}
+{{- range .}}{{range .Enums}}
+{{.DocText "// "}}
+typedef {{.TypeNative}} {{.NameNative}};
+{{if .Values}}
+// {{.NameNative}}_value has the defined values for {{.NameNative}}.
+enum {{.NameNative}}_value {
+{{- range .Values}}
+{{.DocText "\t// "}}
+	{{.NameNative}} = {{.Value}},
+{{- end}}
+};
+{{end}}{{end}}{{end}}
 {{range .}}{{range .Structs}}
 typedef struct {{.NameNative}} {{.NameNative}};
 {{end}}{{end}}
+{{- range .}}{{range .Unions}}
+// {{.NameNative}}_type identifies the members of {{.NameNative}}.
+enum {{.NameNative}}_type {
+{{- range $i, $m := .Members}}
+	{{.ValueNative}}{{if eq $i 0}} = 1{{end}},
+{{- end}}
+};
+
+{{.DocText "// "}}
+typedef struct {
+	// type selects the member in place, with zero for none.
+	enum {{.NameNative}}_type type;
+	union {
+{{- range .Members}}
+		{{.Struct.NameNative}}* {{.NameNative}};
+{{- end}}
+	};
+} {{.NameNative}};
+{{end}}{{end}}
 {{range .}}{{range .Structs}}
 {{.DocText "// "}}
 struct {{.NameNative}} {
 {{- range .Fields}}
-{{.DocText "\t// "}}{{- if .TypeList}}
- {{- if eq .Type "float32"}}
+{{.DocText "\t// "}}{{- if .TypeKey}}
+	struct {
+		{{.TypeKeyNative}}* keys;
+		{{if .TypeRef}}struct {{.TypeRef.NameNative}}{{else}}{{.TypeNative}}{{end}}* values;
+		size_t len;
+	}
+{{- else if .TypeList}}
+ {{- if eq .Type "bool" "uint8" "uint16" "uint32" "uint64" "int32" "int64"}}
+	struct {
+		{{.TypeNative}}* list;
+		size_t len;
+	}
+ {{- else if eq .Type "float32"}}
 	struct {
 		float* list;
 		size_t len;
@@ -151,31 +322,66 @@ struct {{.NameNative}} {
 {{- else}}
  {{- if eq .Type "timestamp"}}
 	struct {{.TypeNative}}
+ {{- else if .TypeUnion}}
+	{{.TypeNative}}
  {{- else if .TypeRef}}
 	{{.TypeRef.NameNative}}*
  {{- else}}
 	{{.TypeNative}}
  {{- end}}
 {{- end}} {{.NameNative}};
+{{- if .Optional}}
+	// has_{{.NameNative}} flags the presence of {{.NameNative}}.
+	char has_{{.NameNative}};
+{{- end}}
+{{- end}}
+{{- if .Pkg.KeepUnknown}}
+	// colfer_unknown has the fields of a newer schema, if any, in serial form.
+	colfer_binary colfer_unknown;
 {{- end}}
 };
 
 // {{.NameNative}}_marshal_len returns the Colfer serial octet size.
 // When the return is zero then errno is set to EFBIG to indicate a breach of
-// either colfer_size_max or colfer_list_max.
+// either colfer_size_max or colfer_list_max, or errno is set to ELOOP to
+// indicate a breach of colfer_depth_max, which includes reference cycles.
+{{- if .HasMap}} Map keys must be in strictly
+// ascending order, with text compared per octet, or errno is set to EINVAL.
+{{- end}}
 size_t {{.NameNative}}_marshal_len(const {{.NameNative}}* o);
 
+// {{.NameNative}}_marshal_len_with sizes like {{.NameNative}}_marshal_len does,
+// with limits instead of the globals.
+size_t {{.NameNative}}_marshal_len_with(const {{.NameNative}}* o, const colfer_limits* limits);
+
 // {{.NameNative}}_marshal encodes o as Colfer into buf and returns the number
-// of octets written.
+// of octets written. The size of buf must be at least the return of
+// {{.NameNative}}_marshal_len, which also catches reference cycles.
 size_t {{.NameNative}}_marshal(const {{.NameNative}}* o, void* buf);
 
 // {{.NameNative}}_unmarshal decodes data as Colfer into o and returns the
 // number of octets read. The data is read up to a maximum of datalen or
 // colfer_size_max, whichever occurs first.
-// When the return is zero then errno is set to one of the following 3 values:
+// When the return is zero then errno is set to one of the following 4 values:
 // EWOULDBLOCK on incomplete data, EFBIG on a breach of either colfer_size_max
-// or colfer_list_max and EILSEQ on schema mismatch.
+// or colfer_list_max, ELOOP on a breach of colfer_depth_max and EILSEQ on
+// schema mismatch or on a malformed length.
+{{- if .Pkg.KeepUnknown}}
+// Fields beyond the schema are kept with {{.NameNative}}_unmarshal_serial only.
+{{- end}}
 size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t datalen);
+
+// {{.NameNative}}_unmarshal_with decodes like {{.NameNative}}_unmarshal does,
+// with limits instead of the globals.
+size_t {{.NameNative}}_unmarshal_with({{.NameNative}}* o, const void* data, size_t datalen, const colfer_limits* limits);
+{{- if .Pkg.KeepUnknown}}
+
+// {{.NameNative}}_unmarshal_serial decodes data as Colfer into o, with datalen
+// as the exact serial size. Any fields with an index beyond {{.IndexMax}} are kept in
+// colfer_unknown. Nested data structures can not keep unknown fields. The
+// return and errno are as with {{.NameNative}}_unmarshal.
+size_t {{.NameNative}}_unmarshal_serial({{.NameNative}}* o, const void* data, size_t datalen);
+{{- end}}
 {{end}}{{end}}
 
 #ifdef __cplusplus
@@ -209,24 +415,76 @@ const cTemplate = `// Code generated by colf(1); DO NOT EDIT.
 {{with index . 0}}
 size_t colfer_size_max = {{.SizeMax}};
 size_t colfer_list_max = {{.ListMax}};
+size_t colfer_depth_max = {{.DepthMax}};
 {{end}}
+colfer_limits colfer_defaults(void) {
+	colfer_limits limits = {colfer_size_max, colfer_list_max, colfer_depth_max};
+	return limits;
+}
 
 {{range .}}{{range .Structs}}
 size_t {{.NameNative}}_marshal_len(const {{.NameNative}}* o) {
-	size_t l = 1;
-{{range .Fields}}{{if eq .Type "bool"}}
-	if (o->{{.NameNative}}) l++;
+	colfer_limits limits = colfer_defaults();
+	return {{.NameNative}}_marshal_len_with(o, &limits);
+}
+
+size_t {{.NameNative}}_marshal_len_with(const {{.NameNative}}* o, const colfer_limits* limits) {
+	if (!limits->depth_max) {
+		errno = ELOOP;
+		return 0;
+	}
+{{- if .HasRef}}
+	// nested data structures are one level deeper
+	colfer_limits nested = *limits;
+	--nested.depth_max;
+{{- end}}
+	size_t l = 1{{if .Pkg.KeepUnknown}} + o->colfer_unknown.len{{end}};
+{{range .Fields}}{{if .TypeKey}}{{template "map-marshal-len" .}}{{else if and .TypeList (eq .Type "bool" "uint8" "uint16" "uint32" "uint64" "int32" "int64")}}
+	{
+		size_t n = o->{{.NameNative}}.len;
+		if (n) {
+			if (n > {{or .ListMax "limits->list_max"}}) {
+				errno = EFBIG;
+				return 0;
+			}
+ {{- if eq .Type "bool" "uint8"}}
+			for (l += n + 2; n > 127; n >>= 7, ++l);
+ {{- else}}
+			const {{.TypeNative}}* a = o->{{.NameNative}}.list;
+			for (size_t i = 0; i < n; ++i) {
+				{{- if eq .Type "int32"}}
+				uint64_t v = (uint32_t) a[i] << 1;
+				if (a[i] < 0) v ^= UINT32_MAX;
+ {{- else if eq .Type "int64"}}
+				uint64_t v = (uint64_t) a[i] << 1;
+				if (a[i] < 0) v = ~v;
+ {{- else}}
+				uint64_t v = a[i];
+ {{- end}}
+				for (int m = 8; v > 127 && m; --m, v >>= 7) ++l;
+				++l;
+			}
+			for (l += 2; n > 127; n >>= 7, ++l);
+			if (l > limits->size_max) {
+				errno = EFBIG;
+				return 0;
+			}
+ {{- end}}
+		}
+	}
+{{else if eq .Type "bool"}}
+	if (o->{{if .Optional}}has_{{end}}{{.NameNative}}) l++;
 {{else if eq .Type "uint8"}}
-	if (o->{{.NameNative}}) l += 2;
+	if (o->{{if .Optional}}has_{{end}}{{.NameNative}}) l += 2;
 {{else if eq .Type "uint16"}}
 	{
 		uint_fast16_t x = o->{{.NameNative}};
-		if (x) l += x < 256 ? 2 : 3;
+		if ({{if .Optional}}o->has_{{.NameNative}}{{else}}x{{end}}) l += x < 256 ? 2 : 3;
 	}
 {{else if eq .Type "uint32"}}
 	{
 		uint_fast32_t x = o->{{.NameNative}};
-		if (x) {
+		if ({{if .Optional}}o->has_{{.NameNative}}{{else}}x{{end}}) {
 			if (x >= (uint_fast32_t) 1 << 21) l += 5;
 			else for (l += 2; x > 127; x >>= 7, ++l);
 		}
@@ -234,7 +492,7 @@ size_t {{.NameNative}}_marshal_len(const {{.NameNative}}* o) {
 {{else if eq .Type "uint64"}}
 	{
 		uint_fast64_t x = o->{{.NameNative}};
-		if (x) {
+		if ({{if .Optional}}o->has_{{.NameNative}}{{else}}x{{end}}) {
 			if (x >= (uint_fast64_t) 1 << 49) l += 9;
 			else for (l += 2; x > 127; x >>= 7, ++l);
 		}
@@ -242,7 +500,7 @@ size_t {{.NameNative}}_marshal_len(const {{.NameNative}}* o) {
 {{else if eq .Type "int32"}}
 	{
 		uint_fast32_t x = o->{{.NameNative}};
-		if (x) {
+		if ({{if .Optional}}o->has_{{.NameNative}}{{else}}x{{end}}) {
 			if (x & (uint_fast32_t) 1 << 31) {
 				x = ~x;
 				++x;
@@ -253,7 +511,7 @@ size_t {{.NameNative}}_marshal_len(const {{.NameNative}}* o) {
 {{else if eq .Type "int64"}}
 	{
 		uint_fast64_t x = o->{{.NameNative}};
-		if (x) {
+		if ({{if .Optional}}o->has_{{.NameNative}}{{else}}x{{end}}) {
 			if (x & (uint_fast64_t) 1 << 63) {
 				x = ~x;
 				++x;
@@ -264,12 +522,12 @@ size_t {{.NameNative}}_marshal_len(const {{.NameNative}}* o) {
 	}
 {{else if eq .Type "float32"}}
  {{- if not .TypeList}}
-	if (o->{{.NameNative}} != 0.0f) l += 5;
+	if ({{if .Optional}}o->has_{{.NameNative}}{{else}}o->{{.NameNative}} != 0.0f{{end}}) l += 5;
  {{- else}}
 	{
 		size_t n = o->{{.NameNative}}.len;
 		if (n) {
-			if (n > colfer_list_max) {
+			if (n > {{or .ListMax "limits->list_max"}}) {
 				errno = EFBIG;
 				return 0;
 			}
@@ -279,12 +537,12 @@ size_t {{.NameNative}}_marshal_len(const {{.NameNative}}* o) {
  {{- end}}
 {{else if eq .Type "float64"}}
  {{- if not .TypeList}}
-	if (o->{{.NameNative}} != 0.0) l += 9;
+	if ({{if .Optional}}o->has_{{.NameNative}}{{else}}o->{{.NameNative}} != 0.0{{end}}) l += 9;
  {{- else}}
 	{
 		size_t n = o->{{.NameNative}}.len;
 		if (n) {
-			if (n > colfer_list_max) {
+			if (n > {{or .ListMax "limits->list_max"}}) {
 				errno = EFBIG;
 				return 0;
 			}
@@ -305,7 +563,7 @@ size_t {{.NameNative}}_marshal_len(const {{.NameNative}}* o) {
  {{- if not .TypeList}}
 	{
 		size_t n = o->{{.NameNative}}.len;
-		if (n > colfer_size_max) {
+		if (n > {{or .SizeMax "limits->size_max"}}) {
 			errno = EFBIG;
 			return 0;
 		}
@@ -315,21 +573,21 @@ size_t {{.NameNative}}_marshal_len(const {{.NameNative}}* o) {
 	{
 		size_t n = o->{{.NameNative}}.len;
 		if (n) {
-			if (n > colfer_list_max) {
+			if (n > {{or .ListMax "limits->list_max"}}) {
 				errno = EFBIG;
 				return 0;
 			}
 			colfer_text* a = o->{{.NameNative}}.list;
 			for (size_t i = 0; i < n; ++i) {
 				size_t len = a[i].len;
-				if (len > colfer_size_max) {
+				if (len > {{or .SizeMax "limits->size_max"}}) {
 					errno = EFBIG;
 					return 0;
 				}
 				for (l += len + 1; len > 127; len >>= 7, ++l);
 			}
 			for (l += 2; n > 127; n >>= 7, ++l);
-			if (l > colfer_size_max) {
+			if (l > limits->size_max) {
 				errno = EFBIG;
 				return 0;
 			}
@@ -340,7 +598,7 @@ size_t {{.NameNative}}_marshal_len(const {{.NameNative}}* o) {
  {{- if not .TypeList}}
 	{
 		size_t n = o->{{.NameNative}}.len;
-		if (n > colfer_size_max) {
+		if (n > {{or .SizeMax "limits->size_max"}}) {
 			errno = EFBIG;
 			return 0;
 		}
@@ -350,44 +608,90 @@ size_t {{.NameNative}}_marshal_len(const {{.NameNative}}* o) {
 	{
 		size_t n = o->{{.NameNative}}.len;
 		if (n) {
-			if (n > colfer_list_max) {
+			if (n > {{or .ListMax "limits->list_max"}}) {
 				errno = EFBIG;
 				return 0;
 			}
 			colfer_binary* a = o->{{.NameNative}}.list;
 			for (size_t i = 0; i < n; ++i) {
 				size_t len = a[i].len;
-				if (len > colfer_size_max) {
+				if (len > {{or .SizeMax "limits->size_max"}}) {
 					errno = EFBIG;
 					return 0;
 				}
 				for (l += len + 1; len > 127; len >>= 7, ++l);
 			}
 			for (l += 2; n > 127; n >>= 7, ++l);
-			if (l > colfer_size_max) {
+			if (l > limits->size_max) {
 				errno = EFBIG;
 				return 0;
 			}
 		}
 	}
  {{- end}}
+{{else if .TypeUnion}}
+	{{- $f := .}}
+	switch (o->{{.NameNative}}.type) {
+ {{- range .TypeUnion.Members}}
+	case {{.ValueNative}}:
+		if (o->{{$f.NameNative}}.{{.NameNative}}) {
+{{- if eq .Struct.String $f.Struct.String}}
+			if (o->{{$f.NameNative}}.{{.NameNative}} == o) {
+				// reference cycle
+				errno = ELOOP;
+				return 0;
+			}
+{{- end}}
+			size_t size = {{.Struct.NameNative}}_marshal_len_with(o->{{$f.NameNative}}.{{.NameNative}}, &nested);
+			if (!size) return 0;
+			l += 2 + size;
+		} else {
+			l += 3;
+		}
+		break;
+ {{- end}}
+	default:
+		break;
+	}
 {{else}}
  {{- if not .TypeList}}
 	{
-		if (o->{{.NameNative}}) l += 1 + {{.TypeRef.NameNative}}_marshal_len(o->{{.NameNative}});
+		if (o->{{.NameNative}}) {
+{{- if eq .TypeRef.String .Struct.String}}
+			if (o->{{.NameNative}} == o) {
+				// reference cycle
+				errno = ELOOP;
+				return 0;
+			}
+{{- end}}
+			size_t size = {{.TypeRef.NameNative}}_marshal_len_with(o->{{.NameNative}}, &nested);
+			if (!size) return 0;
+			l += 1 + size;
+		}
 	}
  {{- else}}
 	{
 		size_t n = o->{{.NameNative}}.len;
 		if (n) {
-			if (n > colfer_list_max) {
+			if (n > {{or .ListMax "limits->list_max"}}) {
 				errno = EFBIG;
 				return 0;
 			}
 			{{.TypeRef.NameNative}}* a = o->{{.NameNative}}.list;
-			for (size_t i = 0; i < n; ++i) l += {{.TypeRef.NameNative}}_marshal_len(&a[i]);
+			for (size_t i = 0; i < n; ++i) {
+{{- if eq .TypeRef.String .Struct.String}}
+				if (&a[i] == o) {
+					// reference cycle
+					errno = ELOOP;
+					return 0;
+				}
+{{- end}}
+				size_t size = {{.TypeRef.NameNative}}_marshal_len_with(&a[i], &nested);
+				if (!size) return 0;
+				l += size;
+			}
 			for (l += 2; n > 127; n >>= 7, ++l);
-			if (l > colfer_size_max) {
+			if (l > limits->size_max) {
 				errno = EFBIG;
 				return 0;
 			}
@@ -395,7 +699,7 @@ size_t {{.NameNative}}_marshal_len(const {{.NameNative}}* o) {
 	}
  {{- end}}
 {{end}}{{end}}
-	if (l > colfer_size_max) {
+	if (l > limits->size_max) {
 		errno = EFBIG;
 		return 0;
 	}
@@ -405,10 +709,50 @@ size_t {{.NameNative}}_marshal_len(const {{.NameNative}}* o) {
 size_t {{.NameNative}}_marshal(const {{.NameNative}}* o, void* buf) {
 	// octet pointer navigation
 	uint8_t* p = buf;
-{{range .Fields}}{{if eq .Type "bool"}}
+{{range .Fields}}{{if .TypeKey}}{{template "map-marshal" .}}{{else if and .TypeList (eq .Type "bool" "uint8" "uint16" "uint32" "uint64" "int32" "int64")}}
+	{
+		size_t n = o->{{.NameNative}}.len;
+		if (n) {
+			*p++ = {{.Index}};
+
+			uint_fast32_t x = n;
+			for (; x >= 128; x >>= 7) *p++ = x | 128;
+			*p++ = x;
+ {{- if eq .Type "bool"}}
+
+			const char* a = o->{{.NameNative}}.list;
+			for (size_t i = 0; i < n; ++i) *p++ = a[i] != 0;
+ {{- else if eq .Type "uint8"}}
+
+			memcpy(p, o->{{.NameNative}}.list, n);
+			p += n;
+ {{- else}}
+
+			const {{.TypeNative}}* a = o->{{.NameNative}}.list;
+			for (size_t i = 0; i < n; ++i) {
+				{{- if eq .Type "int32"}}
+				uint64_t v = (uint32_t) a[i] << 1;
+				if (a[i] < 0) v ^= UINT32_MAX;
+ {{- else if eq .Type "int64"}}
+				uint64_t v = (uint64_t) a[i] << 1;
+				if (a[i] < 0) v = ~v;
+ {{- else}}
+				uint64_t v = a[i];
+ {{- end}}
+				for (int m = 8; v >= 128 && m; --m, v >>= 7) *p++ = v | 128;
+				*p++ = v;
+			}
+ {{- end}}
+		}
+	}
+{{else if eq .Type "bool"}}
+ {{- if .Optional}}
+	if (o->has_{{.NameNative}}) *p++ = o->{{.NameNative}} ? {{.Index}} : {{.Index}} | 128;
+ {{- else}}
 	if (o->{{.NameNative}}) *p++ = {{.Index}};
+ {{- end}}
 {{else if eq .Type "uint8"}}
-	if (o->{{.NameNative}}) {
+	if (o->{{if .Optional}}has_{{end}}{{.NameNative}}) {
 		*p++ = {{.Index}};
 
 		*p++ = o->{{.NameNative}};
@@ -416,7 +760,7 @@ size_t {{.NameNative}}_marshal(const {{.NameNative}}* o, void* buf) {
 {{else if eq .Type "uint16"}}
 	{
 		uint_fast16_t x = o->{{.NameNative}};
-		if (x) {
+		if ({{if .Optional}}o->has_{{.NameNative}}{{else}}x{{end}}) {
 			if (x < 256)  {
 				*p++ = {{.Index}} | 0x80;
 
@@ -432,7 +776,7 @@ size_t {{.NameNative}}_marshal(const {{.NameNative}}* o, void* buf) {
 {{else if eq .Type "uint32"}}
 	{
 		uint_fast32_t x = o->{{.NameNative}};
-		if (x) {
+		if ({{if .Optional}}o->has_{{.NameNative}}{{else}}x{{end}}) {
 			if (x < (uint_fast32_t) 1 << 21) {
 				*p++ = {{.Index}};
 				for (; x >= 128; x >>= 7) *p++ = x | 128;
@@ -454,7 +798,7 @@ size_t {{.NameNative}}_marshal(const {{.NameNative}}* o, void* buf) {
 {{else if eq .Type "uint64"}}
 	{
 		uint_fast64_t x = o->{{.NameNative}};
-		if (x) {
+		if ({{if .Optional}}o->has_{{.NameNative}}{{else}}x{{end}}) {
 			if (x < (uint_fast64_t) 1 << 49) {
 				*p++ = {{.Index}};
 				for (; x >= 128; x >>= 7) *p++ = x | 128;
@@ -480,7 +824,7 @@ size_t {{.NameNative}}_marshal(const {{.NameNative}}* o, void* buf) {
 {{else if eq .Type "int32"}}
 	{
 		uint_fast32_t x = o->{{.NameNative}};
-		if (x) {
+		if ({{if .Optional}}o->has_{{.NameNative}}{{else}}x{{end}}) {
 			if (x & (uint_fast32_t) 1 << 31) {
 				*p++ = {{.Index}} | 128;
 				x = ~x + 1;
@@ -493,7 +837,7 @@ size_t {{.NameNative}}_marshal(const {{.NameNative}}* o, void* buf) {
 {{else if eq .Type "int64"}}
 	{
 		uint_fast64_t x = o->{{.NameNative}};
-		if (x) {
+		if ({{if .Optional}}o->has_{{.NameNative}}{{else}}x{{end}}) {
 			if (x & (uint_fast64_t) 1 << 63) {
 				*p++ = {{.Index}} | 128;
 				x = ~x + 1;
@@ -506,7 +850,7 @@ size_t {{.NameNative}}_marshal(const {{.NameNative}}* o, void* buf) {
 	}
 {{else if eq .Type "float32"}}
  {{- if not .TypeList}}
-	if (o->{{.NameNative}} != 0.0f) {
+	if ({{if .Optional}}o->has_{{.NameNative}}{{else}}o->{{.NameNative}} != 0.0f{{end}}) {
 		*p++ = {{.Index}};
 
 #ifdef COLFER_ENDIAN
@@ -551,7 +895,7 @@ size_t {{.NameNative}}_marshal(const {{.NameNative}}* o, void* buf) {
  {{- end}}
 {{else if eq .Type "float64"}}
  {{- if not .TypeList}}
-	if (o->{{.NameNative}} != 0.0) {
+	if ({{if .Optional}}o->has_{{.NameNative}}{{else}}o->{{.NameNative}} != 0.0{{end}}) {
 		*p++ = {{.Index}};
 
 #ifdef COLFER_ENDIAN
@@ -717,6 +1061,21 @@ size_t {{.NameNative}}_marshal(const {{.NameNative}}* o, void* buf) {
 		}
 	}
  {{- end}}
+{{else if .TypeUnion}}
+	{{- $f := .}}
+	switch (o->{{.NameNative}}.type) {
+ {{- range .TypeUnion.Members}}
+	case {{.ValueNative}}:
+		*p++ = {{$f.Index}};
+		*p++ = {{.Index}};
+
+		if (o->{{$f.NameNative}}.{{.NameNative}}) p += {{.Struct.NameNative}}_marshal(o->{{$f.NameNative}}.{{.NameNative}}, p);
+		else *p++ = 127;
+		break;
+ {{- end}}
+	default:
+		break;
+	}
 {{else}}
  {{- if not .TypeList}}
 	{
@@ -742,21 +1101,38 @@ size_t {{.NameNative}}_marshal(const {{.NameNative}}* o, void* buf) {
 	}
  {{- end}}
 {{end}}{{end}}
+{{- if .Pkg.KeepUnknown}}
+	if (o->colfer_unknown.len) {
+		memcpy(p, o->colfer_unknown.octets, o->colfer_unknown.len);
+		p += o->colfer_unknown.len;
+	}
+{{end}}
 	*p++ = 127;
 
 	return p - (uint8_t*) buf;
 }
 
-size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t datalen) {
+{{if .Pkg.KeepUnknown}}static size_t {{.NameNative}}_unmarshal_fields({{.NameNative}}* o, const void* data, size_t datalen, const colfer_limits* limits, int serial) {
+{{- else}}size_t {{.NameNative}}_unmarshal_with({{.NameNative}}* o, const void* data, size_t datalen, const colfer_limits* limits) {
+{{- end}}
+	if (!limits->depth_max) {
+		errno = ELOOP;
+		return 0;
+	}
+{{- if .HasRef}}
+	// nested data structures are one level deeper
+	colfer_limits nested = *limits;
+	--nested.depth_max;
+{{- end}}
 	// octet pointer navigation
 	const uint8_t* p = data;
 	const uint8_t* end;
 	int enderr;
-	if (datalen < colfer_size_max) {
+	if (datalen < limits->size_max) {
 		end = p + datalen;
 		enderr = EWOULDBLOCK;
 	} else {
-		end = p + colfer_size_max;
+		end = p + limits->size_max;
 		enderr = EFBIG;
 	}
 
@@ -765,15 +1141,109 @@ size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t da
 		return 0;
 	}
 	uint_fast8_t header = *p++;
-{{range .Fields}}{{if eq .Type "bool"}}
+{{range .Fields}}{{if .TypeKey}}{{template "map-unmarshal" .}}{{else if and .TypeList (eq .Type "bool" "uint8" "uint16" "uint32" "uint64" "int32" "int64")}}
+	if (header == {{.Index}}) {
+		if (p >= end) {
+			errno = enderr;
+			return 0;
+		}
+		size_t n = *p++;
+		if (n > 127) {
+			n &= 127;
+			for (size_t shift = 7; ; shift += 7) {
+				if (shift >= sizeof(size_t) * CHAR_BIT) {
+					errno = EILSEQ;
+					return 0;
+				}
+				if (p >= end) {
+					errno = enderr;
+					return 0;
+				}
+				size_t c = *p++;
+				if (c <= 127) {
+					n |= c << shift;
+					break;
+				}
+				n |= (c & 127) << shift;
+			}
+		}
+		if (n > {{or .ListMax "limits->list_max"}}) {
+			errno = EFBIG;
+			return 0;
+		}
+		if (p+n >= end) {
+			errno = enderr;
+			return 0;
+		}
+		o->{{.NameNative}}.len = n;
+
+		{{.TypeNative}}* a = malloc(n * sizeof({{.TypeNative}}));
+		o->{{.NameNative}}.list = a;
+ {{- if eq .Type "bool"}}
+		for (size_t i = 0; i < n; ++i) a[i] = *p++ != 0;
+ {{- else if eq .Type "uint8"}}
+		memcpy(a, p, n);
+		p += n;
+ {{- else}}
+		for (size_t i = 0; i < n; ++i) {
+			if (p >= end) {
+				errno = enderr;
+				return 0;
+			}
+			uint_fast64_t x = *p++;
+			if (x > 127) {
+				x &= 127;
+				for (int shift = 7; ; shift += 7) {
+					if (p >= end) {
+						errno = enderr;
+						return 0;
+					}
+					uint_fast64_t b = *p++;
+					if (b <= 127 || shift == 56) {
+						x |= b << shift;
+						break;
+					}
+					x |= (b & 127) << shift;
+				}
+			}
+  {{- if eq .Type "int32"}}
+			uint32_t u = x;
+			a[i] = (u >> 1) ^ (0 - (u & 1));
+  {{- else if eq .Type "int64"}}
+			a[i] = (x >> 1) ^ (0 - (x & 1));
+  {{- else}}
+			a[i] = x;
+  {{- end}}
+		}
+		if (p >= end) {
+			errno = enderr;
+			return 0;
+		}
+ {{- end}}
+		header = *p++;
+	}
+{{else if eq .Type "bool"}}
 	if (header == {{.Index}}) {
 		o->{{.NameNative}} = 1;
+ {{- if .Optional}}
+		o->has_{{.NameNative}} = 1;
+ {{- end}}
+		if (p >= end) {
+			errno = enderr;
+			return 0;
+		}
+		header = *p++;
+	}
+ {{- if .Optional}} else if (header == ({{.Index}} | 128)) {
+		o->{{.NameNative}} = 0;
+		o->has_{{.NameNative}} = 1;
 		if (p >= end) {
 			errno = enderr;
 			return 0;
 		}
 		header = *p++;
 	}
+ {{- end}}
 {{else if eq .Type "uint8"}}
 	if (header == {{.Index}}) {
 		if (p+1 >= end) {
@@ -781,6 +1251,9 @@ size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t da
 			return 0;
 		}
 		o->{{.NameNative}} = *p++;
+{{- if .Optional}}
+		o->has_{{.NameNative}} = 1;
+{{- end}}
 		header = *p++;
 	}
 {{else if eq .Type "uint16"}}
@@ -792,6 +1265,9 @@ size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t da
 		uint_fast16_t x = *p++;
 		x <<= 8;
 		o->{{.NameNative}} = x | *p++;
+{{- if .Optional}}
+		o->has_{{.NameNative}} = 1;
+{{- end}}
 		header = *p++;
 	} else if (header == ({{.Index}} | 128)) {
 		if (p+1 >= end) {
@@ -799,6 +1275,9 @@ size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t da
 			return 0;
 		}
 		o->{{.NameNative}} = *p++;
+{{- if .Optional}}
+		o->has_{{.NameNative}} = 1;
+{{- end}}
 		header = *p++;
 	}
 {{else if eq .Type "uint32"}}
@@ -824,6 +1303,9 @@ size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t da
 			}
 		}
 		o->{{.NameNative}} = x;
+{{- if .Optional}}
+		o->has_{{.NameNative}} = 1;
+{{- end}}
 		header = *p++;
 	} else if (header == ({{.Index}} | 128)) {
 		if (p+4 >= end) {
@@ -836,6 +1318,9 @@ size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t da
 		x |= (uint_fast32_t) *p++ << 8;
 		x |= (uint_fast32_t) *p++;
 		o->{{.NameNative}} = x;
+{{- if .Optional}}
+		o->has_{{.NameNative}} = 1;
+{{- end}}
 		header = *p++;
 	}
 {{else if eq .Type "uint64"}}
@@ -861,6 +1346,9 @@ size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t da
 			}
 		}
 		o->{{.NameNative}} = x;
+{{- if .Optional}}
+		o->has_{{.NameNative}} = 1;
+{{- end}}
 		header = *p++;
 	} else if (header == ({{.Index}} | 128)) {
 		if (p+8 >= end) {
@@ -877,6 +1365,9 @@ size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t da
 		x |= (uint_fast64_t) *p++ << 8;
 		x |= (uint_fast64_t) *p++;
 		o->{{.NameNative}} = x;
+{{- if .Optional}}
+		o->has_{{.NameNative}} = 1;
+{{- end}}
 		header = *p++;
 	}
 {{else if eq .Type "int32"}}
@@ -903,6 +1394,9 @@ size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t da
 		}
 		if (header & 128) x = ~x + 1;
 		o->{{.NameNative}} = x;
+{{- if .Optional}}
+		o->has_{{.NameNative}} = 1;
+{{- end}}
 		header = *p++;
 	}
 {{else if eq .Type "int64"}}
@@ -929,6 +1423,9 @@ size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t da
 		}
 		if (header & 128) x = ~x + 1;
 		o->{{.NameNative}} = x;
+{{- if .Optional}}
+		o->has_{{.NameNative}} = 1;
+{{- end}}
 		header = *p++;
 	}
 {{else if eq .Type "float32"}}
@@ -949,6 +1446,9 @@ size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t da
 		x |= (uint_fast32_t) *p++;
 		memcpy(&o->{{.NameNative}}, &x, 4);
 #endif
+{{- if .Optional}}
+		o->has_{{.NameNative}} = 1;
+{{- end}}
 		header = *p++;
 	}
  {{- else}}
@@ -960,7 +1460,11 @@ size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t da
 		size_t n = *p++;
 		if (n > 127) {
 			n &= 127;
-			for (int shift = 7; ; shift += 7) {
+			for (size_t shift = 7; ; shift += 7) {
+				if (shift >= sizeof(size_t) * CHAR_BIT) {
+					errno = EILSEQ;
+					return 0;
+				}
 				if (p >= end) {
 					errno = enderr;
 					return 0;
@@ -973,7 +1477,7 @@ size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t da
 				n |= (c & 127) << shift;
 			}
 		}
-		if (n > colfer_list_max) {
+		if (n > {{or .ListMax "limits->list_max"}}) {
 			errno = EFBIG;
 			return 0;
 		}
@@ -1023,6 +1527,9 @@ size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t da
 		x |= (uint_fast64_t) *p++;
 		memcpy(&o->{{.NameNative}}, &x, 8);
 #endif
+{{- if .Optional}}
+		o->has_{{.NameNative}} = 1;
+{{- end}}
 		header = *p++;
 	}
  {{- else}}
@@ -1034,7 +1541,11 @@ size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t da
 		size_t n = *p++;
 		if (n > 127) {
 			n &= 127;
-			for (int shift = 7; ; shift += 7) {
+			for (size_t shift = 7; ; shift += 7) {
+				if (shift >= sizeof(size_t) * CHAR_BIT) {
+					errno = EILSEQ;
+					return 0;
+				}
 				if (p >= end) {
 					errno = enderr;
 					return 0;
@@ -1047,7 +1558,7 @@ size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t da
 				n |= (c & 127) << shift;
 			}
 		}
-		if (n > colfer_list_max) {
+		if (n > {{or .ListMax "limits->list_max"}}) {
 			errno = EFBIG;
 			return 0;
 		}
@@ -1126,7 +1637,7 @@ size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t da
 		size_t n = *p++;
 		if (n > 127) {
 			n &= 127;
-			for (int shift = 7; shift < sizeof(size_t) * CHAR_BIT; shift += 7) {
+			for (size_t shift = 7; shift < sizeof(size_t) * CHAR_BIT; shift += 7) {
 				if (p >= end) {
 					errno = enderr;
 					return 0;
@@ -1139,7 +1650,7 @@ size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t da
 				n |= (c & 127) << shift;
 			}
 		}
-		if (n > colfer_size_max) {
+		if (n > {{or .SizeMax "limits->size_max"}}) {
 			errno = EFBIG;
 			return 0;
 		}
@@ -1166,7 +1677,11 @@ size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t da
 		size_t n = *p++;
 		if (n > 127) {
 			n &= 127;
-			for (int shift = 7; ; shift += 7) {
+			for (size_t shift = 7; ; shift += 7) {
+				if (shift >= sizeof(size_t) * CHAR_BIT) {
+					errno = EILSEQ;
+					return 0;
+				}
 				if (p >= end) {
 					errno = enderr;
 					return 0;
@@ -1179,7 +1694,7 @@ size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t da
 				n |= (c & 127) << shift;
 			}
 		}
-		if (n > colfer_list_max) {
+		if (n > {{or .ListMax "limits->list_max"}}) {
 			errno = EFBIG;
 			return 0;
 		}
@@ -1195,7 +1710,11 @@ size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t da
 			size_t len = *p++;
 			if (len > 127) {
 				len &= 127;
-				for (int shift = 7; ; shift += 7) {
+				for (size_t shift = 7; ; shift += 7) {
+					if (shift >= sizeof(size_t) * CHAR_BIT) {
+						errno = EILSEQ;
+						return 0;
+					}
 					if (p >= end) {
 						errno = enderr;
 						return 0;
@@ -1208,7 +1727,7 @@ size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t da
 					len |= (c & 127) << shift;
 				}
 			}
-			if (len > colfer_size_max) {
+			if (len > {{or .SizeMax "limits->size_max"}}) {
 				errno = EFBIG;
 				return 0;
 			}
@@ -1243,7 +1762,11 @@ size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t da
 		size_t n = *p++;
 		if (n > 127) {
 			n &= 127;
-			for (int shift = 7; ; shift += 7) {
+			for (size_t shift = 7; ; shift += 7) {
+				if (shift >= sizeof(size_t) * CHAR_BIT) {
+					errno = EILSEQ;
+					return 0;
+				}
 				if (p >= end) {
 					errno = enderr;
 					return 0;
@@ -1256,7 +1779,7 @@ size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t da
 				n |= (c & 127) << shift;
 			}
 		}
-		if (n > colfer_size_max) {
+		if (n > {{or .SizeMax "limits->size_max"}}) {
 			errno = EFBIG;
 			return 0;
 		}
@@ -1283,7 +1806,11 @@ size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t da
 		size_t n = *p++;
 		if (n > 127) {
 			n &= 127;
-			for (int shift = 7; ; shift += 7) {
+			for (size_t shift = 7; ; shift += 7) {
+				if (shift >= sizeof(size_t) * CHAR_BIT) {
+					errno = EILSEQ;
+					return 0;
+				}
 				if (p >= end) {
 					errno = enderr;
 					return 0;
@@ -1296,7 +1823,7 @@ size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t da
 				n |= (c & 127) << shift;
 			}
 		}
-		if (n > colfer_list_max) {
+		if (n > {{or .ListMax "limits->list_max"}}) {
 			errno = EFBIG;
 			return 0;
 		}
@@ -1312,7 +1839,11 @@ size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t da
 			size_t len = *p++;
 			if (len > 127) {
 				len &= 127;
-				for (int shift = 7; ; shift += 7) {
+				for (size_t shift = 7; ; shift += 7) {
+					if (shift >= sizeof(size_t) * CHAR_BIT) {
+						errno = EILSEQ;
+						return 0;
+					}
 					if (p >= end) {
 						errno = enderr;
 						return 0;
@@ -1325,7 +1856,7 @@ size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t da
 					len |= (c & 127) << shift;
 				}
 			}
-			if (len > colfer_size_max) {
+			if (len > {{or .SizeMax "limits->size_max"}}) {
 				errno = EFBIG;
 				return 0;
 			}
@@ -1350,11 +1881,43 @@ size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t da
 		header = *p++;
 	}
  {{- end}}
+{{else if .TypeUnion}}
+	{{- $f := .}}
+	if (header == {{.Index}}) {
+		if (p >= end) {
+			errno = enderr;
+			return 0;
+		}
+		size_t read;
+		switch (*p++) {
+ {{- range .TypeUnion.Members}}
+		case {{.Index}}:
+			o->{{$f.NameNative}}.type = {{.ValueNative}};
+			o->{{$f.NameNative}}.{{.NameNative}} = calloc(1, sizeof({{.Struct.NameNative}}));
+			read = {{.Struct.NameNative}}_unmarshal_with(o->{{$f.NameNative}}.{{.NameNative}}, p, (size_t) (end - p), &nested);
+			break;
+ {{- end}}
+		default:
+			errno = EILSEQ;
+			return 0;
+		}
+		if (!read) {
+			if (errno == EWOULDBLOCK) errno = enderr;
+			return read;
+		}
+		p += read;
+
+		if (p >= end) {
+			errno = enderr;
+			return 0;
+		}
+		header = *p++;
+	}
 {{else}}
  {{- if not .TypeList}}
 	if (header == {{.Index}}) {
 		o->{{.NameNative}} = calloc(1, sizeof({{.TypeRef.NameNative}}));
-		size_t read = {{.TypeRef.NameNative}}_unmarshal(o->{{.NameNative}}, p, (size_t) (end - p));
+		size_t read = {{.TypeRef.NameNative}}_unmarshal_with(o->{{.NameNative}}, p, (size_t) (end - p), &nested);
 		if (!read) {
 			if (errno == EWOULDBLOCK) errno = enderr;
 			return read;
@@ -1376,7 +1939,11 @@ size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t da
 		size_t n = *p++;
 		if (n > 127) {
 			n &= 127;
-			for (int shift = 7; ; shift += 7) {
+			for (size_t shift = 7; ; shift += 7) {
+				if (shift >= sizeof(size_t) * CHAR_BIT) {
+					errno = EILSEQ;
+					return 0;
+				}
 				if (p >= end) {
 					errno = enderr;
 					return 0;
@@ -1389,14 +1956,14 @@ size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t da
 				n |= (c & 127) << shift;
 			}
 		}
-		if (n > colfer_list_max) {
+		if (n > {{or .ListMax "limits->list_max"}}) {
 			errno = EFBIG;
 			return 0;
 		}
 
 		{{.TypeRef.NameNative}}* a = calloc(n, sizeof({{.TypeRef.NameNative}}));
 		for (size_t i = 0; i < n; ++i) {
-			size_t read = {{.TypeRef.NameNative}}_unmarshal(&a[i], p, (size_t) (end - p));
+			size_t read = {{.TypeRef.NameNative}}_unmarshal_with(&a[i], p, (size_t) (end - p), &nested);
 			if (!read) {
 				if (errno == EWOULDBLOCK) errno = enderr;
 				return read;
@@ -1415,10 +1982,483 @@ size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t da
  {{- end}}
 {{end}}{{end}}
 	if (header != 127) {
+{{- if .Pkg.KeepUnknown}}
+		if (!serial{{if .Fields}} || (header & 127) <= {{.IndexMax}}{{end}}) {
+			errno = EILSEQ;
+			return 0;
+		}
+		if (enderr == EFBIG) {
+			errno = EFBIG;
+			return 0;
+		}
+		if (end[-1] != 127) {
+			errno = EILSEQ;
+			return 0;
+		}
+		// unknown fields from the header up to the serial end
+		size_t n = end - p;
+		void* a = malloc(n);
+		memcpy(a, p - 1, n);
+		o->colfer_unknown.octets = (uint8_t*) a;
+		o->colfer_unknown.len = n;
+		p = end;
+{{- else}}
 		errno = EILSEQ;
 		return 0;
+{{- end}}
 	}
 
 	return (size_t) (p - (const uint8_t*) data);
 }
+{{- if .Pkg.KeepUnknown}}
+
+size_t {{.NameNative}}_unmarshal_with({{.NameNative}}* o, const void* data, size_t datalen, const colfer_limits* limits) {
+	return {{.NameNative}}_unmarshal_fields(o, data, datalen, limits, 0);
+}
+
+size_t {{.NameNative}}_unmarshal_serial({{.NameNative}}* o, const void* data, size_t datalen) {
+	colfer_limits limits = colfer_defaults();
+	return {{.NameNative}}_unmarshal_fields(o, data, datalen, &limits, 1);
+}
+{{- end}}
+
+size_t {{.NameNative}}_unmarshal({{.NameNative}}* o, const void* data, size_t datalen) {
+	colfer_limits limits = colfer_defaults();
+	return {{.NameNative}}_unmarshal_with(o, data, datalen, &limits);
+}
 {{end}}{{end}}`
+
+const cMapMarshalLen = `
+	{
+		size_t n = o->{{.NameNative}}.len;
+		if (n) {
+			if (n > {{or .ListMax "limits->list_max"}}) {
+				errno = EFBIG;
+				return 0;
+			}
+			const {{.TypeKeyNative}}* keys = o->{{.NameNative}}.keys;
+			const {{if .TypeRef}}{{.TypeRef.NameNative}}{{else}}{{.TypeNative}}{{end}}* values = o->{{.NameNative}}.values;
+			for (size_t i = 0; i < n; ++i) {
+{{- if eq .TypeKey "text"}}
+				size_t len = keys[i].len;
+				if (len > {{or .SizeMax "limits->size_max"}}) {
+					errno = EFBIG;
+					return 0;
+				}
+				if (i) {
+					size_t prev = keys[i - 1].len;
+					int c = memcmp(keys[i - 1].utf8, keys[i].utf8, prev < len ? prev : len);
+					if (c > 0 || (c == 0 && prev >= len)) {
+						errno = EINVAL;
+						return 0;
+					}
+				}
+				for (l += len + 1; len > 127; len >>= 7, ++l);
+{{- else}}
+				if (i && keys[i - 1] >= keys[i]) {
+					errno = EINVAL;
+					return 0;
+				}
+ {{- if eq .TypeKey "uint8"}}
+				++l;
+ {{- else}}
+{{- if eq .TypeKey "int32"}}
+				uint64_t k = (uint32_t) keys[i] << 1;
+				if (keys[i] < 0) k ^= UINT32_MAX;
+{{- else if eq .TypeKey "int64"}}
+				uint64_t k = (uint64_t) keys[i] << 1;
+				if (keys[i] < 0) k = ~k;
+{{- else}}
+				uint64_t k = keys[i];
+{{- end}}
+				for (int m = 8; k > 127 && m; --m, k >>= 7) ++l;
+				++l;
+ {{- end}}
+{{- end}}
+
+{{- if eq .Type "bool" "uint8"}}
+				++l;
+{{- else if eq .Type "uint16" "uint32" "uint64" "int32" "int64"}}
+{{- if eq .Type "int32"}}
+				uint64_t v = (uint32_t) values[i] << 1;
+				if (values[i] < 0) v ^= UINT32_MAX;
+{{- else if eq .Type "int64"}}
+				uint64_t v = (uint64_t) values[i] << 1;
+				if (values[i] < 0) v = ~v;
+{{- else}}
+				uint64_t v = values[i];
+{{- end}}
+				for (int m = 8; v > 127 && m; --m, v >>= 7) ++l;
+				++l;
+{{- else if eq .Type "float32"}}
+				l += 4;
+{{- else if eq .Type "float64"}}
+				l += 8;
+{{- else if eq .Type "text" "binary"}}
+				size_t size = values[i].len;
+				if (size > {{or .SizeMax "limits->size_max"}}) {
+					errno = EFBIG;
+					return 0;
+				}
+				for (l += size + 1; size > 127; size >>= 7, ++l);
+{{- else}}
+{{- if eq .TypeRef.String .Struct.String}}
+				if (&values[i] == o) {
+					// reference cycle
+					errno = ELOOP;
+					return 0;
+				}
+{{- end}}
+				size_t size = {{.TypeRef.NameNative}}_marshal_len_with(&values[i], &nested);
+				if (!size) return 0;
+				l += size;
+{{- end}}
+			}
+			for (l += 2; n > 127; n >>= 7, ++l);
+			if (l > limits->size_max) {
+				errno = EFBIG;
+				return 0;
+			}
+		}
+	}
+`
+
+const cMapMarshal = `
+	{
+		size_t n = o->{{.NameNative}}.len;
+		if (n) {
+			*p++ = {{.Index}};
+
+			uint_fast32_t x = n;
+			for (; x >= 128; x >>= 7) *p++ = x | 128;
+			*p++ = x;
+
+			const {{.TypeKeyNative}}* keys = o->{{.NameNative}}.keys;
+			const {{if .TypeRef}}{{.TypeRef.NameNative}}{{else}}{{.TypeNative}}{{end}}* values = o->{{.NameNative}}.values;
+			for (size_t i = 0; i < n; ++i) {
+{{- if eq .TypeKey "text"}}
+				size_t len = keys[i].len;
+				for (x = len; x >= 128; x >>= 7) *p++ = x | 128;
+				*p++ = x;
+				memcpy(p, keys[i].utf8, len);
+				p += len;
+{{- else if eq .TypeKey "uint8"}}
+				*p++ = keys[i];
+{{- else}}
+{{- if eq .TypeKey "int32"}}
+				uint64_t k = (uint32_t) keys[i] << 1;
+				if (keys[i] < 0) k ^= UINT32_MAX;
+{{- else if eq .TypeKey "int64"}}
+				uint64_t k = (uint64_t) keys[i] << 1;
+				if (keys[i] < 0) k = ~k;
+{{- else}}
+				uint64_t k = keys[i];
+{{- end}}
+				for (int m = 8; k >= 128 && m; --m, k >>= 7) *p++ = k | 128;
+				*p++ = k;
+{{- end}}
+
+{{- if eq .Type "bool"}}
+				*p++ = values[i] != 0;
+{{- else if eq .Type "uint8"}}
+				*p++ = values[i];
+{{- else if eq .Type "uint16" "uint32" "uint64" "int32" "int64"}}
+{{- if eq .Type "int32"}}
+				uint64_t v = (uint32_t) values[i] << 1;
+				if (values[i] < 0) v ^= UINT32_MAX;
+{{- else if eq .Type "int64"}}
+				uint64_t v = (uint64_t) values[i] << 1;
+				if (values[i] < 0) v = ~v;
+{{- else}}
+				uint64_t v = values[i];
+{{- end}}
+				for (int m = 8; v >= 128 && m; --m, v >>= 7) *p++ = v | 128;
+				*p++ = v;
+{{- else if eq .Type "float32"}}
+				uint_fast32_t v;
+				memcpy(&v, &values[i], 4);
+				*p++ = v >> 24;
+				*p++ = v >> 16;
+				*p++ = v >> 8;
+				*p++ = v;
+{{- else if eq .Type "float64"}}
+				uint_fast64_t v;
+				memcpy(&v, &values[i], 8);
+				*p++ = v >> 56;
+				*p++ = v >> 48;
+				*p++ = v >> 40;
+				*p++ = v >> 32;
+				*p++ = v >> 24;
+				*p++ = v >> 16;
+				*p++ = v >> 8;
+				*p++ = v;
+{{- else if eq .Type "text" "binary"}}
+				size_t size = values[i].len;
+				for (x = size; x >= 128; x >>= 7) *p++ = x | 128;
+				*p++ = x;
+				memcpy(p, values[i].{{if eq .Type "text"}}utf8{{else}}octets{{end}}, size);
+				p += size;
+{{- else}}
+				p += {{.TypeRef.NameNative}}_marshal(&values[i], p);
+{{- end}}
+			}
+		}
+	}
+`
+
+const cMapUnmarshal = `
+	if (header == {{.Index}}) {
+		if (p >= end) {
+			errno = enderr;
+			return 0;
+		}
+		size_t n = *p++;
+		if (n > 127) {
+			n &= 127;
+			for (size_t shift = 7; ; shift += 7) {
+				if (shift >= sizeof(size_t) * CHAR_BIT) {
+					errno = EILSEQ;
+					return 0;
+				}
+				if (p >= end) {
+					errno = enderr;
+					return 0;
+				}
+				size_t c = *p++;
+				if (c <= 127) {
+					n |= c << shift;
+					break;
+				}
+				n |= (c & 127) << shift;
+			}
+		}
+		if (n > {{or .ListMax "limits->list_max"}}) {
+			errno = EFBIG;
+			return 0;
+		}
+		if (p+n*2 >= end) {
+			errno = enderr;
+			return 0;
+		}
+
+		{{.TypeKeyNative}}* keys = calloc(n, sizeof({{.TypeKeyNative}}));
+		{{if .TypeRef}}{{.TypeRef.NameNative}}{{else}}{{.TypeNative}}{{end}}* values = calloc(n, sizeof({{if .TypeRef}}{{.TypeRef.NameNative}}{{else}}{{.TypeNative}}{{end}}));
+		o->{{.NameNative}}.len = n;
+		o->{{.NameNative}}.keys = keys;
+		o->{{.NameNative}}.values = values;
+		for (size_t i = 0; i < n; ++i) {
+			{
+{{- if eq .TypeKey "text"}}
+				if (p >= end) {
+					errno = enderr;
+					return 0;
+				}
+				size_t size = *p++;
+				if (size > 127) {
+					size &= 127;
+					for (size_t shift = 7; ; shift += 7) {
+						if (shift >= sizeof(size_t) * CHAR_BIT) {
+							errno = EILSEQ;
+							return 0;
+						}
+						if (p >= end) {
+							errno = enderr;
+							return 0;
+						}
+						size_t c = *p++;
+						if (c <= 127) {
+							size |= c << shift;
+							break;
+						}
+						size |= (c & 127) << shift;
+					}
+				}
+				if (size > {{or .SizeMax "limits->size_max"}}) {
+					errno = EFBIG;
+					return 0;
+				}
+				if (p+size >= end) {
+					errno = enderr;
+					return 0;
+				}
+				if (i) {
+					size_t prev = keys[i - 1].len;
+					int c = memcmp(keys[i - 1].utf8, p, prev < size ? prev : size);
+					if (c > 0 || (c == 0 && prev >= size)) {
+						errno = EILSEQ;
+						return 0;
+					}
+				}
+				char* a = malloc(size);
+				if (size) memcpy(a, p, size);
+				p += size;
+				keys[i].utf8 = a;
+				keys[i].len = size;
+{{- else}}
+ {{- if eq .TypeKey "uint8"}}
+				if (p >= end) {
+					errno = enderr;
+					return 0;
+				}
+				keys[i] = *p++;
+ {{- else}}
+				if (p >= end) {
+					errno = enderr;
+					return 0;
+				}
+				uint_fast64_t x = *p++;
+				if (x > 127) {
+					x &= 127;
+					for (int shift = 7; ; shift += 7) {
+						if (p >= end) {
+							errno = enderr;
+							return 0;
+						}
+						uint_fast64_t b = *p++;
+						if (b <= 127 || shift == 56) {
+							x |= b << shift;
+							break;
+						}
+						x |= (b & 127) << shift;
+					}
+				}
+  {{- if eq .TypeKey "int32"}}
+				uint32_t u = x;
+				keys[i] = (u >> 1) ^ (0 - (u & 1));
+  {{- else if eq .TypeKey "int64"}}
+				keys[i] = (x >> 1) ^ (0 - (x & 1));
+  {{- else}}
+				keys[i] = x;
+  {{- end}}
+ {{- end}}
+				if (i && keys[i - 1] >= keys[i]) {
+					errno = EILSEQ;
+					return 0;
+				}
+{{- end}}
+			}
+
+{{- if eq .Type "bool" "uint8"}}
+			if (p >= end) {
+				errno = enderr;
+				return 0;
+			}
+			values[i] = {{if eq .Type "bool"}}*p++ != 0{{else}}*p++{{end}};
+{{- else if eq .Type "uint16" "uint32" "uint64" "int32" "int64"}}
+			{
+				if (p >= end) {
+					errno = enderr;
+					return 0;
+				}
+				uint_fast64_t x = *p++;
+				if (x > 127) {
+					x &= 127;
+					for (int shift = 7; ; shift += 7) {
+						if (p >= end) {
+							errno = enderr;
+							return 0;
+						}
+						uint_fast64_t b = *p++;
+						if (b <= 127 || shift == 56) {
+							x |= b << shift;
+							break;
+						}
+						x |= (b & 127) << shift;
+					}
+				}
+  {{- if eq .Type "int32"}}
+				uint32_t u = x;
+				values[i] = (u >> 1) ^ (0 - (u & 1));
+  {{- else if eq .Type "int64"}}
+				values[i] = (x >> 1) ^ (0 - (x & 1));
+  {{- else}}
+				values[i] = x;
+  {{- end}}
+			}
+{{- else if eq .Type "float32"}}
+			if (p+4 > end) {
+				errno = enderr;
+				return 0;
+			}
+			{
+				uint_fast32_t x = *p++;
+				x <<= 24;
+				x |= (uint_fast32_t) *p++ << 16;
+				x |= (uint_fast32_t) *p++ << 8;
+				x |= (uint_fast32_t) *p++;
+				memcpy(&values[i], &x, 4);
+			}
+{{- else if eq .Type "float64"}}
+			if (p+8 > end) {
+				errno = enderr;
+				return 0;
+			}
+			{
+				uint_fast64_t x = *p++;
+				x <<= 56;
+				x |= (uint_fast64_t) *p++ << 48;
+				x |= (uint_fast64_t) *p++ << 40;
+				x |= (uint_fast64_t) *p++ << 32;
+				x |= (uint_fast64_t) *p++ << 24;
+				x |= (uint_fast64_t) *p++ << 16;
+				x |= (uint_fast64_t) *p++ << 8;
+				x |= (uint_fast64_t) *p++;
+				memcpy(&values[i], &x, 8);
+			}
+{{- else if eq .Type "text" "binary"}}
+			{
+				if (p >= end) {
+					errno = enderr;
+					return 0;
+				}
+				size_t size = *p++;
+				if (size > 127) {
+					size &= 127;
+					for (size_t shift = 7; ; shift += 7) {
+						if (shift >= sizeof(size_t) * CHAR_BIT) {
+							errno = EILSEQ;
+							return 0;
+						}
+						if (p >= end) {
+							errno = enderr;
+							return 0;
+						}
+						size_t c = *p++;
+						if (c <= 127) {
+							size |= c << shift;
+							break;
+						}
+						size |= (c & 127) << shift;
+					}
+				}
+				if (size > {{or .SizeMax "limits->size_max"}}) {
+					errno = EFBIG;
+					return 0;
+				}
+				if (p+size >= end) {
+					errno = enderr;
+					return 0;
+				}
+				{{if eq .Type "text"}}char{{else}}uint8_t{{end}}* a = malloc(size);
+				if (size) memcpy(a, p, size);
+				p += size;
+				values[i].{{if eq .Type "text"}}utf8{{else}}octets{{end}} = a;
+				values[i].len = size;
+			}
+{{- else}}
+			size_t read = {{.TypeRef.NameNative}}_unmarshal_with(&values[i], p, (size_t) (end - p), &nested);
+			if (!read) {
+				if (errno == EWOULDBLOCK) errno = enderr;
+				return read;
+			}
+			p += read;
+{{- end}}
+		}
+
+		if (p >= end) {
+			errno = enderr;
+			return 0;
+		}
+		header = *p++;
+	}
+`
@@ -19,10 +19,27 @@
 
 size_t colfer_size_max = 16 * 1024 * 1024;
 size_t colfer_list_max = 64 * 1024;
+size_t colfer_depth_max = 100;
 
+colfer_limits colfer_defaults(void) {
+	colfer_limits limits = {colfer_size_max, colfer_list_max, colfer_depth_max};
+	return limits;
+}
 
 
 size_t gen_o_marshal_len(const gen_o* o) {
+	colfer_limits limits = colfer_defaults();
+	return gen_o_marshal_len_with(o, &limits);
+}
+
+size_t gen_o_marshal_len_with(const gen_o* o, const colfer_limits* limits) {
+	if (!limits->depth_max) {
+		errno = ELOOP;
+		return 0;
+	}
+	// nested data structures are one level deeper
+	colfer_limits nested = *limits;
+	--nested.depth_max;
 	size_t l = 1;
 
 	if (o->b) l++;
@@ -81,7 +98,7 @@ size_t gen_o_marshal_len(const gen_o* o) {
 
 	{
 		size_t n = o->s.len;
-		if (n > colfer_size_max) {
+		if (n > limits->size_max) {
 			errno = EFBIG;
 			return 0;
 		}
@@ -90,7 +107,7 @@ size_t gen_o_marshal_len(const gen_o* o) {
 
 	{
 		size_t n = o->a.len;
-		if (n > colfer_size_max) {
+		if (n > limits->size_max) {
 			errno = EFBIG;
 			return 0;
 		}
@@ -98,20 +115,38 @@ size_t gen_o_marshal_len(const gen_o* o) {
 	}
 
 	{
-		if (o->o) l += 1 + gen_o_marshal_len(o->o);
+		if (o->o) {
+			if (o->o == o) {
+				// reference cycle
+				errno = ELOOP;
+				return 0;
+			}
+			size_t size = gen_o_marshal_len_with(o->o, &nested);
+			if (!size) return 0;
+			l += 1 + size;
+		}
 	}
 
 	{
 		size_t n = o->os.len;
 		if (n) {
-			if (n > colfer_list_max) {
+			if (n > limits->list_max) {
 				errno = EFBIG;
 				return 0;
 			}
 			gen_o* a = o->os.list;
-			for (size_t i = 0; i < n; ++i) l += gen_o_marshal_len(&a[i]);
+			for (size_t i = 0; i < n; ++i) {
+				if (&a[i] == o) {
+					// reference cycle
+					errno = ELOOP;
+					return 0;
+				}
+				size_t size = gen_o_marshal_len_with(&a[i], &nested);
+				if (!size) return 0;
+				l += size;
+			}
 			for (l += 2; n > 127; n >>= 7, ++l);
-			if (l > colfer_size_max) {
+			if (l > limits->size_max) {
 				errno = EFBIG;
 				return 0;
 			}
@@ -121,21 +156,21 @@ size_t gen_o_marshal_len(const gen_o* o) {
 	{
 		size_t n = o->ss.len;
 		if (n) {
-			if (n > colfer_list_max) {
+			if (n > limits->list_max) {
 				errno = EFBIG;
 				return 0;
 			}
 			colfer_text* a = o->ss.list;
 			for (size_t i = 0; i < n; ++i) {
 				size_t len = a[i].len;
-				if (len > colfer_size_max) {
+				if (len > limits->size_max) {
 					errno = EFBIG;
 					return 0;
 				}
 				for (l += len + 1; len > 127; len >>= 7, ++l);
 			}
 			for (l += 2; n > 127; n >>= 7, ++l);
-			if (l > colfer_size_max) {
+			if (l > limits->size_max) {
 				errno = EFBIG;
 				return 0;
 			}
@@ -145,21 +180,21 @@ size_t gen_o_marshal_len(const gen_o* o) {
 	{
 		size_t n = o->as.len;
 		if (n) {
-			if (n > colfer_list_max) {
+			if (n > limits->list_max) {
 				errno = EFBIG;
 				return 0;
 			}
 			colfer_binary* a = o->as.list;
 			for (size_t i = 0; i < n; ++i) {
 				size_t len = a[i].len;
-				if (len > colfer_size_max) {
+				if (len > limits->size_max) {
 					errno = EFBIG;
 					return 0;
 				}
 				for (l += len + 1; len > 127; len >>= 7, ++l);
 			}
 			for (l += 2; n > 127; n >>= 7, ++l);
-			if (l > colfer_size_max) {
+			if (l > limits->size_max) {
 				errno = EFBIG;
 				return 0;
 			}
@@ -176,7 +211,7 @@ size_t gen_o_marshal_len(const gen_o* o) {
 	{
 		size_t n = o->f32s.len;
 		if (n) {
-			if (n > colfer_list_max) {
+			if (n > limits->list_max) {
 				errno = EFBIG;
 				return 0;
 			}
@@ -187,7 +222,7 @@ size_t gen_o_marshal_len(const gen_o* o) {
 	{
 		size_t n = o->f64s.len;
 		if (n) {
-			if (n > colfer_list_max) {
+			if (n > limits->list_max) {
 				errno = EFBIG;
 				return 0;
 			}
@@ -195,7 +230,379 @@ size_t gen_o_marshal_len(const gen_o* o) {
 		}
 	}
 
-	if (l > colfer_size_max) {
+	if (o->e) l += 2;
+
+	{
+		size_t n = o->bs.len;
+		if (n) {
+			if (n > limits->list_max) {
+				errno = EFBIG;
+				return 0;
+			}
+			for (l += n + 2; n > 127; n >>= 7, ++l);
+		}
+	}
+
+	{
+		size_t n = o->u8s.len;
+		if (n) {
+			if (n > limits->list_max) {
+				errno = EFBIG;
+				return 0;
+			}
+			for (l += n + 2; n > 127; n >>= 7, ++l);
+		}
+	}
+
+	{
+		size_t n = o->u16s.len;
+		if (n) {
+			if (n > limits->list_max) {
+				errno = EFBIG;
+				return 0;
+			}
+			const uint16_t* a = o->u16s.list;
+			for (size_t i = 0; i < n; ++i) {
+				uint64_t v = a[i];
+				for (int m = 8; v > 127 && m; --m, v >>= 7) ++l;
+				++l;
+			}
+			for (l += 2; n > 127; n >>= 7, ++l);
+			if (l > limits->size_max) {
+				errno = EFBIG;
+				return 0;
+			}
+		}
+	}
+
+	{
+		size_t n = o->u32s.len;
+		if (n) {
+			if (n > limits->list_max) {
+				errno = EFBIG;
+				return 0;
+			}
+			const uint32_t* a = o->u32s.list;
+			for (size_t i = 0; i < n; ++i) {
+				uint64_t v = a[i];
+				for (int m = 8; v > 127 && m; --m, v >>= 7) ++l;
+				++l;
+			}
+			for (l += 2; n > 127; n >>= 7, ++l);
+			if (l > limits->size_max) {
+				errno = EFBIG;
+				return 0;
+			}
+		}
+	}
+
+	{
+		size_t n = o->u64s.len;
+		if (n) {
+			if (n > limits->list_max) {
+				errno = EFBIG;
+				return 0;
+			}
+			const uint64_t* a = o->u64s.list;
+			for (size_t i = 0; i < n; ++i) {
+				uint64_t v = a[i];
+				for (int m = 8; v > 127 && m; --m, v >>= 7) ++l;
+				++l;
+			}
+			for (l += 2; n > 127; n >>= 7, ++l);
+			if (l > limits->size_max) {
+				errno = EFBIG;
+				return 0;
+			}
+		}
+	}
+
+	{
+		size_t n = o->i32s.len;
+		if (n) {
+			if (n > limits->list_max) {
+				errno = EFBIG;
+				return 0;
+			}
+			const int32_t* a = o->i32s.list;
+			for (size_t i = 0; i < n; ++i) {
+				uint64_t v = (uint32_t) a[i] << 1;
+				if (a[i] < 0) v ^= UINT32_MAX;
+				for (int m = 8; v > 127 && m; --m, v >>= 7) ++l;
+				++l;
+			}
+			for (l += 2; n > 127; n >>= 7, ++l);
+			if (l > limits->size_max) {
+				errno = EFBIG;
+				return 0;
+			}
+		}
+	}
+
+	{
+		size_t n = o->i64s.len;
+		if (n) {
+			if (n > limits->list_max) {
+				errno = EFBIG;
+				return 0;
+			}
+			const int64_t* a = o->i64s.list;
+			for (size_t i = 0; i < n; ++i) {
+				uint64_t v = (uint64_t) a[i] << 1;
+				if (a[i] < 0) v = ~v;
+				for (int m = 8; v > 127 && m; --m, v >>= 7) ++l;
+				++l;
+			}
+			for (l += 2; n > 127; n >>= 7, ++l);
+			if (l > limits->size_max) {
+				errno = EFBIG;
+				return 0;
+			}
+		}
+	}
+
+	{
+		size_t n = o->es.len;
+		if (n) {
+			if (n > limits->list_max) {
+				errno = EFBIG;
+				return 0;
+			}
+			for (l += n + 2; n > 127; n >>= 7, ++l);
+		}
+	}
+
+	{
+		size_t n = o->mt.len;
+		if (n) {
+			if (n > limits->list_max) {
+				errno = EFBIG;
+				return 0;
+			}
+			const colfer_text* keys = o->mt.keys;
+			const colfer_text* values = o->mt.values;
+			for (size_t i = 0; i < n; ++i) {
+				size_t len = keys[i].len;
+				if (len > limits->size_max) {
+					errno = EFBIG;
+					return 0;
+				}
+				if (i) {
+					size_t prev = keys[i - 1].len;
+					int c = memcmp(keys[i - 1].utf8, keys[i].utf8, prev < len ? prev : len);
+					if (c > 0 || (c == 0 && prev >= len)) {
+						errno = EINVAL;
+						return 0;
+					}
+				}
+				for (l += len + 1; len > 127; len >>= 7, ++l);
+				size_t size = values[i].len;
+				if (size > limits->size_max) {
+					errno = EFBIG;
+					return 0;
+				}
+				for (l += size + 1; size > 127; size >>= 7, ++l);
+			}
+			for (l += 2; n > 127; n >>= 7, ++l);
+			if (l > limits->size_max) {
+				errno = EFBIG;
+				return 0;
+			}
+		}
+	}
+
+	{
+		size_t n = o->mu.len;
+		if (n) {
+			if (n > limits->list_max) {
+				errno = EFBIG;
+				return 0;
+			}
+			const uint32_t* keys = o->mu.keys;
+			const int64_t* values = o->mu.values;
+			for (size_t i = 0; i < n; ++i) {
+				if (i && keys[i - 1] >= keys[i]) {
+					errno = EINVAL;
+					return 0;
+				}
+				uint64_t k = keys[i];
+				for (int m = 8; k > 127 && m; --m, k >>= 7) ++l;
+				++l;
+				uint64_t v = (uint64_t) values[i] << 1;
+				if (values[i] < 0) v = ~v;
+				for (int m = 8; v > 127 && m; --m, v >>= 7) ++l;
+				++l;
+			}
+			for (l += 2; n > 127; n >>= 7, ++l);
+			if (l > limits->size_max) {
+				errno = EFBIG;
+				return 0;
+			}
+		}
+	}
+
+	{
+		size_t n = o->mi.len;
+		if (n) {
+			if (n > limits->list_max) {
+				errno = EFBIG;
+				return 0;
+			}
+			const int64_t* keys = o->mi.keys;
+			const colfer_binary* values = o->mi.values;
+			for (size_t i = 0; i < n; ++i) {
+				if (i && keys[i - 1] >= keys[i]) {
+					errno = EINVAL;
+					return 0;
+				}
+				uint64_t k = (uint64_t) keys[i] << 1;
+				if (keys[i] < 0) k = ~k;
+				for (int m = 8; k > 127 && m; --m, k >>= 7) ++l;
+				++l;
+				size_t size = values[i].len;
+				if (size > limits->size_max) {
+					errno = EFBIG;
+					return 0;
+				}
+				for (l += size + 1; size > 127; size >>= 7, ++l);
+			}
+			for (l += 2; n > 127; n >>= 7, ++l);
+			if (l > limits->size_max) {
+				errno = EFBIG;
+				return 0;
+			}
+		}
+	}
+
+	{
+		size_t n = o->mo.len;
+		if (n) {
+			if (n > limits->list_max) {
+				errno = EFBIG;
+				return 0;
+			}
+			const colfer_text* keys = o->mo.keys;
+			const gen_o* values = o->mo.values;
+			for (size_t i = 0; i < n; ++i) {
+				size_t len = keys[i].len;
+				if (len > limits->size_max) {
+					errno = EFBIG;
+					return 0;
+				}
+				if (i) {
+					size_t prev = keys[i - 1].len;
+					int c = memcmp(keys[i - 1].utf8, keys[i].utf8, prev < len ? prev : len);
+					if (c > 0 || (c == 0 && prev >= len)) {
+						errno = EINVAL;
+						return 0;
+					}
+				}
+				for (l += len + 1; len > 127; len >>= 7, ++l);
+				if (&values[i] == o) {
+					// reference cycle
+					errno = ELOOP;
+					return 0;
+				}
+				size_t size = gen_o_marshal_len_with(&values[i], &nested);
+				if (!size) return 0;
+				l += size;
+			}
+			for (l += 2; n > 127; n >>= 7, ++l);
+			if (l > limits->size_max) {
+				errno = EFBIG;
+				return 0;
+			}
+		}
+	}
+
+	if (o->gap) l++;
+
+	{
+		size_t n = o->host.len;
+		if (n > 4) {
+			errno = EFBIG;
+			return 0;
+		}
+		if (n) for (l += 2 + n; n > 127; n >>= 7, ++l);
+	}
+
+	{
+		size_t n = o->tags.len;
+		if (n) {
+			if (n > 2) {
+				errno = EFBIG;
+				return 0;
+			}
+			colfer_text* a = o->tags.list;
+			for (size_t i = 0; i < n; ++i) {
+				size_t len = a[i].len;
+				if (len > 1) {
+					errno = EFBIG;
+					return 0;
+				}
+				for (l += len + 1; len > 127; len >>= 7, ++l);
+			}
+			for (l += 2; n > 127; n >>= 7, ++l);
+			if (l > limits->size_max) {
+				errno = EFBIG;
+				return 0;
+			}
+		}
+	}
+
+	if (o->has_ob) l++;
+
+	{
+		uint_fast32_t x = o->ou32;
+		if (o->has_ou32) {
+			if (x >= (uint_fast32_t) 1 << 21) l += 5;
+			else for (l += 2; x > 127; x >>= 7, ++l);
+		}
+	}
+
+	{
+		uint_fast64_t x = o->oi64;
+		if (o->has_oi64) {
+			if (x & (uint_fast64_t) 1 << 63) {
+				x = ~x;
+				++x;
+			}
+			size_t max = l + 10;
+			for (l += 2; x > 127 && l < max; x >>= 7, ++l);
+		}
+	}
+
+	if (o->has_of64) l += 9;
+
+	switch (o->u.type) {
+	case GEN_PICK_O:
+		if (o->u.o) {
+			if (o->u.o == o) {
+				// reference cycle
+				errno = ELOOP;
+				return 0;
+			}
+			size_t size = gen_o_marshal_len_with(o->u.o, &nested);
+			if (!size) return 0;
+			l += 2 + size;
+		} else {
+			l += 3;
+		}
+		break;
+	case GEN_PICK_POINT:
+		if (o->u.point) {
+			size_t size = gen_point_marshal_len_with(o->u.point, &nested);
+			if (!size) return 0;
+			l += 2 + size;
+		} else {
+			l += 3;
+		}
+		break;
+	default:
+		break;
+	}
+
+	if (l > limits->size_max) {
 		errno = EFBIG;
 		return 0;
 	}
@@ -532,25 +939,384 @@ size_t gen_o_marshal(const gen_o* o, void* buf) {
 		}
 	}
 
-	*p++ = 127;
-
-	return p - (uint8_t*) buf;
-}
+	if (o->e) {
+		*p++ = 18;
 
-size_t gen_o_unmarshal(gen_o* o, const void* data, size_t datalen) {
-	// octet pointer navigation
-	const uint8_t* p = data;
-	const uint8_t* end;
-	int enderr;
-	if (datalen < colfer_size_max) {
-		end = p + datalen;
-		enderr = EWOULDBLOCK;
-	} else {
-		end = p + colfer_size_max;
-		enderr = EFBIG;
+		*p++ = o->e;
 	}
 
-	if (p >= end) {
+	{
+		size_t n = o->bs.len;
+		if (n) {
+			*p++ = 19;
+
+			uint_fast32_t x = n;
+			for (; x >= 128; x >>= 7) *p++ = x | 128;
+			*p++ = x;
+
+			const char* a = o->bs.list;
+			for (size_t i = 0; i < n; ++i) *p++ = a[i] != 0;
+		}
+	}
+
+	{
+		size_t n = o->u8s.len;
+		if (n) {
+			*p++ = 20;
+
+			uint_fast32_t x = n;
+			for (; x >= 128; x >>= 7) *p++ = x | 128;
+			*p++ = x;
+
+			memcpy(p, o->u8s.list, n);
+			p += n;
+		}
+	}
+
+	{
+		size_t n = o->u16s.len;
+		if (n) {
+			*p++ = 21;
+
+			uint_fast32_t x = n;
+			for (; x >= 128; x >>= 7) *p++ = x | 128;
+			*p++ = x;
+
+			const uint16_t* a = o->u16s.list;
+			for (size_t i = 0; i < n; ++i) {
+				uint64_t v = a[i];
+				for (int m = 8; v >= 128 && m; --m, v >>= 7) *p++ = v | 128;
+				*p++ = v;
+			}
+		}
+	}
+
+	{
+		size_t n = o->u32s.len;
+		if (n) {
+			*p++ = 22;
+
+			uint_fast32_t x = n;
+			for (; x >= 128; x >>= 7) *p++ = x | 128;
+			*p++ = x;
+
+			const uint32_t* a = o->u32s.list;
+			for (size_t i = 0; i < n; ++i) {
+				uint64_t v = a[i];
+				for (int m = 8; v >= 128 && m; --m, v >>= 7) *p++ = v | 128;
+				*p++ = v;
+			}
+		}
+	}
+
+	{
+		size_t n = o->u64s.len;
+		if (n) {
+			*p++ = 23;
+
+			uint_fast32_t x = n;
+			for (; x >= 128; x >>= 7) *p++ = x | 128;
+			*p++ = x;
+
+			const uint64_t* a = o->u64s.list;
+			for (size_t i = 0; i < n; ++i) {
+				uint64_t v = a[i];
+				for (int m = 8; v >= 128 && m; --m, v >>= 7) *p++ = v | 128;
+				*p++ = v;
+			}
+		}
+	}
+
+	{
+		size_t n = o->i32s.len;
+		if (n) {
+			*p++ = 24;
+
+			uint_fast32_t x = n;
+			for (; x >= 128; x >>= 7) *p++ = x | 128;
+			*p++ = x;
+
+			const int32_t* a = o->i32s.list;
+			for (size_t i = 0; i < n; ++i) {
+				uint64_t v = (uint32_t) a[i] << 1;
+				if (a[i] < 0) v ^= UINT32_MAX;
+				for (int m = 8; v >= 128 && m; --m, v >>= 7) *p++ = v | 128;
+				*p++ = v;
+			}
+		}
+	}
+
+	{
+		size_t n = o->i64s.len;
+		if (n) {
+			*p++ = 25;
+
+			uint_fast32_t x = n;
+			for (; x >= 128; x >>= 7) *p++ = x | 128;
+			*p++ = x;
+
+			const int64_t* a = o->i64s.list;
+			for (size_t i = 0; i < n; ++i) {
+				uint64_t v = (uint64_t) a[i] << 1;
+				if (a[i] < 0) v = ~v;
+				for (int m = 8; v >= 128 && m; --m, v >>= 7) *p++ = v | 128;
+				*p++ = v;
+			}
+		}
+	}
+
+	{
+		size_t n = o->es.len;
+		if (n) {
+			*p++ = 26;
+
+			uint_fast32_t x = n;
+			for (; x >= 128; x >>= 7) *p++ = x | 128;
+			*p++ = x;
+
+			memcpy(p, o->es.list, n);
+			p += n;
+		}
+	}
+
+	{
+		size_t n = o->mt.len;
+		if (n) {
+			*p++ = 27;
+
+			uint_fast32_t x = n;
+			for (; x >= 128; x >>= 7) *p++ = x | 128;
+			*p++ = x;
+
+			const colfer_text* keys = o->mt.keys;
+			const colfer_text* values = o->mt.values;
+			for (size_t i = 0; i < n; ++i) {
+				size_t len = keys[i].len;
+				for (x = len; x >= 128; x >>= 7) *p++ = x | 128;
+				*p++ = x;
+				memcpy(p, keys[i].utf8, len);
+				p += len;
+				size_t size = values[i].len;
+				for (x = size; x >= 128; x >>= 7) *p++ = x | 128;
+				*p++ = x;
+				memcpy(p, values[i].utf8, size);
+				p += size;
+			}
+		}
+	}
+
+	{
+		size_t n = o->mu.len;
+		if (n) {
+			*p++ = 28;
+
+			uint_fast32_t x = n;
+			for (; x >= 128; x >>= 7) *p++ = x | 128;
+			*p++ = x;
+
+			const uint32_t* keys = o->mu.keys;
+			const int64_t* values = o->mu.values;
+			for (size_t i = 0; i < n; ++i) {
+				uint64_t k = keys[i];
+				for (int m = 8; k >= 128 && m; --m, k >>= 7) *p++ = k | 128;
+				*p++ = k;
+				uint64_t v = (uint64_t) values[i] << 1;
+				if (values[i] < 0) v = ~v;
+				for (int m = 8; v >= 128 && m; --m, v >>= 7) *p++ = v | 128;
+				*p++ = v;
+			}
+		}
+	}
+
+	{
+		size_t n = o->mi.len;
+		if (n) {
+			*p++ = 29;
+
+			uint_fast32_t x = n;
+			for (; x >= 128; x >>= 7) *p++ = x | 128;
+			*p++ = x;
+
+			const int64_t* keys = o->mi.keys;
+			const colfer_binary* values = o->mi.values;
+			for (size_t i = 0; i < n; ++i) {
+				uint64_t k = (uint64_t) keys[i] << 1;
+				if (keys[i] < 0) k = ~k;
+				for (int m = 8; k >= 128 && m; --m, k >>= 7) *p++ = k | 128;
+				*p++ = k;
+				size_t size = values[i].len;
+				for (x = size; x >= 128; x >>= 7) *p++ = x | 128;
+				*p++ = x;
+				memcpy(p, values[i].octets, size);
+				p += size;
+			}
+		}
+	}
+
+	{
+		size_t n = o->mo.len;
+		if (n) {
+			*p++ = 30;
+
+			uint_fast32_t x = n;
+			for (; x >= 128; x >>= 7) *p++ = x | 128;
+			*p++ = x;
+
+			const colfer_text* keys = o->mo.keys;
+			const gen_o* values = o->mo.values;
+			for (size_t i = 0; i < n; ++i) {
+				size_t len = keys[i].len;
+				for (x = len; x >= 128; x >>= 7) *p++ = x | 128;
+				*p++ = x;
+				memcpy(p, keys[i].utf8, len);
+				p += len;
+				p += gen_o_marshal(&values[i], p);
+			}
+		}
+	}
+
+	if (o->gap) *p++ = 32;
+
+	{
+		size_t n = o->host.len;
+		if (n) {
+			*p++ = 33;
+
+			uint_fast32_t x = n;
+			for (; x >= 128; x >>= 7) *p++ = x | 128;
+			*p++ = x;
+
+			memcpy(p, o->host.utf8, n);
+			p += n;
+		}
+	}
+
+	{
+		size_t count = o->tags.len;
+		if (count) {
+			*p++ = 34;
+
+			uint_fast32_t x = count;
+			for (; x >= 128; x >>= 7) *p++ = x | 128;
+			*p++ = x;
+
+			colfer_text* text = o->tags.list;
+			do {
+				size_t n = text->len;
+				for (x = n; x >= 128; x >>= 7) *p++ = x | 128;
+				*p++ = x;
+
+				memcpy(p, text->utf8, n);
+				p += n;
+
+				++text;
+			} while (--count != 0);
+		}
+	}
+
+	if (o->has_ob) *p++ = o->ob ? 35 : 35 | 128;
+
+	{
+		uint_fast32_t x = o->ou32;
+		if (o->has_ou32) {
+			if (x < (uint_fast32_t) 1 << 21) {
+				*p++ = 36;
+				for (; x >= 128; x >>= 7) *p++ = x | 128;
+				*p++ = x;
+			} else {
+				*p++ = 36 | 128;
+#ifdef COLFER_ENDIAN
+				memcpy(p, &o->ou32, 4);
+				p += 4;
+#else
+				*p++ = x >> 24;
+				*p++ = x >> 16;
+				*p++ = x >> 8;
+				*p++ = x;
+#endif
+			}
+		}
+	}
+
+	{
+		uint_fast64_t x = o->oi64;
+		if (o->has_oi64) {
+			if (x & (uint_fast64_t) 1 << 63) {
+				*p++ = 37 | 128;
+				x = ~x + 1;
+			} else	*p++ = 37;
+
+			uint8_t* max = p + 8;
+			for (; x >= 128 && p < max; x >>= 7) *p++ = x | 128;
+			*p++ = x;
+		}
+	}
+
+	if (o->has_of64) {
+		*p++ = 38;
+
+#ifdef COLFER_ENDIAN
+		memcpy(p, &o->of64, 8);
+		p += 8;
+#else
+		uint_fast64_t x;
+		memcpy(&x, &o->of64, 8);
+		*p++ = x >> 56;
+		*p++ = x >> 48;
+		*p++ = x >> 40;
+		*p++ = x >> 32;
+		*p++ = x >> 24;
+		*p++ = x >> 16;
+		*p++ = x >> 8;
+		*p++ = x;
+#endif
+	}
+
+	switch (o->u.type) {
+	case GEN_PICK_O:
+		*p++ = 39;
+		*p++ = 0;
+
+		if (o->u.o) p += gen_o_marshal(o->u.o, p);
+		else *p++ = 127;
+		break;
+	case GEN_PICK_POINT:
+		*p++ = 39;
+		*p++ = 1;
+
+		if (o->u.point) p += gen_point_marshal(o->u.point, p);
+		else *p++ = 127;
+		break;
+	default:
+		break;
+	}
+
+	*p++ = 127;
+
+	return p - (uint8_t*) buf;
+}
+
+size_t gen_o_unmarshal_with(gen_o* o, const void* data, size_t datalen, const colfer_limits* limits) {
+	if (!limits->depth_max) {
+		errno = ELOOP;
+		return 0;
+	}
+	// nested data structures are one level deeper
+	colfer_limits nested = *limits;
+	--nested.depth_max;
+	// octet pointer navigation
+	const uint8_t* p = data;
+	const uint8_t* end;
+	int enderr;
+	if (datalen < limits->size_max) {
+		end = p + datalen;
+		enderr = EWOULDBLOCK;
+	} else {
+		end = p + limits->size_max;
+		enderr = EFBIG;
+	}
+
+	if (p >= end) {
 		errno = enderr;
 		return 0;
 	}
@@ -782,7 +1548,7 @@ size_t gen_o_unmarshal(gen_o* o, const void* data, size_t datalen) {
 		size_t n = *p++;
 		if (n > 127) {
 			n &= 127;
-			for (int shift = 7; shift < sizeof(size_t) * CHAR_BIT; shift += 7) {
+			for (size_t shift = 7; shift < sizeof(size_t) * CHAR_BIT; shift += 7) {
 				if (p >= end) {
 					errno = enderr;
 					return 0;
@@ -795,7 +1561,7 @@ size_t gen_o_unmarshal(gen_o* o, const void* data, size_t datalen) {
 				n |= (c & 127) << shift;
 			}
 		}
-		if (n > colfer_size_max) {
+		if (n > limits->size_max) {
 			errno = EFBIG;
 			return 0;
 		}
@@ -822,7 +1588,11 @@ size_t gen_o_unmarshal(gen_o* o, const void* data, size_t datalen) {
 		size_t n = *p++;
 		if (n > 127) {
 			n &= 127;
-			for (int shift = 7; ; shift += 7) {
+			for (size_t shift = 7; ; shift += 7) {
+				if (shift >= sizeof(size_t) * CHAR_BIT) {
+					errno = EILSEQ;
+					return 0;
+				}
 				if (p >= end) {
 					errno = enderr;
 					return 0;
@@ -835,7 +1605,7 @@ size_t gen_o_unmarshal(gen_o* o, const void* data, size_t datalen) {
 				n |= (c & 127) << shift;
 			}
 		}
-		if (n > colfer_size_max) {
+		if (n > limits->size_max) {
 			errno = EFBIG;
 			return 0;
 		}
@@ -856,7 +1626,7 @@ size_t gen_o_unmarshal(gen_o* o, const void* data, size_t datalen) {
 
 	if (header == 10) {
 		o->o = calloc(1, sizeof(gen_o));
-		size_t read = gen_o_unmarshal(o->o, p, (size_t) (end - p));
+		size_t read = gen_o_unmarshal_with(o->o, p, (size_t) (end - p), &nested);
 		if (!read) {
 			if (errno == EWOULDBLOCK) errno = enderr;
 			return read;
@@ -878,7 +1648,11 @@ size_t gen_o_unmarshal(gen_o* o, const void* data, size_t datalen) {
 		size_t n = *p++;
 		if (n > 127) {
 			n &= 127;
-			for (int shift = 7; ; shift += 7) {
+			for (size_t shift = 7; ; shift += 7) {
+				if (shift >= sizeof(size_t) * CHAR_BIT) {
+					errno = EILSEQ;
+					return 0;
+				}
 				if (p >= end) {
 					errno = enderr;
 					return 0;
@@ -891,14 +1665,14 @@ size_t gen_o_unmarshal(gen_o* o, const void* data, size_t datalen) {
 				n |= (c & 127) << shift;
 			}
 		}
-		if (n > colfer_list_max) {
+		if (n > limits->list_max) {
 			errno = EFBIG;
 			return 0;
 		}
 
 		gen_o* a = calloc(n, sizeof(gen_o));
 		for (size_t i = 0; i < n; ++i) {
-			size_t read = gen_o_unmarshal(&a[i], p, (size_t) (end - p));
+			size_t read = gen_o_unmarshal_with(&a[i], p, (size_t) (end - p), &nested);
 			if (!read) {
 				if (errno == EWOULDBLOCK) errno = enderr;
 				return read;
@@ -923,7 +1697,11 @@ size_t gen_o_unmarshal(gen_o* o, const void* data, size_t datalen) {
 		size_t n = *p++;
 		if (n > 127) {
 			n &= 127;
-			for (int shift = 7; ; shift += 7) {
+			for (size_t shift = 7; ; shift += 7) {
+				if (shift >= sizeof(size_t) * CHAR_BIT) {
+					errno = EILSEQ;
+					return 0;
+				}
 				if (p >= end) {
 					errno = enderr;
 					return 0;
@@ -936,7 +1714,7 @@ size_t gen_o_unmarshal(gen_o* o, const void* data, size_t datalen) {
 				n |= (c & 127) << shift;
 			}
 		}
-		if (n > colfer_list_max) {
+		if (n > limits->list_max) {
 			errno = EFBIG;
 			return 0;
 		}
@@ -952,7 +1730,11 @@ size_t gen_o_unmarshal(gen_o* o, const void* data, size_t datalen) {
 			size_t len = *p++;
 			if (len > 127) {
 				len &= 127;
-				for (int shift = 7; ; shift += 7) {
+				for (size_t shift = 7; ; shift += 7) {
+					if (shift >= sizeof(size_t) * CHAR_BIT) {
+						errno = EILSEQ;
+						return 0;
+					}
 					if (p >= end) {
 						errno = enderr;
 						return 0;
@@ -965,7 +1747,7 @@ size_t gen_o_unmarshal(gen_o* o, const void* data, size_t datalen) {
 					len |= (c & 127) << shift;
 				}
 			}
-			if (len > colfer_size_max) {
+			if (len > limits->size_max) {
 				errno = EFBIG;
 				return 0;
 			}
@@ -998,8 +1780,12 @@ size_t gen_o_unmarshal(gen_o* o, const void* data, size_t datalen) {
 		size_t n = *p++;
 		if (n > 127) {
 			n &= 127;
-			for (int shift = 7; ; shift += 7) {
-				if (p >= end) {
+			for (size_t shift = 7; ; shift += 7) {
+				if (shift >= sizeof(size_t) * CHAR_BIT) {
+					errno = EILSEQ;
+					return 0;
+				}
+				if (p >= end) {
 					errno = enderr;
 					return 0;
 				}
@@ -1011,7 +1797,7 @@ size_t gen_o_unmarshal(gen_o* o, const void* data, size_t datalen) {
 				n |= (c & 127) << shift;
 			}
 		}
-		if (n > colfer_list_max) {
+		if (n > limits->list_max) {
 			errno = EFBIG;
 			return 0;
 		}
@@ -1027,7 +1813,11 @@ size_t gen_o_unmarshal(gen_o* o, const void* data, size_t datalen) {
 			size_t len = *p++;
 			if (len > 127) {
 				len &= 127;
-				for (int shift = 7; ; shift += 7) {
+				for (size_t shift = 7; ; shift += 7) {
+					if (shift >= sizeof(size_t) * CHAR_BIT) {
+						errno = EILSEQ;
+						return 0;
+					}
 					if (p >= end) {
 						errno = enderr;
 						return 0;
@@ -1040,7 +1830,7 @@ size_t gen_o_unmarshal(gen_o* o, const void* data, size_t datalen) {
 					len |= (c & 127) << shift;
 				}
 			}
-			if (len > colfer_size_max) {
+			if (len > limits->size_max) {
 				errno = EFBIG;
 				return 0;
 			}
@@ -1100,7 +1890,11 @@ size_t gen_o_unmarshal(gen_o* o, const void* data, size_t datalen) {
 		size_t n = *p++;
 		if (n > 127) {
 			n &= 127;
-			for (int shift = 7; ; shift += 7) {
+			for (size_t shift = 7; ; shift += 7) {
+				if (shift >= sizeof(size_t) * CHAR_BIT) {
+					errno = EILSEQ;
+					return 0;
+				}
 				if (p >= end) {
 					errno = enderr;
 					return 0;
@@ -1113,7 +1907,7 @@ size_t gen_o_unmarshal(gen_o* o, const void* data, size_t datalen) {
 				n |= (c & 127) << shift;
 			}
 		}
-		if (n > colfer_list_max) {
+		if (n > limits->list_max) {
 			errno = EFBIG;
 			return 0;
 		}
@@ -1149,7 +1943,11 @@ size_t gen_o_unmarshal(gen_o* o, const void* data, size_t datalen) {
 		size_t n = *p++;
 		if (n > 127) {
 			n &= 127;
-			for (int shift = 7; ; shift += 7) {
+			for (size_t shift = 7; ; shift += 7) {
+				if (shift >= sizeof(size_t) * CHAR_BIT) {
+					errno = EILSEQ;
+					return 0;
+				}
 				if (p >= end) {
 					errno = enderr;
 					return 0;
@@ -1162,7 +1960,7 @@ size_t gen_o_unmarshal(gen_o* o, const void* data, size_t datalen) {
 				n |= (c & 127) << shift;
 			}
 		}
-		if (n > colfer_list_max) {
+		if (n > limits->list_max) {
 			errno = EFBIG;
 			return 0;
 		}
@@ -1194,10 +1992,1374 @@ size_t gen_o_unmarshal(gen_o* o, const void* data, size_t datalen) {
 		header = *p++;
 	}
 
-	if (header != 127) {
-		errno = EILSEQ;
-		return 0;
+	if (header == 18) {
+		if (p+1 >= end) {
+			errno = enderr;
+			return 0;
+		}
+		o->e = *p++;
+		header = *p++;
 	}
 
-	return (size_t) (p - (const uint8_t*) data);
+	if (header == 19) {
+		if (p >= end) {
+			errno = enderr;
+			return 0;
+		}
+		size_t n = *p++;
+		if (n > 127) {
+			n &= 127;
+			for (size_t shift = 7; ; shift += 7) {
+				if (shift >= sizeof(size_t) * CHAR_BIT) {
+					errno = EILSEQ;
+					return 0;
+				}
+				if (p >= end) {
+					errno = enderr;
+					return 0;
+				}
+				size_t c = *p++;
+				if (c <= 127) {
+					n |= c << shift;
+					break;
+				}
+				n |= (c & 127) << shift;
+			}
+		}
+		if (n > limits->list_max) {
+			errno = EFBIG;
+			return 0;
+		}
+		if (p+n >= end) {
+			errno = enderr;
+			return 0;
+		}
+		o->bs.len = n;
+
+		char* a = malloc(n * sizeof(char));
+		o->bs.list = a;
+		for (size_t i = 0; i < n; ++i) a[i] = *p++ != 0;
+		header = *p++;
+	}
+
+	if (header == 20) {
+		if (p >= end) {
+			errno = enderr;
+			return 0;
+		}
+		size_t n = *p++;
+		if (n > 127) {
+			n &= 127;
+			for (size_t shift = 7; ; shift += 7) {
+				if (shift >= sizeof(size_t) * CHAR_BIT) {
+					errno = EILSEQ;
+					return 0;
+				}
+				if (p >= end) {
+					errno = enderr;
+					return 0;
+				}
+				size_t c = *p++;
+				if (c <= 127) {
+					n |= c << shift;
+					break;
+				}
+				n |= (c & 127) << shift;
+			}
+		}
+		if (n > limits->list_max) {
+			errno = EFBIG;
+			return 0;
+		}
+		if (p+n >= end) {
+			errno = enderr;
+			return 0;
+		}
+		o->u8s.len = n;
+
+		uint8_t* a = malloc(n * sizeof(uint8_t));
+		o->u8s.list = a;
+		memcpy(a, p, n);
+		p += n;
+		header = *p++;
+	}
+
+	if (header == 21) {
+		if (p >= end) {
+			errno = enderr;
+			return 0;
+		}
+		size_t n = *p++;
+		if (n > 127) {
+			n &= 127;
+			for (size_t shift = 7; ; shift += 7) {
+				if (shift >= sizeof(size_t) * CHAR_BIT) {
+					errno = EILSEQ;
+					return 0;
+				}
+				if (p >= end) {
+					errno = enderr;
+					return 0;
+				}
+				size_t c = *p++;
+				if (c <= 127) {
+					n |= c << shift;
+					break;
+				}
+				n |= (c & 127) << shift;
+			}
+		}
+		if (n > limits->list_max) {
+			errno = EFBIG;
+			return 0;
+		}
+		if (p+n >= end) {
+			errno = enderr;
+			return 0;
+		}
+		o->u16s.len = n;
+
+		uint16_t* a = malloc(n * sizeof(uint16_t));
+		o->u16s.list = a;
+		for (size_t i = 0; i < n; ++i) {
+			if (p >= end) {
+				errno = enderr;
+				return 0;
+			}
+			uint_fast64_t x = *p++;
+			if (x > 127) {
+				x &= 127;
+				for (int shift = 7; ; shift += 7) {
+					if (p >= end) {
+						errno = enderr;
+						return 0;
+					}
+					uint_fast64_t b = *p++;
+					if (b <= 127 || shift == 56) {
+						x |= b << shift;
+						break;
+					}
+					x |= (b & 127) << shift;
+				}
+			}
+			a[i] = x;
+		}
+		if (p >= end) {
+			errno = enderr;
+			return 0;
+		}
+		header = *p++;
+	}
+
+	if (header == 22) {
+		if (p >= end) {
+			errno = enderr;
+			return 0;
+		}
+		size_t n = *p++;
+		if (n > 127) {
+			n &= 127;
+			for (size_t shift = 7; ; shift += 7) {
+				if (shift >= sizeof(size_t) * CHAR_BIT) {
+					errno = EILSEQ;
+					return 0;
+				}
+				if (p >= end) {
+					errno = enderr;
+					return 0;
+				}
+				size_t c = *p++;
+				if (c <= 127) {
+					n |= c << shift;
+					break;
+				}
+				n |= (c & 127) << shift;
+			}
+		}
+		if (n > limits->list_max) {
+			errno = EFBIG;
+			return 0;
+		}
+		if (p+n >= end) {
+			errno = enderr;
+			return 0;
+		}
+		o->u32s.len = n;
+
+		uint32_t* a = malloc(n * sizeof(uint32_t));
+		o->u32s.list = a;
+		for (size_t i = 0; i < n; ++i) {
+			if (p >= end) {
+				errno = enderr;
+				return 0;
+			}
+			uint_fast64_t x = *p++;
+			if (x > 127) {
+				x &= 127;
+				for (int shift = 7; ; shift += 7) {
+					if (p >= end) {
+						errno = enderr;
+						return 0;
+					}
+					uint_fast64_t b = *p++;
+					if (b <= 127 || shift == 56) {
+						x |= b << shift;
+						break;
+					}
+					x |= (b & 127) << shift;
+				}
+			}
+			a[i] = x;
+		}
+		if (p >= end) {
+			errno = enderr;
+			return 0;
+		}
+		header = *p++;
+	}
+
+	if (header == 23) {
+		if (p >= end) {
+			errno = enderr;
+			return 0;
+		}
+		size_t n = *p++;
+		if (n > 127) {
+			n &= 127;
+			for (size_t shift = 7; ; shift += 7) {
+				if (shift >= sizeof(size_t) * CHAR_BIT) {
+					errno = EILSEQ;
+					return 0;
+				}
+				if (p >= end) {
+					errno = enderr;
+					return 0;
+				}
+				size_t c = *p++;
+				if (c <= 127) {
+					n |= c << shift;
+					break;
+				}
+				n |= (c & 127) << shift;
+			}
+		}
+		if (n > limits->list_max) {
+			errno = EFBIG;
+			return 0;
+		}
+		if (p+n >= end) {
+			errno = enderr;
+			return 0;
+		}
+		o->u64s.len = n;
+
+		uint64_t* a = malloc(n * sizeof(uint64_t));
+		o->u64s.list = a;
+		for (size_t i = 0; i < n; ++i) {
+			if (p >= end) {
+				errno = enderr;
+				return 0;
+			}
+			uint_fast64_t x = *p++;
+			if (x > 127) {
+				x &= 127;
+				for (int shift = 7; ; shift += 7) {
+					if (p >= end) {
+						errno = enderr;
+						return 0;
+					}
+					uint_fast64_t b = *p++;
+					if (b <= 127 || shift == 56) {
+						x |= b << shift;
+						break;
+					}
+					x |= (b & 127) << shift;
+				}
+			}
+			a[i] = x;
+		}
+		if (p >= end) {
+			errno = enderr;
+			return 0;
+		}
+		header = *p++;
+	}
+
+	if (header == 24) {
+		if (p >= end) {
+			errno = enderr;
+			return 0;
+		}
+		size_t n = *p++;
+		if (n > 127) {
+			n &= 127;
+			for (size_t shift = 7; ; shift += 7) {
+				if (shift >= sizeof(size_t) * CHAR_BIT) {
+					errno = EILSEQ;
+					return 0;
+				}
+				if (p >= end) {
+					errno = enderr;
+					return 0;
+				}
+				size_t c = *p++;
+				if (c <= 127) {
+					n |= c << shift;
+					break;
+				}
+				n |= (c & 127) << shift;
+			}
+		}
+		if (n > limits->list_max) {
+			errno = EFBIG;
+			return 0;
+		}
+		if (p+n >= end) {
+			errno = enderr;
+			return 0;
+		}
+		o->i32s.len = n;
+
+		int32_t* a = malloc(n * sizeof(int32_t));
+		o->i32s.list = a;
+		for (size_t i = 0; i < n; ++i) {
+			if (p >= end) {
+				errno = enderr;
+				return 0;
+			}
+			uint_fast64_t x = *p++;
+			if (x > 127) {
+				x &= 127;
+				for (int shift = 7; ; shift += 7) {
+					if (p >= end) {
+						errno = enderr;
+						return 0;
+					}
+					uint_fast64_t b = *p++;
+					if (b <= 127 || shift == 56) {
+						x |= b << shift;
+						break;
+					}
+					x |= (b & 127) << shift;
+				}
+			}
+			uint32_t u = x;
+			a[i] = (u >> 1) ^ (0 - (u & 1));
+		}
+		if (p >= end) {
+			errno = enderr;
+			return 0;
+		}
+		header = *p++;
+	}
+
+	if (header == 25) {
+		if (p >= end) {
+			errno = enderr;
+			return 0;
+		}
+		size_t n = *p++;
+		if (n > 127) {
+			n &= 127;
+			for (size_t shift = 7; ; shift += 7) {
+				if (shift >= sizeof(size_t) * CHAR_BIT) {
+					errno = EILSEQ;
+					return 0;
+				}
+				if (p >= end) {
+					errno = enderr;
+					return 0;
+				}
+				size_t c = *p++;
+				if (c <= 127) {
+					n |= c << shift;
+					break;
+				}
+				n |= (c & 127) << shift;
+			}
+		}
+		if (n > limits->list_max) {
+			errno = EFBIG;
+			return 0;
+		}
+		if (p+n >= end) {
+			errno = enderr;
+			return 0;
+		}
+		o->i64s.len = n;
+
+		int64_t* a = malloc(n * sizeof(int64_t));
+		o->i64s.list = a;
+		for (size_t i = 0; i < n; ++i) {
+			if (p >= end) {
+				errno = enderr;
+				return 0;
+			}
+			uint_fast64_t x = *p++;
+			if (x > 127) {
+				x &= 127;
+				for (int shift = 7; ; shift += 7) {
+					if (p >= end) {
+						errno = enderr;
+						return 0;
+					}
+					uint_fast64_t b = *p++;
+					if (b <= 127 || shift == 56) {
+						x |= b << shift;
+						break;
+					}
+					x |= (b & 127) << shift;
+				}
+			}
+			a[i] = (x >> 1) ^ (0 - (x & 1));
+		}
+		if (p >= end) {
+			errno = enderr;
+			return 0;
+		}
+		header = *p++;
+	}
+
+	if (header == 26) {
+		if (p >= end) {
+			errno = enderr;
+			return 0;
+		}
+		size_t n = *p++;
+		if (n > 127) {
+			n &= 127;
+			for (size_t shift = 7; ; shift += 7) {
+				if (shift >= sizeof(size_t) * CHAR_BIT) {
+					errno = EILSEQ;
+					return 0;
+				}
+				if (p >= end) {
+					errno = enderr;
+					return 0;
+				}
+				size_t c = *p++;
+				if (c <= 127) {
+					n |= c << shift;
+					break;
+				}
+				n |= (c & 127) << shift;
+			}
+		}
+		if (n > limits->list_max) {
+			errno = EFBIG;
+			return 0;
+		}
+		if (p+n >= end) {
+			errno = enderr;
+			return 0;
+		}
+		o->es.len = n;
+
+		gen_color* a = malloc(n * sizeof(gen_color));
+		o->es.list = a;
+		memcpy(a, p, n);
+		p += n;
+		header = *p++;
+	}
+
+	if (header == 27) {
+		if (p >= end) {
+			errno = enderr;
+			return 0;
+		}
+		size_t n = *p++;
+		if (n > 127) {
+			n &= 127;
+			for (size_t shift = 7; ; shift += 7) {
+				if (shift >= sizeof(size_t) * CHAR_BIT) {
+					errno = EILSEQ;
+					return 0;
+				}
+				if (p >= end) {
+					errno = enderr;
+					return 0;
+				}
+				size_t c = *p++;
+				if (c <= 127) {
+					n |= c << shift;
+					break;
+				}
+				n |= (c & 127) << shift;
+			}
+		}
+		if (n > limits->list_max) {
+			errno = EFBIG;
+			return 0;
+		}
+		if (p+n*2 >= end) {
+			errno = enderr;
+			return 0;
+		}
+
+		colfer_text* keys = calloc(n, sizeof(colfer_text));
+		colfer_text* values = calloc(n, sizeof(colfer_text));
+		o->mt.len = n;
+		o->mt.keys = keys;
+		o->mt.values = values;
+		for (size_t i = 0; i < n; ++i) {
+			{
+				if (p >= end) {
+					errno = enderr;
+					return 0;
+				}
+				size_t size = *p++;
+				if (size > 127) {
+					size &= 127;
+					for (size_t shift = 7; ; shift += 7) {
+						if (shift >= sizeof(size_t) * CHAR_BIT) {
+							errno = EILSEQ;
+							return 0;
+						}
+						if (p >= end) {
+							errno = enderr;
+							return 0;
+						}
+						size_t c = *p++;
+						if (c <= 127) {
+							size |= c << shift;
+							break;
+						}
+						size |= (c & 127) << shift;
+					}
+				}
+				if (size > limits->size_max) {
+					errno = EFBIG;
+					return 0;
+				}
+				if (p+size >= end) {
+					errno = enderr;
+					return 0;
+				}
+				if (i) {
+					size_t prev = keys[i - 1].len;
+					int c = memcmp(keys[i - 1].utf8, p, prev < size ? prev : size);
+					if (c > 0 || (c == 0 && prev >= size)) {
+						errno = EILSEQ;
+						return 0;
+					}
+				}
+				char* a = malloc(size);
+				if (size) memcpy(a, p, size);
+				p += size;
+				keys[i].utf8 = a;
+				keys[i].len = size;
+			}
+			{
+				if (p >= end) {
+					errno = enderr;
+					return 0;
+				}
+				size_t size = *p++;
+				if (size > 127) {
+					size &= 127;
+					for (size_t shift = 7; ; shift += 7) {
+						if (shift >= sizeof(size_t) * CHAR_BIT) {
+							errno = EILSEQ;
+							return 0;
+						}
+						if (p >= end) {
+							errno = enderr;
+							return 0;
+						}
+						size_t c = *p++;
+						if (c <= 127) {
+							size |= c << shift;
+							break;
+						}
+						size |= (c & 127) << shift;
+					}
+				}
+				if (size > limits->size_max) {
+					errno = EFBIG;
+					return 0;
+				}
+				if (p+size >= end) {
+					errno = enderr;
+					return 0;
+				}
+				char* a = malloc(size);
+				if (size) memcpy(a, p, size);
+				p += size;
+				values[i].utf8 = a;
+				values[i].len = size;
+			}
+		}
+
+		if (p >= end) {
+			errno = enderr;
+			return 0;
+		}
+		header = *p++;
+	}
+
+	if (header == 28) {
+		if (p >= end) {
+			errno = enderr;
+			return 0;
+		}
+		size_t n = *p++;
+		if (n > 127) {
+			n &= 127;
+			for (size_t shift = 7; ; shift += 7) {
+				if (shift >= sizeof(size_t) * CHAR_BIT) {
+					errno = EILSEQ;
+					return 0;
+				}
+				if (p >= end) {
+					errno = enderr;
+					return 0;
+				}
+				size_t c = *p++;
+				if (c <= 127) {
+					n |= c << shift;
+					break;
+				}
+				n |= (c & 127) << shift;
+			}
+		}
+		if (n > limits->list_max) {
+			errno = EFBIG;
+			return 0;
+		}
+		if (p+n*2 >= end) {
+			errno = enderr;
+			return 0;
+		}
+
+		uint32_t* keys = calloc(n, sizeof(uint32_t));
+		int64_t* values = calloc(n, sizeof(int64_t));
+		o->mu.len = n;
+		o->mu.keys = keys;
+		o->mu.values = values;
+		for (size_t i = 0; i < n; ++i) {
+			{
+				if (p >= end) {
+					errno = enderr;
+					return 0;
+				}
+				uint_fast64_t x = *p++;
+				if (x > 127) {
+					x &= 127;
+					for (int shift = 7; ; shift += 7) {
+						if (p >= end) {
+							errno = enderr;
+							return 0;
+						}
+						uint_fast64_t b = *p++;
+						if (b <= 127 || shift == 56) {
+							x |= b << shift;
+							break;
+						}
+						x |= (b & 127) << shift;
+					}
+				}
+				keys[i] = x;
+				if (i && keys[i - 1] >= keys[i]) {
+					errno = EILSEQ;
+					return 0;
+				}
+			}
+			{
+				if (p >= end) {
+					errno = enderr;
+					return 0;
+				}
+				uint_fast64_t x = *p++;
+				if (x > 127) {
+					x &= 127;
+					for (int shift = 7; ; shift += 7) {
+						if (p >= end) {
+							errno = enderr;
+							return 0;
+						}
+						uint_fast64_t b = *p++;
+						if (b <= 127 || shift == 56) {
+							x |= b << shift;
+							break;
+						}
+						x |= (b & 127) << shift;
+					}
+				}
+				values[i] = (x >> 1) ^ (0 - (x & 1));
+			}
+		}
+
+		if (p >= end) {
+			errno = enderr;
+			return 0;
+		}
+		header = *p++;
+	}
+
+	if (header == 29) {
+		if (p >= end) {
+			errno = enderr;
+			return 0;
+		}
+		size_t n = *p++;
+		if (n > 127) {
+			n &= 127;
+			for (size_t shift = 7; ; shift += 7) {
+				if (shift >= sizeof(size_t) * CHAR_BIT) {
+					errno = EILSEQ;
+					return 0;
+				}
+				if (p >= end) {
+					errno = enderr;
+					return 0;
+				}
+				size_t c = *p++;
+				if (c <= 127) {
+					n |= c << shift;
+					break;
+				}
+				n |= (c & 127) << shift;
+			}
+		}
+		if (n > limits->list_max) {
+			errno = EFBIG;
+			return 0;
+		}
+		if (p+n*2 >= end) {
+			errno = enderr;
+			return 0;
+		}
+
+		int64_t* keys = calloc(n, sizeof(int64_t));
+		colfer_binary* values = calloc(n, sizeof(colfer_binary));
+		o->mi.len = n;
+		o->mi.keys = keys;
+		o->mi.values = values;
+		for (size_t i = 0; i < n; ++i) {
+			{
+				if (p >= end) {
+					errno = enderr;
+					return 0;
+				}
+				uint_fast64_t x = *p++;
+				if (x > 127) {
+					x &= 127;
+					for (int shift = 7; ; shift += 7) {
+						if (p >= end) {
+							errno = enderr;
+							return 0;
+						}
+						uint_fast64_t b = *p++;
+						if (b <= 127 || shift == 56) {
+							x |= b << shift;
+							break;
+						}
+						x |= (b & 127) << shift;
+					}
+				}
+				keys[i] = (x >> 1) ^ (0 - (x & 1));
+				if (i && keys[i - 1] >= keys[i]) {
+					errno = EILSEQ;
+					return 0;
+				}
+			}
+			{
+				if (p >= end) {
+					errno = enderr;
+					return 0;
+				}
+				size_t size = *p++;
+				if (size > 127) {
+					size &= 127;
+					for (size_t shift = 7; ; shift += 7) {
+						if (shift >= sizeof(size_t) * CHAR_BIT) {
+							errno = EILSEQ;
+							return 0;
+						}
+						if (p >= end) {
+							errno = enderr;
+							return 0;
+						}
+						size_t c = *p++;
+						if (c <= 127) {
+							size |= c << shift;
+							break;
+						}
+						size |= (c & 127) << shift;
+					}
+				}
+				if (size > limits->size_max) {
+					errno = EFBIG;
+					return 0;
+				}
+				if (p+size >= end) {
+					errno = enderr;
+					return 0;
+				}
+				uint8_t* a = malloc(size);
+				if (size) memcpy(a, p, size);
+				p += size;
+				values[i].octets = a;
+				values[i].len = size;
+			}
+		}
+
+		if (p >= end) {
+			errno = enderr;
+			return 0;
+		}
+		header = *p++;
+	}
+
+	if (header == 30) {
+		if (p >= end) {
+			errno = enderr;
+			return 0;
+		}
+		size_t n = *p++;
+		if (n > 127) {
+			n &= 127;
+			for (size_t shift = 7; ; shift += 7) {
+				if (shift >= sizeof(size_t) * CHAR_BIT) {
+					errno = EILSEQ;
+					return 0;
+				}
+				if (p >= end) {
+					errno = enderr;
+					return 0;
+				}
+				size_t c = *p++;
+				if (c <= 127) {
+					n |= c << shift;
+					break;
+				}
+				n |= (c & 127) << shift;
+			}
+		}
+		if (n > limits->list_max) {
+			errno = EFBIG;
+			return 0;
+		}
+		if (p+n*2 >= end) {
+			errno = enderr;
+			return 0;
+		}
+
+		colfer_text* keys = calloc(n, sizeof(colfer_text));
+		gen_o* values = calloc(n, sizeof(gen_o));
+		o->mo.len = n;
+		o->mo.keys = keys;
+		o->mo.values = values;
+		for (size_t i = 0; i < n; ++i) {
+			{
+				if (p >= end) {
+					errno = enderr;
+					return 0;
+				}
+				size_t size = *p++;
+				if (size > 127) {
+					size &= 127;
+					for (size_t shift = 7; ; shift += 7) {
+						if (shift >= sizeof(size_t) * CHAR_BIT) {
+							errno = EILSEQ;
+							return 0;
+						}
+						if (p >= end) {
+							errno = enderr;
+							return 0;
+						}
+						size_t c = *p++;
+						if (c <= 127) {
+							size |= c << shift;
+							break;
+						}
+						size |= (c & 127) << shift;
+					}
+				}
+				if (size > limits->size_max) {
+					errno = EFBIG;
+					return 0;
+				}
+				if (p+size >= end) {
+					errno = enderr;
+					return 0;
+				}
+				if (i) {
+					size_t prev = keys[i - 1].len;
+					int c = memcmp(keys[i - 1].utf8, p, prev < size ? prev : size);
+					if (c > 0 || (c == 0 && prev >= size)) {
+						errno = EILSEQ;
+						return 0;
+					}
+				}
+				char* a = malloc(size);
+				if (size) memcpy(a, p, size);
+				p += size;
+				keys[i].utf8 = a;
+				keys[i].len = size;
+			}
+			size_t read = gen_o_unmarshal_with(&values[i], p, (size_t) (end - p), &nested);
+			if (!read) {
+				if (errno == EWOULDBLOCK) errno = enderr;
+				return read;
+			}
+			p += read;
+		}
+
+		if (p >= end) {
+			errno = enderr;
+			return 0;
+		}
+		header = *p++;
+	}
+
+	if (header == 32) {
+		o->gap = 1;
+		if (p >= end) {
+			errno = enderr;
+			return 0;
+		}
+		header = *p++;
+	}
+
+	if (header == 33) {
+		if (p >= end) {
+			errno = enderr;
+			return 0;
+		}
+		size_t n = *p++;
+		if (n > 127) {
+			n &= 127;
+			for (size_t shift = 7; shift < sizeof(size_t) * CHAR_BIT; shift += 7) {
+				if (p >= end) {
+					errno = enderr;
+					return 0;
+				}
+				size_t c = *p++;
+				if (c <= 127) {
+					n |= c << shift;
+					break;
+				}
+				n |= (c & 127) << shift;
+			}
+		}
+		if (n > 4) {
+			errno = EFBIG;
+			return 0;
+		}
+		if (p+n >= end) {
+			errno = enderr;
+			return 0;
+		}
+		o->host.len = n;
+
+		void* a = malloc(n);
+		o->host.utf8 = (char*) a;
+		if (n) {
+			memcpy(a, p, n);
+			p += n;
+		}
+		header = *p++;
+	}
+
+	if (header == 34) {
+		if (p >= end) {
+			errno = enderr;
+			return 0;
+		}
+		size_t n = *p++;
+		if (n > 127) {
+			n &= 127;
+			for (size_t shift = 7; ; shift += 7) {
+				if (shift >= sizeof(size_t) * CHAR_BIT) {
+					errno = EILSEQ;
+					return 0;
+				}
+				if (p >= end) {
+					errno = enderr;
+					return 0;
+				}
+				size_t c = *p++;
+				if (c <= 127) {
+					n |= c << shift;
+					break;
+				}
+				n |= (c & 127) << shift;
+			}
+		}
+		if (n > 2) {
+			errno = EFBIG;
+			return 0;
+		}
+		o->tags.len = n;
+
+		colfer_text* text = malloc(n * sizeof(colfer_text));
+		o->tags.list = text;
+		for (; n; --n, ++text) {
+			if (p >= end) {
+				errno = enderr;
+				return 0;
+			}
+			size_t len = *p++;
+			if (len > 127) {
+				len &= 127;
+				for (size_t shift = 7; ; shift += 7) {
+					if (shift >= sizeof(size_t) * CHAR_BIT) {
+						errno = EILSEQ;
+						return 0;
+					}
+					if (p >= end) {
+						errno = enderr;
+						return 0;
+					}
+					size_t c = *p++;
+					if (c <= 127) {
+						len |= c << shift;
+						break;
+					}
+					len |= (c & 127) << shift;
+				}
+			}
+			if (len > 1) {
+				errno = EFBIG;
+				return 0;
+			}
+			if (p+len >= end) {
+				errno = enderr;
+				return 0;
+			}
+			text->len = len;
+
+			char* a = malloc(len);
+			text->utf8 = a;
+			if (len) {
+				memcpy(a, p, len);
+				p += len;
+			}
+		}
+
+		if (p >= end) {
+			errno = enderr;
+			return 0;
+		}
+		header = *p++;
+	}
+
+	if (header == 35) {
+		o->ob = 1;
+		o->has_ob = 1;
+		if (p >= end) {
+			errno = enderr;
+			return 0;
+		}
+		header = *p++;
+	} else if (header == (35 | 128)) {
+		o->ob = 0;
+		o->has_ob = 1;
+		if (p >= end) {
+			errno = enderr;
+			return 0;
+		}
+		header = *p++;
+	}
+
+	if (header == 36) {
+		if (p+1 >= end) {
+			errno = enderr;
+			return 0;
+		}
+		uint_fast32_t x = *p++;
+		if (x > 127) {
+			x &= 127;
+			for (int shift = 7; ; shift += 7) {
+				uint_fast32_t b = *p++;
+				if (p >= end) {
+					errno = enderr;
+					return 0;
+				}
+				if (b <= 127) {
+					x |= b << shift;
+					break;
+				}
+				x |= (b & 127) << shift;
+			}
+		}
+		o->ou32 = x;
+		o->has_ou32 = 1;
+		header = *p++;
+	} else if (header == (36 | 128)) {
+		if (p+4 >= end) {
+			errno = enderr;
+			return 0;
+		}
+		uint_fast32_t x = *p++;
+		x <<= 24;
+		x |= (uint_fast32_t) *p++ << 16;
+		x |= (uint_fast32_t) *p++ << 8;
+		x |= (uint_fast32_t) *p++;
+		o->ou32 = x;
+		o->has_ou32 = 1;
+		header = *p++;
+	}
+
+	if ((header & 127) == 37) {
+		if (p+1 >= end) {
+			errno = enderr;
+			return 0;
+		}
+		uint_fast64_t x = *p++;
+		if (x > 127) {
+			x &= 127;
+			for (int shift = 7; ; shift += 7) {
+				uint_fast64_t b = *p++;
+				if (p >= end) {
+					errno = enderr;
+					return 0;
+				}
+				if (b <= 127 || shift == 56) {
+					x |= b << shift;
+					break;
+				}
+				x |= (b & 127) << shift;
+			}
+		}
+		if (header & 128) x = ~x + 1;
+		o->oi64 = x;
+		o->has_oi64 = 1;
+		header = *p++;
+	}
+
+	if (header == 38) {
+		if (p+8 >= end) {
+			errno = enderr;
+			return 0;
+		}
+#ifdef COLFER_ENDIAN
+		memcpy(&o->of64, p, 8);
+		p += 8;
+#else
+		uint_fast64_t x = *p++;
+		x <<= 56;
+		x |= (uint_fast64_t) *p++ << 48;
+		x |= (uint_fast64_t) *p++ << 40;
+		x |= (uint_fast64_t) *p++ << 32;
+		x |= (uint_fast64_t) *p++ << 24;
+		x |= (uint_fast64_t) *p++ << 16;
+		x |= (uint_fast64_t) *p++ << 8;
+		x |= (uint_fast64_t) *p++;
+		memcpy(&o->of64, &x, 8);
+#endif
+		o->has_of64 = 1;
+		header = *p++;
+	}
+
+	if (header == 39) {
+		if (p >= end) {
+			errno = enderr;
+			return 0;
+		}
+		size_t read;
+		switch (*p++) {
+		case 0:
+			o->u.type = GEN_PICK_O;
+			o->u.o = calloc(1, sizeof(gen_o));
+			read = gen_o_unmarshal_with(o->u.o, p, (size_t) (end - p), &nested);
+			break;
+		case 1:
+			o->u.type = GEN_PICK_POINT;
+			o->u.point = calloc(1, sizeof(gen_point));
+			read = gen_point_unmarshal_with(o->u.point, p, (size_t) (end - p), &nested);
+			break;
+		default:
+			errno = EILSEQ;
+			return 0;
+		}
+		if (!read) {
+			if (errno == EWOULDBLOCK) errno = enderr;
+			return read;
+		}
+		p += read;
+
+		if (p >= end) {
+			errno = enderr;
+			return 0;
+		}
+		header = *p++;
+	}
+
+	if (header != 127) {
+		errno = EILSEQ;
+		return 0;
+	}
+
+	return (size_t) (p - (const uint8_t*) data);
+}
+
+size_t gen_o_unmarshal(gen_o* o, const void* data, size_t datalen) {
+	colfer_limits limits = colfer_defaults();
+	return gen_o_unmarshal_with(o, data, datalen, &limits);
+}
+
+size_t gen_point_marshal_len(const gen_point* o) {
+	colfer_limits limits = colfer_defaults();
+	return gen_point_marshal_len_with(o, &limits);
+}
+
+size_t gen_point_marshal_len_with(const gen_point* o, const colfer_limits* limits) {
+	if (!limits->depth_max) {
+		errno = ELOOP;
+		return 0;
+	}
+	size_t l = 1;
+
+	{
+		uint_fast32_t x = o->x;
+		if (x) {
+			if (x & (uint_fast32_t) 1 << 31) {
+				x = ~x;
+				++x;
+			}
+			for (l += 2; x > 127; x >>= 7, ++l);
+		}
+	}
+
+	{
+		uint_fast32_t x = o->y;
+		if (x) {
+			if (x & (uint_fast32_t) 1 << 31) {
+				x = ~x;
+				++x;
+			}
+			for (l += 2; x > 127; x >>= 7, ++l);
+		}
+	}
+
+	if (l > limits->size_max) {
+		errno = EFBIG;
+		return 0;
+	}
+	return l;
+}
+
+size_t gen_point_marshal(const gen_point* o, void* buf) {
+	// octet pointer navigation
+	uint8_t* p = buf;
+
+	{
+		uint_fast32_t x = o->x;
+		if (x) {
+			if (x & (uint_fast32_t) 1 << 31) {
+				*p++ = 0 | 128;
+				x = ~x + 1;
+			} else	*p++ = 0;
+
+			for (; x >= 128; x >>= 7) *p++ = x | 128;
+			*p++ = x;
+		}
+	}
+
+	{
+		uint_fast32_t x = o->y;
+		if (x) {
+			if (x & (uint_fast32_t) 1 << 31) {
+				*p++ = 1 | 128;
+				x = ~x + 1;
+			} else	*p++ = 1;
+
+			for (; x >= 128; x >>= 7) *p++ = x | 128;
+			*p++ = x;
+		}
+	}
+
+	*p++ = 127;
+
+	return p - (uint8_t*) buf;
+}
+
+size_t gen_point_unmarshal_with(gen_point* o, const void* data, size_t datalen, const colfer_limits* limits) {
+	if (!limits->depth_max) {
+		errno = ELOOP;
+		return 0;
+	}
+	// octet pointer navigation
+	const uint8_t* p = data;
+	const uint8_t* end;
+	int enderr;
+	if (datalen < limits->size_max) {
+		end = p + datalen;
+		enderr = EWOULDBLOCK;
+	} else {
+		end = p + limits->size_max;
+		enderr = EFBIG;
+	}
+
+	if (p >= end) {
+		errno = enderr;
+		return 0;
+	}
+	uint_fast8_t header = *p++;
+
+	if ((header & 127) == 0) {
+		if (p+1 >= end) {
+			errno = enderr;
+			return 0;
+		}
+		uint_fast32_t x = *p++;
+		if (x > 127) {
+			x &= 127;
+			for (int shift = 7; shift < 35; shift += 7) {
+				uint_fast32_t b = *p++;
+				if (p >= end) {
+					errno = enderr;
+					return 0;
+				}
+				if (b <= 127) {
+					x |= b << shift;
+					break;
+				}
+				x |= (b & 127) << shift;
+			}
+		}
+		if (header & 128) x = ~x + 1;
+		o->x = x;
+		header = *p++;
+	}
+
+	if ((header & 127) == 1) {
+		if (p+1 >= end) {
+			errno = enderr;
+			return 0;
+		}
+		uint_fast32_t x = *p++;
+		if (x > 127) {
+			x &= 127;
+			for (int shift = 7; shift < 35; shift += 7) {
+				uint_fast32_t b = *p++;
+				if (p >= end) {
+					errno = enderr;
+					return 0;
+				}
+				if (b <= 127) {
+					x |= b << shift;
+					break;
+				}
+				x |= (b & 127) << shift;
+			}
+		}
+		if (header & 128) x = ~x + 1;
+		o->y = x;
+		header = *p++;
+	}
+
+	if (header != 127) {
+		errno = EILSEQ;
+		return 0;
+	}
+
+	return (size_t) (p - (const uint8_t*) data);
+}
+
+size_t gen_point_unmarshal(gen_point* o, const void* data, size_t datalen) {
+	colfer_limits limits = colfer_defaults();
+	return gen_point_unmarshal_with(o, data, datalen, &limits);
 }
@@ -25,6 +25,29 @@ extern size_t colfer_size_max;
 // colfer_list_max is the upper limit for the number of elements in a list.
 extern size_t colfer_list_max;
 
+// colfer_depth_max is the upper limit for the nesting depth of data
+// structures.
+extern size_t colfer_depth_max;
+
+// colfer_limits has the upper boundaries for marshalling and unmarshalling,
+// which apply to nested data structures too.
+typedef struct {
+	// size_max is the upper limit for serial octet sizes.
+	size_t size_max;
+	// list_max is the upper limit for the number of elements in a list or
+	// in a map.
+	size_t list_max;
+	// depth_max is the upper limit for the nesting depth of data
+	// structures, with one for a struct without any nested values.
+	size_t depth_max;
+} colfer_limits;
+
+// colfer_defaults returns the limits of colfer_size_max, colfer_list_max and
+// colfer_depth_max, which apply when none are given. A change to any of the
+// globals races with any (un)marshalling in progress. The functions with
+// explicit limits need no such change.
+colfer_limits colfer_defaults(void);
+
 
 // colfer_text is a UTF-8 CLOB.
 typedef struct {
@@ -38,9 +61,67 @@ typedef struct {
 	size_t   len;
 } colfer_binary;
 
+// ConstBool tests boolean constants.
+#define GEN_CONST_BOOL 1
+
+// ConstU8 tests unsigned 8-bit integer constants.
+#define GEN_CONST_U8 UINT8_C(255)
+
+// ConstU32 tests unsigned 32-bit integer constants.
+#define GEN_CONST_U32 UINT32_C(4294967295)
+
+// ConstU64 tests unsigned 64-bit integer constants.
+#define GEN_CONST_U64 UINT64_C(9007199254740991)
+
+// ConstI32 tests signed 32-bit integer constants.
+#define GEN_CONST_I32 INT32_MIN
+
+// ConstI64 tests signed 64-bit integer constants.
+#define GEN_CONST_I64 INT64_C(-9007199254740991)
+
+// ConstF32 tests 32-bit floating point constants.
+#define GEN_CONST_F32 1.5f
+
+// ConstF64 tests 64-bit floating point constants.
+#define GEN_CONST_F64 1e-100
+
+// ConstText tests text constants.
+#define GEN_CONST_TEXT "\"\317\200\"\011\?\?="
+
+// Color tests enumerations.
+typedef uint8_t gen_color;
+
+// gen_color_value has the defined values for gen_color.
+enum gen_color_value {
+	// Red is the zero value.
+	GEN_COLOR_RED = 0,
+	// Green is the first in line.
+	GEN_COLOR_GREEN = 1,
+	// Blue is the last one.
+	GEN_COLOR_BLUE = 2,
+};
+
 
 typedef struct gen_o gen_o;
 
+typedef struct gen_point gen_point;
+
+// gen_pick_type identifies the members of gen_pick.
+enum gen_pick_type {
+	GEN_PICK_O = 1,
+	GEN_PICK_POINT,
+};
+
+// Pick tests unions.
+typedef struct {
+	// type selects the member in place, with zero for none.
+	enum gen_pick_type type;
+	union {
+		gen_o* o;
+		gen_point* point;
+	};
+} gen_pick;
+
 
 // O contains all supported data types.
 struct gen_o {
@@ -95,25 +176,166 @@ struct gen_o {
 		double* list;
 		size_t len;
 	} f64s;
+	// E tests enumerations.
+	gen_color e;
+	// Bs tests boolean lists.
+	struct {
+		char* list;
+		size_t len;
+	} bs;
+	// U8s tests unsigned 8-bit integer lists.
+	struct {
+		uint8_t* list;
+		size_t len;
+	} u8s;
+	// U16s tests unsigned 16-bit integer lists.
+	struct {
+		uint16_t* list;
+		size_t len;
+	} u16s;
+	// U32s tests unsigned 32-bit integer lists.
+	struct {
+		uint32_t* list;
+		size_t len;
+	} u32s;
+	// U64s tests unsigned 64-bit integer lists.
+	struct {
+		uint64_t* list;
+		size_t len;
+	} u64s;
+	// I32s tests signed 32-bit integer lists.
+	struct {
+		int32_t* list;
+		size_t len;
+	} i32s;
+	// I64s tests signed 64-bit integer lists.
+	struct {
+		int64_t* list;
+		size_t len;
+	} i64s;
+	// Es tests enumeration lists.
+	struct {
+		gen_color* list;
+		size_t len;
+	} es;
+	// Mt tests maps with text keys.
+	struct {
+		colfer_text* keys;
+		colfer_text* values;
+		size_t len;
+	} mt;
+	// Mu tests maps with unsigned integer keys.
+	struct {
+		uint32_t* keys;
+		int64_t* values;
+		size_t len;
+	} mu;
+	// Mi tests maps with signed integer keys.
+	struct {
+		int64_t* keys;
+		colfer_binary* values;
+		size_t len;
+	} mi;
+	// Mo tests maps with data structure values.
+	struct {
+		colfer_text* keys;
+		struct gen_o* values;
+		size_t len;
+	} mo;
+	// Gap tests explicit indexes.
+	char gap;
+	// Host tests field size limits.
+	colfer_text host;
+	// Tags tests field list limits.
+	struct {
+		colfer_text* list;
+		size_t len;
+	} tags;
+	// Ob tests optional booleans.
+	char ob;
+	// has_ob flags the presence of ob.
+	char has_ob;
+	// Ou32 tests optional unsigned integers.
+	uint32_t ou32;
+	// has_ou32 flags the presence of ou32.
+	char has_ou32;
+	// Oi64 tests optional signed integers.
+	int64_t oi64;
+	// has_oi64 flags the presence of oi64.
+	char has_oi64;
+	// Of64 tests optional floating points.
+	double of64;
+	// has_of64 flags the presence of of64.
+	char has_of64;
+	// U tests unions.
+	gen_pick u;
 };
 
 // gen_o_marshal_len returns the Colfer serial octet size.
 // When the return is zero then errno is set to EFBIG to indicate a breach of
-// either colfer_size_max or colfer_list_max.
+// either colfer_size_max or colfer_list_max, or errno is set to ELOOP to
+// indicate a breach of colfer_depth_max, which includes reference cycles. Map keys must be in strictly
+// ascending order, with text compared per octet, or errno is set to EINVAL.
 size_t gen_o_marshal_len(const gen_o* o);
 
+// gen_o_marshal_len_with sizes like gen_o_marshal_len does,
+// with limits instead of the globals.
+size_t gen_o_marshal_len_with(const gen_o* o, const colfer_limits* limits);
+
 // gen_o_marshal encodes o as Colfer into buf and returns the number
-// of octets written.
+// of octets written. The size of buf must be at least the return of
+// gen_o_marshal_len, which also catches reference cycles.
 size_t gen_o_marshal(const gen_o* o, void* buf);
 
 // gen_o_unmarshal decodes data as Colfer into o and returns the
 // number of octets read. The data is read up to a maximum of datalen or
 // colfer_size_max, whichever occurs first.
-// When the return is zero then errno is set to one of the following 3 values:
+// When the return is zero then errno is set to one of the following 4 values:
 // EWOULDBLOCK on incomplete data, EFBIG on a breach of either colfer_size_max
-// or colfer_list_max and EILSEQ on schema mismatch.
+// or colfer_list_max, ELOOP on a breach of colfer_depth_max and EILSEQ on
+// schema mismatch or on a malformed length.
 size_t gen_o_unmarshal(gen_o* o, const void* data, size_t datalen);
 
+// gen_o_unmarshal_with decodes like gen_o_unmarshal does,
+// with limits instead of the globals.
+size_t gen_o_unmarshal_with(gen_o* o, const void* data, size_t datalen, const colfer_limits* limits);
+
+// Point is a union member.
+struct gen_point {
+
+	int32_t x;
+
+	int32_t y;
+};
+
+// gen_point_marshal_len returns the Colfer serial octet size.
+// When the return is zero then errno is set to EFBIG to indicate a breach of
+// either colfer_size_max or colfer_list_max, or errno is set to ELOOP to
+// indicate a breach of colfer_depth_max, which includes reference cycles.
+size_t gen_point_marshal_len(const gen_point* o);
+
+// gen_point_marshal_len_with sizes like gen_point_marshal_len does,
+// with limits instead of the globals.
+size_t gen_point_marshal_len_with(const gen_point* o, const colfer_limits* limits);
+
+// gen_point_marshal encodes o as Colfer into buf and returns the number
+// of octets written. The size of buf must be at least the return of
+// gen_point_marshal_len, which also catches reference cycles.
+size_t gen_point_marshal(const gen_point* o, void* buf);
+
+// gen_point_unmarshal decodes data as Colfer into o and returns the
+// number of octets read. The data is read up to a maximum of datalen or
+// colfer_size_max, whichever occurs first.
+// When the return is zero then errno is set to one of the following 4 values:
+// EWOULDBLOCK on incomplete data, EFBIG on a breach of either colfer_size_max
+// or colfer_list_max, ELOOP on a breach of colfer_depth_max and EILSEQ on
+// schema mismatch or on a malformed length.
+size_t gen_point_unmarshal(gen_point* o, const void* data, size_t datalen);
+
+// gen_point_unmarshal_with decodes like gen_point_unmarshal does,
+// with limits instead of the globals.
+size_t gen_point_unmarshal_with(gen_point* o, const void* data, size_t datalen, const colfer_limits* limits);
+
 
 #ifdef __cplusplus
 } // extern "C"
@@ -19,6 +19,8 @@ void hexstr(char* buf, const void* data, size_t datalen) {
 	*buf = 0;
 }
 
+int gen_pick_equal(const gen_pick* pa, const gen_pick* pb);
+
 int gen_o_equal(const gen_o* pa, const gen_o* pb) {
 	if (pa == NULL || pb == NULL) return pa == pb;
 	const gen_o a = *pa, b = *pb;
@@ -26,6 +28,7 @@ int gen_o_equal(const gen_o* pa, const gen_o* pb) {
 	if (! (
 		a.b == b.b
 		&& a.u8 == b.u8
+		&& a.e == b.e
 		&& a.u16 == b.u16
 		&& a.u32 == b.u32
 		&& a.u64 == b.u64
@@ -42,6 +45,26 @@ int gen_o_equal(const gen_o* pa, const gen_o* pb) {
 		&& a.as.len == b.as.len
 		&& gen_o_equal(a.o, b.o)
 		&& a.os.len == b.os.len
+		&& a.bs.len == b.bs.len && !memcmp(a.bs.list, b.bs.list, a.bs.len)
+		&& a.u8s.len == b.u8s.len && !memcmp(a.u8s.list, b.u8s.list, a.u8s.len)
+		&& a.u16s.len == b.u16s.len && !memcmp(a.u16s.list, b.u16s.list, a.u16s.len * sizeof(uint16_t))
+		&& a.u32s.len == b.u32s.len && !memcmp(a.u32s.list, b.u32s.list, a.u32s.len * sizeof(uint32_t))
+		&& a.u64s.len == b.u64s.len && !memcmp(a.u64s.list, b.u64s.list, a.u64s.len * sizeof(uint64_t))
+		&& a.i32s.len == b.i32s.len && !memcmp(a.i32s.list, b.i32s.list, a.i32s.len * sizeof(int32_t))
+		&& a.i64s.len == b.i64s.len && !memcmp(a.i64s.list, b.i64s.list, a.i64s.len * sizeof(int64_t))
+		&& a.es.len == b.es.len && !memcmp(a.es.list, b.es.list, a.es.len * sizeof(gen_color))
+		&& a.mt.len == b.mt.len
+		&& a.mu.len == b.mu.len && !memcmp(a.mu.keys, b.mu.keys, a.mu.len * sizeof(uint32_t)) && !memcmp(a.mu.values, b.mu.values, a.mu.len * sizeof(int64_t))
+		&& a.mi.len == b.mi.len && !memcmp(a.mi.keys, b.mi.keys, a.mi.len * sizeof(int64_t))
+		&& a.mo.len == b.mo.len
+		&& a.gap == b.gap
+		&& a.host.len == b.host.len && !memcmp(a.host.utf8, b.host.utf8, a.host.len)
+		&& a.tags.len == b.tags.len
+		&& a.ob == b.ob && a.has_ob == b.has_ob
+		&& a.ou32 == b.ou32 && a.has_ou32 == b.has_ou32
+		&& a.oi64 == b.oi64 && a.has_oi64 == b.has_oi64
+		&& a.of64 == b.of64 && a.has_of64 == b.has_of64
+		&& gen_pick_equal(&a.u, &b.u)
 	))
 		return 0;
 
@@ -68,15 +91,52 @@ int gen_o_equal(const gen_o* pa, const gen_o* pb) {
 	for (size_t i = 0, n = a.os.len; i < n; ++i)
 		if (!gen_o_equal(&a.os.list[i], &b.os.list[i])) return 0;
 
+	for (size_t i = 0, n = a.mt.len; i < n; ++i) {
+		colfer_text ka = a.mt.keys[i], kb = b.mt.keys[i];
+		if (ka.len != kb.len || memcmp(ka.utf8, kb.utf8, ka.len)) return 0;
+		colfer_text va = a.mt.values[i], vb = b.mt.values[i];
+		if (va.len != vb.len || memcmp(va.utf8, vb.utf8, va.len)) return 0;
+	}
+
+	for (size_t i = 0, n = a.mi.len; i < n; ++i) {
+		colfer_binary va = a.mi.values[i], vb = b.mi.values[i];
+		if (va.len != vb.len || memcmp(va.octets, vb.octets, va.len)) return 0;
+	}
+
+	for (size_t i = 0, n = a.mo.len; i < n; ++i) {
+		colfer_text ka = a.mo.keys[i], kb = b.mo.keys[i];
+		if (ka.len != kb.len || memcmp(ka.utf8, kb.utf8, ka.len)) return 0;
+		if (!gen_o_equal(&a.mo.values[i], &b.mo.values[i])) return 0;
+	}
+
+	for (size_t i = 0, n = a.tags.len; i < n; ++i) {
+		colfer_text sa = a.tags.list[i], sb = b.tags.list[i];
+		if (sa.len != sb.len || memcmp(sa.utf8, sb.utf8, sa.len)) return 0;
+	}
+
 	return 1;
 }
 
+int gen_pick_equal(const gen_pick* pa, const gen_pick* pb) {
+	if (pa->type != pb->type) return 0;
+	switch (pa->type) {
+	case GEN_PICK_O:
+		return gen_o_equal(pa->o, pb->o);
+	case GEN_PICK_POINT:
+		if (pa->point == NULL || pb->point == NULL) return pa->point == pb->point;
+		return pa->point->x == pb->point->x && pa->point->y == pb->point->y;
+	default:
+		return 1;
+	}
+}
+
 void gen_o_dump(const gen_o o) {
 	char* buf = malloc(colfer_size_max * 2 + 1);
 
 	printf("{ ");
 	if (o.b) printf("b=true ");
 	if (o.u8) printf("u8=%" PRIu8 " ", o.u8);
+	if (o.e) printf("e=%" PRIu8 " ", o.e);
 	if (o.u16) printf("u16=%" PRIu16 " ", o.u16);
 	if (o.u32) printf("u32=%" PRIu32 " ", o.u32);
 	if (o.i64) printf("i64=%" PRId64 " ", o.i64);
@@ -135,6 +195,109 @@ void gen_o_dump(const gen_o o) {
 		}
 		printf("] ");
 	}
+	if (o.bs.len) {
+		printf("bs=[");
+		for (size_t i = 0; i < o.bs.len; ++i)
+			printf(" %d", o.bs.list[i]);
+		printf(" ] ");
+	}
+	if (o.u8s.len) {
+		hexstr(buf, o.u8s.list, o.u8s.len);
+		printf("u8s=0x%s ", buf);
+	}
+	if (o.u16s.len) {
+		printf("u16s=[");
+		for (size_t i = 0; i < o.u16s.len; ++i)
+			printf(" %" PRIu16, o.u16s.list[i]);
+		printf(" ] ");
+	}
+	if (o.u32s.len) {
+		printf("u32s=[");
+		for (size_t i = 0; i < o.u32s.len; ++i)
+			printf(" %" PRIu32, o.u32s.list[i]);
+		printf(" ] ");
+	}
+	if (o.u64s.len) {
+		printf("u64s=[");
+		for (size_t i = 0; i < o.u64s.len; ++i)
+			printf(" %" PRIu64, o.u64s.list[i]);
+		printf(" ] ");
+	}
+	if (o.i32s.len) {
+		printf("i32s=[");
+		for (size_t i = 0; i < o.i32s.len; ++i)
+			printf(" %" PRId32, o.i32s.list[i]);
+		printf(" ] ");
+	}
+	if (o.i64s.len) {
+		printf("i64s=[");
+		for (size_t i = 0; i < o.i64s.len; ++i)
+			printf(" %" PRId64, o.i64s.list[i]);
+		printf(" ] ");
+	}
+	if (o.es.len) {
+		printf("es=[");
+		for (size_t i = 0; i < o.es.len; ++i)
+			printf(" %" PRIu8, o.es.list[i]);
+		printf(" ] ");
+	}
+	if (o.mt.len) {
+		printf("mt=[");
+		for (size_t i = 0; i < o.mt.len; ++i) {
+			hexstr(buf, o.mt.keys[i].utf8, o.mt.keys[i].len);
+			printf(" 0x%s:", buf);
+			hexstr(buf, o.mt.values[i].utf8, o.mt.values[i].len);
+			printf("0x%s", buf);
+		}
+		printf(" ] ");
+	}
+	if (o.mu.len) {
+		printf("mu=[");
+		for (size_t i = 0; i < o.mu.len; ++i)
+			printf(" %" PRIu32 ":%" PRId64, o.mu.keys[i], o.mu.values[i]);
+		printf(" ] ");
+	}
+	if (o.mi.len) {
+		printf("mi=[");
+		for (size_t i = 0; i < o.mi.len; ++i) {
+			hexstr(buf, o.mi.values[i].octets, o.mi.values[i].len);
+			printf(" %" PRId64 ":0x%s", o.mi.keys[i], buf);
+		}
+		printf(" ] ");
+	}
+	if (o.mo.len) {
+		printf("mo=[");
+		for (size_t i = 0; i < o.mo.len; ++i) {
+			hexstr(buf, o.mo.keys[i].utf8, o.mo.keys[i].len);
+			printf(" 0x%s:", buf);
+			gen_o_dump(o.mo.values[i]);
+		}
+		printf(" ] ");
+	}
+	if (o.gap) printf("gap=true ");
+	if (o.host.len) {
+		hexstr(buf, o.host.utf8, o.host.len);
+		printf("host=0x%s ", buf);
+	}
+	if (o.tags.len) {
+		printf("tags=[");
+		for (size_t i = 0; i < o.tags.len; ++i) {
+			hexstr(buf, o.tags.list[i].utf8, o.tags.list[i].len);
+			printf(" 0x%s", buf);
+		}
+		printf(" ] ");
+	}
+	if (o.has_ob) printf("ob=%s ", o.ob ? "true" : "false");
+	if (o.has_ou32) printf("ou32=%" PRIu32 " ", o.ou32);
+	if (o.has_oi64) printf("oi64=%" PRId64 " ", o.oi64);
+	if (o.has_of64) printf("of64=%f ", o.of64);
+	if (o.u.type == GEN_PICK_O) {
+		printf("u=o:");
+		if (o.u.o) gen_o_dump(*o.u.o);
+		printf(" ");
+	}
+	if (o.u.type == GEN_PICK_POINT && o.u.point)
+		printf("u=point:{x=%" PRId32 " y=%" PRId32 "} ", o.u.point->x, o.u.point->y);
 	putchar('}');
 
 	free(buf);
@@ -144,6 +307,14 @@ int main() {
 	const int n = sizeof(golden_cases) / sizeof(golden);
 	printf("got %d golden cases\n", n);
 
+	printf("TEST constants...\n");
+	if (GEN_CONST_U32 != UINT32_MAX)
+		printf("got uint32 constant %" PRIu32 ", want %" PRIu32 "\n", GEN_CONST_U32, UINT32_MAX);
+	if (GEN_CONST_I32 != INT32_MIN)
+		printf("got int32 constant %" PRId32 ", want %" PRId32 "\n", GEN_CONST_I32, INT32_MIN);
+	if (strcmp(GEN_CONST_TEXT, "\"\xcf\x80\"\t?\?="))
+		printf("got text constant %s\n", GEN_CONST_TEXT);
+
 	printf("TEST equality...\n");
 	for (int i = 0; i < n; ++i) {
 		const gen_o* a = &golden_cases[i].o;
@@ -237,6 +408,117 @@ int main() {
 			errno = 0;
 		}
 		colfer_size_max = 16 * 1024 * 1024;
+
+		// explicit limits:
+		colfer_limits limits = colfer_defaults();
+		for (limits.size_max = 0; limits.size_max < len; ++limits.size_max) {
+			gen_o o = {0};
+			size_t read = gen_o_unmarshal_with(&o, buf, len, &limits);
+			if (read || errno != EFBIG)
+				printf("0x%s: unmarshal with read %zu and errno %d for size maximum %zu\n", g.hex, read, errno, limits.size_max);
+
+			errno = 0;
+		}
+		limits.size_max = len + 1;
+		gen_o o = {0};
+		size_t read = gen_o_unmarshal_with(&o, buf, len, &limits);
+		if (read != len)
+			printf("0x%s: unmarshal with read %zu and errno %d for size maximum %zu\n", g.hex, read, errno, limits.size_max);
+		errno = 0;
+	}
+
+	printf("TEST malformed lengths...\n");
+	{
+		// length varints beyond the bit size of size_t
+		const char* headers[] = {"\x19", "\x1b", "\x0c\x01"};
+		for (size_t i = 0; i < sizeof headers / sizeof *headers; ++i) {
+			char data[32];
+			size_t len = strlen(headers[i]);
+			memcpy(data, headers[i], len);
+			memset(data + len, 0x80, 12);
+			len += 12;
+			data[len++] = 1;
+			data[len++] = 127;
+
+			gen_o o = {0};
+			size_t read = gen_o_unmarshal(&o, data, len);
+			if (read || errno != EILSEQ)
+				printf("0x%02hhx: unmarshal read %zu and errno %d\n", headers[i][0], read, errno);
+			errno = 0;
+		}
+	}
+
+	printf("TEST depth limit...\n");
+	{
+		// nested o fields one level beyond colfer_depth_max
+		size_t depth = colfer_depth_max + 1;
+		size_t len = 2 * depth - 1;
+		char* data = malloc(len);
+		for (size_t i = 0; i < len; ++i) data[i] = i < depth - 1 ? 10 : 127;
+
+		gen_o o = {0};
+		size_t read = gen_o_unmarshal(&o, data, len);
+		if (read || errno != ELOOP)
+			printf("depth %zu: unmarshal read %zu and errno %d\n", depth, read, errno);
+		errno = 0;
+
+		colfer_limits limits = colfer_defaults();
+		++limits.depth_max;
+		gen_o deep = {0};
+		read = gen_o_unmarshal_with(&deep, data, len, &limits);
+		if (read != len)
+			printf("depth %zu: unmarshal with one more level read %zu and errno %d\n", depth, read, errno);
+		errno = 0;
+
+		size_t got = gen_o_marshal_len(&deep);
+		if (got || errno != ELOOP)
+			printf("depth %zu: got marshal length %zu and errno %d\n", depth, got, errno);
+		errno = 0;
+		got = gen_o_marshal_len_with(&deep, &limits);
+		if (got != len)
+			printf("depth %zu: got marshal length %zu and errno %d with one more level\n", depth, got, errno);
+		errno = 0;
+
+		free(data);
+	}
+
+	printf("TEST reference cycles...\n");
+	{
+		gen_o self = {0};
+		self.o = &self;
+		gen_o list = {0};
+		list.os.list = &list;
+		list.os.len = 1;
+		gen_o pick = {0};
+		pick.u.type = GEN_PICK_O;
+		pick.u.o = &pick;
+		gen_o pair = {0}, peer = {0};
+		pair.o = &peer;
+		peer.o = &pair;
+
+		const gen_o* cycles[] = {&self, &list, &pick, &pair};
+		const char* names[] = {"self", "list", "union", "pair"};
+		for (size_t i = 0; i < sizeof cycles / sizeof *cycles; ++i) {
+			size_t got = gen_o_marshal_len(cycles[i]);
+			if (got || errno != ELOOP)
+				printf("%s: got marshal length %zu and errno %d\n", names[i], got, errno);
+			errno = 0;
+		}
+	}
+
+	printf("TEST map order...\n");
+	{
+		gen_o o = {.mu = {.keys = (uint32_t[2]) {2, 1}, .values = (int64_t[2]) {0, 0}, .len = 2}};
+		size_t got = gen_o_marshal_len(&o);
+		if (got || errno != EINVAL)
+			printf("descending keys: got marshal length %zu and errno %d\n", got, errno);
+		errno = 0;
+
+		const char* data = "\x1c\x02\x01\x01\x01\x02\x7f";
+		size_t read = gen_o_unmarshal(&o, data, 7);
+		if (read || errno != EILSEQ)
+			printf("duplicate keys: unmarshal read %zu and errno %d\n", read, errno);
+		errno = 0;
 	}
 
 	free(buf);
@@ -15,6 +15,7 @@ const struct golden golden_cases[] = {
 	{"01017f", {.u32 = 1}},
 	{"01ff017f", {.u32 = UINT8_MAX}},
 	{"01ffff037f", {.u32 = UINT16_MAX}},
+	{"01ff7f7f", {.u32 = 16383}},
 	{"81ffffffff7f", {.u32 = UINT32_MAX}},
 	{"02017f", {.u64 = 1}},
 	{"02ff017f", {.u64 = UINT8_MAX}},
@@ -69,5 +70,33 @@ const struct golden golden_cases[] = {
 	{"8f017f", {.u16 = 1}},
 	{"0fffff7f", {.u16 = UINT16_MAX}},
 	{"1002000000003f8000007f", {.f32s = {.list = (float[2]) {0.0f, 1.0f}, .len = 2}}},
-	{"11014058c000000000007f", {.f64s = {.list = (double[1]) {99.0}, .len = 1}}}
+	{"11014058c000000000007f", {.f64s = {.list = (double[1]) {99.0}, .len = 1}}},
+	{"12017f", {.e = GEN_COLOR_GREEN}},
+	{"12ff7f", {.e = UINT8_MAX}},
+	{"13030100017f", {.bs = {.list = (char[3]) {1, 0, 1}, .len = 3}}},
+	{"140200ff7f", {.u8s = {.list = (uint8_t[2]) {0, UINT8_MAX}, .len = 2}}},
+	{"150201ffff037f", {.u16s = {.list = (uint16_t[2]) {1, UINT16_MAX}, .len = 2}}},
+	{"1601ffffffff0f7f", {.u32s = {.list = (uint32_t[1]) {UINT32_MAX}, .len = 1}}},
+	{"170201ffffffffffffffffff7f", {.u64s = {.list = (uint64_t[2]) {1, UINT64_MAX}, .len = 2}}},
+	{"1501ff7f7f", {.u16s = {.list = (uint16_t[1]) {16383}, .len = 1}}},
+	{"1602ff7fffff7f7f", {.u32s = {.list = (uint32_t[2]) {16383, 2097151}, .len = 2}}},
+	{"1702ff7fffffffffffffff0f7f", {.u64s = {.list = (uint64_t[2]) {16383, 9007199254740991}, .len = 2}}},
+	{"18030102ffffffff0f7f", {.i32s = {.list = (int32_t[3]) {-1, 1, INT32_MIN}, .len = 3}}},
+	{"1902fffffffffffffffffffeffffffffffffffff7f", {.i64s = {.list = (int64_t[2]) {INT64_MIN, INT64_MAX}, .len = 2}}},
+	{"1a0201ff7f", {.es = {.list = (gen_color[2]) {GEN_COLOR_GREEN, UINT8_MAX}, .len = 2}}},
+	{"1b02000178016101627f", {.mt = {.keys = (colfer_text[2]) {{.utf8 = "", .len = 0}, {.utf8 = "a", .len = 1}}, .values = (colfer_text[2]) {{.utf8 = "x", .len = 1}, {.utf8 = "b", .len = 1}}, .len = 2}}},
+	{"1c020101ffffffff0ffeffffffffffffffff7f", {.mu = {.keys = (uint32_t[2]) {1, UINT32_MAX}, .values = (int64_t[2]) {-1, INT64_MAX}, .len = 2}}},
+	{"1d020101ff00007f", {.mi = {.keys = (int64_t[2]) {-1, 0}, .values = (colfer_binary[2]) {{.octets = (uint8_t*) "\xff", .len = 1}, {.octets = (uint8_t*) "", .len = 0}}, .len = 2}}},
+	{"1e01016f007f7f", {.mo = {.keys = (colfer_text[1]) {{.utf8 = "o", .len = 1}}, .values = (gen_o[1]) {{.b = 1}}, .len = 1}}},
+	{"207f", {.gap = 1}},
+	{"2104616263647f", {.host = {.utf8 = "abcd", .len = 4}}},
+	{"2202016101627f", {.tags = {.list = (colfer_text[2]) {{.utf8 = "a", .len = 1}, {.utf8 = "b", .len = 1}}, .len = 2 }}},
+	{"237f", {.ob = 1, .has_ob = 1}},
+	{"a37f", {.ob = 0, .has_ob = 1}},
+	{"24007f", {.has_ou32 = 1}},
+	{"25007f", {.has_oi64 = 1}},
+	{"a5017f", {.oi64 = -1, .has_oi64 = 1}},
+	{"2600000000000000007f", {.has_of64 = 1}},
+	{"27007f7f", {.u = {.type = GEN_PICK_O, .o = &((gen_o) {.b = 0})}}},
+	{"270100017f7f", {.u = {.type = GEN_PICK_POINT, .point = &((gen_point) {.x = 1})}}}
 };
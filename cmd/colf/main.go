@@ -1,7 +1,12 @@
 package main
 
 import (
+	"bufio"
+	"encoding/json"
 	"flag"
+	"fmt"
+	"go/scanner"
+	"io"
 	"io/ioutil"
 	"log"
 	"os"
@@ -26,22 +31,47 @@ var (
 	format  = flag.Bool("f", false, "Normalizes the format of all input schemas on the fly.")
 	verbose = flag.Bool("v", false, "Enables verbose reporting to "+italic+"standard error"+clear+".")
 
-	sizeMax = flag.String("s", "16 * 1024 * 1024", "Sets the default upper limit for serial byte sizes. The\n`expression` is applied to the target language under the name\nColferSizeMax.")
-	listMax = flag.String("l", "64 * 1024", "Sets the default upper limit for the number of elements in a\nlist. The `expression` is applied to the target language under\nthe name ColferListMax.")
+	sizeMax  = flag.String("s", "16 * 1024 * 1024", "Sets the default upper limit for serial byte sizes. The\n`expression` is applied to the target language under the name\nColferSizeMax.")
+	listMax  = flag.String("l", "64 * 1024", "Sets the default upper limit for the number of elements in a\nlist. The `expression` is applied to the target language under\nthe name ColferListMax.")
+	depthMax = flag.String("d", "100", "Sets the default upper limit for the nesting depth of data\nstructures. The `expression` is applied to the target language\nunder the name ColferDepthMax.")
 
-	superClass = flag.String("x", "", "Makes all generated classes extend a super `class`. Use slash as\na package separator. Java only.")
+	superClass  = flag.String("x", "", "Makes all generated classes extend a super `class`. Use slash as\na package separator. Java only.")
+	zeroCopy    = flag.Bool("z", false, "Makes unmarshalled binaries and texts share memory with the\nserial data, without a copy. Go only.")
+	keepUnknown = flag.Bool("u", false, "Keeps unknown fields at the end of whole serials, and writes them\nback when marshalling. Fields from a newer schema pass as is.")
+	typeName    = flag.String("t", "", "Selects the data structure by qualified `name`, e.g., pkg.struct.\nDecode and encode only.")
 )
 
 var report = log.New(ioutil.Discard, "", 0)
 
 func main() {
 	flag.Parse()
+	mode := strings.ToLower(flag.Arg(0))
+	if mode == "decode" || mode == "encode" {
+		// options may follow the mode
+		flag.CommandLine.Parse(flag.Args()[1:])
+	}
 
 	log.SetFlags(0)
 	if *verbose {
 		report.SetOutput(os.Stderr)
 	}
 
+	switch mode {
+	case "compat":
+		if flag.NArg() != 3 {
+			flag.Usage()
+			os.Exit(2)
+		}
+		compat(flag.Arg(1), flag.Arg(2))
+		return
+	case "decode":
+		decode(selectStruct(flag.Args()))
+		return
+	case "encode":
+		encode(selectStruct(flag.Args()))
+		return
+	}
+
 	var files []string
 	switch args := flag.Args(); len(args) {
 	case 0:
@@ -55,6 +85,8 @@ func main() {
 
 	// select language
 	var gen func(string, colfer.Packages) error
+	// lint checks any definitions, including none
+	var lint bool
 	switch lang := flag.Arg(0); strings.ToLower(lang) {
 	case "c":
 		report.Println("Set up for C")
@@ -62,6 +94,9 @@ func main() {
 		if *superClass != "" {
 			log.Fatal("colf: super class not supported with C")
 		}
+		if *zeroCopy {
+			log.Fatal("colf: zero-copy not supported with C")
+		}
 
 	case "go":
 		report.Println("Set up for Go")
@@ -73,6 +108,9 @@ func main() {
 	case "java":
 		report.Println("Set up for Java")
 		gen = colfer.GenerateJava
+		if *zeroCopy {
+			log.Fatal("colf: zero-copy not supported with Java")
+		}
 
 	case "javascript", "js", "ecmascript":
 		report.Println("Set up for ECMAScript")
@@ -80,12 +118,59 @@ func main() {
 		if *superClass != "" {
 			log.Fatal("colf: super class not supported with ECMAScript")
 		}
+		if *zeroCopy {
+			log.Fatal("colf: zero-copy not supported with ECMAScript")
+		}
+
+	case "lint":
+		report.Println("Set up for name checks in all languages")
+		lint = true
+		gen = func(_ string, packages colfer.Packages) error {
+			return colfer.Lint(packages)
+		}
 
 	default:
 		log.Fatalf("colf: unsupported language %q", lang)
 	}
 
-	// resolve clean file set
+	files = resolveFiles(files)
+	packages := parseFiles(files)
+
+	if *format {
+		for _, file := range files {
+			changed, err := colfer.Format(file)
+			if err != nil {
+				log.Fatal(err)
+			}
+			if changed {
+				log.Println("colf: formatted", file)
+			}
+		}
+	}
+
+	if len(packages) == 0 && !lint {
+		log.Fatal("colf: no struct definitons found")
+	}
+
+	for _, p := range packages {
+		p.Name = path.Join(*prefix, p.Name)
+		p.SizeMax = *sizeMax
+		p.ListMax = *listMax
+		p.DepthMax = *depthMax
+		p.SuperClass = *superClass
+		p.ZeroCopy = *zeroCopy
+		p.KeepUnknown = *keepUnknown
+	}
+
+	if err := gen(*basedir, packages); err != nil {
+		scanner.PrintError(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// resolveFiles returns the schema files for the operands, with directories
+// expanded and duplicates removed.
+func resolveFiles(files []string) []string {
 	var writeIndex int
 	for i := 0; i < len(files); i++ {
 		f := files[i]
@@ -118,36 +203,114 @@ func main() {
 	}
 	files = files[:writeIndex]
 	report.Println("Found schema files", strings.Join(files, ", "))
+	return files
+}
 
+// parseFiles returns the schema definitions or exits on error.
+func parseFiles(files []string) colfer.Packages {
 	packages, err := colfer.ParseFiles(files)
 	if err != nil {
-		log.Fatal(err)
+		// one line per error as file:line:column: message
+		scanner.PrintError(os.Stderr, err)
+		os.Exit(1)
+	}
+	return packages
+}
+
+// compat reports the changes from the old to the new schema operand, and it
+// exits with status 3 when any of them breaks compatibility.
+func compat(old, new string) {
+	breaking, safe := colfer.Compare(parseFiles(resolveFiles([]string{old})), parseFiles(resolveFiles([]string{new})))
+	for _, c := range safe {
+		fmt.Println(c)
+	}
+	for _, c := range breaking {
+		fmt.Fprintln(os.Stderr, c)
 	}
+	if len(breaking) != 0 {
+		os.Exit(3)
+	}
+}
 
-	if *format {
-		for _, file := range files {
-			changed, err := colfer.Format(file)
-			if err != nil {
-				log.Fatal(err)
-			}
-			if changed {
-				log.Println("colf: formatted", file)
+// selectStruct returns the definition named by the -t option, with the
+// limits of the -s, -l and -d options.
+func selectStruct(files []string) *colfer.Struct {
+	if *typeName == "" {
+		log.Fatal("colf: need a data structure name (option -t)")
+	}
+	if len(files) == 0 {
+		files = []string{"."}
+	}
+	var s *colfer.Struct
+	for _, p := range parseFiles(resolveFiles(files)) {
+		p.SizeMax = *sizeMax
+		p.ListMax = *listMax
+		p.DepthMax = *depthMax
+		for _, o := range p.Structs {
+			if o.String() == *typeName {
+				s = o
 			}
 		}
 	}
-
-	if len(packages) == 0 {
-		log.Fatal("colf: no struct definitons found")
+	if s == nil {
+		log.Fatalf("colf: struct %s not found", *typeName)
 	}
+	return s
+}
 
-	for _, p := range packages {
-		p.Name = path.Join(*prefix, p.Name)
-		p.SizeMax = *sizeMax
-		p.ListMax = *listMax
-		p.SuperClass = *superClass
+// decode writes the serials from standard input as JSON on standard output,
+// one line per serial.
+func decode(s *colfer.Struct) {
+	data, err := ioutil.ReadAll(os.Stdin)
+	if err != nil {
+		log.Fatal(err)
+	}
+	out := bufio.NewWriter(os.Stdout)
+	var offset int
+	for serial := 0; offset < len(data); serial++ {
+		n, err := colfer.DecodeJSON(out, s, data[offset:])
+		switch err {
+		case nil:
+			break
+		case io.EOF:
+			out.Flush()
+			log.Fatalf("colf: serial %d at byte %d incomplete; %d bytes remain", serial, offset, len(data)-offset)
+		default:
+			out.Flush()
+			log.Fatalf("colf: serial %d at byte %d: %s", serial, offset, err)
+		}
+		out.WriteByte('\n')
+		report.Printf("Serial %d at byte %d has %d bytes", serial, offset, n)
+		offset += n
 	}
+	if err := out.Flush(); err != nil {
+		log.Fatal(err)
+	}
+}
 
-	if err := gen(*basedir, packages); err != nil {
+// encode writes the JSON objects from standard input as serials on standard
+// output.
+func encode(s *colfer.Struct) {
+	in := json.NewDecoder(bufio.NewReader(os.Stdin))
+	out := bufio.NewWriter(os.Stdout)
+	for object := 0; ; object++ {
+		var raw json.RawMessage
+		if err := in.Decode(&raw); err == io.EOF {
+			break
+		} else if err != nil {
+			out.Flush()
+			log.Fatalf("colf: object %d: %s", object, err)
+		}
+
+		serial, err := colfer.EncodeJSON(s, raw)
+		if err != nil {
+			out.Flush()
+			log.Fatalf("colf: object %d: %s", object, err)
+		}
+		out.Write(serial)
+		report.Printf("Object %d has %d bytes", object, len(serial))
+	}
+	if err := out.Flush(); err != nil {
 		log.Fatal(err)
 	}
 }
@@ -158,27 +321,55 @@ func init() {
 	help := bold + "NAME\n\t" + cmd + clear + " \u2014 compile Colfer schemas\n\n"
 	help += bold + "SYNOPSIS\n\t" + cmd + clear
 	help += " [ " + underline + "options" + clear + " ] " + underline + "language" + clear
-	help += " [ " + underline + "file" + clear + " " + underline + "..." + clear + " ]\n\n"
+	help += " [ " + underline + "file" + clear + " " + underline + "..." + clear + " ]\n"
+	help += "\t" + bold + cmd + clear + " [ " + underline + "options" + clear + " ] " + bold + "compat" + clear
+	help += " " + underline + "old" + clear + " " + underline + "new" + clear + "\n"
+	help += "\t" + bold + cmd + clear + " [ " + underline + "options" + clear + " ] " + bold + "decode" + clear
+	help += " -t " + underline + "name" + clear + " [ " + underline + "file" + clear + " " + underline + "..." + clear + " ]\n"
+	help += "\t" + bold + cmd + clear + " [ " + underline + "options" + clear + " ] " + bold + "encode" + clear
+	help += " -t " + underline + "name" + clear + " [ " + underline + "file" + clear + " " + underline + "..." + clear + " ]\n\n"
 	help += bold + "DESCRIPTION\n\t" + clear
 	help += "Generates source code for a " + underline + "language" + clear + ". The options are: "
 	help += bold + "C" + clear + ", " + bold + "Go" + clear + ",\n"
-	help += "\t" + bold + "Java" + clear + " and " + bold + "JavaScript" + clear + ".\n"
+	help += "\t" + bold + "Java" + clear + " and " + bold + "JavaScript" + clear + ". The " + bold + "lint" + clear + " option generates\n"
+	help += "\tnothing. Instead, it reports the definitions whose names collide\n"
+	help += "\tin any of the languages.\n"
 	help += "\tThe " + underline + "file" + clear + " operands specify schema input. Directories are scanned\n"
 	help += "\tfor files with the colf extension. When no files are given, then\n"
 	help += "\tthe current " + italic + "working directory" + clear + " is used.\n"
 	help += "\tA package definition may be spread over several schema files.\n"
 	help += "\tThe directory hierarchy of the input is not relevant for the\n"
-	help += "\tgenerated code.\n\n"
+	help += "\tgenerated code.\n"
+	help += "\tThe " + bold + "compat" + clear + " form compares two versions of a schema, each\n"
+	help += "\tgiven as a file or directory. Safe changes, such as renames and\n"
+	help += "\tappended fields, are listed on " + italic + "standard output" + clear + ". Changes which\n"
+	help += "\tbreak existing serials are listed on " + italic + "standard error" + clear + ".\n"
+	help += "\tThe " + bold + "decode" + clear + " form reads serials of the data structure " + underline + "name" + clear + " from\n"
+	help += "\t" + italic + "standard input" + clear + ", and it writes each serial as a line of JSON on\n"
+	help += "\t" + italic + "standard output" + clear + ". The " + underline + "file" + clear + " operands specify the schema. With -v,\n"
+	help += "\tthe offset and size of each serial are reported on " + italic + "standard error" + clear + ".\n"
+	help += "\tThe " + bold + "encode" + clear + " form does the reverse. It reads JSON objects from\n"
+	help += "\t" + italic + "standard input" + clear + ", either one or many, and it writes the serials to\n"
+	help += "\t" + italic + "standard output" + clear + ". The bytes match the generated code.\n\n"
 	help += bold + "OPTIONS\n" + clear
 
 	tail := "\n" + bold + "EXIT STATUS" + clear + "\n"
 	tail += "\tThe command exits 0 on succes, 1 on compilation failure and 2\n"
-	tail += "\twhen invoked without arguments.\n"
+	tail += "\twhen invoked without arguments. Schema errors are reported on\n"
+	tail += "\tstandard error, one per line as file:line:column: message.\n"
+	tail += "\tThe compat form exits 3 when it finds any breaking changes.\n"
 	tail += "\n" + bold + "EXAMPLES" + clear + "\n"
 	tail += "\tCompile ./io.colf with compact limits as C:\n\n"
 	tail += "\t\t" + cmd + " -b src -s 2048 -l 96 C io.colf\n\n"
 	tail += "\tCompile ./api/*.colf in package com.example as Java:\n\n"
-	tail += "\t\t" + cmd + " -p com/example -x com/example/Parent Java api\n"
+	tail += "\t\t" + cmd + " -p com/example -x com/example/Parent Java api\n\n"
+	tail += "\tVerify that ./api still reads the serials of release 1.0:\n\n"
+	tail += "\t\tgit worktree add /tmp/v1.0 v1.0\n"
+	tail += "\t\t" + cmd + " compat /tmp/v1.0/api api\n\n"
+	tail += "\tShow the content of a serial from package api:\n\n"
+	tail += "\t\t" + cmd + " decode -t api.request api < request.bin\n\n"
+	tail += "\tMake a serial from package api:\n\n"
+	tail += "\t\techo '{\"host\":\"example.com\"}' | " + cmd + " encode -t api.request api > request.bin\n"
 	tail += "\n" + bold + "BUGS" + clear + "\n"
 	tail += "\tReport bugs at <https://github.com/pascaldekloe/colfer/issues>.\n\n"
 	tail += "\tText validation is not part of the marshalling and unmarshalling\n"
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestMain runs the command instead of the tests when the environment says
+// so, with the arguments after "--".
+func TestMain(m *testing.M) {
+	if os.Getenv("COLF_TEST_MAIN") == "" {
+		os.Exit(m.Run())
+	}
+	for i, arg := range os.Args {
+		if arg == "--" {
+			os.Args = append([]string{"colf"}, os.Args[i+1:]...)
+			break
+		}
+	}
+	main()
+	os.Exit(0)
+}
+
+// run executes the command with args and returns the exit status.
+func run(t *testing.T, args ...string) (status int, stdout, stderr string) {
+	cmd := exec.Command(os.Args[0], append([]string{"--"}, args...)...)
+	cmd.Env = append(os.Environ(), "COLF_TEST_MAIN=1")
+	var outBuf, errBuf bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &outBuf, &errBuf
+	err := cmd.Run()
+	if err != nil {
+		exit, ok := err.(*exec.ExitError)
+		if !ok {
+			t.Fatal(err)
+		}
+		status = exit.ExitCode()
+	}
+	return status, outBuf.String(), errBuf.String()
+}
+
+func TestCompatExit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "colf-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	write := func(name, schema string) string {
+		file := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(file, []byte(schema), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return file
+	}
+	v1 := write("v1.colf", "package gen\n\ntype o struct {\n\ta bool\n\tb text\n}\n")
+	v2 := write("v2.colf", "package gen\n\ntype o struct {\n\ta bool\n\tb text\n\tc int32\n}\n")
+	v3 := write("v3.colf", "package gen\n\ntype o struct {\n\ta bool\n}\n")
+
+	status, stdout, stderr := run(t, "compat", v1, v2)
+	if status != 0 {
+		t.Errorf("appended field: got exit status %d, want 0; standard error: %s", status, stderr)
+	}
+	if !strings.Contains(stdout, "field gen.o.c appended") {
+		t.Errorf("appended field: got standard output %q", stdout)
+	}
+
+	status, _, stderr = run(t, "compat", v1, v3)
+	if status != 3 {
+		t.Errorf("removed field: got exit status %d, want 3", status)
+	}
+	if !strings.Contains(stderr, "field gen.o.b with index 1 removed") {
+		t.Errorf("removed field: got standard error %q", stderr)
+	}
+
+	if status, _, _ := run(t, "compat", v1); status != 2 {
+		t.Errorf("missing operand: got exit status %d, want 2", status)
+	}
+}
+
+func TestLintExit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "colf-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// constants only
+	file := filepath.Join(dir, "a.colf")
+	if err := ioutil.WriteFile(file, []byte("package gen\n\nconst (\n\ta text = \"x\"\n\tA text = \"y\"\n)\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	status, _, stderr := run(t, "lint", file)
+	if status != 1 {
+		t.Errorf("constant collision: got exit status %d, want 1", status)
+	}
+	if !strings.Contains(stderr, "Go name A of constant gen.A collides with constant gen.a") {
+		t.Errorf("constant collision: got standard error %q", stderr)
+	}
+
+	empty := filepath.Join(dir, "empty")
+	if err := os.Mkdir(empty, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if status, _, stderr := run(t, "lint", empty); status != 0 {
+		t.Errorf("no definitions: got exit status %d, want 0; standard error: %s", status, stderr)
+	}
+}
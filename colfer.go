@@ -4,7 +4,10 @@ package colfer
 import (
 	"bytes"
 	"fmt"
+	"go/constant"
+	"go/token"
 	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -50,16 +53,30 @@ type Package struct {
 	Docs []string
 	// Structs are the type definitions.
 	Structs []*Struct
+	// Enums are the enumeration definitions.
+	Enums []*Enum
+	// Unions are the choice definitions.
+	Unions []*Union
+	// Consts are the named values other than enumerations.
+	Consts []*Const
 	// SchemaFiles are the source filenames.
 	SchemaFiles []string
 	// SizeMax is the uper limit expression.
 	SizeMax string
 	// ListMax is the uper limit expression.
 	ListMax string
+	// DepthMax is the uper limit expression.
+	DepthMax string
 	// SuperClass is the fully qualified path.
 	SuperClass string
 	// SuperClassNative is the language specific SuperClass.
 	SuperClassNative string
+	// ZeroCopy makes unmarshalled binaries and texts share memory with
+	// the serial. Go only.
+	ZeroCopy bool
+	// KeepUnknown makes whole serials keep any fields beyond the schema
+	// as is, for the marshaller to write them back.
+	KeepUnknown bool
 }
 
 // DocText returns the documentation lines prefixed with ident.
@@ -87,6 +104,12 @@ func (p *Package) Refs() Packages {
 			if f.TypeRef != nil && f.TypeRef.Pkg != p {
 				found[f.TypeRef.Pkg] = struct{}{}
 			}
+			if f.TypeEnum != nil && f.TypeEnum.Pkg != p {
+				found[f.TypeEnum.Pkg] = struct{}{}
+			}
+			if f.TypeUnion != nil && f.TypeUnion.Pkg != p {
+				found[f.TypeUnion.Pkg] = struct{}{}
+			}
 		}
 	}
 
@@ -118,7 +141,7 @@ func (p *Package) HasTimestamp() bool {
 	return false
 }
 
-// HasList returns whether p has one or more list fields.
+// HasList returns whether p has one or more list or map fields.
 func (p *Package) HasList() bool {
 	for _, s := range p.Structs {
 		if s.HasList() {
@@ -128,6 +151,196 @@ func (p *Package) HasList() bool {
 	return false
 }
 
+// HasMap returns whether p has one or more map fields.
+func (p *Package) HasMap() bool {
+	for _, s := range p.Structs {
+		if s.HasMap() {
+			return true
+		}
+	}
+	return false
+}
+
+// HasBinary returns whether p has one or more binary fields.
+func (p *Package) HasBinary() bool {
+	for _, s := range p.Structs {
+		if s.HasBinary() {
+			return true
+		}
+	}
+	return false
+}
+
+// Enum is a named integer type with a set of named values.
+type Enum struct {
+	Pkg *Package
+	// Name is the identification token.
+	Name string
+	// NameNative is the language specific Name.
+	NameNative string
+	// Docs are the documentation texts.
+	Docs []string
+	// Type is the underlying integer datatype.
+	Type string
+	// TypeNative is the language specific Type.
+	TypeNative string
+	// Values are the named elements in order of appearance.
+	Values []*EnumValue
+	// SchemaFile is the source filename.
+	SchemaFile string
+	// Pos is the location of the declaration.
+	Pos token.Position
+}
+
+// NameTitle returns the identification token in title case.
+func (e *Enum) NameTitle() string {
+	return strings.Title(e.Name)
+}
+
+// DocText returns the documentation lines prefixed with ident.
+func (e *Enum) DocText(indent string) string {
+	return docText(e.Docs, indent)
+}
+
+// String returns the qualified name.
+func (e *Enum) String() string {
+	return fmt.Sprintf("%s.%s", e.Pkg.Name, e.Name)
+}
+
+// DistinctValues returns the values without aliases, i.e., only the
+// first name for each number.
+func (e *Enum) DistinctValues() []*EnumValue {
+	var a []*EnumValue
+	seen := make(map[uint64]bool, len(e.Values))
+	for _, v := range e.Values {
+		if !seen[v.Value] {
+			seen[v.Value] = true
+			a = append(a, v)
+		}
+	}
+	return a
+}
+
+// EnumValue is a named Enum element.
+type EnumValue struct {
+	// Enum is the parent.
+	Enum *Enum
+	// Name is the identification token.
+	Name string
+	// NameNative is the language specific Name.
+	NameNative string
+	// Docs are the documentation texts.
+	Docs []string
+	// Value is the numeric representation.
+	Value uint64
+	// ValueNative is the language specific Value.
+	ValueNative string
+	// Pos is the location of the declaration.
+	Pos token.Position
+}
+
+// NameTitle returns the identification token in title case.
+func (v *EnumValue) NameTitle() string {
+	return strings.Title(v.Name)
+}
+
+// DocText returns the documentation lines prefixed with ident.
+func (v *EnumValue) DocText(indent string) string {
+	return docText(v.Docs, indent)
+}
+
+// String returns the qualified name.
+func (v *EnumValue) String() string {
+	return fmt.Sprintf("%s.%s", v.Enum.Pkg.Name, v.Name)
+}
+
+// Const is a named value of a Colfer datatype.
+type Const struct {
+	Pkg *Package
+	// Name is the identification token.
+	Name string
+	// NameNative is the language specific Name.
+	NameNative string
+	// Docs are the documentation texts.
+	Docs []string
+	// Type is the Colfer datatype.
+	Type string
+	// TypeNative is the language specific Type.
+	TypeNative string
+	// Value is the exact representation, conform Type.
+	Value constant.Value
+	// ValueNative is the language specific Value.
+	ValueNative string
+	// SchemaFile is the source filename.
+	SchemaFile string
+	// Pos is the location of the declaration.
+	Pos token.Position
+}
+
+// NameTitle returns the identification token in title case.
+func (c *Const) NameTitle() string {
+	return strings.Title(c.Name)
+}
+
+// DocText returns the documentation lines prefixed with ident.
+func (c *Const) DocText(indent string) string {
+	return docText(c.Docs, indent)
+}
+
+// String returns the qualified name.
+func (c *Const) String() string {
+	return fmt.Sprintf("%s.%s", c.Pkg.Name, c.Name)
+}
+
+// Union is a named choice of data structures.
+type Union struct {
+	Pkg *Package
+	// Name is the identification token.
+	Name string
+	// NameNative is the language specific Name.
+	NameNative string
+	// Docs are the documentation texts.
+	Docs []string
+	// Members are the options in order of appearance.
+	Members []*UnionMember
+	// SchemaFile is the source filename.
+	SchemaFile string
+	// Pos is the location of the declaration.
+	Pos token.Position
+}
+
+// NameTitle returns the identification token in title case.
+func (u *Union) NameTitle() string {
+	return strings.Title(u.Name)
+}
+
+// DocText returns the documentation lines prefixed with ident.
+func (u *Union) DocText(indent string) string {
+	return docText(u.Docs, indent)
+}
+
+// String returns the qualified name.
+func (u *Union) String() string {
+	return fmt.Sprintf("%s.%s", u.Pkg.Name, u.Name)
+}
+
+// UnionMember is a Union option.
+type UnionMember struct {
+	// Union is the parent.
+	Union *Union
+	// Index is the serial discriminator, which is the position in the
+	// Members of Union.
+	Index int
+	// Name is the identification token of Struct.
+	Name string
+	// NameNative is the language specific Name.
+	NameNative string
+	// ValueNative is the language specific discriminator.
+	ValueNative string
+	// Struct is the data structure reference.
+	Struct *Struct
+}
+
 // Struct is a data structure definition.
 type Struct struct {
 	Pkg *Package
@@ -137,10 +350,14 @@ type Struct struct {
 	NameNative string
 	// Docs are the documentation texts.
 	Docs []string
-	// Fields are the elements in order of appearance.
+	// Fields are the elements in order of Index.
 	Fields []*Field
+	// Unions are the choices with s as a member.
+	Unions []*Union
 	// SchemaFile is the source filename.
 	SchemaFile string
+	// Pos is the location of the declaration.
+	Pos token.Position
 }
 
 // NameTitle returns the identification token in title case.
@@ -158,6 +375,14 @@ func (s *Struct) String() string {
 	return fmt.Sprintf("%s.%s", s.Pkg.Name, s.Name)
 }
 
+// IndexMax returns the highest field index, or -1 without any fields.
+func (s *Struct) IndexMax() int {
+	if len(s.Fields) == 0 {
+		return -1
+	}
+	return s.Fields[len(s.Fields)-1].Index
+}
+
 // HasFloat returns whether s has one or more floating point fields.
 func (s *Struct) HasFloat() bool {
 	for _, f := range s.Fields {
@@ -168,10 +393,10 @@ func (s *Struct) HasFloat() bool {
 	return false
 }
 
-// HasText returns whether s has one or more text fields.
+// HasText returns whether s has one or more text fields, including map keys.
 func (s *Struct) HasText() bool {
 	for _, f := range s.Fields {
-		if f.Type == "text" {
+		if f.Type == "text" || f.TypeKey == "text" {
 			return true
 		}
 	}
@@ -198,6 +423,16 @@ func (s *Struct) HasBinaryList() bool {
 	return false
 }
 
+// HasBinaryMap returns whether s has one or more map fields with binary values.
+func (s *Struct) HasBinaryMap() bool {
+	for _, f := range s.Fields {
+		if f.Type == "binary" && f.TypeKey != "" {
+			return true
+		}
+	}
+	return false
+}
+
 // HasTimestamp returns whether s has one or more timestamp fields.
 func (s *Struct) HasTimestamp() bool {
 	for _, f := range s.Fields {
@@ -208,10 +443,31 @@ func (s *Struct) HasTimestamp() bool {
 	return false
 }
 
-// HasList returns whether s has one or more list fields.
+// HasList returns whether s has one or more list or map fields.
 func (s *Struct) HasList() bool {
 	for _, f := range s.Fields {
-		if f.TypeList {
+		if f.TypeList || f.TypeKey != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// HasMap returns whether s has one or more map fields.
+func (s *Struct) HasMap() bool {
+	for _, f := range s.Fields {
+		if f.TypeKey != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// HasRef returns whether s has one or more fields with nested data
+// structures, including unions.
+func (s *Struct) HasRef() bool {
+	for _, f := range s.Fields {
+		if f.TypeRef != nil || f.TypeUnion != nil {
 			return true
 		}
 	}
@@ -222,10 +478,13 @@ func (s *Struct) HasList() bool {
 type Field struct {
 	// Struct is the parent.
 	Struct *Struct
-	// Index is the Struct.Fields position.
+	// Index is the serial identification, which defaults to the
+	// declaration position, counting on from the preceding field.
 	Index int
 	// Name is the identification token.
 	Name string
+	// Pos is the location of the declaration.
+	Pos token.Position
 	// NameNative is the language specific Name.
 	NameNative string
 	// Docs are the documentation texts.
@@ -236,8 +495,28 @@ type Field struct {
 	TypeNative string
 	// TypeRef is the Colfer data structure reference.
 	TypeRef *Struct
+	// TypeEnum is the Colfer enumeration reference.
+	// When set, Type holds the underlying integer datatype.
+	TypeEnum *Enum
+	// TypeUnion is the Colfer union reference.
+	TypeUnion *Union
 	// TypeList flags whether the datatype is a list.
 	TypeList bool
+	// Optional flags whether the presence of a scalar is tracked, such
+	// that zero values are serialized rather than omitted.
+	Optional bool
+	// SizeMax is the upper limit for the octet size of text and binary
+	// data, including list elements and map entries. The empty string
+	// defaults to the package limit.
+	SizeMax string
+	// ListMax is the upper limit for the number of elements in a list or
+	// map. The empty string defaults to the package limit.
+	ListMax string
+	// TypeKey is the key datatype for maps, which is either text or one
+	// of the integer types. Type, TypeRef and TypeEnum apply to the values.
+	TypeKey string
+	// TypeKeyNative is the language specific TypeKey.
+	TypeKeyNative string
 }
 
 // NameTitle returns the identification token in title case.
@@ -275,3 +554,14 @@ func docText(docs []string, indent string) string {
 
 	return buf.String()
 }
+
+// floatLiteral returns the shortest decimal notation of the floating point
+// value in v, with either a decimal point or an exponent.
+func floatLiteral(v constant.Value, bitSize int) string {
+	f, _ := constant.Float64Val(v)
+	s := strconv.FormatFloat(f, 'g', -1, bitSize)
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	return s
+}
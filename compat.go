@@ -0,0 +1,287 @@
+package colfer
+
+import (
+	"bytes"
+	"fmt"
+	"go/token"
+	"sort"
+	"strconv"
+)
+
+// Change is a difference between two versions of a schema.
+type Change struct {
+	// Pos is the location in the new version, or the location in the
+	// old version in case of a removal.
+	Pos token.Position
+	// Msg is the description.
+	Msg string
+}
+
+// String returns the description with its location, if any.
+func (c *Change) String() string {
+	if c.Pos.IsValid() {
+		return c.Pos.String() + ": " + c.Msg
+	}
+	return c.Msg
+}
+
+// Compare returns the differences between two versions of a schema. Data
+// structures match on their qualified name and fields match on their index.
+// Breaking changes alter the interpretation of serials, such as a removed
+// field, a different datatype or a lower limit. Enumeration values match on
+// their name, and any removal or renumbering is a breaking change. Safe changes
+// include renamed fields, new fields and new enumeration values. Both slices
+// are sorted by position.
+func Compare(from, to Packages) (breaking, safe []*Change) {
+	fromStructs := make(map[string]*Struct)
+	for _, p := range from {
+		for _, s := range p.Structs {
+			fromStructs[s.String()] = s
+		}
+	}
+	toStructs := make(map[string]*Struct)
+	for _, p := range to {
+		for _, s := range p.Structs {
+			toStructs[s.String()] = s
+		}
+	}
+
+	for _, p := range from {
+		for _, s := range p.Structs {
+			if _, ok := toStructs[s.String()]; !ok {
+				breaking = append(breaking, &Change{s.Pos, fmt.Sprintf("struct %s removed", s)})
+			}
+		}
+		for _, u := range p.Unions {
+			if findUnion(to, u.String()) == nil {
+				breaking = append(breaking, &Change{u.Pos, fmt.Sprintf("union %s removed", u)})
+			}
+		}
+		for _, e := range p.Enums {
+			if findEnum(to, e.String()) == nil {
+				breaking = append(breaking, &Change{e.Pos, fmt.Sprintf("enumeration %s removed", e)})
+			}
+		}
+	}
+
+	for _, p := range to {
+		for _, s := range p.Structs {
+			old, ok := fromStructs[s.String()]
+			if !ok {
+				safe = append(safe, &Change{s.Pos, fmt.Sprintf("struct %s added", s)})
+				continue
+			}
+			b, c := compareFields(old, s)
+			breaking = append(breaking, b...)
+			safe = append(safe, c...)
+		}
+		for _, u := range p.Unions {
+			old := findUnion(from, u.String())
+			if old == nil {
+				safe = append(safe, &Change{u.Pos, fmt.Sprintf("union %s added", u)})
+				continue
+			}
+			for i, m := range u.Members {
+				switch {
+				case i >= len(old.Members):
+					safe = append(safe, &Change{u.Pos, fmt.Sprintf("union %s member %s appended", u, m.Name)})
+				case old.Members[i].Name != m.Name:
+					breaking = append(breaking, &Change{u.Pos, fmt.Sprintf("union %s member %d changed from %s to %s", u, i, old.Members[i].Name, m.Name)})
+				}
+			}
+			if len(old.Members) > len(u.Members) {
+				breaking = append(breaking, &Change{u.Pos, fmt.Sprintf("union %s members removed", u)})
+			}
+		}
+		for _, e := range p.Enums {
+			old := findEnum(from, e.String())
+			if old == nil {
+				safe = append(safe, &Change{e.Pos, fmt.Sprintf("enumeration %s added", e)})
+				continue
+			}
+			b, c := compareEnumValues(old, e)
+			breaking = append(breaking, b...)
+			safe = append(safe, c...)
+		}
+	}
+
+	sortChanges(breaking)
+	sortChanges(safe)
+	return breaking, safe
+}
+
+// compareFields returns the differences between two versions of a data structure.
+func compareFields(from, to *Struct) (breaking, safe []*Change) {
+	toFields := make(map[int]*Field, len(to.Fields))
+	toNames := make(map[string]*Field, len(to.Fields))
+	for _, f := range to.Fields {
+		toFields[f.Index] = f
+		toNames[f.Name] = f
+	}
+
+	fromFields := make(map[int]*Field, len(from.Fields))
+	fromNames := make(map[string]*Field, len(from.Fields))
+	var fromIndexMax int
+	for _, f := range from.Fields {
+		fromFields[f.Index] = f
+		fromNames[f.Name] = f
+		if f.Index > fromIndexMax {
+			fromIndexMax = f.Index
+		}
+
+		n, ok := toFields[f.Index]
+		if !ok {
+			if moved, ok := toNames[f.Name]; ok {
+				breaking = append(breaking, &Change{moved.Pos, fmt.Sprintf("field %s moved from index %d to %d", moved, f.Index, moved.Index)})
+			} else {
+				breaking = append(breaking, &Change{f.Pos, fmt.Sprintf("field %s with index %d removed", f, f.Index)})
+			}
+			continue
+		}
+
+		if f.Name != n.Name {
+			if moved, ok := toNames[f.Name]; ok {
+				breaking = append(breaking, &Change{moved.Pos, fmt.Sprintf("field %s moved from index %d to %d", moved, f.Index, moved.Index)})
+				continue
+			}
+		}
+		if was, is := wireType(f), wireType(n); was != is {
+			breaking = append(breaking, &Change{n.Pos, fmt.Sprintf("field %s datatype changed from %s to %s", n, was, is)})
+			continue
+		}
+		if f.Name != n.Name {
+			safe = append(safe, &Change{n.Pos, fmt.Sprintf("field %s renamed from %s", n, f.Name)})
+		}
+		if shrunk(f.SizeMax, n.SizeMax) {
+			breaking = append(breaking, &Change{n.Pos, fmt.Sprintf("field %s size limit lowered to %s", n, n.SizeMax)})
+		} else if f.SizeMax != n.SizeMax {
+			safe = append(safe, &Change{n.Pos, fmt.Sprintf("field %s size limit raised", n)})
+		}
+		if shrunk(f.ListMax, n.ListMax) {
+			breaking = append(breaking, &Change{n.Pos, fmt.Sprintf("field %s list limit lowered to %s", n, n.ListMax)})
+		} else if f.ListMax != n.ListMax {
+			safe = append(safe, &Change{n.Pos, fmt.Sprintf("field %s list limit raised", n)})
+		}
+	}
+
+	for _, f := range to.Fields {
+		if _, ok := fromFields[f.Index]; ok {
+			continue
+		}
+		if _, ok := fromNames[f.Name]; ok {
+			continue // reported as moved
+		}
+		if f.Index > fromIndexMax {
+			safe = append(safe, &Change{f.Pos, fmt.Sprintf("field %s appended with index %d", f, f.Index)})
+		} else {
+			safe = append(safe, &Change{f.Pos, fmt.Sprintf("field %s added with index %d", f, f.Index)})
+		}
+	}
+
+	return breaking, safe
+}
+
+// compareEnumValues returns the differences between two versions of an
+// enumeration.
+func compareEnumValues(from, to *Enum) (breaking, safe []*Change) {
+	if from.Type != to.Type {
+		breaking = append(breaking, &Change{to.Pos, fmt.Sprintf("enumeration %s datatype changed from %s to %s", to, from.Type, to.Type)})
+	}
+
+	toValues := make(map[string]*EnumValue, len(to.Values))
+	for _, v := range to.Values {
+		toValues[v.Name] = v
+	}
+	fromValues := make(map[string]*EnumValue, len(from.Values))
+	for _, v := range from.Values {
+		fromValues[v.Name] = v
+
+		n, ok := toValues[v.Name]
+		switch {
+		case !ok:
+			breaking = append(breaking, &Change{v.Pos, fmt.Sprintf("enumeration value %s (%d) removed", v, v.Value)})
+		case n.Value != v.Value:
+			breaking = append(breaking, &Change{n.Pos, fmt.Sprintf("enumeration value %s changed from %d to %d", n, v.Value, n.Value)})
+		}
+	}
+
+	for _, v := range to.Values {
+		if _, ok := fromValues[v.Name]; !ok {
+			safe = append(safe, &Change{v.Pos, fmt.Sprintf("enumeration value %s (%d) added", v, v.Value)})
+		}
+	}
+	return breaking, safe
+}
+
+// wireType returns a description of the serial format of f.
+// Enumerations are interchangeable with their underlying integer type.
+func wireType(f *Field) string {
+	var buf bytes.Buffer
+	if f.TypeKey != "" {
+		buf.WriteString("map[" + f.TypeKey + "]")
+	}
+	if f.TypeList {
+		buf.WriteString("[]")
+	}
+	if f.Optional {
+		buf.WriteByte('*')
+	}
+	switch {
+	case f.TypeRef != nil:
+		buf.WriteString(f.TypeRef.String())
+	case f.TypeUnion != nil:
+		buf.WriteString(f.TypeUnion.String())
+	default:
+		buf.WriteString(f.Type)
+	}
+	return buf.String()
+}
+
+// shrunk returns whether limit to is lower than limit from. The empty
+// string represents the package default, which is treated as unlimited.
+func shrunk(from, to string) bool {
+	if to == "" {
+		return false
+	}
+	if from == "" {
+		return true
+	}
+	a, _ := strconv.ParseUint(from, 10, 31)
+	b, _ := strconv.ParseUint(to, 10, 31)
+	return b < a
+}
+
+func findUnion(packages Packages, qname string) *Union {
+	for _, p := range packages {
+		for _, u := range p.Unions {
+			if u.String() == qname {
+				return u
+			}
+		}
+	}
+	return nil
+}
+
+func findEnum(packages Packages, qname string) *Enum {
+	for _, p := range packages {
+		for _, e := range p.Enums {
+			if e.String() == qname {
+				return e
+			}
+		}
+	}
+	return nil
+}
+
+func sortChanges(a []*Change) {
+	sort.SliceStable(a, func(i, j int) bool {
+		p, q := a[i].Pos, a[j].Pos
+		if p.Filename != q.Filename {
+			return p.Filename < q.Filename
+		}
+		if p.Line != q.Line {
+			return p.Line < q.Line
+		}
+		return p.Column < q.Column
+	})
+}
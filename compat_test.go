@@ -0,0 +1,241 @@
+package colfer
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// compareSchemas returns the changes from schema from to schema to as text,
+// with the base name of the file in the positions.
+func compareSchemas(t *testing.T, from, to string) (breaking, safe []string) {
+	dir, err := ioutil.TempDir("", "colfer-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	parse := func(name, schema string) Packages {
+		file := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(file, []byte(schema), 0644); err != nil {
+			t.Fatal(err)
+		}
+		packages, err := ParseFiles([]string{file})
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		return packages
+	}
+	b, s := Compare(parse("old.colf", from), parse("new.colf", to))
+
+	format := func(changes []*Change) []string {
+		var a []string
+		for _, c := range changes {
+			a = append(a, fmt.Sprintf("%s:%d:%d: %s", filepath.Base(c.Pos.Filename), c.Pos.Line, c.Pos.Column, c.Msg))
+		}
+		return a
+	}
+	return format(b), format(s)
+}
+
+func TestCompare(t *testing.T) {
+	const base = `package gen
+
+type color uint8
+
+const (
+	red color = iota
+	green
+)
+
+type o struct {
+	a bool
+	b text ` + "`colfer:\"max=10\"`" + `
+	c []int64
+	d o
+}
+`
+
+	for _, gold := range []struct {
+		label, schema  string
+		breaking, safe []string
+	}{
+		{"same", base, nil, nil},
+		{"reorder", `package gen
+
+type color uint8
+
+const (
+	red color = iota
+	green
+)
+
+type o struct {
+	b text ` + "`colfer:\"max=10\"`" + `
+	a bool
+	c []int64
+	d o
+}
+`, []string{
+			"new.colf:11:2: field gen.o.b moved from index 1 to 0",
+			"new.colf:12:2: field gen.o.a moved from index 0 to 1",
+		}, nil},
+		{"removal", `package gen
+
+type color uint8
+
+const (
+	red color = iota
+	green
+)
+
+type o struct {
+	a bool
+	b text ` + "`colfer:\"max=10\"`" + `
+	c []int64
+}
+`, []string{
+			"old.colf:14:2: field gen.o.d with index 3 removed",
+		}, nil},
+		{"type change", `package gen
+
+type color uint8
+
+const (
+	red color = iota
+	green
+)
+
+type o struct {
+	a uint8
+	b []text ` + "`colfer:\"max=10\"`" + `
+	c int64
+	d *color
+}
+`, []string{
+			"new.colf:11:2: field gen.o.a datatype changed from bool to uint8",
+			"new.colf:12:2: field gen.o.b datatype changed from text to []text",
+			"new.colf:13:2: field gen.o.c datatype changed from []int64 to int64",
+			"new.colf:14:2: field gen.o.d datatype changed from gen.o to *uint8",
+		}, nil},
+		{"lowered limits", `package gen
+
+type color uint8
+
+const (
+	red color = iota
+	green
+)
+
+type o struct {
+	a bool
+	b text ` + "`colfer:\"max=9\"`" + `
+	c []int64 ` + "`colfer:\"list=2\"`" + `
+	d o
+}
+`, []string{
+			"new.colf:12:2: field gen.o.b size limit lowered to 9",
+			"new.colf:13:2: field gen.o.c list limit lowered to 2",
+		}, nil},
+		{"raised limit", `package gen
+
+type color uint8
+
+const (
+	red color = iota
+	green
+)
+
+type o struct {
+	a bool
+	b text
+	c []int64
+	d o
+}
+`, nil, []string{
+			"new.colf:12:2: field gen.o.b size limit raised",
+		}},
+		{"rename and append", `package gen
+
+type color uint8
+
+const (
+	red color = iota
+	green
+)
+
+type o struct {
+	flag bool
+	b text ` + "`colfer:\"max=10\"`" + `
+	c []int64
+	d o
+	e color
+}
+`, nil, []string{
+			"new.colf:11:2: field gen.o.flag renamed from a",
+			"new.colf:15:2: field gen.o.e appended with index 4",
+		}},
+		{"enumeration values", `package gen
+
+type color uint8
+
+const (
+	green color = iota
+	blue
+)
+
+type o struct {
+	a bool
+	b text ` + "`colfer:\"max=10\"`" + `
+	c []int64
+	d o
+}
+`, []string{
+			"new.colf:6:2: enumeration value gen.green changed from 1 to 0",
+			"old.colf:6:2: enumeration value gen.red (0) removed",
+		}, []string{
+			"new.colf:7:2: enumeration value gen.blue (1) added",
+		}},
+		{"enumeration removal", `package gen
+
+type o struct {
+	a bool
+	b text ` + "`colfer:\"max=10\"`" + `
+	c []int64
+	d o
+}
+`, []string{
+			"old.colf:3:6: enumeration gen.color removed",
+		}, nil},
+		{"enumeration type", `package gen
+
+type color uint16
+
+const (
+	red color = iota
+	green
+)
+
+type o struct {
+	a bool
+	b text ` + "`colfer:\"max=10\"`" + `
+	c []int64
+	d o
+}
+`, []string{
+			"new.colf:3:6: enumeration gen.color datatype changed from uint8 to uint16",
+		}, nil},
+	} {
+		t.Run(gold.label, func(t *testing.T) {
+			breaking, safe := compareSchemas(t, base, gold.schema)
+			t.Run("breaking", func(t *testing.T) {
+				verifyErrors(t, breaking, gold.breaking)
+			})
+			t.Run("safe", func(t *testing.T) {
+				verifyErrors(t, safe, gold.safe)
+			})
+		})
+	}
+}
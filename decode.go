@@ -0,0 +1,760 @@
+package colfer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// Default limits, conform the colf(1) options.
+const (
+	defaultSizeMax  = 16 * 1024 * 1024
+	defaultListMax  = 64 * 1024
+	defaultDepthMax = 100
+)
+
+// DecodeJSON interprets the serial at the start of data as s, and it writes
+// the content to w as a JSON object, without any generated code. The return
+// is the number of bytes read, such that concatenated serials can be decoded
+// in sequence. Only the fields present in the serial are written, without
+// any zero values, conform the generated MarshalJSON.
+// The error return is io.EOF when data ends before the serial does.
+func DecodeJSON(w io.Writer, s *Struct, data []byte) (n int, err error) {
+	d := NewDynamic(s)
+	n, err = d.Unmarshal(data)
+	if err != nil {
+		return 0, err
+	}
+
+	var buf bytes.Buffer
+	writeJSON(&buf, d)
+	if _, err := buf.WriteTo(w); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// limits are the effective upper bounds of a package.
+type limits struct {
+	sizeMax, listMax, depthMax int
+}
+
+// limitSet caches the evaluation per package.
+type limitSet map[*Package]limits
+
+// pkg returns the limits of p, with the colf(1) defaults for absent
+// expressions.
+func (set limitSet) pkg(p *Package) (limits, error) {
+	l, ok := set[p]
+	if ok {
+		return l, nil
+	}
+	var err error
+	if l.sizeMax, err = evalLimit(p.SizeMax, defaultSizeMax); err != nil {
+		return l, fmt.Errorf("colfer: package %s size limit: %s", p.Name, err)
+	}
+	if l.listMax, err = evalLimit(p.ListMax, defaultListMax); err != nil {
+		return l, fmt.Errorf("colfer: package %s list limit: %s", p.Name, err)
+	}
+	if l.depthMax, err = evalLimit(p.DepthMax, defaultDepthMax); err != nil {
+		return l, fmt.Errorf("colfer: package %s depth limit: %s", p.Name, err)
+	}
+	set[p] = l
+	return l, nil
+}
+
+// field returns the limits of f.
+func (set limitSet) field(f *Field) (limits, error) {
+	l, err := set.pkg(f.Struct.Pkg)
+	if err != nil {
+		return l, err
+	}
+	if f.SizeMax != "" {
+		l.sizeMax, _ = strconv.Atoi(f.SizeMax)
+	}
+	if f.ListMax != "" {
+		l.listMax, _ = strconv.Atoi(f.ListMax)
+	}
+	return l, nil
+}
+
+// decoder interprets serials conform the generated Unmarshal methods.
+type decoder struct {
+	data []byte
+	// i is the read index in data.
+	i      int
+	limits limitSet
+	// depthMax is the number of struct levels left to enter.
+	depthMax int
+}
+
+// evalLimit returns the value of a constant integer expression.
+func evalLimit(expr string, def int) (int, error) {
+	if expr == "" {
+		return def, nil
+	}
+	tv, err := types.Eval(token.NewFileSet(), nil, token.NoPos, expr)
+	if err != nil {
+		return 0, err
+	}
+	if tv.Value == nil || tv.Value.Kind() != constant.Int {
+		return 0, fmt.Errorf("%q is not an integer constant", expr)
+	}
+	v, ok := constant.Int64Val(tv.Value)
+	if !ok || v < 0 || v > math.MaxInt32 {
+		return 0, fmt.Errorf("%q out of range", expr)
+	}
+	return int(v), nil
+}
+
+// next returns the following n bytes.
+func (d *decoder) next(n int) ([]byte, error) {
+	if n > len(d.data)-d.i {
+		return nil, io.EOF
+	}
+	b := d.data[d.i : d.i+n]
+	d.i += n
+	return b, nil
+}
+
+func (d *decoder) byte() (byte, error) {
+	if d.i >= len(d.data) {
+		return 0, io.EOF
+	}
+	c := d.data[d.i]
+	d.i++
+	return c, nil
+}
+
+// varint reads an unsigned LEB128 where the ninth byte, if any, holds
+// the 8 most significant bits. The generated code does so for 64-bit fields,
+// and for the integers in lists and maps.
+func (d *decoder) varint() (uint64, error) {
+	var x uint64
+	for shift := uint(0); ; shift += 7 {
+		c, err := d.byte()
+		if err != nil {
+			return 0, err
+		}
+		if c < 0x80 || shift == 56 {
+			return x | uint64(c)<<shift, nil
+		}
+		x |= uint64(c&0x7f) << shift
+	}
+}
+
+// uvarint reads an unsigned LEB128 of any length, where bits beyond the 64th
+// are lost. The generated code does so for 32-bit fields, which keep the 32
+// least significant bits only, and for sizes and element counts.
+func (d *decoder) uvarint() (uint64, error) {
+	var x uint64
+	for shift := uint(0); ; shift += 7 {
+		c, err := d.byte()
+		if err != nil {
+			return 0, err
+		}
+		if c < 0x80 {
+			return x | uint64(c)<<shift, nil
+		}
+		x |= uint64(c&0x7f) << shift
+	}
+}
+
+// length reads a size or element count.
+func (d *decoder) length(max int, f *Field, what string) (int, error) {
+	x, err := d.uvarint()
+	if err != nil {
+		return 0, err
+	}
+	if x > uint64(max) {
+		unit := "bytes"
+		if what == "length" {
+			unit = "elements"
+		}
+		return 0, fmt.Errorf("colfer: %s %s %d exceeds %d %s", f, what, x, max, unit)
+	}
+	return int(x), nil
+}
+
+func (d *decoder) readStruct(s *Struct) (*Dynamic, error) {
+	if d.depthMax < 1 {
+		return nil, fmt.Errorf("colfer: struct %s exceeds the nesting depth limit", s)
+	}
+	start := d.i
+	d.depthMax--
+	r, err := d.readFields(s)
+	d.depthMax++
+	if err == nil && d.i-start < d.limit(s) {
+		return r, nil
+	}
+	if err == io.EOF && len(d.data)-start < d.limit(s) {
+		return nil, err
+	}
+	if err == nil || err == io.EOF {
+		err = fmt.Errorf("colfer: struct %s size exceeds %d bytes", s, d.limit(s))
+	}
+	return nil, err
+}
+
+// limit returns the size limit of s.
+func (d *decoder) limit(s *Struct) int {
+	l, err := d.limits.pkg(s.Pkg)
+	if err != nil {
+		return defaultSizeMax
+	}
+	return l.sizeMax
+}
+
+func (d *decoder) readFields(s *Struct) (*Dynamic, error) {
+	if _, err := d.limits.pkg(s.Pkg); err != nil {
+		return nil, err
+	}
+	r := NewDynamic(s)
+
+	header, err := d.byte()
+	if err != nil {
+		return nil, err
+	}
+	for fi, f := range s.Fields {
+		if header&0x7f != byte(f.Index) {
+			continue
+		}
+		flag := header&0x80 != 0
+		if flag && !hasFlag(f) {
+			break
+		}
+
+		if r.values[fi], err = d.readField(f, flag); err != nil {
+			return nil, err
+		}
+		if header, err = d.byte(); err != nil {
+			return nil, err
+		}
+	}
+	if header != 0x7f {
+		return nil, fmt.Errorf("colfer: unknown header at byte %d", d.i-1)
+	}
+	return r, nil
+}
+
+// hasFlag returns whether the header of f may have its most significant
+// bit set.
+func hasFlag(f *Field) bool {
+	if f.TypeList || f.TypeKey != "" {
+		return false
+	}
+	switch f.Type {
+	case "bool":
+		return f.Optional
+	case "uint16", "uint32", "uint64", "int32", "int64", "timestamp":
+		return true
+	}
+	return false
+}
+
+func (d *decoder) readField(f *Field, flag bool) (interface{}, error) {
+	switch {
+	case f.TypeKey != "":
+		return d.readMap(f)
+	case f.TypeUnion != nil:
+		return d.readUnion(f.TypeUnion)
+	case f.TypeList:
+		return d.readList(f)
+	case f.TypeRef != nil:
+		return d.readStruct(f.TypeRef)
+	}
+
+	switch f.Type {
+	case "bool":
+		return !flag, nil
+	case "uint8":
+		return d.byte()
+	case "uint16":
+		if flag {
+			c, err := d.byte()
+			return uint16(c), err
+		}
+		b, err := d.next(2)
+		if err != nil {
+			return nil, err
+		}
+		return binary.BigEndian.Uint16(b), nil
+	case "uint32":
+		if flag {
+			b, err := d.next(4)
+			if err != nil {
+				return nil, err
+			}
+			return binary.BigEndian.Uint32(b), nil
+		}
+		x, err := d.uvarint()
+		return uint32(x), err
+	case "uint64":
+		if flag {
+			b, err := d.next(8)
+			if err != nil {
+				return nil, err
+			}
+			return binary.BigEndian.Uint64(b), nil
+		}
+		return d.varint()
+	case "int32":
+		x, err := d.uvarint()
+		if flag {
+			x = -x
+		}
+		return int32(x), err
+	case "int64":
+		x, err := d.varint()
+		if flag {
+			x = -x
+		}
+		return int64(x), err
+	case "float32":
+		b, err := d.next(4)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float32frombits(binary.BigEndian.Uint32(b)), nil
+	case "float64":
+		b, err := d.next(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), nil
+	case "timestamp":
+		n := 8
+		if flag {
+			n = 12
+		}
+		b, err := d.next(n)
+		if err != nil {
+			return nil, err
+		}
+		var sec int64
+		if flag {
+			sec = int64(binary.BigEndian.Uint64(b))
+		} else {
+			sec = int64(binary.BigEndian.Uint32(b))
+		}
+		return time.Unix(sec, int64(binary.BigEndian.Uint32(b[n-4:]))).In(time.UTC), nil
+	case "text", "binary":
+		l, err := d.limits.field(f)
+		if err != nil {
+			return nil, err
+		}
+		size, err := d.length(l.sizeMax, f, "size")
+		if err != nil {
+			return nil, err
+		}
+		return d.readData(f.Type, size)
+	}
+	return nil, fmt.Errorf("colfer: %s has unsupported type %q", f, f.Type)
+}
+
+// readData reads a text or binary of size bytes.
+func (d *decoder) readData(typ string, size int) (interface{}, error) {
+	b, err := d.next(size)
+	if err != nil {
+		return nil, err
+	}
+	if typ == "text" {
+		return string(b), nil
+	}
+	return append([]byte(nil), b...), nil
+}
+
+// readElem reads a list element or map value of f.
+func (d *decoder) readElem(f *Field, l limits) (interface{}, error) {
+	if f.TypeRef != nil {
+		return d.readStruct(f.TypeRef)
+	}
+
+	switch f.Type {
+	case "bool":
+		c, err := d.byte()
+		return c != 0, err
+	case "uint8":
+		return d.byte()
+	case "uint16", "uint32", "uint64", "int32", "int64":
+		x, err := d.varint()
+		return fromZigZag(f.Type, x), err
+	case "float32":
+		b, err := d.next(4)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float32frombits(binary.BigEndian.Uint32(b)), nil
+	case "float64":
+		b, err := d.next(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), nil
+	case "text", "binary":
+		size, err := d.length(l.sizeMax, f, "size")
+		if err != nil {
+			return nil, err
+		}
+		return d.readData(f.Type, size)
+	}
+	return nil, fmt.Errorf("colfer: %s has unsupported element type %q", f, f.Type)
+}
+
+// fromZigZag returns the value of a varint in lists and maps, where the
+// signed types use zig-zag encoding.
+func fromZigZag(typ string, x uint64) interface{} {
+	switch typ {
+	case "uint8":
+		return uint8(x)
+	case "uint16":
+		return uint16(x)
+	case "uint32":
+		return uint32(x)
+	case "int32":
+		return int32(x>>1) ^ -int32(x&1)
+	case "int64":
+		return int64(x>>1) ^ -int64(x&1)
+	}
+	return x
+}
+
+func (d *decoder) readList(f *Field) (interface{}, error) {
+	l, err := d.limits.field(f)
+	if err != nil {
+		return nil, err
+	}
+	n, err := d.length(l.listMax, f, "length")
+	if err != nil {
+		return nil, err
+	}
+	// each element takes at least one byte
+	if n > len(d.data)-d.i {
+		return nil, io.EOF
+	}
+
+	a := make([]interface{}, n)
+	for ai := range a {
+		if a[ai], err = d.readElem(f, l); err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
+}
+
+func (d *decoder) readMap(f *Field) (interface{}, error) {
+	l, err := d.limits.field(f)
+	if err != nil {
+		return nil, err
+	}
+	n, err := d.length(l.listMax, f, "length")
+	if err != nil {
+		return nil, err
+	}
+	// each entry takes at least two bytes
+	if n > (len(d.data)-d.i)/2 {
+		return nil, io.EOF
+	}
+
+	m := make(map[interface{}]interface{}, n)
+	var last interface{}
+	for mi := 0; mi < n; mi++ {
+		start := d.i
+		var k interface{}
+		switch f.TypeKey {
+		case "text":
+			size, err := d.length(l.sizeMax, f, "key size")
+			if err != nil {
+				return nil, err
+			}
+			k, err = d.readData("text", size)
+			if err != nil {
+				return nil, err
+			}
+		case "uint8":
+			if k, err = d.byte(); err != nil {
+				return nil, err
+			}
+		default:
+			x, err := d.varint()
+			if err != nil {
+				return nil, err
+			}
+			k = fromZigZag(f.TypeKey, x)
+		}
+		if mi != 0 && !keyLess(last, k) {
+			return nil, fmt.Errorf("colfer: %s key at byte %d not in ascending order", f, start)
+		}
+		last = k
+
+		if m[k], err = d.readElem(f, l); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func (d *decoder) readUnion(u *Union) (interface{}, error) {
+	c, err := d.byte()
+	if err != nil {
+		return nil, err
+	}
+	if int(c) >= len(u.Members) {
+		return nil, fmt.Errorf("colfer: unknown header at byte %d", d.i-1)
+	}
+	return d.readStruct(u.Members[c].Struct)
+}
+
+// keyLess returns whether map key a sorts before b.
+func keyLess(a, b interface{}) bool {
+	switch a := a.(type) {
+	case string:
+		return a < b.(string)
+	case uint8:
+		return a < b.(uint8)
+	case uint16:
+		return a < b.(uint16)
+	case uint32:
+		return a < b.(uint32)
+	case uint64:
+		return a < b.(uint64)
+	case int32:
+		return a < b.(int32)
+	case int64:
+		return a < b.(int64)
+	}
+	return false
+}
+
+// writeJSON encodes r as an object with the fields in order of appearance.
+// Data structures in a union are written as an object with the member name
+// as "kind" and the data structure as "value".
+func writeJSON(buf *bytes.Buffer, r *Dynamic) {
+	buf.WriteByte('{')
+	var n int
+	for fi, v := range r.values {
+		f := r.Struct.Fields[fi]
+		if v == nil || omitJSON(f, v) {
+			continue
+		}
+		if n != 0 {
+			buf.WriteByte(',')
+		}
+		n++
+
+		writeJSONString(buf, f.Name)
+		buf.WriteByte(':')
+		writeJSONValue(buf, f, v)
+	}
+	buf.WriteByte('}')
+}
+
+// omitJSON returns whether the generated MarshalJSON leaves v out, which is
+// the case for the zero values of fields other than optional ones. Serials
+// from the generated code have no such values, yet others may.
+func omitJSON(f *Field, v interface{}) bool {
+	if f.Optional {
+		return false
+	}
+	switch v := v.(type) {
+	case []interface{}:
+		return len(v) == 0
+	case map[interface{}]interface{}:
+		return len(v) == 0
+	case bool:
+		return !v
+	case uint8:
+		return v == 0
+	case uint16:
+		return v == 0
+	case uint32:
+		return v == 0
+	case uint64:
+		return v == 0
+	case int32:
+		return v == 0
+	case int64:
+		return v == 0
+	case float32:
+		return v == 0
+	case float64:
+		return v == 0
+	case time.Time:
+		return v.IsZero()
+	case string:
+		return v == ""
+	case []byte:
+		return len(v) == 0
+	}
+	return false
+}
+
+func writeJSONValue(buf *bytes.Buffer, f *Field, v interface{}) {
+	switch v := v.(type) {
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, e := range v {
+			if i != 0 {
+				buf.WriteByte(',')
+			}
+			writeJSONValue(buf, f, e)
+		}
+		buf.WriteByte(']')
+
+	case map[interface{}]interface{}:
+		keys := make([]interface{}, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool { return keyLess(keys[i], keys[j]) })
+
+		buf.WriteByte('{')
+		for i, k := range keys {
+			if i != 0 {
+				buf.WriteByte(',')
+			}
+			if s, ok := k.(string); ok {
+				writeJSONString(buf, s)
+			} else {
+				fmt.Fprintf(buf, `"%d"`, k)
+			}
+			buf.WriteByte(':')
+			writeJSONValue(buf, f, v[k])
+		}
+		buf.WriteByte('}')
+
+	case *Dynamic:
+		if f.TypeUnion == nil {
+			writeJSON(buf, v)
+			break
+		}
+		buf.WriteString(`{"kind":`)
+		writeJSONString(buf, v.Struct.Name)
+		buf.WriteString(`,"value":`)
+		writeJSON(buf, v)
+		buf.WriteByte('}')
+
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case float32:
+		writeJSONFloat(buf, float64(v), 32)
+	case float64:
+		writeJSONFloat(buf, v, 64)
+	case time.Time:
+		writeJSONString(buf, v.Format(time.RFC3339Nano))
+	case string:
+		writeJSONString(buf, v)
+	case []byte:
+		writeJSONString(buf, base64.StdEncoding.EncodeToString(v))
+
+	default: // integer
+		if f.TypeEnum != nil {
+			x, _ := strconv.ParseUint(fmt.Sprint(v), 10, 64)
+			for _, ev := range f.TypeEnum.DistinctValues() {
+				if ev.Value == x {
+					writeJSONString(buf, ev.Name)
+					return
+				}
+			}
+		}
+		// beyond the safe integer range of ECMAScript as a string
+		switch x := v.(type) {
+		case uint64:
+			if x > 1<<53-1 {
+				fmt.Fprintf(buf, `"%d"`, x)
+				return
+			}
+		case int64:
+			if x > 1<<53-1 || x < -(1<<53-1) {
+				fmt.Fprintf(buf, `"%d"`, x)
+				return
+			}
+		}
+		fmt.Fprint(buf, v)
+	}
+}
+
+// writeJSONFloat writes NaN and the infinities as a string, since JSON has
+// no notation for them. Other values get the shortest decimal which reads
+// back bit-exactly, in the notation of ECMAScript's Number toString.
+func writeJSONFloat(buf *bytes.Buffer, f float64, bitSize int) {
+	switch {
+	case math.IsNaN(f):
+		buf.WriteString(`"NaN"`)
+	case math.IsInf(f, 1):
+		buf.WriteString(`"Infinity"`)
+	case math.IsInf(f, -1):
+		buf.WriteString(`"-Infinity"`)
+	default:
+		format := byte('f')
+		if abs := math.Abs(f); abs != 0 {
+			if bitSize == 64 && (abs < 1e-6 || abs >= 1e21) || bitSize == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+				format = 'e'
+			}
+		}
+		var a [32]byte
+		b := strconv.AppendFloat(a[:0], f, format, -1, bitSize)
+		// exponent without leading zeros, as in "1e-7"
+		if n := len(b); format == 'e' && b[n-3] == '-' && b[n-2] == '0' {
+			b[n-2] = b[n-1]
+			b = b[:n-1]
+		}
+		buf.Write(b)
+	}
+}
+
+// writeJSONString escapes quotation marks, backslashes and control characters
+// only, like ECMAScript's JSON.stringify. Invalid UTF-8 becomes U+FFFD.
+func writeJSONString(buf *bytes.Buffer, s string) {
+	const hex = "0123456789abcdef"
+	buf.WriteByte('"')
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c >= utf8.RuneSelf {
+			r, size := utf8.DecodeRuneInString(s[i:])
+			if r == utf8.RuneError && size == 1 {
+				buf.WriteRune(utf8.RuneError)
+			} else {
+				buf.WriteString(s[i : i+size])
+			}
+			i += size
+			continue
+		}
+
+		switch c {
+		case '"', '\\':
+			buf.WriteByte('\\')
+			buf.WriteByte(c)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if c < 0x20 {
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hex[c>>4])
+				buf.WriteByte(hex[c&0xf])
+			} else {
+				buf.WriteByte(c)
+			}
+		}
+		i++
+	}
+	buf.WriteByte('"')
+}
@@ -0,0 +1,197 @@
+package colfer
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+// schemaO returns the definition of gen.o from the test schema.
+func schemaO(t *testing.T) *Struct {
+	packages, err := ParseFiles([]string{"testdata/test.colf"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range packages[0].Structs {
+		if s.Name == "o" {
+			return s
+		}
+	}
+	t.Fatal("struct o not found")
+	return nil
+}
+
+// jsonGolden is a serial of gen.o with its JSON.
+type jsonGolden struct{ serial, json string }
+
+// jsonGoldenCases returns the cases shared with the generated code tests.
+func jsonGoldenCases(t *testing.T) []jsonGolden {
+	text, err := ioutil.ReadFile("testdata/json-golden.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cases []jsonGolden
+	for _, line := range strings.Split(string(text), "\n") {
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		i := strings.IndexByte(line, ' ')
+		cases = append(cases, jsonGolden{line[:i], line[i+1:]})
+	}
+	return cases
+}
+
+func TestDecodeJSON(t *testing.T) {
+	s := schemaO(t)
+
+	for _, gold := range jsonGoldenCases(t) {
+		data, err := hex.DecodeString(gold.serial)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var buf bytes.Buffer
+		n, err := DecodeJSON(&buf, s, data)
+		if err != nil {
+			t.Errorf("0x%s: got error %q", gold.serial, err)
+			continue
+		}
+		if n != len(data) {
+			t.Errorf("0x%s: got %d bytes read, want %d", gold.serial, n, len(data))
+		}
+		if got := buf.String(); got != gold.json {
+			t.Errorf("0x%s: got JSON %s, want %s", gold.serial, got, gold.json)
+		}
+		if !json.Valid(buf.Bytes()) {
+			t.Errorf("0x%s: invalid JSON %s", gold.serial, buf.Bytes())
+		}
+
+		for i := range data {
+			incomplete := data[:i]
+			if _, err := DecodeJSON(ioutil.Discard, s, incomplete); err != io.EOF {
+				t.Errorf("0x%s: got error %T: %q", hex.EncodeToString(incomplete), err, err)
+			}
+		}
+	}
+
+	for _, gold := range []jsonGolden{
+		{"7f", `{}`},
+		{"83017f", `{"i32":-1}`},
+		{"848080808080808080807f", `{"i64":"-9223372036854775808"}`},
+		{"057fc000007f", `{"f32":"NaN"}`},
+		{"0755ef312a2e5da4e77f", `{"t":"2015-09-08T19:04:10.777888999Z"}`},
+		{"090202007f", `{"a":"AgA="}`},
+		{"0b027f7f7f", `{"os":[{},{}]}`},
+		{"1a0201ff7f", `{"es":["green",255]}`},
+		{"1d020101ff00007f", `{"mi":{"-1":"/w==","0":""}}`},
+		{"a37f", `{"ob":false}`},
+		{"270100017f7f", `{"u":{"kind":"point","value":{"x":1}}}`},
+	} {
+		data, err := hex.DecodeString(gold.serial)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if _, err := DecodeJSON(&buf, s, data); err != nil {
+			t.Errorf("0x%s: got error %q", gold.serial, err)
+		} else if got := buf.String(); got != gold.json {
+			t.Errorf("0x%s: got JSON %s, want %s", gold.serial, got, gold.json)
+		}
+	}
+
+	for _, serial := range []string{"210561626364657f", "22030161016201637f", "22010261627f", "1b0201620178016101627f", "2703007f7f"} {
+		data, err := hex.DecodeString(serial)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := DecodeJSON(ioutil.Discard, s, data); err == nil || err == io.EOF {
+			t.Errorf("0x%s: got error %v, want rejection", serial, err)
+		}
+	}
+
+	s.Pkg.SizeMax = "2 * 2"
+	for _, gold := range jsonGoldenCases(t) {
+		data, err := hex.DecodeString(gold.serial)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = DecodeJSON(ioutil.Discard, s, data)
+		if len(data) < 4 {
+			if err != nil {
+				t.Errorf("0x%s: got error %q with size limit 4", gold.serial, err)
+			}
+		} else if err == nil || !strings.Contains(err.Error(), "exceeds 4 bytes") {
+			t.Errorf("0x%s: got error %v, want size limit 4 breach", gold.serial, err)
+		}
+	}
+}
+
+// TestDecodeJSONZero verifies that zero values, which the generated code
+// does not marshal, yet does unmarshal, are left out as with the generated
+// MarshalJSON.
+func TestDecodeJSONZero(t *testing.T) {
+	s := schemaO(t)
+
+	for _, gold := range []jsonGolden{
+		{"01007f", `{}`},
+		{"08007f", `{}`},
+		{"09007f", `{}`},
+		{"0b007f", `{}`},
+		{"13007f", `{}`},
+		{"1b007f", `{}`},
+		{"05000000007f", `{}`},
+		{"0e0024007f", `{"ou32":0}`},
+		{"0700000000000000007f", `{"t":"1970-01-01T00:00:00Z"}`},
+	} {
+		data, err := hex.DecodeString(gold.serial)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if _, err := DecodeJSON(&buf, s, data); err != nil {
+			t.Errorf("0x%s: got error %q", gold.serial, err)
+		} else if got := buf.String(); got != gold.json {
+			t.Errorf("0x%s: got JSON %s, want %s", gold.serial, got, gold.json)
+		}
+	}
+}
+
+// TestDecodeOverlong verifies that varints beyond their size read the same
+// as with the generated code. The 32-bit integers and the lengths take any
+// number of bytes, while 64-bit integers end at the ninth byte.
+func TestDecodeOverlong(t *testing.T) {
+	s := schemaO(t)
+
+	for _, gold := range []jsonGolden{
+		{"018180808080808080800e7f", `{"u32":1}`},
+		{"838180808080808080800e7f", `{"i32":-1}`},
+		{"270100818080808080808080007f7f", `{"u":{"kind":"point","value":{"x":1}}}`},
+		{"0881808080808080808000417f", `{"s":"A"}`},
+		{"0b818080808080808080007f7f", `{"os":[{}]}`},
+	} {
+		data, err := hex.DecodeString(gold.serial)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if _, err := DecodeJSON(&buf, s, data); err != nil {
+			t.Errorf("0x%s: got error %q", gold.serial, err)
+		} else if got := buf.String(); got != gold.json {
+			t.Errorf("0x%s: got JSON %s, want %s", gold.serial, got, gold.json)
+		}
+	}
+
+	// The ninth byte of a 64-bit integer has all 8 bits in use, such that
+	// the tenth byte reads as the next header.
+	data, err := hex.DecodeString("04818080808080808080007f")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DecodeJSON(ioutil.Discard, s, data); err == nil || err == io.EOF {
+		t.Errorf("0x%x: got error %v, want rejection", data, err)
+	}
+}
@@ -0,0 +1,225 @@
+package colfer
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+)
+
+// Dynamic is a data structure instance without any generated code. The
+// values follow the Go types of the Colfer datatypes, with time.Time for
+// timestamps, []byte for binaries and *Dynamic for data structures,
+// including union members. Enumerations use their underlying integer type.
+// Lists are []interface{} and maps are map[interface{}]interface{}.
+// Serials from the generated code marshal back bit-exactly.
+type Dynamic struct {
+	// Struct is the definition.
+	Struct *Struct
+	// values has an entry per field in order of Struct.Fields. Absent
+	// fields have a nil value.
+	values []interface{}
+}
+
+// NewDynamic returns an instance of s without any field values.
+func NewDynamic(s *Struct) *Dynamic {
+	return &Dynamic{Struct: s, values: make([]interface{}, len(s.Fields))}
+}
+
+// fieldIndex returns the position of a field in Struct.Fields, or -1 when
+// the name is not defined.
+func (d *Dynamic) fieldIndex(name string) int {
+	for i, f := range d.Struct.Fields {
+		if f.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Get returns the value of a field, or nil when absent or not defined.
+func (d *Dynamic) Get(name string) interface{} {
+	i := d.fieldIndex(name)
+	if i < 0 {
+		return nil
+	}
+	return d.values[i]
+}
+
+// Set assigns the value of a field. The nil value makes the field absent.
+func (d *Dynamic) Set(name string, v interface{}) error {
+	i := d.fieldIndex(name)
+	if i < 0 {
+		return fmt.Errorf("colfer: struct %s has no field %q", d.Struct, name)
+	}
+	if v != nil {
+		if err := checkValue(d.Struct.Fields[i], v); err != nil {
+			return err
+		}
+	}
+	d.values[i] = v
+	return nil
+}
+
+// Range calls fn for each field with a value, in order of appearance,
+// until fn returns false.
+func (d *Dynamic) Range(fn func(f *Field, v interface{}) bool) {
+	for i, v := range d.values {
+		if v != nil && !fn(d.Struct.Fields[i], v) {
+			return
+		}
+	}
+}
+
+// MarshalBinary encodes d as Colfer conform encoding.BinaryMarshaler.
+// The limits apply as with the generated MarshalLen.
+func (d *Dynamic) MarshalBinary() (data []byte, err error) {
+	e := encoder{limits: make(limitSet)}
+	l, err := e.limits.pkg(d.Struct.Pkg)
+	if err != nil {
+		return nil, err
+	}
+	e.depthMax = l.depthMax
+	if err := e.writeStruct(d); err != nil {
+		return nil, err
+	}
+	return e.buf.Bytes(), nil
+}
+
+// Unmarshal decodes data as Colfer and returns the number of bytes read.
+// The error return is io.EOF when data ends before the serial does.
+func (d *Dynamic) Unmarshal(data []byte) (int, error) {
+	dec := decoder{data: data, limits: make(limitSet)}
+	l, err := dec.limits.pkg(d.Struct.Pkg)
+	if err != nil {
+		return 0, err
+	}
+	dec.depthMax = l.depthMax
+	o, err := dec.readStruct(d.Struct)
+	if err != nil {
+		return 0, err
+	}
+	d.values = o.values
+	return dec.i, nil
+}
+
+// UnmarshalBinary decodes data as Colfer conform encoding.BinaryUnmarshaler.
+func (d *Dynamic) UnmarshalBinary(data []byte) error {
+	i, err := d.Unmarshal(data)
+	if i < len(data) && err == nil {
+		return fmt.Errorf("colfer: data continuation at byte %d", i)
+	}
+	return err
+}
+
+// MarshalJSON honors the json.Marshaler interface with the mapping of
+// DecodeJSON.
+func (d *Dynamic) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	writeJSON(&buf, d)
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON honors the json.Unmarshaler interface with the mapping of
+// EncodeJSON. Struct must be set.
+func (d *Dynamic) UnmarshalJSON(data []byte) error {
+	o, err := dynamicFromJSONBytes(d.Struct, data)
+	if err != nil {
+		return err
+	}
+	d.values = o.values
+	return nil
+}
+
+// checkValue returns an error when v does not apply to f.
+func checkValue(f *Field, v interface{}) error {
+	switch {
+	case f.TypeKey != "":
+		m, ok := v.(map[interface{}]interface{})
+		if !ok {
+			return fmt.Errorf("colfer: field %s needs a map[interface{}]interface{}; got %T", f, v)
+		}
+		for k, e := range m {
+			if !isType(f.TypeKey, k) {
+				return fmt.Errorf("colfer: field %s key needs Go type of %s; got %T", f, f.TypeKey, k)
+			}
+			if err := checkElem(f, e); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case f.TypeUnion != nil:
+		o, ok := v.(*Dynamic)
+		if ok && o != nil {
+			for _, m := range f.TypeUnion.Members {
+				if m.Struct == o.Struct {
+					return nil
+				}
+			}
+		}
+		return fmt.Errorf("colfer: field %s needs a *Dynamic with a member of union %s", f, f.TypeUnion)
+
+	case f.TypeList:
+		a, ok := v.([]interface{})
+		if !ok {
+			return fmt.Errorf("colfer: field %s needs a []interface{}; got %T", f, v)
+		}
+		for _, e := range a {
+			if err := checkElem(f, e); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return checkElem(f, v)
+}
+
+// checkElem returns an error when v does not apply to f as a single value,
+// list element or map value.
+func checkElem(f *Field, v interface{}) error {
+	if f.TypeRef != nil {
+		// nil entries serialize as empty, like the generated code
+		if v == nil {
+			return nil
+		}
+		if o, ok := v.(*Dynamic); ok && o != nil && o.Struct == f.TypeRef {
+			return nil
+		}
+		return fmt.Errorf("colfer: field %s needs a *Dynamic of struct %s", f, f.TypeRef)
+	}
+	if !isType(f.Type, v) {
+		return fmt.Errorf("colfer: field %s needs Go type of %s; got %T", f, f.Type, v)
+	}
+	return nil
+}
+
+// isType returns whether v has the Go type of a Colfer datatype.
+func isType(typ string, v interface{}) bool {
+	switch v.(type) {
+	case bool:
+		return typ == "bool"
+	case uint8:
+		return typ == "uint8"
+	case uint16:
+		return typ == "uint16"
+	case uint32:
+		return typ == "uint32"
+	case uint64:
+		return typ == "uint64"
+	case int32:
+		return typ == "int32"
+	case int64:
+		return typ == "int64"
+	case float32:
+		return typ == "float32"
+	case float64:
+		return typ == "float64"
+	case time.Time:
+		return typ == "timestamp"
+	case string:
+		return typ == "text"
+	case []byte:
+		return typ == "binary"
+	}
+	return false
+}
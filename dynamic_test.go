@@ -0,0 +1,90 @@
+package colfer
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestDynamic(t *testing.T) {
+	s := schemaO(t)
+
+	for _, gold := range jsonGoldenCases(t) {
+		data, err := hex.DecodeString(gold.serial)
+		if err != nil {
+			t.Fatal(err)
+		}
+		d := NewDynamic(s)
+		if err := d.UnmarshalBinary(data); err != nil {
+			t.Errorf("0x%s: unmarshal error %q", gold.serial, err)
+			continue
+		}
+		got, err := d.MarshalBinary()
+		if err != nil {
+			t.Errorf("0x%s: marshal error %q", gold.serial, err)
+		} else if !bytes.Equal(got, data) {
+			t.Errorf("0x%s: got serial 0x%x", gold.serial, got)
+		}
+	}
+
+	d := NewDynamic(s)
+	for _, set := range []struct {
+		name  string
+		value interface{}
+	}{
+		{"u8", uint8(7)},
+		{"s", "hello"},
+		{"e", uint8(2)}, // blue
+		{"os", []interface{}{NewDynamic(s), nil}},
+		{"mt", map[interface{}]interface{}{"b": "2", "a": "1"}},
+		{"ob", false},
+	} {
+		if err := d.Set(set.name, set.value); err != nil {
+			t.Fatalf("set %s: %s", set.name, err)
+		}
+	}
+	got, err := d.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = "080568656c6c6f0b027f7f0e0712021b020161013101620132a37f"
+	if hex.EncodeToString(got) != want {
+		t.Errorf("got serial 0x%x, want 0x%s", got, want)
+	}
+
+	if v := d.Get("s"); v != "hello" {
+		t.Errorf("got s %#v, want hello", v)
+	}
+	if v := d.Get("u32"); v != nil {
+		t.Errorf("got absent u32 %#v, want nil", v)
+	}
+	var names []string
+	d.Range(func(f *Field, v interface{}) bool {
+		names = append(names, f.Name)
+		return f.Name != "e"
+	})
+	if got, want := strings.Join(names, " "), "s os u8 e"; got != want {
+		t.Errorf("got range %q, want %q", got, want)
+	}
+
+	point := s.Fields[len(s.Fields)-1].TypeUnion.Members[1].Struct
+	for _, set := range []struct {
+		name  string
+		value interface{}
+	}{
+		{"u8", 7},
+		{"nope", true},
+		{"os", []*Dynamic{}},
+		{"o", NewDynamic(point)},
+		{"u", "point"},
+		{"mu", map[interface{}]interface{}{int64(1): int64(1)}},
+	} {
+		if err := d.Set(set.name, set.value); err == nil {
+			t.Errorf("set %s to %#v: no error", set.name, set.value)
+		}
+	}
+	if err := d.Set("u", NewDynamic(point)); err != nil {
+		t.Errorf("set union member: %s", err)
+	}
+}
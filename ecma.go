@@ -1,6 +1,9 @@
 package colfer
 
 import (
+	"encoding/json"
+	"fmt"
+	"go/constant"
 	"os"
 	"path/filepath"
 	"strings"
@@ -21,12 +24,44 @@ func IsECMAKeyword(s string) bool {
 
 // GenerateECMA writes the code into file "Colfer.js".
 func GenerateECMA(basedir string, packages Packages) error {
+	if err := lintECMA(packages).Err(); err != nil {
+		return err
+	}
+
 	for _, p := range packages {
 		p.NameNative = strings.Replace(p.Name, "/", "_", -1)
 		if IsECMAKeyword(p.NameNative) {
 			p.NameNative += "_"
 		}
 
+		for _, c := range p.Consts {
+			c.NameNative = c.Name
+			switch c.Type {
+			case "bool":
+				c.ValueNative = c.Value.ExactString()
+			case "float32", "float64":
+				c.ValueNative = floatLiteral(c.Value, 64)
+			case "text":
+				b, err := json.Marshal(constant.StringVal(c.Value))
+				if err != nil {
+					return err
+				}
+				c.ValueNative = string(b)
+			default:
+				x, exact := constant.Int64Val(c.Value)
+				if !exact || x > 1<<53-1 || x < 1-1<<53 {
+					return fmt.Errorf("colfer: constant %s value %s exceeds the safe integer range of JavaScript", c, c.Value)
+				}
+				c.ValueNative = c.Value.ExactString()
+			}
+		}
+
+		for _, e := range p.Enums {
+			for _, v := range e.Values {
+				v.NameNative = v.Name
+			}
+		}
+
 		for _, s := range p.Structs {
 			for _, f := range s.Fields {
 				f.NameNative = f.Name
@@ -41,6 +76,10 @@ func GenerateECMA(basedir string, packages Packages) error {
 	template.Must(t.Parse(ecmaCode))
 	template.Must(t.New("marshal").Parse(ecmaMarshal))
 	template.Must(t.New("unmarshal").Parse(ecmaUnmarshal))
+	template.Must(t.New("json").Parse(ecmaJSON))
+	template.Must(t.New("json-value").Parse(ecmaJSONValue))
+	template.Must(t.New("json-decode").Parse(ecmaJSONDecode))
+	template.Must(t.New("json-range").Parse(ecmaJSONRange))
 
 	if err := os.MkdirAll(basedir, os.ModeDir|os.ModePerm); err != nil {
 		return err
@@ -68,7 +107,23 @@ var {{.NameNative}} = new function() {
 	// The upper limit for the number of elements in a list.
 	var colferListMax = {{.ListMax}};
 {{- end}}
-{{range .Structs}}
+	// The upper limit for the nesting depth of data structures.
+	var colferDepthMax = {{.DepthMax}};
+{{range .Consts}}
+{{.DocText "\t// "}}
+	Object.defineProperty(this, '{{.NameNative}}', {value: {{.ValueNative}}, enumerable: true});
+{{end}}
+{{- range .Enums}}
+	// Enumeration of the named {{.Type}} values.
+{{.DocText "\t// "}}
+	this.{{.NameTitle}} = Object.freeze({
+{{- range .Values}}
+{{.DocText "\t\t// "}}
+		{{.NameNative}}: {{.Value}},
+{{- end}}
+	});
+{{end}}
+{{- range .Structs}}
 	// Constructor.
 {{.DocText "\t// "}}
 	// When init is provided all enumerable properties are merged into the new object a.k.a. shallow cloning.
@@ -76,32 +131,61 @@ var {{.NameNative}} = new function() {
 {{- range .Fields}}
 {{.DocText "\t\t// "}}
 		this.{{.NameNative}} =
-{{- if .TypeList}} {{if eq .Type "float32"}}new Float32Array(0){{else if eq .Type "float64"}}new Float64Array(0){{else}}[]{{end}}
+{{- if .TypeKey}} new Map()
+{{- else if .TypeList}} {{if eq .Type "float32"}}new Float32Array(0){{else if eq .Type "float64"}}new Float64Array(0)
+ {{- else if eq .Type "uint8"}}new Uint8Array(0){{else if eq .Type "uint16"}}new Uint16Array(0)
+ {{- else if eq .Type "uint32"}}new Uint32Array(0){{else if eq .Type "int32"}}new Int32Array(0){{else}}[]{{end}}
+{{- else if .Optional}} undefined
 {{- else if eq .Type "bool"}} false
 {{- else if eq .Type "timestamp"}} null;
 		this.{{.NameNative}}_ns = 0
 {{- else if eq .Type "text"}} ''
 {{- else if eq .Type "binary"}} new Uint8Array(0)
-{{- else if .TypeRef}} null
+{{- else if or .TypeRef .TypeUnion}} null
 {{- else}} 0
 {{- end}};{{end}}
+{{- if .Pkg.KeepUnknown}}
+		// colferUnknown has the fields of a newer schema, if any, in serial form.
+		this.colferUnknown = null;
+{{- end}}
 
 		for (var p in init) this[p] = init[p];
 	}
 {{template "marshal" .}}
 {{template "unmarshal" .}}
+{{template "json" .}}
 {{end}}
 	// private section
 
 	var encodeVarint = function(bytes, i, x) {
 		while (x > 127) {
 			bytes[i++] = (x & 127) | 128;
-			x /= 128;
+			x = Math.floor(x / 128);
 		}
 		bytes[i++] = x & 127;
 		return i;
 	}
-{{if .HasTimestamp}}
+{{if .HasMap}}
+	// Encodes with zig-zag encoding without exceeding the integer precision.
+	var encodeZigZag = function(bytes, i, x) {
+		var neg = x < 0 ? 1 : 0;
+		if (neg) x = -x - 1;
+		if (x < 64) {
+			bytes[i++] = x * 2 + neg;
+			return i;
+		}
+		bytes[i++] = (x % 64) * 2 + neg + 128;
+		return encodeVarint(bytes, i, Math.floor(x / 64));
+	}
+
+	// Compares octets in lexicographical order.
+	function compareBytes(a, b) {
+		for (var i = 0; i < a.length && i < b.length; i++)
+			if (a[i] != b[i]) return a[i] - b[i];
+		return a.length - b.length;
+	}
+{{end}}
+{{- if .HasTimestamp}}
 	function decodeInt64(data, i) {
 		var v = 0, j = i + 7, m = 1;
 		if (data[i] & 128) {
@@ -118,6 +202,203 @@ var {{.NameNative}} = new function() {
 		}
 		return v;
 	}
+{{end}}
+	// JSON mapping
+
+	function jsonObject(v, what) {
+		if (v == null) return {};
+		if (typeof v !== 'object' || Array.isArray(v))
+			throw new Error('colfer: ' + what + ' needs a JSON object');
+		return v;
+	}
+
+	// Encodes integers beyond the safe range as a string, as JSON readers
+	// lose precision on them.
+	function jsonInt64(x) {
+		var s = String(x);
+		return Number.isSafeInteger(x) ? s : '"' + s + '"';
+	}
+
+	function jsonList(a, f) {
+		var s = [];
+		for (var i = 0; i < a.length; i++) s.push(f(a[i]));
+		return '[' + s.join(',') + ']';
+	}
+{{if .HasMap}}
+	// Encodes the entries in serial order.
+	function jsonMap(m, textKeys, f) {
+		var keys = [];
+		m.forEach(function(v, k) { keys.push(k); });
+		if (textKeys) keys.sort(function(a, b) { return compareBytes(encodeUTF8(a), encodeUTF8(b)); });
+		else keys.sort(function(a, b) { return a - b; });
+		var s = [];
+		keys.forEach(function(k) {
+			s.push(JSON.stringify(String(k)) + ':' + f(m.get(k)));
+		});
+		return '{' + s.join(',') + '}';
+	}
+
+	function fromJSONKey(k, min, max, what) {
+		if (!/^-?[0-9]+$/.test(k))
+			throw new Error('colfer: ' + what + ' key ' + JSON.stringify(k) + ' is not an integer');
+		return fromJSONInt(Number(k), min, max, what);
+	}
+{{end}}
+	// Encodes the schema name of an enumeration value, if any.
+	function jsonEnum(values, x) {
+		for (var name in values)
+			if (values[name] === x) return JSON.stringify(name);
+		return String(x);
+	}
+
+	function fromJSONEnum(values, v, min, max, what) {
+		if (typeof v !== 'string') return fromJSONInt(v, min, max, what);
+		if (!Object.prototype.hasOwnProperty.call(values, v))
+			throw new Error('colfer: ' + what + ' has no enumeration value ' + JSON.stringify(v));
+		return values[v];
+	}
+
+	function fromJSONBool(v, what) {
+		if (typeof v !== 'boolean')
+			throw new Error('colfer: ' + what + ' needs a JSON boolean');
+		return v;
+	}
+
+	// Accepts decimal strings too, as used for 64-bit integers beyond the safe
+	// integer range.
+	function fromJSONInt(v, min, max, what) {
+		if (typeof v === 'string' && /^-?[0-9]+$/.test(v)) v = Number(v);
+		if (typeof v !== 'number' || !Number.isInteger(v) || v < min || v > max)
+			throw new Error('colfer: ' + what + ' needs an integer in range [' + min + ', ' + max + ']; got ' + JSON.stringify(v));
+		return v;
+	}
+
+	function fromJSONText(v, what) {
+		if (v === null) return '';
+		if (typeof v !== 'string')
+			throw new Error('colfer: ' + what + ' needs a JSON string');
+		return v;
+	}
+{{if .HasFloat}}
+	// Encodes NaN and the infinities as a string, since JSON has no notation
+	// for them. Negative zero is kept.
+	function jsonFloat64(x) {
+		if (Number.isNaN(x)) return '"NaN"';
+		if (x === Infinity) return '"Infinity"';
+		if (x === -Infinity) return '"-Infinity"';
+		if (x === 0 && 1 / x < 0) return '-0';
+		return String(x);
+	}
+
+	// Encodes the shortest decimal which reads back as the same float32.
+	function jsonFloat32(x) {
+		x = Math.fround(x);
+		if (x === 0 || !isFinite(x)) return jsonFloat64(x);
+		for (var p = 1; p <= 9; p++) {
+			var e = x.toExponential(p - 1).split('e');
+			var digits = Number(e[0].replace('.', ''));
+			var exp = Number(e[1]) - (p - 1);
+			// the nearest first, and then its neighbours for the
+			// asymmetric rounding interval at powers of two
+			var candidates = [digits, digits - 1, digits + 1];
+			for (var i = 0; i < candidates.length; i++) {
+				var f = Number(candidates[i] + 'e' + exp);
+				if (Math.fround(f) === x) return String(f);
+			}
+		}
+		return String(x);
+	}
+
+	function fromJSONFloat(v, what) {
+		if (typeof v === 'number') return v;
+		switch (v) {
+		case 'NaN':
+			return NaN;
+		case 'Infinity':
+			return Infinity;
+		case '-Infinity':
+			return -Infinity;
+		}
+		throw new Error('colfer: ' + what + ' needs a JSON number');
+	}
+{{end}}
+{{- if .HasTimestamp}}
+	function pad(x, n) {
+		var s = String(x);
+		while (s.length < n) s = '0' + s;
+		return s;
+	}
+
+	// Encodes RFC 3339 in UTC, with a fraction only when non-zero, without
+	// trailing zeros. Years outside 0000–9999 follow Go's notation.
+	function jsonTime(date, ns) {
+		var ms = date ? date.getTime() : 0;
+		var msf = ms % 1E3;
+		if (msf < 0) msf += 1E3;
+		ns = (ns || 0) + msf * 1E6;
+		var d = new Date(ms - msf);
+
+		var y = d.getUTCFullYear();
+		var s = (y < 0 ? '-' + pad(-y, 4) : pad(y, 4)) + '-' + pad(d.getUTCMonth() + 1, 2) + '-' + pad(d.getUTCDate(), 2);
+		s += 'T' + pad(d.getUTCHours(), 2) + ':' + pad(d.getUTCMinutes(), 2) + ':' + pad(d.getUTCSeconds(), 2);
+		if (ns) s += ('.' + pad(ns, 9)).replace(/0+$/, '');
+		return '"' + s + 'Z"';
+	}
+
+	// Decodes RFC 3339 into a Date and the nanoseconds within its millisecond.
+	function fromJSONTime(v, what) {
+		var m = typeof v === 'string' && /^(-?[0-9]{4,})-([0-9]{2})-([0-9]{2})T([0-9]{2}):([0-9]{2}):([0-9]{2})(?:\.([0-9]{1,9}))?(Z|([+-])([0-9]{2}):([0-9]{2}))$/.exec(v);
+		if (!m) throw new Error('colfer: ' + what + ' needs an RFC 3339 timestamp');
+
+		var d = new Date(0);
+		// no Date.UTC as it maps years 0–99 to 1900–1999
+		d.setUTCFullYear(Number(m[1]), Number(m[2]) - 1, Number(m[3]));
+		d.setUTCHours(Number(m[4]), Number(m[5]), Number(m[6]));
+		var ms = d.getTime();
+		if (m[9]) {
+			var offset = (Number(m[10]) * 60 + Number(m[11])) * 6E4;
+			ms += m[9] === '-' ? offset : -offset;
+		}
+		var ns = m[7] ? Number((m[7] + '00000000').substring(0, 9)) : 0;
+		return {date: new Date(ms + Math.floor(ns / 1E6)), ns: ns % 1E6};
+	}
+{{end}}
+{{- if .HasBinary}}
+	var base64 = 'ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/';
+
+	// Encodes standard base64 with padding.
+	function encodeBase64(bytes) {
+		var s = '';
+		for (var i = 0; i < bytes.length; i += 3) {
+			var n = bytes[i] << 16;
+			if (i + 1 < bytes.length) n |= bytes[i + 1] << 8;
+			if (i + 2 < bytes.length) n |= bytes[i + 2];
+			s += base64[n >> 18] + base64[n >> 12 & 63];
+			s += i + 1 < bytes.length ? base64[n >> 6 & 63] : '=';
+			s += i + 2 < bytes.length ? base64[n & 63] : '=';
+		}
+		return s;
+	}
+
+	function decodeBase64(s, what) {
+		if (s === null) return new Uint8Array(0);
+		if (typeof s !== 'string' || s.length % 4 || !/^[A-Za-z0-9+/]*={0,2}$/.test(s))
+			throw new Error('colfer: ' + what + ' needs standard base64 in a JSON string');
+		var size = s.length / 4 * 3;
+		if (s.endsWith('==')) size -= 2;
+		else if (s.endsWith('=')) size--;
+
+		var bytes = new Uint8Array(size);
+		for (var i = 0, j = 0; i < s.length; i += 4) {
+			var n = 0;
+			for (var k = i; k < i + 4; k++)
+				n = n << 6 | (s[k] === '=' ? 0 : base64.indexOf(s[k]));
+			bytes[j++] = n >> 16;
+			if (j < size) bytes[j++] = n >> 8 & 255;
+			if (j < size) bytes[j++] = n & 255;
+		}
+		return bytes;
+	}
 {{end}}
 	function encodeUTF8(s) {
 		var i = 0, bytes = new Uint8Array(s.length * 4);
@@ -184,26 +465,212 @@ if (typeof exports !== 'undefined') exports.{{.NameNative}} = {{.NameNative}};
 
 const ecmaMarshal = `
 	// Serializes the object into an Uint8Array.
-{{- range .Fields}}{{if .TypeList}}{{if eq .Type "float32" "float64"}}{{else}}
+{{- range .Fields}}{{if .TypeKey}}{{if eq .Type "text" "binary"}}
+	// All null values in property {{.NameNative}} will be replaced with {{if eq .Type "text"}}an empty String{{else}}an empty Array{{end}}.
+{{- else if .TypeRef}}
+	// All null values in property {{.NameNative}} will be replaced with a new {{.TypeRef.Pkg.NameNative}}.{{.TypeRef.NameTitle}}.
+{{- end}}{{else if .TypeList}}{{if eq .Type "bool" "uint8" "uint16" "uint32" "uint64" "int32" "int64" "float32" "float64"}}{{else}}
 	// All null entries in property {{.NameNative}} will be replaced with {{if eq .Type "text"}}an empty String{{else if eq .Type "binary"}}an empty Array{{else}}a new {{.TypeRef.Pkg.NameNative}}.{{.TypeRef.NameTitle}}{{end}}.
-{{- end}}{{end}}{{end}}
-	this.{{.NameTitle}}.prototype.marshal = function(buf) {
+{{- end}}{{else if .TypeUnion}}
+	// A null value in property {{.NameNative}} will be replaced with a new instance of its kind.
+{{- end}}{{end}}
+	// The optional depthMax overrides the upper limit for the nesting depth.
+	this.{{.NameTitle}}.prototype.marshal = function(buf, depthMax) {
+		if (depthMax === undefined) depthMax = colferDepthMax;
+		if (depthMax < 1) throw new RangeError('colfer: {{.String}} exceeds the nesting depth limit; check for reference cycles');
 		if (! buf || !buf.length) buf = new Uint8Array(colferSizeMax);
 		var i = 0;
 		var view = new DataView(buf.buffer);
 
-{{range .Fields}}{{if eq .Type "bool"}}
+{{range .Fields}}{{if .TypeKey}}
+		if (this.{{.NameNative}} && this.{{.NameNative}}.size) {
+			var m = this.{{.NameNative}};
+			if (m.size > {{or .ListMax "colferListMax"}})
+				throw new Error('colfer: {{.String}} length exceeds {{or .ListMax "colferListMax"}}');
+			buf[i++] = {{.Index}};
+			i = encodeVarint(buf, i, m.size);
+
+			var keys = [];
+			m.forEach(function(v, k) {
+ {{- if eq .TypeKey "text"}}
+				var utf8 = encodeUTF8(k);
+				{{- if .SizeMax}}
+				if (utf8.length > {{.SizeMax}})
+					throw new Error('colfer: {{.String}} key size ' + utf8.length + ' exceeds {{.SizeMax}} UTF-8 bytes');
+				{{- end}}
+				keys.push({k: k, utf8: utf8});
+			});
+			keys.sort(function(a, b) { return compareBytes(a.utf8, b.utf8); });
+ {{- else}}
+  {{- if eq .TypeKey "int32"}}
+				if (k > 2147483647 || k < -2147483648)
+					throw new Error('colfer: {{.String}} key exceeds 32-bit range');
+  {{- else if eq .TypeKey "int64"}}
+				if (k > Number.MAX_SAFE_INTEGER || k < Number.MIN_SAFE_INTEGER)
+					throw new Error('colfer: {{.String}} key exceeds Number.MAX_SAFE_INTEGER');
+  {{- else if eq .TypeKey "uint64"}}
+				if (k > Number.MAX_SAFE_INTEGER || k < 0)
+					throw new Error('colfer: {{.String}} key out of reach: ' + k);
+  {{- else}}
+				if (k > {{if eq .TypeKey "uint8"}}255{{else if eq .TypeKey "uint16"}}65535{{else}}4294967295{{end}} || k < 0)
+					throw new Error('colfer: {{.String}} key out of reach: ' + k);
+  {{- end}}
+				keys.push({k: k});
+			});
+			keys.sort(function(a, b) { return a.k - b.k; });
+ {{- end}}
+
+			keys.forEach(function(e) {
+ {{- if eq .TypeKey "text"}}
+				i = encodeVarint(buf, i, e.utf8.length);
+				buf.set(e.utf8, i);
+				i += e.utf8.length;
+ {{- else if eq .TypeKey "uint8"}}
+				buf[i++] = e.k;
+ {{- else if eq .TypeKey "int32" "int64"}}
+				i = encodeZigZag(buf, i, e.k);
+ {{- else}}
+				i = encodeVarint(buf, i, e.k);
+ {{- end}}
+
+				var v = m.get(e.k);
+ {{- if eq .Type "bool"}}
+				buf[i++] = v ? 1 : 0;
+ {{- else if eq .Type "float32"}}
+				if (v > 3.4028234663852886E38 || v < -3.4028234663852886E38)
+					throw new Error('colfer: {{.String}} value exceeds 32-bit range');
+				view.setFloat32(i, v);
+				i += 4;
+ {{- else if eq .Type "float64"}}
+				view.setFloat64(i, v);
+				i += 8;
+ {{- else if eq .Type "text"}}
+				if (v == null) {
+					v = '';
+					m.set(e.k, v);
+				}
+				var utf8 = encodeUTF8(v);
+				{{- if .SizeMax}}
+				if (utf8.length > {{.SizeMax}})
+					throw new Error('colfer: {{.String}} value size ' + utf8.length + ' exceeds {{.SizeMax}} UTF-8 bytes');
+				{{- end}}
+				i = encodeVarint(buf, i, utf8.length);
+				buf.set(utf8, i);
+				i += utf8.length;
+ {{- else if eq .Type "binary"}}
+				if (v == null) {
+					v = new Uint8Array(0);
+					m.set(e.k, v);
+				}
+				{{- if .SizeMax}}
+				if (v.length > {{.SizeMax}})
+					throw new Error('colfer: {{.String}} value size ' + v.length + ' exceeds {{.SizeMax}} bytes');
+				{{- end}}
+				i = encodeVarint(buf, i, v.length);
+				buf.set(v, i);
+				i += v.length;
+ {{- else if .TypeRef}}
+				if (v == null) {
+					v = new {{.TypeRef.Pkg.NameNative}}.{{.TypeRef.NameTitle}}();
+					m.set(e.k, v);
+				}
+{{- if eq .TypeRef.String .Struct.String}}
+				if (v === this)
+					throw new RangeError('colfer: {{.String}} makes a reference cycle');
+{{- end}}
+				var b = v.marshal(null, depthMax - 1);
+				buf.set(b, i);
+				i += b.length;
+ {{- else}}
+  {{- if eq .Type "int32"}}
+				if (v > 2147483647 || v < -2147483648)
+					throw new Error('colfer: {{.String}} value exceeds 32-bit range');
+  {{- else if eq .Type "int64"}}
+				if (v > Number.MAX_SAFE_INTEGER || v < Number.MIN_SAFE_INTEGER)
+					throw new Error('colfer: {{.String}} value exceeds Number.MAX_SAFE_INTEGER');
+  {{- else if eq .Type "uint64"}}
+				if (v > Number.MAX_SAFE_INTEGER || v < 0)
+					throw new Error('colfer: {{.String}} value out of reach: ' + v);
+  {{- else}}
+				if (v > {{if eq .Type "uint8"}}255{{else if eq .Type "uint16"}}65535{{else}}4294967295{{end}} || v < 0)
+					throw new Error('colfer: {{.String}} value out of reach: ' + v);
+  {{- end}}
+  {{- if eq .Type "uint8"}}
+				buf[i++] = v;
+  {{- else if eq .Type "int32" "int64"}}
+				i = encodeZigZag(buf, i, v);
+  {{- else}}
+				i = encodeVarint(buf, i, v);
+  {{- end}}
+ {{- end}}
+			}, this);
+		}
+{{else if and .TypeList (eq .Type "bool" "uint8" "uint16" "uint32" "uint64" "int32" "int64")}}
+		if (this.{{.NameNative}} && this.{{.NameNative}}.length) {
+			var a = this.{{.NameNative}};
+			if (a.length > {{or .ListMax "colferListMax"}})
+				throw new Error('colfer: {{.String}} length exceeds {{or .ListMax "colferListMax"}}');
+			buf[i++] = {{.Index}};
+			i = encodeVarint(buf, i, a.length);
+ {{- if eq .Type "bool"}}
+			a.forEach(function(b) {
+				buf[i++] = b ? 1 : 0;
+			});
+ {{- else if eq .Type "int32" "int64"}}
+			a.forEach(function(v, vi) {
+  {{- if eq .Type "int32"}}
+				if (v > 2147483647 || v < -2147483648)
+					throw new Error('colfer: {{.String}}[' + vi + '] exceeds 32-bit range');
+  {{- else}}
+				if (v > Number.MAX_SAFE_INTEGER || v < Number.MIN_SAFE_INTEGER)
+					throw new Error('colfer: {{.String}}[' + vi + '] exceeds Number.MAX_SAFE_INTEGER');
+  {{- end}}
+				// zig-zag encoding without exceeding the integer precision
+				var neg = v < 0 ? 1 : 0;
+				if (neg) v = -v - 1;
+				if (v < 64) {
+					buf[i++] = v * 2 + neg;
+				} else {
+					buf[i++] = (v % 64) * 2 + neg + 128;
+					i = encodeVarint(buf, i, Math.floor(v / 64));
+				}
+			});
+ {{- else}}
+			a.forEach(function(v, vi) {
+  {{- if eq .Type "uint64"}}
+				if (v < 0)
+					throw new Error('colfer: {{.String}}[' + vi + '] out of reach: ' + v);
+				if (v > Number.MAX_SAFE_INTEGER)
+					throw new Error('colfer: {{.String}}[' + vi + '] exceeds Number.MAX_SAFE_INTEGER');
+  {{- else}}
+				if (v > {{if eq .Type "uint8"}}255{{else if eq .Type "uint16"}}65535{{else}}4294967295{{end}} || v < 0)
+					throw new Error('colfer: {{.String}}[' + vi + '] out of reach: ' + v);
+  {{- end}}
+  {{- if eq .Type "uint8"}}
+				buf[i++] = v;
+  {{- else}}
+				i = encodeVarint(buf, i, v);
+  {{- end}}
+			});
+ {{- end}}
+		}
+{{else if eq .Type "bool"}}
+ {{- if .Optional}}
+		if (this.{{.NameNative}} != null)
+			buf[i++] = this.{{.NameNative}} ? {{.Index}} : {{.Index}} | 128;
+ {{- else}}
 		if (this.{{.NameNative}})
 			buf[i++] = {{.Index}};
+ {{- end}}
 {{else if eq .Type "uint8"}}
-		if (this.{{.NameNative}}) {
+		if (this.{{.NameNative}}{{if .Optional}} != null{{end}}) {
 			if (this.{{.NameNative}} > 255 || this.{{.NameNative}} < 0)
 				throw new Error('colfer: {{.Struct.Pkg.NameNative}}/{{.Struct.NameTitle}} field {{.NameNative}} out of reach: ' + this.{{.NameNative}});
 			buf[i++] = {{.Index}};
 			buf[i++] = this.{{.NameNative}};
 		}
 {{else if eq .Type "uint16"}}
-		if (this.{{.NameNative}}) {
+		if (this.{{.NameNative}}{{if .Optional}} != null{{end}}) {
 			if (this.{{.NameNative}} > 65535 || this.{{.NameNative}} < 0)
 				throw new Error('colfer: {{.Struct.Pkg.NameNative}}/{{.Struct.NameTitle}} field {{.NameNative}} out of reach: ' + this.{{.NameNative}});
 			if (this.{{.NameNative}} < 256) {
@@ -216,7 +683,7 @@ const ecmaMarshal = `
 			}
 		}
 {{else if eq .Type "uint32"}}
-		if (this.{{.NameNative}}) {
+		if (this.{{.NameNative}}{{if .Optional}} != null{{end}}) {
 			if (this.{{.NameNative}} > 4294967295 || this.{{.NameNative}} < 0)
 				throw new Error('colfer: {{.Struct.Pkg.NameNative}}/{{.Struct.NameTitle}} field {{.NameNative}} out of reach: ' + this.{{.NameNative}});
 			if (this.{{.NameNative}} < 0x200000) {
@@ -229,7 +696,7 @@ const ecmaMarshal = `
 			}
 		}
 {{else if eq .Type "uint64"}}
-		if (this.{{.NameNative}}) {
+		if (this.{{.NameNative}}{{if .Optional}} != null{{end}}) {
 			if (this.{{.NameNative}} < 0)
 				throw new Error('colfer: {{.Struct.Pkg.NameNative}}/{{.Struct.NameTitle}} field {{.NameNative}} out of reach: ' + this.{{.NameNative}});
 			if (this.{{.NameNative}} > Number.MAX_SAFE_INTEGER)
@@ -246,7 +713,7 @@ const ecmaMarshal = `
 			}
 		}
 {{else if eq .Type "int32"}}
-		if (this.{{.NameNative}}) {
+		if (this.{{.NameNative}}{{if .Optional}} != null{{end}}) {
 			if (this.{{.NameNative}} < 0) {
 				buf[i++] = {{.Index}} | 128;
 				if (this.{{.NameNative}} < -2147483648)
@@ -260,7 +727,7 @@ const ecmaMarshal = `
 			}
 		}
 {{else if eq .Type "int64"}}
-		if (this.{{.NameNative}}) {
+		if (this.{{.NameNative}}{{if .Optional}} != null{{end}}) {
 			if (this.{{.NameNative}} < 0) {
 				buf[i++] = {{.Index}} | 128;
 				if (this.{{.NameNative}} < Number.MIN_SAFE_INTEGER)
@@ -277,8 +744,8 @@ const ecmaMarshal = `
  {{- if .TypeList}}
 		if (this.{{.NameNative}} && this.{{.NameNative}}.length) {
 			var a = this.{{.NameNative}};
-			if (a.length > colferListMax)
-				throw new Error('colfer: {{.String}} length exceeds colferListMax');
+			if (a.length > {{or .ListMax "colferListMax"}})
+				throw new Error('colfer: {{.String}} length exceeds {{or .ListMax "colferListMax"}}');
 			buf[i++] = {{.Index}};
 			i = encodeVarint(buf, i, a.length);
 			a.forEach(function(f, fi) {
@@ -289,7 +756,7 @@ const ecmaMarshal = `
 			});
 		}
  {{- else}}
-		if (this.{{.NameNative}} || Number.isNaN(this.{{.NameNative}})) {
+		if ({{if .Optional}}this.{{.NameNative}} != null{{else}}this.{{.NameNative}} || Number.isNaN(this.{{.NameNative}}){{end}}) {
 			if (this.{{.NameNative}} > 3.4028234663852886E38 || this.{{.NameNative}} < -3.4028234663852886E38)
 				throw new Error('colfer: {{.Struct.Pkg.NameNative}}/{{.Struct.NameTitle}} field {{.NameNative}} exceeds 32-bit range');
 			buf[i++] = {{.Index}};
@@ -301,8 +768,8 @@ const ecmaMarshal = `
  {{- if .TypeList}}
 		if (this.{{.NameNative}} && this.{{.NameNative}}.length) {
 			var a = this.{{.NameNative}};
-			if (a.length > colferListMax)
-				throw new Error('colfer: {{.String}} length exceeds colferListMax');
+			if (a.length > {{or .ListMax "colferListMax"}})
+				throw new Error('colfer: {{.String}} length exceeds {{or .ListMax "colferListMax"}}');
 			buf[i++] = {{.Index}};
 			i = encodeVarint(buf, i, a.length);
 			a.forEach(function(f) {
@@ -311,7 +778,7 @@ const ecmaMarshal = `
 			});
 		}
  {{- else}}
-		if (this.{{.NameNative}} || Number.isNaN(this.{{.NameNative}})) {
+		if ({{if .Optional}}this.{{.NameNative}} != null{{else}}this.{{.NameNative}} || Number.isNaN(this.{{.NameNative}}){{end}}) {
 			buf[i++] = {{.Index}};
 			view.setFloat64(i, this.{{.NameNative}});
 			i += 8;
@@ -362,8 +829,8 @@ const ecmaMarshal = `
  {{- if .TypeList}}
 		if (this.{{.NameNative}} && this.{{.NameNative}}.length) {
 			var a = this.{{.NameNative}};
-			if (a.length > colferListMax)
-				throw new Error('colfer: {{.String}} length exceeds colferListMax');
+			if (a.length > {{or .ListMax "colferListMax"}})
+				throw new Error('colfer: {{.String}} length exceeds {{or .ListMax "colferListMax"}}');
 			buf[i++] = {{.Index}};
 			i = encodeVarint(buf, i, a.length);
 
@@ -373,6 +840,10 @@ const ecmaMarshal = `
 					a[si] = s;
 				}
 				var utf8 = encodeUTF8(s);
+				{{- if .SizeMax}}
+				if (utf8.length > {{.SizeMax}})
+					throw new Error('colfer: {{.String}} element ' + si + ' size ' + utf8.length + ' exceeds {{.SizeMax}} UTF-8 bytes');
+				{{- end}}
 				i = encodeVarint(buf, i, utf8.length);
 				buf.set(utf8, i);
 				i += utf8.length;
@@ -382,6 +853,10 @@ const ecmaMarshal = `
 		if (this.{{.NameNative}}) {
 			buf[i++] = {{.Index}};
 			var utf8 = encodeUTF8(this.{{.NameNative}});
+			{{- if .SizeMax}}
+			if (utf8.length > {{.SizeMax}})
+				throw new Error('colfer: {{.String}} size ' + utf8.length + ' exceeds {{.SizeMax}} UTF-8 bytes');
+			{{- end}}
 			i = encodeVarint(buf, i, utf8.length);
 			buf.set(utf8, i);
 			i += utf8.length;
@@ -391,8 +866,8 @@ const ecmaMarshal = `
  {{- if .TypeList}}
 		if (this.{{.NameNative}} && this.{{.NameNative}}.length) {
 			var a = this.{{.NameNative}};
-			if (a.length > colferListMax)
-				throw new Error('colfer: {{.String}} length exceeds colferListMax');
+			if (a.length > {{or .ListMax "colferListMax"}})
+				throw new Error('colfer: {{.String}} length exceeds {{or .ListMax "colferListMax"}}');
 			buf[i++] = {{.Index}};
 			i = encodeVarint(buf, i, a.length);
 			a.forEach(function(b, bi) {
@@ -400,6 +875,10 @@ const ecmaMarshal = `
 					b = "";
 					a[bi] = b;
 				}
+				{{- if .SizeMax}}
+				if (b.length > {{.SizeMax}})
+					throw new Error('colfer: {{.String}} element ' + bi + ' size ' + b.length + ' exceeds {{.SizeMax}} bytes');
+				{{- end}}
 				i = encodeVarint(buf, i, b.length);
 				buf.set(b, i);
 				i += b.length;
@@ -409,6 +888,10 @@ const ecmaMarshal = `
 		if (this.{{.NameNative}} && this.{{.NameNative}}.length) {
 			buf[i++] = {{.Index}};
 			var b = this.{{.NameNative}};
+			{{- if .SizeMax}}
+			if (b.length > {{.SizeMax}})
+				throw new Error('colfer: {{.String}} size ' + b.length + ' exceeds {{.SizeMax}} bytes');
+			{{- end}}
 			i = encodeVarint(buf, i, b.length);
 			buf.set(b, i);
 			i += b.length;
@@ -417,8 +900,8 @@ const ecmaMarshal = `
 {{else if .TypeList}}
 		if (this.{{.NameNative}} && this.{{.NameNative}}.length) {
 			var a = this.{{.NameNative}};
-			if (a.length > colferListMax)
-				throw new Error('colfer: {{.String}} length exceeds colferListMax');
+			if (a.length > {{or .ListMax "colferListMax"}})
+				throw new Error('colfer: {{.String}} length exceeds {{or .ListMax "colferListMax"}}');
 			buf[i++] = {{.Index}};
 			i = encodeVarint(buf, i, a.length);
 			a.forEach(function(v, vi) {
@@ -426,20 +909,55 @@ const ecmaMarshal = `
 					v = new {{.TypeRef.Pkg.NameNative}}.{{.TypeRef.NameTitle}}();
 					a[vi] = v;
 				}
-				var b = v.marshal();
+{{- if eq .TypeRef.String .Struct.String}}
+				if (v === this)
+					throw new RangeError('colfer: {{.String}} makes a reference cycle');
+{{- end}}
+				var b = v.marshal(null, depthMax - 1);
 				buf.set(b, i);
 				i += b.length;
-			});
+			}, this);
+		}
+{{else if .TypeUnion}}
+		if (this.{{.NameNative}}) {
+ {{- $f := .}}
+			buf[i++] = {{.Index}};
+			var u = this.{{.NameNative}};
+			switch (u.kind) {
+ {{- range .TypeUnion.Members}}
+			case '{{.Name}}':
+				buf[i++] = {{.Index}};
+				if (u.value == null) u.value = new {{.Struct.Pkg.NameNative}}.{{.Struct.NameTitle}}();
+{{- if eq .Struct.String $f.Struct.String}}
+				if (u.value === this)
+					throw new RangeError('colfer: {{$f.String}} makes a reference cycle');
+{{- end}}
+				break;
+ {{- end}}
+			default:
+				throw new Error('colfer: {{.String}} kind ' + u.kind + ' is not a member');
+			}
+			var b = u.value.marshal(null, depthMax - 1);
+			buf.set(b, i);
+			i += b.length;
 		}
 {{else}}
 		if (this.{{.NameNative}}) {
 			buf[i++] = {{.Index}};
-			var b = this.{{.NameNative}}.marshal();
+{{- if eq .TypeRef.String .Struct.String}}
+			if (this.{{.NameNative}} === this)
+				throw new RangeError('colfer: {{.String}} makes a reference cycle');
+{{- end}}
+			var b = this.{{.NameNative}}.marshal(null, depthMax - 1);
 			buf.set(b, i);
 			i += b.length;
 		}
 {{end}}{{end}}
-
+{{if .Pkg.KeepUnknown}}		if (this.colferUnknown) {
+			buf.set(this.colferUnknown, i);
+			i += this.colferUnknown.length;
+		}
+{{end}}
 		buf[i++] = 127;
 		if (i >= colferSizeMax)
 			throw new Error('colfer: {{.String}} serial size ' + i + ' exceeds ' + colferSizeMax + ' bytes');
@@ -448,7 +966,17 @@ const ecmaMarshal = `
 
 const ecmaUnmarshal = `
 	// Deserializes the object from an Uint8Array and returns the number of bytes read.
-	this.{{.NameTitle}}.prototype.unmarshal = function(data) {
+{{- if .Pkg.KeepUnknown}}
+	// Fields beyond the schema are kept with unmarshalSerial only.
+{{- end}}
+	// The optional depthMax overrides the upper limit for the nesting depth.
+{{- if .Pkg.KeepUnknown}}
+	this.{{.NameTitle}}.prototype.unmarshal = function(data, serial, depthMax) {
+{{- else}}
+	this.{{.NameTitle}}.prototype.unmarshal = function(data, depthMax) {
+{{- end}}
+		if (depthMax === undefined) depthMax = colferDepthMax;
+		if (depthMax < 1) throw new RangeError('colfer: {{.String}} exceeds the nesting depth limit');
 		if (!data || ! data.length) throw new Error(EOF);
 		var header = data[0];
 		var i = 1;
@@ -474,11 +1002,150 @@ const ecmaUnmarshal = `
 			}
 			return -1;
 		}
-{{range .Fields}}{{if eq .Type "bool"}}
+{{- if .HasMap}}
+
+		// Reads a variable-length integer, optionally with zig-zag encoding.
+		// The return is null when the value exceeds Number.MAX_SAFE_INTEGER.
+		var readInt = function(zigZag) {
+			if (i >= data.length) throw new Error(EOF);
+			var c = data[i++];
+			var neg = zigZag ? c & 1 : 0;
+			var x = zigZag ? (c & 127) >>> 1 : c & 127;
+			for (var scale = zigZag ? 64 : 128; c > 127; scale *= 128) {
+				if (i >= data.length) throw new Error(EOF);
+				c = data[i++];
+				x += (c & 127) * scale;
+			}
+			if (x > Number.MAX_SAFE_INTEGER - neg) return null;
+			return neg ? -x - 1 : x;
+		}
+{{- end}}
+{{range .Fields}}{{if .TypeKey}}
+		if (header == {{.Index}}) {
+			var l = readVarint();
+			if (l < 0) throw new Error('colfer: {{.String}} length exceeds Number.MAX_SAFE_INTEGER');
+			if (l > {{or .ListMax "colferListMax"}})
+				throw new Error('colfer: {{.String}} length ' + l + ' exceeds ' + {{or .ListMax "colferListMax"}} + ' elements');
+			if (i + l * 2 > data.length) throw new Error(EOF);
+
+			var m = new Map(), last = null;
+			for (var n = 0; n < l; ++n) {
+				var start = i;
+ {{- if eq .TypeKey "text"}}
+				var size = readVarint();
+				if (size < 0)
+					throw new Error('colfer: {{.String}} key size exceeds Number.MAX_SAFE_INTEGER');
+				else if (size > {{or .SizeMax "colferSizeMax"}})
+					throw new Error('colfer: {{.String}} key size ' + size + ' exceeds ' + {{or .SizeMax "colferSizeMax"}} + ' UTF-8 bytes');
+				i += size;
+				if (i > data.length) throw new Error(EOF);
+				var utf8 = data.subarray(i - size, i);
+				if (last != null && compareBytes(last, utf8) >= 0)
+					throw new Error('colfer: {{.String}} key at byte ' + start + ' out of order');
+				last = utf8;
+				var k = decodeUTF8(utf8);
+ {{- else}}
+  {{- if eq .TypeKey "uint8"}}
+				if (i >= data.length) throw new Error(EOF);
+				var k = data[i++];
+  {{- else}}
+				var k = readInt({{if eq .TypeKey "int32" "int64"}}true{{else}}false{{end}});
+				if (k == null) throw new Error('colfer: {{.String}} key exceeds Number.MAX_SAFE_INTEGER');
+  {{- end}}
+				if (last != null && k <= last)
+					throw new Error('colfer: {{.String}} key at byte ' + start + ' out of order');
+				last = k;
+ {{- end}}
+{{if eq .Type "bool" "uint8"}}
+				if (i >= data.length) throw new Error(EOF);
+				var v = {{if eq .Type "bool"}}data[i++] != 0{{else}}data[i++]{{end}};
+ {{- else if eq .Type "float32"}}
+				if (i + 4 > data.length) throw new Error(EOF);
+				var v = view.getFloat32(i);
+				i += 4;
+ {{- else if eq .Type "float64"}}
+				if (i + 8 > data.length) throw new Error(EOF);
+				var v = view.getFloat64(i);
+				i += 8;
+ {{- else if eq .Type "text" "binary"}}
+				var size = readVarint();
+				if (size < 0)
+					throw new Error('colfer: {{.String}} value size exceeds Number.MAX_SAFE_INTEGER');
+				else if (size > {{or .SizeMax "colferSizeMax"}})
+					throw new Error('colfer: {{.String}} value size ' + size + ' exceeds ' + {{or .SizeMax "colferSizeMax"}} + '{{if eq .Type "text"}} UTF-8{{end}} bytes');
+				i += size;
+				if (i > data.length) throw new Error(EOF);
+				var v = {{if eq .Type "text"}}decodeUTF8(data.subarray(i - size, i)){{else}}data.slice(i - size, i){{end}};
+ {{- else if .TypeRef}}
+				var v = new {{.TypeRef.Pkg.NameNative}}.{{.TypeRef.NameTitle}}();
+				i += v.unmarshal(data.subarray(i){{if .Struct.Pkg.KeepUnknown}}, false{{end}}, depthMax - 1);
+ {{- else}}
+				var v = readInt({{if eq .Type "int32" "int64"}}true{{else}}false{{end}});
+				if (v == null) throw new Error('colfer: {{.String}} value exceeds Number.MAX_SAFE_INTEGER');
+ {{- end}}
+				m.set(k, v);
+			}
+			this.{{.NameNative}} = m;
+			readHeader();
+		}
+{{else if and .TypeList (eq .Type "bool" "uint8" "uint16" "uint32" "uint64" "int32" "int64")}}
+		if (header == {{.Index}}) {
+			var l = readVarint();
+			if (l < 0) throw new Error('colfer: {{.String}} length exceeds Number.MAX_SAFE_INTEGER');
+			if (l > {{or .ListMax "colferListMax"}})
+				throw new Error('colfer: {{.String}} length ' + l + ' exceeds ' + {{or .ListMax "colferListMax"}} + ' elements');
+			if (i + l > data.length) throw new Error(EOF);
+ {{- if eq .Type "bool"}}
+
+			this.{{.NameNative}} = new Array(l);
+			for (var n = 0; n < l; ++n)
+				this.{{.NameNative}}[n] = data[i++] != 0;
+ {{- else if eq .Type "uint8"}}
+
+			this.{{.NameNative}} = data.slice(i, i + l);
+			i += l;
+ {{- else}}
+
+			this.{{.NameNative}} = new {{if eq .Type "uint16"}}Uint16Array{{else if eq .Type "uint32"}}Uint32Array{{else if eq .Type "int32"}}Int32Array{{else}}Array{{end}}(l);
+			for (var n = 0; n < l; ++n) {
+				if (i >= data.length) throw new Error(EOF);
+				var c = data[i++];
+  {{- if eq .Type "int32" "int64"}}
+				// zig-zag decoding without exceeding the integer precision
+				var neg = c & 1;
+				var x = (c & 127) >>> 1;
+				for (var scale = 64; c > 127; scale *= 128) {
+  {{- else}}
+				var x = c & 127;
+				for (var scale = 128; c > 127; scale *= 128) {
+  {{- end}}
+					if (i >= data.length) throw new Error(EOF);
+					c = data[i++];
+					x += (c & 127) * scale;
+				}
+  {{- if eq .Type "int32" "int64"}}
+				if (x > Number.MAX_SAFE_INTEGER - neg)
+					throw new Error('colfer: {{.String}}[' + n + '] exceeds Number.MAX_SAFE_INTEGER');
+				this.{{.NameNative}}[n] = neg ? -x - 1 : x;
+  {{- else}}
+				if (x > Number.MAX_SAFE_INTEGER)
+					throw new Error('colfer: {{.String}}[' + n + '] exceeds Number.MAX_SAFE_INTEGER');
+				this.{{.NameNative}}[n] = x;
+  {{- end}}
+			}
+ {{- end}}
+			readHeader();
+		}
+{{else if eq .Type "bool"}}
 		if (header == {{.Index}}) {
 			this.{{.NameNative}} = true;
 			readHeader();
 		}
+ {{- if .Optional}} else if (header == ({{.Index}} | 128)) {
+			this.{{.NameNative}} = false;
+			readHeader();
+		}
+ {{- end}}
 {{else if eq .Type "uint8"}}
 		if (header == {{.Index}}) {
 			if (i + 1 >= data.length) throw new Error(EOF);
@@ -552,8 +1219,8 @@ const ecmaUnmarshal = `
  {{- if .TypeList}}
 			var l = readVarint();
 			if (l < 0) throw new Error('colfer: {{.String}} length exceeds Number.MAX_SAFE_INTEGER');
-			if (l > colferListMax)
-				throw new Error('colfer: {{.String}} length ' + l + ' exceeds ' + colferListMax + ' elements');
+			if (l > {{or .ListMax "colferListMax"}})
+				throw new Error('colfer: {{.String}} length ' + l + ' exceeds ' + {{or .ListMax "colferListMax"}} + ' elements');
 			if (i + l * 4 > data.length) throw new Error(EOF);
 
 			this.{{.NameNative}} = new Float32Array(l);
@@ -573,8 +1240,8 @@ const ecmaUnmarshal = `
  {{- if .TypeList}}
 			var l = readVarint();
 			if (l < 0) throw new Error('colfer: {{.String}} length exceeds Number.MAX_SAFE_INTEGER');
-			if (l > colferListMax)
-				throw new Error('colfer: {{.String}} length ' + l + ' exceeds ' + colferListMax + ' elements');
+			if (l > {{or .ListMax "colferListMax"}})
+				throw new Error('colfer: {{.String}} length ' + l + ' exceeds ' + {{or .ListMax "colferListMax"}} + ' elements');
 			if (i + l * 8 > data.length) throw new Error(EOF);
 
 			this.{{.NameNative}} = new Float64Array(l);
@@ -620,16 +1287,16 @@ const ecmaUnmarshal = `
  {{- if .TypeList}}
 			var l = readVarint();
 			if (l < 0) throw new Error('colfer: {{.String}} length exceeds Number.MAX_SAFE_INTEGER');
-			if (l > colferListMax)
-				throw new Error('colfer: {{.String}} length ' + l + ' exceeds ' + colferListMax + ' elements');
+			if (l > {{or .ListMax "colferListMax"}})
+				throw new Error('colfer: {{.String}} length ' + l + ' exceeds ' + {{or .ListMax "colferListMax"}} + ' elements');
 
 			this.{{.NameNative}} = new Array(l);
 			for (var n = 0; n < l; ++n) {
 				var size = readVarint();
 				if (size < 0)
 					throw new Error('colfer: {{.String}} element ' + this.{{.NameNative}}.length + ' size exceeds Number.MAX_SAFE_INTEGER');
-				else if (size > colferSizeMax)
-					throw new Error('colfer: {{.String}} element ' + this.{{.NameNative}}.length + ' size ' + size + ' exceeds ' + colferSizeMax + ' UTF-8 bytes');
+				else if (size > {{or .SizeMax "colferSizeMax"}})
+					throw new Error('colfer: {{.String}} element ' + this.{{.NameNative}}.length + ' size ' + size + ' exceeds ' + {{or .SizeMax "colferSizeMax"}} + ' UTF-8 bytes');
 
 				var start = i;
 				i += size;
@@ -640,8 +1307,8 @@ const ecmaUnmarshal = `
 			var size = readVarint();
 			if (size < 0)
 				throw new Error('colfer: {{.String}} size exceeds Number.MAX_SAFE_INTEGER');
-			else if (size > colferSizeMax)
-				throw new Error('colfer: {{.String}} size ' + size + ' exceeds ' + colferSizeMax + ' UTF-8 bytes');
+			else if (size > {{or .SizeMax "colferSizeMax"}})
+				throw new Error('colfer: {{.String}} size ' + size + ' exceeds ' + {{or .SizeMax "colferSizeMax"}} + ' UTF-8 bytes');
 
 			var start = i;
 			i += size;
@@ -655,16 +1322,16 @@ const ecmaUnmarshal = `
  {{- if .TypeList}}
 			var l = readVarint();
 			if (l < 0) throw new Error('colfer: {{.String}} length exceeds Number.MAX_SAFE_INTEGER');
-			if (l > colferListMax)
-				throw new Error('colfer: {{.String}} length ' + l + ' exceeds ' + colferListMax + ' elements');
+			if (l > {{or .ListMax "colferListMax"}})
+				throw new Error('colfer: {{.String}} length ' + l + ' exceeds ' + {{or .ListMax "colferListMax"}} + ' elements');
 
 			this.{{.NameNative}} = new Array(l);
 			for (var n = 0; n < l; ++n) {
 				var size = readVarint();
 				if (size < 0)
 					throw new Error('colfer: {{.String}} element ' + this.{{.NameNative}}.length + ' size exceeds Number.MAX_SAFE_INTEGER');
-				else if (size > colferSizeMax)
-					throw new Error('colfer: {{.String}} element ' + this.{{.NameNative}}.length + ' size ' + size + ' exceeds ' + colferSizeMax + ' UTF-8 bytes');
+				else if (size > {{or .SizeMax "colferSizeMax"}})
+					throw new Error('colfer: {{.String}} element ' + this.{{.NameNative}}.length + ' size ' + size + ' exceeds ' + {{or .SizeMax "colferSizeMax"}} + ' UTF-8 bytes');
 
 				var start = i;
 				i += size;
@@ -675,8 +1342,8 @@ const ecmaUnmarshal = `
 			var size = readVarint();
 			if (size < 0)
 				throw new Error('colfer: {{.String}} size exceeds Number.MAX_SAFE_INTEGER');
-			else if (size > colferSizeMax)
-				throw new Error('colfer: {{.String}} size ' + size + ' exceeds ' + colferSizeMax + ' bytes');
+			else if (size > {{or .SizeMax "colferSizeMax"}})
+				throw new Error('colfer: {{.String}} size ' + size + ' exceeds ' + {{or .SizeMax "colferSizeMax"}} + ' bytes');
 
 			var start = i;
 			i += size;
@@ -689,26 +1356,193 @@ const ecmaUnmarshal = `
 		if (header == {{.Index}}) {
 			var l = readVarint();
 			if (l < 0) throw new Error('colfer: {{.String}} length exceeds Number.MAX_SAFE_INTEGER');
-			if (l > colferListMax)
-				throw new Error('colfer: {{.String}} length ' + l + ' exceeds ' + colferListMax + ' elements');
+			if (l > {{or .ListMax "colferListMax"}})
+				throw new Error('colfer: {{.String}} length ' + l + ' exceeds ' + {{or .ListMax "colferListMax"}} + ' elements');
 
 			for (var n = 0; n < l; ++n) {
 				var o = new {{.TypeRef.Pkg.NameNative}}.{{.TypeRef.NameTitle}}();
-				i += o.unmarshal(data.subarray(i));
+				i += o.unmarshal(data.subarray(i){{if .Struct.Pkg.KeepUnknown}}, false{{end}}, depthMax - 1);
 				this.{{.NameNative}}[n] = o;
 			}
 			readHeader();
 		}
+{{else if .TypeUnion}}
+		if (header == {{.Index}}) {
+			if (i >= data.length) throw new Error(EOF);
+			var o;
+			switch (data[i++]) {
+ {{- range .TypeUnion.Members}}
+			case {{.Index}}:
+				o = {kind: '{{.Name}}', value: new {{.Struct.Pkg.NameNative}}.{{.Struct.NameTitle}}()};
+				break;
+ {{- end}}
+			default:
+				throw new Error('colfer: unknown {{.String}} member at byte ' + (i - 1));
+			}
+			i += o.value.unmarshal(data.subarray(i){{if .Struct.Pkg.KeepUnknown}}, false{{end}}, depthMax - 1);
+			this.{{.NameNative}} = o;
+			readHeader();
+		}
 {{else}}
 		if (header == {{.Index}}) {
 			var o = new {{.TypeRef.Pkg.NameNative}}.{{.TypeRef.NameTitle}}();
-			i += o.unmarshal(data.subarray(i));
+			i += o.unmarshal(data.subarray(i){{if .Struct.Pkg.KeepUnknown}}, false{{end}}, depthMax - 1);
 			this.{{.NameNative}} = o;
 			readHeader();
 		}
 {{end}}{{end}}
+{{- if .Pkg.KeepUnknown}}
+		if (header != 127) {
+			if (! serial{{if .Fields}} || (header & 127) <= {{.IndexMax}}{{end}} || data[data.length - 1] != 127)
+				throw new Error('colfer: unknown header at byte ' + (i - 1));
+			this.colferUnknown = data.slice(i - 1, data.length - 1);
+			i = data.length;
+		}
+{{- else}}
 		if (header != 127) throw new Error('colfer: unknown header at byte ' + (i - 1));
+{{- end}}
 		if (i > colferSizeMax)
 			throw new Error('colfer: {{.String}} serial size ' + size + ' exceeds ' + colferSizeMax + ' bytes');
 		return i;
+	}
+{{- if .Pkg.KeepUnknown}}
+
+	// Deserializes the object from an Uint8Array with exactly one serial and
+	// returns the number of bytes read. Any fields with an index beyond {{.IndexMax}}
+	// are kept in property colferUnknown. Nested data structures can not keep
+	// unknown fields.
+	this.{{.NameTitle}}.prototype.unmarshalSerial = function(data) {
+		return this.unmarshal(data, true);
+	}
+{{- end}}`
+
+const ecmaJSON = `
+	// Serializes the object as a JSON text. The JSON object has the fields
+	// present in the serial only, in order of appearance, with the names from
+	// the schema. Integers beyond 2^53 - 1 in magnitude are written as a JSON
+	// string. The mapping matches the generated Go and Java code.
+	this.{{.NameTitle}}.prototype.marshalJSON = function() {
+		var fields = [];
+{{range .Fields}}{{if .TypeKey}}
+		if (this.{{.NameNative}} && this.{{.NameNative}}.size)
+			fields.push('"{{.Name}}":' + jsonMap(this.{{.NameNative}}, {{eq .TypeKey "text"}}, function(v) {
+				return {{template "json-value" .}};
+			}));
+{{else if .TypeList}}
+		if (this.{{.NameNative}} && this.{{.NameNative}}.length)
+			fields.push('"{{.Name}}":' + jsonList(this.{{.NameNative}}, function(v) {
+				return {{template "json-value" .}};
+			}));
+{{else if .TypeUnion}}
+		if (this.{{.NameNative}}) {
+			var u = this.{{.NameNative}};
+			switch (u.kind) {
+ {{- range .TypeUnion.Members}}
+			case '{{.Name}}':
+ {{- end}}
+				break;
+			default:
+				throw new Error('colfer: {{.String}} kind ' + u.kind + ' is not a member');
+			}
+			fields.push('"{{.Name}}":{"kind":' + JSON.stringify(u.kind) + ',"value":' + (u.value == null ? '{}' : u.value.marshalJSON()) + '}');
+		}
+{{else if eq .Type "timestamp"}}
+		if ((this.{{.NameNative}} && this.{{.NameNative}}.getTime()) || this.{{.NameNative}}_ns)
+			fields.push('"{{.Name}}":' + jsonTime(this.{{.NameNative}}, this.{{.NameNative}}_ns));
+{{else}}
+		if ({{if .Optional}}this.{{.NameNative}} != null{{else if eq .Type "float32" "float64"}}this.{{.NameNative}} || Number.isNaN(this.{{.NameNative}}){{else if eq .Type "binary"}}this.{{.NameNative}} && this.{{.NameNative}}.length{{else}}this.{{.NameNative}}{{end}}) {
+			var v = this.{{.NameNative}};
+			fields.push('"{{.Name}}":' + {{template "json-value" .}});
+		}
+{{end}}{{end}}
+		return '{' + fields.join(',') + '}';
+	}
+
+	// Deserializes the object from either a JSON text, or from a value as
+	// returned by JSON.parse, with the mapping of marshalJSON. Fields absent
+	// in the JSON object, or with a null value, remain as is.
+	this.{{.NameTitle}}.prototype.unmarshalJSON = function(json) {
+		var o = jsonObject(typeof json === 'string' ? JSON.parse(json) : json, '{{.String}}');
+		for (var name in o) {
+			var v = o[name];
+			if (v === null) continue;
+
+			switch (name) {
+{{- range .Fields}}
+			case '{{.Name}}':
+{{- if .TypeKey}}
+				var m = new Map();
+				v = jsonObject(v, '{{.String}}');
+				for (var k in v) {
+					var e = v[k];
+					m.set({{if eq .TypeKey "text"}}k{{else}}fromJSONKey(k, {{template "json-range" .TypeKey}}, '{{.String}}'){{end}}, {{template "json-decode" .}});
+				}
+				this.{{.NameNative}} = m;
+{{- else if .TypeList}}
+				if (!Array.isArray(v)) throw new Error('colfer: {{.String}} needs a JSON array');
+				var a = v.map(function(e) {
+					return {{template "json-decode" .}};
+				});
+				this.{{.NameNative}} = {{if eq .Type "float32"}}new Float32Array(a){{else if eq .Type "float64"}}new Float64Array(a)
+ {{- else if eq .Type "uint8"}}new Uint8Array(a){{else if eq .Type "uint16"}}new Uint16Array(a)
+ {{- else if eq .Type "uint32"}}new Uint32Array(a){{else if eq .Type "int32"}}new Int32Array(a){{else}}a{{end}};
+{{- else if .TypeUnion}}{{$f := .}}
+				var u = jsonObject(v, '{{.String}}');
+				switch (u.kind) {
+ {{- range .TypeUnion.Members}}
+				case '{{.Name}}':
+					this.{{$f.NameNative}} = {kind: u.kind, value: new {{.Struct.Pkg.NameNative}}.{{.Struct.NameTitle}}().unmarshalJSON(u.value)};
+					break;
+ {{- end}}
+				default:
+					throw new Error('colfer: {{.String}} kind ' + JSON.stringify(u.kind) + ' is not a member of union {{.TypeUnion}}');
+				}
+{{- else if eq .Type "timestamp"}}
+				var t = fromJSONTime(v, '{{.String}}');
+				this.{{.NameNative}} = t.date;
+				this.{{.NameNative}}_ns = t.ns;
+{{- else}}
+				var e = v;
+				this.{{.NameNative}} = {{template "json-decode" .}};
+{{- end}}
+				break;
+{{- end}}
+			default:
+				throw new Error('colfer: struct {{.String}} has no field ' + JSON.stringify(name));
+			}
+		}
+		return this;
 	}`
+
+const ecmaJSONValue = `
+{{- if .TypeRef}}(v == null ? '{}' : v.marshalJSON())
+{{- else if .TypeEnum}}jsonEnum({{.TypeEnum.Pkg.NameNative}}.{{.TypeEnum.NameTitle}}, v)
+{{- else if eq .Type "bool"}}(v ? 'true' : 'false')
+{{- else if eq .Type "float32"}}jsonFloat32(v)
+{{- else if eq .Type "float64"}}jsonFloat64(v)
+{{- else if eq .Type "text"}}JSON.stringify(v == null ? '' : v)
+{{- else if eq .Type "binary"}}'"' + encodeBase64(v == null ? [] : v) + '"'
+{{- else if eq .Type "uint64" "int64"}}jsonInt64(v)
+{{- else}}String(v)
+{{- end}}`
+
+const ecmaJSONDecode = `
+{{- if .TypeRef}}new {{.TypeRef.Pkg.NameNative}}.{{.TypeRef.NameTitle}}().unmarshalJSON(e)
+{{- else if .TypeEnum}}fromJSONEnum({{.TypeEnum.Pkg.NameNative}}.{{.TypeEnum.NameTitle}}, e, {{template "json-range" .Type}}, '{{.String}}')
+{{- else if eq .Type "bool"}}fromJSONBool(e, '{{.String}}')
+{{- else if eq .Type "float32"}}Math.fround(fromJSONFloat(e, '{{.String}}'))
+{{- else if eq .Type "float64"}}fromJSONFloat(e, '{{.String}}')
+{{- else if eq .Type "text"}}fromJSONText(e, '{{.String}}')
+{{- else if eq .Type "binary"}}decodeBase64(e, '{{.String}}')
+{{- else}}fromJSONInt(e, {{template "json-range" .Type}}, '{{.String}}')
+{{- end}}`
+
+// ecmaJSONRange has the bounds of an integer type, as arguments.
+const ecmaJSONRange = `
+{{- if eq . "uint8"}}0, 255
+{{- else if eq . "uint16"}}0, 65535
+{{- else if eq . "uint32"}}0, 4294967295
+{{- else if eq . "int32"}}-2147483648, 2147483647
+{{- else if eq . "int64"}}Number.MIN_SAFE_INTEGER, Number.MAX_SAFE_INTEGER
+{{- else}}0, Number.MAX_SAFE_INTEGER
+{{- end}}`
@@ -9,6 +9,46 @@ var gen = new function() {
 	var colferSizeMax = 16 * 1024 * 1024;
 	// The upper limit for the number of elements in a list.
 	var colferListMax = 64 * 1024;
+	// The upper limit for the nesting depth of data structures.
+	var colferDepthMax = 100;
+
+	// ConstBool tests boolean constants.
+	Object.defineProperty(this, 'constBool', {value: true, enumerable: true});
+
+	// ConstU8 tests unsigned 8-bit integer constants.
+	Object.defineProperty(this, 'constU8', {value: 255, enumerable: true});
+
+	// ConstU32 tests unsigned 32-bit integer constants.
+	Object.defineProperty(this, 'constU32', {value: 4294967295, enumerable: true});
+
+	// ConstU64 tests unsigned 64-bit integer constants.
+	Object.defineProperty(this, 'constU64', {value: 9007199254740991, enumerable: true});
+
+	// ConstI32 tests signed 32-bit integer constants.
+	Object.defineProperty(this, 'constI32', {value: -2147483648, enumerable: true});
+
+	// ConstI64 tests signed 64-bit integer constants.
+	Object.defineProperty(this, 'constI64', {value: -9007199254740991, enumerable: true});
+
+	// ConstF32 tests 32-bit floating point constants.
+	Object.defineProperty(this, 'constF32', {value: 1.5, enumerable: true});
+
+	// ConstF64 tests 64-bit floating point constants.
+	Object.defineProperty(this, 'constF64', {value: 1e-100, enumerable: true});
+
+	// ConstText tests text constants.
+	Object.defineProperty(this, 'constText', {value: "\"π\"\t??=", enumerable: true});
+
+	// Enumeration of the named uint8 values.
+	// Color tests enumerations.
+	this.Color = Object.freeze({
+		// Red is the zero value.
+		red: 0,
+		// Green is the first in line.
+		green: 1,
+		// Blue is the last one.
+		blue: 2,
+	});
 
 	// Constructor.
 	// O contains all supported data types.
@@ -51,6 +91,48 @@ var gen = new function() {
 		this.f32s = new Float32Array(0);
 		// F64s tests 64-bit floating point lists.
 		this.f64s = new Float64Array(0);
+		// E tests enumerations.
+		this.e = 0;
+		// Bs tests boolean lists.
+		this.bs = [];
+		// U8s tests unsigned 8-bit integer lists.
+		this.u8s = new Uint8Array(0);
+		// U16s tests unsigned 16-bit integer lists.
+		this.u16s = new Uint16Array(0);
+		// U32s tests unsigned 32-bit integer lists.
+		this.u32s = new Uint32Array(0);
+		// U64s tests unsigned 64-bit integer lists.
+		this.u64s = [];
+		// I32s tests signed 32-bit integer lists.
+		this.i32s = new Int32Array(0);
+		// I64s tests signed 64-bit integer lists.
+		this.i64s = [];
+		// Es tests enumeration lists.
+		this.es = new Uint8Array(0);
+		// Mt tests maps with text keys.
+		this.mt = new Map();
+		// Mu tests maps with unsigned integer keys.
+		this.mu = new Map();
+		// Mi tests maps with signed integer keys.
+		this.mi = new Map();
+		// Mo tests maps with data structure values.
+		this.mo = new Map();
+		// Gap tests explicit indexes.
+		this.gap = false;
+		// Host tests field size limits.
+		this.host = '';
+		// Tags tests field list limits.
+		this.tags = [];
+		// Ob tests optional booleans.
+		this.ob = undefined;
+		// Ou32 tests optional unsigned integers.
+		this.ou32 = undefined;
+		// Oi64 tests optional signed integers.
+		this.oi64 = undefined;
+		// Of64 tests optional floating points.
+		this.of64 = undefined;
+		// U tests unions.
+		this.u = null;
 
 		for (var p in init) this[p] = init[p];
 	}
@@ -59,7 +141,15 @@ var gen = new function() {
 	// All null entries in property os will be replaced with a new gen.O.
 	// All null entries in property ss will be replaced with an empty String.
 	// All null entries in property as will be replaced with an empty Array.
-	this.O.prototype.marshal = function(buf) {
+	// All null values in property mt will be replaced with an empty String.
+	// All null values in property mi will be replaced with an empty Array.
+	// All null values in property mo will be replaced with a new gen.O.
+	// All null entries in property tags will be replaced with an empty String.
+	// A null value in property u will be replaced with a new instance of its kind.
+	// The optional depthMax overrides the upper limit for the nesting depth.
+	this.O.prototype.marshal = function(buf, depthMax) {
+		if (depthMax === undefined) depthMax = colferDepthMax;
+		if (depthMax < 1) throw new RangeError('colfer: gen.o exceeds the nesting depth limit; check for reference cycles');
 		if (! buf || !buf.length) buf = new Uint8Array(colferSizeMax);
 		var i = 0;
 		var view = new DataView(buf.buffer);
@@ -199,7 +289,9 @@ var gen = new function() {
 
 		if (this.o) {
 			buf[i++] = 10;
-			var b = this.o.marshal();
+			if (this.o === this)
+				throw new RangeError('colfer: gen.o.o makes a reference cycle');
+			var b = this.o.marshal(null, depthMax - 1);
 			buf.set(b, i);
 			i += b.length;
 		}
@@ -215,10 +307,12 @@ var gen = new function() {
 					v = new gen.O();
 					a[vi] = v;
 				}
-				var b = v.marshal();
+				if (v === this)
+					throw new RangeError('colfer: gen.o.os makes a reference cycle');
+				var b = v.marshal(null, depthMax - 1);
 				buf.set(b, i);
 				i += b.length;
-			});
+			}, this);
 		}
 
 		if (this.ss && this.ss.length) {
@@ -303,6 +397,342 @@ var gen = new function() {
 			});
 		}
 
+		if (this.e) {
+			if (this.e > 255 || this.e < 0)
+				throw new Error('colfer: gen/O field e out of reach: ' + this.e);
+			buf[i++] = 18;
+			buf[i++] = this.e;
+		}
+
+		if (this.bs && this.bs.length) {
+			var a = this.bs;
+			if (a.length > colferListMax)
+				throw new Error('colfer: gen.o.bs length exceeds colferListMax');
+			buf[i++] = 19;
+			i = encodeVarint(buf, i, a.length);
+			a.forEach(function(b) {
+				buf[i++] = b ? 1 : 0;
+			});
+		}
+
+		if (this.u8s && this.u8s.length) {
+			var a = this.u8s;
+			if (a.length > colferListMax)
+				throw new Error('colfer: gen.o.u8s length exceeds colferListMax');
+			buf[i++] = 20;
+			i = encodeVarint(buf, i, a.length);
+			a.forEach(function(v, vi) {
+				if (v > 255 || v < 0)
+					throw new Error('colfer: gen.o.u8s[' + vi + '] out of reach: ' + v);
+				buf[i++] = v;
+			});
+		}
+
+		if (this.u16s && this.u16s.length) {
+			var a = this.u16s;
+			if (a.length > colferListMax)
+				throw new Error('colfer: gen.o.u16s length exceeds colferListMax');
+			buf[i++] = 21;
+			i = encodeVarint(buf, i, a.length);
+			a.forEach(function(v, vi) {
+				if (v > 65535 || v < 0)
+					throw new Error('colfer: gen.o.u16s[' + vi + '] out of reach: ' + v);
+				i = encodeVarint(buf, i, v);
+			});
+		}
+
+		if (this.u32s && this.u32s.length) {
+			var a = this.u32s;
+			if (a.length > colferListMax)
+				throw new Error('colfer: gen.o.u32s length exceeds colferListMax');
+			buf[i++] = 22;
+			i = encodeVarint(buf, i, a.length);
+			a.forEach(function(v, vi) {
+				if (v > 4294967295 || v < 0)
+					throw new Error('colfer: gen.o.u32s[' + vi + '] out of reach: ' + v);
+				i = encodeVarint(buf, i, v);
+			});
+		}
+
+		if (this.u64s && this.u64s.length) {
+			var a = this.u64s;
+			if (a.length > colferListMax)
+				throw new Error('colfer: gen.o.u64s length exceeds colferListMax');
+			buf[i++] = 23;
+			i = encodeVarint(buf, i, a.length);
+			a.forEach(function(v, vi) {
+				if (v < 0)
+					throw new Error('colfer: gen.o.u64s[' + vi + '] out of reach: ' + v);
+				if (v > Number.MAX_SAFE_INTEGER)
+					throw new Error('colfer: gen.o.u64s[' + vi + '] exceeds Number.MAX_SAFE_INTEGER');
+				i = encodeVarint(buf, i, v);
+			});
+		}
+
+		if (this.i32s && this.i32s.length) {
+			var a = this.i32s;
+			if (a.length > colferListMax)
+				throw new Error('colfer: gen.o.i32s length exceeds colferListMax');
+			buf[i++] = 24;
+			i = encodeVarint(buf, i, a.length);
+			a.forEach(function(v, vi) {
+				if (v > 2147483647 || v < -2147483648)
+					throw new Error('colfer: gen.o.i32s[' + vi + '] exceeds 32-bit range');
+				// zig-zag encoding without exceeding the integer precision
+				var neg = v < 0 ? 1 : 0;
+				if (neg) v = -v - 1;
+				if (v < 64) {
+					buf[i++] = v * 2 + neg;
+				} else {
+					buf[i++] = (v % 64) * 2 + neg + 128;
+					i = encodeVarint(buf, i, Math.floor(v / 64));
+				}
+			});
+		}
+
+		if (this.i64s && this.i64s.length) {
+			var a = this.i64s;
+			if (a.length > colferListMax)
+				throw new Error('colfer: gen.o.i64s length exceeds colferListMax');
+			buf[i++] = 25;
+			i = encodeVarint(buf, i, a.length);
+			a.forEach(function(v, vi) {
+				if (v > Number.MAX_SAFE_INTEGER || v < Number.MIN_SAFE_INTEGER)
+					throw new Error('colfer: gen.o.i64s[' + vi + '] exceeds Number.MAX_SAFE_INTEGER');
+				// zig-zag encoding without exceeding the integer precision
+				var neg = v < 0 ? 1 : 0;
+				if (neg) v = -v - 1;
+				if (v < 64) {
+					buf[i++] = v * 2 + neg;
+				} else {
+					buf[i++] = (v % 64) * 2 + neg + 128;
+					i = encodeVarint(buf, i, Math.floor(v / 64));
+				}
+			});
+		}
+
+		if (this.es && this.es.length) {
+			var a = this.es;
+			if (a.length > colferListMax)
+				throw new Error('colfer: gen.o.es length exceeds colferListMax');
+			buf[i++] = 26;
+			i = encodeVarint(buf, i, a.length);
+			a.forEach(function(v, vi) {
+				if (v > 255 || v < 0)
+					throw new Error('colfer: gen.o.es[' + vi + '] out of reach: ' + v);
+				buf[i++] = v;
+			});
+		}
+
+		if (this.mt && this.mt.size) {
+			var m = this.mt;
+			if (m.size > colferListMax)
+				throw new Error('colfer: gen.o.mt length exceeds colferListMax');
+			buf[i++] = 27;
+			i = encodeVarint(buf, i, m.size);
+
+			var keys = [];
+			m.forEach(function(v, k) {
+				var utf8 = encodeUTF8(k);
+				keys.push({k: k, utf8: utf8});
+			});
+			keys.sort(function(a, b) { return compareBytes(a.utf8, b.utf8); });
+
+			keys.forEach(function(e) {
+				i = encodeVarint(buf, i, e.utf8.length);
+				buf.set(e.utf8, i);
+				i += e.utf8.length;
+
+				var v = m.get(e.k);
+				if (v == null) {
+					v = '';
+					m.set(e.k, v);
+				}
+				var utf8 = encodeUTF8(v);
+				i = encodeVarint(buf, i, utf8.length);
+				buf.set(utf8, i);
+				i += utf8.length;
+			}, this);
+		}
+
+		if (this.mu && this.mu.size) {
+			var m = this.mu;
+			if (m.size > colferListMax)
+				throw new Error('colfer: gen.o.mu length exceeds colferListMax');
+			buf[i++] = 28;
+			i = encodeVarint(buf, i, m.size);
+
+			var keys = [];
+			m.forEach(function(v, k) {
+				if (k > 4294967295 || k < 0)
+					throw new Error('colfer: gen.o.mu key out of reach: ' + k);
+				keys.push({k: k});
+			});
+			keys.sort(function(a, b) { return a.k - b.k; });
+
+			keys.forEach(function(e) {
+				i = encodeVarint(buf, i, e.k);
+
+				var v = m.get(e.k);
+				if (v > Number.MAX_SAFE_INTEGER || v < Number.MIN_SAFE_INTEGER)
+					throw new Error('colfer: gen.o.mu value exceeds Number.MAX_SAFE_INTEGER');
+				i = encodeZigZag(buf, i, v);
+			}, this);
+		}
+
+		if (this.mi && this.mi.size) {
+			var m = this.mi;
+			if (m.size > colferListMax)
+				throw new Error('colfer: gen.o.mi length exceeds colferListMax');
+			buf[i++] = 29;
+			i = encodeVarint(buf, i, m.size);
+
+			var keys = [];
+			m.forEach(function(v, k) {
+				if (k > Number.MAX_SAFE_INTEGER || k < Number.MIN_SAFE_INTEGER)
+					throw new Error('colfer: gen.o.mi key exceeds Number.MAX_SAFE_INTEGER');
+				keys.push({k: k});
+			});
+			keys.sort(function(a, b) { return a.k - b.k; });
+
+			keys.forEach(function(e) {
+				i = encodeZigZag(buf, i, e.k);
+
+				var v = m.get(e.k);
+				if (v == null) {
+					v = new Uint8Array(0);
+					m.set(e.k, v);
+				}
+				i = encodeVarint(buf, i, v.length);
+				buf.set(v, i);
+				i += v.length;
+			}, this);
+		}
+
+		if (this.mo && this.mo.size) {
+			var m = this.mo;
+			if (m.size > colferListMax)
+				throw new Error('colfer: gen.o.mo length exceeds colferListMax');
+			buf[i++] = 30;
+			i = encodeVarint(buf, i, m.size);
+
+			var keys = [];
+			m.forEach(function(v, k) {
+				var utf8 = encodeUTF8(k);
+				keys.push({k: k, utf8: utf8});
+			});
+			keys.sort(function(a, b) { return compareBytes(a.utf8, b.utf8); });
+
+			keys.forEach(function(e) {
+				i = encodeVarint(buf, i, e.utf8.length);
+				buf.set(e.utf8, i);
+				i += e.utf8.length;
+
+				var v = m.get(e.k);
+				if (v == null) {
+					v = new gen.O();
+					m.set(e.k, v);
+				}
+				if (v === this)
+					throw new RangeError('colfer: gen.o.mo makes a reference cycle');
+				var b = v.marshal(null, depthMax - 1);
+				buf.set(b, i);
+				i += b.length;
+			}, this);
+		}
+
+		if (this.gap)
+			buf[i++] = 32;
+
+		if (this.host) {
+			buf[i++] = 33;
+			var utf8 = encodeUTF8(this.host);
+			if (utf8.length > 4)
+				throw new Error('colfer: gen.o.host size ' + utf8.length + ' exceeds 4 UTF-8 bytes');
+			i = encodeVarint(buf, i, utf8.length);
+			buf.set(utf8, i);
+			i += utf8.length;
+		}
+
+		if (this.tags && this.tags.length) {
+			var a = this.tags;
+			if (a.length > 2)
+				throw new Error('colfer: gen.o.tags length exceeds 2');
+			buf[i++] = 34;
+			i = encodeVarint(buf, i, a.length);
+
+			a.forEach(function(s, si) {
+				if (s == null) {
+					s = "";
+					a[si] = s;
+				}
+				var utf8 = encodeUTF8(s);
+				if (utf8.length > 1)
+					throw new Error('colfer: gen.o.tags element ' + si + ' size ' + utf8.length + ' exceeds 1 UTF-8 bytes');
+				i = encodeVarint(buf, i, utf8.length);
+				buf.set(utf8, i);
+				i += utf8.length;
+			});
+		}
+
+		if (this.ob != null)
+			buf[i++] = this.ob ? 35 : 35 | 128;
+
+		if (this.ou32 != null) {
+			if (this.ou32 > 4294967295 || this.ou32 < 0)
+				throw new Error('colfer: gen/O field ou32 out of reach: ' + this.ou32);
+			if (this.ou32 < 0x200000) {
+				buf[i++] = 36;
+				i = encodeVarint(buf, i, this.ou32);
+			} else {
+				buf[i++] = 36 | 128;
+				view.setUint32(i, this.ou32);
+				i += 4;
+			}
+		}
+
+		if (this.oi64 != null) {
+			if (this.oi64 < 0) {
+				buf[i++] = 37 | 128;
+				if (this.oi64 < Number.MIN_SAFE_INTEGER)
+					throw new Error('colfer: gen/O field oi64 exceeds Number.MIN_SAFE_INTEGER');
+				i = encodeVarint(buf, i, -this.oi64);
+			} else {
+				buf[i++] = 37; 
+				if (this.oi64 > Number.MAX_SAFE_INTEGER)
+					throw new Error('colfer: gen/O field oi64 exceeds Number.MAX_SAFE_INTEGER');
+				i = encodeVarint(buf, i, this.oi64);
+			}
+		}
+
+		if (this.of64 != null) {
+			buf[i++] = 38;
+			view.setFloat64(i, this.of64);
+			i += 8;
+		}
+
+		if (this.u) {
+			buf[i++] = 39;
+			var u = this.u;
+			switch (u.kind) {
+			case 'o':
+				buf[i++] = 0;
+				if (u.value == null) u.value = new gen.O();
+				if (u.value === this)
+					throw new RangeError('colfer: gen.o.u makes a reference cycle');
+				break;
+			case 'point':
+				buf[i++] = 1;
+				if (u.value == null) u.value = new gen.Point();
+				break;
+			default:
+				throw new Error('colfer: gen.o.u kind ' + u.kind + ' is not a member');
+			}
+			var b = u.value.marshal(null, depthMax - 1);
+			buf.set(b, i);
+			i += b.length;
+		}
+
 
 		buf[i++] = 127;
 		if (i >= colferSizeMax)
@@ -311,7 +741,10 @@ var gen = new function() {
 	}
 
 	// Deserializes the object from an Uint8Array and returns the number of bytes read.
-	this.O.prototype.unmarshal = function(data) {
+	// The optional depthMax overrides the upper limit for the nesting depth.
+	this.O.prototype.unmarshal = function(data, depthMax) {
+		if (depthMax === undefined) depthMax = colferDepthMax;
+		if (depthMax < 1) throw new RangeError('colfer: gen.o exceeds the nesting depth limit');
 		if (!data || ! data.length) throw new Error(EOF);
 		var header = data[0];
 		var i = 1;
@@ -338,6 +771,22 @@ var gen = new function() {
 			return -1;
 		}
 
+		// Reads a variable-length integer, optionally with zig-zag encoding.
+		// The return is null when the value exceeds Number.MAX_SAFE_INTEGER.
+		var readInt = function(zigZag) {
+			if (i >= data.length) throw new Error(EOF);
+			var c = data[i++];
+			var neg = zigZag ? c & 1 : 0;
+			var x = zigZag ? (c & 127) >>> 1 : c & 127;
+			for (var scale = zigZag ? 64 : 128; c > 127; scale *= 128) {
+				if (i >= data.length) throw new Error(EOF);
+				c = data[i++];
+				x += (c & 127) * scale;
+			}
+			if (x > Number.MAX_SAFE_INTEGER - neg) return null;
+			return neg ? -x - 1 : x;
+		}
+
 		if (header == 0) {
 			this.b = true;
 			readHeader();
@@ -465,7 +914,7 @@ var gen = new function() {
 
 		if (header == 10) {
 			var o = new gen.O();
-			i += o.unmarshal(data.subarray(i));
+			i += o.unmarshal(data.subarray(i), depthMax - 1);
 			this.o = o;
 			readHeader();
 		}
@@ -478,7 +927,7 @@ var gen = new function() {
 
 			for (var n = 0; n < l; ++n) {
 				var o = new gen.O();
-				i += o.unmarshal(data.subarray(i));
+				i += o.unmarshal(data.subarray(i), depthMax - 1);
 				this.os[n] = o;
 			}
 			readHeader();
@@ -574,38 +1023,1257 @@ var gen = new function() {
 			readHeader();
 		}
 
-		if (header != 127) throw new Error('colfer: unknown header at byte ' + (i - 1));
-		if (i > colferSizeMax)
-			throw new Error('colfer: gen.o serial size ' + size + ' exceeds ' + colferSizeMax + ' bytes');
-		return i;
-	}
+		if (header == 18) {
+			if (i + 1 >= data.length) throw new Error(EOF);
+			this.e = data[i++];
+			header = data[i++];
+		}
 
-	// private section
+		if (header == 19) {
+			var l = readVarint();
+			if (l < 0) throw new Error('colfer: gen.o.bs length exceeds Number.MAX_SAFE_INTEGER');
+			if (l > colferListMax)
+				throw new Error('colfer: gen.o.bs length ' + l + ' exceeds ' + colferListMax + ' elements');
+			if (i + l > data.length) throw new Error(EOF);
 
-	var encodeVarint = function(bytes, i, x) {
-		while (x > 127) {
-			bytes[i++] = (x & 127) | 128;
-			x /= 128;
+			this.bs = new Array(l);
+			for (var n = 0; n < l; ++n)
+				this.bs[n] = data[i++] != 0;
+			readHeader();
 		}
-		bytes[i++] = x & 127;
-		return i;
-	}
 
-	function decodeInt64(data, i) {
-		var v = 0, j = i + 7, m = 1;
-		if (data[i] & 128) {
-			// two's complement
-			for (var carry = 1; j >= i; --j, m *= 256) {
-				var b = (data[j] ^ 255) + carry;
-				carry = b >> 8;
-				v += (b & 255) * m;
+		if (header == 20) {
+			var l = readVarint();
+			if (l < 0) throw new Error('colfer: gen.o.u8s length exceeds Number.MAX_SAFE_INTEGER');
+			if (l > colferListMax)
+				throw new Error('colfer: gen.o.u8s length ' + l + ' exceeds ' + colferListMax + ' elements');
+			if (i + l > data.length) throw new Error(EOF);
+
+			this.u8s = data.slice(i, i + l);
+			i += l;
+			readHeader();
+		}
+
+		if (header == 21) {
+			var l = readVarint();
+			if (l < 0) throw new Error('colfer: gen.o.u16s length exceeds Number.MAX_SAFE_INTEGER');
+			if (l > colferListMax)
+				throw new Error('colfer: gen.o.u16s length ' + l + ' exceeds ' + colferListMax + ' elements');
+			if (i + l > data.length) throw new Error(EOF);
+
+			this.u16s = new Uint16Array(l);
+			for (var n = 0; n < l; ++n) {
+				if (i >= data.length) throw new Error(EOF);
+				var c = data[i++];
+				var x = c & 127;
+				for (var scale = 128; c > 127; scale *= 128) {
+					if (i >= data.length) throw new Error(EOF);
+					c = data[i++];
+					x += (c & 127) * scale;
+				}
+				if (x > Number.MAX_SAFE_INTEGER)
+					throw new Error('colfer: gen.o.u16s[' + n + '] exceeds Number.MAX_SAFE_INTEGER');
+				this.u16s[n] = x;
 			}
-			v = -v;
-		} else {
-			for (; j >= i; --j, m *= 256)
-				v += data[j] * m;
+			readHeader();
 		}
-		return v;
+
+		if (header == 22) {
+			var l = readVarint();
+			if (l < 0) throw new Error('colfer: gen.o.u32s length exceeds Number.MAX_SAFE_INTEGER');
+			if (l > colferListMax)
+				throw new Error('colfer: gen.o.u32s length ' + l + ' exceeds ' + colferListMax + ' elements');
+			if (i + l > data.length) throw new Error(EOF);
+
+			this.u32s = new Uint32Array(l);
+			for (var n = 0; n < l; ++n) {
+				if (i >= data.length) throw new Error(EOF);
+				var c = data[i++];
+				var x = c & 127;
+				for (var scale = 128; c > 127; scale *= 128) {
+					if (i >= data.length) throw new Error(EOF);
+					c = data[i++];
+					x += (c & 127) * scale;
+				}
+				if (x > Number.MAX_SAFE_INTEGER)
+					throw new Error('colfer: gen.o.u32s[' + n + '] exceeds Number.MAX_SAFE_INTEGER');
+				this.u32s[n] = x;
+			}
+			readHeader();
+		}
+
+		if (header == 23) {
+			var l = readVarint();
+			if (l < 0) throw new Error('colfer: gen.o.u64s length exceeds Number.MAX_SAFE_INTEGER');
+			if (l > colferListMax)
+				throw new Error('colfer: gen.o.u64s length ' + l + ' exceeds ' + colferListMax + ' elements');
+			if (i + l > data.length) throw new Error(EOF);
+
+			this.u64s = new Array(l);
+			for (var n = 0; n < l; ++n) {
+				if (i >= data.length) throw new Error(EOF);
+				var c = data[i++];
+				var x = c & 127;
+				for (var scale = 128; c > 127; scale *= 128) {
+					if (i >= data.length) throw new Error(EOF);
+					c = data[i++];
+					x += (c & 127) * scale;
+				}
+				if (x > Number.MAX_SAFE_INTEGER)
+					throw new Error('colfer: gen.o.u64s[' + n + '] exceeds Number.MAX_SAFE_INTEGER');
+				this.u64s[n] = x;
+			}
+			readHeader();
+		}
+
+		if (header == 24) {
+			var l = readVarint();
+			if (l < 0) throw new Error('colfer: gen.o.i32s length exceeds Number.MAX_SAFE_INTEGER');
+			if (l > colferListMax)
+				throw new Error('colfer: gen.o.i32s length ' + l + ' exceeds ' + colferListMax + ' elements');
+			if (i + l > data.length) throw new Error(EOF);
+
+			this.i32s = new Int32Array(l);
+			for (var n = 0; n < l; ++n) {
+				if (i >= data.length) throw new Error(EOF);
+				var c = data[i++];
+				// zig-zag decoding without exceeding the integer precision
+				var neg = c & 1;
+				var x = (c & 127) >>> 1;
+				for (var scale = 64; c > 127; scale *= 128) {
+					if (i >= data.length) throw new Error(EOF);
+					c = data[i++];
+					x += (c & 127) * scale;
+				}
+				if (x > Number.MAX_SAFE_INTEGER - neg)
+					throw new Error('colfer: gen.o.i32s[' + n + '] exceeds Number.MAX_SAFE_INTEGER');
+				this.i32s[n] = neg ? -x - 1 : x;
+			}
+			readHeader();
+		}
+
+		if (header == 25) {
+			var l = readVarint();
+			if (l < 0) throw new Error('colfer: gen.o.i64s length exceeds Number.MAX_SAFE_INTEGER');
+			if (l > colferListMax)
+				throw new Error('colfer: gen.o.i64s length ' + l + ' exceeds ' + colferListMax + ' elements');
+			if (i + l > data.length) throw new Error(EOF);
+
+			this.i64s = new Array(l);
+			for (var n = 0; n < l; ++n) {
+				if (i >= data.length) throw new Error(EOF);
+				var c = data[i++];
+				// zig-zag decoding without exceeding the integer precision
+				var neg = c & 1;
+				var x = (c & 127) >>> 1;
+				for (var scale = 64; c > 127; scale *= 128) {
+					if (i >= data.length) throw new Error(EOF);
+					c = data[i++];
+					x += (c & 127) * scale;
+				}
+				if (x > Number.MAX_SAFE_INTEGER - neg)
+					throw new Error('colfer: gen.o.i64s[' + n + '] exceeds Number.MAX_SAFE_INTEGER');
+				this.i64s[n] = neg ? -x - 1 : x;
+			}
+			readHeader();
+		}
+
+		if (header == 26) {
+			var l = readVarint();
+			if (l < 0) throw new Error('colfer: gen.o.es length exceeds Number.MAX_SAFE_INTEGER');
+			if (l > colferListMax)
+				throw new Error('colfer: gen.o.es length ' + l + ' exceeds ' + colferListMax + ' elements');
+			if (i + l > data.length) throw new Error(EOF);
+
+			this.es = data.slice(i, i + l);
+			i += l;
+			readHeader();
+		}
+
+		if (header == 27) {
+			var l = readVarint();
+			if (l < 0) throw new Error('colfer: gen.o.mt length exceeds Number.MAX_SAFE_INTEGER');
+			if (l > colferListMax)
+				throw new Error('colfer: gen.o.mt length ' + l + ' exceeds ' + colferListMax + ' elements');
+			if (i + l * 2 > data.length) throw new Error(EOF);
+
+			var m = new Map(), last = null;
+			for (var n = 0; n < l; ++n) {
+				var start = i;
+				var size = readVarint();
+				if (size < 0)
+					throw new Error('colfer: gen.o.mt key size exceeds Number.MAX_SAFE_INTEGER');
+				else if (size > colferSizeMax)
+					throw new Error('colfer: gen.o.mt key size ' + size + ' exceeds ' + colferSizeMax + ' UTF-8 bytes');
+				i += size;
+				if (i > data.length) throw new Error(EOF);
+				var utf8 = data.subarray(i - size, i);
+				if (last != null && compareBytes(last, utf8) >= 0)
+					throw new Error('colfer: gen.o.mt key at byte ' + start + ' out of order');
+				last = utf8;
+				var k = decodeUTF8(utf8);
+
+				var size = readVarint();
+				if (size < 0)
+					throw new Error('colfer: gen.o.mt value size exceeds Number.MAX_SAFE_INTEGER');
+				else if (size > colferSizeMax)
+					throw new Error('colfer: gen.o.mt value size ' + size + ' exceeds ' + colferSizeMax + ' UTF-8 bytes');
+				i += size;
+				if (i > data.length) throw new Error(EOF);
+				var v = decodeUTF8(data.subarray(i - size, i));
+				m.set(k, v);
+			}
+			this.mt = m;
+			readHeader();
+		}
+
+		if (header == 28) {
+			var l = readVarint();
+			if (l < 0) throw new Error('colfer: gen.o.mu length exceeds Number.MAX_SAFE_INTEGER');
+			if (l > colferListMax)
+				throw new Error('colfer: gen.o.mu length ' + l + ' exceeds ' + colferListMax + ' elements');
+			if (i + l * 2 > data.length) throw new Error(EOF);
+
+			var m = new Map(), last = null;
+			for (var n = 0; n < l; ++n) {
+				var start = i;
+				var k = readInt(false);
+				if (k == null) throw new Error('colfer: gen.o.mu key exceeds Number.MAX_SAFE_INTEGER');
+				if (last != null && k <= last)
+					throw new Error('colfer: gen.o.mu key at byte ' + start + ' out of order');
+				last = k;
+
+				var v = readInt(true);
+				if (v == null) throw new Error('colfer: gen.o.mu value exceeds Number.MAX_SAFE_INTEGER');
+				m.set(k, v);
+			}
+			this.mu = m;
+			readHeader();
+		}
+
+		if (header == 29) {
+			var l = readVarint();
+			if (l < 0) throw new Error('colfer: gen.o.mi length exceeds Number.MAX_SAFE_INTEGER');
+			if (l > colferListMax)
+				throw new Error('colfer: gen.o.mi length ' + l + ' exceeds ' + colferListMax + ' elements');
+			if (i + l * 2 > data.length) throw new Error(EOF);
+
+			var m = new Map(), last = null;
+			for (var n = 0; n < l; ++n) {
+				var start = i;
+				var k = readInt(true);
+				if (k == null) throw new Error('colfer: gen.o.mi key exceeds Number.MAX_SAFE_INTEGER');
+				if (last != null && k <= last)
+					throw new Error('colfer: gen.o.mi key at byte ' + start + ' out of order');
+				last = k;
+
+				var size = readVarint();
+				if (size < 0)
+					throw new Error('colfer: gen.o.mi value size exceeds Number.MAX_SAFE_INTEGER');
+				else if (size > colferSizeMax)
+					throw new Error('colfer: gen.o.mi value size ' + size + ' exceeds ' + colferSizeMax + ' bytes');
+				i += size;
+				if (i > data.length) throw new Error(EOF);
+				var v = data.slice(i - size, i);
+				m.set(k, v);
+			}
+			this.mi = m;
+			readHeader();
+		}
+
+		if (header == 30) {
+			var l = readVarint();
+			if (l < 0) throw new Error('colfer: gen.o.mo length exceeds Number.MAX_SAFE_INTEGER');
+			if (l > colferListMax)
+				throw new Error('colfer: gen.o.mo length ' + l + ' exceeds ' + colferListMax + ' elements');
+			if (i + l * 2 > data.length) throw new Error(EOF);
+
+			var m = new Map(), last = null;
+			for (var n = 0; n < l; ++n) {
+				var start = i;
+				var size = readVarint();
+				if (size < 0)
+					throw new Error('colfer: gen.o.mo key size exceeds Number.MAX_SAFE_INTEGER');
+				else if (size > colferSizeMax)
+					throw new Error('colfer: gen.o.mo key size ' + size + ' exceeds ' + colferSizeMax + ' UTF-8 bytes');
+				i += size;
+				if (i > data.length) throw new Error(EOF);
+				var utf8 = data.subarray(i - size, i);
+				if (last != null && compareBytes(last, utf8) >= 0)
+					throw new Error('colfer: gen.o.mo key at byte ' + start + ' out of order');
+				last = utf8;
+				var k = decodeUTF8(utf8);
+
+				var v = new gen.O();
+				i += v.unmarshal(data.subarray(i), depthMax - 1);
+				m.set(k, v);
+			}
+			this.mo = m;
+			readHeader();
+		}
+
+		if (header == 32) {
+			this.gap = true;
+			readHeader();
+		}
+
+		if (header == 33) {
+			var size = readVarint();
+			if (size < 0)
+				throw new Error('colfer: gen.o.host size exceeds Number.MAX_SAFE_INTEGER');
+			else if (size > 4)
+				throw new Error('colfer: gen.o.host size ' + size + ' exceeds ' + 4 + ' UTF-8 bytes');
+
+			var start = i;
+			i += size;
+			if (i > data.length) throw new Error(EOF);
+			this.host = decodeUTF8(data.subarray(start, i));
+			readHeader();
+		}
+
+		if (header == 34) {
+			var l = readVarint();
+			if (l < 0) throw new Error('colfer: gen.o.tags length exceeds Number.MAX_SAFE_INTEGER');
+			if (l > 2)
+				throw new Error('colfer: gen.o.tags length ' + l + ' exceeds ' + 2 + ' elements');
+
+			this.tags = new Array(l);
+			for (var n = 0; n < l; ++n) {
+				var size = readVarint();
+				if (size < 0)
+					throw new Error('colfer: gen.o.tags element ' + this.tags.length + ' size exceeds Number.MAX_SAFE_INTEGER');
+				else if (size > 1)
+					throw new Error('colfer: gen.o.tags element ' + this.tags.length + ' size ' + size + ' exceeds ' + 1 + ' UTF-8 bytes');
+
+				var start = i;
+				i += size;
+				if (i > data.length) throw new Error(EOF);
+				this.tags[n] = decodeUTF8(data.subarray(start, i));
+			}
+			readHeader();
+		}
+
+		if (header == 35) {
+			this.ob = true;
+			readHeader();
+		} else if (header == (35 | 128)) {
+			this.ob = false;
+			readHeader();
+		}
+
+		if (header == 36) {
+			var x = readVarint();
+			if (x < 0) throw new Error('colfer: gen/O field ou32 exceeds Number.MAX_SAFE_INTEGER');
+			this.ou32 = x;
+			readHeader();
+		} else if (header == (36 | 128)) {
+			if (i + 4 > data.length) throw new Error(EOF);
+			this.ou32 = view.getUint32(i);
+			i += 4;
+			readHeader();
+		}
+
+		if (header == 37) {
+			var x = readVarint();
+			if (x < 0) throw new Error('colfer: gen/O field oi64 exceeds Number.MAX_SAFE_INTEGER');
+			this.oi64 = x;
+			readHeader();
+		} else if (header == (37 | 128)) {
+			var x = readVarint();
+			if (x < 0) throw new Error('colfer: gen/O field oi64 exceeds Number.MAX_SAFE_INTEGER');
+			this.oi64 = -1 * x;
+			readHeader();
+		}
+
+		if (header == 38) {
+			if (i + 8 > data.length) throw new Error(EOF);
+			this.of64 = view.getFloat64(i);
+			i += 8;
+			readHeader();
+		}
+
+		if (header == 39) {
+			if (i >= data.length) throw new Error(EOF);
+			var o;
+			switch (data[i++]) {
+			case 0:
+				o = {kind: 'o', value: new gen.O()};
+				break;
+			case 1:
+				o = {kind: 'point', value: new gen.Point()};
+				break;
+			default:
+				throw new Error('colfer: unknown gen.o.u member at byte ' + (i - 1));
+			}
+			i += o.value.unmarshal(data.subarray(i), depthMax - 1);
+			this.u = o;
+			readHeader();
+		}
+
+		if (header != 127) throw new Error('colfer: unknown header at byte ' + (i - 1));
+		if (i > colferSizeMax)
+			throw new Error('colfer: gen.o serial size ' + size + ' exceeds ' + colferSizeMax + ' bytes');
+		return i;
+	}
+
+	// Serializes the object as a JSON text. The JSON object has the fields
+	// present in the serial only, in order of appearance, with the names from
+	// the schema. Integers beyond 2^53 - 1 in magnitude are written as a JSON
+	// string. The mapping matches the generated Go and Java code.
+	this.O.prototype.marshalJSON = function() {
+		var fields = [];
+
+		if (this.b) {
+			var v = this.b;
+			fields.push('"b":' + (v ? 'true' : 'false'));
+		}
+
+		if (this.u32) {
+			var v = this.u32;
+			fields.push('"u32":' + String(v));
+		}
+
+		if (this.u64) {
+			var v = this.u64;
+			fields.push('"u64":' + jsonInt64(v));
+		}
+
+		if (this.i32) {
+			var v = this.i32;
+			fields.push('"i32":' + String(v));
+		}
+
+		if (this.i64) {
+			var v = this.i64;
+			fields.push('"i64":' + jsonInt64(v));
+		}
+
+		if (this.f32 || Number.isNaN(this.f32)) {
+			var v = this.f32;
+			fields.push('"f32":' + jsonFloat32(v));
+		}
+
+		if (this.f64 || Number.isNaN(this.f64)) {
+			var v = this.f64;
+			fields.push('"f64":' + jsonFloat64(v));
+		}
+
+		if ((this.t && this.t.getTime()) || this.t_ns)
+			fields.push('"t":' + jsonTime(this.t, this.t_ns));
+
+		if (this.s) {
+			var v = this.s;
+			fields.push('"s":' + JSON.stringify(v == null ? '' : v));
+		}
+
+		if (this.a && this.a.length) {
+			var v = this.a;
+			fields.push('"a":' + '"' + encodeBase64(v == null ? [] : v) + '"');
+		}
+
+		if (this.o) {
+			var v = this.o;
+			fields.push('"o":' + (v == null ? '{}' : v.marshalJSON()));
+		}
+
+		if (this.os && this.os.length)
+			fields.push('"os":' + jsonList(this.os, function(v) {
+				return (v == null ? '{}' : v.marshalJSON());
+			}));
+
+		if (this.ss && this.ss.length)
+			fields.push('"ss":' + jsonList(this.ss, function(v) {
+				return JSON.stringify(v == null ? '' : v);
+			}));
+
+		if (this.as && this.as.length)
+			fields.push('"as":' + jsonList(this.as, function(v) {
+				return '"' + encodeBase64(v == null ? [] : v) + '"';
+			}));
+
+		if (this.u8) {
+			var v = this.u8;
+			fields.push('"u8":' + String(v));
+		}
+
+		if (this.u16) {
+			var v = this.u16;
+			fields.push('"u16":' + String(v));
+		}
+
+		if (this.f32s && this.f32s.length)
+			fields.push('"f32s":' + jsonList(this.f32s, function(v) {
+				return jsonFloat32(v);
+			}));
+
+		if (this.f64s && this.f64s.length)
+			fields.push('"f64s":' + jsonList(this.f64s, function(v) {
+				return jsonFloat64(v);
+			}));
+
+		if (this.e) {
+			var v = this.e;
+			fields.push('"e":' + jsonEnum(gen.Color, v));
+		}
+
+		if (this.bs && this.bs.length)
+			fields.push('"bs":' + jsonList(this.bs, function(v) {
+				return (v ? 'true' : 'false');
+			}));
+
+		if (this.u8s && this.u8s.length)
+			fields.push('"u8s":' + jsonList(this.u8s, function(v) {
+				return String(v);
+			}));
+
+		if (this.u16s && this.u16s.length)
+			fields.push('"u16s":' + jsonList(this.u16s, function(v) {
+				return String(v);
+			}));
+
+		if (this.u32s && this.u32s.length)
+			fields.push('"u32s":' + jsonList(this.u32s, function(v) {
+				return String(v);
+			}));
+
+		if (this.u64s && this.u64s.length)
+			fields.push('"u64s":' + jsonList(this.u64s, function(v) {
+				return jsonInt64(v);
+			}));
+
+		if (this.i32s && this.i32s.length)
+			fields.push('"i32s":' + jsonList(this.i32s, function(v) {
+				return String(v);
+			}));
+
+		if (this.i64s && this.i64s.length)
+			fields.push('"i64s":' + jsonList(this.i64s, function(v) {
+				return jsonInt64(v);
+			}));
+
+		if (this.es && this.es.length)
+			fields.push('"es":' + jsonList(this.es, function(v) {
+				return jsonEnum(gen.Color, v);
+			}));
+
+		if (this.mt && this.mt.size)
+			fields.push('"mt":' + jsonMap(this.mt, true, function(v) {
+				return JSON.stringify(v == null ? '' : v);
+			}));
+
+		if (this.mu && this.mu.size)
+			fields.push('"mu":' + jsonMap(this.mu, false, function(v) {
+				return jsonInt64(v);
+			}));
+
+		if (this.mi && this.mi.size)
+			fields.push('"mi":' + jsonMap(this.mi, false, function(v) {
+				return '"' + encodeBase64(v == null ? [] : v) + '"';
+			}));
+
+		if (this.mo && this.mo.size)
+			fields.push('"mo":' + jsonMap(this.mo, true, function(v) {
+				return (v == null ? '{}' : v.marshalJSON());
+			}));
+
+		if (this.gap) {
+			var v = this.gap;
+			fields.push('"gap":' + (v ? 'true' : 'false'));
+		}
+
+		if (this.host) {
+			var v = this.host;
+			fields.push('"host":' + JSON.stringify(v == null ? '' : v));
+		}
+
+		if (this.tags && this.tags.length)
+			fields.push('"tags":' + jsonList(this.tags, function(v) {
+				return JSON.stringify(v == null ? '' : v);
+			}));
+
+		if (this.ob != null) {
+			var v = this.ob;
+			fields.push('"ob":' + (v ? 'true' : 'false'));
+		}
+
+		if (this.ou32 != null) {
+			var v = this.ou32;
+			fields.push('"ou32":' + String(v));
+		}
+
+		if (this.oi64 != null) {
+			var v = this.oi64;
+			fields.push('"oi64":' + jsonInt64(v));
+		}
+
+		if (this.of64 != null) {
+			var v = this.of64;
+			fields.push('"of64":' + jsonFloat64(v));
+		}
+
+		if (this.u) {
+			var u = this.u;
+			switch (u.kind) {
+			case 'o':
+			case 'point':
+				break;
+			default:
+				throw new Error('colfer: gen.o.u kind ' + u.kind + ' is not a member');
+			}
+			fields.push('"u":{"kind":' + JSON.stringify(u.kind) + ',"value":' + (u.value == null ? '{}' : u.value.marshalJSON()) + '}');
+		}
+
+		return '{' + fields.join(',') + '}';
+	}
+
+	// Deserializes the object from either a JSON text, or from a value as
+	// returned by JSON.parse, with the mapping of marshalJSON. Fields absent
+	// in the JSON object, or with a null value, remain as is.
+	this.O.prototype.unmarshalJSON = function(json) {
+		var o = jsonObject(typeof json === 'string' ? JSON.parse(json) : json, 'gen.o');
+		for (var name in o) {
+			var v = o[name];
+			if (v === null) continue;
+
+			switch (name) {
+			case 'b':
+				var e = v;
+				this.b = fromJSONBool(e, 'gen.o.b');
+				break;
+			case 'u32':
+				var e = v;
+				this.u32 = fromJSONInt(e, 0, 4294967295, 'gen.o.u32');
+				break;
+			case 'u64':
+				var e = v;
+				this.u64 = fromJSONInt(e, 0, Number.MAX_SAFE_INTEGER, 'gen.o.u64');
+				break;
+			case 'i32':
+				var e = v;
+				this.i32 = fromJSONInt(e, -2147483648, 2147483647, 'gen.o.i32');
+				break;
+			case 'i64':
+				var e = v;
+				this.i64 = fromJSONInt(e, Number.MIN_SAFE_INTEGER, Number.MAX_SAFE_INTEGER, 'gen.o.i64');
+				break;
+			case 'f32':
+				var e = v;
+				this.f32 = Math.fround(fromJSONFloat(e, 'gen.o.f32'));
+				break;
+			case 'f64':
+				var e = v;
+				this.f64 = fromJSONFloat(e, 'gen.o.f64');
+				break;
+			case 't':
+				var t = fromJSONTime(v, 'gen.o.t');
+				this.t = t.date;
+				this.t_ns = t.ns;
+				break;
+			case 's':
+				var e = v;
+				this.s = fromJSONText(e, 'gen.o.s');
+				break;
+			case 'a':
+				var e = v;
+				this.a = decodeBase64(e, 'gen.o.a');
+				break;
+			case 'o':
+				var e = v;
+				this.o = new gen.O().unmarshalJSON(e);
+				break;
+			case 'os':
+				if (!Array.isArray(v)) throw new Error('colfer: gen.o.os needs a JSON array');
+				var a = v.map(function(e) {
+					return new gen.O().unmarshalJSON(e);
+				});
+				this.os = a;
+				break;
+			case 'ss':
+				if (!Array.isArray(v)) throw new Error('colfer: gen.o.ss needs a JSON array');
+				var a = v.map(function(e) {
+					return fromJSONText(e, 'gen.o.ss');
+				});
+				this.ss = a;
+				break;
+			case 'as':
+				if (!Array.isArray(v)) throw new Error('colfer: gen.o.as needs a JSON array');
+				var a = v.map(function(e) {
+					return decodeBase64(e, 'gen.o.as');
+				});
+				this.as = a;
+				break;
+			case 'u8':
+				var e = v;
+				this.u8 = fromJSONInt(e, 0, 255, 'gen.o.u8');
+				break;
+			case 'u16':
+				var e = v;
+				this.u16 = fromJSONInt(e, 0, 65535, 'gen.o.u16');
+				break;
+			case 'f32s':
+				if (!Array.isArray(v)) throw new Error('colfer: gen.o.f32s needs a JSON array');
+				var a = v.map(function(e) {
+					return Math.fround(fromJSONFloat(e, 'gen.o.f32s'));
+				});
+				this.f32s = new Float32Array(a);
+				break;
+			case 'f64s':
+				if (!Array.isArray(v)) throw new Error('colfer: gen.o.f64s needs a JSON array');
+				var a = v.map(function(e) {
+					return fromJSONFloat(e, 'gen.o.f64s');
+				});
+				this.f64s = new Float64Array(a);
+				break;
+			case 'e':
+				var e = v;
+				this.e = fromJSONEnum(gen.Color, e, 0, 255, 'gen.o.e');
+				break;
+			case 'bs':
+				if (!Array.isArray(v)) throw new Error('colfer: gen.o.bs needs a JSON array');
+				var a = v.map(function(e) {
+					return fromJSONBool(e, 'gen.o.bs');
+				});
+				this.bs = a;
+				break;
+			case 'u8s':
+				if (!Array.isArray(v)) throw new Error('colfer: gen.o.u8s needs a JSON array');
+				var a = v.map(function(e) {
+					return fromJSONInt(e, 0, 255, 'gen.o.u8s');
+				});
+				this.u8s = new Uint8Array(a);
+				break;
+			case 'u16s':
+				if (!Array.isArray(v)) throw new Error('colfer: gen.o.u16s needs a JSON array');
+				var a = v.map(function(e) {
+					return fromJSONInt(e, 0, 65535, 'gen.o.u16s');
+				});
+				this.u16s = new Uint16Array(a);
+				break;
+			case 'u32s':
+				if (!Array.isArray(v)) throw new Error('colfer: gen.o.u32s needs a JSON array');
+				var a = v.map(function(e) {
+					return fromJSONInt(e, 0, 4294967295, 'gen.o.u32s');
+				});
+				this.u32s = new Uint32Array(a);
+				break;
+			case 'u64s':
+				if (!Array.isArray(v)) throw new Error('colfer: gen.o.u64s needs a JSON array');
+				var a = v.map(function(e) {
+					return fromJSONInt(e, 0, Number.MAX_SAFE_INTEGER, 'gen.o.u64s');
+				});
+				this.u64s = a;
+				break;
+			case 'i32s':
+				if (!Array.isArray(v)) throw new Error('colfer: gen.o.i32s needs a JSON array');
+				var a = v.map(function(e) {
+					return fromJSONInt(e, -2147483648, 2147483647, 'gen.o.i32s');
+				});
+				this.i32s = new Int32Array(a);
+				break;
+			case 'i64s':
+				if (!Array.isArray(v)) throw new Error('colfer: gen.o.i64s needs a JSON array');
+				var a = v.map(function(e) {
+					return fromJSONInt(e, Number.MIN_SAFE_INTEGER, Number.MAX_SAFE_INTEGER, 'gen.o.i64s');
+				});
+				this.i64s = a;
+				break;
+			case 'es':
+				if (!Array.isArray(v)) throw new Error('colfer: gen.o.es needs a JSON array');
+				var a = v.map(function(e) {
+					return fromJSONEnum(gen.Color, e, 0, 255, 'gen.o.es');
+				});
+				this.es = new Uint8Array(a);
+				break;
+			case 'mt':
+				var m = new Map();
+				v = jsonObject(v, 'gen.o.mt');
+				for (var k in v) {
+					var e = v[k];
+					m.set(k, fromJSONText(e, 'gen.o.mt'));
+				}
+				this.mt = m;
+				break;
+			case 'mu':
+				var m = new Map();
+				v = jsonObject(v, 'gen.o.mu');
+				for (var k in v) {
+					var e = v[k];
+					m.set(fromJSONKey(k, 0, 4294967295, 'gen.o.mu'), fromJSONInt(e, Number.MIN_SAFE_INTEGER, Number.MAX_SAFE_INTEGER, 'gen.o.mu'));
+				}
+				this.mu = m;
+				break;
+			case 'mi':
+				var m = new Map();
+				v = jsonObject(v, 'gen.o.mi');
+				for (var k in v) {
+					var e = v[k];
+					m.set(fromJSONKey(k, Number.MIN_SAFE_INTEGER, Number.MAX_SAFE_INTEGER, 'gen.o.mi'), decodeBase64(e, 'gen.o.mi'));
+				}
+				this.mi = m;
+				break;
+			case 'mo':
+				var m = new Map();
+				v = jsonObject(v, 'gen.o.mo');
+				for (var k in v) {
+					var e = v[k];
+					m.set(k, new gen.O().unmarshalJSON(e));
+				}
+				this.mo = m;
+				break;
+			case 'gap':
+				var e = v;
+				this.gap = fromJSONBool(e, 'gen.o.gap');
+				break;
+			case 'host':
+				var e = v;
+				this.host = fromJSONText(e, 'gen.o.host');
+				break;
+			case 'tags':
+				if (!Array.isArray(v)) throw new Error('colfer: gen.o.tags needs a JSON array');
+				var a = v.map(function(e) {
+					return fromJSONText(e, 'gen.o.tags');
+				});
+				this.tags = a;
+				break;
+			case 'ob':
+				var e = v;
+				this.ob = fromJSONBool(e, 'gen.o.ob');
+				break;
+			case 'ou32':
+				var e = v;
+				this.ou32 = fromJSONInt(e, 0, 4294967295, 'gen.o.ou32');
+				break;
+			case 'oi64':
+				var e = v;
+				this.oi64 = fromJSONInt(e, Number.MIN_SAFE_INTEGER, Number.MAX_SAFE_INTEGER, 'gen.o.oi64');
+				break;
+			case 'of64':
+				var e = v;
+				this.of64 = fromJSONFloat(e, 'gen.o.of64');
+				break;
+			case 'u':
+				var u = jsonObject(v, 'gen.o.u');
+				switch (u.kind) {
+				case 'o':
+					this.u = {kind: u.kind, value: new gen.O().unmarshalJSON(u.value)};
+					break;
+				case 'point':
+					this.u = {kind: u.kind, value: new gen.Point().unmarshalJSON(u.value)};
+					break;
+				default:
+					throw new Error('colfer: gen.o.u kind ' + JSON.stringify(u.kind) + ' is not a member of union gen.pick');
+				}
+				break;
+			default:
+				throw new Error('colfer: struct gen.o has no field ' + JSON.stringify(name));
+			}
+		}
+		return this;
+	}
+
+	// Constructor.
+	// Point is a union member.
+	// When init is provided all enumerable properties are merged into the new object a.k.a. shallow cloning.
+	this.Point = function(init) {
+
+		this.x = 0;
+
+		this.y = 0;
+
+		for (var p in init) this[p] = init[p];
+	}
+
+	// Serializes the object into an Uint8Array.
+	// The optional depthMax overrides the upper limit for the nesting depth.
+	this.Point.prototype.marshal = function(buf, depthMax) {
+		if (depthMax === undefined) depthMax = colferDepthMax;
+		if (depthMax < 1) throw new RangeError('colfer: gen.point exceeds the nesting depth limit; check for reference cycles');
+		if (! buf || !buf.length) buf = new Uint8Array(colferSizeMax);
+		var i = 0;
+		var view = new DataView(buf.buffer);
+
+
+		if (this.x) {
+			if (this.x < 0) {
+				buf[i++] = 0 | 128;
+				if (this.x < -2147483648)
+					throw new Error('colfer: gen/Point field x exceeds 32-bit range');
+				i = encodeVarint(buf, i, -this.x);
+			} else {
+				buf[i++] = 0; 
+				if (this.x > 2147483647)
+					throw new Error('colfer: gen/Point field x exceeds 32-bit range');
+				i = encodeVarint(buf, i, this.x);
+			}
+		}
+
+		if (this.y) {
+			if (this.y < 0) {
+				buf[i++] = 1 | 128;
+				if (this.y < -2147483648)
+					throw new Error('colfer: gen/Point field y exceeds 32-bit range');
+				i = encodeVarint(buf, i, -this.y);
+			} else {
+				buf[i++] = 1; 
+				if (this.y > 2147483647)
+					throw new Error('colfer: gen/Point field y exceeds 32-bit range');
+				i = encodeVarint(buf, i, this.y);
+			}
+		}
+
+
+		buf[i++] = 127;
+		if (i >= colferSizeMax)
+			throw new Error('colfer: gen.point serial size ' + i + ' exceeds ' + colferSizeMax + ' bytes');
+		return buf.subarray(0, i);
+	}
+
+	// Deserializes the object from an Uint8Array and returns the number of bytes read.
+	// The optional depthMax overrides the upper limit for the nesting depth.
+	this.Point.prototype.unmarshal = function(data, depthMax) {
+		if (depthMax === undefined) depthMax = colferDepthMax;
+		if (depthMax < 1) throw new RangeError('colfer: gen.point exceeds the nesting depth limit');
+		if (!data || ! data.length) throw new Error(EOF);
+		var header = data[0];
+		var i = 1;
+		var readHeader = function() {
+			if (i >= data.length) throw new Error(EOF);
+			header = data[i++];
+		}
+
+		var view = new DataView(data.buffer, data.byteOffset, data.byteLength);
+
+		var readVarint = function() {
+			var pos = 0, result = 0;
+			while (pos != 8) {
+				var c = data[i+pos];
+				result += (c & 127) * Math.pow(128, pos);
+				++pos;
+				if (c < 128) {
+					i += pos;
+					if (result > Number.MAX_SAFE_INTEGER) break;
+					return result;
+				}
+				if (pos == data.length) throw new Error(EOF);
+			}
+			return -1;
+		}
+
+		if (header == 0) {
+			var x = readVarint();
+			if (x < 0) throw new Error('colfer: gen/Point field x exceeds Number.MAX_SAFE_INTEGER');
+			this.x = x;
+			readHeader();
+		} else if (header == (0 | 128)) {
+			var x = readVarint();
+			if (x < 0) throw new Error('colfer: gen/Point field x exceeds Number.MAX_SAFE_INTEGER');
+			this.x = -1 * x;
+			readHeader();
+		}
+
+		if (header == 1) {
+			var x = readVarint();
+			if (x < 0) throw new Error('colfer: gen/Point field y exceeds Number.MAX_SAFE_INTEGER');
+			this.y = x;
+			readHeader();
+		} else if (header == (1 | 128)) {
+			var x = readVarint();
+			if (x < 0) throw new Error('colfer: gen/Point field y exceeds Number.MAX_SAFE_INTEGER');
+			this.y = -1 * x;
+			readHeader();
+		}
+
+		if (header != 127) throw new Error('colfer: unknown header at byte ' + (i - 1));
+		if (i > colferSizeMax)
+			throw new Error('colfer: gen.point serial size ' + size + ' exceeds ' + colferSizeMax + ' bytes');
+		return i;
+	}
+
+	// Serializes the object as a JSON text. The JSON object has the fields
+	// present in the serial only, in order of appearance, with the names from
+	// the schema. Integers beyond 2^53 - 1 in magnitude are written as a JSON
+	// string. The mapping matches the generated Go and Java code.
+	this.Point.prototype.marshalJSON = function() {
+		var fields = [];
+
+		if (this.x) {
+			var v = this.x;
+			fields.push('"x":' + String(v));
+		}
+
+		if (this.y) {
+			var v = this.y;
+			fields.push('"y":' + String(v));
+		}
+
+		return '{' + fields.join(',') + '}';
+	}
+
+	// Deserializes the object from either a JSON text, or from a value as
+	// returned by JSON.parse, with the mapping of marshalJSON. Fields absent
+	// in the JSON object, or with a null value, remain as is.
+	this.Point.prototype.unmarshalJSON = function(json) {
+		var o = jsonObject(typeof json === 'string' ? JSON.parse(json) : json, 'gen.point');
+		for (var name in o) {
+			var v = o[name];
+			if (v === null) continue;
+
+			switch (name) {
+			case 'x':
+				var e = v;
+				this.x = fromJSONInt(e, -2147483648, 2147483647, 'gen.point.x');
+				break;
+			case 'y':
+				var e = v;
+				this.y = fromJSONInt(e, -2147483648, 2147483647, 'gen.point.y');
+				break;
+			default:
+				throw new Error('colfer: struct gen.point has no field ' + JSON.stringify(name));
+			}
+		}
+		return this;
+	}
+
+	// private section
+
+	var encodeVarint = function(bytes, i, x) {
+		while (x > 127) {
+			bytes[i++] = (x & 127) | 128;
+			x = Math.floor(x / 128);
+		}
+		bytes[i++] = x & 127;
+		return i;
+	}
+
+	// Encodes with zig-zag encoding without exceeding the integer precision.
+	var encodeZigZag = function(bytes, i, x) {
+		var neg = x < 0 ? 1 : 0;
+		if (neg) x = -x - 1;
+		if (x < 64) {
+			bytes[i++] = x * 2 + neg;
+			return i;
+		}
+		bytes[i++] = (x % 64) * 2 + neg + 128;
+		return encodeVarint(bytes, i, Math.floor(x / 64));
+	}
+
+	// Compares octets in lexicographical order.
+	function compareBytes(a, b) {
+		for (var i = 0; i < a.length && i < b.length; i++)
+			if (a[i] != b[i]) return a[i] - b[i];
+		return a.length - b.length;
+	}
+
+	function decodeInt64(data, i) {
+		var v = 0, j = i + 7, m = 1;
+		if (data[i] & 128) {
+			// two's complement
+			for (var carry = 1; j >= i; --j, m *= 256) {
+				var b = (data[j] ^ 255) + carry;
+				carry = b >> 8;
+				v += (b & 255) * m;
+			}
+			v = -v;
+		} else {
+			for (; j >= i; --j, m *= 256)
+				v += data[j] * m;
+		}
+		return v;
+	}
+
+	// JSON mapping
+
+	function jsonObject(v, what) {
+		if (v == null) return {};
+		if (typeof v !== 'object' || Array.isArray(v))
+			throw new Error('colfer: ' + what + ' needs a JSON object');
+		return v;
+	}
+
+	// Encodes integers beyond the safe range as a string, as JSON readers
+	// lose precision on them.
+	function jsonInt64(x) {
+		var s = String(x);
+		return Number.isSafeInteger(x) ? s : '"' + s + '"';
+	}
+
+	function jsonList(a, f) {
+		var s = [];
+		for (var i = 0; i < a.length; i++) s.push(f(a[i]));
+		return '[' + s.join(',') + ']';
+	}
+
+	// Encodes the entries in serial order.
+	function jsonMap(m, textKeys, f) {
+		var keys = [];
+		m.forEach(function(v, k) { keys.push(k); });
+		if (textKeys) keys.sort(function(a, b) { return compareBytes(encodeUTF8(a), encodeUTF8(b)); });
+		else keys.sort(function(a, b) { return a - b; });
+		var s = [];
+		keys.forEach(function(k) {
+			s.push(JSON.stringify(String(k)) + ':' + f(m.get(k)));
+		});
+		return '{' + s.join(',') + '}';
+	}
+
+	function fromJSONKey(k, min, max, what) {
+		if (!/^-?[0-9]+$/.test(k))
+			throw new Error('colfer: ' + what + ' key ' + JSON.stringify(k) + ' is not an integer');
+		return fromJSONInt(Number(k), min, max, what);
+	}
+
+	// Encodes the schema name of an enumeration value, if any.
+	function jsonEnum(values, x) {
+		for (var name in values)
+			if (values[name] === x) return JSON.stringify(name);
+		return String(x);
+	}
+
+	function fromJSONEnum(values, v, min, max, what) {
+		if (typeof v !== 'string') return fromJSONInt(v, min, max, what);
+		if (!Object.prototype.hasOwnProperty.call(values, v))
+			throw new Error('colfer: ' + what + ' has no enumeration value ' + JSON.stringify(v));
+		return values[v];
+	}
+
+	function fromJSONBool(v, what) {
+		if (typeof v !== 'boolean')
+			throw new Error('colfer: ' + what + ' needs a JSON boolean');
+		return v;
+	}
+
+	// Accepts decimal strings too, as used for 64-bit integers beyond the safe
+	// integer range.
+	function fromJSONInt(v, min, max, what) {
+		if (typeof v === 'string' && /^-?[0-9]+$/.test(v)) v = Number(v);
+		if (typeof v !== 'number' || !Number.isInteger(v) || v < min || v > max)
+			throw new Error('colfer: ' + what + ' needs an integer in range [' + min + ', ' + max + ']; got ' + JSON.stringify(v));
+		return v;
+	}
+
+	function fromJSONText(v, what) {
+		if (v === null) return '';
+		if (typeof v !== 'string')
+			throw new Error('colfer: ' + what + ' needs a JSON string');
+		return v;
+	}
+
+	// Encodes NaN and the infinities as a string, since JSON has no notation
+	// for them. Negative zero is kept.
+	function jsonFloat64(x) {
+		if (Number.isNaN(x)) return '"NaN"';
+		if (x === Infinity) return '"Infinity"';
+		if (x === -Infinity) return '"-Infinity"';
+		if (x === 0 && 1 / x < 0) return '-0';
+		return String(x);
+	}
+
+	// Encodes the shortest decimal which reads back as the same float32.
+	function jsonFloat32(x) {
+		x = Math.fround(x);
+		if (x === 0 || !isFinite(x)) return jsonFloat64(x);
+		for (var p = 1; p <= 9; p++) {
+			var e = x.toExponential(p - 1).split('e');
+			var digits = Number(e[0].replace('.', ''));
+			var exp = Number(e[1]) - (p - 1);
+			// the nearest first, and then its neighbours for the
+			// asymmetric rounding interval at powers of two
+			var candidates = [digits, digits - 1, digits + 1];
+			for (var i = 0; i < candidates.length; i++) {
+				var f = Number(candidates[i] + 'e' + exp);
+				if (Math.fround(f) === x) return String(f);
+			}
+		}
+		return String(x);
+	}
+
+	function fromJSONFloat(v, what) {
+		if (typeof v === 'number') return v;
+		switch (v) {
+		case 'NaN':
+			return NaN;
+		case 'Infinity':
+			return Infinity;
+		case '-Infinity':
+			return -Infinity;
+		}
+		throw new Error('colfer: ' + what + ' needs a JSON number');
+	}
+
+	function pad(x, n) {
+		var s = String(x);
+		while (s.length < n) s = '0' + s;
+		return s;
+	}
+
+	// Encodes RFC 3339 in UTC, with a fraction only when non-zero, without
+	// trailing zeros. Years outside 0000–9999 follow Go's notation.
+	function jsonTime(date, ns) {
+		var ms = date ? date.getTime() : 0;
+		var msf = ms % 1E3;
+		if (msf < 0) msf += 1E3;
+		ns = (ns || 0) + msf * 1E6;
+		var d = new Date(ms - msf);
+
+		var y = d.getUTCFullYear();
+		var s = (y < 0 ? '-' + pad(-y, 4) : pad(y, 4)) + '-' + pad(d.getUTCMonth() + 1, 2) + '-' + pad(d.getUTCDate(), 2);
+		s += 'T' + pad(d.getUTCHours(), 2) + ':' + pad(d.getUTCMinutes(), 2) + ':' + pad(d.getUTCSeconds(), 2);
+		if (ns) s += ('.' + pad(ns, 9)).replace(/0+$/, '');
+		return '"' + s + 'Z"';
+	}
+
+	// Decodes RFC 3339 into a Date and the nanoseconds within its millisecond.
+	function fromJSONTime(v, what) {
+		var m = typeof v === 'string' && /^(-?[0-9]{4,})-([0-9]{2})-([0-9]{2})T([0-9]{2}):([0-9]{2}):([0-9]{2})(?:\.([0-9]{1,9}))?(Z|([+-])([0-9]{2}):([0-9]{2}))$/.exec(v);
+		if (!m) throw new Error('colfer: ' + what + ' needs an RFC 3339 timestamp');
+
+		var d = new Date(0);
+		// no Date.UTC as it maps years 0–99 to 1900–1999
+		d.setUTCFullYear(Number(m[1]), Number(m[2]) - 1, Number(m[3]));
+		d.setUTCHours(Number(m[4]), Number(m[5]), Number(m[6]));
+		var ms = d.getTime();
+		if (m[9]) {
+			var offset = (Number(m[10]) * 60 + Number(m[11])) * 6E4;
+			ms += m[9] === '-' ? offset : -offset;
+		}
+		var ns = m[7] ? Number((m[7] + '00000000').substring(0, 9)) : 0;
+		return {date: new Date(ms + Math.floor(ns / 1E6)), ns: ns % 1E6};
+	}
+
+	var base64 = 'ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/';
+
+	// Encodes standard base64 with padding.
+	function encodeBase64(bytes) {
+		var s = '';
+		for (var i = 0; i < bytes.length; i += 3) {
+			var n = bytes[i] << 16;
+			if (i + 1 < bytes.length) n |= bytes[i + 1] << 8;
+			if (i + 2 < bytes.length) n |= bytes[i + 2];
+			s += base64[n >> 18] + base64[n >> 12 & 63];
+			s += i + 1 < bytes.length ? base64[n >> 6 & 63] : '=';
+			s += i + 2 < bytes.length ? base64[n & 63] : '=';
+		}
+		return s;
+	}
+
+	function decodeBase64(s, what) {
+		if (s === null) return new Uint8Array(0);
+		if (typeof s !== 'string' || s.length % 4 || !/^[A-Za-z0-9+/]*={0,2}$/.test(s))
+			throw new Error('colfer: ' + what + ' needs standard base64 in a JSON string');
+		var size = s.length / 4 * 3;
+		if (s.endsWith('==')) size -= 2;
+		else if (s.endsWith('=')) size--;
+
+		var bytes = new Uint8Array(size);
+		for (var i = 0, j = 0; i < s.length; i += 4) {
+			var n = 0;
+			for (var k = i; k < i + 4; k++)
+				n = n << 6 | (s[k] === '=' ? 0 : base64.indexOf(s[k]));
+			bytes[j++] = n >> 16;
+			if (j < size) bytes[j++] = n >> 8 & 255;
+			if (j < size) bytes[j++] = n & 255;
+		}
+		return bytes;
 	}
 
 	function encodeUTF8(s) {
@@ -5,6 +5,14 @@ QUnit.test('constructor', function(assert) {
 	assert.deepEqual(new gen.O(o), o, 'clone');
 });
 
+QUnit.test('constants', function(assert) {
+	assert.strictEqual(gen.constU64, Number.MAX_SAFE_INTEGER, 'uint64');
+	assert.strictEqual(gen.constI32, -2147483648, 'int32');
+	assert.strictEqual(gen.constText, '"\u03c0"\t??=', 'text');
+	gen.constBool = false;
+	assert.strictEqual(gen.constBool, true, 'read-only');
+});
+
 function newGoldenCases() {
 	return {
 		'7f': {},
@@ -12,6 +20,7 @@ function newGoldenCases() {
 		'01017f': {u32: 1},
 		'01ff017f': {u32: 255},
 		'01ffff037f': {u32: 65535},
+		'01ff7f7f': {u32: 16383},
 		'81ffffffff7f': {u32: 4294967295},
 		'02017f': {u64: 1},
 		'02ff017f': {u64: 255},
@@ -64,7 +73,35 @@ function newGoldenCases() {
 		'8f017f': {u16: 1},
 		'0fffff7f': {u16: 65535},
 		'1002000000003f8000007f': {f32s: new Float32Array([0, 1])},
-		'11014058c000000000007f': {f64s: new Float64Array([99])}
+		'11014058c000000000007f': {f64s: new Float64Array([99])},
+		'12017f': {e: gen.Color.green},
+		'12ff7f': {e: 255},
+		'13030100017f': {bs: [true, false, true]},
+		'140200ff7f': {u8s: new Uint8Array([0, 255])},
+		'150201ffff037f': {u16s: new Uint16Array([1, 65535])},
+		'1601ffffffff0f7f': {u32s: new Uint32Array([4294967295])},
+		'170201ffffffffffffff0f7f': {u64s: [1, Number.MAX_SAFE_INTEGER]},
+		'1501ff7f7f': {u16s: new Uint16Array([16383])},
+		'1602ff7fffff7f7f': {u32s: new Uint32Array([16383, 2097151])},
+		'1702ff7fffffffffffffff0f7f': {u64s: [16383, Number.MAX_SAFE_INTEGER]},
+		'18030102ffffffff0f7f': {i32s: new Int32Array([-1, 1, -2147483648])},
+		'1902fdffffffffffff1ffeffffffffffff1f7f': {i64s: [Number.MIN_SAFE_INTEGER, Number.MAX_SAFE_INTEGER]},
+		'1a0201ff7f': {es: new Uint8Array([gen.Color.green, 255])},
+		'1b02000178016101627f': {mt: new Map([['', 'x'], ['a', 'b']])},
+		'1c020101ffffffff0ffdffffffffffff1f7f': {mu: new Map([[1, -1], [4294967295, Number.MIN_SAFE_INTEGER]])},
+		'1d020101ff00007f': {mi: new Map([[-1, new Uint8Array([255])], [0, new Uint8Array(0)]])},
+		'1e01016f007f7f': {mo: new Map([['o', new gen.O({b: true})]])},
+		'207f': {gap: true},
+		'2104616263647f': {host: 'abcd'},
+		'2202016101627f': {tags: ['a', 'b']},
+		'237f': {ob: true},
+		'a37f': {ob: false},
+		'24007f': {ou32: 0},
+		'25007f': {oi64: 0},
+		'a5017f': {oi64: -1},
+		'2600000000000000007f': {of64: 0},
+		'27007f7f': {u: {kind: 'o', value: new gen.O()}},
+		'270100017f7f': {u: {kind: 'point', value: new gen.Point({x: 1})}}
 	}
 }
 
@@ -91,13 +128,127 @@ QUnit.test('unmarshal', function(assert) {
 		try {
 			var got = new gen.O();
 			got.unmarshal(decodeHex(hex));
-			assert.deepEqual(got, new gen.O(want), desc);
+			assert.deepEqual(mapEntries(got), mapEntries(new gen.O(want)), desc);
 		} catch (err) {
 			assert.equal(err, 'no error', desc);
 		}
 	}
 });
 
+QUnit.test('map order', function(assert) {
+	assert.throws(function() {
+		new gen.O().unmarshal(decodeHex('1b020161000001787f'));
+	}, /out of order/, 'descending keys');
+	assert.throws(function() {
+		new gen.O().unmarshal(decodeHex('1c0201010101027f'));
+	}, /out of order/, 'duplicate keys');
+});
+
+QUnit.test('union', function(assert) {
+	assert.throws(function() {
+		new gen.O().unmarshal(decodeHex('27027f7f'));
+	}, /unknown gen.o.u member/, 'unknown member');
+	assert.throws(function() {
+		new gen.O({u: {kind: 'x', value: null}}).marshal();
+	}, /not a member/, 'unknown kind');
+});
+
+QUnit.test('depth limit', function(assert) {
+	['0a', '0b01', '1e010161', '2700'].forEach(function(prefix) {
+		// nested gen.o one level beyond the colf(1) default of 100
+		var hex = prefix.repeat(100) + '7f'.repeat(101);
+		assert.throws(function() {
+			new gen.O().unmarshal(decodeHex(hex));
+		}, RangeError, prefix + ': unmarshal');
+		assert.equal(new gen.O().unmarshal(decodeHex(hex), 101), hex.length / 2, prefix + ': unmarshal with one more level');
+
+		// each nested marshal allocates a buffer, so keep it shallow
+		hex = prefix.repeat(2) + '7f'.repeat(3);
+		var o = new gen.O();
+		o.unmarshal(decodeHex(hex));
+		assert.throws(function() {
+			o.marshal(null, 2);
+		}, RangeError, prefix + ': marshal');
+		assert.equal(encodeHex(o.marshal(null, 3)), hex, prefix + ': marshal with depth maximum 3');
+	});
+});
+
+QUnit.test('reference cycles', function(assert) {
+	var self = new gen.O();
+	self.o = self;
+	var list = new gen.O();
+	list.os = [new gen.O(), list];
+	var mapped = new gen.O();
+	mapped.mo = new Map([['a', mapped]]);
+	var union = new gen.O();
+	union.u = {kind: 'o', value: union};
+	[self, list, mapped, union].forEach(function(o, i) {
+		assert.throws(function() {
+			o.marshal();
+		}, /reference cycle/, 'case ' + i);
+	});
+
+	// longer cycles run into the depth limit
+	var pair = new gen.O();
+	pair.o = new gen.O();
+	pair.o.o = pair;
+	assert.throws(function() {
+		pair.marshal(null, 4);
+	}, RangeError, 'pair');
+});
+
+// The JSON golden cases are shared with the Go and Java tests. Browsers
+// have no file access.
+if (typeof require === 'function') QUnit.test('JSON golden', function(assert) {
+	var text = require('fs').readFileSync('../testdata/json-golden.txt', 'utf8');
+	var beyond = false;
+	text.split('\n').forEach(function(line) {
+		if (line.startsWith('# beyond')) beyond = true;
+		if (!line || line[0] == '#') return;
+		var i = line.indexOf(' ');
+		var hex = line.substring(0, i), json = line.substring(i + 1);
+		if (beyond) {
+			assert.throws(function() {
+				new gen.O().unmarshal(decodeHex(hex));
+			}, /MAX_SAFE_INTEGER/, hex + ': unmarshal beyond safe range');
+			assert.throws(function() {
+				new gen.O().unmarshalJSON(json);
+			}, /range/, json + ': unmarshalJSON beyond safe range');
+			return;
+		}
+		try {
+			var o = new gen.O();
+			o.unmarshal(decodeHex(hex));
+			assert.equal(o.marshalJSON(), json, hex + ': marshal');
+			assert.equal(new gen.O().unmarshalJSON(json).marshalJSON(), json, json + ': unmarshal');
+		} catch (err) {
+			assert.equal(err, 'no error', hex);
+		}
+	});
+
+	assert.throws(function() {
+		new gen.O().unmarshalJSON('{"nope":true}');
+	}, /nope/, 'unknown field');
+	assert.throws(function() {
+		new gen.O().unmarshalJSON('{"u8":256}');
+	}, /gen.o.u8/, 'range');
+	assert.equal(new gen.O().unmarshalJSON('{"u64":"42","i32":"-7"}').marshalJSON(), '{"u64":42,"i32":-7}', 'integer as a string');
+	assert.throws(function() {
+		new gen.O().unmarshalJSON('{"e":"purple"}');
+	}, /purple/, 'enumeration');
+	assert.throws(function() {
+		new gen.O().unmarshalJSON('{"u":{"kind":"o2"}}');
+	}, /gen.o.u/, 'union');
+});
+
+// MapEntries replaces each Map property with its entries in insertion order,
+// as deepEqual does not inspect the content of a Map.
+function mapEntries(o) {
+	for (var p in o)
+		if (o[p] instanceof Map) o[p] = Array.from(o[p]);
+	return o;
+}
+
 function encodeHex(bytes) {
 	var s = '';
 	if (!bytes) return s;
@@ -0,0 +1,588 @@
+package colfer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EncodeJSON returns the serial of s for a JSON object, conform the mapping
+// of DecodeJSON. The output matches the generated MarshalTo, and the limits
+// apply as with the generated MarshalLen. Absent fields and null values
+// count as zero.
+func EncodeJSON(s *Struct, data []byte) ([]byte, error) {
+	d, err := dynamicFromJSONBytes(s, data)
+	if err != nil {
+		return nil, err
+	}
+	return d.MarshalBinary()
+}
+
+// dynamicFromJSONBytes parses a single JSON object.
+func dynamicFromJSONBytes(s *Struct, data []byte) (*Dynamic, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("colfer: data after JSON object at byte %d", dec.InputOffset())
+	}
+	return dynamicFromJSON(s, v)
+}
+
+func dynamicFromJSON(s *Struct, v interface{}) (*Dynamic, error) {
+	r := NewDynamic(s)
+	if v == nil {
+		return r, nil
+	}
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("colfer: struct %s needs a JSON object", s)
+	}
+
+	for fi, f := range s.Fields {
+		jv, ok := obj[f.Name]
+		if !ok || jv == nil {
+			continue
+		}
+		var err error
+		if r.values[fi], err = fieldFromJSON(f, jv); err != nil {
+			return nil, err
+		}
+	}
+	for name := range obj {
+		var found bool
+		for _, f := range s.Fields {
+			if f.Name == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("colfer: struct %s has no field %q", s, name)
+		}
+	}
+	return r, nil
+}
+
+func fieldFromJSON(f *Field, v interface{}) (interface{}, error) {
+	switch {
+	case f.TypeKey != "":
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("colfer: field %s needs a JSON object", f)
+		}
+		m := make(map[interface{}]interface{}, len(obj))
+		for jk, jv := range obj {
+			k, err := keyFromJSON(f, jk)
+			if err != nil {
+				return nil, err
+			}
+			if m[k], err = elemFromJSON(f, jv); err != nil {
+				return nil, err
+			}
+		}
+		return m, nil
+
+	case f.TypeUnion != nil:
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("colfer: field %s needs a JSON object with a kind and a value", f)
+		}
+		kind, _ := obj["kind"].(string)
+		for _, m := range f.TypeUnion.Members {
+			if m.Name == kind {
+				return dynamicFromJSON(m.Struct, obj["value"])
+			}
+		}
+		return nil, fmt.Errorf("colfer: field %s kind %q is not a member of union %s", f, kind, f.TypeUnion)
+
+	case f.TypeList:
+		array, ok := v.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("colfer: field %s needs a JSON array", f)
+		}
+		a := make([]interface{}, len(array))
+		for i, jv := range array {
+			var err error
+			if a[i], err = elemFromJSON(f, jv); err != nil {
+				return nil, err
+			}
+		}
+		return a, nil
+	}
+
+	return elemFromJSON(f, v)
+}
+
+// elemFromJSON returns a single value, list element or map value of f.
+func elemFromJSON(f *Field, v interface{}) (interface{}, error) {
+	if f.TypeRef != nil {
+		// null entries in lists and maps serialize as empty
+		return dynamicFromJSON(f.TypeRef, v)
+	}
+
+	switch f.Type {
+	case "bool":
+		if b, ok := v.(bool); ok {
+			return b, nil
+		}
+
+	case "uint8", "uint16", "uint32", "uint64", "int32", "int64":
+		s, ok := v.(string)
+		if ok && f.TypeEnum != nil {
+			for _, ev := range f.TypeEnum.Values {
+				if ev.Name == s {
+					return integerOf(f.Type, ev.Value), nil
+				}
+			}
+			return nil, fmt.Errorf("colfer: field %s value %q is not in enumeration %s", f, s, f.TypeEnum)
+		}
+		if ok {
+			return parseInteger(f, f.Type, s)
+		}
+		if n, ok := v.(json.Number); ok {
+			return parseInteger(f, f.Type, n.String())
+		}
+
+	case "float32", "float64":
+		bitSize := 64
+		if f.Type == "float32" {
+			bitSize = 32
+		}
+		var x float64
+		switch v := v.(type) {
+		case json.Number:
+			var err error
+			x, err = strconv.ParseFloat(v.String(), bitSize)
+			if err != nil {
+				return nil, fmt.Errorf("colfer: field %s: %s", f, err)
+			}
+		case string:
+			switch v {
+			case "NaN":
+				x = math.NaN()
+			case "Infinity":
+				x = math.Inf(1)
+			case "-Infinity":
+				x = math.Inf(-1)
+			default:
+				return nil, fmt.Errorf("colfer: field %s needs a JSON number; got %q", f, v)
+			}
+		default:
+			return nil, fmt.Errorf("colfer: field %s needs a JSON number", f)
+		}
+		if bitSize == 32 {
+			return float32(x), nil
+		}
+		return x, nil
+
+	case "timestamp":
+		if s, ok := v.(string); ok {
+			t, err := parseTimestamp(s)
+			if err != nil {
+				return nil, fmt.Errorf("colfer: field %s: %s", f, err)
+			}
+			return t, nil
+		}
+
+	case "text":
+		if s, ok := v.(string); ok {
+			return s, nil
+		}
+
+	case "binary":
+		if s, ok := v.(string); ok {
+			b, err := base64.StdEncoding.DecodeString(s)
+			if err != nil {
+				return nil, fmt.Errorf("colfer: field %s: %s", f, err)
+			}
+			return b, nil
+		}
+	}
+	return nil, fmt.Errorf("colfer: field %s has no JSON mapping for %T", f, v)
+}
+
+// parseTimestamp reads RFC 3339, including the years beyond the 4-digit
+// range, as written by time.Time.Format.
+func parseTimestamp(s string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err == nil {
+		return t, nil
+	}
+
+	// year precedes "-MM-DDT"
+	i := strings.IndexByte(s, 'T') - 6
+	if i < 1 {
+		return t, err
+	}
+	year, yearErr := strconv.Atoi(s[:i])
+	// leap year for February 29th
+	u, uErr := time.Parse(time.RFC3339Nano, "2000"+s[i:])
+	if yearErr != nil || uErr != nil {
+		return t, err
+	}
+	return time.Date(year, u.Month(), u.Day(), u.Hour(), u.Minute(), u.Second(), u.Nanosecond(), u.Location()), nil
+}
+
+// keyFromJSON returns the map key of f for a JSON member name.
+func keyFromJSON(f *Field, s string) (interface{}, error) {
+	if f.TypeKey == "text" {
+		return s, nil
+	}
+	return parseInteger(f, f.TypeKey, s)
+}
+
+// parseInteger returns the value of decimal s as the Go type of typ.
+func parseInteger(f *Field, typ, s string) (interface{}, error) {
+	bitSize := map[string]int{"uint8": 8, "uint16": 16, "uint32": 32, "uint64": 64, "int32": 32, "int64": 64}[typ]
+	if typ[0] == 'i' {
+		x, err := strconv.ParseInt(s, 10, bitSize)
+		if err != nil {
+			return nil, fmt.Errorf("colfer: field %s: %s", f, err)
+		}
+		if typ == "int32" {
+			return int32(x), nil
+		}
+		return x, nil
+	}
+	x, err := strconv.ParseUint(s, 10, bitSize)
+	if err != nil {
+		return nil, fmt.Errorf("colfer: field %s: %s", f, err)
+	}
+	return integerOf(typ, x), nil
+}
+
+// integerOf returns x as the Go type of typ.
+func integerOf(typ string, x uint64) interface{} {
+	switch typ {
+	case "uint8":
+		return uint8(x)
+	case "uint16":
+		return uint16(x)
+	case "uint32":
+		return uint32(x)
+	case "int32":
+		return int32(x)
+	case "int64":
+		return int64(x)
+	}
+	return x
+}
+
+// encoder produces serials conform the generated MarshalTo methods.
+type encoder struct {
+	buf    bytes.Buffer
+	limits limitSet
+	// depthMax is the number of struct levels left to enter.
+	depthMax int
+}
+
+// varint writes an unsigned LEB128 where the ninth byte, if any, holds the
+// 8 most significant bits.
+func (e *encoder) varint(x uint64) {
+	for n := 0; x >= 0x80 && n < 8; n++ {
+		e.buf.WriteByte(byte(x | 0x80))
+		x >>= 7
+	}
+	e.buf.WriteByte(byte(x))
+}
+
+func (e *encoder) uint16(x uint16) {
+	e.buf.WriteByte(byte(x >> 8))
+	e.buf.WriteByte(byte(x))
+}
+
+func (e *encoder) uint32(x uint32) {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], x)
+	e.buf.Write(b[:])
+}
+
+func (e *encoder) uint64(x uint64) {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], x)
+	e.buf.Write(b[:])
+}
+
+func (e *encoder) writeStruct(r *Dynamic) error {
+	l, err := e.limits.pkg(r.Struct.Pkg)
+	if err != nil {
+		return err
+	}
+	if e.depthMax < 1 {
+		return fmt.Errorf("colfer: struct %s exceeds the nesting depth limit", r.Struct)
+	}
+
+	start := e.buf.Len()
+	e.depthMax--
+	for fi, f := range r.Struct.Fields {
+		if v := r.values[fi]; v != nil {
+			if err := e.writeField(f, v); err != nil {
+				return err
+			}
+		}
+	}
+	e.depthMax++
+	e.buf.WriteByte(0x7f)
+
+	if e.buf.Len()-start > l.sizeMax {
+		return fmt.Errorf("colfer: struct %s size exceeds %d bytes", r.Struct, l.sizeMax)
+	}
+	return nil
+}
+
+func (e *encoder) writeField(f *Field, v interface{}) error {
+	l, err := e.limits.field(f)
+	if err != nil {
+		return err
+	}
+	index := byte(f.Index)
+
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		if len(v) == 0 {
+			return nil
+		}
+		if len(v) > l.listMax {
+			return fmt.Errorf("colfer: field %s exceeds %d elements", f, l.listMax)
+		}
+		e.buf.WriteByte(index)
+		e.varint(uint64(len(v)))
+
+		keys := make([]interface{}, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool { return keyLess(keys[i], keys[j]) })
+		for _, k := range keys {
+			switch k := k.(type) {
+			case string:
+				if len(k) > l.sizeMax {
+					return fmt.Errorf("colfer: field %s key exceeds %d bytes", f, l.sizeMax)
+				}
+				e.varint(uint64(len(k)))
+				e.buf.WriteString(k)
+			case uint8:
+				e.buf.WriteByte(k)
+			default:
+				e.varint(zigZag(k))
+			}
+			if err := e.writeElem(f, v[k], l); err != nil {
+				return err
+			}
+		}
+
+	case []interface{}:
+		if len(v) == 0 {
+			return nil
+		}
+		if len(v) > l.listMax {
+			return fmt.Errorf("colfer: field %s exceeds %d elements", f, l.listMax)
+		}
+		e.buf.WriteByte(index)
+		e.varint(uint64(len(v)))
+		for _, elem := range v {
+			if err := e.writeElem(f, elem, l); err != nil {
+				return err
+			}
+		}
+
+	case *Dynamic:
+		e.buf.WriteByte(index)
+		if u := f.TypeUnion; u != nil {
+			var member *UnionMember
+			for _, m := range u.Members {
+				if m.Struct == v.Struct {
+					member = m
+				}
+			}
+			if member == nil {
+				return fmt.Errorf("colfer: field %s struct %s is not a member of union %s", f, v.Struct, u)
+			}
+			e.buf.WriteByte(byte(member.Index))
+		}
+		return e.writeStruct(v)
+
+	case bool:
+		switch {
+		case v:
+			e.buf.WriteByte(index)
+		case f.Optional:
+			e.buf.WriteByte(index | 0x80)
+		}
+
+	case uint8:
+		if v != 0 || f.Optional {
+			e.buf.WriteByte(index)
+			e.buf.WriteByte(v)
+		}
+
+	case uint16:
+		if v >= 1<<8 {
+			e.buf.WriteByte(index)
+			e.uint16(v)
+		} else if v != 0 || f.Optional {
+			e.buf.WriteByte(index | 0x80)
+			e.buf.WriteByte(byte(v))
+		}
+
+	case uint32:
+		if v >= 1<<21 {
+			e.buf.WriteByte(index | 0x80)
+			e.uint32(v)
+		} else if v != 0 || f.Optional {
+			e.buf.WriteByte(index)
+			e.varint(uint64(v))
+		}
+
+	case uint64:
+		if v >= 1<<49 {
+			e.buf.WriteByte(index | 0x80)
+			e.uint64(v)
+		} else if v != 0 || f.Optional {
+			e.buf.WriteByte(index)
+			e.varint(v)
+		}
+
+	case int32:
+		if v != 0 || f.Optional {
+			x := uint32(v)
+			if v >= 0 {
+				e.buf.WriteByte(index)
+			} else {
+				x = ^x + 1
+				e.buf.WriteByte(index | 0x80)
+			}
+			e.varint(uint64(x))
+		}
+
+	case int64:
+		if v != 0 || f.Optional {
+			x := uint64(v)
+			if v >= 0 {
+				e.buf.WriteByte(index)
+			} else {
+				x = ^x + 1
+				e.buf.WriteByte(index | 0x80)
+			}
+			e.varint(x)
+		}
+
+	case float32:
+		if v != 0 || f.Optional {
+			e.buf.WriteByte(index)
+			e.uint32(math.Float32bits(v))
+		}
+
+	case float64:
+		if v != 0 || f.Optional {
+			e.buf.WriteByte(index)
+			e.uint64(math.Float64bits(v))
+		}
+
+	case time.Time:
+		if !v.IsZero() {
+			if s := uint64(v.Unix()); s < 1<<32 {
+				e.buf.WriteByte(index)
+				e.uint32(uint32(s))
+			} else {
+				e.buf.WriteByte(index | 0x80)
+				e.uint64(s)
+			}
+			e.uint32(uint32(v.Nanosecond()))
+		}
+
+	case string:
+		return e.writeData(f, index, []byte(v), l)
+	case []byte:
+		return e.writeData(f, index, v, l)
+
+	default:
+		return fmt.Errorf("colfer: field %s has unsupported value type %T", f, v)
+	}
+	return nil
+}
+
+// writeData writes a text or binary field.
+func (e *encoder) writeData(f *Field, index byte, b []byte, l limits) error {
+	if len(b) == 0 {
+		return nil
+	}
+	if len(b) > l.sizeMax {
+		return fmt.Errorf("colfer: field %s exceeds %d bytes", f, l.sizeMax)
+	}
+	e.buf.WriteByte(index)
+	e.varint(uint64(len(b)))
+	e.buf.Write(b)
+	return nil
+}
+
+// writeElem writes a list element or map value of f.
+func (e *encoder) writeElem(f *Field, v interface{}, l limits) error {
+	switch v := v.(type) {
+	case *Dynamic:
+		return e.writeStruct(v)
+	case nil:
+		if f.TypeRef == nil {
+			return fmt.Errorf("colfer: field %s has a nil element", f)
+		}
+		// serializes as empty, like the generated code
+		e.buf.WriteByte(0x7f)
+	case bool:
+		if v {
+			e.buf.WriteByte(1)
+		} else {
+			e.buf.WriteByte(0)
+		}
+	case uint8:
+		e.buf.WriteByte(v)
+	case uint16, uint32, uint64, int32, int64:
+		e.varint(zigZag(v))
+	case float32:
+		e.uint32(math.Float32bits(v))
+	case float64:
+		e.uint64(math.Float64bits(v))
+	case string:
+		if len(v) > l.sizeMax {
+			return fmt.Errorf("colfer: field %s exceeds %d bytes", f, l.sizeMax)
+		}
+		e.varint(uint64(len(v)))
+		e.buf.WriteString(v)
+	case []byte:
+		if len(v) > l.sizeMax {
+			return fmt.Errorf("colfer: field %s exceeds %d bytes", f, l.sizeMax)
+		}
+		e.varint(uint64(len(v)))
+		e.buf.Write(v)
+	default:
+		return fmt.Errorf("colfer: field %s has unsupported element type %T", f, v)
+	}
+	return nil
+}
+
+// zigZag returns the varint value of an integer in lists and maps.
+func zigZag(v interface{}) uint64 {
+	switch v := v.(type) {
+	case uint16:
+		return uint64(v)
+	case uint32:
+		return uint64(v)
+	case uint64:
+		return v
+	case int32:
+		return uint64(uint32(v<<1) ^ uint32(v>>31))
+	case int64:
+		return uint64(v<<1) ^ uint64(v>>63)
+	}
+	return 0
+}
@@ -0,0 +1,65 @@
+package colfer
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestEncodeJSON(t *testing.T) {
+	s := schemaO(t)
+
+	// The JSON encodes back to its serial, except for NaN payloads, which
+	// have no JSON notation.
+	for _, gold := range jsonGoldenCases(t) {
+		data, err := EncodeJSON(s, []byte(gold.json))
+		if err != nil {
+			t.Errorf("%s: got error %q", gold.json, err)
+			continue
+		}
+		if !strings.Contains(gold.json, `"NaN"`) && hex.EncodeToString(data) != gold.serial {
+			t.Errorf("%s: got serial 0x%x, want 0x%s", gold.json, data, gold.serial)
+		}
+		var buf bytes.Buffer
+		if _, err := DecodeJSON(&buf, s, data); err != nil {
+			t.Errorf("%s: decode got error %q", gold.json, err)
+		} else if got := buf.String(); got != gold.json {
+			t.Errorf("%s: got JSON %s after encode", gold.json, got)
+		}
+	}
+
+	for _, gold := range []jsonGolden{
+		{"7f", `{"b":false,"u32":0,"s":"","os":[],"mt":{},"o":null}`},
+		{"a324007f", `{"ob":false,"ou32":0}`},
+		{"0b017f7f", `{"os":[null]}`},
+		{"1a0202007f", `{"es":["blue",0]}`},
+		{"1c020101ffffffff0ffeffffffffffffffff7f", `{"mu":{"4294967295":9223372036854775807,"1":-1}}`},
+		{"06fff00000000000007f", `{"f64":"-Infinity"}`},
+		{"82ffffffffffffffff83017f", `{"u64":"18446744073709551615","i32":"-1"}`},
+	} {
+		got, err := EncodeJSON(s, []byte(gold.json))
+		if err != nil {
+			t.Errorf("%s: got error %q", gold.json, err)
+		} else if hex.EncodeToString(got) != gold.serial {
+			t.Errorf("%s: got serial 0x%x, want 0x%s", gold.json, got, gold.serial)
+		}
+	}
+
+	for _, gold := range []struct{ json, want string }{
+		{`{"host":"abcde"}`, "gen.o.host"},
+		{`{"tags":["a","b","c"]}`, "gen.o.tags"},
+		{`{"tags":["ab"]}`, "gen.o.tags"},
+		{`{"nope":true}`, "nope"},
+		{`{"u8":256}`, "gen.o.u8"},
+		{`{"e":"purple"}`, "gen.o.e"},
+		{`{"u":{"kind":"o2"}}`, "gen.o.u"},
+		{`{"b":1}`, "gen.o.b"},
+		{`{} {}`, "data after"},
+	} {
+		_, err := EncodeJSON(s, []byte(gold.json))
+		if err == nil || !strings.Contains(err.Error(), gold.want) {
+			t.Errorf("%s: got error %v, want mention of %s", gold.json, err, gold.want)
+		}
+	}
+}
@@ -2,32 +2,97 @@ package colfer
 
 import (
 	"bytes"
+	"go/constant"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"text/template"
 )
 
 // GenerateGo writes the code into file "Colfer.go".
 func GenerateGo(basedir string, packages Packages) error {
+	if err := lintGo(packages).Err(); err != nil {
+		return err
+	}
+
 	t := template.New("go-code")
 	template.Must(t.Parse(goCode))
 	template.Must(t.New("marshal-field").Parse(goMarshalField))
 	template.Must(t.New("marshal-field-len").Parse(goMarshalFieldLen))
 	template.Must(t.New("unmarshal-field").Parse(goUnmarshalField))
 	template.Must(t.New("unmarshal-varint").Parse(goUnmarshalVarint))
+	template.Must(t.New("unmarshal-varint64").Parse(goUnmarshalVarint64))
+	template.Must(t.New("limits-arg").Parse(goLimitsArg))
+	template.Must(t.New("view-limits-arg").Parse(goViewLimitsArg))
+	template.Must(t.New("marshal-map").Parse(goMarshalMap))
+	template.Must(t.New("marshal-map-len").Parse(goMarshalMapLen))
+	template.Must(t.New("unmarshal-map").Parse(goUnmarshalMap))
+	template.Must(t.New("marshal-optional").Parse(goMarshalOptional))
+	template.Must(t.New("marshal-optional-len").Parse(goMarshalOptionalLen))
+	template.Must(t.New("marshal-union").Parse(goMarshalUnion))
+	template.Must(t.New("marshal-union-len").Parse(goMarshalUnionLen))
+	template.Must(t.New("unmarshal-union").Parse(goUnmarshalUnion))
+	template.Must(t.New("view-index").Parse(goViewIndex))
+	template.Must(t.New("view-get").Parse(goViewGet))
+	template.Must(t.New("view-map-key").Parse(goViewMapKey))
+	template.Must(t.New("mask-add").Parse(goMaskAdd))
+	template.Must(t.New("mask-field").Parse(goMaskField))
+	template.Must(t.New("equal-field").Parse(goEqualField))
+	template.Must(t.New("equal-elem").Parse(goEqualElem))
+	template.Must(t.New("clone-field").Parse(goCloneField))
+	template.Must(t.New("json-marshal-field").Parse(goJSONMarshalField))
+	template.Must(t.New("json-value").Parse(goJSONValue))
+	template.Must(t.New("json-unmarshal-field").Parse(goJSONUnmarshalField))
+	template.Must(t.New("json-decode").Parse(goJSONDecode))
 
 	for _, p := range packages {
 		p.NameNative = p.Name[strings.LastIndexByte(p.Name, '/')+1:]
 	}
 
 	for _, p := range packages {
+		for _, e := range p.Enums {
+			e.TypeNative = e.Type
+		}
+
+		for _, c := range p.Consts {
+			switch c.Type {
+			case "text":
+				c.TypeNative = "string"
+				c.ValueNative = strconv.Quote(constant.StringVal(c.Value))
+			case "float32":
+				c.TypeNative = c.Type
+				c.ValueNative = floatLiteral(c.Value, 32)
+			case "float64":
+				c.TypeNative = c.Type
+				c.ValueNative = floatLiteral(c.Value, 64)
+			default:
+				c.TypeNative = c.Type
+				c.ValueNative = c.Value.ExactString()
+			}
+		}
+
 		for _, s := range p.Structs {
 			for _, f := range s.Fields {
+				f.TypeKeyNative = f.TypeKey
+				if f.TypeKey == "text" {
+					f.TypeKeyNative = "string"
+				}
+
 				switch f.Type {
 				default:
-					if f.TypeRef == nil {
+					if f.TypeEnum != nil {
+						f.TypeNative = f.TypeEnum.NameTitle()
+						if f.TypeEnum.Pkg != p {
+							f.TypeNative = f.TypeEnum.Pkg.NameNative + "." + f.TypeNative
+						}
+					} else if f.TypeUnion != nil {
+						f.TypeNative = f.TypeUnion.NameTitle()
+						if f.TypeUnion.Pkg != p {
+							f.TypeNative = f.TypeUnion.Pkg.NameNative + "." + f.TypeNative
+						}
+					} else if f.TypeRef == nil {
 						f.TypeNative = f.Type
 					} else {
 						f.TypeNative = f.TypeRef.NameTitle()
@@ -74,15 +139,38 @@ package {{.NameNative}}
 // The compiler used schema file {{.SchemaFileList}}.
 
 import (
+{{- if .HasBinary}}
+	"encoding/base64"
+{{- end}}
 	"encoding/binary"
+{{- if or .Structs .Enums}}
+	"encoding/json"
+{{- end}}
 	"fmt"
+{{- if .Structs}}
 	"io"
+{{- end}}
 {{- if .HasFloat}}
 	"math"
 {{- end}}
+{{- if .HasMap}}
+	"sort"
+{{- end}}
+{{- if or .Structs .Enums}}
+	"strconv"
+{{- end}}
+{{- if .Structs}}
+	"strings"
+{{- end}}
 {{- if .HasTimestamp}}
 	"time"
 {{- end}}
+{{- if .Structs}}
+	"unicode/utf8"
+{{- end}}
+{{- if and .Structs .ZeroCopy}}
+	"unsafe"
+{{- end}}
 {{- range .Refs}}
 	"{{.Name}}"
 {{- end}}
@@ -92,20 +180,58 @@ var intconv = binary.BigEndian
 
 // Colfer configuration attributes
 var (
-	// ColferSizeMax is the upper limit for serial byte sizes.
+	// ColferSizeMax is the upper limit for serial byte sizes. It is the
+	// SizeMax of ColferDefaults.
 	ColferSizeMax = {{.SizeMax}}
 {{- if .HasList}}
 	// ColferListMax is the upper limit for the number of elements in a list.
+	// It is the ListMax of ColferDefaults.
 	ColferListMax = {{.ListMax}}
 {{- end}}
+	// ColferDepthMax is the upper limit for the nesting depth of data
+	// structures. It is the DepthMax of ColferDefaults.
+	ColferDepthMax = {{.DepthMax}}
 )
 
+// ColferLimits has the upper boundaries for marshalling and unmarshalling.
+// The limits apply to nested data structures too, including those from other
+// packages.
+type ColferLimits struct {
+	// SizeMax is the upper limit for serial byte sizes.
+	SizeMax int
+	// ListMax is the upper limit for the number of elements in a list or in
+	// a map.
+	ListMax int
+	// DepthMax is the upper limit for the nesting depth of data structures,
+	// with one for a struct without any nested values.
+	DepthMax int
+}
+
+// ColferDefaults returns the limits which apply when none are given. A
+// change to ColferSizeMax, ColferListMax or ColferDepthMax races with any
+// (un)marshalling in progress. The methods with explicit limits, like
+// UnmarshalWith, need no such change.
+func ColferDefaults() ColferLimits {
+	return ColferLimits{
+		SizeMax:  ColferSizeMax,
+		ListMax:  {{if .HasList}}ColferListMax{{else}}{{.ListMax}}{{end}},
+		DepthMax: ColferDepthMax,
+	}
+}
+
 // ColferMax signals an upper limit breach.
 type ColferMax string
 
 // Error honors the error interface.
 func (m ColferMax) Error() string { return string(m) }
 
+// ColferDepth signals a breach of the nesting depth limit. Marshalling a
+// reference cycle breaches the limit too.
+type ColferDepth string
+
+// Error honors the error interface.
+func (d ColferDepth) Error() string { return string(d) }
+
 // ColferError signals a data mismatch as as a byte index.
 type ColferError int
 
@@ -121,42 +247,519 @@ type ColferTail int
 func (i ColferTail) Error() string {
 	return fmt.Sprintf("colfer: data continuation at byte %d", i)
 }
-{{range .Structs}}
+{{- if and .Structs .ZeroCopy}}
+
+// colferString returns the bytes as a string without a copy. The bytes must
+// not change for as long as the string is in use.
+func colferString(b []byte) string {
+	return *(*string)(unsafe.Pointer(&b))
+}
+{{- end}}
+{{- if .Structs}}
+
+// colferVarint decodes the varint at data[i] from a validated serial, and
+// it returns the value with the index of the first byte after the varint.
+// The ninth byte has all 8 bits in use when capped, as with 64-bit integers.
+func colferVarint(data []byte, i int, capped bool) (uint64, int) {
+	x := uint64(data[i])
+	i++
+
+	if x >= 0x80 {
+		x &= 0x7f
+		for shift := uint(7); ; shift += 7 {
+			b := uint64(data[i])
+			i++
+
+			if b < 0x80 || capped && shift == 56 {
+				x |= b << shift
+				break
+			}
+			x |= (b & 0x7f) << shift
+		}
+	}
+	return x, i
+}
+
+// colferAppendJSONString appends s as a JSON string. Only quotation marks,
+// backslashes and control characters are escaped, like ECMAScript's
+// JSON.stringify does. Invalid UTF-8 becomes U+FFFD.
+func colferAppendJSONString(buf []byte, s string) []byte {
+	const hex = "0123456789abcdef"
+	buf = append(buf, '"')
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c >= utf8.RuneSelf {
+			r, size := utf8.DecodeRuneInString(s[i:])
+			if r == utf8.RuneError && size == 1 {
+				buf = append(buf, "\uFFFD"...)
+			} else {
+				buf = append(buf, s[i:i+size]...)
+			}
+			i += size
+			continue
+		}
+
+		switch c {
+		case '"', '\\':
+			buf = append(buf, '\\', c)
+		case '\b':
+			buf = append(buf, '\\', 'b')
+		case '\f':
+			buf = append(buf, '\\', 'f')
+		case '\n':
+			buf = append(buf, '\\', 'n')
+		case '\r':
+			buf = append(buf, '\\', 'r')
+		case '\t':
+			buf = append(buf, '\\', 't')
+		default:
+			if c < 0x20 {
+				buf = append(buf, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xf])
+			} else {
+				buf = append(buf, c)
+			}
+		}
+		i++
+	}
+	return append(buf, '"')
+}
+
+// colferAppendJSONUint appends x as a JSON number, or as a JSON string when x
+// exceeds the safe integer range of ECMAScript, which ends at 2^53 - 1.
+func colferAppendJSONUint(buf []byte, x uint64) []byte {
+	if x <= 1<<53-1 {
+		return strconv.AppendUint(buf, x, 10)
+	}
+	buf = strconv.AppendUint(append(buf, '"'), x, 10)
+	return append(buf, '"')
+}
+
+// colferAppendJSONInt appends x as a JSON number, or as a JSON string when x
+// exceeds the safe integer range of ECMAScript, which is 2^53 - 1 at most in
+// magnitude.
+func colferAppendJSONInt(buf []byte, x int64) []byte {
+	if x <= 1<<53-1 && x >= -(1<<53-1) {
+		return strconv.AppendInt(buf, x, 10)
+	}
+	buf = strconv.AppendInt(append(buf, '"'), x, 10)
+	return append(buf, '"')
+}
+
+// colferParseJSONUint reads an unsigned integer from a JSON number, or from a
+// JSON string with a number.
+func colferParseJSONUint(data []byte, bitSize int) (uint64, error) {
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(string(n), 10, bitSize)
+}
+
+// colferParseJSONInt reads a signed integer from a JSON number, or from a
+// JSON string with a number.
+func colferParseJSONInt(data []byte, bitSize int) (int64, error) {
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(string(n), 10, bitSize)
+}
+{{- if .HasFloat}}
+
+// colferAppendJSONFloat appends the shortest decimal which reads back as f
+// bit-exactly, in the notation of ECMAScript's Number toString. NaN and the
+// infinities become a JSON string, since JSON has no notation for them.
+func colferAppendJSONFloat(buf []byte, f float64, bitSize int) []byte {
+	switch {
+	case math.IsNaN(f):
+		return append(buf, "\"NaN\""...)
+	case math.IsInf(f, 1):
+		return append(buf, "\"Infinity\""...)
+	case math.IsInf(f, -1):
+		return append(buf, "\"-Infinity\""...)
+	}
+
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 {
+		if bitSize == 64 && (abs < 1e-6 || abs >= 1e21) || bitSize == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+	buf = strconv.AppendFloat(buf, f, format, -1, bitSize)
+	// exponent without leading zeros, as in "1e-7"
+	if n := len(buf); format == 'e' && buf[n-3] == '-' && buf[n-2] == '0' {
+		buf[n-2] = buf[n-1]
+		buf = buf[:n-1]
+	}
+	return buf
+}
+
+// colferParseJSONFloat reads a JSON number, or a JSON string with "NaN",
+// "Infinity" or "-Infinity".
+func colferParseJSONFloat(data []byte, bitSize int) (float64, error) {
+	var s string
+	if json.Unmarshal(data, &s) == nil {
+		switch s {
+		case "NaN":
+			return math.NaN(), nil
+		case "Infinity":
+			return math.Inf(1), nil
+		case "-Infinity":
+			return math.Inf(-1), nil
+		}
+		return 0, fmt.Errorf("%q is not a number", s)
+	}
+
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(string(n), bitSize)
+}
+{{- end}}
+{{- if .HasTimestamp}}
+
+// colferAppendJSONTime appends t as an RFC 3339 JSON string in UTC.
+func colferAppendJSONTime(buf []byte, t time.Time) []byte {
+	buf = append(buf, '"')
+	buf = t.UTC().AppendFormat(buf, time.RFC3339Nano)
+	return append(buf, '"')
+}
+
+// colferParseJSONTime reads an RFC 3339 JSON string, including the years
+// beyond the 4-digit range, as written by colferAppendJSONTime.
+func colferParseJSONTime(data []byte) (time.Time, error) {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return time.Time{}, err
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		// year precedes "-MM-DDT"
+		i := strings.IndexByte(s, 'T') - 6
+		if i < 1 {
+			return t, err
+		}
+		year, yearErr := strconv.Atoi(s[:i])
+		// leap year for February 29th
+		u, uErr := time.Parse(time.RFC3339Nano, "2000"+s[i:])
+		if yearErr != nil || uErr != nil {
+			return t, err
+		}
+		t = time.Date(year, u.Month(), u.Day(), u.Hour(), u.Minute(), u.Second(), u.Nanosecond(), u.Location())
+	}
+	return t.UTC(), nil
+}
+{{- end}}
+{{- if .HasBinary}}
+
+// colferAppendJSONBinary appends b as a JSON string in standard base64
+// encoding, with padding.
+func colferAppendJSONBinary(buf, b []byte) []byte {
+	buf = append(buf, '"')
+	n := len(buf)
+	buf = append(buf, make([]byte, base64.StdEncoding.EncodedLen(len(b)))...)
+	base64.StdEncoding.Encode(buf[n:], b)
+	return append(buf, '"')
+}
+{{- end}}
+
+// ColferDecoder reads serials from an input stream.
+type ColferDecoder struct {
+	r      io.Reader
+	limits ColferLimits
+	// buf is the read buffer.
+	buf []byte
+	// offset is the index of the first data byte in buf.
+	offset int
+	// i is the index of the data end (exclusive) in buf.
+	i int
+}
+
+// NewColferDecoder returns a new decoder which reads from r, with the
+// ColferDefaults at the time of the call.
+func NewColferDecoder(r io.Reader) *ColferDecoder {
+	return NewColferDecoderWith(r, ColferDefaults())
+}
+
+// NewColferDecoderWith returns a new decoder which reads from r. The read
+// buffer grows on demand, limited by limits.SizeMax.
+func NewColferDecoderWith(r io.Reader, limits ColferLimits) *ColferDecoder {
+	size := 2048
+	if limits.SizeMax < size {
+		size = limits.SizeMax
+	}
+	return &ColferDecoder{r: r, limits: limits, buf: make([]byte, size)}
+}
+
+// Decode reads the next serial into o. The error return is io.EOF when the
+// stream ends before the serial starts, and io.ErrUnexpectedEOF when the
+// stream ends within the serial. Other error return options come from the
+// Unmarshal method of o, or from the input stream. The limits of d apply to
+// o when it has an UnmarshalWith method with ColferLimits from this package.
+// A serial which does not fit in SizeMax bytes gets a ColferMax error.
+{{- if .ZeroCopy}}
+// The read buffer is reused, so any binary and text values in o are valid
+// until the next call to Decode only.
+{{- end}}
+func (d *ColferDecoder) Decode(o interface{ Unmarshal([]byte) (int, error) }) error {
+	for {
+		if d.offset < d.i {
+			var n int
+			var err error
+			if u, ok := o.(interface {
+				UnmarshalWith([]byte, ColferLimits) (int, error)
+			}); ok {
+				n, err = u.UnmarshalWith(d.buf[d.offset:d.i], d.limits)
+			} else {
+				n, err = o.Unmarshal(d.buf[d.offset:d.i])
+			}
+			if err != io.EOF {
+				if err == nil {
+					d.offset += n
+				}
+				return err
+			}
+		}
+		// not enough data
+
+		if d.offset == d.i {
+			d.offset, d.i = 0, 0
+		}
+		if d.i == len(d.buf) {
+			if d.offset == 0 {
+				if len(d.buf) >= d.limits.SizeMax {
+					return ColferMax(fmt.Sprintf("colfer: serial size exceeds %d bytes", d.limits.SizeMax))
+				}
+				// grow
+				size := len(d.buf) * 4
+				if size > d.limits.SizeMax {
+					size = d.limits.SizeMax
+				}
+				bigger := make([]byte, size)
+				copy(bigger, d.buf)
+				d.buf = bigger
+			} else {
+				// move data to start of buffer
+				d.i = copy(d.buf, d.buf[d.offset:d.i])
+				d.offset = 0
+			}
+		}
+
+		n, err := d.r.Read(d.buf[d.i:])
+		d.i += n
+		if n == 0 && err != nil {
+			if err == io.EOF && d.offset < d.i {
+				return io.ErrUnexpectedEOF
+			}
+			return err
+		}
+	}
+}
+
+// ColferEncoder writes serials to an output stream with buffering.
+type ColferEncoder struct {
+	w   io.Writer
+	buf []byte
+}
+
+// NewColferEncoder returns a new encoder which writes to w.
+func NewColferEncoder(w io.Writer) *ColferEncoder {
+	return &ColferEncoder{w: w, buf: make([]byte, 0, 4096)}
+}
+
+// Encode buffers the serial of o. The error return options come from the
+// MarshalLen method of o, or from the output stream on a flush.
+func (e *ColferEncoder) Encode(o interface {
+	MarshalLen() (int, error)
+	MarshalTo([]byte) int
+}) error {
+	l, err := o.MarshalLen()
+	if err != nil {
+		return err
+	}
+	if len(e.buf)+l > cap(e.buf) {
+		if err := e.Flush(); err != nil {
+			return err
+		}
+		if l > cap(e.buf) {
+			e.buf = make([]byte, 0, l)
+		}
+	}
+	n := len(e.buf)
+	e.buf = e.buf[:n+l]
+	o.MarshalTo(e.buf[n:])
+	return nil
+}
+
+// Flush writes any buffered data to the output stream.
+func (e *ColferEncoder) Flush() error {
+	if len(e.buf) == 0 {
+		return nil
+	}
+	_, err := e.w.Write(e.buf)
+	e.buf = e.buf[:0]
+	return err
+}
+{{- end}}
+{{- if .Consts}}
+
+const (
+{{- range .Consts}}
+{{.DocText "\t// "}}
+	{{.NameTitle}} {{.TypeNative}} = {{.ValueNative}}
+{{- end}}
+)
+{{- end}}
+{{range .Enums}}
+{{.DocText "// "}}
+type {{.NameTitle}} {{.TypeNative}}
+{{if .Values}}
+const (
+{{- range .Values}}
+{{.DocText "\t// "}}
+	{{.NameTitle}} {{.Enum.NameTitle}} = {{.Value}}
+{{- end}}
+)
+{{end}}
+// String returns the schema name of x, or a numeric representation
+// when x is not one of the defined values.
+func (x {{.NameTitle}}) String() string {
+	switch x {
+{{- range .DistinctValues}}
+	case {{.NameTitle}}:
+		return "{{.Name}}"
+{{- end}}
+	}
+	return fmt.Sprintf("{{.Name}}(%d)", {{.TypeNative}}(x))
+}
+
+// MarshalJSON encodes x as a JSON string with the schema name, or as a JSON
+// number when x is not one of the defined values.
+func (x {{.NameTitle}}) MarshalJSON() ([]byte, error) {
+	switch x {
+{{- range .DistinctValues}}
+	case {{.NameTitle}}:
+		return []byte("\"{{.Name}}\""), nil
+{{- end}}
+	}
+	return strconv.AppendUint(nil, uint64(x), 10), nil
+}
+
+// UnmarshalJSON decodes either a JSON string with a schema name, or a JSON
+// number.
+func (x *{{.NameTitle}}) UnmarshalJSON(data []byte) error {
+	var name string
+	if json.Unmarshal(data, &name) == nil {
+		switch name {
+{{- range .Values}}
+		case "{{.Name}}":
+			*x = {{.NameTitle}}
+{{- end}}
+		default:
+			return fmt.Errorf("colfer: enumeration {{.String}} has no value %q", name)
+		}
+		return nil
+	}
+
+	v, err := strconv.ParseUint(string(data), 10, {{if eq .Type "uint8"}}8{{else if eq .Type "uint16"}}16{{else}}32{{end}})
+	if err != nil {
+		return fmt.Errorf("colfer: enumeration {{.String}} needs a name or a number; got %s", data)
+	}
+	*x = {{.NameTitle}}(v)
+	return nil
+}
+{{end}}
+{{- range .Unions}}
+{{.DocText "// "}}
+type {{.NameTitle}} interface {
+	is{{.NameTitle}}()
+}
+{{range .Members}}
+func (*{{.Struct.NameTitle}}) is{{.Union.NameTitle}}() {}
+{{- end}}
+{{end}}
+{{- range .Structs}}
 {{.DocText "// "}}
 type {{.NameTitle}} struct {
 {{range .Fields}}{{.DocText "\t// "}}
-	{{.NameTitle}}	{{if .TypeList}}[]{{end}}{{if .TypeRef}}*{{end}}{{.TypeNative}}
+	{{.NameTitle}}	{{if .TypeList}}[]{{end}}{{if .TypeKey}}map[{{.TypeKeyNative}}]{{end}}{{if or .TypeRef .Optional}}*{{end}}{{.TypeNative}}
+{{end}}
+{{- if .Pkg.KeepUnknown}}
+	// ColferUnknown has the fields of a newer schema, if any, in serial form.
+	ColferUnknown	[]byte
 {{end}}}
 
 // MarshalTo encodes o as Colfer into buf and returns the number of bytes written.
-// If the buffer is too small, MarshalTo will panic.
+// If the buffer is too small, MarshalTo will panic. MarshalTo does no checks on
+// its own. MarshalLen gives the size, and it catches reference cycles.
 {{- range .Fields}}{{if and .TypeList .TypeRef}}
 // All nil entries in o.{{.NameTitle}} will be replaced with a new value.
+{{- else if .TypeUnion}}
+// A nil pointer in o.{{.NameTitle}} will be replaced with a new value.
 {{- end}}{{end}}
 func (o *{{.NameTitle}}) MarshalTo(buf []byte) int {
 	var i int
 {{range .Fields}}{{template "marshal-field" .}}{{end}}
+{{- if .Pkg.KeepUnknown}}
+	i += copy(buf[i:], o.ColferUnknown)
+{{end}}
 	buf[i] = 0x7f
 	i++
 	return i
 }
 
 // MarshalLen returns the Colfer serial byte size.
-// The error return option is {{.Pkg.NameNative}}.ColferMax.
+// The limits are the ColferDefaults.
+// The error return options are {{.Pkg.NameNative}}.ColferMax and {{.Pkg.NameNative}}.ColferDepth.
 func (o *{{.NameTitle}}) MarshalLen() (int, error) {
-	l := 1
+	return o.MarshalLenWith(ColferDefaults())
+}
+
+// MarshalLenWith returns the Colfer serial byte size like MarshalLen does,
+// with limits instead of the ColferDefaults.
+func (o *{{.NameTitle}}) MarshalLenWith(limits ColferLimits) (int, error) {
+	if limits.DepthMax < 1 {
+		return 0, ColferDepth("colfer: struct {{.String}} exceeds the nesting depth limit; check for reference cycles")
+	}
+{{- if .HasRef}}
+	limits.DepthMax--
+{{- end}}
+	l := 1{{if .Pkg.KeepUnknown}} + len(o.ColferUnknown){{end}}
 {{range .Fields}}{{template "marshal-field-len" .}}{{end}}
-	if l > ColferSizeMax {
-		return l, ColferMax(fmt.Sprintf("colfer: struct {{.String}} exceeds %d bytes", ColferSizeMax))
+	if l > limits.SizeMax {
+		return l, ColferMax(fmt.Sprintf("colfer: struct {{.String}} exceeds %d bytes", limits.SizeMax))
 	}
 	return l, nil
 }
 
+// MarshalAppend encodes o as Colfer and appends the serial to dst, with
+// growth as with the built-in append.
+{{- range .Fields}}{{if and .TypeList .TypeRef}}
+// All nil entries in o.{{.NameTitle}} will be replaced with a new value.
+{{- else if .TypeUnion}}
+// A nil pointer in o.{{.NameTitle}} will be replaced with a new value.
+{{- end}}{{end}}
+// The error return options are {{.Pkg.NameNative}}.ColferMax and
+// {{.Pkg.NameNative}}.ColferDepth, in which case dst is returned as is.
+func (o *{{.NameTitle}}) MarshalAppend(dst []byte) ([]byte, error) {
+	l, err := o.MarshalLen()
+	if err != nil {
+		return dst, err
+	}
+	offset := len(dst)
+	dst = append(dst, make([]byte, l)...)
+	o.MarshalTo(dst[offset:])
+	return dst, nil
+}
+
 // MarshalBinary encodes o as Colfer conform encoding.BinaryMarshaler.
 {{- range .Fields}}{{if and .TypeList .TypeRef}}
 // All nil entries in o.{{.NameTitle}} will be replaced with a new value.
+{{- else if .TypeUnion}}
+// A nil pointer in o.{{.NameTitle}} will be replaced with a new value.
 {{- end}}{{end}}
-// The error return option is {{.Pkg.NameNative}}.ColferMax.
+// The error return options are {{.Pkg.NameNative}}.ColferMax and {{.Pkg.NameNative}}.ColferDepth.
 func (o *{{.NameTitle}}) MarshalBinary() (data []byte, err error) {
 	l, err := o.MarshalLen()
 	if err != nil {
@@ -168,8 +771,42 @@ func (o *{{.NameTitle}}) MarshalBinary() (data []byte, err error) {
 }
 
 // Unmarshal decodes data as Colfer and returns the number of bytes read.
-// The error return options are io.EOF, {{.Pkg.NameNative}}.ColferError and {{.Pkg.NameNative}}.ColferMax.
+{{- if .Pkg.ZeroCopy}}
+// Binary and text values share memory with data, without a copy. Any change
+// to data, including reuse of the buffer, changes those values too, and text
+// values may then even break the string immutability guarantee. The caller
+// must keep data intact for as long as o (or any of its values) is in use.
+// Appending to a binary value never overwrites data as its capacity is capped.
+// Map keys are copied still, because a change would corrupt the map.
+{{- end}}
+{{- if .Pkg.KeepUnknown}}
+// Fields beyond the schema are kept with UnmarshalBinary only, because the end
+// of the serial is unknown here.
+{{- end}}
+// The limits are the ColferDefaults.
+// The error return options are io.EOF, {{.Pkg.NameNative}}.ColferError, {{.Pkg.NameNative}}.ColferMax and {{.Pkg.NameNative}}.ColferDepth.
 func (o *{{.NameTitle}}) Unmarshal(data []byte) (int, error) {
+	return o.UnmarshalWith(data, ColferDefaults())
+}
+
+// UnmarshalWith decodes data as Colfer like Unmarshal does, with limits
+// instead of the ColferDefaults.
+func (o *{{.NameTitle}}) UnmarshalWith(data []byte, limits ColferLimits) (int, error) {
+{{- if .Pkg.KeepUnknown}}
+	return o.unmarshal(data, limits, false)
+}
+
+// unmarshal decodes data as Colfer and returns the number of bytes read.
+// With serial set, data has exactly one serial, and any fields beyond the
+// schema go into ColferUnknown.
+func (o *{{.NameTitle}}) unmarshal(data []byte, limits ColferLimits, serial bool) (int, error) {
+{{- end}}
+	if limits.DepthMax < 1 {
+		return 0, ColferDepth("colfer: struct {{.String}} exceeds the nesting depth limit")
+	}
+{{- if .HasRef}}
+	limits.DepthMax--
+{{- end}}
 	if len(data) == 0 {
 		return 0, io.EOF
 	}
@@ -177,108 +814,360 @@ func (o *{{.NameTitle}}) Unmarshal(data []byte) (int, error) {
 	i := 1
 {{range .Fields}}{{template "unmarshal-field" .}}{{end}}
 	if header != 0x7f {
+{{- if .Pkg.KeepUnknown}}
+		if !serial || {{if .Fields}}header&0x7f <= {{.IndexMax}} || {{end}}data[len(data)-1] != 0x7f {
+			return 0, ColferError(i - 1)
+		}
+  {{- if .Pkg.ZeroCopy}}
+		o.ColferUnknown = data[i-1 : len(data)-1 : len(data)-1]
+  {{- else}}
+		o.ColferUnknown = make([]byte, len(data)-i)
+		copy(o.ColferUnknown, data[i-1:])
+  {{- end}}
+		i = len(data)
+{{- else}}
 		return 0, ColferError(i - 1)
+{{- end}}
 	}
-	if i < ColferSizeMax {
+	if i < limits.SizeMax {
 		return i, nil
 	}
 eof:
-	if i >= ColferSizeMax {
-		return 0, ColferMax(fmt.Sprintf("colfer: struct {{.String}} size exceeds %d bytes", ColferSizeMax))
+	if i >= limits.SizeMax {
+		return 0, ColferMax(fmt.Sprintf("colfer: struct {{.String}} size exceeds %d bytes", limits.SizeMax))
 	}
 	return 0, io.EOF
 }
 
 // UnmarshalBinary decodes data as Colfer conform encoding.BinaryUnmarshaler.
-// The error return options are io.EOF, {{.Pkg.NameNative}}.ColferError, {{.Pkg.NameNative}}.ColferTail and {{.Pkg.NameNative}}.ColferMax.
+{{- if .Pkg.ZeroCopy}}
+// Binary and text values share memory with data, like Unmarshal does.
+{{- end}}
+{{- if .Pkg.KeepUnknown}}
+// Any fields with an index beyond {{.IndexMax}} are kept in ColferUnknown, as data
+// ends with the serial. Nested data structures can not keep unknown fields.
+{{- end}}
+// The error return options are io.EOF, {{.Pkg.NameNative}}.ColferError, {{.Pkg.NameNative}}.ColferTail, {{.Pkg.NameNative}}.ColferMax and {{.Pkg.NameNative}}.ColferDepth.
 func (o *{{.NameTitle}}) UnmarshalBinary(data []byte) error {
+{{- if .Pkg.KeepUnknown}}
+	i, err := o.unmarshal(data, ColferDefaults(), true)
+{{- else}}
 	i, err := o.Unmarshal(data)
+{{- end}}
 	if i < len(data) && err == nil {
 		return ColferTail(i)
 	}
 	return err
 }
-{{end}}`
 
-const goMarshalField = `{{if eq .Type "bool"}}
-	if o.{{.NameTitle}} {
-		buf[i] = {{.Index}}
-		i++
+// Equal returns whether o and x have the same serial. Hence, nil and empty
+// lists, maps and binaries are equal, and so are nil and empty entries in
+// lists, maps and unions. Either of o and x may be nil.
+func (o *{{.NameTitle}}) Equal(x *{{.NameTitle}}) bool {
+	if o == nil || x == nil {
+		return o == x
 	}
-{{else if eq .Type "uint8"}}
-	if x := o.{{.NameTitle}}; x != 0 {
-		buf[i] = {{.Index}}
-		i++
-		buf[i] = x
-		i++
+{{range .Fields}}{{template "equal-field" .}}{{end}}
+{{- if .Pkg.KeepUnknown}}
+	return string(o.ColferUnknown) == string(x.ColferUnknown)
+{{- else}}
+	return true
+{{- end}}
+}
+
+// Clone returns a deep copy of o, or nil when o is nil.
+{{- if .Pkg.ZeroCopy}}
+// The binary and text values of the copy do not share memory with any serial.
+{{- end}}
+func (o *{{.NameTitle}}) Clone() *{{.NameTitle}} {
+	if o == nil {
+		return nil
 	}
-{{else if eq .Type "uint16"}}
-	if x := o.{{.NameTitle}}; x >= 1<<8 {
-		buf[i] = {{.Index}}
-		i++
-		buf[i] = byte(x >> 8)
-		i++
-		buf[i] = byte(x)
-		i++
-	} else if x != 0 {
-		buf[i] = {{.Index}} | 0x80
-		i++
-		buf[i] = byte(x)
-		i++
+	c := *o
+{{range .Fields}}{{template "clone-field" .}}{{end}}
+{{- if .Pkg.KeepUnknown}}
+	if o.ColferUnknown != nil {
+		c.ColferUnknown = make([]byte, len(o.ColferUnknown))
+		copy(c.ColferUnknown, o.ColferUnknown)
 	}
-{{else if eq .Type "uint32"}}
-	if x := o.{{.NameTitle}}; x >= 1<<21 {
-		buf[i] = {{.Index}} | 0x80
-		intconv.PutUint32(buf[i+1:], x)
-		i += 5
-	} else if x != 0 {
-		buf[i] = {{.Index}}
-		i++
-		for x >= 0x80 {
-			buf[i] = byte(x | 0x80)
-			x >>= 7
-			i++
-		}
-		buf[i] = byte(x)
-		i++
+{{- end}}
+	return &c
+}
+
+// MarshalJSON encodes o conform the json.Marshaler interface. The JSON object
+// has the fields present in the serial only, in order of appearance, with the
+// names from the schema. Integers beyond 2^53 - 1 in magnitude are written as
+// a JSON string, as JavaScript can not hold them exactly. The mapping matches
+// the generated Java and JavaScript code, and colf(1) decode.
+{{- if .Pkg.KeepUnknown}}
+// Any ColferUnknown content is not included.
+{{- end}}
+func (o *{{.NameTitle}}) MarshalJSON() ([]byte, error) {
+	// each field starts with a comma, of which the first one becomes the
+	// opening brace
+	var buf []byte
+{{range .Fields}}{{template "json-marshal-field" .}}{{end}}
+	if len(buf) == 0 {
+		return []byte("{}"), nil
 	}
-{{else if eq .Type "uint64"}}
-	if x := o.{{.NameTitle}}; x >= 1<<49 {
-		buf[i] = {{.Index}} | 0x80
-		intconv.PutUint64(buf[i+1:], x)
-		i += 9
-	} else if x != 0 {
-		buf[i] = {{.Index}}
-		i++
-		for x >= 0x80 {
-			buf[i] = byte(x | 0x80)
-			x >>= 7
-			i++
-		}
-		buf[i] = byte(x)
-		i++
+	buf[0] = '{'
+	return append(buf, '}'), nil
+}
+
+// UnmarshalJSON decodes data conform the json.Unmarshaler interface, with the
+// mapping of MarshalJSON. Fields absent in data, or with a null value, remain
+// as is.
+func (o *{{.NameTitle}}) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
 	}
-{{else if eq .Type "int32"}}
-	if v := o.{{.NameTitle}}; v != 0 {
-		x := uint32(v)
-		if v >= 0 {
-			buf[i] = {{.Index}}
-		} else {
-			x = ^x + 1
-			buf[i] = {{.Index}} | 0x80
+	for name, raw := range fields {
+		if string(raw) == "null" {
+			continue
 		}
-		i++
-		for x >= 0x80 {
-			buf[i] = byte(x | 0x80)
-			x >>= 7
-			i++
+
+		switch name {
+{{- range .Fields}}{{template "json-unmarshal-field" .}}{{end}}
+		default:
+			return fmt.Errorf("colfer: struct {{.String}} has no field %q", name)
 		}
-		buf[i] = byte(x)
-		i++
 	}
-{{else if eq .Type "int64"}}
-	if v := o.{{.NameTitle}}; v != 0 {
-		x := uint64(v)
+	return nil
+}
+
+// {{.NameTitle}}View provides read-only access to {{.NameTitle}} serials.
+// Fields decode on demand. Binary values share memory with the serial.
+{{- if .Pkg.ZeroCopy}}
+// Text values share memory with the serial too.
+{{- end}}
+// The zero value has all fields absent.
+type {{.NameTitle}}View struct {
+	// data is the serial.
+	data []byte
+	// index has the position of each field value in data, right after the
+	// header byte, with zero for absent.
+	index [{{.IndexMax}} + 1]int
+	// limits apply to nested data structures.
+	limits ColferLimits
+}
+
+// Unmarshal indexes data as Colfer and returns the number of bytes read.
+// The checks match {{.NameTitle}}.Unmarshal, without any allocation. The view
+// uses data as is, so the caller must keep data intact for as long as v (or
+// any of its values) is in use.
+// The limits are the ColferDefaults.
+// The error return options are io.EOF, {{.Pkg.NameNative}}.ColferError, {{.Pkg.NameNative}}.ColferMax and {{.Pkg.NameNative}}.ColferDepth.
+func (v *{{.NameTitle}}View) Unmarshal(data []byte) (int, error) {
+	return v.UnmarshalWith(data, ColferDefaults())
+}
+
+// UnmarshalWith indexes data as Colfer like Unmarshal does, with limits
+// instead of the ColferDefaults.
+func (v *{{.NameTitle}}View) UnmarshalWith(data []byte, limits ColferLimits) (int, error) {
+	if limits.DepthMax < 1 {
+		return 0, ColferDepth("colfer: struct {{.String}} exceeds the nesting depth limit")
+	}
+{{- if .HasRef}}
+	limits.DepthMax--
+{{- end}}
+	if len(data) == 0 {
+		return 0, io.EOF
+	}
+	header := data[0]
+	i := 1
+	var index [{{.IndexMax}} + 1]int
+{{range .Fields}}{{template "view-index" .}}{{end}}
+	if header != 0x7f {
+		return 0, ColferError(i - 1)
+	}
+	if i < limits.SizeMax {
+		v.data = data[:i]
+		v.index = index
+		v.limits = limits
+		return i, nil
+	}
+eof:
+	if i >= limits.SizeMax {
+		return 0, ColferMax(fmt.Sprintf("colfer: struct {{.String}} size exceeds %d bytes", limits.SizeMax))
+	}
+	return 0, io.EOF
+}
+{{range .Fields}}{{template "view-get" .}}{{end}}
+// {{.NameTitle}}Mask is a field selection for {{.NameTitle}}.UnmarshalMask.
+// The zero value selects nothing.
+type {{.NameTitle}}Mask struct {
+	// whole flags the fields selected in full, by index.
+	whole [{{.IndexMax}} + 1]bool
+{{- range .Fields}}{{if .TypeRef}}
+	// sub{{.Index}} has the selection within field {{.Name}}, if any.
+	sub{{.Index}} *{{.TypeNative}}Mask
+{{- end}}{{end}}
+}
+
+// Add selects fields by their name in the schema. Paths with dots select the
+// fields of nested data structures, which applies to each element in lists,
+// and to each value in maps. Unions can only be selected as a whole.
+func (m *{{.NameTitle}}Mask) Add(paths ...string) error {
+	for _, path := range paths {
+		name := path
+		if dot := strings.IndexByte(path, '.'); dot >= 0 {
+			name = path[:dot]
+		}
+
+		switch name {
+{{- range .Fields}}{{template "mask-add" .}}{{end}}
+		default:
+			return fmt.Errorf("colfer: struct {{.String}} has no field %q", name)
+		}
+	}
+	return nil
+}
+
+// UnmarshalMask decodes the fields selected by mask only, and it returns the
+// number of bytes read. The checks match Unmarshal, with the fields which are
+// not selected skipped without any allocation.
+{{- if .Pkg.ZeroCopy}}
+// Binary and text values share memory with data, like Unmarshal does.
+{{- end}}
+// The limits are the ColferDefaults.
+// The error return options are io.EOF, {{.Pkg.NameNative}}.ColferError, {{.Pkg.NameNative}}.ColferMax and {{.Pkg.NameNative}}.ColferDepth.
+func (o *{{.NameTitle}}) UnmarshalMask(data []byte, mask *{{.NameTitle}}Mask) (int, error) {
+	return o.UnmarshalMaskWith(data, mask, ColferDefaults())
+}
+
+// UnmarshalMaskWith decodes like UnmarshalMask does, with limits instead of
+// the ColferDefaults.
+func (o *{{.NameTitle}}) UnmarshalMaskWith(data []byte, mask *{{.NameTitle}}Mask, limits ColferLimits) (int, error) {
+	var v {{.NameTitle}}View
+	n, err := v.UnmarshalWith(data, limits)
+	if err != nil {
+		return 0, err
+	}
+{{range .Fields}}{{template "mask-field" .}}{{end}}
+	return n, nil
+}
+{{- end}}`
+
+const goMarshalField = `{{if .TypeKey}}{{template "marshal-map" .}}{{else if .Optional}}{{template "marshal-optional" .}}{{else if .TypeUnion}}{{template "marshal-union" .}}{{else if and .TypeList (eq .Type "bool" "uint8" "uint16" "uint32" "uint64" "int32" "int64")}}
+	if l := len(o.{{.NameTitle}}); l != 0 {
+		buf[i] = {{.Index}}
+		i++
+		x := uint(l)
+		for x >= 0x80 {
+			buf[i] = byte(x | 0x80)
+			x >>= 7
+			i++
+		}
+		buf[i] = byte(x)
+		i++
+ {{- if eq .Type "bool"}}
+		for _, v := range o.{{.NameTitle}} {
+			buf[i] = 0
+			if v {
+				buf[i] = 1
+			}
+			i++
+		}
+ {{- else if and (eq .Type "uint8") (not .TypeEnum)}}
+		i += copy(buf[i:], o.{{.NameTitle}})
+ {{- else if eq .Type "uint8"}}
+		for _, v := range o.{{.NameTitle}} {
+			buf[i] = byte(v)
+			i++
+		}
+ {{- else}}
+		for _, v := range o.{{.NameTitle}} {
+			x := {{if eq .Type "int32"}}uint32(v<<1) ^ uint32(v>>31){{else if eq .Type "int64"}}uint64(v<<1) ^ uint64(v>>63){{else if eq .Type "uint64"}}uint64(v){{else}}uint32(v){{end}}
+			{{if eq .Type "uint64" "int64"}}for n := 0; x >= 0x80 && n < 8; n++ {{"{"}}{{else}}for x >= 0x80 {{"{"}}{{end}}
+				buf[i] = byte(x | 0x80)
+				x >>= 7
+				i++
+			}
+			buf[i] = byte(x)
+			i++
+		}
+ {{- end}}
+	}
+{{else if eq .Type "bool"}}
+	if o.{{.NameTitle}} {
+		buf[i] = {{.Index}}
+		i++
+	}
+{{else if eq .Type "uint8"}}
+	if x := {{if .TypeEnum}}{{.Type}}(o.{{.NameTitle}}){{else}}o.{{.NameTitle}}{{end}}; x != 0 {
+		buf[i] = {{.Index}}
+		i++
+		buf[i] = x
+		i++
+	}
+{{else if eq .Type "uint16"}}
+	if x := {{if .TypeEnum}}{{.Type}}(o.{{.NameTitle}}){{else}}o.{{.NameTitle}}{{end}}; x >= 1<<8 {
+		buf[i] = {{.Index}}
+		i++
+		buf[i] = byte(x >> 8)
+		i++
+		buf[i] = byte(x)
+		i++
+	} else if x != 0 {
+		buf[i] = {{.Index}} | 0x80
+		i++
+		buf[i] = byte(x)
+		i++
+	}
+{{else if eq .Type "uint32"}}
+	if x := {{if .TypeEnum}}{{.Type}}(o.{{.NameTitle}}){{else}}o.{{.NameTitle}}{{end}}; x >= 1<<21 {
+		buf[i] = {{.Index}} | 0x80
+		intconv.PutUint32(buf[i+1:], x)
+		i += 5
+	} else if x != 0 {
+		buf[i] = {{.Index}}
+		i++
+		for x >= 0x80 {
+			buf[i] = byte(x | 0x80)
+			x >>= 7
+			i++
+		}
+		buf[i] = byte(x)
+		i++
+	}
+{{else if eq .Type "uint64"}}
+	if x := o.{{.NameTitle}}; x >= 1<<49 {
+		buf[i] = {{.Index}} | 0x80
+		intconv.PutUint64(buf[i+1:], x)
+		i += 9
+	} else if x != 0 {
+		buf[i] = {{.Index}}
+		i++
+		for x >= 0x80 {
+			buf[i] = byte(x | 0x80)
+			x >>= 7
+			i++
+		}
+		buf[i] = byte(x)
+		i++
+	}
+{{else if eq .Type "int32"}}
+	if v := o.{{.NameTitle}}; v != 0 {
+		x := uint32(v)
+		if v >= 0 {
+			buf[i] = {{.Index}}
+		} else {
+			x = ^x + 1
+			buf[i] = {{.Index}} | 0x80
+		}
+		i++
+		for x >= 0x80 {
+			buf[i] = byte(x | 0x80)
+			x >>= 7
+			i++
+		}
+		buf[i] = byte(x)
+		i++
+	}
+{{else if eq .Type "int64"}}
+	if v := o.{{.NameTitle}}; v != 0 {
+		x := uint64(v)
 		if v >= 0 {
 			buf[i] = {{.Index}}
 		} else {
@@ -415,22 +1304,48 @@ const goMarshalField = `{{if eq .Type "bool"}}
 	}
 {{end}}`
 
-const goMarshalFieldLen = `{{if eq .Type "bool"}}
+const goMarshalFieldLen = `{{if .TypeKey}}{{template "marshal-map-len" .}}{{else if .Optional}}{{template "marshal-optional-len" .}}{{else if .TypeUnion}}{{template "marshal-union-len" .}}{{else if and .TypeList (eq .Type "bool" "uint8" "uint16" "uint32" "uint64" "int32" "int64")}}
+	if x := len(o.{{.NameTitle}}); x != 0 {
+		if x > {{or .ListMax "limits.ListMax"}} {
+			return 0, ColferMax(fmt.Sprintf("colfer: field {{.String}} exceeds %d elements", {{or .ListMax "limits.ListMax"}}))
+		}
+ {{- if eq .Type "bool" "uint8"}}
+		for l += 2 + x; x >= 0x80; l++ {
+			x >>= 7
+		}
+ {{- else}}
+		for l += 2; x >= 0x80; l++ {
+			x >>= 7
+		}
+		for _, v := range o.{{.NameTitle}} {
+			x := {{if eq .Type "int32"}}uint32(v<<1) ^ uint32(v>>31){{else if eq .Type "int64"}}uint64(v<<1) ^ uint64(v>>63){{else if eq .Type "uint64"}}uint64(v){{else}}uint32(v){{end}}
+			{{if eq .Type "uint64" "int64"}}for n := 0; x >= 0x80 && n < 8; n++ {{"{"}}{{else}}for x >= 0x80 {{"{"}}{{end}}
+				x >>= 7
+				l++
+			}
+			l++
+		}
+		if l > limits.SizeMax {
+			return 0, ColferMax(fmt.Sprintf("colfer: struct {{.Struct.String}} size exceeds %d bytes", limits.SizeMax))
+		}
+ {{- end}}
+	}
+{{else if eq .Type "bool"}}
 	if o.{{.NameTitle}} {
 		l++
 	}
 {{else if eq .Type "uint8"}}
-	if x := o.{{.NameTitle}}; x != 0 {
+	if x := {{if .TypeEnum}}{{.Type}}(o.{{.NameTitle}}){{else}}o.{{.NameTitle}}{{end}}; x != 0 {
 		l += 2
 	}
 {{else if eq .Type "uint16"}}
-	if x := o.{{.NameTitle}}; x >= 1<<8 {
+	if x := {{if .TypeEnum}}{{.Type}}(o.{{.NameTitle}}){{else}}o.{{.NameTitle}}{{end}}; x >= 1<<8 {
 		l += 3
 	} else if x != 0 {
 		l += 2
 	}
 {{else if eq .Type "uint32"}}
-	if x := o.{{.NameTitle}}; x >= 1<<21 {
+	if x := {{if .TypeEnum}}{{.Type}}(o.{{.NameTitle}}){{else}}o.{{.NameTitle}}{{end}}; x >= 1<<21 {
 		l += 5
 	} else if x != 0 {
 		for l += 2; x >= 0x80; l++ {
@@ -470,8 +1385,8 @@ const goMarshalFieldLen = `{{if eq .Type "bool"}}
 {{else if eq .Type "float32"}}
  {{- if .TypeList}}
 	if x := len(o.{{.NameTitle}}); x != 0 {
-		if x > ColferListMax {
-			return 0, ColferMax(fmt.Sprintf("colfer: field {{.String}} exceeds %d elements", ColferListMax))
+		if x > {{or .ListMax "limits.ListMax"}} {
+			return 0, ColferMax(fmt.Sprintf("colfer: field {{.String}} exceeds %d elements", {{or .ListMax "limits.ListMax"}}))
 		}
 		for l += 2+x*4; x >= 0x80; l++ {
 			x >>= 7
@@ -485,8 +1400,8 @@ const goMarshalFieldLen = `{{if eq .Type "bool"}}
 {{else if eq .Type "float64"}}
  {{- if .TypeList}}
 	if x := len(o.{{.NameTitle}}); x != 0 {
-		if x > ColferListMax {
-			return 0, ColferMax(fmt.Sprintf("colfer: field {{.String}} exceeds %d elements", ColferListMax))
+		if x > {{or .ListMax "limits.ListMax"}} {
+			return 0, ColferMax(fmt.Sprintf("colfer: field {{.String}} exceeds %d elements", {{or .ListMax "limits.ListMax"}}))
 		}
 		for l += 2+x*8; x >= 0x80; l++ {
 			x >>= 7
@@ -508,27 +1423,27 @@ const goMarshalFieldLen = `{{if eq .Type "bool"}}
 {{else if eq .Type "text" "binary"}}
 	if x := len(o.{{.NameTitle}}); x != 0 {
  {{- if .TypeList}}
-		if x > ColferListMax {
-			return 0, ColferMax(fmt.Sprintf("colfer: field {{.String}} exceeds %d elements", ColferListMax))
+		if x > {{or .ListMax "limits.ListMax"}} {
+			return 0, ColferMax(fmt.Sprintf("colfer: field {{.String}} exceeds %d elements", {{or .ListMax "limits.ListMax"}}))
 		}
 		for l += 2; x >= 0x80; l++ {
 			x >>= 7
 		}
 		for _, a := range o.{{.NameTitle}} {
 			x = len(a)
-			if x > ColferSizeMax {
-				return 0, ColferMax(fmt.Sprintf("colfer: field {{.String}} exceeds %d bytes", ColferSizeMax))
+			if x > {{or .SizeMax "limits.SizeMax"}} {
+				return 0, ColferMax(fmt.Sprintf("colfer: field {{.String}} exceeds %d bytes", {{or .SizeMax "limits.SizeMax"}}))
 			}
 			for l += x+1; x >= 0x80; l++ {
 				x >>= 7
 			}
 		}
-		if l >= ColferSizeMax {
-			return 0, ColferMax(fmt.Sprintf("colfer: struct {{.Struct.String}} size exceeds %d bytes", ColferSizeMax))
+		if l >= limits.SizeMax {
+			return 0, ColferMax(fmt.Sprintf("colfer: struct {{.Struct.String}} size exceeds %d bytes", limits.SizeMax))
 		}
  {{- else}}
-		if x > ColferSizeMax {
-			return 0, ColferMax(fmt.Sprintf("colfer: field {{.String}} exceeds %d bytes", ColferSizeMax))
+		if x > {{or .SizeMax "limits.SizeMax"}} {
+			return 0, ColferMax(fmt.Sprintf("colfer: field {{.String}} exceeds %d bytes", {{or .SizeMax "limits.SizeMax"}}))
 		}
 		for l += x+2; x >= 0x80; l++ {
 			x >>= 7
@@ -537,8 +1452,8 @@ const goMarshalFieldLen = `{{if eq .Type "bool"}}
 	}
 {{else if .TypeList}}
 	if x := len(o.{{.NameTitle}}); x != 0 {
-		if x > ColferListMax {
-			return 0, ColferMax(fmt.Sprintf("colfer: field {{.String}} exceeds %d elements", ColferListMax))
+		if x > {{or .ListMax "limits.ListMax"}} {
+			return 0, ColferMax(fmt.Sprintf("colfer: field {{.String}} exceeds %d elements", {{or .ListMax "limits.ListMax"}}))
 		}
 		for l += 2; x >= 0x80; l++ {
 			x >>= 7
@@ -548,19 +1463,29 @@ const goMarshalFieldLen = `{{if eq .Type "bool"}}
 				l++
 				continue
 			}
-			vl, err := v.MarshalLen()
+{{- if eq .TypeRef.String .Struct.String}}
+			if v == o {
+				return 0, ColferDepth("colfer: field {{.String}} makes a reference cycle")
+			}
+{{- end}}
+			vl, err := v.MarshalLenWith({{template "limits-arg" .}})
 			if err != nil {
 				return 0, err
 			}
 			l += vl
 		}
-		if l > ColferSizeMax {
-			return 0, ColferMax(fmt.Sprintf("colfer: struct {{.Struct.String}} size exceeds %d bytes", ColferSizeMax))
+		if l > limits.SizeMax {
+			return 0, ColferMax(fmt.Sprintf("colfer: struct {{.Struct.String}} size exceeds %d bytes", limits.SizeMax))
 		}
 	}
 {{else}}
 	if v := o.{{.NameTitle}}; v != nil {
-		vl, err := v.MarshalLen()
+{{- if eq .TypeRef.String .Struct.String}}
+		if v == o {
+			return 0, ColferDepth("colfer: field {{.String}} makes a reference cycle")
+		}
+{{- end}}
+		vl, err := v.MarshalLenWith({{template "limits-arg" .}})
 		if err != nil {
 			return 0, err
 		}
@@ -568,15 +1493,89 @@ const goMarshalFieldLen = `{{if eq .Type "bool"}}
 	}
 {{end}}`
 
-const goUnmarshalField = `{{if eq .Type "bool"}}
+const goUnmarshalField = `{{if .TypeKey}}{{template "unmarshal-map" .}}{{else if .TypeUnion}}{{template "unmarshal-union" .}}{{else if and .TypeList (eq .Type "bool" "uint8" "uint16" "uint32" "uint64" "int32" "int64")}}
 	if header == {{.Index}} {
+{{template "unmarshal-varint" .}}
+		if x > uint({{or .ListMax "limits.ListMax"}}) {
+			return 0, ColferMax(fmt.Sprintf("colfer: {{.String}} length %d exceeds %d elements", x, {{or .ListMax "limits.ListMax"}}))
+		}
+		l := int(x)
+
+		if end := i + l; end >= len(data) {
+			i = end
+			goto eof
+		}
+		a := make([]{{.TypeNative}}, l)
+ {{- if eq .Type "bool"}}
+		for ai := range a {
+			a[ai] = data[i] != 0
+			i++
+		}
+ {{- else if and (eq .Type "uint8") (not .TypeEnum)}}
+		i += copy(a, data[i:])
+ {{- else if eq .Type "uint8"}}
+		for ai := range a {
+			a[ai] = {{.TypeNative}}(data[i])
+			i++
+		}
+ {{- else}}
+		for ai := range a {
+			if i >= len(data) {
+				goto eof
+			}
+			x := uint64(data[i])
+			i++
+
+			if x >= 0x80 {
+				x &= 0x7f
+				for shift := uint(7); ; shift += 7 {
+					if i >= len(data) {
+						goto eof
+					}
+					b := uint64(data[i])
+					i++
+
+					if b < 0x80 || shift == 56 {
+						x |= b << shift
+						break
+					}
+					x |= (b & 0x7f) << shift
+				}
+			}
+  {{- if eq .Type "int32"}}
+			a[ai] = int32(x>>1) ^ -int32(x&1)
+  {{- else if eq .Type "int64"}}
+			a[ai] = int64(x>>1) ^ -int64(x&1)
+  {{- else}}
+			a[ai] = {{.TypeNative}}(x)
+  {{- end}}
+		}
+ {{- end}}
+		o.{{.NameTitle}} = a
+
 		if i >= len(data) {
 			goto eof
 		}
-		o.{{.NameTitle}} = true
 		header = data[i]
 		i++
 	}
+{{else if eq .Type "bool"}}
+	if header == {{.Index}} {
+		if i >= len(data) {
+			goto eof
+		}
+		{{if .Optional}}o.{{.NameTitle}} = new(bool)
+		*{{end}}o.{{.NameTitle}} = true
+		header = data[i]
+		i++
+	}{{if .Optional}} else if header == {{.Index}}|0x80 {
+		if i >= len(data) {
+			goto eof
+		}
+		o.{{.NameTitle}} = new(bool)
+		header = data[i]
+		i++
+	}{{end}}
 {{else if eq .Type "uint8"}}
 	if header == {{.Index}} {
 		start := i
@@ -584,7 +1583,8 @@ const goUnmarshalField = `{{if eq .Type "bool"}}
 		if i >= len(data) {
 			goto eof
 		}
-		o.{{.NameTitle}} = data[start]
+		{{if .Optional}}o.{{.NameTitle}} = new({{.TypeNative}})
+		*{{end}}o.{{.NameTitle}} = {{if .TypeEnum}}{{.TypeNative}}(data[start]){{else}}data[start]{{end}}
 		header = data[i]
 		i++
 	}
@@ -595,7 +1595,8 @@ const goUnmarshalField = `{{if eq .Type "bool"}}
 		if i >= len(data) {
 			goto eof
 		}
-		o.{{.NameTitle}} = intconv.Uint16(data[start:])
+		{{if .Optional}}o.{{.NameTitle}} = new({{.TypeNative}})
+		*{{end}}o.{{.NameTitle}} = {{if .TypeEnum}}{{.TypeNative}}(intconv.Uint16(data[start:])){{else}}intconv.Uint16(data[start:]){{end}}
 		header = data[i]
 		i++
 	} else if header == {{.Index}}|0x80 {
@@ -604,7 +1605,8 @@ const goUnmarshalField = `{{if eq .Type "bool"}}
 		if i >= len(data) {
 			goto eof
 		}
-		o.{{.NameTitle}} = uint16(data[start])
+		{{if .Optional}}o.{{.NameTitle}} = new({{.TypeNative}})
+		*{{end}}o.{{.NameTitle}} = {{.TypeNative}}(data[start])
 		header = data[i]
 		i++
 	}
@@ -633,7 +1635,8 @@ const goUnmarshalField = `{{if eq .Type "bool"}}
 				x |= (b & 0x7f) << shift
 			}
 		}
-		o.{{.NameTitle}} = x
+		{{if .Optional}}o.{{.NameTitle}} = new({{.TypeNative}})
+		*{{end}}o.{{.NameTitle}} = {{if .TypeEnum}}{{.TypeNative}}(x){{else}}x{{end}}
 
 		header = data[i]
 		i++
@@ -643,7 +1646,8 @@ const goUnmarshalField = `{{if eq .Type "bool"}}
 		if i >= len(data) {
 			goto eof
 		}
-		o.{{.NameTitle}} = intconv.Uint32(data[start:])
+		{{if .Optional}}o.{{.NameTitle}} = new({{.TypeNative}})
+		*{{end}}o.{{.NameTitle}} = {{if .TypeEnum}}{{.TypeNative}}(intconv.Uint32(data[start:])){{else}}intconv.Uint32(data[start:]){{end}}
 		header = data[i]
 		i++
 	}
@@ -672,7 +1676,8 @@ const goUnmarshalField = `{{if eq .Type "bool"}}
 				x |= (b & 0x7f) << shift
 			}
 		}
-		o.{{.NameTitle}} = x
+		{{if .Optional}}o.{{.NameTitle}} = new({{.TypeNative}})
+		*{{end}}o.{{.NameTitle}} = x
 
 		header = data[i]
 		i++
@@ -682,7 +1687,8 @@ const goUnmarshalField = `{{if eq .Type "bool"}}
 		if i >= len(data) {
 			goto eof
 		}
-		o.{{.NameTitle}} = intconv.Uint64(data[start:])
+		{{if .Optional}}o.{{.NameTitle}} = new({{.TypeNative}})
+		*{{end}}o.{{.NameTitle}} = intconv.Uint64(data[start:])
 		header = data[i]
 		i++
 	}
@@ -711,7 +1717,8 @@ const goUnmarshalField = `{{if eq .Type "bool"}}
 				x |= (b & 0x7f) << shift
 			}
 		}
-		o.{{.NameTitle}} = int32(x)
+		{{if .Optional}}o.{{.NameTitle}} = new({{.TypeNative}})
+		*{{end}}o.{{.NameTitle}} = int32(x)
 
 		header = data[i]
 		i++
@@ -739,7 +1746,8 @@ const goUnmarshalField = `{{if eq .Type "bool"}}
 				x |= (b & 0x7f) << shift
 			}
 		}
-		o.{{.NameTitle}} = int32(^x + 1)
+		{{if .Optional}}o.{{.NameTitle}} = new({{.TypeNative}})
+		*{{end}}o.{{.NameTitle}} = int32(^x + 1)
 
 		header = data[i]
 		i++
@@ -769,7 +1777,8 @@ const goUnmarshalField = `{{if eq .Type "bool"}}
 				x |= (b & 0x7f) << shift
 			}
 		}
-		o.{{.NameTitle}} = int64(x)
+		{{if .Optional}}o.{{.NameTitle}} = new({{.TypeNative}})
+		*{{end}}o.{{.NameTitle}} = int64(x)
 
 		header = data[i]
 		i++
@@ -797,7 +1806,8 @@ const goUnmarshalField = `{{if eq .Type "bool"}}
 				x |= (b & 0x7f) << shift
 			}
 		}
-		o.{{.NameTitle}} = int64(^x + 1)
+		{{if .Optional}}o.{{.NameTitle}} = new({{.TypeNative}})
+		*{{end}}o.{{.NameTitle}} = int64(^x + 1)
 
 		header = data[i]
 		i++
@@ -806,8 +1816,8 @@ const goUnmarshalField = `{{if eq .Type "bool"}}
  {{- if .TypeList}}
 	if header == {{.Index}} {
 {{template "unmarshal-varint" .}}
-		if x > uint(ColferListMax) {
-			return 0, ColferMax(fmt.Sprintf("colfer: {{.String}} length %d exceeds %d elements", x, ColferListMax))
+		if x > uint({{or .ListMax "limits.ListMax"}}) {
+			return 0, ColferMax(fmt.Sprintf("colfer: {{.String}} length %d exceeds %d elements", x, {{or .ListMax "limits.ListMax"}}))
 		}
 
 		l := int(x)
@@ -833,7 +1843,8 @@ const goUnmarshalField = `{{if eq .Type "bool"}}
 		if i >= len(data) {
 			goto eof
 		}
-		o.{{.NameTitle}} = math.Float32frombits(intconv.Uint32(data[start:]))
+		{{if .Optional}}o.{{.NameTitle}} = new({{.TypeNative}})
+		*{{end}}o.{{.NameTitle}} = math.Float32frombits(intconv.Uint32(data[start:]))
 		header = data[i]
 		i++
 	}
@@ -842,8 +1853,8 @@ const goUnmarshalField = `{{if eq .Type "bool"}}
  {{- if .TypeList}}
 	if header == {{.Index}} {
 {{template "unmarshal-varint" .}}
-		if x > uint(ColferListMax) {
-			return 0, ColferMax(fmt.Sprintf("colfer: {{.String}} length %d exceeds %d elements", x, ColferListMax))
+		if x > uint({{or .ListMax "limits.ListMax"}}) {
+			return 0, ColferMax(fmt.Sprintf("colfer: {{.String}} length %d exceeds %d elements", x, {{or .ListMax "limits.ListMax"}}))
 		}
 		l := int(x)
 
@@ -868,7 +1879,8 @@ const goUnmarshalField = `{{if eq .Type "bool"}}
 		if i >= len(data) {
 			goto eof
 		}
-		o.{{.NameTitle}} = math.Float64frombits(intconv.Uint64(data[start:]))
+		{{if .Optional}}o.{{.NameTitle}} = new({{.TypeNative}})
+		*{{end}}o.{{.NameTitle}} = math.Float64frombits(intconv.Uint64(data[start:]))
 		header = data[i]
 		i++
 	}
@@ -897,16 +1909,16 @@ const goUnmarshalField = `{{if eq .Type "bool"}}
 	if header == {{.Index}} {
 {{template "unmarshal-varint" .}}
  {{- if .TypeList}}
-		if x > uint(ColferListMax) {
-			return 0, ColferMax(fmt.Sprintf("colfer: {{.String}} length %d exceeds %d elements", x, ColferListMax))
+		if x > uint({{or .ListMax "limits.ListMax"}}) {
+			return 0, ColferMax(fmt.Sprintf("colfer: {{.String}} length %d exceeds %d elements", x, {{or .ListMax "limits.ListMax"}}))
 		}
 		a := make([]string, int(x))
 		o.{{.NameTitle}} = a
 
 		for ai := range a {
 {{template "unmarshal-varint" .}}
-			if x > uint(ColferSizeMax) {
-				return 0, ColferMax(fmt.Sprintf("colfer: {{.String}} element %d size %d exceeds %d bytes", ai, x, ColferSizeMax))
+			if x > uint({{or .SizeMax "limits.SizeMax"}}) {
+				return 0, ColferMax(fmt.Sprintf("colfer: {{.String}} element %d size %d exceeds %d bytes", ai, x, {{or .SizeMax "limits.SizeMax"}}))
 			}
 
 			start := i
@@ -914,7 +1926,11 @@ const goUnmarshalField = `{{if eq .Type "bool"}}
 			if i >= len(data) {
 				goto eof
 			}
+{{- if .Struct.Pkg.ZeroCopy}}
+			a[ai] = colferString(data[start:i])
+{{- else}}
 			a[ai] = string(data[start:i])
+{{- end}}
 		}
 
 		if i >= len(data) {
@@ -924,8 +1940,8 @@ const goUnmarshalField = `{{if eq .Type "bool"}}
 		i++
 	}
  {{- else}}
-		if x > uint(ColferSizeMax) {
-			return 0, ColferMax(fmt.Sprintf("colfer: {{.String}} size %d exceeds %d bytes", x, ColferSizeMax))
+		if x > uint({{or .SizeMax "limits.SizeMax"}}) {
+			return 0, ColferMax(fmt.Sprintf("colfer: {{.String}} size %d exceeds %d bytes", x, {{or .SizeMax "limits.SizeMax"}}))
 		}
 
 		start := i
@@ -933,7 +1949,11 @@ const goUnmarshalField = `{{if eq .Type "bool"}}
 		if i >= len(data) {
 			goto eof
 		}
+{{- if .Struct.Pkg.ZeroCopy}}
+		o.{{.NameTitle}} = colferString(data[start:i])
+{{- else}}
 		o.{{.NameTitle}} = string(data[start:i])
+{{- end}}
 
 		header = data[i]
 		i++
@@ -943,42 +1963,49 @@ const goUnmarshalField = `{{if eq .Type "bool"}}
 	if header == {{.Index}} {
 {{template "unmarshal-varint" .}}
  {{- if not .TypeList}}
-		if x > uint(ColferSizeMax) {
-			return 0, ColferMax(fmt.Sprintf("colfer: {{.String}} size %d exceeds %d bytes", x, ColferSizeMax))
+		if x > uint({{or .SizeMax "limits.SizeMax"}}) {
+			return 0, ColferMax(fmt.Sprintf("colfer: {{.String}} size %d exceeds %d bytes", x, {{or .SizeMax "limits.SizeMax"}}))
 		}
-		v := make([]byte, int(x))
 
 		start := i
-		i += len(v)
+		i += int(x)
 		if i >= len(data) {
 			goto eof
 		}
+  {{- if .Struct.Pkg.ZeroCopy}}
+		o.{{.NameTitle}} = data[start:i:i]
+  {{- else}}
+		v := make([]byte, int(x))
 		copy(v, data[start:i])
 		o.{{.NameTitle}} = v
+  {{- end}}
 
 		header = data[i]
 		i++
  {{- else}}
-		if x > uint(ColferListMax) {
-			return 0, ColferMax(fmt.Sprintf("colfer: {{.String}} length %d exceeds %d elements", x, ColferListMax))
+		if x > uint({{or .ListMax "limits.ListMax"}}) {
+			return 0, ColferMax(fmt.Sprintf("colfer: {{.String}} length %d exceeds %d elements", x, {{or .ListMax "limits.ListMax"}}))
 		}
 		a := make([][]byte, int(x))
 		o.{{.NameTitle}} = a
 		for ai := range a {
 {{template "unmarshal-varint" .}}
-			if x > uint(ColferSizeMax) {
-				return 0, ColferMax(fmt.Sprintf("colfer: {{.String}} element %d size %d exceeds %d bytes", ai, x, ColferSizeMax))
+			if x > uint({{or .SizeMax "limits.SizeMax"}}) {
+				return 0, ColferMax(fmt.Sprintf("colfer: {{.String}} element %d size %d exceeds %d bytes", ai, x, {{or .SizeMax "limits.SizeMax"}}))
 			}
-			v := make([]byte, int(x))
 
 			start := i
-			i += len(v)
+			i += int(x)
 			if i >= len(data) {
 				goto eof
 			}
-
+  {{- if .Struct.Pkg.ZeroCopy}}
+			a[ai] = data[start:i:i]
+  {{- else}}
+			v := make([]byte, int(x))
 			copy(v, data[start:i])
 			a[ai] = v
+  {{- end}}
 		}
 
 		if i >= len(data) {
@@ -991,8 +2018,8 @@ const goUnmarshalField = `{{if eq .Type "bool"}}
 {{else if .TypeList}}
 	if header == {{.Index}} {
 {{template "unmarshal-varint" .}}
-		if x > uint(ColferListMax) {
-			return 0, ColferMax(fmt.Sprintf("colfer: {{.String}} length %d exceeds %d elements", x, ColferListMax))
+		if x > uint({{or .ListMax "limits.ListMax"}}) {
+			return 0, ColferMax(fmt.Sprintf("colfer: {{.String}} length %d exceeds %d elements", x, {{or .ListMax "limits.ListMax"}}))
 		}
 
 		l := int(x)
@@ -1002,10 +2029,10 @@ const goUnmarshalField = `{{if eq .Type "bool"}}
 			v := &malloc[ai]
 			a[ai] = v
 
-			n, err := v.Unmarshal(data[i:])
+			n, err := v.UnmarshalWith(data[i:], {{template "limits-arg" .}})
 			if err != nil {
-				if err == io.EOF && len(data) >= ColferSizeMax {
-					return 0, ColferMax(fmt.Sprintf("colfer: {{.Struct.String}} size exceeds %d bytes", ColferSizeMax))
+				if err == io.EOF && len(data) >= limits.SizeMax {
+					return 0, ColferMax(fmt.Sprintf("colfer: {{.Struct.String}} size exceeds %d bytes", limits.SizeMax))
 				}
 				return 0, err
 			}
@@ -1022,10 +2049,10 @@ const goUnmarshalField = `{{if eq .Type "bool"}}
 {{else}}
 	if header == {{.Index}} {
 		o.{{.NameTitle}} = new({{.TypeNative}})
-		n, err := o.{{.NameTitle}}.Unmarshal(data[i:])
+		n, err := o.{{.NameTitle}}.UnmarshalWith(data[i:], {{template "limits-arg" .}})
 		if err != nil {
-			if err == io.EOF && len(data) >= ColferSizeMax {
-				return 0, ColferMax(fmt.Sprintf("colfer: {{.Struct.String}} size exceeds %d bytes", ColferSizeMax))
+			if err == io.EOF && len(data) >= limits.SizeMax {
+				return 0, ColferMax(fmt.Sprintf("colfer: {{.Struct.String}} size exceeds %d bytes", limits.SizeMax))
 			}
 			return 0, err
 		}
@@ -1062,3 +2089,1586 @@ const goUnmarshalVarint = `		if i >= len(data) {
 			}
 		}
 `
+
+const goMarshalMap = `
+	if l := len(o.{{.NameTitle}}); l != 0 {
+		buf[i] = {{.Index}}
+		i++
+		x := uint(l)
+		for x >= 0x80 {
+			buf[i] = byte(x | 0x80)
+			x >>= 7
+			i++
+		}
+		buf[i] = byte(x)
+		i++
+
+		keys := make([]{{.TypeKeyNative}}, 0, l)
+		for k := range o.{{.NameTitle}} {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(a, b int) bool { return keys[a] < keys[b] })
+		for _, k := range keys {
+			v := o.{{.NameTitle}}[k]
+{{- if eq .TypeKey "text"}}
+			x = uint(len(k))
+			for x >= 0x80 {
+				buf[i] = byte(x | 0x80)
+				x >>= 7
+				i++
+			}
+			buf[i] = byte(x)
+			i++
+			i += copy(buf[i:], k)
+{{- else if eq .TypeKey "uint8"}}
+			buf[i] = k
+			i++
+{{- else}}
+			kx := {{if eq .TypeKey "int32"}}uint32(k<<1) ^ uint32(k>>31){{else if eq .TypeKey "int64"}}uint64(k<<1) ^ uint64(k>>63){{else if eq .TypeKey "uint64"}}k{{else}}uint32(k){{end}}
+			{{if eq .TypeKey "uint64" "int64"}}for n := 0; kx >= 0x80 && n < 8; n++ {{"{"}}{{else}}for kx >= 0x80 {{"{"}}{{end}}
+				buf[i] = byte(kx | 0x80)
+				kx >>= 7
+				i++
+			}
+			buf[i] = byte(kx)
+			i++
+{{- end}}{{- if eq .Type "bool"}}
+			buf[i] = 0
+			if v {
+				buf[i] = 1
+			}
+			i++
+{{- else if eq .Type "uint8"}}
+			buf[i] = byte(v)
+			i++
+{{- else if eq .Type "uint16" "uint32" "uint64" "int32" "int64"}}
+			vx := {{if eq .Type "int32"}}uint32(v<<1) ^ uint32(v>>31){{else if eq .Type "int64"}}uint64(v<<1) ^ uint64(v>>63){{else if eq .Type "uint64"}}uint64(v){{else}}uint32(v){{end}}
+			{{if eq .Type "uint64" "int64"}}for n := 0; vx >= 0x80 && n < 8; n++ {{"{"}}{{else}}for vx >= 0x80 {{"{"}}{{end}}
+				buf[i] = byte(vx | 0x80)
+				vx >>= 7
+				i++
+			}
+			buf[i] = byte(vx)
+			i++
+{{- else if eq .Type "float32"}}
+			intconv.PutUint32(buf[i:], math.Float32bits(v))
+			i += 4
+{{- else if eq .Type "float64"}}
+			intconv.PutUint64(buf[i:], math.Float64bits(v))
+			i += 8
+{{- else if eq .Type "text" "binary"}}
+			x = uint(len(v))
+			for x >= 0x80 {
+				buf[i] = byte(x | 0x80)
+				x >>= 7
+				i++
+			}
+			buf[i] = byte(x)
+			i++
+			i += copy(buf[i:], v)
+{{- else}}
+			if v == nil {
+				v = new({{.TypeNative}})
+				o.{{.NameTitle}}[k] = v
+			}
+			i += v.MarshalTo(buf[i:])
+{{- end}}
+		}
+	}
+`
+
+const goMarshalMapLen = `
+	if x := len(o.{{.NameTitle}}); x != 0 {
+		if x > {{or .ListMax "limits.ListMax"}} {
+			return 0, ColferMax(fmt.Sprintf("colfer: field {{.String}} exceeds %d elements", {{or .ListMax "limits.ListMax"}}))
+		}
+		for l += 2; x >= 0x80; l++ {
+			x >>= 7
+		}
+{{- if and (eq .TypeKey "uint8") (eq .Type "bool" "uint8")}}
+		l += len(o.{{.NameTitle}}) * 2
+{{- else}}
+		for {{if eq .TypeKey "uint8"}}_{{else}}k{{end}}, {{if eq .Type "bool" "uint8"}}_{{else}}v{{end}} := range o.{{.NameTitle}} {
+{{- if eq .TypeKey "text"}}
+			kx := len(k)
+			if kx > {{or .SizeMax "limits.SizeMax"}} {
+				return 0, ColferMax(fmt.Sprintf("colfer: field {{.String}} key exceeds %d bytes", {{or .SizeMax "limits.SizeMax"}}))
+			}
+			for l += kx + 1; kx >= 0x80; l++ {
+				kx >>= 7
+			}
+{{- else if eq .TypeKey "uint8"}}
+			l++
+{{- else}}
+			kx := {{if eq .TypeKey "int32"}}uint32(k<<1) ^ uint32(k>>31){{else if eq .TypeKey "int64"}}uint64(k<<1) ^ uint64(k>>63){{else if eq .TypeKey "uint64"}}k{{else}}uint32(k){{end}}
+			{{if eq .TypeKey "uint64" "int64"}}for n := 0; kx >= 0x80 && n < 8; n++ {{"{"}}{{else}}for kx >= 0x80 {{"{"}}{{end}}
+				kx >>= 7
+				l++
+			}
+			l++
+{{- end}}{{- if eq .Type "bool" "uint8"}}
+			l++
+{{- else if eq .Type "uint16" "uint32" "uint64" "int32" "int64"}}
+			vx := {{if eq .Type "int32"}}uint32(v<<1) ^ uint32(v>>31){{else if eq .Type "int64"}}uint64(v<<1) ^ uint64(v>>63){{else if eq .Type "uint64"}}uint64(v){{else}}uint32(v){{end}}
+			{{if eq .Type "uint64" "int64"}}for n := 0; vx >= 0x80 && n < 8; n++ {{"{"}}{{else}}for vx >= 0x80 {{"{"}}{{end}}
+				vx >>= 7
+				l++
+			}
+			l++
+{{- else if eq .Type "float32"}}
+			l += 4
+{{- else if eq .Type "float64"}}
+			l += 8
+{{- else if eq .Type "text" "binary"}}
+			vx := len(v)
+			if vx > {{or .SizeMax "limits.SizeMax"}} {
+				return 0, ColferMax(fmt.Sprintf("colfer: field {{.String}} value exceeds %d bytes", {{or .SizeMax "limits.SizeMax"}}))
+			}
+			for l += vx + 1; vx >= 0x80; l++ {
+				vx >>= 7
+			}
+{{- else}}
+			if v == nil {
+				l++
+{{- if eq .TypeRef.String .Struct.String}}
+			} else if v == o {
+				return 0, ColferDepth("colfer: field {{.String}} makes a reference cycle")
+{{- end}}
+			} else {
+				vl, err := v.MarshalLenWith({{template "limits-arg" .}})
+				if err != nil {
+					return 0, err
+				}
+				l += vl
+			}
+{{- end}}
+		}
+		if l > limits.SizeMax {
+			return 0, ColferMax(fmt.Sprintf("colfer: struct {{.Struct.String}} size exceeds %d bytes", limits.SizeMax))
+		}
+{{- end}}
+	}
+`
+
+const goUnmarshalMap = `
+	if header == {{.Index}} {
+{{template "unmarshal-varint" .}}
+		if x > uint({{or .ListMax "limits.ListMax"}}) {
+			return 0, ColferMax(fmt.Sprintf("colfer: {{.String}} length %d exceeds %d elements", x, {{or .ListMax "limits.ListMax"}}))
+		}
+		l := int(x)
+
+		if end := i + l*2; end >= len(data) {
+			i = end
+			goto eof
+		}
+		m := make(map[{{.TypeKeyNative}}]{{if .TypeRef}}*{{end}}{{.TypeNative}}, l)
+		var last {{.TypeKeyNative}}
+		for mi := 0; mi < l; mi++ {
+			start := i
+			var k {{.TypeKeyNative}}
+{{- if eq .TypeKey "text"}}
+			{
+{{template "unmarshal-varint" .}}
+				if x > uint({{or .SizeMax "limits.SizeMax"}}) {
+					return 0, ColferMax(fmt.Sprintf("colfer: {{.String}} key size %d exceeds %d bytes", x, {{or .SizeMax "limits.SizeMax"}}))
+				}
+				end := i + int(x)
+				if end >= len(data) {
+					i = end
+					goto eof
+				}
+				k = string(data[i:end])
+				i = end
+			}
+{{- else if eq .TypeKey "uint8"}}
+			if i >= len(data) {
+				goto eof
+			}
+			k = data[i]
+			i++
+{{- else}}
+			{
+{{template "unmarshal-varint64" .}}
+{{- if eq .TypeKey "int32"}}
+				k = int32(x>>1) ^ -int32(x&1)
+{{- else if eq .TypeKey "int64"}}
+				k = int64(x>>1) ^ -int64(x&1)
+{{- else}}
+				k = {{.TypeKeyNative}}(x)
+{{- end}}
+			}
+{{- end}}
+			if mi != 0 && k <= last {
+				return 0, ColferError(start)
+			}
+			last = k
+
+			var v {{if .TypeRef}}*{{end}}{{.TypeNative}}
+{{- if eq .Type "bool"}}
+			if i >= len(data) {
+				goto eof
+			}
+			v = data[i] != 0
+			i++
+{{- else if eq .Type "uint8"}}
+			if i >= len(data) {
+				goto eof
+			}
+			v = {{.TypeNative}}(data[i])
+			i++
+{{- else if eq .Type "uint16" "uint32" "uint64" "int32" "int64"}}
+			{
+{{template "unmarshal-varint64" .}}
+{{- if eq .Type "int32"}}
+				v = int32(x>>1) ^ -int32(x&1)
+{{- else if eq .Type "int64"}}
+				v = int64(x>>1) ^ -int64(x&1)
+{{- else}}
+				v = {{.TypeNative}}(x)
+{{- end}}
+			}
+{{- else if eq .Type "float32"}}
+			if i+4 > len(data) {
+				i += 4
+				goto eof
+			}
+			v = math.Float32frombits(intconv.Uint32(data[i:]))
+			i += 4
+{{- else if eq .Type "float64"}}
+			if i+8 > len(data) {
+				i += 8
+				goto eof
+			}
+			v = math.Float64frombits(intconv.Uint64(data[i:]))
+			i += 8
+{{- else if eq .Type "text" "binary"}}
+			{
+{{template "unmarshal-varint" .}}
+				if x > uint({{or .SizeMax "limits.SizeMax"}}) {
+					return 0, ColferMax(fmt.Sprintf("colfer: {{.String}} value size %d exceeds %d bytes", x, {{or .SizeMax "limits.SizeMax"}}))
+				}
+				start := i
+				i += int(x)
+				if i >= len(data) {
+					goto eof
+				}
+{{- if and (eq .Type "text") .Struct.Pkg.ZeroCopy}}
+				v = colferString(data[start:i])
+{{- else if eq .Type "text"}}
+				v = string(data[start:i])
+{{- else if .Struct.Pkg.ZeroCopy}}
+				v = data[start:i:i]
+{{- else}}
+				v = make([]byte, int(x))
+				copy(v, data[start:i])
+{{- end}}
+			}
+{{- else}}
+			v = new({{.TypeNative}})
+			n, err := v.UnmarshalWith(data[i:], {{template "limits-arg" .}})
+			if err != nil {
+				if err == io.EOF && len(data) >= limits.SizeMax {
+					return 0, ColferMax(fmt.Sprintf("colfer: {{.Struct.String}} size exceeds %d bytes", limits.SizeMax))
+				}
+				return 0, err
+			}
+			i += n
+{{- end}}
+			m[k] = v
+		}
+		o.{{.NameTitle}} = m
+
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+`
+
+const goUnmarshalVarint64 = `				if i >= len(data) {
+					goto eof
+				}
+				x := uint64(data[i])
+				i++
+
+				if x >= 0x80 {
+					x &= 0x7f
+					for shift := uint(7); ; shift += 7 {
+						if i >= len(data) {
+							goto eof
+						}
+						b := uint64(data[i])
+						i++
+
+						if b < 0x80 || shift == 56 {
+							x |= b << shift
+							break
+						}
+						x |= (b & 0x7f) << shift
+					}
+				}
+`
+
+const goLimitsArg = `{{if ne .TypeNative .TypeRef.NameTitle}}{{.TypeRef.Pkg.NameNative}}.ColferLimits(limits){{else}}limits{{end}}`
+
+const goViewLimitsArg = `{{if ne .TypeNative .TypeRef.NameTitle}}{{.TypeRef.Pkg.NameNative}}.ColferLimits(v.limits){{else}}v.limits{{end}}`
+
+const goMarshalOptional = `
+	if v := o.{{.NameTitle}}; v != nil {
+ {{- if eq .Type "bool"}}
+		if *v {
+			buf[i] = {{.Index}}
+		} else {
+			buf[i] = {{.Index}} | 0x80
+		}
+		i++
+ {{- else if eq .Type "uint8"}}
+		buf[i] = {{.Index}}
+		buf[i+1] = {{if .TypeEnum}}{{.Type}}(*v){{else}}*v{{end}}
+		i += 2
+ {{- else if eq .Type "uint16"}}
+		if x := {{if .TypeEnum}}{{.Type}}(*v){{else}}*v{{end}}; x >= 1<<8 {
+			buf[i] = {{.Index}}
+			buf[i+1] = byte(x >> 8)
+			buf[i+2] = byte(x)
+			i += 3
+		} else {
+			buf[i] = {{.Index}} | 0x80
+			buf[i+1] = byte(x)
+			i += 2
+		}
+ {{- else if eq .Type "uint32" "uint64"}}
+		if x := {{if .TypeEnum}}{{.Type}}(*v){{else}}*v{{end}}; x >= {{if eq .Type "uint32"}}1<<21{{else}}1<<49{{end}} {
+			buf[i] = {{.Index}} | 0x80
+  {{- if eq .Type "uint32"}}
+			intconv.PutUint32(buf[i+1:], x)
+			i += 5
+  {{- else}}
+			intconv.PutUint64(buf[i+1:], x)
+			i += 9
+  {{- end}}
+		} else {
+			buf[i] = {{.Index}}
+			i++
+			for x >= 0x80 {
+				buf[i] = byte(x | 0x80)
+				x >>= 7
+				i++
+			}
+			buf[i] = byte(x)
+			i++
+		}
+ {{- else if eq .Type "int32" "int64"}}
+		x := {{if eq .Type "int32"}}uint32{{else}}uint64{{end}}(*v)
+		if *v >= 0 {
+			buf[i] = {{.Index}}
+		} else {
+			x = ^x + 1
+			buf[i] = {{.Index}} | 0x80
+		}
+		i++
+		{{if eq .Type "int64"}}for n := 0; x >= 0x80 && n < 8; n++ {{"{"}}{{else}}for x >= 0x80 {{"{"}}{{end}}
+			buf[i] = byte(x | 0x80)
+			x >>= 7
+			i++
+		}
+		buf[i] = byte(x)
+		i++
+ {{- else if eq .Type "float32"}}
+		buf[i] = {{.Index}}
+		intconv.PutUint32(buf[i+1:], math.Float32bits(*v))
+		i += 5
+ {{- else if eq .Type "float64"}}
+		buf[i] = {{.Index}}
+		intconv.PutUint64(buf[i+1:], math.Float64bits(*v))
+		i += 9
+ {{- end}}
+	}
+`
+
+const goMarshalOptionalLen = `
+	if v := o.{{.NameTitle}}; v != nil {
+ {{- if eq .Type "bool"}}
+		l++
+ {{- else if eq .Type "uint8"}}
+		l += 2
+ {{- else if eq .Type "uint16"}}
+		if *v >= 1<<8 {
+			l += 3
+		} else {
+			l += 2
+		}
+ {{- else if eq .Type "uint32" "uint64"}}
+		if x := {{if .TypeEnum}}{{.Type}}(*v){{else}}*v{{end}}; x >= {{if eq .Type "uint32"}}1<<21{{else}}1<<49{{end}} {
+			l += {{if eq .Type "uint32"}}5{{else}}9{{end}}
+		} else {
+			for l += 2; x >= 0x80; l++ {
+				x >>= 7
+			}
+		}
+ {{- else if eq .Type "int32"}}
+		x := uint32(*v)
+		if *v < 0 {
+			x = ^x + 1
+		}
+		for l += 2; x >= 0x80; l++ {
+			x >>= 7
+		}
+ {{- else if eq .Type "int64"}}
+		l += 2
+		x := uint64(*v)
+		if *v < 0 {
+			x = ^x + 1
+		}
+		for n := 0; x >= 0x80 && n < 8; n++ {
+			x >>= 7
+			l++
+		}
+ {{- else if eq .Type "float32"}}
+		l += 5
+ {{- else if eq .Type "float64"}}
+		l += 9
+ {{- end}}
+	}
+`
+
+const goMarshalUnion = `{{$pkg := ""}}{{if ne .TypeNative .TypeUnion.NameTitle}}{{$pkg = print .TypeUnion.Pkg.NameNative "."}}{{end}}
+	if v := o.{{.NameTitle}}; v != nil {
+		buf[i] = {{.Index}}
+		switch v := v.(type) {
+{{- range .TypeUnion.Members}}
+		case *{{$pkg}}{{.Struct.NameTitle}}:
+			if v == nil {
+				v = new({{$pkg}}{{.Struct.NameTitle}})
+				o.{{$.NameTitle}} = v
+			}
+			buf[i+1] = {{.Index}}
+			i += 2
+			i += v.MarshalTo(buf[i:])
+{{- end}}
+		}
+	}
+`
+
+const goMarshalUnionLen = `{{$pkg := ""}}{{if ne .TypeNative .TypeUnion.NameTitle}}{{$pkg = print .TypeUnion.Pkg.NameNative "."}}{{end}}{{$f := .}}
+	if v := o.{{.NameTitle}}; v != nil {
+		l += 2
+		switch v := v.(type) {
+{{- range .TypeUnion.Members}}
+		case *{{$pkg}}{{.Struct.NameTitle}}:
+			if v == nil {
+				v = new({{$pkg}}{{.Struct.NameTitle}})
+			}
+{{- if eq .Struct.String $f.Struct.String}}
+			if v == o {
+				return 0, ColferDepth("colfer: field {{$f.String}} makes a reference cycle")
+			}
+{{- end}}
+			vl, err := v.MarshalLenWith({{if $pkg}}{{$pkg}}ColferLimits(limits){{else}}limits{{end}})
+			if err != nil {
+				return 0, err
+			}
+			l += vl
+{{- end}}
+		}
+	}
+`
+
+const goUnmarshalUnion = `{{$pkg := ""}}{{if ne .TypeNative .TypeUnion.NameTitle}}{{$pkg = print .TypeUnion.Pkg.NameNative "."}}{{end}}
+	if header == {{.Index}} {
+		if i >= len(data) {
+			goto eof
+		}
+		var n int
+		var err error
+		switch data[i] {
+{{- range .TypeUnion.Members}}
+		case {{.Index}}:
+			v := new({{$pkg}}{{.Struct.NameTitle}})
+			o.{{$.NameTitle}} = v
+			n, err = v.UnmarshalWith(data[i+1:], {{if $pkg}}{{$pkg}}ColferLimits(limits){{else}}limits{{end}})
+{{- end}}
+		default:
+			return 0, ColferError(i)
+		}
+		if err != nil {
+			if err == io.EOF && len(data) >= limits.SizeMax {
+				return 0, ColferMax(fmt.Sprintf("colfer: {{.Struct.String}} size exceeds %d bytes", limits.SizeMax))
+			}
+			return 0, err
+		}
+		i += n + 1
+
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+`
+
+const goViewIndex = `{{if .TypeKey}}
+	if header == {{.Index}} {
+		index[{{.Index}}] = i
+{{template "unmarshal-varint" .}}
+		if x > uint({{or .ListMax "limits.ListMax"}}) {
+			return 0, ColferMax(fmt.Sprintf("colfer: {{.String}} length %d exceeds %d elements", x, {{or .ListMax "limits.ListMax"}}))
+		}
+		l := int(x)
+
+		if end := i + l*2; end >= len(data) {
+			i = end
+			goto eof
+		}
+{{- if eq .TypeKey "text"}}
+		var last []byte
+{{- else}}
+		var last {{.TypeKeyNative}}
+{{- end}}
+		for mi := 0; mi < l; mi++ {
+			start := i
+{{- if eq .TypeKey "text"}}
+			var k []byte
+			{
+{{template "unmarshal-varint" .}}
+				if x > uint({{or .SizeMax "limits.SizeMax"}}) {
+					return 0, ColferMax(fmt.Sprintf("colfer: {{.String}} key size %d exceeds %d bytes", x, {{or .SizeMax "limits.SizeMax"}}))
+				}
+				end := i + int(x)
+				if end >= len(data) {
+					i = end
+					goto eof
+				}
+				k = data[i:end]
+				i = end
+			}
+			if mi != 0 && string(k) <= string(last) {
+				return 0, ColferError(start)
+			}
+{{- else}}
+			var k {{.TypeKeyNative}}
+ {{- if eq .TypeKey "uint8"}}
+			if i >= len(data) {
+				goto eof
+			}
+			k = data[i]
+			i++
+ {{- else}}
+			{
+{{template "unmarshal-varint64" .}}
+  {{- if eq .TypeKey "int32"}}
+				k = int32(x>>1) ^ -int32(x&1)
+  {{- else if eq .TypeKey "int64"}}
+				k = int64(x>>1) ^ -int64(x&1)
+  {{- else}}
+				k = {{.TypeKeyNative}}(x)
+  {{- end}}
+			}
+ {{- end}}
+			if mi != 0 && k <= last {
+				return 0, ColferError(start)
+			}
+{{- end}}
+			last = k
+{{- if eq .Type "bool" "uint8"}}
+
+			if i >= len(data) {
+				goto eof
+			}
+			i++
+{{- else if eq .Type "uint16" "uint32" "uint64" "int32" "int64"}}
+
+			{
+{{template "unmarshal-varint64" .}}
+				_ = x
+			}
+{{- else if eq .Type "float32" "float64"}}
+
+			if i+{{if eq .Type "float32"}}4{{else}}8{{end}} > len(data) {
+				i += {{if eq .Type "float32"}}4{{else}}8{{end}}
+				goto eof
+			}
+			i += {{if eq .Type "float32"}}4{{else}}8{{end}}
+{{- else if eq .Type "text" "binary"}}
+
+			{
+{{template "unmarshal-varint" .}}
+				if x > uint({{or .SizeMax "limits.SizeMax"}}) {
+					return 0, ColferMax(fmt.Sprintf("colfer: {{.String}} value size %d exceeds %d bytes", x, {{or .SizeMax "limits.SizeMax"}}))
+				}
+				i += int(x)
+				if i >= len(data) {
+					goto eof
+				}
+			}
+{{- else}}
+
+			var e {{.TypeNative}}View
+			n, err := e.UnmarshalWith(data[i:], {{template "limits-arg" .}})
+			if err != nil {
+				if err == io.EOF && len(data) >= limits.SizeMax {
+					return 0, ColferMax(fmt.Sprintf("colfer: {{.Struct.String}} size exceeds %d bytes", limits.SizeMax))
+				}
+				return 0, err
+			}
+			i += n
+{{- end}}
+		}
+
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+{{else if .TypeUnion}}{{$pkg := ""}}{{if ne .TypeNative .TypeUnion.NameTitle}}{{$pkg = print .TypeUnion.Pkg.NameNative "."}}{{end}}
+	if header == {{.Index}} {
+		index[{{.Index}}] = i
+		if i >= len(data) {
+			goto eof
+		}
+		var n int
+		var err error
+		switch data[i] {
+{{- range .TypeUnion.Members}}
+		case {{.Index}}:
+			var e {{$pkg}}{{.Struct.NameTitle}}View
+			n, err = e.UnmarshalWith(data[i+1:], {{if $pkg}}{{$pkg}}ColferLimits(limits){{else}}limits{{end}})
+{{- end}}
+		default:
+			return 0, ColferError(i)
+		}
+		if err != nil {
+			if err == io.EOF && len(data) >= limits.SizeMax {
+				return 0, ColferMax(fmt.Sprintf("colfer: {{.Struct.String}} size exceeds %d bytes", limits.SizeMax))
+			}
+			return 0, err
+		}
+		i += n + 1
+
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+{{else if and .TypeList (eq .Type "bool" "uint8")}}
+	if header == {{.Index}} {
+		index[{{.Index}}] = i
+{{template "unmarshal-varint" .}}
+		if x > uint({{or .ListMax "limits.ListMax"}}) {
+			return 0, ColferMax(fmt.Sprintf("colfer: {{.String}} length %d exceeds %d elements", x, {{or .ListMax "limits.ListMax"}}))
+		}
+		i += int(x)
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+{{else if and .TypeList (eq .Type "uint16" "uint32" "uint64" "int32" "int64")}}
+	if header == {{.Index}} {
+		index[{{.Index}}] = i
+{{template "unmarshal-varint" .}}
+		if x > uint({{or .ListMax "limits.ListMax"}}) {
+			return 0, ColferMax(fmt.Sprintf("colfer: {{.String}} length %d exceeds %d elements", x, {{or .ListMax "limits.ListMax"}}))
+		}
+		l := int(x)
+
+		if end := i + l; end >= len(data) {
+			i = end
+			goto eof
+		}
+		for ai := 0; ai < l; ai++ {
+			if i >= len(data) {
+				goto eof
+			}
+			x := data[i]
+			i++
+
+			if x >= 0x80 {
+				for shift := uint(7); ; shift += 7 {
+					if i >= len(data) {
+						goto eof
+					}
+					b := data[i]
+					i++
+
+					if b < 0x80 || shift == 56 {
+						break
+					}
+				}
+			}
+		}
+
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+{{else if and .TypeList (eq .Type "float32" "float64")}}
+	if header == {{.Index}} {
+		index[{{.Index}}] = i
+{{template "unmarshal-varint" .}}
+		if x > uint({{or .ListMax "limits.ListMax"}}) {
+			return 0, ColferMax(fmt.Sprintf("colfer: {{.String}} length %d exceeds %d elements", x, {{or .ListMax "limits.ListMax"}}))
+		}
+		i += int(x) * {{if eq .Type "float32"}}4{{else}}8{{end}}
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+{{else if and .TypeList (eq .Type "text" "binary")}}
+	if header == {{.Index}} {
+		index[{{.Index}}] = i
+{{template "unmarshal-varint" .}}
+		if x > uint({{or .ListMax "limits.ListMax"}}) {
+			return 0, ColferMax(fmt.Sprintf("colfer: {{.String}} length %d exceeds %d elements", x, {{or .ListMax "limits.ListMax"}}))
+		}
+		for ai, l := 0, int(x); ai < l; ai++ {
+{{template "unmarshal-varint" .}}
+			if x > uint({{or .SizeMax "limits.SizeMax"}}) {
+				return 0, ColferMax(fmt.Sprintf("colfer: {{.String}} element %d size %d exceeds %d bytes", ai, x, {{or .SizeMax "limits.SizeMax"}}))
+			}
+
+			i += int(x)
+			if i >= len(data) {
+				goto eof
+			}
+		}
+
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+{{else if .TypeList}}
+	if header == {{.Index}} {
+		index[{{.Index}}] = i
+{{template "unmarshal-varint" .}}
+		if x > uint({{or .ListMax "limits.ListMax"}}) {
+			return 0, ColferMax(fmt.Sprintf("colfer: {{.String}} length %d exceeds %d elements", x, {{or .ListMax "limits.ListMax"}}))
+		}
+
+		for ai, l := 0, int(x); ai < l; ai++ {
+			var e {{.TypeNative}}View
+			n, err := e.UnmarshalWith(data[i:], {{template "limits-arg" .}})
+			if err != nil {
+				if err == io.EOF && len(data) >= limits.SizeMax {
+					return 0, ColferMax(fmt.Sprintf("colfer: {{.Struct.String}} size exceeds %d bytes", limits.SizeMax))
+				}
+				return 0, err
+			}
+			i += n
+		}
+
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+{{else if eq .Type "bool"}}
+	if header == {{.Index}}{{if .Optional}} || header == {{.Index}}|0x80{{end}} {
+		index[{{.Index}}] = i
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+{{else if eq .Type "uint32" "uint64" "int32" "int64"}}
+	if header == {{.Index}}{{if eq .Type "int32" "int64"}} || header == {{.Index}}|0x80{{end}} {
+		index[{{.Index}}] = i
+{{- if eq .Type "int32" "int64"}}
+		if i+1 >= len(data) {
+			i++
+			goto eof
+		}
+		x := data[i]
+		i++
+{{- else}}
+		start := i
+		i++
+		if i >= len(data) {
+			goto eof
+		}
+		x := data[start]
+{{- end}}
+
+		if x >= 0x80 {
+			for shift := uint(7); ; shift += 7 {
+				b := data[i]
+				i++
+				if i >= len(data) {
+					goto eof
+				}
+
+				if b < 0x80{{if eq .Type "uint64" "int64"}} || shift == 56{{end}} {
+					break
+				}
+			}
+		}
+
+		header = data[i]
+		i++
+	}
+{{- if eq .Type "uint32" "uint64"}} else if header == {{.Index}}|0x80 {
+		index[{{.Index}}] = i
+		i += {{if eq .Type "uint32"}}4{{else}}8{{end}}
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+{{- end}}
+{{else if eq .Type "text" "binary"}}
+	if header == {{.Index}} {
+		index[{{.Index}}] = i
+{{template "unmarshal-varint" .}}
+		if x > uint({{or .SizeMax "limits.SizeMax"}}) {
+			return 0, ColferMax(fmt.Sprintf("colfer: {{.String}} size %d exceeds %d bytes", x, {{or .SizeMax "limits.SizeMax"}}))
+		}
+
+		i += int(x)
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+{{else if .TypeRef}}
+	if header == {{.Index}} {
+		index[{{.Index}}] = i
+		var e {{.TypeNative}}View
+		n, err := e.UnmarshalWith(data[i:], {{template "limits-arg" .}})
+		if err != nil {
+			if err == io.EOF && len(data) >= limits.SizeMax {
+				return 0, ColferMax(fmt.Sprintf("colfer: {{.Struct.String}} size exceeds %d bytes", limits.SizeMax))
+			}
+			return 0, err
+		}
+		i += n
+
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+{{else}}
+	if header == {{.Index}}{{if eq .Type "uint16" "timestamp"}} || header == {{.Index}}|0x80{{end}} {
+		index[{{.Index}}] = i
+{{- if eq .Type "uint16"}}
+		if header == {{.Index}} {
+			i += 2
+		} else {
+			i++
+		}
+{{- else if eq .Type "timestamp"}}
+		if header == {{.Index}} {
+			i += 8
+		} else {
+			i += 12
+		}
+{{- else}}
+		i += {{if eq .Type "uint8"}}1{{else if eq .Type "float32"}}4{{else}}8{{end}}
+{{- end}}
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+{{end}}`
+
+const goViewGet = `{{$view := print .Struct.NameTitle "View"}}
+{{- if .TypeKey}}
+// {{.NameTitle}} decodes field {{.Name}} into a new map, or nil when absent.
+func (v *{{$view}}) {{.NameTitle}}() map[{{.TypeKeyNative}}]{{.TypeNative}}{{if .TypeRef}}View{{end}} {
+	i := v.index[{{.Index}}]
+	if i == 0 {
+		return nil
+	}
+	x, i := colferVarint(v.data, i, false)
+	l := int(x)
+	m := make(map[{{.TypeKeyNative}}]{{.TypeNative}}{{if .TypeRef}}View{{end}}, l)
+	for mi := 0; mi < l; mi++ {
+		var k {{.TypeKeyNative}}{{template "view-map-key" .}}
+
+		var e {{.TypeNative}}{{if .TypeRef}}View{{end}}
+{{- if eq .Type "bool"}}
+		e = v.data[i] != 0
+		i++
+{{- else if eq .Type "uint8"}}
+		e = {{.TypeNative}}(v.data[i])
+		i++
+{{- else if eq .Type "uint16" "uint32" "uint64" "int32" "int64"}}
+		x, i = colferVarint(v.data, i, true)
+ {{- if eq .Type "int32"}}
+		e = int32(x>>1) ^ -int32(x&1)
+ {{- else if eq .Type "int64"}}
+		e = int64(x>>1) ^ -int64(x&1)
+ {{- else}}
+		e = {{.TypeNative}}(x)
+ {{- end}}
+{{- else if eq .Type "float32"}}
+		e = math.Float32frombits(intconv.Uint32(v.data[i:]))
+		i += 4
+{{- else if eq .Type "float64"}}
+		e = math.Float64frombits(intconv.Uint64(v.data[i:]))
+		i += 8
+{{- else if eq .Type "text"}}
+		x, i = colferVarint(v.data, i, false)
+ {{- if .Struct.Pkg.ZeroCopy}}
+		e = colferString(v.data[i : i+int(x)])
+ {{- else}}
+		e = string(v.data[i : i+int(x)])
+ {{- end}}
+		i += int(x)
+{{- else if eq .Type "binary"}}
+		x, i = colferVarint(v.data, i, false)
+		end := i + int(x)
+		e = v.data[i:end:end]
+		i = end
+{{- else}}
+		n, _ := e.UnmarshalWith(v.data[i:], {{template "view-limits-arg" .}})
+		i += n
+{{- end}}
+		m[k] = e
+	}
+	return m
+}
+{{else if .TypeUnion}}
+{{- $pkg := ""}}{{if ne .TypeNative .TypeUnion.NameTitle}}{{$pkg = print .TypeUnion.Pkg.NameNative "."}}{{end}}
+// {{.NameTitle}} returns field {{.Name}} as a new
+{{- range $n, $m := .TypeUnion.Members}}{{if $n}} or{{end}} *{{$pkg}}{{$m.Struct.NameTitle}}View{{end}},
+// or nil when absent.
+func (v *{{$view}}) {{.NameTitle}}() interface{} {
+	i := v.index[{{.Index}}]
+	if i == 0 {
+		return nil
+	}
+	switch v.data[i] {
+{{- range .TypeUnion.Members}}
+	case {{.Index}}:
+		e := new({{$pkg}}{{.Struct.NameTitle}}View)
+		e.UnmarshalWith(v.data[i+1:], {{if $pkg}}{{$pkg}}ColferLimits(v.limits){{else}}v.limits{{end}})
+		return e
+{{- end}}
+	}
+	return nil
+}
+{{else if .TypeList}}
+// {{.NameTitle}} {{if .TypeRef}}returns a view per element of{{else}}decodes{{end}} field {{.Name}}{{if not .TypeRef}} into a new slice{{end}}, or nil when absent.
+func (v *{{$view}}) {{.NameTitle}}() []{{.TypeNative}}{{if .TypeRef}}View{{end}} {
+	i := v.index[{{.Index}}]
+	if i == 0 {
+		return nil
+	}
+	x, i := colferVarint(v.data, i, false)
+	a := make([]{{.TypeNative}}{{if .TypeRef}}View{{end}}, int(x))
+{{- if and (eq .Type "uint8") (not .TypeEnum)}}
+	copy(a, v.data[i:])
+{{- else}}
+	for ai := range a {
+ {{- if eq .Type "bool"}}
+		a[ai] = v.data[i] != 0
+		i++
+ {{- else if eq .Type "uint8"}}
+		a[ai] = {{.TypeNative}}(v.data[i])
+		i++
+ {{- else if eq .Type "uint16" "uint32" "uint64" "int32" "int64"}}
+		x, i = colferVarint(v.data, i, true)
+  {{- if eq .Type "int32"}}
+		a[ai] = int32(x>>1) ^ -int32(x&1)
+  {{- else if eq .Type "int64"}}
+		a[ai] = int64(x>>1) ^ -int64(x&1)
+  {{- else}}
+		a[ai] = {{.TypeNative}}(x)
+  {{- end}}
+ {{- else if eq .Type "float32"}}
+		a[ai] = math.Float32frombits(intconv.Uint32(v.data[i:]))
+		i += 4
+ {{- else if eq .Type "float64"}}
+		a[ai] = math.Float64frombits(intconv.Uint64(v.data[i:]))
+		i += 8
+ {{- else if eq .Type "text"}}
+		x, i = colferVarint(v.data, i, false)
+  {{- if .Struct.Pkg.ZeroCopy}}
+		a[ai] = colferString(v.data[i : i+int(x)])
+  {{- else}}
+		a[ai] = string(v.data[i : i+int(x)])
+  {{- end}}
+		i += int(x)
+ {{- else if eq .Type "binary"}}
+		x, i = colferVarint(v.data, i, false)
+		end := i + int(x)
+		a[ai] = v.data[i:end:end]
+		i = end
+ {{- else}}
+		n, _ := a[ai].UnmarshalWith(v.data[i:], {{template "view-limits-arg" .}})
+		i += n
+ {{- end}}
+	}
+{{- end}}
+	return a
+}
+{{else if .TypeRef}}
+// {{.NameTitle}} returns a view of field {{.Name}}, with ok false when absent.
+func (v *{{$view}}) {{.NameTitle}}() (view {{.TypeNative}}View, ok bool) {
+	i := v.index[{{.Index}}]
+	if i == 0 {
+		return view, false
+	}
+	view.UnmarshalWith(v.data[i:], {{template "view-limits-arg" .}})
+	return view, true
+}
+{{else if eq .Type "text" "binary" "timestamp"}}
+// {{.NameTitle}} returns the value of field {{.Name}}, or the zero value when absent.
+func (v *{{$view}}) {{.NameTitle}}() {{.TypeNative}} {
+	i := v.index[{{.Index}}]
+	if i == 0 {
+		return {{if eq .Type "text"}}""{{else if eq .Type "binary"}}nil{{else}}time.Time{}{{end}}
+	}
+{{- if eq .Type "timestamp"}}
+	if v.data[i-1] == {{.Index}} {
+		return time.Unix(int64(intconv.Uint32(v.data[i:])), int64(intconv.Uint32(v.data[i+4:]))).In(time.UTC)
+	}
+	return time.Unix(int64(intconv.Uint64(v.data[i:])), int64(intconv.Uint32(v.data[i+8:]))).In(time.UTC)
+{{- else}}
+	x, i := colferVarint(v.data, i, false)
+	end := i + int(x)
+ {{- if eq .Type "binary"}}
+	return v.data[i:end:end]
+ {{- else if .Struct.Pkg.ZeroCopy}}
+	return colferString(v.data[i:end])
+ {{- else}}
+	return string(v.data[i:end])
+ {{- end}}
+{{- end}}
+}
+{{else if and (eq .Type "bool") (not .Optional)}}
+// {{.NameTitle}} returns the value of field {{.Name}}.
+func (v *{{$view}}) {{.NameTitle}}() bool {
+	return v.index[{{.Index}}] != 0
+}
+{{else}}
+{{- if .Optional}}
+// {{.NameTitle}} returns the value of field {{.Name}}, with ok false when absent.
+func (v *{{$view}}) {{.NameTitle}}() (value {{.TypeNative}}, ok bool) {
+{{- else}}
+// {{.NameTitle}} returns the value of field {{.Name}}, or the zero value when absent.
+func (v *{{$view}}) {{.NameTitle}}() {{.TypeNative}} {
+{{- end}}
+	i := v.index[{{.Index}}]
+	if i == 0 {
+		return {{if eq .Type "bool"}}false{{else}}0{{end}}{{if .Optional}}, false{{end}}
+	}
+{{- if eq .Type "bool"}}
+	return v.data[i-1] == {{.Index}}, true
+{{- else if eq .Type "uint8"}}
+	return {{if .TypeEnum}}{{.TypeNative}}(v.data[i]){{else}}v.data[i]{{end}}{{if .Optional}}, true{{end}}
+{{- else if eq .Type "uint16"}}
+	if v.data[i-1] == {{.Index}} {
+		return {{if .TypeEnum}}{{.TypeNative}}(intconv.Uint16(v.data[i:])){{else}}intconv.Uint16(v.data[i:]){{end}}{{if .Optional}}, true{{end}}
+	}
+	return {{.TypeNative}}(v.data[i]){{if .Optional}}, true{{end}}
+{{- else if eq .Type "uint32" "uint64"}}
+	if v.data[i-1] != {{.Index}} {
+  {{- if .TypeEnum}}
+		return {{.TypeNative}}(intconv.{{if eq .Type "uint32"}}Uint32{{else}}Uint64{{end}}(v.data[i:])){{if .Optional}}, true{{end}}
+  {{- else}}
+		return intconv.{{if eq .Type "uint32"}}Uint32{{else}}Uint64{{end}}(v.data[i:]){{if .Optional}}, true{{end}}
+  {{- end}}
+	}
+	x, _ := colferVarint(v.data, i, {{eq .Type "uint64"}})
+	return {{if or .TypeEnum (eq .Type "uint32")}}{{.TypeNative}}(x){{else}}x{{end}}{{if .Optional}}, true{{end}}
+{{- else if eq .Type "int32" "int64"}}
+	x, _ := colferVarint(v.data, i, {{eq .Type "int64"}})
+	if v.data[i-1] != {{.Index}} {
+  {{- if eq .Type "int32"}}
+		return int32(^uint32(x) + 1){{if .Optional}}, true{{end}}
+  {{- else}}
+		return int64(^x + 1){{if .Optional}}, true{{end}}
+  {{- end}}
+	}
+	return {{.TypeNative}}(x){{if .Optional}}, true{{end}}
+{{- else if eq .Type "float32"}}
+	return math.Float32frombits(intconv.Uint32(v.data[i:])){{if .Optional}}, true{{end}}
+{{- else}}
+	return math.Float64frombits(intconv.Uint64(v.data[i:])){{if .Optional}}, true{{end}}
+{{- end}}
+}
+{{end}}`
+
+const goViewMapKey = `{{- if eq .TypeKey "text"}}
+		x, i = colferVarint(v.data, i, false)
+		k = string(v.data[i : i+int(x)])
+		i += int(x)
+{{- else if eq .TypeKey "uint8"}}
+		k = v.data[i]
+		i++
+{{- else}}
+		x, i = colferVarint(v.data, i, true)
+ {{- if eq .TypeKey "int32"}}
+		k = int32(x>>1) ^ -int32(x&1)
+ {{- else if eq .TypeKey "int64"}}
+		k = int64(x>>1) ^ -int64(x&1)
+ {{- else}}
+		k = {{.TypeKeyNative}}(x)
+ {{- end}}
+{{- end}}`
+
+const goMaskAdd = `
+		case "{{.Name}}":
+{{- if .TypeRef}}
+			if name == path {
+				m.whole[{{.Index}}] = true
+			} else {
+				if m.sub{{.Index}} == nil {
+					m.sub{{.Index}} = new({{.TypeNative}}Mask)
+				}
+				if err := m.sub{{.Index}}.Add(path[len(name)+1:]); err != nil {
+					return err
+				}
+			}
+{{- else}}
+			if name != path {
+				return fmt.Errorf("colfer: field {{.String}} has no fields to select with %q", path)
+			}
+			m.whole[{{.Index}}] = true
+{{- end}}`
+
+const goMaskField = `{{if .TypeUnion}}{{$pkg := ""}}{{if ne .TypeNative .TypeUnion.NameTitle}}{{$pkg = print .TypeUnion.Pkg.NameNative "."}}{{end}}
+	if i := v.index[{{.Index}}]; i != 0 && mask.whole[{{.Index}}] {
+		switch v.data[i] {
+{{- range .TypeUnion.Members}}
+		case {{.Index}}:
+			e := new({{$pkg}}{{.Struct.NameTitle}})
+			e.UnmarshalWith(v.data[i+1:], {{if $pkg}}{{$pkg}}ColferLimits(v.limits){{else}}v.limits{{end}})
+			o.{{$.NameTitle}} = e
+{{- end}}
+		}
+	}
+{{else if and .TypeKey .TypeRef}}
+	if v.index[{{.Index}}] != 0 && (mask.whole[{{.Index}}] || mask.sub{{.Index}} != nil) {
+		x, i := colferVarint(v.data, v.index[{{.Index}}], false)
+		l := int(x)
+		m := make(map[{{.TypeKeyNative}}]*{{.TypeNative}}, l)
+		for mi := 0; mi < l; mi++ {
+			var k {{.TypeKeyNative}}{{template "view-map-key" .}}
+
+			e := new({{.TypeNative}})
+			var n int
+			if mask.whole[{{.Index}}] {
+				n, _ = e.UnmarshalWith(v.data[i:], {{template "view-limits-arg" .}})
+			} else {
+				n, _ = e.UnmarshalMaskWith(v.data[i:], mask.sub{{.Index}}, {{template "view-limits-arg" .}})
+			}
+			i += n
+			m[k] = e
+		}
+		o.{{.NameTitle}} = m
+	}
+{{else if and .TypeList .TypeRef}}
+	if v.index[{{.Index}}] != 0 && (mask.whole[{{.Index}}] || mask.sub{{.Index}} != nil) {
+		x, i := colferVarint(v.data, v.index[{{.Index}}], false)
+		l := int(x)
+		a := make([]*{{.TypeNative}}, l)
+		malloc := make([]{{.TypeNative}}, l)
+		for ai := range a {
+			e := &malloc[ai]
+			a[ai] = e
+
+			var n int
+			if mask.whole[{{.Index}}] {
+				n, _ = e.UnmarshalWith(v.data[i:], {{template "view-limits-arg" .}})
+			} else {
+				n, _ = e.UnmarshalMaskWith(v.data[i:], mask.sub{{.Index}}, {{template "view-limits-arg" .}})
+			}
+			i += n
+		}
+		o.{{.NameTitle}} = a
+	}
+{{else if .TypeRef}}
+	if i := v.index[{{.Index}}]; i != 0 && (mask.whole[{{.Index}}] || mask.sub{{.Index}} != nil) {
+		o.{{.NameTitle}} = new({{.TypeNative}})
+		if mask.whole[{{.Index}}] {
+			o.{{.NameTitle}}.UnmarshalWith(v.data[i:], {{template "view-limits-arg" .}})
+		} else {
+			o.{{.NameTitle}}.UnmarshalMaskWith(v.data[i:], mask.sub{{.Index}}, {{template "view-limits-arg" .}})
+		}
+	}
+{{else if and (eq .Type "binary") (not .Struct.Pkg.ZeroCopy)}}
+	if mask.whole[{{.Index}}] && v.index[{{.Index}}] != 0 {
+ {{- if .TypeKey}}
+		m := v.{{.NameTitle}}()
+		for k, b := range m {
+			c := make([]byte, len(b))
+			copy(c, b)
+			m[k] = c
+		}
+		o.{{.NameTitle}} = m
+ {{- else if .TypeList}}
+		a := v.{{.NameTitle}}()
+		for ai, b := range a {
+			c := make([]byte, len(b))
+			copy(c, b)
+			a[ai] = c
+		}
+		o.{{.NameTitle}} = a
+ {{- else}}
+		b := v.{{.NameTitle}}()
+		o.{{.NameTitle}} = make([]byte, len(b))
+		copy(o.{{.NameTitle}}, b)
+ {{- end}}
+	}
+{{else if .Optional}}
+	if mask.whole[{{.Index}}] && v.index[{{.Index}}] != 0 {
+		x, _ := v.{{.NameTitle}}()
+		o.{{.NameTitle}} = &x
+	}
+{{else}}
+	if mask.whole[{{.Index}}] && v.index[{{.Index}}] != 0 {
+		o.{{.NameTitle}} = v.{{.NameTitle}}()
+	}
+{{end}}`
+
+const goEqualField = `{{if .TypeUnion}}{{$pkg := ""}}{{if ne .TypeNative .TypeUnion.NameTitle}}{{$pkg = print .TypeUnion.Pkg.NameNative "."}}{{end}}
+	switch v := o.{{.NameTitle}}.(type) {
+	case nil:
+		if x.{{.NameTitle}} != nil {
+			return false
+		}
+{{- range .TypeUnion.Members}}
+	case *{{$pkg}}{{.Struct.NameTitle}}:
+		w, ok := x.{{$.NameTitle}}.(*{{$pkg}}{{.Struct.NameTitle}})
+		if !ok {
+			return false
+		}
+		if v == nil {
+			v = new({{$pkg}}{{.Struct.NameTitle}})
+		}
+		if w == nil {
+			w = new({{$pkg}}{{.Struct.NameTitle}})
+		}
+		if !v.Equal(w) {
+			return false
+		}
+{{- end}}
+	}
+{{else if .TypeKey}}
+	if len(o.{{.NameTitle}}) != len(x.{{.NameTitle}}) {
+		return false
+	}
+	for k, v := range o.{{.NameTitle}} {
+		w, ok := x.{{.NameTitle}}[k]
+		if !ok {
+			return false
+		}
+{{template "equal-elem" .}}
+	}
+{{else if .TypeList}}
+	if len(o.{{.NameTitle}}) != len(x.{{.NameTitle}}) {
+		return false
+	}
+	for i, v := range o.{{.NameTitle}} {
+		w := x.{{.NameTitle}}[i]
+{{template "equal-elem" .}}
+	}
+{{else if .Optional}}
+	if (o.{{.NameTitle}} == nil) != (x.{{.NameTitle}} == nil) {
+		return false
+	}
+ {{- if eq .Type "float32" "float64"}}
+	if o.{{.NameTitle}} != nil && math.{{if eq .Type "float32"}}Float32bits{{else}}Float64bits{{end}}(*o.{{.NameTitle}}) != math.{{if eq .Type "float32"}}Float32bits{{else}}Float64bits{{end}}(*x.{{.NameTitle}}) {
+ {{- else}}
+	if o.{{.NameTitle}} != nil && *o.{{.NameTitle}} != *x.{{.NameTitle}} {
+ {{- end}}
+		return false
+	}
+{{else if .TypeRef}}
+	if !o.{{.NameTitle}}.Equal(x.{{.NameTitle}}) {
+		return false
+	}
+{{else if eq .Type "float32" "float64"}}
+	if o.{{.NameTitle}} != x.{{.NameTitle}} && math.{{if eq .Type "float32"}}Float32bits{{else}}Float64bits{{end}}(o.{{.NameTitle}}) != math.{{if eq .Type "float32"}}Float32bits{{else}}Float64bits{{end}}(x.{{.NameTitle}}) {
+		return false
+	}
+{{else if eq .Type "timestamp"}}
+	if !o.{{.NameTitle}}.Equal(x.{{.NameTitle}}) {
+		return false
+	}
+{{else if eq .Type "binary"}}
+	if string(o.{{.NameTitle}}) != string(x.{{.NameTitle}}) {
+		return false
+	}
+{{else}}
+	if o.{{.NameTitle}} != x.{{.NameTitle}} {
+		return false
+	}
+{{end}}`
+
+const goEqualElem = `{{if .TypeRef}}		if v == nil {
+			v = new({{.TypeNative}})
+		}
+		if w == nil {
+			w = new({{.TypeNative}})
+		}
+		if !v.Equal(w) {
+			return false
+		}
+{{- else if eq .Type "float32" "float64"}}		if math.{{if eq .Type "float32"}}Float32bits{{else}}Float64bits{{end}}(v) != math.{{if eq .Type "float32"}}Float32bits{{else}}Float64bits{{end}}(w) {
+			return false
+		}
+{{- else if eq .Type "binary"}}		if string(v) != string(w) {
+			return false
+		}
+{{- else}}		if v != w {
+			return false
+		}
+{{- end}}`
+
+const goCloneField = `{{if .TypeUnion}}{{$pkg := ""}}{{if ne .TypeNative .TypeUnion.NameTitle}}{{$pkg = print .TypeUnion.Pkg.NameNative "."}}{{end}}
+	switch v := o.{{.NameTitle}}.(type) {
+{{- range .TypeUnion.Members}}
+	case *{{$pkg}}{{.Struct.NameTitle}}:
+		c.{{$.NameTitle}} = v.Clone()
+{{- end}}
+	}
+{{else if .TypeKey}}
+	if o.{{.NameTitle}} != nil {
+		m := make(map[{{.TypeKeyNative}}]{{if .TypeRef}}*{{end}}{{.TypeNative}}, len(o.{{.NameTitle}}))
+		for k, v := range o.{{.NameTitle}} {
+ {{- if .TypeRef}}
+			m[k] = v.Clone()
+ {{- else if eq .Type "binary"}}
+			if v != nil {
+				b := make([]byte, len(v))
+				copy(b, v)
+				v = b
+			}
+			m[k] = v
+ {{- else if and (eq .Type "text") .Struct.Pkg.ZeroCopy}}
+			m[k] = string([]byte(v))
+ {{- else}}
+			m[k] = v
+ {{- end}}
+		}
+		c.{{.NameTitle}} = m
+	}
+{{else if .TypeList}}
+	if o.{{.NameTitle}} != nil {
+		a := make([]{{if .TypeRef}}*{{end}}{{.TypeNative}}, len(o.{{.NameTitle}}))
+ {{- if .TypeRef}}
+		for i, v := range o.{{.NameTitle}} {
+			a[i] = v.Clone()
+		}
+ {{- else if eq .Type "binary"}}
+		for i, v := range o.{{.NameTitle}} {
+			if v != nil {
+				a[i] = make([]byte, len(v))
+				copy(a[i], v)
+			}
+		}
+ {{- else if and (eq .Type "text") .Struct.Pkg.ZeroCopy}}
+		for i, v := range o.{{.NameTitle}} {
+			a[i] = string([]byte(v))
+		}
+ {{- else}}
+		copy(a, o.{{.NameTitle}})
+ {{- end}}
+		c.{{.NameTitle}} = a
+	}
+{{else if .Optional}}
+	if v := o.{{.NameTitle}}; v != nil {
+		w := *v
+		c.{{.NameTitle}} = &w
+	}
+{{else if .TypeRef}}
+	c.{{.NameTitle}} = o.{{.NameTitle}}.Clone()
+{{else if eq .Type "binary"}}
+	if o.{{.NameTitle}} != nil {
+		c.{{.NameTitle}} = make([]byte, len(o.{{.NameTitle}}))
+		copy(c.{{.NameTitle}}, o.{{.NameTitle}})
+	}
+{{else if and (eq .Type "text") .Struct.Pkg.ZeroCopy}}
+	c.{{.NameTitle}} = string([]byte(o.{{.NameTitle}}))
+{{end}}`
+
+const goJSONMarshalField = `{{if .TypeUnion}}{{$pkg := ""}}{{if ne .TypeNative .TypeUnion.NameTitle}}{{$pkg = print .TypeUnion.Pkg.NameNative "."}}{{end}}
+	if o.{{.NameTitle}} != nil {
+		buf = append(buf, ",\"{{.Name}}\":{\"kind\":"...)
+		var b []byte
+		switch v := o.{{.NameTitle}}.(type) {
+{{- range .TypeUnion.Members}}
+		case *{{$pkg}}{{.Struct.NameTitle}}:
+			buf = append(buf, "\"{{.Name}}\""...)
+			if v == nil {
+				v = new({{$pkg}}{{.Struct.NameTitle}})
+			}
+			b, _ = v.MarshalJSON()
+{{- end}}
+		}
+		buf = append(buf, ",\"value\":"...)
+		buf = append(buf, b...)
+		buf = append(buf, '}')
+	}
+{{else if .TypeKey}}
+	if len(o.{{.NameTitle}}) != 0 {
+		buf = append(buf, ",\"{{.Name}}\":{"...)
+		keys := make([]{{.TypeKeyNative}}, 0, len(o.{{.NameTitle}}))
+		for k := range o.{{.NameTitle}} {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(a, b int) bool { return keys[a] < keys[b] })
+		for i, k := range keys {
+			if i != 0 {
+				buf = append(buf, ',')
+			}
+ {{- if eq .TypeKey "text"}}
+			buf = colferAppendJSONString(buf, k)
+ {{- else}}
+			buf = append(buf, '"')
+			buf = strconv.{{if eq .TypeKey "int32" "int64"}}AppendInt(buf, int64(k){{else}}AppendUint(buf, uint64(k){{end}}, 10)
+			buf = append(buf, '"')
+ {{- end}}
+			buf = append(buf, ':')
+			v := o.{{.NameTitle}}[k]
+{{template "json-value" .}}
+		}
+		buf = append(buf, '}')
+	}
+{{else if .TypeList}}
+	if len(o.{{.NameTitle}}) != 0 {
+		buf = append(buf, ",\"{{.Name}}\":["...)
+		for i, v := range o.{{.NameTitle}} {
+			if i != 0 {
+				buf = append(buf, ',')
+			}
+{{template "json-value" .}}
+		}
+		buf = append(buf, ']')
+	}
+{{else if .Optional}}
+	if o.{{.NameTitle}} != nil {
+		buf = append(buf, ",\"{{.Name}}\":"...)
+		v := *o.{{.NameTitle}}
+{{template "json-value" .}}
+	}
+{{else}}
+	if v := o.{{.NameTitle}}; {{if .TypeRef}}v != nil{{else if eq .Type "bool"}}v{{else if eq .Type "timestamp"}}!v.IsZero(){{else if eq .Type "text" "binary"}}len(v) != 0{{else}}v != 0{{end}} {
+		buf = append(buf, ",\"{{.Name}}\":"...)
+ {{- if .TypeRef}}
+		b, _ := v.MarshalJSON()
+		buf = append(buf, b...)
+ {{- else}}
+{{template "json-value" .}}
+ {{- end}}
+	}
+{{end}}`
+
+const goJSONValue = `{{if .TypeRef}}		if v == nil {
+			buf = append(buf, "{}"...)
+		} else {
+			b, _ := v.MarshalJSON()
+			buf = append(buf, b...)
+		}
+{{- else if .TypeEnum}}		b, _ := v.MarshalJSON()
+		buf = append(buf, b...)
+{{- else if eq .Type "bool"}}		buf = strconv.AppendBool(buf, v)
+{{- else if eq .Type "uint8" "uint16" "uint32"}}		buf = strconv.AppendUint(buf, uint64(v), 10)
+{{- else if eq .Type "uint64"}}		buf = colferAppendJSONUint(buf, v)
+{{- else if eq .Type "int32"}}		buf = strconv.AppendInt(buf, int64(v), 10)
+{{- else if eq .Type "int64"}}		buf = colferAppendJSONInt(buf, v)
+{{- else if eq .Type "float32"}}		buf = colferAppendJSONFloat(buf, float64(v), 32)
+{{- else if eq .Type "float64"}}		buf = colferAppendJSONFloat(buf, v, 64)
+{{- else if eq .Type "timestamp"}}		buf = colferAppendJSONTime(buf, v)
+{{- else if eq .Type "text"}}		buf = colferAppendJSONString(buf, v)
+{{- else if eq .Type "binary"}}		buf = colferAppendJSONBinary(buf, v)
+{{- end}}`
+
+const goJSONUnmarshalField = `
+		case "{{.Name}}":
+{{- if .TypeUnion}}{{$pkg := ""}}{{if ne .TypeNative .TypeUnion.NameTitle}}{{$pkg = print .TypeUnion.Pkg.NameNative "."}}{{end}}
+			var u struct {
+				Kind  string          "json:\"kind\""
+				Value json.RawMessage "json:\"value\""
+			}
+			if err := json.Unmarshal(raw, &u); err != nil {
+				return fmt.Errorf("colfer: field {{.String}}: %s", err)
+			}
+			switch u.Kind {
+ {{- range .TypeUnion.Members}}
+			case "{{.Name}}":
+				v := new({{$pkg}}{{.Struct.NameTitle}})
+				if err := v.UnmarshalJSON(u.Value); err != nil {
+					return err
+				}
+				o.{{$.NameTitle}} = v
+ {{- end}}
+			default:
+				return fmt.Errorf("colfer: field {{.String}} kind %q is not a member of union {{.TypeUnion}}", u.Kind)
+			}
+{{- else if .TypeKey}}
+			var m map[string]json.RawMessage
+			if err := json.Unmarshal(raw, &m); err != nil {
+				return fmt.Errorf("colfer: field {{.String}}: %s", err)
+			}
+			o.{{.NameTitle}} = make(map[{{.TypeKeyNative}}]{{if .TypeRef}}*{{end}}{{.TypeNative}}, len(m))
+			for k, raw := range m {
+ {{- if ne .TypeKey "text"}}
+				n, err := strconv.{{if eq .TypeKey "int32" "int64"}}ParseInt{{else}}ParseUint{{end}}(k, 10, {{if eq .TypeKey "uint8"}}8{{else if eq .TypeKey "uint16"}}16{{else if eq .TypeKey "uint32" "int32"}}32{{else}}64{{end}})
+				if err != nil {
+					return fmt.Errorf("colfer: field {{.String}} key: %s", err)
+				}
+ {{- end}}
+{{template "json-decode" .}}
+				o.{{.NameTitle}}[{{if eq .TypeKey "text"}}k{{else}}{{.TypeKeyNative}}(n){{end}}] = v
+			}
+{{- else if .TypeList}}
+			var a []json.RawMessage
+			if err := json.Unmarshal(raw, &a); err != nil {
+				return fmt.Errorf("colfer: field {{.String}}: %s", err)
+			}
+			l := make([]{{if .TypeRef}}*{{end}}{{.TypeNative}}, len(a))
+			for i, raw := range a {
+{{template "json-decode" .}}
+				l[i] = v
+			}
+			o.{{.NameTitle}} = l
+{{- else}}
+{{template "json-decode" .}}
+			o.{{.NameTitle}} = {{if .Optional}}&{{end}}v
+{{- end}}`
+
+const goJSONDecode = `{{if .TypeRef}}			v := new({{.TypeNative}})
+			if err := v.UnmarshalJSON(raw); err != nil {
+				return err
+			}
+{{- else if .TypeEnum}}			var v {{.TypeNative}}
+			if err := v.UnmarshalJSON(raw); err != nil {
+				return err
+			}
+{{- else if eq .Type "bool" "text" "binary"}}			var v {{.TypeNative}}
+			if err := json.Unmarshal(raw, &v); err != nil {
+				return fmt.Errorf("colfer: field {{.String}}: %s", err)
+			}
+{{- else if eq .Type "timestamp"}}			v, err := colferParseJSONTime(raw)
+			if err != nil {
+				return fmt.Errorf("colfer: field {{.String}}: %s", err)
+			}
+{{- else}}			x, err := {{if eq .Type "float32" "float64"}}colferParseJSONFloat{{else if eq .Type "int32" "int64"}}colferParseJSONInt{{else}}colferParseJSONUint{{end}}(raw, {{if eq .Type "uint8"}}8{{else if eq .Type "uint16"}}16{{else if eq .Type "uint32" "int32" "float32"}}32{{else}}64{{end}})
+			if err != nil {
+				return fmt.Errorf("colfer: field {{.String}}: %s", err)
+			}
+			v := {{.TypeNative}}(x)
+{{- end}}`
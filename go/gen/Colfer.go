@@ -5,29 +5,73 @@ package gen
 // The compiler used schema file test.colf.
 
 import (
+	"encoding/base64"
 	"encoding/binary"
+	"encoding/json"
 	"fmt"
 	"io"
 	"math"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 var intconv = binary.BigEndian
 
 // Colfer configuration attributes
 var (
-	// ColferSizeMax is the upper limit for serial byte sizes.
+	// ColferSizeMax is the upper limit for serial byte sizes. It is the
+	// SizeMax of ColferDefaults.
 	ColferSizeMax = 16 * 1024 * 1024
 	// ColferListMax is the upper limit for the number of elements in a list.
+	// It is the ListMax of ColferDefaults.
 	ColferListMax = 64 * 1024
+	// ColferDepthMax is the upper limit for the nesting depth of data
+	// structures. It is the DepthMax of ColferDefaults.
+	ColferDepthMax = 100
 )
 
+// ColferLimits has the upper boundaries for marshalling and unmarshalling.
+// The limits apply to nested data structures too, including those from other
+// packages.
+type ColferLimits struct {
+	// SizeMax is the upper limit for serial byte sizes.
+	SizeMax int
+	// ListMax is the upper limit for the number of elements in a list or in
+	// a map.
+	ListMax int
+	// DepthMax is the upper limit for the nesting depth of data structures,
+	// with one for a struct without any nested values.
+	DepthMax int
+}
+
+// ColferDefaults returns the limits which apply when none are given. A
+// change to ColferSizeMax, ColferListMax or ColferDepthMax races with any
+// (un)marshalling in progress. The methods with explicit limits, like
+// UnmarshalWith, need no such change.
+func ColferDefaults() ColferLimits {
+	return ColferLimits{
+		SizeMax:  ColferSizeMax,
+		ListMax:  ColferListMax,
+		DepthMax: ColferDepthMax,
+	}
+}
+
 // ColferMax signals an upper limit breach.
 type ColferMax string
 
 // Error honors the error interface.
 func (m ColferMax) Error() string { return string(m) }
 
+// ColferDepth signals a breach of the nesting depth limit. Marshalling a
+// reference cycle breaches the limit too.
+type ColferDepth string
+
+// Error honors the error interface.
+func (d ColferDepth) Error() string { return string(d) }
+
 // ColferError signals a data mismatch as as a byte index.
 type ColferError int
 
@@ -44,6 +88,436 @@ func (i ColferTail) Error() string {
 	return fmt.Sprintf("colfer: data continuation at byte %d", i)
 }
 
+// colferVarint decodes the varint at data[i] from a validated serial, and
+// it returns the value with the index of the first byte after the varint.
+// The ninth byte has all 8 bits in use when capped, as with 64-bit integers.
+func colferVarint(data []byte, i int, capped bool) (uint64, int) {
+	x := uint64(data[i])
+	i++
+
+	if x >= 0x80 {
+		x &= 0x7f
+		for shift := uint(7); ; shift += 7 {
+			b := uint64(data[i])
+			i++
+
+			if b < 0x80 || capped && shift == 56 {
+				x |= b << shift
+				break
+			}
+			x |= (b & 0x7f) << shift
+		}
+	}
+	return x, i
+}
+
+// colferAppendJSONString appends s as a JSON string. Only quotation marks,
+// backslashes and control characters are escaped, like ECMAScript's
+// JSON.stringify does. Invalid UTF-8 becomes U+FFFD.
+func colferAppendJSONString(buf []byte, s string) []byte {
+	const hex = "0123456789abcdef"
+	buf = append(buf, '"')
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c >= utf8.RuneSelf {
+			r, size := utf8.DecodeRuneInString(s[i:])
+			if r == utf8.RuneError && size == 1 {
+				buf = append(buf, "\uFFFD"...)
+			} else {
+				buf = append(buf, s[i:i+size]...)
+			}
+			i += size
+			continue
+		}
+
+		switch c {
+		case '"', '\\':
+			buf = append(buf, '\\', c)
+		case '\b':
+			buf = append(buf, '\\', 'b')
+		case '\f':
+			buf = append(buf, '\\', 'f')
+		case '\n':
+			buf = append(buf, '\\', 'n')
+		case '\r':
+			buf = append(buf, '\\', 'r')
+		case '\t':
+			buf = append(buf, '\\', 't')
+		default:
+			if c < 0x20 {
+				buf = append(buf, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xf])
+			} else {
+				buf = append(buf, c)
+			}
+		}
+		i++
+	}
+	return append(buf, '"')
+}
+
+// colferAppendJSONUint appends x as a JSON number, or as a JSON string when x
+// exceeds the safe integer range of ECMAScript, which ends at 2^53 - 1.
+func colferAppendJSONUint(buf []byte, x uint64) []byte {
+	if x <= 1<<53-1 {
+		return strconv.AppendUint(buf, x, 10)
+	}
+	buf = strconv.AppendUint(append(buf, '"'), x, 10)
+	return append(buf, '"')
+}
+
+// colferAppendJSONInt appends x as a JSON number, or as a JSON string when x
+// exceeds the safe integer range of ECMAScript, which is 2^53 - 1 at most in
+// magnitude.
+func colferAppendJSONInt(buf []byte, x int64) []byte {
+	if x <= 1<<53-1 && x >= -(1<<53-1) {
+		return strconv.AppendInt(buf, x, 10)
+	}
+	buf = strconv.AppendInt(append(buf, '"'), x, 10)
+	return append(buf, '"')
+}
+
+// colferParseJSONUint reads an unsigned integer from a JSON number, or from a
+// JSON string with a number.
+func colferParseJSONUint(data []byte, bitSize int) (uint64, error) {
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(string(n), 10, bitSize)
+}
+
+// colferParseJSONInt reads a signed integer from a JSON number, or from a
+// JSON string with a number.
+func colferParseJSONInt(data []byte, bitSize int) (int64, error) {
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(string(n), 10, bitSize)
+}
+
+// colferAppendJSONFloat appends the shortest decimal which reads back as f
+// bit-exactly, in the notation of ECMAScript's Number toString. NaN and the
+// infinities become a JSON string, since JSON has no notation for them.
+func colferAppendJSONFloat(buf []byte, f float64, bitSize int) []byte {
+	switch {
+	case math.IsNaN(f):
+		return append(buf, "\"NaN\""...)
+	case math.IsInf(f, 1):
+		return append(buf, "\"Infinity\""...)
+	case math.IsInf(f, -1):
+		return append(buf, "\"-Infinity\""...)
+	}
+
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 {
+		if bitSize == 64 && (abs < 1e-6 || abs >= 1e21) || bitSize == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+	buf = strconv.AppendFloat(buf, f, format, -1, bitSize)
+	// exponent without leading zeros, as in "1e-7"
+	if n := len(buf); format == 'e' && buf[n-3] == '-' && buf[n-2] == '0' {
+		buf[n-2] = buf[n-1]
+		buf = buf[:n-1]
+	}
+	return buf
+}
+
+// colferParseJSONFloat reads a JSON number, or a JSON string with "NaN",
+// "Infinity" or "-Infinity".
+func colferParseJSONFloat(data []byte, bitSize int) (float64, error) {
+	var s string
+	if json.Unmarshal(data, &s) == nil {
+		switch s {
+		case "NaN":
+			return math.NaN(), nil
+		case "Infinity":
+			return math.Inf(1), nil
+		case "-Infinity":
+			return math.Inf(-1), nil
+		}
+		return 0, fmt.Errorf("%q is not a number", s)
+	}
+
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(string(n), bitSize)
+}
+
+// colferAppendJSONTime appends t as an RFC 3339 JSON string in UTC.
+func colferAppendJSONTime(buf []byte, t time.Time) []byte {
+	buf = append(buf, '"')
+	buf = t.UTC().AppendFormat(buf, time.RFC3339Nano)
+	return append(buf, '"')
+}
+
+// colferParseJSONTime reads an RFC 3339 JSON string, including the years
+// beyond the 4-digit range, as written by colferAppendJSONTime.
+func colferParseJSONTime(data []byte) (time.Time, error) {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return time.Time{}, err
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		// year precedes "-MM-DDT"
+		i := strings.IndexByte(s, 'T') - 6
+		if i < 1 {
+			return t, err
+		}
+		year, yearErr := strconv.Atoi(s[:i])
+		// leap year for February 29th
+		u, uErr := time.Parse(time.RFC3339Nano, "2000"+s[i:])
+		if yearErr != nil || uErr != nil {
+			return t, err
+		}
+		t = time.Date(year, u.Month(), u.Day(), u.Hour(), u.Minute(), u.Second(), u.Nanosecond(), u.Location())
+	}
+	return t.UTC(), nil
+}
+
+// colferAppendJSONBinary appends b as a JSON string in standard base64
+// encoding, with padding.
+func colferAppendJSONBinary(buf, b []byte) []byte {
+	buf = append(buf, '"')
+	n := len(buf)
+	buf = append(buf, make([]byte, base64.StdEncoding.EncodedLen(len(b)))...)
+	base64.StdEncoding.Encode(buf[n:], b)
+	return append(buf, '"')
+}
+
+// ColferDecoder reads serials from an input stream.
+type ColferDecoder struct {
+	r      io.Reader
+	limits ColferLimits
+	// buf is the read buffer.
+	buf []byte
+	// offset is the index of the first data byte in buf.
+	offset int
+	// i is the index of the data end (exclusive) in buf.
+	i int
+}
+
+// NewColferDecoder returns a new decoder which reads from r, with the
+// ColferDefaults at the time of the call.
+func NewColferDecoder(r io.Reader) *ColferDecoder {
+	return NewColferDecoderWith(r, ColferDefaults())
+}
+
+// NewColferDecoderWith returns a new decoder which reads from r. The read
+// buffer grows on demand, limited by limits.SizeMax.
+func NewColferDecoderWith(r io.Reader, limits ColferLimits) *ColferDecoder {
+	size := 2048
+	if limits.SizeMax < size {
+		size = limits.SizeMax
+	}
+	return &ColferDecoder{r: r, limits: limits, buf: make([]byte, size)}
+}
+
+// Decode reads the next serial into o. The error return is io.EOF when the
+// stream ends before the serial starts, and io.ErrUnexpectedEOF when the
+// stream ends within the serial. Other error return options come from the
+// Unmarshal method of o, or from the input stream. The limits of d apply to
+// o when it has an UnmarshalWith method with ColferLimits from this package.
+// A serial which does not fit in SizeMax bytes gets a ColferMax error.
+func (d *ColferDecoder) Decode(o interface{ Unmarshal([]byte) (int, error) }) error {
+	for {
+		if d.offset < d.i {
+			var n int
+			var err error
+			if u, ok := o.(interface {
+				UnmarshalWith([]byte, ColferLimits) (int, error)
+			}); ok {
+				n, err = u.UnmarshalWith(d.buf[d.offset:d.i], d.limits)
+			} else {
+				n, err = o.Unmarshal(d.buf[d.offset:d.i])
+			}
+			if err != io.EOF {
+				if err == nil {
+					d.offset += n
+				}
+				return err
+			}
+		}
+		// not enough data
+
+		if d.offset == d.i {
+			d.offset, d.i = 0, 0
+		}
+		if d.i == len(d.buf) {
+			if d.offset == 0 {
+				if len(d.buf) >= d.limits.SizeMax {
+					return ColferMax(fmt.Sprintf("colfer: serial size exceeds %d bytes", d.limits.SizeMax))
+				}
+				// grow
+				size := len(d.buf) * 4
+				if size > d.limits.SizeMax {
+					size = d.limits.SizeMax
+				}
+				bigger := make([]byte, size)
+				copy(bigger, d.buf)
+				d.buf = bigger
+			} else {
+				// move data to start of buffer
+				d.i = copy(d.buf, d.buf[d.offset:d.i])
+				d.offset = 0
+			}
+		}
+
+		n, err := d.r.Read(d.buf[d.i:])
+		d.i += n
+		if n == 0 && err != nil {
+			if err == io.EOF && d.offset < d.i {
+				return io.ErrUnexpectedEOF
+			}
+			return err
+		}
+	}
+}
+
+// ColferEncoder writes serials to an output stream with buffering.
+type ColferEncoder struct {
+	w   io.Writer
+	buf []byte
+}
+
+// NewColferEncoder returns a new encoder which writes to w.
+func NewColferEncoder(w io.Writer) *ColferEncoder {
+	return &ColferEncoder{w: w, buf: make([]byte, 0, 4096)}
+}
+
+// Encode buffers the serial of o. The error return options come from the
+// MarshalLen method of o, or from the output stream on a flush.
+func (e *ColferEncoder) Encode(o interface {
+	MarshalLen() (int, error)
+	MarshalTo([]byte) int
+}) error {
+	l, err := o.MarshalLen()
+	if err != nil {
+		return err
+	}
+	if len(e.buf)+l > cap(e.buf) {
+		if err := e.Flush(); err != nil {
+			return err
+		}
+		if l > cap(e.buf) {
+			e.buf = make([]byte, 0, l)
+		}
+	}
+	n := len(e.buf)
+	e.buf = e.buf[:n+l]
+	o.MarshalTo(e.buf[n:])
+	return nil
+}
+
+// Flush writes any buffered data to the output stream.
+func (e *ColferEncoder) Flush() error {
+	if len(e.buf) == 0 {
+		return nil
+	}
+	_, err := e.w.Write(e.buf)
+	e.buf = e.buf[:0]
+	return err
+}
+
+const (
+	// ConstBool tests boolean constants.
+	ConstBool bool = true
+	// ConstU8 tests unsigned 8-bit integer constants.
+	ConstU8 uint8 = 255
+	// ConstU32 tests unsigned 32-bit integer constants.
+	ConstU32 uint32 = 4294967295
+	// ConstU64 tests unsigned 64-bit integer constants.
+	ConstU64 uint64 = 9007199254740991
+	// ConstI32 tests signed 32-bit integer constants.
+	ConstI32 int32 = -2147483648
+	// ConstI64 tests signed 64-bit integer constants.
+	ConstI64 int64 = -9007199254740991
+	// ConstF32 tests 32-bit floating point constants.
+	ConstF32 float32 = 1.5
+	// ConstF64 tests 64-bit floating point constants.
+	ConstF64 float64 = 1e-100
+	// ConstText tests text constants.
+	ConstText string = "\"π\"\t??="
+)
+
+// Color tests enumerations.
+type Color uint8
+
+const (
+	// Red is the zero value.
+	Red Color = 0
+	// Green is the first in line.
+	Green Color = 1
+	// Blue is the last one.
+	Blue Color = 2
+)
+
+// String returns the schema name of x, or a numeric representation
+// when x is not one of the defined values.
+func (x Color) String() string {
+	switch x {
+	case Red:
+		return "red"
+	case Green:
+		return "green"
+	case Blue:
+		return "blue"
+	}
+	return fmt.Sprintf("color(%d)", uint8(x))
+}
+
+// MarshalJSON encodes x as a JSON string with the schema name, or as a JSON
+// number when x is not one of the defined values.
+func (x Color) MarshalJSON() ([]byte, error) {
+	switch x {
+	case Red:
+		return []byte("\"red\""), nil
+	case Green:
+		return []byte("\"green\""), nil
+	case Blue:
+		return []byte("\"blue\""), nil
+	}
+	return strconv.AppendUint(nil, uint64(x), 10), nil
+}
+
+// UnmarshalJSON decodes either a JSON string with a schema name, or a JSON
+// number.
+func (x *Color) UnmarshalJSON(data []byte) error {
+	var name string
+	if json.Unmarshal(data, &name) == nil {
+		switch name {
+		case "red":
+			*x = Red
+		case "green":
+			*x = Green
+		case "blue":
+			*x = Blue
+		default:
+			return fmt.Errorf("colfer: enumeration gen.color has no value %q", name)
+		}
+		return nil
+	}
+
+	v, err := strconv.ParseUint(string(data), 10, 8)
+	if err != nil {
+		return fmt.Errorf("colfer: enumeration gen.color needs a name or a number; got %s", data)
+	}
+	*x = Color(v)
+	return nil
+}
+
+// Pick tests unions.
+type Pick interface {
+	isPick()
+}
+
+func (*O) isPick()     {}
+func (*Point) isPick() {}
+
 // O contains all supported data types.
 type O struct {
 	// B tests booleans.
@@ -82,11 +556,55 @@ type O struct {
 	F32s []float32
 	// F64s tests 64-bit floating point lists.
 	F64s []float64
+	// E tests enumerations.
+	E Color
+	// Bs tests boolean lists.
+	Bs []bool
+	// U8s tests unsigned 8-bit integer lists.
+	U8s []uint8
+	// U16s tests unsigned 16-bit integer lists.
+	U16s []uint16
+	// U32s tests unsigned 32-bit integer lists.
+	U32s []uint32
+	// U64s tests unsigned 64-bit integer lists.
+	U64s []uint64
+	// I32s tests signed 32-bit integer lists.
+	I32s []int32
+	// I64s tests signed 64-bit integer lists.
+	I64s []int64
+	// Es tests enumeration lists.
+	Es []Color
+	// Mt tests maps with text keys.
+	Mt map[string]string
+	// Mu tests maps with unsigned integer keys.
+	Mu map[uint32]int64
+	// Mi tests maps with signed integer keys.
+	Mi map[int64][]byte
+	// Mo tests maps with data structure values.
+	Mo map[string]*O
+	// Gap tests explicit indexes.
+	Gap bool
+	// Host tests field size limits.
+	Host string
+	// Tags tests field list limits.
+	Tags []string
+	// Ob tests optional booleans.
+	Ob *bool
+	// Ou32 tests optional unsigned integers.
+	Ou32 *uint32
+	// Oi64 tests optional signed integers.
+	Oi64 *int64
+	// Of64 tests optional floating points.
+	Of64 *float64
+	// U tests unions.
+	U Pick
 }
 
 // MarshalTo encodes o as Colfer into buf and returns the number of bytes written.
-// If the buffer is too small, MarshalTo will panic.
+// If the buffer is too small, MarshalTo will panic. MarshalTo does no checks on
+// its own. MarshalLen gives the size, and it catches reference cycles.
 // All nil entries in o.Os will be replaced with a new value.
+// A nil pointer in o.U will be replaced with a new value.
 func (o *O) MarshalTo(buf []byte) int {
 	var i int
 
@@ -347,520 +865,7157 @@ func (o *O) MarshalTo(buf []byte) int {
 		}
 	}
 
-	buf[i] = 0x7f
-	i++
-	return i
-}
-
-// MarshalLen returns the Colfer serial byte size.
-// The error return option is gen.ColferMax.
-func (o *O) MarshalLen() (int, error) {
-	l := 1
-
-	if o.B {
-		l++
+	if x := uint8(o.E); x != 0 {
+		buf[i] = 18
+		i++
+		buf[i] = x
+		i++
 	}
 
-	if x := o.U32; x >= 1<<21 {
-		l += 5
-	} else if x != 0 {
-		for l += 2; x >= 0x80; l++ {
+	if l := len(o.Bs); l != 0 {
+		buf[i] = 19
+		i++
+		x := uint(l)
+		for x >= 0x80 {
+			buf[i] = byte(x | 0x80)
 			x >>= 7
+			i++
 		}
-	}
-
-	if x := o.U64; x >= 1<<49 {
-		l += 9
-	} else if x != 0 {
-		for l += 2; x >= 0x80; l++ {
-			x >>= 7
+		buf[i] = byte(x)
+		i++
+		for _, v := range o.Bs {
+			buf[i] = 0
+			if v {
+				buf[i] = 1
+			}
+			i++
 		}
 	}
 
-	if v := o.I32; v != 0 {
-		x := uint32(v)
-		if v < 0 {
-			x = ^x + 1
-		}
-		for l += 2; x >= 0x80; l++ {
+	if l := len(o.U8s); l != 0 {
+		buf[i] = 20
+		i++
+		x := uint(l)
+		for x >= 0x80 {
+			buf[i] = byte(x | 0x80)
 			x >>= 7
+			i++
 		}
+		buf[i] = byte(x)
+		i++
+		i += copy(buf[i:], o.U8s)
 	}
 
-	if v := o.I64; v != 0 {
-		l += 2
-		x := uint64(v)
-		if v < 0 {
-			x = ^x + 1
-		}
-		for n := 0; x >= 0x80 && n < 8; n++ {
+	if l := len(o.U16s); l != 0 {
+		buf[i] = 21
+		i++
+		x := uint(l)
+		for x >= 0x80 {
+			buf[i] = byte(x | 0x80)
 			x >>= 7
-			l++
+			i++
 		}
-	}
-
-	if o.F32 != 0 {
-		l += 5
-	}
-
-	if o.F64 != 0 {
-		l += 9
-	}
-
-	if v := o.T; !v.IsZero() {
-		if s := uint64(v.Unix()); s < 1<<32 {
-			l += 9
-		} else {
-			l += 13
+		buf[i] = byte(x)
+		i++
+		for _, v := range o.U16s {
+			x := uint32(v)
+			for x >= 0x80 {
+				buf[i] = byte(x | 0x80)
+				x >>= 7
+				i++
+			}
+			buf[i] = byte(x)
+			i++
 		}
 	}
 
-	if x := len(o.S); x != 0 {
-		if x > ColferSizeMax {
-			return 0, ColferMax(fmt.Sprintf("colfer: field gen.o.s exceeds %d bytes", ColferSizeMax))
-		}
-		for l += x + 2; x >= 0x80; l++ {
+	if l := len(o.U32s); l != 0 {
+		buf[i] = 22
+		i++
+		x := uint(l)
+		for x >= 0x80 {
+			buf[i] = byte(x | 0x80)
 			x >>= 7
+			i++
+		}
+		buf[i] = byte(x)
+		i++
+		for _, v := range o.U32s {
+			x := uint32(v)
+			for x >= 0x80 {
+				buf[i] = byte(x | 0x80)
+				x >>= 7
+				i++
+			}
+			buf[i] = byte(x)
+			i++
 		}
 	}
 
-	if x := len(o.A); x != 0 {
-		if x > ColferSizeMax {
-			return 0, ColferMax(fmt.Sprintf("colfer: field gen.o.a exceeds %d bytes", ColferSizeMax))
-		}
-		for l += x + 2; x >= 0x80; l++ {
+	if l := len(o.U64s); l != 0 {
+		buf[i] = 23
+		i++
+		x := uint(l)
+		for x >= 0x80 {
+			buf[i] = byte(x | 0x80)
 			x >>= 7
+			i++
 		}
-	}
-
-	if v := o.O; v != nil {
-		vl, err := v.MarshalLen()
-		if err != nil {
-			return 0, err
+		buf[i] = byte(x)
+		i++
+		for _, v := range o.U64s {
+			x := uint64(v)
+			for n := 0; x >= 0x80 && n < 8; n++ {
+				buf[i] = byte(x | 0x80)
+				x >>= 7
+				i++
+			}
+			buf[i] = byte(x)
+			i++
 		}
-		l += vl + 1
 	}
 
-	if x := len(o.Os); x != 0 {
-		if x > ColferListMax {
-			return 0, ColferMax(fmt.Sprintf("colfer: field gen.o.os exceeds %d elements", ColferListMax))
-		}
-		for l += 2; x >= 0x80; l++ {
+	if l := len(o.I32s); l != 0 {
+		buf[i] = 24
+		i++
+		x := uint(l)
+		for x >= 0x80 {
+			buf[i] = byte(x | 0x80)
 			x >>= 7
+			i++
 		}
-		for _, v := range o.Os {
-			if v == nil {
-				l++
-				continue
-			}
-			vl, err := v.MarshalLen()
-			if err != nil {
-				return 0, err
+		buf[i] = byte(x)
+		i++
+		for _, v := range o.I32s {
+			x := uint32(v<<1) ^ uint32(v>>31)
+			for x >= 0x80 {
+				buf[i] = byte(x | 0x80)
+				x >>= 7
+				i++
 			}
-			l += vl
-		}
-		if l > ColferSizeMax {
-			return 0, ColferMax(fmt.Sprintf("colfer: struct gen.o size exceeds %d bytes", ColferSizeMax))
+			buf[i] = byte(x)
+			i++
 		}
 	}
 
-	if x := len(o.Ss); x != 0 {
-		if x > ColferListMax {
-			return 0, ColferMax(fmt.Sprintf("colfer: field gen.o.ss exceeds %d elements", ColferListMax))
-		}
-		for l += 2; x >= 0x80; l++ {
+	if l := len(o.I64s); l != 0 {
+		buf[i] = 25
+		i++
+		x := uint(l)
+		for x >= 0x80 {
+			buf[i] = byte(x | 0x80)
 			x >>= 7
+			i++
 		}
-		for _, a := range o.Ss {
-			x = len(a)
-			if x > ColferSizeMax {
-				return 0, ColferMax(fmt.Sprintf("colfer: field gen.o.ss exceeds %d bytes", ColferSizeMax))
-			}
-			for l += x + 1; x >= 0x80; l++ {
+		buf[i] = byte(x)
+		i++
+		for _, v := range o.I64s {
+			x := uint64(v<<1) ^ uint64(v>>63)
+			for n := 0; x >= 0x80 && n < 8; n++ {
+				buf[i] = byte(x | 0x80)
 				x >>= 7
+				i++
 			}
-		}
-		if l >= ColferSizeMax {
-			return 0, ColferMax(fmt.Sprintf("colfer: struct gen.o size exceeds %d bytes", ColferSizeMax))
+			buf[i] = byte(x)
+			i++
 		}
 	}
 
-	if x := len(o.As); x != 0 {
-		if x > ColferListMax {
-			return 0, ColferMax(fmt.Sprintf("colfer: field gen.o.as exceeds %d elements", ColferListMax))
+	if l := len(o.Es); l != 0 {
+		buf[i] = 26
+		i++
+		x := uint(l)
+		for x >= 0x80 {
+			buf[i] = byte(x | 0x80)
+			x >>= 7
+			i++
 		}
-		for l += 2; x >= 0x80; l++ {
+		buf[i] = byte(x)
+		i++
+		for _, v := range o.Es {
+			buf[i] = byte(v)
+			i++
+		}
+	}
+
+	if l := len(o.Mt); l != 0 {
+		buf[i] = 27
+		i++
+		x := uint(l)
+		for x >= 0x80 {
+			buf[i] = byte(x | 0x80)
 			x >>= 7
+			i++
 		}
-		for _, a := range o.As {
-			x = len(a)
-			if x > ColferSizeMax {
-				return 0, ColferMax(fmt.Sprintf("colfer: field gen.o.as exceeds %d bytes", ColferSizeMax))
+		buf[i] = byte(x)
+		i++
+
+		keys := make([]string, 0, l)
+		for k := range o.Mt {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(a, b int) bool { return keys[a] < keys[b] })
+		for _, k := range keys {
+			v := o.Mt[k]
+			x = uint(len(k))
+			for x >= 0x80 {
+				buf[i] = byte(x | 0x80)
+				x >>= 7
+				i++
 			}
-			for l += x + 1; x >= 0x80; l++ {
+			buf[i] = byte(x)
+			i++
+			i += copy(buf[i:], k)
+			x = uint(len(v))
+			for x >= 0x80 {
+				buf[i] = byte(x | 0x80)
 				x >>= 7
+				i++
 			}
+			buf[i] = byte(x)
+			i++
+			i += copy(buf[i:], v)
 		}
-		if l >= ColferSizeMax {
-			return 0, ColferMax(fmt.Sprintf("colfer: struct gen.o size exceeds %d bytes", ColferSizeMax))
-		}
-	}
-
-	if x := o.U8; x != 0 {
-		l += 2
-	}
-
-	if x := o.U16; x >= 1<<8 {
-		l += 3
-	} else if x != 0 {
-		l += 2
 	}
 
-	if x := len(o.F32s); x != 0 {
-		if x > ColferListMax {
-			return 0, ColferMax(fmt.Sprintf("colfer: field gen.o.f32s exceeds %d elements", ColferListMax))
-		}
-		for l += 2 + x*4; x >= 0x80; l++ {
+	if l := len(o.Mu); l != 0 {
+		buf[i] = 28
+		i++
+		x := uint(l)
+		for x >= 0x80 {
+			buf[i] = byte(x | 0x80)
 			x >>= 7
+			i++
 		}
-	}
+		buf[i] = byte(x)
+		i++
 
-	if x := len(o.F64s); x != 0 {
-		if x > ColferListMax {
-			return 0, ColferMax(fmt.Sprintf("colfer: field gen.o.f64s exceeds %d elements", ColferListMax))
+		keys := make([]uint32, 0, l)
+		for k := range o.Mu {
+			keys = append(keys, k)
 		}
-		for l += 2 + x*8; x >= 0x80; l++ {
-			x >>= 7
+		sort.Slice(keys, func(a, b int) bool { return keys[a] < keys[b] })
+		for _, k := range keys {
+			v := o.Mu[k]
+			kx := uint32(k)
+			for kx >= 0x80 {
+				buf[i] = byte(kx | 0x80)
+				kx >>= 7
+				i++
+			}
+			buf[i] = byte(kx)
+			i++
+			vx := uint64(v<<1) ^ uint64(v>>63)
+			for n := 0; vx >= 0x80 && n < 8; n++ {
+				buf[i] = byte(vx | 0x80)
+				vx >>= 7
+				i++
+			}
+			buf[i] = byte(vx)
+			i++
 		}
 	}
 
-	if l > ColferSizeMax {
-		return l, ColferMax(fmt.Sprintf("colfer: struct gen.o exceeds %d bytes", ColferSizeMax))
-	}
-	return l, nil
-}
-
-// MarshalBinary encodes o as Colfer conform encoding.BinaryMarshaler.
-// All nil entries in o.Os will be replaced with a new value.
-// The error return option is gen.ColferMax.
-func (o *O) MarshalBinary() (data []byte, err error) {
-	l, err := o.MarshalLen()
-	if err != nil {
-		return nil, err
-	}
-	data = make([]byte, l)
-	o.MarshalTo(data)
-	return data, nil
-}
-
-// Unmarshal decodes data as Colfer and returns the number of bytes read.
-// The error return options are io.EOF, gen.ColferError and gen.ColferMax.
-func (o *O) Unmarshal(data []byte) (int, error) {
-	if len(data) == 0 {
-		return 0, io.EOF
-	}
-	header := data[0]
-	i := 1
-
-	if header == 0 {
-		if i >= len(data) {
-			goto eof
+	if l := len(o.Mi); l != 0 {
+		buf[i] = 29
+		i++
+		x := uint(l)
+		for x >= 0x80 {
+			buf[i] = byte(x | 0x80)
+			x >>= 7
+			i++
 		}
-		o.B = true
-		header = data[i]
+		buf[i] = byte(x)
 		i++
+
+		keys := make([]int64, 0, l)
+		for k := range o.Mi {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(a, b int) bool { return keys[a] < keys[b] })
+		for _, k := range keys {
+			v := o.Mi[k]
+			kx := uint64(k<<1) ^ uint64(k>>63)
+			for n := 0; kx >= 0x80 && n < 8; n++ {
+				buf[i] = byte(kx | 0x80)
+				kx >>= 7
+				i++
+			}
+			buf[i] = byte(kx)
+			i++
+			x = uint(len(v))
+			for x >= 0x80 {
+				buf[i] = byte(x | 0x80)
+				x >>= 7
+				i++
+			}
+			buf[i] = byte(x)
+			i++
+			i += copy(buf[i:], v)
+		}
 	}
 
-	if header == 1 {
-		start := i
+	if l := len(o.Mo); l != 0 {
+		buf[i] = 30
 		i++
-		if i >= len(data) {
-			goto eof
+		x := uint(l)
+		for x >= 0x80 {
+			buf[i] = byte(x | 0x80)
+			x >>= 7
+			i++
 		}
-		x := uint32(data[start])
+		buf[i] = byte(x)
+		i++
 
-		if x >= 0x80 {
-			x &= 0x7f
-			for shift := uint(7); ; shift += 7 {
-				b := uint32(data[i])
+		keys := make([]string, 0, l)
+		for k := range o.Mo {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(a, b int) bool { return keys[a] < keys[b] })
+		for _, k := range keys {
+			v := o.Mo[k]
+			x = uint(len(k))
+			for x >= 0x80 {
+				buf[i] = byte(x | 0x80)
+				x >>= 7
 				i++
-				if i >= len(data) {
-					goto eof
-				}
-
-				if b < 0x80 {
-					x |= b << shift
-					break
-				}
-				x |= (b & 0x7f) << shift
 			}
+			buf[i] = byte(x)
+			i++
+			i += copy(buf[i:], k)
+			if v == nil {
+				v = new(O)
+				o.Mo[k] = v
+			}
+			i += v.MarshalTo(buf[i:])
 		}
-		o.U32 = x
+	}
 
-		header = data[i]
+	if o.Gap {
+		buf[i] = 32
 		i++
-	} else if header == 1|0x80 {
-		start := i
-		i += 4
-		if i >= len(data) {
-			goto eof
+	}
+
+	if l := len(o.Host); l != 0 {
+		buf[i] = 33
+		i++
+		x := uint(l)
+		for x >= 0x80 {
+			buf[i] = byte(x | 0x80)
+			x >>= 7
+			i++
 		}
-		o.U32 = intconv.Uint32(data[start:])
-		header = data[i]
+		buf[i] = byte(x)
 		i++
+		i += copy(buf[i:], o.Host)
 	}
 
-	if header == 2 {
-		start := i
+	if l := len(o.Tags); l != 0 {
+		buf[i] = 34
 		i++
-		if i >= len(data) {
-			goto eof
+		x := uint(l)
+		for x >= 0x80 {
+			buf[i] = byte(x | 0x80)
+			x >>= 7
+			i++
 		}
-		x := uint64(data[start])
-
-		if x >= 0x80 {
-			x &= 0x7f
-			for shift := uint(7); ; shift += 7 {
-				b := uint64(data[i])
+		buf[i] = byte(x)
+		i++
+		for _, a := range o.Tags {
+			x = uint(len(a))
+			for x >= 0x80 {
+				buf[i] = byte(x | 0x80)
+				x >>= 7
 				i++
-				if i >= len(data) {
-					goto eof
-				}
-
-				if b < 0x80 || shift == 56 {
-					x |= b << shift
-					break
-				}
-				x |= (b & 0x7f) << shift
 			}
+			buf[i] = byte(x)
+			i++
+			i += copy(buf[i:], a)
 		}
-		o.U64 = x
+	}
 
-		header = data[i]
-		i++
-	} else if header == 2|0x80 {
-		start := i
-		i += 8
-		if i >= len(data) {
-			goto eof
+	if v := o.Ob; v != nil {
+		if *v {
+			buf[i] = 35
+		} else {
+			buf[i] = 35 | 0x80
 		}
-		o.U64 = intconv.Uint64(data[start:])
-		header = data[i]
 		i++
 	}
 
-	if header == 3 {
-		if i+1 >= len(data) {
+	if v := o.Ou32; v != nil {
+		if x := *v; x >= 1<<21 {
+			buf[i] = 36 | 0x80
+			intconv.PutUint32(buf[i+1:], x)
+			i += 5
+		} else {
+			buf[i] = 36
 			i++
-			goto eof
-		}
-		x := uint32(data[i])
-		i++
-
-		if x >= 0x80 {
-			x &= 0x7f
-			for shift := uint(7); ; shift += 7 {
-				b := uint32(data[i])
+			for x >= 0x80 {
+				buf[i] = byte(x | 0x80)
+				x >>= 7
 				i++
-				if i >= len(data) {
-					goto eof
-				}
-
-				if b < 0x80 {
-					x |= b << shift
-					break
-				}
-				x |= (b & 0x7f) << shift
 			}
+			buf[i] = byte(x)
+			i++
 		}
-		o.I32 = int32(x)
+	}
 
-		header = data[i]
+	if v := o.Oi64; v != nil {
+		x := uint64(*v)
+		if *v >= 0 {
+			buf[i] = 37
+		} else {
+			x = ^x + 1
+			buf[i] = 37 | 0x80
+		}
 		i++
-	} else if header == 3|0x80 {
-		if i+1 >= len(data) {
+		for n := 0; x >= 0x80 && n < 8; n++ {
+			buf[i] = byte(x | 0x80)
+			x >>= 7
 			i++
-			goto eof
 		}
-		x := uint32(data[i])
+		buf[i] = byte(x)
 		i++
+	}
 
-		if x >= 0x80 {
-			x &= 0x7f
-			for shift := uint(7); ; shift += 7 {
-				b := uint32(data[i])
-				i++
-				if i >= len(data) {
-					goto eof
-				}
+	if v := o.Of64; v != nil {
+		buf[i] = 38
+		intconv.PutUint64(buf[i+1:], math.Float64bits(*v))
+		i += 9
+	}
 
-				if b < 0x80 {
-					x |= b << shift
-					break
-				}
-				x |= (b & 0x7f) << shift
+	if v := o.U; v != nil {
+		buf[i] = 39
+		switch v := v.(type) {
+		case *O:
+			if v == nil {
+				v = new(O)
+				o.U = v
+			}
+			buf[i+1] = 0
+			i += 2
+			i += v.MarshalTo(buf[i:])
+		case *Point:
+			if v == nil {
+				v = new(Point)
+				o.U = v
 			}
+			buf[i+1] = 1
+			i += 2
+			i += v.MarshalTo(buf[i:])
 		}
-		o.I32 = int32(^x + 1)
+	}
 
-		header = data[i]
-		i++
+	buf[i] = 0x7f
+	i++
+	return i
+}
+
+// MarshalLen returns the Colfer serial byte size.
+// The limits are the ColferDefaults.
+// The error return options are gen.ColferMax and gen.ColferDepth.
+func (o *O) MarshalLen() (int, error) {
+	return o.MarshalLenWith(ColferDefaults())
+}
+
+// MarshalLenWith returns the Colfer serial byte size like MarshalLen does,
+// with limits instead of the ColferDefaults.
+func (o *O) MarshalLenWith(limits ColferLimits) (int, error) {
+	if limits.DepthMax < 1 {
+		return 0, ColferDepth("colfer: struct gen.o exceeds the nesting depth limit; check for reference cycles")
 	}
+	limits.DepthMax--
+	l := 1
 
-	if header == 4 {
-		if i+1 >= len(data) {
-			i++
-			goto eof
+	if o.B {
+		l++
+	}
+
+	if x := o.U32; x >= 1<<21 {
+		l += 5
+	} else if x != 0 {
+		for l += 2; x >= 0x80; l++ {
+			x >>= 7
 		}
-		x := uint64(data[i])
-		i++
+	}
 
-		if x >= 0x80 {
-			x &= 0x7f
-			for shift := uint(7); ; shift += 7 {
-				b := uint64(data[i])
-				i++
-				if i >= len(data) {
-					goto eof
-				}
+	if x := o.U64; x >= 1<<49 {
+		l += 9
+	} else if x != 0 {
+		for l += 2; x >= 0x80; l++ {
+			x >>= 7
+		}
+	}
+
+	if v := o.I32; v != 0 {
+		x := uint32(v)
+		if v < 0 {
+			x = ^x + 1
+		}
+		for l += 2; x >= 0x80; l++ {
+			x >>= 7
+		}
+	}
+
+	if v := o.I64; v != 0 {
+		l += 2
+		x := uint64(v)
+		if v < 0 {
+			x = ^x + 1
+		}
+		for n := 0; x >= 0x80 && n < 8; n++ {
+			x >>= 7
+			l++
+		}
+	}
+
+	if o.F32 != 0 {
+		l += 5
+	}
+
+	if o.F64 != 0 {
+		l += 9
+	}
+
+	if v := o.T; !v.IsZero() {
+		if s := uint64(v.Unix()); s < 1<<32 {
+			l += 9
+		} else {
+			l += 13
+		}
+	}
+
+	if x := len(o.S); x != 0 {
+		if x > limits.SizeMax {
+			return 0, ColferMax(fmt.Sprintf("colfer: field gen.o.s exceeds %d bytes", limits.SizeMax))
+		}
+		for l += x + 2; x >= 0x80; l++ {
+			x >>= 7
+		}
+	}
+
+	if x := len(o.A); x != 0 {
+		if x > limits.SizeMax {
+			return 0, ColferMax(fmt.Sprintf("colfer: field gen.o.a exceeds %d bytes", limits.SizeMax))
+		}
+		for l += x + 2; x >= 0x80; l++ {
+			x >>= 7
+		}
+	}
+
+	if v := o.O; v != nil {
+		if v == o {
+			return 0, ColferDepth("colfer: field gen.o.o makes a reference cycle")
+		}
+		vl, err := v.MarshalLenWith(limits)
+		if err != nil {
+			return 0, err
+		}
+		l += vl + 1
+	}
+
+	if x := len(o.Os); x != 0 {
+		if x > limits.ListMax {
+			return 0, ColferMax(fmt.Sprintf("colfer: field gen.o.os exceeds %d elements", limits.ListMax))
+		}
+		for l += 2; x >= 0x80; l++ {
+			x >>= 7
+		}
+		for _, v := range o.Os {
+			if v == nil {
+				l++
+				continue
+			}
+			if v == o {
+				return 0, ColferDepth("colfer: field gen.o.os makes a reference cycle")
+			}
+			vl, err := v.MarshalLenWith(limits)
+			if err != nil {
+				return 0, err
+			}
+			l += vl
+		}
+		if l > limits.SizeMax {
+			return 0, ColferMax(fmt.Sprintf("colfer: struct gen.o size exceeds %d bytes", limits.SizeMax))
+		}
+	}
+
+	if x := len(o.Ss); x != 0 {
+		if x > limits.ListMax {
+			return 0, ColferMax(fmt.Sprintf("colfer: field gen.o.ss exceeds %d elements", limits.ListMax))
+		}
+		for l += 2; x >= 0x80; l++ {
+			x >>= 7
+		}
+		for _, a := range o.Ss {
+			x = len(a)
+			if x > limits.SizeMax {
+				return 0, ColferMax(fmt.Sprintf("colfer: field gen.o.ss exceeds %d bytes", limits.SizeMax))
+			}
+			for l += x + 1; x >= 0x80; l++ {
+				x >>= 7
+			}
+		}
+		if l >= limits.SizeMax {
+			return 0, ColferMax(fmt.Sprintf("colfer: struct gen.o size exceeds %d bytes", limits.SizeMax))
+		}
+	}
+
+	if x := len(o.As); x != 0 {
+		if x > limits.ListMax {
+			return 0, ColferMax(fmt.Sprintf("colfer: field gen.o.as exceeds %d elements", limits.ListMax))
+		}
+		for l += 2; x >= 0x80; l++ {
+			x >>= 7
+		}
+		for _, a := range o.As {
+			x = len(a)
+			if x > limits.SizeMax {
+				return 0, ColferMax(fmt.Sprintf("colfer: field gen.o.as exceeds %d bytes", limits.SizeMax))
+			}
+			for l += x + 1; x >= 0x80; l++ {
+				x >>= 7
+			}
+		}
+		if l >= limits.SizeMax {
+			return 0, ColferMax(fmt.Sprintf("colfer: struct gen.o size exceeds %d bytes", limits.SizeMax))
+		}
+	}
+
+	if x := o.U8; x != 0 {
+		l += 2
+	}
+
+	if x := o.U16; x >= 1<<8 {
+		l += 3
+	} else if x != 0 {
+		l += 2
+	}
+
+	if x := len(o.F32s); x != 0 {
+		if x > limits.ListMax {
+			return 0, ColferMax(fmt.Sprintf("colfer: field gen.o.f32s exceeds %d elements", limits.ListMax))
+		}
+		for l += 2 + x*4; x >= 0x80; l++ {
+			x >>= 7
+		}
+	}
+
+	if x := len(o.F64s); x != 0 {
+		if x > limits.ListMax {
+			return 0, ColferMax(fmt.Sprintf("colfer: field gen.o.f64s exceeds %d elements", limits.ListMax))
+		}
+		for l += 2 + x*8; x >= 0x80; l++ {
+			x >>= 7
+		}
+	}
+
+	if x := uint8(o.E); x != 0 {
+		l += 2
+	}
+
+	if x := len(o.Bs); x != 0 {
+		if x > limits.ListMax {
+			return 0, ColferMax(fmt.Sprintf("colfer: field gen.o.bs exceeds %d elements", limits.ListMax))
+		}
+		for l += 2 + x; x >= 0x80; l++ {
+			x >>= 7
+		}
+	}
+
+	if x := len(o.U8s); x != 0 {
+		if x > limits.ListMax {
+			return 0, ColferMax(fmt.Sprintf("colfer: field gen.o.u8s exceeds %d elements", limits.ListMax))
+		}
+		for l += 2 + x; x >= 0x80; l++ {
+			x >>= 7
+		}
+	}
+
+	if x := len(o.U16s); x != 0 {
+		if x > limits.ListMax {
+			return 0, ColferMax(fmt.Sprintf("colfer: field gen.o.u16s exceeds %d elements", limits.ListMax))
+		}
+		for l += 2; x >= 0x80; l++ {
+			x >>= 7
+		}
+		for _, v := range o.U16s {
+			x := uint32(v)
+			for x >= 0x80 {
+				x >>= 7
+				l++
+			}
+			l++
+		}
+		if l > limits.SizeMax {
+			return 0, ColferMax(fmt.Sprintf("colfer: struct gen.o size exceeds %d bytes", limits.SizeMax))
+		}
+	}
+
+	if x := len(o.U32s); x != 0 {
+		if x > limits.ListMax {
+			return 0, ColferMax(fmt.Sprintf("colfer: field gen.o.u32s exceeds %d elements", limits.ListMax))
+		}
+		for l += 2; x >= 0x80; l++ {
+			x >>= 7
+		}
+		for _, v := range o.U32s {
+			x := uint32(v)
+			for x >= 0x80 {
+				x >>= 7
+				l++
+			}
+			l++
+		}
+		if l > limits.SizeMax {
+			return 0, ColferMax(fmt.Sprintf("colfer: struct gen.o size exceeds %d bytes", limits.SizeMax))
+		}
+	}
+
+	if x := len(o.U64s); x != 0 {
+		if x > limits.ListMax {
+			return 0, ColferMax(fmt.Sprintf("colfer: field gen.o.u64s exceeds %d elements", limits.ListMax))
+		}
+		for l += 2; x >= 0x80; l++ {
+			x >>= 7
+		}
+		for _, v := range o.U64s {
+			x := uint64(v)
+			for n := 0; x >= 0x80 && n < 8; n++ {
+				x >>= 7
+				l++
+			}
+			l++
+		}
+		if l > limits.SizeMax {
+			return 0, ColferMax(fmt.Sprintf("colfer: struct gen.o size exceeds %d bytes", limits.SizeMax))
+		}
+	}
+
+	if x := len(o.I32s); x != 0 {
+		if x > limits.ListMax {
+			return 0, ColferMax(fmt.Sprintf("colfer: field gen.o.i32s exceeds %d elements", limits.ListMax))
+		}
+		for l += 2; x >= 0x80; l++ {
+			x >>= 7
+		}
+		for _, v := range o.I32s {
+			x := uint32(v<<1) ^ uint32(v>>31)
+			for x >= 0x80 {
+				x >>= 7
+				l++
+			}
+			l++
+		}
+		if l > limits.SizeMax {
+			return 0, ColferMax(fmt.Sprintf("colfer: struct gen.o size exceeds %d bytes", limits.SizeMax))
+		}
+	}
+
+	if x := len(o.I64s); x != 0 {
+		if x > limits.ListMax {
+			return 0, ColferMax(fmt.Sprintf("colfer: field gen.o.i64s exceeds %d elements", limits.ListMax))
+		}
+		for l += 2; x >= 0x80; l++ {
+			x >>= 7
+		}
+		for _, v := range o.I64s {
+			x := uint64(v<<1) ^ uint64(v>>63)
+			for n := 0; x >= 0x80 && n < 8; n++ {
+				x >>= 7
+				l++
+			}
+			l++
+		}
+		if l > limits.SizeMax {
+			return 0, ColferMax(fmt.Sprintf("colfer: struct gen.o size exceeds %d bytes", limits.SizeMax))
+		}
+	}
+
+	if x := len(o.Es); x != 0 {
+		if x > limits.ListMax {
+			return 0, ColferMax(fmt.Sprintf("colfer: field gen.o.es exceeds %d elements", limits.ListMax))
+		}
+		for l += 2 + x; x >= 0x80; l++ {
+			x >>= 7
+		}
+	}
+
+	if x := len(o.Mt); x != 0 {
+		if x > limits.ListMax {
+			return 0, ColferMax(fmt.Sprintf("colfer: field gen.o.mt exceeds %d elements", limits.ListMax))
+		}
+		for l += 2; x >= 0x80; l++ {
+			x >>= 7
+		}
+		for k, v := range o.Mt {
+			kx := len(k)
+			if kx > limits.SizeMax {
+				return 0, ColferMax(fmt.Sprintf("colfer: field gen.o.mt key exceeds %d bytes", limits.SizeMax))
+			}
+			for l += kx + 1; kx >= 0x80; l++ {
+				kx >>= 7
+			}
+			vx := len(v)
+			if vx > limits.SizeMax {
+				return 0, ColferMax(fmt.Sprintf("colfer: field gen.o.mt value exceeds %d bytes", limits.SizeMax))
+			}
+			for l += vx + 1; vx >= 0x80; l++ {
+				vx >>= 7
+			}
+		}
+		if l > limits.SizeMax {
+			return 0, ColferMax(fmt.Sprintf("colfer: struct gen.o size exceeds %d bytes", limits.SizeMax))
+		}
+	}
+
+	if x := len(o.Mu); x != 0 {
+		if x > limits.ListMax {
+			return 0, ColferMax(fmt.Sprintf("colfer: field gen.o.mu exceeds %d elements", limits.ListMax))
+		}
+		for l += 2; x >= 0x80; l++ {
+			x >>= 7
+		}
+		for k, v := range o.Mu {
+			kx := uint32(k)
+			for kx >= 0x80 {
+				kx >>= 7
+				l++
+			}
+			l++
+			vx := uint64(v<<1) ^ uint64(v>>63)
+			for n := 0; vx >= 0x80 && n < 8; n++ {
+				vx >>= 7
+				l++
+			}
+			l++
+		}
+		if l > limits.SizeMax {
+			return 0, ColferMax(fmt.Sprintf("colfer: struct gen.o size exceeds %d bytes", limits.SizeMax))
+		}
+	}
+
+	if x := len(o.Mi); x != 0 {
+		if x > limits.ListMax {
+			return 0, ColferMax(fmt.Sprintf("colfer: field gen.o.mi exceeds %d elements", limits.ListMax))
+		}
+		for l += 2; x >= 0x80; l++ {
+			x >>= 7
+		}
+		for k, v := range o.Mi {
+			kx := uint64(k<<1) ^ uint64(k>>63)
+			for n := 0; kx >= 0x80 && n < 8; n++ {
+				kx >>= 7
+				l++
+			}
+			l++
+			vx := len(v)
+			if vx > limits.SizeMax {
+				return 0, ColferMax(fmt.Sprintf("colfer: field gen.o.mi value exceeds %d bytes", limits.SizeMax))
+			}
+			for l += vx + 1; vx >= 0x80; l++ {
+				vx >>= 7
+			}
+		}
+		if l > limits.SizeMax {
+			return 0, ColferMax(fmt.Sprintf("colfer: struct gen.o size exceeds %d bytes", limits.SizeMax))
+		}
+	}
+
+	if x := len(o.Mo); x != 0 {
+		if x > limits.ListMax {
+			return 0, ColferMax(fmt.Sprintf("colfer: field gen.o.mo exceeds %d elements", limits.ListMax))
+		}
+		for l += 2; x >= 0x80; l++ {
+			x >>= 7
+		}
+		for k, v := range o.Mo {
+			kx := len(k)
+			if kx > limits.SizeMax {
+				return 0, ColferMax(fmt.Sprintf("colfer: field gen.o.mo key exceeds %d bytes", limits.SizeMax))
+			}
+			for l += kx + 1; kx >= 0x80; l++ {
+				kx >>= 7
+			}
+			if v == nil {
+				l++
+			} else if v == o {
+				return 0, ColferDepth("colfer: field gen.o.mo makes a reference cycle")
+			} else {
+				vl, err := v.MarshalLenWith(limits)
+				if err != nil {
+					return 0, err
+				}
+				l += vl
+			}
+		}
+		if l > limits.SizeMax {
+			return 0, ColferMax(fmt.Sprintf("colfer: struct gen.o size exceeds %d bytes", limits.SizeMax))
+		}
+	}
+
+	if o.Gap {
+		l++
+	}
+
+	if x := len(o.Host); x != 0 {
+		if x > 4 {
+			return 0, ColferMax(fmt.Sprintf("colfer: field gen.o.host exceeds %d bytes", 4))
+		}
+		for l += x + 2; x >= 0x80; l++ {
+			x >>= 7
+		}
+	}
+
+	if x := len(o.Tags); x != 0 {
+		if x > 2 {
+			return 0, ColferMax(fmt.Sprintf("colfer: field gen.o.tags exceeds %d elements", 2))
+		}
+		for l += 2; x >= 0x80; l++ {
+			x >>= 7
+		}
+		for _, a := range o.Tags {
+			x = len(a)
+			if x > 1 {
+				return 0, ColferMax(fmt.Sprintf("colfer: field gen.o.tags exceeds %d bytes", 1))
+			}
+			for l += x + 1; x >= 0x80; l++ {
+				x >>= 7
+			}
+		}
+		if l >= limits.SizeMax {
+			return 0, ColferMax(fmt.Sprintf("colfer: struct gen.o size exceeds %d bytes", limits.SizeMax))
+		}
+	}
+
+	if v := o.Ob; v != nil {
+		l++
+	}
+
+	if v := o.Ou32; v != nil {
+		if x := *v; x >= 1<<21 {
+			l += 5
+		} else {
+			for l += 2; x >= 0x80; l++ {
+				x >>= 7
+			}
+		}
+	}
+
+	if v := o.Oi64; v != nil {
+		l += 2
+		x := uint64(*v)
+		if *v < 0 {
+			x = ^x + 1
+		}
+		for n := 0; x >= 0x80 && n < 8; n++ {
+			x >>= 7
+			l++
+		}
+	}
+
+	if v := o.Of64; v != nil {
+		l += 9
+	}
+
+	if v := o.U; v != nil {
+		l += 2
+		switch v := v.(type) {
+		case *O:
+			if v == nil {
+				v = new(O)
+			}
+			if v == o {
+				return 0, ColferDepth("colfer: field gen.o.u makes a reference cycle")
+			}
+			vl, err := v.MarshalLenWith(limits)
+			if err != nil {
+				return 0, err
+			}
+			l += vl
+		case *Point:
+			if v == nil {
+				v = new(Point)
+			}
+			vl, err := v.MarshalLenWith(limits)
+			if err != nil {
+				return 0, err
+			}
+			l += vl
+		}
+	}
+
+	if l > limits.SizeMax {
+		return l, ColferMax(fmt.Sprintf("colfer: struct gen.o exceeds %d bytes", limits.SizeMax))
+	}
+	return l, nil
+}
+
+// MarshalAppend encodes o as Colfer and appends the serial to dst, with
+// growth as with the built-in append.
+// All nil entries in o.Os will be replaced with a new value.
+// A nil pointer in o.U will be replaced with a new value.
+// The error return options are gen.ColferMax and
+// gen.ColferDepth, in which case dst is returned as is.
+func (o *O) MarshalAppend(dst []byte) ([]byte, error) {
+	l, err := o.MarshalLen()
+	if err != nil {
+		return dst, err
+	}
+	offset := len(dst)
+	dst = append(dst, make([]byte, l)...)
+	o.MarshalTo(dst[offset:])
+	return dst, nil
+}
+
+// MarshalBinary encodes o as Colfer conform encoding.BinaryMarshaler.
+// All nil entries in o.Os will be replaced with a new value.
+// A nil pointer in o.U will be replaced with a new value.
+// The error return options are gen.ColferMax and gen.ColferDepth.
+func (o *O) MarshalBinary() (data []byte, err error) {
+	l, err := o.MarshalLen()
+	if err != nil {
+		return nil, err
+	}
+	data = make([]byte, l)
+	o.MarshalTo(data)
+	return data, nil
+}
+
+// Unmarshal decodes data as Colfer and returns the number of bytes read.
+// The limits are the ColferDefaults.
+// The error return options are io.EOF, gen.ColferError, gen.ColferMax and gen.ColferDepth.
+func (o *O) Unmarshal(data []byte) (int, error) {
+	return o.UnmarshalWith(data, ColferDefaults())
+}
+
+// UnmarshalWith decodes data as Colfer like Unmarshal does, with limits
+// instead of the ColferDefaults.
+func (o *O) UnmarshalWith(data []byte, limits ColferLimits) (int, error) {
+	if limits.DepthMax < 1 {
+		return 0, ColferDepth("colfer: struct gen.o exceeds the nesting depth limit")
+	}
+	limits.DepthMax--
+	if len(data) == 0 {
+		return 0, io.EOF
+	}
+	header := data[0]
+	i := 1
+
+	if header == 0 {
+		if i >= len(data) {
+			goto eof
+		}
+		o.B = true
+		header = data[i]
+		i++
+	}
+
+	if header == 1 {
+		start := i
+		i++
+		if i >= len(data) {
+			goto eof
+		}
+		x := uint32(data[start])
+
+		if x >= 0x80 {
+			x &= 0x7f
+			for shift := uint(7); ; shift += 7 {
+				b := uint32(data[i])
+				i++
+				if i >= len(data) {
+					goto eof
+				}
+
+				if b < 0x80 {
+					x |= b << shift
+					break
+				}
+				x |= (b & 0x7f) << shift
+			}
+		}
+		o.U32 = x
+
+		header = data[i]
+		i++
+	} else if header == 1|0x80 {
+		start := i
+		i += 4
+		if i >= len(data) {
+			goto eof
+		}
+		o.U32 = intconv.Uint32(data[start:])
+		header = data[i]
+		i++
+	}
+
+	if header == 2 {
+		start := i
+		i++
+		if i >= len(data) {
+			goto eof
+		}
+		x := uint64(data[start])
+
+		if x >= 0x80 {
+			x &= 0x7f
+			for shift := uint(7); ; shift += 7 {
+				b := uint64(data[i])
+				i++
+				if i >= len(data) {
+					goto eof
+				}
+
+				if b < 0x80 || shift == 56 {
+					x |= b << shift
+					break
+				}
+				x |= (b & 0x7f) << shift
+			}
+		}
+		o.U64 = x
+
+		header = data[i]
+		i++
+	} else if header == 2|0x80 {
+		start := i
+		i += 8
+		if i >= len(data) {
+			goto eof
+		}
+		o.U64 = intconv.Uint64(data[start:])
+		header = data[i]
+		i++
+	}
+
+	if header == 3 {
+		if i+1 >= len(data) {
+			i++
+			goto eof
+		}
+		x := uint32(data[i])
+		i++
+
+		if x >= 0x80 {
+			x &= 0x7f
+			for shift := uint(7); ; shift += 7 {
+				b := uint32(data[i])
+				i++
+				if i >= len(data) {
+					goto eof
+				}
+
+				if b < 0x80 {
+					x |= b << shift
+					break
+				}
+				x |= (b & 0x7f) << shift
+			}
+		}
+		o.I32 = int32(x)
+
+		header = data[i]
+		i++
+	} else if header == 3|0x80 {
+		if i+1 >= len(data) {
+			i++
+			goto eof
+		}
+		x := uint32(data[i])
+		i++
+
+		if x >= 0x80 {
+			x &= 0x7f
+			for shift := uint(7); ; shift += 7 {
+				b := uint32(data[i])
+				i++
+				if i >= len(data) {
+					goto eof
+				}
+
+				if b < 0x80 {
+					x |= b << shift
+					break
+				}
+				x |= (b & 0x7f) << shift
+			}
+		}
+		o.I32 = int32(^x + 1)
+
+		header = data[i]
+		i++
+	}
+
+	if header == 4 {
+		if i+1 >= len(data) {
+			i++
+			goto eof
+		}
+		x := uint64(data[i])
+		i++
+
+		if x >= 0x80 {
+			x &= 0x7f
+			for shift := uint(7); ; shift += 7 {
+				b := uint64(data[i])
+				i++
+				if i >= len(data) {
+					goto eof
+				}
+
+				if b < 0x80 || shift == 56 {
+					x |= b << shift
+					break
+				}
+				x |= (b & 0x7f) << shift
+			}
+		}
+		o.I64 = int64(x)
+
+		header = data[i]
+		i++
+	} else if header == 4|0x80 {
+		if i+1 >= len(data) {
+			i++
+			goto eof
+		}
+		x := uint64(data[i])
+		i++
+
+		if x >= 0x80 {
+			x &= 0x7f
+			for shift := uint(7); ; shift += 7 {
+				b := uint64(data[i])
+				i++
+				if i >= len(data) {
+					goto eof
+				}
+
+				if b < 0x80 || shift == 56 {
+					x |= b << shift
+					break
+				}
+				x |= (b & 0x7f) << shift
+			}
+		}
+		o.I64 = int64(^x + 1)
+
+		header = data[i]
+		i++
+	}
+
+	if header == 5 {
+		start := i
+		i += 4
+		if i >= len(data) {
+			goto eof
+		}
+		o.F32 = math.Float32frombits(intconv.Uint32(data[start:]))
+		header = data[i]
+		i++
+	}
+
+	if header == 6 {
+		start := i
+		i += 8
+		if i >= len(data) {
+			goto eof
+		}
+		o.F64 = math.Float64frombits(intconv.Uint64(data[start:]))
+		header = data[i]
+		i++
+	}
+
+	if header == 7 {
+		start := i
+		i += 8
+		if i >= len(data) {
+			goto eof
+		}
+		o.T = time.Unix(int64(intconv.Uint32(data[start:])), int64(intconv.Uint32(data[start+4:]))).In(time.UTC)
+		header = data[i]
+		i++
+	} else if header == 7|0x80 {
+		start := i
+		i += 12
+		if i >= len(data) {
+			goto eof
+		}
+		o.T = time.Unix(int64(intconv.Uint64(data[start:])), int64(intconv.Uint32(data[start+8:]))).In(time.UTC)
+		header = data[i]
+		i++
+	}
+
+	if header == 8 {
+		if i >= len(data) {
+			goto eof
+		}
+		x := uint(data[i])
+		i++
+
+		if x >= 0x80 {
+			x &= 0x7f
+			for shift := uint(7); ; shift += 7 {
+				if i >= len(data) {
+					goto eof
+				}
+				b := uint(data[i])
+				i++
+
+				if b < 0x80 {
+					x |= b << shift
+					break
+				}
+				x |= (b & 0x7f) << shift
+			}
+		}
+
+		if x > uint(limits.SizeMax) {
+			return 0, ColferMax(fmt.Sprintf("colfer: gen.o.s size %d exceeds %d bytes", x, limits.SizeMax))
+		}
+
+		start := i
+		i += int(x)
+		if i >= len(data) {
+			goto eof
+		}
+		o.S = string(data[start:i])
+
+		header = data[i]
+		i++
+	}
+
+	if header == 9 {
+		if i >= len(data) {
+			goto eof
+		}
+		x := uint(data[i])
+		i++
+
+		if x >= 0x80 {
+			x &= 0x7f
+			for shift := uint(7); ; shift += 7 {
+				if i >= len(data) {
+					goto eof
+				}
+				b := uint(data[i])
+				i++
+
+				if b < 0x80 {
+					x |= b << shift
+					break
+				}
+				x |= (b & 0x7f) << shift
+			}
+		}
+
+		if x > uint(limits.SizeMax) {
+			return 0, ColferMax(fmt.Sprintf("colfer: gen.o.a size %d exceeds %d bytes", x, limits.SizeMax))
+		}
+
+		start := i
+		i += int(x)
+		if i >= len(data) {
+			goto eof
+		}
+		v := make([]byte, int(x))
+		copy(v, data[start:i])
+		o.A = v
+
+		header = data[i]
+		i++
+	}
+
+	if header == 10 {
+		o.O = new(O)
+		n, err := o.O.UnmarshalWith(data[i:], limits)
+		if err != nil {
+			if err == io.EOF && len(data) >= limits.SizeMax {
+				return 0, ColferMax(fmt.Sprintf("colfer: gen.o size exceeds %d bytes", limits.SizeMax))
+			}
+			return 0, err
+		}
+		i += n
+
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 11 {
+		if i >= len(data) {
+			goto eof
+		}
+		x := uint(data[i])
+		i++
+
+		if x >= 0x80 {
+			x &= 0x7f
+			for shift := uint(7); ; shift += 7 {
+				if i >= len(data) {
+					goto eof
+				}
+				b := uint(data[i])
+				i++
+
+				if b < 0x80 {
+					x |= b << shift
+					break
+				}
+				x |= (b & 0x7f) << shift
+			}
+		}
+
+		if x > uint(limits.ListMax) {
+			return 0, ColferMax(fmt.Sprintf("colfer: gen.o.os length %d exceeds %d elements", x, limits.ListMax))
+		}
+
+		l := int(x)
+		a := make([]*O, l)
+		malloc := make([]O, l)
+		for ai := range a {
+			v := &malloc[ai]
+			a[ai] = v
+
+			n, err := v.UnmarshalWith(data[i:], limits)
+			if err != nil {
+				if err == io.EOF && len(data) >= limits.SizeMax {
+					return 0, ColferMax(fmt.Sprintf("colfer: gen.o size exceeds %d bytes", limits.SizeMax))
+				}
+				return 0, err
+			}
+			i += n
+		}
+		o.Os = a
+
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 12 {
+		if i >= len(data) {
+			goto eof
+		}
+		x := uint(data[i])
+		i++
+
+		if x >= 0x80 {
+			x &= 0x7f
+			for shift := uint(7); ; shift += 7 {
+				if i >= len(data) {
+					goto eof
+				}
+				b := uint(data[i])
+				i++
+
+				if b < 0x80 {
+					x |= b << shift
+					break
+				}
+				x |= (b & 0x7f) << shift
+			}
+		}
+
+		if x > uint(limits.ListMax) {
+			return 0, ColferMax(fmt.Sprintf("colfer: gen.o.ss length %d exceeds %d elements", x, limits.ListMax))
+		}
+		a := make([]string, int(x))
+		o.Ss = a
+
+		for ai := range a {
+			if i >= len(data) {
+				goto eof
+			}
+			x := uint(data[i])
+			i++
+
+			if x >= 0x80 {
+				x &= 0x7f
+				for shift := uint(7); ; shift += 7 {
+					if i >= len(data) {
+						goto eof
+					}
+					b := uint(data[i])
+					i++
+
+					if b < 0x80 {
+						x |= b << shift
+						break
+					}
+					x |= (b & 0x7f) << shift
+				}
+			}
+
+			if x > uint(limits.SizeMax) {
+				return 0, ColferMax(fmt.Sprintf("colfer: gen.o.ss element %d size %d exceeds %d bytes", ai, x, limits.SizeMax))
+			}
+
+			start := i
+			i += int(x)
+			if i >= len(data) {
+				goto eof
+			}
+			a[ai] = string(data[start:i])
+		}
+
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 13 {
+		if i >= len(data) {
+			goto eof
+		}
+		x := uint(data[i])
+		i++
+
+		if x >= 0x80 {
+			x &= 0x7f
+			for shift := uint(7); ; shift += 7 {
+				if i >= len(data) {
+					goto eof
+				}
+				b := uint(data[i])
+				i++
+
+				if b < 0x80 {
+					x |= b << shift
+					break
+				}
+				x |= (b & 0x7f) << shift
+			}
+		}
+
+		if x > uint(limits.ListMax) {
+			return 0, ColferMax(fmt.Sprintf("colfer: gen.o.as length %d exceeds %d elements", x, limits.ListMax))
+		}
+		a := make([][]byte, int(x))
+		o.As = a
+		for ai := range a {
+			if i >= len(data) {
+				goto eof
+			}
+			x := uint(data[i])
+			i++
+
+			if x >= 0x80 {
+				x &= 0x7f
+				for shift := uint(7); ; shift += 7 {
+					if i >= len(data) {
+						goto eof
+					}
+					b := uint(data[i])
+					i++
+
+					if b < 0x80 {
+						x |= b << shift
+						break
+					}
+					x |= (b & 0x7f) << shift
+				}
+			}
+
+			if x > uint(limits.SizeMax) {
+				return 0, ColferMax(fmt.Sprintf("colfer: gen.o.as element %d size %d exceeds %d bytes", ai, x, limits.SizeMax))
+			}
+
+			start := i
+			i += int(x)
+			if i >= len(data) {
+				goto eof
+			}
+			v := make([]byte, int(x))
+			copy(v, data[start:i])
+			a[ai] = v
+		}
+
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 14 {
+		start := i
+		i++
+		if i >= len(data) {
+			goto eof
+		}
+		o.U8 = data[start]
+		header = data[i]
+		i++
+	}
+
+	if header == 15 {
+		start := i
+		i += 2
+		if i >= len(data) {
+			goto eof
+		}
+		o.U16 = intconv.Uint16(data[start:])
+		header = data[i]
+		i++
+	} else if header == 15|0x80 {
+		start := i
+		i++
+		if i >= len(data) {
+			goto eof
+		}
+		o.U16 = uint16(data[start])
+		header = data[i]
+		i++
+	}
+
+	if header == 16 {
+		if i >= len(data) {
+			goto eof
+		}
+		x := uint(data[i])
+		i++
+
+		if x >= 0x80 {
+			x &= 0x7f
+			for shift := uint(7); ; shift += 7 {
+				if i >= len(data) {
+					goto eof
+				}
+				b := uint(data[i])
+				i++
+
+				if b < 0x80 {
+					x |= b << shift
+					break
+				}
+				x |= (b & 0x7f) << shift
+			}
+		}
+
+		if x > uint(limits.ListMax) {
+			return 0, ColferMax(fmt.Sprintf("colfer: gen.o.f32s length %d exceeds %d elements", x, limits.ListMax))
+		}
+
+		l := int(x)
+
+		if end := i + l*4; end >= len(data) {
+			i = end
+			goto eof
+		}
+		a := make([]float32, l)
+		for ai := range a {
+			a[ai] = math.Float32frombits(intconv.Uint32(data[i:]))
+			i += 4
+		}
+		o.F32s = a
+
+		header = data[i]
+		i++
+	}
+
+	if header == 17 {
+		if i >= len(data) {
+			goto eof
+		}
+		x := uint(data[i])
+		i++
+
+		if x >= 0x80 {
+			x &= 0x7f
+			for shift := uint(7); ; shift += 7 {
+				if i >= len(data) {
+					goto eof
+				}
+				b := uint(data[i])
+				i++
+
+				if b < 0x80 {
+					x |= b << shift
+					break
+				}
+				x |= (b & 0x7f) << shift
+			}
+		}
+
+		if x > uint(limits.ListMax) {
+			return 0, ColferMax(fmt.Sprintf("colfer: gen.o.f64s length %d exceeds %d elements", x, limits.ListMax))
+		}
+		l := int(x)
+
+		if end := i + l*8; end >= len(data) {
+			i = end
+			goto eof
+		}
+		a := make([]float64, l)
+		for ai := range a {
+			a[ai] = math.Float64frombits(intconv.Uint64(data[i:]))
+			i += 8
+		}
+		o.F64s = a
+
+		header = data[i]
+		i++
+	}
+
+	if header == 18 {
+		start := i
+		i++
+		if i >= len(data) {
+			goto eof
+		}
+		o.E = Color(data[start])
+		header = data[i]
+		i++
+	}
+
+	if header == 19 {
+		if i >= len(data) {
+			goto eof
+		}
+		x := uint(data[i])
+		i++
+
+		if x >= 0x80 {
+			x &= 0x7f
+			for shift := uint(7); ; shift += 7 {
+				if i >= len(data) {
+					goto eof
+				}
+				b := uint(data[i])
+				i++
+
+				if b < 0x80 {
+					x |= b << shift
+					break
+				}
+				x |= (b & 0x7f) << shift
+			}
+		}
+
+		if x > uint(limits.ListMax) {
+			return 0, ColferMax(fmt.Sprintf("colfer: gen.o.bs length %d exceeds %d elements", x, limits.ListMax))
+		}
+		l := int(x)
+
+		if end := i + l; end >= len(data) {
+			i = end
+			goto eof
+		}
+		a := make([]bool, l)
+		for ai := range a {
+			a[ai] = data[i] != 0
+			i++
+		}
+		o.Bs = a
+
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 20 {
+		if i >= len(data) {
+			goto eof
+		}
+		x := uint(data[i])
+		i++
+
+		if x >= 0x80 {
+			x &= 0x7f
+			for shift := uint(7); ; shift += 7 {
+				if i >= len(data) {
+					goto eof
+				}
+				b := uint(data[i])
+				i++
+
+				if b < 0x80 {
+					x |= b << shift
+					break
+				}
+				x |= (b & 0x7f) << shift
+			}
+		}
+
+		if x > uint(limits.ListMax) {
+			return 0, ColferMax(fmt.Sprintf("colfer: gen.o.u8s length %d exceeds %d elements", x, limits.ListMax))
+		}
+		l := int(x)
+
+		if end := i + l; end >= len(data) {
+			i = end
+			goto eof
+		}
+		a := make([]uint8, l)
+		i += copy(a, data[i:])
+		o.U8s = a
+
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 21 {
+		if i >= len(data) {
+			goto eof
+		}
+		x := uint(data[i])
+		i++
+
+		if x >= 0x80 {
+			x &= 0x7f
+			for shift := uint(7); ; shift += 7 {
+				if i >= len(data) {
+					goto eof
+				}
+				b := uint(data[i])
+				i++
+
+				if b < 0x80 {
+					x |= b << shift
+					break
+				}
+				x |= (b & 0x7f) << shift
+			}
+		}
+
+		if x > uint(limits.ListMax) {
+			return 0, ColferMax(fmt.Sprintf("colfer: gen.o.u16s length %d exceeds %d elements", x, limits.ListMax))
+		}
+		l := int(x)
+
+		if end := i + l; end >= len(data) {
+			i = end
+			goto eof
+		}
+		a := make([]uint16, l)
+		for ai := range a {
+			if i >= len(data) {
+				goto eof
+			}
+			x := uint64(data[i])
+			i++
+
+			if x >= 0x80 {
+				x &= 0x7f
+				for shift := uint(7); ; shift += 7 {
+					if i >= len(data) {
+						goto eof
+					}
+					b := uint64(data[i])
+					i++
+
+					if b < 0x80 || shift == 56 {
+						x |= b << shift
+						break
+					}
+					x |= (b & 0x7f) << shift
+				}
+			}
+			a[ai] = uint16(x)
+		}
+		o.U16s = a
+
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 22 {
+		if i >= len(data) {
+			goto eof
+		}
+		x := uint(data[i])
+		i++
+
+		if x >= 0x80 {
+			x &= 0x7f
+			for shift := uint(7); ; shift += 7 {
+				if i >= len(data) {
+					goto eof
+				}
+				b := uint(data[i])
+				i++
+
+				if b < 0x80 {
+					x |= b << shift
+					break
+				}
+				x |= (b & 0x7f) << shift
+			}
+		}
+
+		if x > uint(limits.ListMax) {
+			return 0, ColferMax(fmt.Sprintf("colfer: gen.o.u32s length %d exceeds %d elements", x, limits.ListMax))
+		}
+		l := int(x)
+
+		if end := i + l; end >= len(data) {
+			i = end
+			goto eof
+		}
+		a := make([]uint32, l)
+		for ai := range a {
+			if i >= len(data) {
+				goto eof
+			}
+			x := uint64(data[i])
+			i++
+
+			if x >= 0x80 {
+				x &= 0x7f
+				for shift := uint(7); ; shift += 7 {
+					if i >= len(data) {
+						goto eof
+					}
+					b := uint64(data[i])
+					i++
+
+					if b < 0x80 || shift == 56 {
+						x |= b << shift
+						break
+					}
+					x |= (b & 0x7f) << shift
+				}
+			}
+			a[ai] = uint32(x)
+		}
+		o.U32s = a
+
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 23 {
+		if i >= len(data) {
+			goto eof
+		}
+		x := uint(data[i])
+		i++
+
+		if x >= 0x80 {
+			x &= 0x7f
+			for shift := uint(7); ; shift += 7 {
+				if i >= len(data) {
+					goto eof
+				}
+				b := uint(data[i])
+				i++
+
+				if b < 0x80 {
+					x |= b << shift
+					break
+				}
+				x |= (b & 0x7f) << shift
+			}
+		}
+
+		if x > uint(limits.ListMax) {
+			return 0, ColferMax(fmt.Sprintf("colfer: gen.o.u64s length %d exceeds %d elements", x, limits.ListMax))
+		}
+		l := int(x)
+
+		if end := i + l; end >= len(data) {
+			i = end
+			goto eof
+		}
+		a := make([]uint64, l)
+		for ai := range a {
+			if i >= len(data) {
+				goto eof
+			}
+			x := uint64(data[i])
+			i++
+
+			if x >= 0x80 {
+				x &= 0x7f
+				for shift := uint(7); ; shift += 7 {
+					if i >= len(data) {
+						goto eof
+					}
+					b := uint64(data[i])
+					i++
+
+					if b < 0x80 || shift == 56 {
+						x |= b << shift
+						break
+					}
+					x |= (b & 0x7f) << shift
+				}
+			}
+			a[ai] = uint64(x)
+		}
+		o.U64s = a
+
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 24 {
+		if i >= len(data) {
+			goto eof
+		}
+		x := uint(data[i])
+		i++
+
+		if x >= 0x80 {
+			x &= 0x7f
+			for shift := uint(7); ; shift += 7 {
+				if i >= len(data) {
+					goto eof
+				}
+				b := uint(data[i])
+				i++
+
+				if b < 0x80 {
+					x |= b << shift
+					break
+				}
+				x |= (b & 0x7f) << shift
+			}
+		}
+
+		if x > uint(limits.ListMax) {
+			return 0, ColferMax(fmt.Sprintf("colfer: gen.o.i32s length %d exceeds %d elements", x, limits.ListMax))
+		}
+		l := int(x)
+
+		if end := i + l; end >= len(data) {
+			i = end
+			goto eof
+		}
+		a := make([]int32, l)
+		for ai := range a {
+			if i >= len(data) {
+				goto eof
+			}
+			x := uint64(data[i])
+			i++
+
+			if x >= 0x80 {
+				x &= 0x7f
+				for shift := uint(7); ; shift += 7 {
+					if i >= len(data) {
+						goto eof
+					}
+					b := uint64(data[i])
+					i++
+
+					if b < 0x80 || shift == 56 {
+						x |= b << shift
+						break
+					}
+					x |= (b & 0x7f) << shift
+				}
+			}
+			a[ai] = int32(x>>1) ^ -int32(x&1)
+		}
+		o.I32s = a
+
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 25 {
+		if i >= len(data) {
+			goto eof
+		}
+		x := uint(data[i])
+		i++
+
+		if x >= 0x80 {
+			x &= 0x7f
+			for shift := uint(7); ; shift += 7 {
+				if i >= len(data) {
+					goto eof
+				}
+				b := uint(data[i])
+				i++
+
+				if b < 0x80 {
+					x |= b << shift
+					break
+				}
+				x |= (b & 0x7f) << shift
+			}
+		}
+
+		if x > uint(limits.ListMax) {
+			return 0, ColferMax(fmt.Sprintf("colfer: gen.o.i64s length %d exceeds %d elements", x, limits.ListMax))
+		}
+		l := int(x)
+
+		if end := i + l; end >= len(data) {
+			i = end
+			goto eof
+		}
+		a := make([]int64, l)
+		for ai := range a {
+			if i >= len(data) {
+				goto eof
+			}
+			x := uint64(data[i])
+			i++
+
+			if x >= 0x80 {
+				x &= 0x7f
+				for shift := uint(7); ; shift += 7 {
+					if i >= len(data) {
+						goto eof
+					}
+					b := uint64(data[i])
+					i++
+
+					if b < 0x80 || shift == 56 {
+						x |= b << shift
+						break
+					}
+					x |= (b & 0x7f) << shift
+				}
+			}
+			a[ai] = int64(x>>1) ^ -int64(x&1)
+		}
+		o.I64s = a
+
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 26 {
+		if i >= len(data) {
+			goto eof
+		}
+		x := uint(data[i])
+		i++
+
+		if x >= 0x80 {
+			x &= 0x7f
+			for shift := uint(7); ; shift += 7 {
+				if i >= len(data) {
+					goto eof
+				}
+				b := uint(data[i])
+				i++
+
+				if b < 0x80 {
+					x |= b << shift
+					break
+				}
+				x |= (b & 0x7f) << shift
+			}
+		}
+
+		if x > uint(limits.ListMax) {
+			return 0, ColferMax(fmt.Sprintf("colfer: gen.o.es length %d exceeds %d elements", x, limits.ListMax))
+		}
+		l := int(x)
+
+		if end := i + l; end >= len(data) {
+			i = end
+			goto eof
+		}
+		a := make([]Color, l)
+		for ai := range a {
+			a[ai] = Color(data[i])
+			i++
+		}
+		o.Es = a
+
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 27 {
+		if i >= len(data) {
+			goto eof
+		}
+		x := uint(data[i])
+		i++
+
+		if x >= 0x80 {
+			x &= 0x7f
+			for shift := uint(7); ; shift += 7 {
+				if i >= len(data) {
+					goto eof
+				}
+				b := uint(data[i])
+				i++
+
+				if b < 0x80 {
+					x |= b << shift
+					break
+				}
+				x |= (b & 0x7f) << shift
+			}
+		}
+
+		if x > uint(limits.ListMax) {
+			return 0, ColferMax(fmt.Sprintf("colfer: gen.o.mt length %d exceeds %d elements", x, limits.ListMax))
+		}
+		l := int(x)
+
+		if end := i + l*2; end >= len(data) {
+			i = end
+			goto eof
+		}
+		m := make(map[string]string, l)
+		var last string
+		for mi := 0; mi < l; mi++ {
+			start := i
+			var k string
+			{
+				if i >= len(data) {
+					goto eof
+				}
+				x := uint(data[i])
+				i++
+
+				if x >= 0x80 {
+					x &= 0x7f
+					for shift := uint(7); ; shift += 7 {
+						if i >= len(data) {
+							goto eof
+						}
+						b := uint(data[i])
+						i++
+
+						if b < 0x80 {
+							x |= b << shift
+							break
+						}
+						x |= (b & 0x7f) << shift
+					}
+				}
+
+				if x > uint(limits.SizeMax) {
+					return 0, ColferMax(fmt.Sprintf("colfer: gen.o.mt key size %d exceeds %d bytes", x, limits.SizeMax))
+				}
+				end := i + int(x)
+				if end >= len(data) {
+					i = end
+					goto eof
+				}
+				k = string(data[i:end])
+				i = end
+			}
+			if mi != 0 && k <= last {
+				return 0, ColferError(start)
+			}
+			last = k
+
+			var v string
+			{
+				if i >= len(data) {
+					goto eof
+				}
+				x := uint(data[i])
+				i++
+
+				if x >= 0x80 {
+					x &= 0x7f
+					for shift := uint(7); ; shift += 7 {
+						if i >= len(data) {
+							goto eof
+						}
+						b := uint(data[i])
+						i++
+
+						if b < 0x80 {
+							x |= b << shift
+							break
+						}
+						x |= (b & 0x7f) << shift
+					}
+				}
+
+				if x > uint(limits.SizeMax) {
+					return 0, ColferMax(fmt.Sprintf("colfer: gen.o.mt value size %d exceeds %d bytes", x, limits.SizeMax))
+				}
+				start := i
+				i += int(x)
+				if i >= len(data) {
+					goto eof
+				}
+				v = string(data[start:i])
+			}
+			m[k] = v
+		}
+		o.Mt = m
+
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 28 {
+		if i >= len(data) {
+			goto eof
+		}
+		x := uint(data[i])
+		i++
+
+		if x >= 0x80 {
+			x &= 0x7f
+			for shift := uint(7); ; shift += 7 {
+				if i >= len(data) {
+					goto eof
+				}
+				b := uint(data[i])
+				i++
+
+				if b < 0x80 {
+					x |= b << shift
+					break
+				}
+				x |= (b & 0x7f) << shift
+			}
+		}
+
+		if x > uint(limits.ListMax) {
+			return 0, ColferMax(fmt.Sprintf("colfer: gen.o.mu length %d exceeds %d elements", x, limits.ListMax))
+		}
+		l := int(x)
+
+		if end := i + l*2; end >= len(data) {
+			i = end
+			goto eof
+		}
+		m := make(map[uint32]int64, l)
+		var last uint32
+		for mi := 0; mi < l; mi++ {
+			start := i
+			var k uint32
+			{
+				if i >= len(data) {
+					goto eof
+				}
+				x := uint64(data[i])
+				i++
+
+				if x >= 0x80 {
+					x &= 0x7f
+					for shift := uint(7); ; shift += 7 {
+						if i >= len(data) {
+							goto eof
+						}
+						b := uint64(data[i])
+						i++
+
+						if b < 0x80 || shift == 56 {
+							x |= b << shift
+							break
+						}
+						x |= (b & 0x7f) << shift
+					}
+				}
+
+				k = uint32(x)
+			}
+			if mi != 0 && k <= last {
+				return 0, ColferError(start)
+			}
+			last = k
+
+			var v int64
+			{
+				if i >= len(data) {
+					goto eof
+				}
+				x := uint64(data[i])
+				i++
+
+				if x >= 0x80 {
+					x &= 0x7f
+					for shift := uint(7); ; shift += 7 {
+						if i >= len(data) {
+							goto eof
+						}
+						b := uint64(data[i])
+						i++
+
+						if b < 0x80 || shift == 56 {
+							x |= b << shift
+							break
+						}
+						x |= (b & 0x7f) << shift
+					}
+				}
+
+				v = int64(x>>1) ^ -int64(x&1)
+			}
+			m[k] = v
+		}
+		o.Mu = m
+
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 29 {
+		if i >= len(data) {
+			goto eof
+		}
+		x := uint(data[i])
+		i++
+
+		if x >= 0x80 {
+			x &= 0x7f
+			for shift := uint(7); ; shift += 7 {
+				if i >= len(data) {
+					goto eof
+				}
+				b := uint(data[i])
+				i++
+
+				if b < 0x80 {
+					x |= b << shift
+					break
+				}
+				x |= (b & 0x7f) << shift
+			}
+		}
+
+		if x > uint(limits.ListMax) {
+			return 0, ColferMax(fmt.Sprintf("colfer: gen.o.mi length %d exceeds %d elements", x, limits.ListMax))
+		}
+		l := int(x)
+
+		if end := i + l*2; end >= len(data) {
+			i = end
+			goto eof
+		}
+		m := make(map[int64][]byte, l)
+		var last int64
+		for mi := 0; mi < l; mi++ {
+			start := i
+			var k int64
+			{
+				if i >= len(data) {
+					goto eof
+				}
+				x := uint64(data[i])
+				i++
+
+				if x >= 0x80 {
+					x &= 0x7f
+					for shift := uint(7); ; shift += 7 {
+						if i >= len(data) {
+							goto eof
+						}
+						b := uint64(data[i])
+						i++
+
+						if b < 0x80 || shift == 56 {
+							x |= b << shift
+							break
+						}
+						x |= (b & 0x7f) << shift
+					}
+				}
+
+				k = int64(x>>1) ^ -int64(x&1)
+			}
+			if mi != 0 && k <= last {
+				return 0, ColferError(start)
+			}
+			last = k
+
+			var v []byte
+			{
+				if i >= len(data) {
+					goto eof
+				}
+				x := uint(data[i])
+				i++
+
+				if x >= 0x80 {
+					x &= 0x7f
+					for shift := uint(7); ; shift += 7 {
+						if i >= len(data) {
+							goto eof
+						}
+						b := uint(data[i])
+						i++
+
+						if b < 0x80 {
+							x |= b << shift
+							break
+						}
+						x |= (b & 0x7f) << shift
+					}
+				}
+
+				if x > uint(limits.SizeMax) {
+					return 0, ColferMax(fmt.Sprintf("colfer: gen.o.mi value size %d exceeds %d bytes", x, limits.SizeMax))
+				}
+				start := i
+				i += int(x)
+				if i >= len(data) {
+					goto eof
+				}
+				v = make([]byte, int(x))
+				copy(v, data[start:i])
+			}
+			m[k] = v
+		}
+		o.Mi = m
+
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 30 {
+		if i >= len(data) {
+			goto eof
+		}
+		x := uint(data[i])
+		i++
+
+		if x >= 0x80 {
+			x &= 0x7f
+			for shift := uint(7); ; shift += 7 {
+				if i >= len(data) {
+					goto eof
+				}
+				b := uint(data[i])
+				i++
+
+				if b < 0x80 {
+					x |= b << shift
+					break
+				}
+				x |= (b & 0x7f) << shift
+			}
+		}
+
+		if x > uint(limits.ListMax) {
+			return 0, ColferMax(fmt.Sprintf("colfer: gen.o.mo length %d exceeds %d elements", x, limits.ListMax))
+		}
+		l := int(x)
+
+		if end := i + l*2; end >= len(data) {
+			i = end
+			goto eof
+		}
+		m := make(map[string]*O, l)
+		var last string
+		for mi := 0; mi < l; mi++ {
+			start := i
+			var k string
+			{
+				if i >= len(data) {
+					goto eof
+				}
+				x := uint(data[i])
+				i++
+
+				if x >= 0x80 {
+					x &= 0x7f
+					for shift := uint(7); ; shift += 7 {
+						if i >= len(data) {
+							goto eof
+						}
+						b := uint(data[i])
+						i++
+
+						if b < 0x80 {
+							x |= b << shift
+							break
+						}
+						x |= (b & 0x7f) << shift
+					}
+				}
+
+				if x > uint(limits.SizeMax) {
+					return 0, ColferMax(fmt.Sprintf("colfer: gen.o.mo key size %d exceeds %d bytes", x, limits.SizeMax))
+				}
+				end := i + int(x)
+				if end >= len(data) {
+					i = end
+					goto eof
+				}
+				k = string(data[i:end])
+				i = end
+			}
+			if mi != 0 && k <= last {
+				return 0, ColferError(start)
+			}
+			last = k
+
+			var v *O
+			v = new(O)
+			n, err := v.UnmarshalWith(data[i:], limits)
+			if err != nil {
+				if err == io.EOF && len(data) >= limits.SizeMax {
+					return 0, ColferMax(fmt.Sprintf("colfer: gen.o size exceeds %d bytes", limits.SizeMax))
+				}
+				return 0, err
+			}
+			i += n
+			m[k] = v
+		}
+		o.Mo = m
+
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 32 {
+		if i >= len(data) {
+			goto eof
+		}
+		o.Gap = true
+		header = data[i]
+		i++
+	}
+
+	if header == 33 {
+		if i >= len(data) {
+			goto eof
+		}
+		x := uint(data[i])
+		i++
+
+		if x >= 0x80 {
+			x &= 0x7f
+			for shift := uint(7); ; shift += 7 {
+				if i >= len(data) {
+					goto eof
+				}
+				b := uint(data[i])
+				i++
+
+				if b < 0x80 {
+					x |= b << shift
+					break
+				}
+				x |= (b & 0x7f) << shift
+			}
+		}
+
+		if x > uint(4) {
+			return 0, ColferMax(fmt.Sprintf("colfer: gen.o.host size %d exceeds %d bytes", x, 4))
+		}
+
+		start := i
+		i += int(x)
+		if i >= len(data) {
+			goto eof
+		}
+		o.Host = string(data[start:i])
+
+		header = data[i]
+		i++
+	}
+
+	if header == 34 {
+		if i >= len(data) {
+			goto eof
+		}
+		x := uint(data[i])
+		i++
+
+		if x >= 0x80 {
+			x &= 0x7f
+			for shift := uint(7); ; shift += 7 {
+				if i >= len(data) {
+					goto eof
+				}
+				b := uint(data[i])
+				i++
+
+				if b < 0x80 {
+					x |= b << shift
+					break
+				}
+				x |= (b & 0x7f) << shift
+			}
+		}
+
+		if x > uint(2) {
+			return 0, ColferMax(fmt.Sprintf("colfer: gen.o.tags length %d exceeds %d elements", x, 2))
+		}
+		a := make([]string, int(x))
+		o.Tags = a
+
+		for ai := range a {
+			if i >= len(data) {
+				goto eof
+			}
+			x := uint(data[i])
+			i++
+
+			if x >= 0x80 {
+				x &= 0x7f
+				for shift := uint(7); ; shift += 7 {
+					if i >= len(data) {
+						goto eof
+					}
+					b := uint(data[i])
+					i++
+
+					if b < 0x80 {
+						x |= b << shift
+						break
+					}
+					x |= (b & 0x7f) << shift
+				}
+			}
+
+			if x > uint(1) {
+				return 0, ColferMax(fmt.Sprintf("colfer: gen.o.tags element %d size %d exceeds %d bytes", ai, x, 1))
+			}
+
+			start := i
+			i += int(x)
+			if i >= len(data) {
+				goto eof
+			}
+			a[ai] = string(data[start:i])
+		}
+
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 35 {
+		if i >= len(data) {
+			goto eof
+		}
+		o.Ob = new(bool)
+		*o.Ob = true
+		header = data[i]
+		i++
+	} else if header == 35|0x80 {
+		if i >= len(data) {
+			goto eof
+		}
+		o.Ob = new(bool)
+		header = data[i]
+		i++
+	}
+
+	if header == 36 {
+		start := i
+		i++
+		if i >= len(data) {
+			goto eof
+		}
+		x := uint32(data[start])
+
+		if x >= 0x80 {
+			x &= 0x7f
+			for shift := uint(7); ; shift += 7 {
+				b := uint32(data[i])
+				i++
+				if i >= len(data) {
+					goto eof
+				}
+
+				if b < 0x80 {
+					x |= b << shift
+					break
+				}
+				x |= (b & 0x7f) << shift
+			}
+		}
+		o.Ou32 = new(uint32)
+		*o.Ou32 = x
+
+		header = data[i]
+		i++
+	} else if header == 36|0x80 {
+		start := i
+		i += 4
+		if i >= len(data) {
+			goto eof
+		}
+		o.Ou32 = new(uint32)
+		*o.Ou32 = intconv.Uint32(data[start:])
+		header = data[i]
+		i++
+	}
+
+	if header == 37 {
+		if i+1 >= len(data) {
+			i++
+			goto eof
+		}
+		x := uint64(data[i])
+		i++
+
+		if x >= 0x80 {
+			x &= 0x7f
+			for shift := uint(7); ; shift += 7 {
+				b := uint64(data[i])
+				i++
+				if i >= len(data) {
+					goto eof
+				}
+
+				if b < 0x80 || shift == 56 {
+					x |= b << shift
+					break
+				}
+				x |= (b & 0x7f) << shift
+			}
+		}
+		o.Oi64 = new(int64)
+		*o.Oi64 = int64(x)
+
+		header = data[i]
+		i++
+	} else if header == 37|0x80 {
+		if i+1 >= len(data) {
+			i++
+			goto eof
+		}
+		x := uint64(data[i])
+		i++
+
+		if x >= 0x80 {
+			x &= 0x7f
+			for shift := uint(7); ; shift += 7 {
+				b := uint64(data[i])
+				i++
+				if i >= len(data) {
+					goto eof
+				}
+
+				if b < 0x80 || shift == 56 {
+					x |= b << shift
+					break
+				}
+				x |= (b & 0x7f) << shift
+			}
+		}
+		o.Oi64 = new(int64)
+		*o.Oi64 = int64(^x + 1)
+
+		header = data[i]
+		i++
+	}
+
+	if header == 38 {
+		start := i
+		i += 8
+		if i >= len(data) {
+			goto eof
+		}
+		o.Of64 = new(float64)
+		*o.Of64 = math.Float64frombits(intconv.Uint64(data[start:]))
+		header = data[i]
+		i++
+	}
+
+	if header == 39 {
+		if i >= len(data) {
+			goto eof
+		}
+		var n int
+		var err error
+		switch data[i] {
+		case 0:
+			v := new(O)
+			o.U = v
+			n, err = v.UnmarshalWith(data[i+1:], limits)
+		case 1:
+			v := new(Point)
+			o.U = v
+			n, err = v.UnmarshalWith(data[i+1:], limits)
+		default:
+			return 0, ColferError(i)
+		}
+		if err != nil {
+			if err == io.EOF && len(data) >= limits.SizeMax {
+				return 0, ColferMax(fmt.Sprintf("colfer: gen.o size exceeds %d bytes", limits.SizeMax))
+			}
+			return 0, err
+		}
+		i += n + 1
+
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header != 0x7f {
+		return 0, ColferError(i - 1)
+	}
+	if i < limits.SizeMax {
+		return i, nil
+	}
+eof:
+	if i >= limits.SizeMax {
+		return 0, ColferMax(fmt.Sprintf("colfer: struct gen.o size exceeds %d bytes", limits.SizeMax))
+	}
+	return 0, io.EOF
+}
+
+// UnmarshalBinary decodes data as Colfer conform encoding.BinaryUnmarshaler.
+// The error return options are io.EOF, gen.ColferError, gen.ColferTail, gen.ColferMax and gen.ColferDepth.
+func (o *O) UnmarshalBinary(data []byte) error {
+	i, err := o.Unmarshal(data)
+	if i < len(data) && err == nil {
+		return ColferTail(i)
+	}
+	return err
+}
+
+// Equal returns whether o and x have the same serial. Hence, nil and empty
+// lists, maps and binaries are equal, and so are nil and empty entries in
+// lists, maps and unions. Either of o and x may be nil.
+func (o *O) Equal(x *O) bool {
+	if o == nil || x == nil {
+		return o == x
+	}
+
+	if o.B != x.B {
+		return false
+	}
+
+	if o.U32 != x.U32 {
+		return false
+	}
+
+	if o.U64 != x.U64 {
+		return false
+	}
+
+	if o.I32 != x.I32 {
+		return false
+	}
+
+	if o.I64 != x.I64 {
+		return false
+	}
+
+	if o.F32 != x.F32 && math.Float32bits(o.F32) != math.Float32bits(x.F32) {
+		return false
+	}
+
+	if o.F64 != x.F64 && math.Float64bits(o.F64) != math.Float64bits(x.F64) {
+		return false
+	}
+
+	if !o.T.Equal(x.T) {
+		return false
+	}
+
+	if o.S != x.S {
+		return false
+	}
+
+	if string(o.A) != string(x.A) {
+		return false
+	}
+
+	if !o.O.Equal(x.O) {
+		return false
+	}
+
+	if len(o.Os) != len(x.Os) {
+		return false
+	}
+	for i, v := range o.Os {
+		w := x.Os[i]
+		if v == nil {
+			v = new(O)
+		}
+		if w == nil {
+			w = new(O)
+		}
+		if !v.Equal(w) {
+			return false
+		}
+	}
+
+	if len(o.Ss) != len(x.Ss) {
+		return false
+	}
+	for i, v := range o.Ss {
+		w := x.Ss[i]
+		if v != w {
+			return false
+		}
+	}
+
+	if len(o.As) != len(x.As) {
+		return false
+	}
+	for i, v := range o.As {
+		w := x.As[i]
+		if string(v) != string(w) {
+			return false
+		}
+	}
+
+	if o.U8 != x.U8 {
+		return false
+	}
+
+	if o.U16 != x.U16 {
+		return false
+	}
+
+	if len(o.F32s) != len(x.F32s) {
+		return false
+	}
+	for i, v := range o.F32s {
+		w := x.F32s[i]
+		if math.Float32bits(v) != math.Float32bits(w) {
+			return false
+		}
+	}
+
+	if len(o.F64s) != len(x.F64s) {
+		return false
+	}
+	for i, v := range o.F64s {
+		w := x.F64s[i]
+		if math.Float64bits(v) != math.Float64bits(w) {
+			return false
+		}
+	}
+
+	if o.E != x.E {
+		return false
+	}
+
+	if len(o.Bs) != len(x.Bs) {
+		return false
+	}
+	for i, v := range o.Bs {
+		w := x.Bs[i]
+		if v != w {
+			return false
+		}
+	}
+
+	if len(o.U8s) != len(x.U8s) {
+		return false
+	}
+	for i, v := range o.U8s {
+		w := x.U8s[i]
+		if v != w {
+			return false
+		}
+	}
+
+	if len(o.U16s) != len(x.U16s) {
+		return false
+	}
+	for i, v := range o.U16s {
+		w := x.U16s[i]
+		if v != w {
+			return false
+		}
+	}
+
+	if len(o.U32s) != len(x.U32s) {
+		return false
+	}
+	for i, v := range o.U32s {
+		w := x.U32s[i]
+		if v != w {
+			return false
+		}
+	}
+
+	if len(o.U64s) != len(x.U64s) {
+		return false
+	}
+	for i, v := range o.U64s {
+		w := x.U64s[i]
+		if v != w {
+			return false
+		}
+	}
+
+	if len(o.I32s) != len(x.I32s) {
+		return false
+	}
+	for i, v := range o.I32s {
+		w := x.I32s[i]
+		if v != w {
+			return false
+		}
+	}
+
+	if len(o.I64s) != len(x.I64s) {
+		return false
+	}
+	for i, v := range o.I64s {
+		w := x.I64s[i]
+		if v != w {
+			return false
+		}
+	}
+
+	if len(o.Es) != len(x.Es) {
+		return false
+	}
+	for i, v := range o.Es {
+		w := x.Es[i]
+		if v != w {
+			return false
+		}
+	}
+
+	if len(o.Mt) != len(x.Mt) {
+		return false
+	}
+	for k, v := range o.Mt {
+		w, ok := x.Mt[k]
+		if !ok {
+			return false
+		}
+		if v != w {
+			return false
+		}
+	}
+
+	if len(o.Mu) != len(x.Mu) {
+		return false
+	}
+	for k, v := range o.Mu {
+		w, ok := x.Mu[k]
+		if !ok {
+			return false
+		}
+		if v != w {
+			return false
+		}
+	}
+
+	if len(o.Mi) != len(x.Mi) {
+		return false
+	}
+	for k, v := range o.Mi {
+		w, ok := x.Mi[k]
+		if !ok {
+			return false
+		}
+		if string(v) != string(w) {
+			return false
+		}
+	}
+
+	if len(o.Mo) != len(x.Mo) {
+		return false
+	}
+	for k, v := range o.Mo {
+		w, ok := x.Mo[k]
+		if !ok {
+			return false
+		}
+		if v == nil {
+			v = new(O)
+		}
+		if w == nil {
+			w = new(O)
+		}
+		if !v.Equal(w) {
+			return false
+		}
+	}
+
+	if o.Gap != x.Gap {
+		return false
+	}
+
+	if o.Host != x.Host {
+		return false
+	}
+
+	if len(o.Tags) != len(x.Tags) {
+		return false
+	}
+	for i, v := range o.Tags {
+		w := x.Tags[i]
+		if v != w {
+			return false
+		}
+	}
+
+	if (o.Ob == nil) != (x.Ob == nil) {
+		return false
+	}
+	if o.Ob != nil && *o.Ob != *x.Ob {
+		return false
+	}
+
+	if (o.Ou32 == nil) != (x.Ou32 == nil) {
+		return false
+	}
+	if o.Ou32 != nil && *o.Ou32 != *x.Ou32 {
+		return false
+	}
+
+	if (o.Oi64 == nil) != (x.Oi64 == nil) {
+		return false
+	}
+	if o.Oi64 != nil && *o.Oi64 != *x.Oi64 {
+		return false
+	}
+
+	if (o.Of64 == nil) != (x.Of64 == nil) {
+		return false
+	}
+	if o.Of64 != nil && math.Float64bits(*o.Of64) != math.Float64bits(*x.Of64) {
+		return false
+	}
+
+	switch v := o.U.(type) {
+	case nil:
+		if x.U != nil {
+			return false
+		}
+	case *O:
+		w, ok := x.U.(*O)
+		if !ok {
+			return false
+		}
+		if v == nil {
+			v = new(O)
+		}
+		if w == nil {
+			w = new(O)
+		}
+		if !v.Equal(w) {
+			return false
+		}
+	case *Point:
+		w, ok := x.U.(*Point)
+		if !ok {
+			return false
+		}
+		if v == nil {
+			v = new(Point)
+		}
+		if w == nil {
+			w = new(Point)
+		}
+		if !v.Equal(w) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Clone returns a deep copy of o, or nil when o is nil.
+func (o *O) Clone() *O {
+	if o == nil {
+		return nil
+	}
+	c := *o
+
+	if o.A != nil {
+		c.A = make([]byte, len(o.A))
+		copy(c.A, o.A)
+	}
+
+	c.O = o.O.Clone()
+
+	if o.Os != nil {
+		a := make([]*O, len(o.Os))
+		for i, v := range o.Os {
+			a[i] = v.Clone()
+		}
+		c.Os = a
+	}
+
+	if o.Ss != nil {
+		a := make([]string, len(o.Ss))
+		copy(a, o.Ss)
+		c.Ss = a
+	}
+
+	if o.As != nil {
+		a := make([][]byte, len(o.As))
+		for i, v := range o.As {
+			if v != nil {
+				a[i] = make([]byte, len(v))
+				copy(a[i], v)
+			}
+		}
+		c.As = a
+	}
+
+	if o.F32s != nil {
+		a := make([]float32, len(o.F32s))
+		copy(a, o.F32s)
+		c.F32s = a
+	}
+
+	if o.F64s != nil {
+		a := make([]float64, len(o.F64s))
+		copy(a, o.F64s)
+		c.F64s = a
+	}
+
+	if o.Bs != nil {
+		a := make([]bool, len(o.Bs))
+		copy(a, o.Bs)
+		c.Bs = a
+	}
+
+	if o.U8s != nil {
+		a := make([]uint8, len(o.U8s))
+		copy(a, o.U8s)
+		c.U8s = a
+	}
+
+	if o.U16s != nil {
+		a := make([]uint16, len(o.U16s))
+		copy(a, o.U16s)
+		c.U16s = a
+	}
+
+	if o.U32s != nil {
+		a := make([]uint32, len(o.U32s))
+		copy(a, o.U32s)
+		c.U32s = a
+	}
+
+	if o.U64s != nil {
+		a := make([]uint64, len(o.U64s))
+		copy(a, o.U64s)
+		c.U64s = a
+	}
+
+	if o.I32s != nil {
+		a := make([]int32, len(o.I32s))
+		copy(a, o.I32s)
+		c.I32s = a
+	}
+
+	if o.I64s != nil {
+		a := make([]int64, len(o.I64s))
+		copy(a, o.I64s)
+		c.I64s = a
+	}
+
+	if o.Es != nil {
+		a := make([]Color, len(o.Es))
+		copy(a, o.Es)
+		c.Es = a
+	}
+
+	if o.Mt != nil {
+		m := make(map[string]string, len(o.Mt))
+		for k, v := range o.Mt {
+			m[k] = v
+		}
+		c.Mt = m
+	}
+
+	if o.Mu != nil {
+		m := make(map[uint32]int64, len(o.Mu))
+		for k, v := range o.Mu {
+			m[k] = v
+		}
+		c.Mu = m
+	}
+
+	if o.Mi != nil {
+		m := make(map[int64][]byte, len(o.Mi))
+		for k, v := range o.Mi {
+			if v != nil {
+				b := make([]byte, len(v))
+				copy(b, v)
+				v = b
+			}
+			m[k] = v
+		}
+		c.Mi = m
+	}
+
+	if o.Mo != nil {
+		m := make(map[string]*O, len(o.Mo))
+		for k, v := range o.Mo {
+			m[k] = v.Clone()
+		}
+		c.Mo = m
+	}
+
+	if o.Tags != nil {
+		a := make([]string, len(o.Tags))
+		copy(a, o.Tags)
+		c.Tags = a
+	}
+
+	if v := o.Ob; v != nil {
+		w := *v
+		c.Ob = &w
+	}
+
+	if v := o.Ou32; v != nil {
+		w := *v
+		c.Ou32 = &w
+	}
+
+	if v := o.Oi64; v != nil {
+		w := *v
+		c.Oi64 = &w
+	}
+
+	if v := o.Of64; v != nil {
+		w := *v
+		c.Of64 = &w
+	}
+
+	switch v := o.U.(type) {
+	case *O:
+		c.U = v.Clone()
+	case *Point:
+		c.U = v.Clone()
+	}
+
+	return &c
+}
+
+// MarshalJSON encodes o conform the json.Marshaler interface. The JSON object
+// has the fields present in the serial only, in order of appearance, with the
+// names from the schema. Integers beyond 2^53 - 1 in magnitude are written as
+// a JSON string, as JavaScript can not hold them exactly. The mapping matches
+// the generated Java and JavaScript code, and colf(1) decode.
+func (o *O) MarshalJSON() ([]byte, error) {
+	// each field starts with a comma, of which the first one becomes the
+	// opening brace
+	var buf []byte
+
+	if v := o.B; v {
+		buf = append(buf, ",\"b\":"...)
+		buf = strconv.AppendBool(buf, v)
+	}
+
+	if v := o.U32; v != 0 {
+		buf = append(buf, ",\"u32\":"...)
+		buf = strconv.AppendUint(buf, uint64(v), 10)
+	}
+
+	if v := o.U64; v != 0 {
+		buf = append(buf, ",\"u64\":"...)
+		buf = colferAppendJSONUint(buf, v)
+	}
+
+	if v := o.I32; v != 0 {
+		buf = append(buf, ",\"i32\":"...)
+		buf = strconv.AppendInt(buf, int64(v), 10)
+	}
+
+	if v := o.I64; v != 0 {
+		buf = append(buf, ",\"i64\":"...)
+		buf = colferAppendJSONInt(buf, v)
+	}
+
+	if v := o.F32; v != 0 {
+		buf = append(buf, ",\"f32\":"...)
+		buf = colferAppendJSONFloat(buf, float64(v), 32)
+	}
+
+	if v := o.F64; v != 0 {
+		buf = append(buf, ",\"f64\":"...)
+		buf = colferAppendJSONFloat(buf, v, 64)
+	}
+
+	if v := o.T; !v.IsZero() {
+		buf = append(buf, ",\"t\":"...)
+		buf = colferAppendJSONTime(buf, v)
+	}
+
+	if v := o.S; len(v) != 0 {
+		buf = append(buf, ",\"s\":"...)
+		buf = colferAppendJSONString(buf, v)
+	}
+
+	if v := o.A; len(v) != 0 {
+		buf = append(buf, ",\"a\":"...)
+		buf = colferAppendJSONBinary(buf, v)
+	}
+
+	if v := o.O; v != nil {
+		buf = append(buf, ",\"o\":"...)
+		b, _ := v.MarshalJSON()
+		buf = append(buf, b...)
+	}
+
+	if len(o.Os) != 0 {
+		buf = append(buf, ",\"os\":["...)
+		for i, v := range o.Os {
+			if i != 0 {
+				buf = append(buf, ',')
+			}
+			if v == nil {
+				buf = append(buf, "{}"...)
+			} else {
+				b, _ := v.MarshalJSON()
+				buf = append(buf, b...)
+			}
+		}
+		buf = append(buf, ']')
+	}
+
+	if len(o.Ss) != 0 {
+		buf = append(buf, ",\"ss\":["...)
+		for i, v := range o.Ss {
+			if i != 0 {
+				buf = append(buf, ',')
+			}
+			buf = colferAppendJSONString(buf, v)
+		}
+		buf = append(buf, ']')
+	}
+
+	if len(o.As) != 0 {
+		buf = append(buf, ",\"as\":["...)
+		for i, v := range o.As {
+			if i != 0 {
+				buf = append(buf, ',')
+			}
+			buf = colferAppendJSONBinary(buf, v)
+		}
+		buf = append(buf, ']')
+	}
+
+	if v := o.U8; v != 0 {
+		buf = append(buf, ",\"u8\":"...)
+		buf = strconv.AppendUint(buf, uint64(v), 10)
+	}
+
+	if v := o.U16; v != 0 {
+		buf = append(buf, ",\"u16\":"...)
+		buf = strconv.AppendUint(buf, uint64(v), 10)
+	}
+
+	if len(o.F32s) != 0 {
+		buf = append(buf, ",\"f32s\":["...)
+		for i, v := range o.F32s {
+			if i != 0 {
+				buf = append(buf, ',')
+			}
+			buf = colferAppendJSONFloat(buf, float64(v), 32)
+		}
+		buf = append(buf, ']')
+	}
+
+	if len(o.F64s) != 0 {
+		buf = append(buf, ",\"f64s\":["...)
+		for i, v := range o.F64s {
+			if i != 0 {
+				buf = append(buf, ',')
+			}
+			buf = colferAppendJSONFloat(buf, v, 64)
+		}
+		buf = append(buf, ']')
+	}
+
+	if v := o.E; v != 0 {
+		buf = append(buf, ",\"e\":"...)
+		b, _ := v.MarshalJSON()
+		buf = append(buf, b...)
+	}
+
+	if len(o.Bs) != 0 {
+		buf = append(buf, ",\"bs\":["...)
+		for i, v := range o.Bs {
+			if i != 0 {
+				buf = append(buf, ',')
+			}
+			buf = strconv.AppendBool(buf, v)
+		}
+		buf = append(buf, ']')
+	}
+
+	if len(o.U8s) != 0 {
+		buf = append(buf, ",\"u8s\":["...)
+		for i, v := range o.U8s {
+			if i != 0 {
+				buf = append(buf, ',')
+			}
+			buf = strconv.AppendUint(buf, uint64(v), 10)
+		}
+		buf = append(buf, ']')
+	}
+
+	if len(o.U16s) != 0 {
+		buf = append(buf, ",\"u16s\":["...)
+		for i, v := range o.U16s {
+			if i != 0 {
+				buf = append(buf, ',')
+			}
+			buf = strconv.AppendUint(buf, uint64(v), 10)
+		}
+		buf = append(buf, ']')
+	}
+
+	if len(o.U32s) != 0 {
+		buf = append(buf, ",\"u32s\":["...)
+		for i, v := range o.U32s {
+			if i != 0 {
+				buf = append(buf, ',')
+			}
+			buf = strconv.AppendUint(buf, uint64(v), 10)
+		}
+		buf = append(buf, ']')
+	}
+
+	if len(o.U64s) != 0 {
+		buf = append(buf, ",\"u64s\":["...)
+		for i, v := range o.U64s {
+			if i != 0 {
+				buf = append(buf, ',')
+			}
+			buf = colferAppendJSONUint(buf, v)
+		}
+		buf = append(buf, ']')
+	}
+
+	if len(o.I32s) != 0 {
+		buf = append(buf, ",\"i32s\":["...)
+		for i, v := range o.I32s {
+			if i != 0 {
+				buf = append(buf, ',')
+			}
+			buf = strconv.AppendInt(buf, int64(v), 10)
+		}
+		buf = append(buf, ']')
+	}
+
+	if len(o.I64s) != 0 {
+		buf = append(buf, ",\"i64s\":["...)
+		for i, v := range o.I64s {
+			if i != 0 {
+				buf = append(buf, ',')
+			}
+			buf = colferAppendJSONInt(buf, v)
+		}
+		buf = append(buf, ']')
+	}
+
+	if len(o.Es) != 0 {
+		buf = append(buf, ",\"es\":["...)
+		for i, v := range o.Es {
+			if i != 0 {
+				buf = append(buf, ',')
+			}
+			b, _ := v.MarshalJSON()
+			buf = append(buf, b...)
+		}
+		buf = append(buf, ']')
+	}
+
+	if len(o.Mt) != 0 {
+		buf = append(buf, ",\"mt\":{"...)
+		keys := make([]string, 0, len(o.Mt))
+		for k := range o.Mt {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(a, b int) bool { return keys[a] < keys[b] })
+		for i, k := range keys {
+			if i != 0 {
+				buf = append(buf, ',')
+			}
+			buf = colferAppendJSONString(buf, k)
+			buf = append(buf, ':')
+			v := o.Mt[k]
+			buf = colferAppendJSONString(buf, v)
+		}
+		buf = append(buf, '}')
+	}
+
+	if len(o.Mu) != 0 {
+		buf = append(buf, ",\"mu\":{"...)
+		keys := make([]uint32, 0, len(o.Mu))
+		for k := range o.Mu {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(a, b int) bool { return keys[a] < keys[b] })
+		for i, k := range keys {
+			if i != 0 {
+				buf = append(buf, ',')
+			}
+			buf = append(buf, '"')
+			buf = strconv.AppendUint(buf, uint64(k), 10)
+			buf = append(buf, '"')
+			buf = append(buf, ':')
+			v := o.Mu[k]
+			buf = colferAppendJSONInt(buf, v)
+		}
+		buf = append(buf, '}')
+	}
+
+	if len(o.Mi) != 0 {
+		buf = append(buf, ",\"mi\":{"...)
+		keys := make([]int64, 0, len(o.Mi))
+		for k := range o.Mi {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(a, b int) bool { return keys[a] < keys[b] })
+		for i, k := range keys {
+			if i != 0 {
+				buf = append(buf, ',')
+			}
+			buf = append(buf, '"')
+			buf = strconv.AppendInt(buf, int64(k), 10)
+			buf = append(buf, '"')
+			buf = append(buf, ':')
+			v := o.Mi[k]
+			buf = colferAppendJSONBinary(buf, v)
+		}
+		buf = append(buf, '}')
+	}
+
+	if len(o.Mo) != 0 {
+		buf = append(buf, ",\"mo\":{"...)
+		keys := make([]string, 0, len(o.Mo))
+		for k := range o.Mo {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(a, b int) bool { return keys[a] < keys[b] })
+		for i, k := range keys {
+			if i != 0 {
+				buf = append(buf, ',')
+			}
+			buf = colferAppendJSONString(buf, k)
+			buf = append(buf, ':')
+			v := o.Mo[k]
+			if v == nil {
+				buf = append(buf, "{}"...)
+			} else {
+				b, _ := v.MarshalJSON()
+				buf = append(buf, b...)
+			}
+		}
+		buf = append(buf, '}')
+	}
+
+	if v := o.Gap; v {
+		buf = append(buf, ",\"gap\":"...)
+		buf = strconv.AppendBool(buf, v)
+	}
+
+	if v := o.Host; len(v) != 0 {
+		buf = append(buf, ",\"host\":"...)
+		buf = colferAppendJSONString(buf, v)
+	}
+
+	if len(o.Tags) != 0 {
+		buf = append(buf, ",\"tags\":["...)
+		for i, v := range o.Tags {
+			if i != 0 {
+				buf = append(buf, ',')
+			}
+			buf = colferAppendJSONString(buf, v)
+		}
+		buf = append(buf, ']')
+	}
+
+	if o.Ob != nil {
+		buf = append(buf, ",\"ob\":"...)
+		v := *o.Ob
+		buf = strconv.AppendBool(buf, v)
+	}
+
+	if o.Ou32 != nil {
+		buf = append(buf, ",\"ou32\":"...)
+		v := *o.Ou32
+		buf = strconv.AppendUint(buf, uint64(v), 10)
+	}
+
+	if o.Oi64 != nil {
+		buf = append(buf, ",\"oi64\":"...)
+		v := *o.Oi64
+		buf = colferAppendJSONInt(buf, v)
+	}
+
+	if o.Of64 != nil {
+		buf = append(buf, ",\"of64\":"...)
+		v := *o.Of64
+		buf = colferAppendJSONFloat(buf, v, 64)
+	}
+
+	if o.U != nil {
+		buf = append(buf, ",\"u\":{\"kind\":"...)
+		var b []byte
+		switch v := o.U.(type) {
+		case *O:
+			buf = append(buf, "\"o\""...)
+			if v == nil {
+				v = new(O)
+			}
+			b, _ = v.MarshalJSON()
+		case *Point:
+			buf = append(buf, "\"point\""...)
+			if v == nil {
+				v = new(Point)
+			}
+			b, _ = v.MarshalJSON()
+		}
+		buf = append(buf, ",\"value\":"...)
+		buf = append(buf, b...)
+		buf = append(buf, '}')
+	}
+
+	if len(buf) == 0 {
+		return []byte("{}"), nil
+	}
+	buf[0] = '{'
+	return append(buf, '}'), nil
+}
+
+// UnmarshalJSON decodes data conform the json.Unmarshaler interface, with the
+// mapping of MarshalJSON. Fields absent in data, or with a null value, remain
+// as is.
+func (o *O) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	for name, raw := range fields {
+		if string(raw) == "null" {
+			continue
+		}
+
+		switch name {
+		case "b":
+			var v bool
+			if err := json.Unmarshal(raw, &v); err != nil {
+				return fmt.Errorf("colfer: field gen.o.b: %s", err)
+			}
+			o.B = v
+		case "u32":
+			x, err := colferParseJSONUint(raw, 32)
+			if err != nil {
+				return fmt.Errorf("colfer: field gen.o.u32: %s", err)
+			}
+			v := uint32(x)
+			o.U32 = v
+		case "u64":
+			x, err := colferParseJSONUint(raw, 64)
+			if err != nil {
+				return fmt.Errorf("colfer: field gen.o.u64: %s", err)
+			}
+			v := uint64(x)
+			o.U64 = v
+		case "i32":
+			x, err := colferParseJSONInt(raw, 32)
+			if err != nil {
+				return fmt.Errorf("colfer: field gen.o.i32: %s", err)
+			}
+			v := int32(x)
+			o.I32 = v
+		case "i64":
+			x, err := colferParseJSONInt(raw, 64)
+			if err != nil {
+				return fmt.Errorf("colfer: field gen.o.i64: %s", err)
+			}
+			v := int64(x)
+			o.I64 = v
+		case "f32":
+			x, err := colferParseJSONFloat(raw, 32)
+			if err != nil {
+				return fmt.Errorf("colfer: field gen.o.f32: %s", err)
+			}
+			v := float32(x)
+			o.F32 = v
+		case "f64":
+			x, err := colferParseJSONFloat(raw, 64)
+			if err != nil {
+				return fmt.Errorf("colfer: field gen.o.f64: %s", err)
+			}
+			v := float64(x)
+			o.F64 = v
+		case "t":
+			v, err := colferParseJSONTime(raw)
+			if err != nil {
+				return fmt.Errorf("colfer: field gen.o.t: %s", err)
+			}
+			o.T = v
+		case "s":
+			var v string
+			if err := json.Unmarshal(raw, &v); err != nil {
+				return fmt.Errorf("colfer: field gen.o.s: %s", err)
+			}
+			o.S = v
+		case "a":
+			var v []byte
+			if err := json.Unmarshal(raw, &v); err != nil {
+				return fmt.Errorf("colfer: field gen.o.a: %s", err)
+			}
+			o.A = v
+		case "o":
+			v := new(O)
+			if err := v.UnmarshalJSON(raw); err != nil {
+				return err
+			}
+			o.O = v
+		case "os":
+			var a []json.RawMessage
+			if err := json.Unmarshal(raw, &a); err != nil {
+				return fmt.Errorf("colfer: field gen.o.os: %s", err)
+			}
+			l := make([]*O, len(a))
+			for i, raw := range a {
+				v := new(O)
+				if err := v.UnmarshalJSON(raw); err != nil {
+					return err
+				}
+				l[i] = v
+			}
+			o.Os = l
+		case "ss":
+			var a []json.RawMessage
+			if err := json.Unmarshal(raw, &a); err != nil {
+				return fmt.Errorf("colfer: field gen.o.ss: %s", err)
+			}
+			l := make([]string, len(a))
+			for i, raw := range a {
+				var v string
+				if err := json.Unmarshal(raw, &v); err != nil {
+					return fmt.Errorf("colfer: field gen.o.ss: %s", err)
+				}
+				l[i] = v
+			}
+			o.Ss = l
+		case "as":
+			var a []json.RawMessage
+			if err := json.Unmarshal(raw, &a); err != nil {
+				return fmt.Errorf("colfer: field gen.o.as: %s", err)
+			}
+			l := make([][]byte, len(a))
+			for i, raw := range a {
+				var v []byte
+				if err := json.Unmarshal(raw, &v); err != nil {
+					return fmt.Errorf("colfer: field gen.o.as: %s", err)
+				}
+				l[i] = v
+			}
+			o.As = l
+		case "u8":
+			x, err := colferParseJSONUint(raw, 8)
+			if err != nil {
+				return fmt.Errorf("colfer: field gen.o.u8: %s", err)
+			}
+			v := uint8(x)
+			o.U8 = v
+		case "u16":
+			x, err := colferParseJSONUint(raw, 16)
+			if err != nil {
+				return fmt.Errorf("colfer: field gen.o.u16: %s", err)
+			}
+			v := uint16(x)
+			o.U16 = v
+		case "f32s":
+			var a []json.RawMessage
+			if err := json.Unmarshal(raw, &a); err != nil {
+				return fmt.Errorf("colfer: field gen.o.f32s: %s", err)
+			}
+			l := make([]float32, len(a))
+			for i, raw := range a {
+				x, err := colferParseJSONFloat(raw, 32)
+				if err != nil {
+					return fmt.Errorf("colfer: field gen.o.f32s: %s", err)
+				}
+				v := float32(x)
+				l[i] = v
+			}
+			o.F32s = l
+		case "f64s":
+			var a []json.RawMessage
+			if err := json.Unmarshal(raw, &a); err != nil {
+				return fmt.Errorf("colfer: field gen.o.f64s: %s", err)
+			}
+			l := make([]float64, len(a))
+			for i, raw := range a {
+				x, err := colferParseJSONFloat(raw, 64)
+				if err != nil {
+					return fmt.Errorf("colfer: field gen.o.f64s: %s", err)
+				}
+				v := float64(x)
+				l[i] = v
+			}
+			o.F64s = l
+		case "e":
+			var v Color
+			if err := v.UnmarshalJSON(raw); err != nil {
+				return err
+			}
+			o.E = v
+		case "bs":
+			var a []json.RawMessage
+			if err := json.Unmarshal(raw, &a); err != nil {
+				return fmt.Errorf("colfer: field gen.o.bs: %s", err)
+			}
+			l := make([]bool, len(a))
+			for i, raw := range a {
+				var v bool
+				if err := json.Unmarshal(raw, &v); err != nil {
+					return fmt.Errorf("colfer: field gen.o.bs: %s", err)
+				}
+				l[i] = v
+			}
+			o.Bs = l
+		case "u8s":
+			var a []json.RawMessage
+			if err := json.Unmarshal(raw, &a); err != nil {
+				return fmt.Errorf("colfer: field gen.o.u8s: %s", err)
+			}
+			l := make([]uint8, len(a))
+			for i, raw := range a {
+				x, err := colferParseJSONUint(raw, 8)
+				if err != nil {
+					return fmt.Errorf("colfer: field gen.o.u8s: %s", err)
+				}
+				v := uint8(x)
+				l[i] = v
+			}
+			o.U8s = l
+		case "u16s":
+			var a []json.RawMessage
+			if err := json.Unmarshal(raw, &a); err != nil {
+				return fmt.Errorf("colfer: field gen.o.u16s: %s", err)
+			}
+			l := make([]uint16, len(a))
+			for i, raw := range a {
+				x, err := colferParseJSONUint(raw, 16)
+				if err != nil {
+					return fmt.Errorf("colfer: field gen.o.u16s: %s", err)
+				}
+				v := uint16(x)
+				l[i] = v
+			}
+			o.U16s = l
+		case "u32s":
+			var a []json.RawMessage
+			if err := json.Unmarshal(raw, &a); err != nil {
+				return fmt.Errorf("colfer: field gen.o.u32s: %s", err)
+			}
+			l := make([]uint32, len(a))
+			for i, raw := range a {
+				x, err := colferParseJSONUint(raw, 32)
+				if err != nil {
+					return fmt.Errorf("colfer: field gen.o.u32s: %s", err)
+				}
+				v := uint32(x)
+				l[i] = v
+			}
+			o.U32s = l
+		case "u64s":
+			var a []json.RawMessage
+			if err := json.Unmarshal(raw, &a); err != nil {
+				return fmt.Errorf("colfer: field gen.o.u64s: %s", err)
+			}
+			l := make([]uint64, len(a))
+			for i, raw := range a {
+				x, err := colferParseJSONUint(raw, 64)
+				if err != nil {
+					return fmt.Errorf("colfer: field gen.o.u64s: %s", err)
+				}
+				v := uint64(x)
+				l[i] = v
+			}
+			o.U64s = l
+		case "i32s":
+			var a []json.RawMessage
+			if err := json.Unmarshal(raw, &a); err != nil {
+				return fmt.Errorf("colfer: field gen.o.i32s: %s", err)
+			}
+			l := make([]int32, len(a))
+			for i, raw := range a {
+				x, err := colferParseJSONInt(raw, 32)
+				if err != nil {
+					return fmt.Errorf("colfer: field gen.o.i32s: %s", err)
+				}
+				v := int32(x)
+				l[i] = v
+			}
+			o.I32s = l
+		case "i64s":
+			var a []json.RawMessage
+			if err := json.Unmarshal(raw, &a); err != nil {
+				return fmt.Errorf("colfer: field gen.o.i64s: %s", err)
+			}
+			l := make([]int64, len(a))
+			for i, raw := range a {
+				x, err := colferParseJSONInt(raw, 64)
+				if err != nil {
+					return fmt.Errorf("colfer: field gen.o.i64s: %s", err)
+				}
+				v := int64(x)
+				l[i] = v
+			}
+			o.I64s = l
+		case "es":
+			var a []json.RawMessage
+			if err := json.Unmarshal(raw, &a); err != nil {
+				return fmt.Errorf("colfer: field gen.o.es: %s", err)
+			}
+			l := make([]Color, len(a))
+			for i, raw := range a {
+				var v Color
+				if err := v.UnmarshalJSON(raw); err != nil {
+					return err
+				}
+				l[i] = v
+			}
+			o.Es = l
+		case "mt":
+			var m map[string]json.RawMessage
+			if err := json.Unmarshal(raw, &m); err != nil {
+				return fmt.Errorf("colfer: field gen.o.mt: %s", err)
+			}
+			o.Mt = make(map[string]string, len(m))
+			for k, raw := range m {
+				var v string
+				if err := json.Unmarshal(raw, &v); err != nil {
+					return fmt.Errorf("colfer: field gen.o.mt: %s", err)
+				}
+				o.Mt[k] = v
+			}
+		case "mu":
+			var m map[string]json.RawMessage
+			if err := json.Unmarshal(raw, &m); err != nil {
+				return fmt.Errorf("colfer: field gen.o.mu: %s", err)
+			}
+			o.Mu = make(map[uint32]int64, len(m))
+			for k, raw := range m {
+				n, err := strconv.ParseUint(k, 10, 32)
+				if err != nil {
+					return fmt.Errorf("colfer: field gen.o.mu key: %s", err)
+				}
+				x, err := colferParseJSONInt(raw, 64)
+				if err != nil {
+					return fmt.Errorf("colfer: field gen.o.mu: %s", err)
+				}
+				v := int64(x)
+				o.Mu[uint32(n)] = v
+			}
+		case "mi":
+			var m map[string]json.RawMessage
+			if err := json.Unmarshal(raw, &m); err != nil {
+				return fmt.Errorf("colfer: field gen.o.mi: %s", err)
+			}
+			o.Mi = make(map[int64][]byte, len(m))
+			for k, raw := range m {
+				n, err := strconv.ParseInt(k, 10, 64)
+				if err != nil {
+					return fmt.Errorf("colfer: field gen.o.mi key: %s", err)
+				}
+				var v []byte
+				if err := json.Unmarshal(raw, &v); err != nil {
+					return fmt.Errorf("colfer: field gen.o.mi: %s", err)
+				}
+				o.Mi[int64(n)] = v
+			}
+		case "mo":
+			var m map[string]json.RawMessage
+			if err := json.Unmarshal(raw, &m); err != nil {
+				return fmt.Errorf("colfer: field gen.o.mo: %s", err)
+			}
+			o.Mo = make(map[string]*O, len(m))
+			for k, raw := range m {
+				v := new(O)
+				if err := v.UnmarshalJSON(raw); err != nil {
+					return err
+				}
+				o.Mo[k] = v
+			}
+		case "gap":
+			var v bool
+			if err := json.Unmarshal(raw, &v); err != nil {
+				return fmt.Errorf("colfer: field gen.o.gap: %s", err)
+			}
+			o.Gap = v
+		case "host":
+			var v string
+			if err := json.Unmarshal(raw, &v); err != nil {
+				return fmt.Errorf("colfer: field gen.o.host: %s", err)
+			}
+			o.Host = v
+		case "tags":
+			var a []json.RawMessage
+			if err := json.Unmarshal(raw, &a); err != nil {
+				return fmt.Errorf("colfer: field gen.o.tags: %s", err)
+			}
+			l := make([]string, len(a))
+			for i, raw := range a {
+				var v string
+				if err := json.Unmarshal(raw, &v); err != nil {
+					return fmt.Errorf("colfer: field gen.o.tags: %s", err)
+				}
+				l[i] = v
+			}
+			o.Tags = l
+		case "ob":
+			var v bool
+			if err := json.Unmarshal(raw, &v); err != nil {
+				return fmt.Errorf("colfer: field gen.o.ob: %s", err)
+			}
+			o.Ob = &v
+		case "ou32":
+			x, err := colferParseJSONUint(raw, 32)
+			if err != nil {
+				return fmt.Errorf("colfer: field gen.o.ou32: %s", err)
+			}
+			v := uint32(x)
+			o.Ou32 = &v
+		case "oi64":
+			x, err := colferParseJSONInt(raw, 64)
+			if err != nil {
+				return fmt.Errorf("colfer: field gen.o.oi64: %s", err)
+			}
+			v := int64(x)
+			o.Oi64 = &v
+		case "of64":
+			x, err := colferParseJSONFloat(raw, 64)
+			if err != nil {
+				return fmt.Errorf("colfer: field gen.o.of64: %s", err)
+			}
+			v := float64(x)
+			o.Of64 = &v
+		case "u":
+			var u struct {
+				Kind  string          "json:\"kind\""
+				Value json.RawMessage "json:\"value\""
+			}
+			if err := json.Unmarshal(raw, &u); err != nil {
+				return fmt.Errorf("colfer: field gen.o.u: %s", err)
+			}
+			switch u.Kind {
+			case "o":
+				v := new(O)
+				if err := v.UnmarshalJSON(u.Value); err != nil {
+					return err
+				}
+				o.U = v
+			case "point":
+				v := new(Point)
+				if err := v.UnmarshalJSON(u.Value); err != nil {
+					return err
+				}
+				o.U = v
+			default:
+				return fmt.Errorf("colfer: field gen.o.u kind %q is not a member of union gen.pick", u.Kind)
+			}
+		default:
+			return fmt.Errorf("colfer: struct gen.o has no field %q", name)
+		}
+	}
+	return nil
+}
+
+// OView provides read-only access to O serials.
+// Fields decode on demand. Binary values share memory with the serial.
+// The zero value has all fields absent.
+type OView struct {
+	// data is the serial.
+	data []byte
+	// index has the position of each field value in data, right after the
+	// header byte, with zero for absent.
+	index [39 + 1]int
+	// limits apply to nested data structures.
+	limits ColferLimits
+}
+
+// Unmarshal indexes data as Colfer and returns the number of bytes read.
+// The checks match O.Unmarshal, without any allocation. The view
+// uses data as is, so the caller must keep data intact for as long as v (or
+// any of its values) is in use.
+// The limits are the ColferDefaults.
+// The error return options are io.EOF, gen.ColferError, gen.ColferMax and gen.ColferDepth.
+func (v *OView) Unmarshal(data []byte) (int, error) {
+	return v.UnmarshalWith(data, ColferDefaults())
+}
+
+// UnmarshalWith indexes data as Colfer like Unmarshal does, with limits
+// instead of the ColferDefaults.
+func (v *OView) UnmarshalWith(data []byte, limits ColferLimits) (int, error) {
+	if limits.DepthMax < 1 {
+		return 0, ColferDepth("colfer: struct gen.o exceeds the nesting depth limit")
+	}
+	limits.DepthMax--
+	if len(data) == 0 {
+		return 0, io.EOF
+	}
+	header := data[0]
+	i := 1
+	var index [39 + 1]int
+
+	if header == 0 {
+		index[0] = i
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 1 {
+		index[1] = i
+		start := i
+		i++
+		if i >= len(data) {
+			goto eof
+		}
+		x := data[start]
+
+		if x >= 0x80 {
+			for shift := uint(7); ; shift += 7 {
+				b := data[i]
+				i++
+				if i >= len(data) {
+					goto eof
+				}
+
+				if b < 0x80 {
+					break
+				}
+			}
+		}
+
+		header = data[i]
+		i++
+	} else if header == 1|0x80 {
+		index[1] = i
+		i += 4
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 2 {
+		index[2] = i
+		start := i
+		i++
+		if i >= len(data) {
+			goto eof
+		}
+		x := data[start]
+
+		if x >= 0x80 {
+			for shift := uint(7); ; shift += 7 {
+				b := data[i]
+				i++
+				if i >= len(data) {
+					goto eof
+				}
+
+				if b < 0x80 || shift == 56 {
+					break
+				}
+			}
+		}
+
+		header = data[i]
+		i++
+	} else if header == 2|0x80 {
+		index[2] = i
+		i += 8
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 3 || header == 3|0x80 {
+		index[3] = i
+		if i+1 >= len(data) {
+			i++
+			goto eof
+		}
+		x := data[i]
+		i++
+
+		if x >= 0x80 {
+			for shift := uint(7); ; shift += 7 {
+				b := data[i]
+				i++
+				if i >= len(data) {
+					goto eof
+				}
+
+				if b < 0x80 {
+					break
+				}
+			}
+		}
+
+		header = data[i]
+		i++
+	}
+
+	if header == 4 || header == 4|0x80 {
+		index[4] = i
+		if i+1 >= len(data) {
+			i++
+			goto eof
+		}
+		x := data[i]
+		i++
+
+		if x >= 0x80 {
+			for shift := uint(7); ; shift += 7 {
+				b := data[i]
+				i++
+				if i >= len(data) {
+					goto eof
+				}
+
+				if b < 0x80 || shift == 56 {
+					break
+				}
+			}
+		}
+
+		header = data[i]
+		i++
+	}
+
+	if header == 5 {
+		index[5] = i
+		i += 4
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 6 {
+		index[6] = i
+		i += 8
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 7 || header == 7|0x80 {
+		index[7] = i
+		if header == 7 {
+			i += 8
+		} else {
+			i += 12
+		}
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 8 {
+		index[8] = i
+		if i >= len(data) {
+			goto eof
+		}
+		x := uint(data[i])
+		i++
+
+		if x >= 0x80 {
+			x &= 0x7f
+			for shift := uint(7); ; shift += 7 {
+				if i >= len(data) {
+					goto eof
+				}
+				b := uint(data[i])
+				i++
+
+				if b < 0x80 {
+					x |= b << shift
+					break
+				}
+				x |= (b & 0x7f) << shift
+			}
+		}
+
+		if x > uint(limits.SizeMax) {
+			return 0, ColferMax(fmt.Sprintf("colfer: gen.o.s size %d exceeds %d bytes", x, limits.SizeMax))
+		}
+
+		i += int(x)
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 9 {
+		index[9] = i
+		if i >= len(data) {
+			goto eof
+		}
+		x := uint(data[i])
+		i++
+
+		if x >= 0x80 {
+			x &= 0x7f
+			for shift := uint(7); ; shift += 7 {
+				if i >= len(data) {
+					goto eof
+				}
+				b := uint(data[i])
+				i++
+
+				if b < 0x80 {
+					x |= b << shift
+					break
+				}
+				x |= (b & 0x7f) << shift
+			}
+		}
+
+		if x > uint(limits.SizeMax) {
+			return 0, ColferMax(fmt.Sprintf("colfer: gen.o.a size %d exceeds %d bytes", x, limits.SizeMax))
+		}
+
+		i += int(x)
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 10 {
+		index[10] = i
+		var e OView
+		n, err := e.UnmarshalWith(data[i:], limits)
+		if err != nil {
+			if err == io.EOF && len(data) >= limits.SizeMax {
+				return 0, ColferMax(fmt.Sprintf("colfer: gen.o size exceeds %d bytes", limits.SizeMax))
+			}
+			return 0, err
+		}
+		i += n
+
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 11 {
+		index[11] = i
+		if i >= len(data) {
+			goto eof
+		}
+		x := uint(data[i])
+		i++
+
+		if x >= 0x80 {
+			x &= 0x7f
+			for shift := uint(7); ; shift += 7 {
+				if i >= len(data) {
+					goto eof
+				}
+				b := uint(data[i])
+				i++
+
+				if b < 0x80 {
+					x |= b << shift
+					break
+				}
+				x |= (b & 0x7f) << shift
+			}
+		}
+
+		if x > uint(limits.ListMax) {
+			return 0, ColferMax(fmt.Sprintf("colfer: gen.o.os length %d exceeds %d elements", x, limits.ListMax))
+		}
+
+		for ai, l := 0, int(x); ai < l; ai++ {
+			var e OView
+			n, err := e.UnmarshalWith(data[i:], limits)
+			if err != nil {
+				if err == io.EOF && len(data) >= limits.SizeMax {
+					return 0, ColferMax(fmt.Sprintf("colfer: gen.o size exceeds %d bytes", limits.SizeMax))
+				}
+				return 0, err
+			}
+			i += n
+		}
+
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 12 {
+		index[12] = i
+		if i >= len(data) {
+			goto eof
+		}
+		x := uint(data[i])
+		i++
+
+		if x >= 0x80 {
+			x &= 0x7f
+			for shift := uint(7); ; shift += 7 {
+				if i >= len(data) {
+					goto eof
+				}
+				b := uint(data[i])
+				i++
+
+				if b < 0x80 {
+					x |= b << shift
+					break
+				}
+				x |= (b & 0x7f) << shift
+			}
+		}
+
+		if x > uint(limits.ListMax) {
+			return 0, ColferMax(fmt.Sprintf("colfer: gen.o.ss length %d exceeds %d elements", x, limits.ListMax))
+		}
+		for ai, l := 0, int(x); ai < l; ai++ {
+			if i >= len(data) {
+				goto eof
+			}
+			x := uint(data[i])
+			i++
+
+			if x >= 0x80 {
+				x &= 0x7f
+				for shift := uint(7); ; shift += 7 {
+					if i >= len(data) {
+						goto eof
+					}
+					b := uint(data[i])
+					i++
+
+					if b < 0x80 {
+						x |= b << shift
+						break
+					}
+					x |= (b & 0x7f) << shift
+				}
+			}
+
+			if x > uint(limits.SizeMax) {
+				return 0, ColferMax(fmt.Sprintf("colfer: gen.o.ss element %d size %d exceeds %d bytes", ai, x, limits.SizeMax))
+			}
+
+			i += int(x)
+			if i >= len(data) {
+				goto eof
+			}
+		}
+
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 13 {
+		index[13] = i
+		if i >= len(data) {
+			goto eof
+		}
+		x := uint(data[i])
+		i++
+
+		if x >= 0x80 {
+			x &= 0x7f
+			for shift := uint(7); ; shift += 7 {
+				if i >= len(data) {
+					goto eof
+				}
+				b := uint(data[i])
+				i++
+
+				if b < 0x80 {
+					x |= b << shift
+					break
+				}
+				x |= (b & 0x7f) << shift
+			}
+		}
+
+		if x > uint(limits.ListMax) {
+			return 0, ColferMax(fmt.Sprintf("colfer: gen.o.as length %d exceeds %d elements", x, limits.ListMax))
+		}
+		for ai, l := 0, int(x); ai < l; ai++ {
+			if i >= len(data) {
+				goto eof
+			}
+			x := uint(data[i])
+			i++
+
+			if x >= 0x80 {
+				x &= 0x7f
+				for shift := uint(7); ; shift += 7 {
+					if i >= len(data) {
+						goto eof
+					}
+					b := uint(data[i])
+					i++
+
+					if b < 0x80 {
+						x |= b << shift
+						break
+					}
+					x |= (b & 0x7f) << shift
+				}
+			}
+
+			if x > uint(limits.SizeMax) {
+				return 0, ColferMax(fmt.Sprintf("colfer: gen.o.as element %d size %d exceeds %d bytes", ai, x, limits.SizeMax))
+			}
+
+			i += int(x)
+			if i >= len(data) {
+				goto eof
+			}
+		}
+
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 14 {
+		index[14] = i
+		i += 1
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 15 || header == 15|0x80 {
+		index[15] = i
+		if header == 15 {
+			i += 2
+		} else {
+			i++
+		}
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 16 {
+		index[16] = i
+		if i >= len(data) {
+			goto eof
+		}
+		x := uint(data[i])
+		i++
+
+		if x >= 0x80 {
+			x &= 0x7f
+			for shift := uint(7); ; shift += 7 {
+				if i >= len(data) {
+					goto eof
+				}
+				b := uint(data[i])
+				i++
+
+				if b < 0x80 {
+					x |= b << shift
+					break
+				}
+				x |= (b & 0x7f) << shift
+			}
+		}
+
+		if x > uint(limits.ListMax) {
+			return 0, ColferMax(fmt.Sprintf("colfer: gen.o.f32s length %d exceeds %d elements", x, limits.ListMax))
+		}
+		i += int(x) * 4
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 17 {
+		index[17] = i
+		if i >= len(data) {
+			goto eof
+		}
+		x := uint(data[i])
+		i++
+
+		if x >= 0x80 {
+			x &= 0x7f
+			for shift := uint(7); ; shift += 7 {
+				if i >= len(data) {
+					goto eof
+				}
+				b := uint(data[i])
+				i++
+
+				if b < 0x80 {
+					x |= b << shift
+					break
+				}
+				x |= (b & 0x7f) << shift
+			}
+		}
+
+		if x > uint(limits.ListMax) {
+			return 0, ColferMax(fmt.Sprintf("colfer: gen.o.f64s length %d exceeds %d elements", x, limits.ListMax))
+		}
+		i += int(x) * 8
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 18 {
+		index[18] = i
+		i += 1
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 19 {
+		index[19] = i
+		if i >= len(data) {
+			goto eof
+		}
+		x := uint(data[i])
+		i++
+
+		if x >= 0x80 {
+			x &= 0x7f
+			for shift := uint(7); ; shift += 7 {
+				if i >= len(data) {
+					goto eof
+				}
+				b := uint(data[i])
+				i++
+
+				if b < 0x80 {
+					x |= b << shift
+					break
+				}
+				x |= (b & 0x7f) << shift
+			}
+		}
+
+		if x > uint(limits.ListMax) {
+			return 0, ColferMax(fmt.Sprintf("colfer: gen.o.bs length %d exceeds %d elements", x, limits.ListMax))
+		}
+		i += int(x)
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 20 {
+		index[20] = i
+		if i >= len(data) {
+			goto eof
+		}
+		x := uint(data[i])
+		i++
+
+		if x >= 0x80 {
+			x &= 0x7f
+			for shift := uint(7); ; shift += 7 {
+				if i >= len(data) {
+					goto eof
+				}
+				b := uint(data[i])
+				i++
+
+				if b < 0x80 {
+					x |= b << shift
+					break
+				}
+				x |= (b & 0x7f) << shift
+			}
+		}
+
+		if x > uint(limits.ListMax) {
+			return 0, ColferMax(fmt.Sprintf("colfer: gen.o.u8s length %d exceeds %d elements", x, limits.ListMax))
+		}
+		i += int(x)
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 21 {
+		index[21] = i
+		if i >= len(data) {
+			goto eof
+		}
+		x := uint(data[i])
+		i++
+
+		if x >= 0x80 {
+			x &= 0x7f
+			for shift := uint(7); ; shift += 7 {
+				if i >= len(data) {
+					goto eof
+				}
+				b := uint(data[i])
+				i++
+
+				if b < 0x80 {
+					x |= b << shift
+					break
+				}
+				x |= (b & 0x7f) << shift
+			}
+		}
+
+		if x > uint(limits.ListMax) {
+			return 0, ColferMax(fmt.Sprintf("colfer: gen.o.u16s length %d exceeds %d elements", x, limits.ListMax))
+		}
+		l := int(x)
+
+		if end := i + l; end >= len(data) {
+			i = end
+			goto eof
+		}
+		for ai := 0; ai < l; ai++ {
+			if i >= len(data) {
+				goto eof
+			}
+			x := data[i]
+			i++
+
+			if x >= 0x80 {
+				for shift := uint(7); ; shift += 7 {
+					if i >= len(data) {
+						goto eof
+					}
+					b := data[i]
+					i++
+
+					if b < 0x80 || shift == 56 {
+						break
+					}
+				}
+			}
+		}
+
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 22 {
+		index[22] = i
+		if i >= len(data) {
+			goto eof
+		}
+		x := uint(data[i])
+		i++
+
+		if x >= 0x80 {
+			x &= 0x7f
+			for shift := uint(7); ; shift += 7 {
+				if i >= len(data) {
+					goto eof
+				}
+				b := uint(data[i])
+				i++
+
+				if b < 0x80 {
+					x |= b << shift
+					break
+				}
+				x |= (b & 0x7f) << shift
+			}
+		}
+
+		if x > uint(limits.ListMax) {
+			return 0, ColferMax(fmt.Sprintf("colfer: gen.o.u32s length %d exceeds %d elements", x, limits.ListMax))
+		}
+		l := int(x)
+
+		if end := i + l; end >= len(data) {
+			i = end
+			goto eof
+		}
+		for ai := 0; ai < l; ai++ {
+			if i >= len(data) {
+				goto eof
+			}
+			x := data[i]
+			i++
+
+			if x >= 0x80 {
+				for shift := uint(7); ; shift += 7 {
+					if i >= len(data) {
+						goto eof
+					}
+					b := data[i]
+					i++
+
+					if b < 0x80 || shift == 56 {
+						break
+					}
+				}
+			}
+		}
+
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 23 {
+		index[23] = i
+		if i >= len(data) {
+			goto eof
+		}
+		x := uint(data[i])
+		i++
+
+		if x >= 0x80 {
+			x &= 0x7f
+			for shift := uint(7); ; shift += 7 {
+				if i >= len(data) {
+					goto eof
+				}
+				b := uint(data[i])
+				i++
+
+				if b < 0x80 {
+					x |= b << shift
+					break
+				}
+				x |= (b & 0x7f) << shift
+			}
+		}
+
+		if x > uint(limits.ListMax) {
+			return 0, ColferMax(fmt.Sprintf("colfer: gen.o.u64s length %d exceeds %d elements", x, limits.ListMax))
+		}
+		l := int(x)
+
+		if end := i + l; end >= len(data) {
+			i = end
+			goto eof
+		}
+		for ai := 0; ai < l; ai++ {
+			if i >= len(data) {
+				goto eof
+			}
+			x := data[i]
+			i++
+
+			if x >= 0x80 {
+				for shift := uint(7); ; shift += 7 {
+					if i >= len(data) {
+						goto eof
+					}
+					b := data[i]
+					i++
+
+					if b < 0x80 || shift == 56 {
+						break
+					}
+				}
+			}
+		}
+
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 24 {
+		index[24] = i
+		if i >= len(data) {
+			goto eof
+		}
+		x := uint(data[i])
+		i++
+
+		if x >= 0x80 {
+			x &= 0x7f
+			for shift := uint(7); ; shift += 7 {
+				if i >= len(data) {
+					goto eof
+				}
+				b := uint(data[i])
+				i++
+
+				if b < 0x80 {
+					x |= b << shift
+					break
+				}
+				x |= (b & 0x7f) << shift
+			}
+		}
+
+		if x > uint(limits.ListMax) {
+			return 0, ColferMax(fmt.Sprintf("colfer: gen.o.i32s length %d exceeds %d elements", x, limits.ListMax))
+		}
+		l := int(x)
+
+		if end := i + l; end >= len(data) {
+			i = end
+			goto eof
+		}
+		for ai := 0; ai < l; ai++ {
+			if i >= len(data) {
+				goto eof
+			}
+			x := data[i]
+			i++
+
+			if x >= 0x80 {
+				for shift := uint(7); ; shift += 7 {
+					if i >= len(data) {
+						goto eof
+					}
+					b := data[i]
+					i++
+
+					if b < 0x80 || shift == 56 {
+						break
+					}
+				}
+			}
+		}
+
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 25 {
+		index[25] = i
+		if i >= len(data) {
+			goto eof
+		}
+		x := uint(data[i])
+		i++
+
+		if x >= 0x80 {
+			x &= 0x7f
+			for shift := uint(7); ; shift += 7 {
+				if i >= len(data) {
+					goto eof
+				}
+				b := uint(data[i])
+				i++
+
+				if b < 0x80 {
+					x |= b << shift
+					break
+				}
+				x |= (b & 0x7f) << shift
+			}
+		}
+
+		if x > uint(limits.ListMax) {
+			return 0, ColferMax(fmt.Sprintf("colfer: gen.o.i64s length %d exceeds %d elements", x, limits.ListMax))
+		}
+		l := int(x)
+
+		if end := i + l; end >= len(data) {
+			i = end
+			goto eof
+		}
+		for ai := 0; ai < l; ai++ {
+			if i >= len(data) {
+				goto eof
+			}
+			x := data[i]
+			i++
+
+			if x >= 0x80 {
+				for shift := uint(7); ; shift += 7 {
+					if i >= len(data) {
+						goto eof
+					}
+					b := data[i]
+					i++
+
+					if b < 0x80 || shift == 56 {
+						break
+					}
+				}
+			}
+		}
+
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 26 {
+		index[26] = i
+		if i >= len(data) {
+			goto eof
+		}
+		x := uint(data[i])
+		i++
+
+		if x >= 0x80 {
+			x &= 0x7f
+			for shift := uint(7); ; shift += 7 {
+				if i >= len(data) {
+					goto eof
+				}
+				b := uint(data[i])
+				i++
+
+				if b < 0x80 {
+					x |= b << shift
+					break
+				}
+				x |= (b & 0x7f) << shift
+			}
+		}
+
+		if x > uint(limits.ListMax) {
+			return 0, ColferMax(fmt.Sprintf("colfer: gen.o.es length %d exceeds %d elements", x, limits.ListMax))
+		}
+		i += int(x)
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 27 {
+		index[27] = i
+		if i >= len(data) {
+			goto eof
+		}
+		x := uint(data[i])
+		i++
+
+		if x >= 0x80 {
+			x &= 0x7f
+			for shift := uint(7); ; shift += 7 {
+				if i >= len(data) {
+					goto eof
+				}
+				b := uint(data[i])
+				i++
+
+				if b < 0x80 {
+					x |= b << shift
+					break
+				}
+				x |= (b & 0x7f) << shift
+			}
+		}
+
+		if x > uint(limits.ListMax) {
+			return 0, ColferMax(fmt.Sprintf("colfer: gen.o.mt length %d exceeds %d elements", x, limits.ListMax))
+		}
+		l := int(x)
+
+		if end := i + l*2; end >= len(data) {
+			i = end
+			goto eof
+		}
+		var last []byte
+		for mi := 0; mi < l; mi++ {
+			start := i
+			var k []byte
+			{
+				if i >= len(data) {
+					goto eof
+				}
+				x := uint(data[i])
+				i++
+
+				if x >= 0x80 {
+					x &= 0x7f
+					for shift := uint(7); ; shift += 7 {
+						if i >= len(data) {
+							goto eof
+						}
+						b := uint(data[i])
+						i++
+
+						if b < 0x80 {
+							x |= b << shift
+							break
+						}
+						x |= (b & 0x7f) << shift
+					}
+				}
+
+				if x > uint(limits.SizeMax) {
+					return 0, ColferMax(fmt.Sprintf("colfer: gen.o.mt key size %d exceeds %d bytes", x, limits.SizeMax))
+				}
+				end := i + int(x)
+				if end >= len(data) {
+					i = end
+					goto eof
+				}
+				k = data[i:end]
+				i = end
+			}
+			if mi != 0 && string(k) <= string(last) {
+				return 0, ColferError(start)
+			}
+			last = k
+
+			{
+				if i >= len(data) {
+					goto eof
+				}
+				x := uint(data[i])
+				i++
+
+				if x >= 0x80 {
+					x &= 0x7f
+					for shift := uint(7); ; shift += 7 {
+						if i >= len(data) {
+							goto eof
+						}
+						b := uint(data[i])
+						i++
+
+						if b < 0x80 {
+							x |= b << shift
+							break
+						}
+						x |= (b & 0x7f) << shift
+					}
+				}
+
+				if x > uint(limits.SizeMax) {
+					return 0, ColferMax(fmt.Sprintf("colfer: gen.o.mt value size %d exceeds %d bytes", x, limits.SizeMax))
+				}
+				i += int(x)
+				if i >= len(data) {
+					goto eof
+				}
+			}
+		}
+
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 28 {
+		index[28] = i
+		if i >= len(data) {
+			goto eof
+		}
+		x := uint(data[i])
+		i++
+
+		if x >= 0x80 {
+			x &= 0x7f
+			for shift := uint(7); ; shift += 7 {
+				if i >= len(data) {
+					goto eof
+				}
+				b := uint(data[i])
+				i++
+
+				if b < 0x80 {
+					x |= b << shift
+					break
+				}
+				x |= (b & 0x7f) << shift
+			}
+		}
+
+		if x > uint(limits.ListMax) {
+			return 0, ColferMax(fmt.Sprintf("colfer: gen.o.mu length %d exceeds %d elements", x, limits.ListMax))
+		}
+		l := int(x)
+
+		if end := i + l*2; end >= len(data) {
+			i = end
+			goto eof
+		}
+		var last uint32
+		for mi := 0; mi < l; mi++ {
+			start := i
+			var k uint32
+			{
+				if i >= len(data) {
+					goto eof
+				}
+				x := uint64(data[i])
+				i++
+
+				if x >= 0x80 {
+					x &= 0x7f
+					for shift := uint(7); ; shift += 7 {
+						if i >= len(data) {
+							goto eof
+						}
+						b := uint64(data[i])
+						i++
+
+						if b < 0x80 || shift == 56 {
+							x |= b << shift
+							break
+						}
+						x |= (b & 0x7f) << shift
+					}
+				}
+
+				k = uint32(x)
+			}
+			if mi != 0 && k <= last {
+				return 0, ColferError(start)
+			}
+			last = k
+
+			{
+				if i >= len(data) {
+					goto eof
+				}
+				x := uint64(data[i])
+				i++
+
+				if x >= 0x80 {
+					x &= 0x7f
+					for shift := uint(7); ; shift += 7 {
+						if i >= len(data) {
+							goto eof
+						}
+						b := uint64(data[i])
+						i++
+
+						if b < 0x80 || shift == 56 {
+							x |= b << shift
+							break
+						}
+						x |= (b & 0x7f) << shift
+					}
+				}
+
+				_ = x
+			}
+		}
+
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 29 {
+		index[29] = i
+		if i >= len(data) {
+			goto eof
+		}
+		x := uint(data[i])
+		i++
+
+		if x >= 0x80 {
+			x &= 0x7f
+			for shift := uint(7); ; shift += 7 {
+				if i >= len(data) {
+					goto eof
+				}
+				b := uint(data[i])
+				i++
+
+				if b < 0x80 {
+					x |= b << shift
+					break
+				}
+				x |= (b & 0x7f) << shift
+			}
+		}
+
+		if x > uint(limits.ListMax) {
+			return 0, ColferMax(fmt.Sprintf("colfer: gen.o.mi length %d exceeds %d elements", x, limits.ListMax))
+		}
+		l := int(x)
+
+		if end := i + l*2; end >= len(data) {
+			i = end
+			goto eof
+		}
+		var last int64
+		for mi := 0; mi < l; mi++ {
+			start := i
+			var k int64
+			{
+				if i >= len(data) {
+					goto eof
+				}
+				x := uint64(data[i])
+				i++
+
+				if x >= 0x80 {
+					x &= 0x7f
+					for shift := uint(7); ; shift += 7 {
+						if i >= len(data) {
+							goto eof
+						}
+						b := uint64(data[i])
+						i++
+
+						if b < 0x80 || shift == 56 {
+							x |= b << shift
+							break
+						}
+						x |= (b & 0x7f) << shift
+					}
+				}
+
+				k = int64(x>>1) ^ -int64(x&1)
+			}
+			if mi != 0 && k <= last {
+				return 0, ColferError(start)
+			}
+			last = k
+
+			{
+				if i >= len(data) {
+					goto eof
+				}
+				x := uint(data[i])
+				i++
+
+				if x >= 0x80 {
+					x &= 0x7f
+					for shift := uint(7); ; shift += 7 {
+						if i >= len(data) {
+							goto eof
+						}
+						b := uint(data[i])
+						i++
+
+						if b < 0x80 {
+							x |= b << shift
+							break
+						}
+						x |= (b & 0x7f) << shift
+					}
+				}
+
+				if x > uint(limits.SizeMax) {
+					return 0, ColferMax(fmt.Sprintf("colfer: gen.o.mi value size %d exceeds %d bytes", x, limits.SizeMax))
+				}
+				i += int(x)
+				if i >= len(data) {
+					goto eof
+				}
+			}
+		}
+
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 30 {
+		index[30] = i
+		if i >= len(data) {
+			goto eof
+		}
+		x := uint(data[i])
+		i++
+
+		if x >= 0x80 {
+			x &= 0x7f
+			for shift := uint(7); ; shift += 7 {
+				if i >= len(data) {
+					goto eof
+				}
+				b := uint(data[i])
+				i++
+
+				if b < 0x80 {
+					x |= b << shift
+					break
+				}
+				x |= (b & 0x7f) << shift
+			}
+		}
+
+		if x > uint(limits.ListMax) {
+			return 0, ColferMax(fmt.Sprintf("colfer: gen.o.mo length %d exceeds %d elements", x, limits.ListMax))
+		}
+		l := int(x)
+
+		if end := i + l*2; end >= len(data) {
+			i = end
+			goto eof
+		}
+		var last []byte
+		for mi := 0; mi < l; mi++ {
+			start := i
+			var k []byte
+			{
+				if i >= len(data) {
+					goto eof
+				}
+				x := uint(data[i])
+				i++
+
+				if x >= 0x80 {
+					x &= 0x7f
+					for shift := uint(7); ; shift += 7 {
+						if i >= len(data) {
+							goto eof
+						}
+						b := uint(data[i])
+						i++
+
+						if b < 0x80 {
+							x |= b << shift
+							break
+						}
+						x |= (b & 0x7f) << shift
+					}
+				}
+
+				if x > uint(limits.SizeMax) {
+					return 0, ColferMax(fmt.Sprintf("colfer: gen.o.mo key size %d exceeds %d bytes", x, limits.SizeMax))
+				}
+				end := i + int(x)
+				if end >= len(data) {
+					i = end
+					goto eof
+				}
+				k = data[i:end]
+				i = end
+			}
+			if mi != 0 && string(k) <= string(last) {
+				return 0, ColferError(start)
+			}
+			last = k
+
+			var e OView
+			n, err := e.UnmarshalWith(data[i:], limits)
+			if err != nil {
+				if err == io.EOF && len(data) >= limits.SizeMax {
+					return 0, ColferMax(fmt.Sprintf("colfer: gen.o size exceeds %d bytes", limits.SizeMax))
+				}
+				return 0, err
+			}
+			i += n
+		}
+
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 32 {
+		index[32] = i
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 33 {
+		index[33] = i
+		if i >= len(data) {
+			goto eof
+		}
+		x := uint(data[i])
+		i++
+
+		if x >= 0x80 {
+			x &= 0x7f
+			for shift := uint(7); ; shift += 7 {
+				if i >= len(data) {
+					goto eof
+				}
+				b := uint(data[i])
+				i++
+
+				if b < 0x80 {
+					x |= b << shift
+					break
+				}
+				x |= (b & 0x7f) << shift
+			}
+		}
+
+		if x > uint(4) {
+			return 0, ColferMax(fmt.Sprintf("colfer: gen.o.host size %d exceeds %d bytes", x, 4))
+		}
+
+		i += int(x)
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 34 {
+		index[34] = i
+		if i >= len(data) {
+			goto eof
+		}
+		x := uint(data[i])
+		i++
+
+		if x >= 0x80 {
+			x &= 0x7f
+			for shift := uint(7); ; shift += 7 {
+				if i >= len(data) {
+					goto eof
+				}
+				b := uint(data[i])
+				i++
+
+				if b < 0x80 {
+					x |= b << shift
+					break
+				}
+				x |= (b & 0x7f) << shift
+			}
+		}
+
+		if x > uint(2) {
+			return 0, ColferMax(fmt.Sprintf("colfer: gen.o.tags length %d exceeds %d elements", x, 2))
+		}
+		for ai, l := 0, int(x); ai < l; ai++ {
+			if i >= len(data) {
+				goto eof
+			}
+			x := uint(data[i])
+			i++
+
+			if x >= 0x80 {
+				x &= 0x7f
+				for shift := uint(7); ; shift += 7 {
+					if i >= len(data) {
+						goto eof
+					}
+					b := uint(data[i])
+					i++
+
+					if b < 0x80 {
+						x |= b << shift
+						break
+					}
+					x |= (b & 0x7f) << shift
+				}
+			}
+
+			if x > uint(1) {
+				return 0, ColferMax(fmt.Sprintf("colfer: gen.o.tags element %d size %d exceeds %d bytes", ai, x, 1))
+			}
+
+			i += int(x)
+			if i >= len(data) {
+				goto eof
+			}
+		}
+
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 35 || header == 35|0x80 {
+		index[35] = i
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 36 {
+		index[36] = i
+		start := i
+		i++
+		if i >= len(data) {
+			goto eof
+		}
+		x := data[start]
+
+		if x >= 0x80 {
+			for shift := uint(7); ; shift += 7 {
+				b := data[i]
+				i++
+				if i >= len(data) {
+					goto eof
+				}
+
+				if b < 0x80 {
+					break
+				}
+			}
+		}
+
+		header = data[i]
+		i++
+	} else if header == 36|0x80 {
+		index[36] = i
+		i += 4
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 37 || header == 37|0x80 {
+		index[37] = i
+		if i+1 >= len(data) {
+			i++
+			goto eof
+		}
+		x := data[i]
+		i++
+
+		if x >= 0x80 {
+			for shift := uint(7); ; shift += 7 {
+				b := data[i]
+				i++
+				if i >= len(data) {
+					goto eof
+				}
+
+				if b < 0x80 || shift == 56 {
+					break
+				}
+			}
+		}
+
+		header = data[i]
+		i++
+	}
+
+	if header == 38 {
+		index[38] = i
+		i += 8
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 39 {
+		index[39] = i
+		if i >= len(data) {
+			goto eof
+		}
+		var n int
+		var err error
+		switch data[i] {
+		case 0:
+			var e OView
+			n, err = e.UnmarshalWith(data[i+1:], limits)
+		case 1:
+			var e PointView
+			n, err = e.UnmarshalWith(data[i+1:], limits)
+		default:
+			return 0, ColferError(i)
+		}
+		if err != nil {
+			if err == io.EOF && len(data) >= limits.SizeMax {
+				return 0, ColferMax(fmt.Sprintf("colfer: gen.o size exceeds %d bytes", limits.SizeMax))
+			}
+			return 0, err
+		}
+		i += n + 1
+
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header != 0x7f {
+		return 0, ColferError(i - 1)
+	}
+	if i < limits.SizeMax {
+		v.data = data[:i]
+		v.index = index
+		v.limits = limits
+		return i, nil
+	}
+eof:
+	if i >= limits.SizeMax {
+		return 0, ColferMax(fmt.Sprintf("colfer: struct gen.o size exceeds %d bytes", limits.SizeMax))
+	}
+	return 0, io.EOF
+}
+
+// B returns the value of field b.
+func (v *OView) B() bool {
+	return v.index[0] != 0
+}
+
+// U32 returns the value of field u32, or the zero value when absent.
+func (v *OView) U32() uint32 {
+	i := v.index[1]
+	if i == 0 {
+		return 0
+	}
+	if v.data[i-1] != 1 {
+		return intconv.Uint32(v.data[i:])
+	}
+	x, _ := colferVarint(v.data, i, false)
+	return uint32(x)
+}
+
+// U64 returns the value of field u64, or the zero value when absent.
+func (v *OView) U64() uint64 {
+	i := v.index[2]
+	if i == 0 {
+		return 0
+	}
+	if v.data[i-1] != 2 {
+		return intconv.Uint64(v.data[i:])
+	}
+	x, _ := colferVarint(v.data, i, true)
+	return x
+}
+
+// I32 returns the value of field i32, or the zero value when absent.
+func (v *OView) I32() int32 {
+	i := v.index[3]
+	if i == 0 {
+		return 0
+	}
+	x, _ := colferVarint(v.data, i, false)
+	if v.data[i-1] != 3 {
+		return int32(^uint32(x) + 1)
+	}
+	return int32(x)
+}
+
+// I64 returns the value of field i64, or the zero value when absent.
+func (v *OView) I64() int64 {
+	i := v.index[4]
+	if i == 0 {
+		return 0
+	}
+	x, _ := colferVarint(v.data, i, true)
+	if v.data[i-1] != 4 {
+		return int64(^x + 1)
+	}
+	return int64(x)
+}
+
+// F32 returns the value of field f32, or the zero value when absent.
+func (v *OView) F32() float32 {
+	i := v.index[5]
+	if i == 0 {
+		return 0
+	}
+	return math.Float32frombits(intconv.Uint32(v.data[i:]))
+}
+
+// F64 returns the value of field f64, or the zero value when absent.
+func (v *OView) F64() float64 {
+	i := v.index[6]
+	if i == 0 {
+		return 0
+	}
+	return math.Float64frombits(intconv.Uint64(v.data[i:]))
+}
+
+// T returns the value of field t, or the zero value when absent.
+func (v *OView) T() time.Time {
+	i := v.index[7]
+	if i == 0 {
+		return time.Time{}
+	}
+	if v.data[i-1] == 7 {
+		return time.Unix(int64(intconv.Uint32(v.data[i:])), int64(intconv.Uint32(v.data[i+4:]))).In(time.UTC)
+	}
+	return time.Unix(int64(intconv.Uint64(v.data[i:])), int64(intconv.Uint32(v.data[i+8:]))).In(time.UTC)
+}
+
+// S returns the value of field s, or the zero value when absent.
+func (v *OView) S() string {
+	i := v.index[8]
+	if i == 0 {
+		return ""
+	}
+	x, i := colferVarint(v.data, i, false)
+	end := i + int(x)
+	return string(v.data[i:end])
+}
+
+// A returns the value of field a, or the zero value when absent.
+func (v *OView) A() []byte {
+	i := v.index[9]
+	if i == 0 {
+		return nil
+	}
+	x, i := colferVarint(v.data, i, false)
+	end := i + int(x)
+	return v.data[i:end:end]
+}
+
+// O returns a view of field o, with ok false when absent.
+func (v *OView) O() (view OView, ok bool) {
+	i := v.index[10]
+	if i == 0 {
+		return view, false
+	}
+	view.UnmarshalWith(v.data[i:], v.limits)
+	return view, true
+}
+
+// Os returns a view per element of field os, or nil when absent.
+func (v *OView) Os() []OView {
+	i := v.index[11]
+	if i == 0 {
+		return nil
+	}
+	x, i := colferVarint(v.data, i, false)
+	a := make([]OView, int(x))
+	for ai := range a {
+		n, _ := a[ai].UnmarshalWith(v.data[i:], v.limits)
+		i += n
+	}
+	return a
+}
+
+// Ss decodes field ss into a new slice, or nil when absent.
+func (v *OView) Ss() []string {
+	i := v.index[12]
+	if i == 0 {
+		return nil
+	}
+	x, i := colferVarint(v.data, i, false)
+	a := make([]string, int(x))
+	for ai := range a {
+		x, i = colferVarint(v.data, i, false)
+		a[ai] = string(v.data[i : i+int(x)])
+		i += int(x)
+	}
+	return a
+}
+
+// As decodes field as into a new slice, or nil when absent.
+func (v *OView) As() [][]byte {
+	i := v.index[13]
+	if i == 0 {
+		return nil
+	}
+	x, i := colferVarint(v.data, i, false)
+	a := make([][]byte, int(x))
+	for ai := range a {
+		x, i = colferVarint(v.data, i, false)
+		end := i + int(x)
+		a[ai] = v.data[i:end:end]
+		i = end
+	}
+	return a
+}
+
+// U8 returns the value of field u8, or the zero value when absent.
+func (v *OView) U8() uint8 {
+	i := v.index[14]
+	if i == 0 {
+		return 0
+	}
+	return v.data[i]
+}
+
+// U16 returns the value of field u16, or the zero value when absent.
+func (v *OView) U16() uint16 {
+	i := v.index[15]
+	if i == 0 {
+		return 0
+	}
+	if v.data[i-1] == 15 {
+		return intconv.Uint16(v.data[i:])
+	}
+	return uint16(v.data[i])
+}
+
+// F32s decodes field f32s into a new slice, or nil when absent.
+func (v *OView) F32s() []float32 {
+	i := v.index[16]
+	if i == 0 {
+		return nil
+	}
+	x, i := colferVarint(v.data, i, false)
+	a := make([]float32, int(x))
+	for ai := range a {
+		a[ai] = math.Float32frombits(intconv.Uint32(v.data[i:]))
+		i += 4
+	}
+	return a
+}
+
+// F64s decodes field f64s into a new slice, or nil when absent.
+func (v *OView) F64s() []float64 {
+	i := v.index[17]
+	if i == 0 {
+		return nil
+	}
+	x, i := colferVarint(v.data, i, false)
+	a := make([]float64, int(x))
+	for ai := range a {
+		a[ai] = math.Float64frombits(intconv.Uint64(v.data[i:]))
+		i += 8
+	}
+	return a
+}
+
+// E returns the value of field e, or the zero value when absent.
+func (v *OView) E() Color {
+	i := v.index[18]
+	if i == 0 {
+		return 0
+	}
+	return Color(v.data[i])
+}
+
+// Bs decodes field bs into a new slice, or nil when absent.
+func (v *OView) Bs() []bool {
+	i := v.index[19]
+	if i == 0 {
+		return nil
+	}
+	x, i := colferVarint(v.data, i, false)
+	a := make([]bool, int(x))
+	for ai := range a {
+		a[ai] = v.data[i] != 0
+		i++
+	}
+	return a
+}
+
+// U8s decodes field u8s into a new slice, or nil when absent.
+func (v *OView) U8s() []uint8 {
+	i := v.index[20]
+	if i == 0 {
+		return nil
+	}
+	x, i := colferVarint(v.data, i, false)
+	a := make([]uint8, int(x))
+	copy(a, v.data[i:])
+	return a
+}
+
+// U16s decodes field u16s into a new slice, or nil when absent.
+func (v *OView) U16s() []uint16 {
+	i := v.index[21]
+	if i == 0 {
+		return nil
+	}
+	x, i := colferVarint(v.data, i, false)
+	a := make([]uint16, int(x))
+	for ai := range a {
+		x, i = colferVarint(v.data, i, true)
+		a[ai] = uint16(x)
+	}
+	return a
+}
+
+// U32s decodes field u32s into a new slice, or nil when absent.
+func (v *OView) U32s() []uint32 {
+	i := v.index[22]
+	if i == 0 {
+		return nil
+	}
+	x, i := colferVarint(v.data, i, false)
+	a := make([]uint32, int(x))
+	for ai := range a {
+		x, i = colferVarint(v.data, i, true)
+		a[ai] = uint32(x)
+	}
+	return a
+}
+
+// U64s decodes field u64s into a new slice, or nil when absent.
+func (v *OView) U64s() []uint64 {
+	i := v.index[23]
+	if i == 0 {
+		return nil
+	}
+	x, i := colferVarint(v.data, i, false)
+	a := make([]uint64, int(x))
+	for ai := range a {
+		x, i = colferVarint(v.data, i, true)
+		a[ai] = uint64(x)
+	}
+	return a
+}
+
+// I32s decodes field i32s into a new slice, or nil when absent.
+func (v *OView) I32s() []int32 {
+	i := v.index[24]
+	if i == 0 {
+		return nil
+	}
+	x, i := colferVarint(v.data, i, false)
+	a := make([]int32, int(x))
+	for ai := range a {
+		x, i = colferVarint(v.data, i, true)
+		a[ai] = int32(x>>1) ^ -int32(x&1)
+	}
+	return a
+}
+
+// I64s decodes field i64s into a new slice, or nil when absent.
+func (v *OView) I64s() []int64 {
+	i := v.index[25]
+	if i == 0 {
+		return nil
+	}
+	x, i := colferVarint(v.data, i, false)
+	a := make([]int64, int(x))
+	for ai := range a {
+		x, i = colferVarint(v.data, i, true)
+		a[ai] = int64(x>>1) ^ -int64(x&1)
+	}
+	return a
+}
+
+// Es decodes field es into a new slice, or nil when absent.
+func (v *OView) Es() []Color {
+	i := v.index[26]
+	if i == 0 {
+		return nil
+	}
+	x, i := colferVarint(v.data, i, false)
+	a := make([]Color, int(x))
+	for ai := range a {
+		a[ai] = Color(v.data[i])
+		i++
+	}
+	return a
+}
+
+// Mt decodes field mt into a new map, or nil when absent.
+func (v *OView) Mt() map[string]string {
+	i := v.index[27]
+	if i == 0 {
+		return nil
+	}
+	x, i := colferVarint(v.data, i, false)
+	l := int(x)
+	m := make(map[string]string, l)
+	for mi := 0; mi < l; mi++ {
+		var k string
+		x, i = colferVarint(v.data, i, false)
+		k = string(v.data[i : i+int(x)])
+		i += int(x)
+
+		var e string
+		x, i = colferVarint(v.data, i, false)
+		e = string(v.data[i : i+int(x)])
+		i += int(x)
+		m[k] = e
+	}
+	return m
+}
+
+// Mu decodes field mu into a new map, or nil when absent.
+func (v *OView) Mu() map[uint32]int64 {
+	i := v.index[28]
+	if i == 0 {
+		return nil
+	}
+	x, i := colferVarint(v.data, i, false)
+	l := int(x)
+	m := make(map[uint32]int64, l)
+	for mi := 0; mi < l; mi++ {
+		var k uint32
+		x, i = colferVarint(v.data, i, true)
+		k = uint32(x)
+
+		var e int64
+		x, i = colferVarint(v.data, i, true)
+		e = int64(x>>1) ^ -int64(x&1)
+		m[k] = e
+	}
+	return m
+}
+
+// Mi decodes field mi into a new map, or nil when absent.
+func (v *OView) Mi() map[int64][]byte {
+	i := v.index[29]
+	if i == 0 {
+		return nil
+	}
+	x, i := colferVarint(v.data, i, false)
+	l := int(x)
+	m := make(map[int64][]byte, l)
+	for mi := 0; mi < l; mi++ {
+		var k int64
+		x, i = colferVarint(v.data, i, true)
+		k = int64(x>>1) ^ -int64(x&1)
+
+		var e []byte
+		x, i = colferVarint(v.data, i, false)
+		end := i + int(x)
+		e = v.data[i:end:end]
+		i = end
+		m[k] = e
+	}
+	return m
+}
+
+// Mo decodes field mo into a new map, or nil when absent.
+func (v *OView) Mo() map[string]OView {
+	i := v.index[30]
+	if i == 0 {
+		return nil
+	}
+	x, i := colferVarint(v.data, i, false)
+	l := int(x)
+	m := make(map[string]OView, l)
+	for mi := 0; mi < l; mi++ {
+		var k string
+		x, i = colferVarint(v.data, i, false)
+		k = string(v.data[i : i+int(x)])
+		i += int(x)
+
+		var e OView
+		n, _ := e.UnmarshalWith(v.data[i:], v.limits)
+		i += n
+		m[k] = e
+	}
+	return m
+}
+
+// Gap returns the value of field gap.
+func (v *OView) Gap() bool {
+	return v.index[32] != 0
+}
+
+// Host returns the value of field host, or the zero value when absent.
+func (v *OView) Host() string {
+	i := v.index[33]
+	if i == 0 {
+		return ""
+	}
+	x, i := colferVarint(v.data, i, false)
+	end := i + int(x)
+	return string(v.data[i:end])
+}
+
+// Tags decodes field tags into a new slice, or nil when absent.
+func (v *OView) Tags() []string {
+	i := v.index[34]
+	if i == 0 {
+		return nil
+	}
+	x, i := colferVarint(v.data, i, false)
+	a := make([]string, int(x))
+	for ai := range a {
+		x, i = colferVarint(v.data, i, false)
+		a[ai] = string(v.data[i : i+int(x)])
+		i += int(x)
+	}
+	return a
+}
+
+// Ob returns the value of field ob, with ok false when absent.
+func (v *OView) Ob() (value bool, ok bool) {
+	i := v.index[35]
+	if i == 0 {
+		return false, false
+	}
+	return v.data[i-1] == 35, true
+}
+
+// Ou32 returns the value of field ou32, with ok false when absent.
+func (v *OView) Ou32() (value uint32, ok bool) {
+	i := v.index[36]
+	if i == 0 {
+		return 0, false
+	}
+	if v.data[i-1] != 36 {
+		return intconv.Uint32(v.data[i:]), true
+	}
+	x, _ := colferVarint(v.data, i, false)
+	return uint32(x), true
+}
+
+// Oi64 returns the value of field oi64, with ok false when absent.
+func (v *OView) Oi64() (value int64, ok bool) {
+	i := v.index[37]
+	if i == 0 {
+		return 0, false
+	}
+	x, _ := colferVarint(v.data, i, true)
+	if v.data[i-1] != 37 {
+		return int64(^x + 1), true
+	}
+	return int64(x), true
+}
+
+// Of64 returns the value of field of64, with ok false when absent.
+func (v *OView) Of64() (value float64, ok bool) {
+	i := v.index[38]
+	if i == 0 {
+		return 0, false
+	}
+	return math.Float64frombits(intconv.Uint64(v.data[i:])), true
+}
+
+// U returns field u as a new *OView or *PointView,
+// or nil when absent.
+func (v *OView) U() interface{} {
+	i := v.index[39]
+	if i == 0 {
+		return nil
+	}
+	switch v.data[i] {
+	case 0:
+		e := new(OView)
+		e.UnmarshalWith(v.data[i+1:], v.limits)
+		return e
+	case 1:
+		e := new(PointView)
+		e.UnmarshalWith(v.data[i+1:], v.limits)
+		return e
+	}
+	return nil
+}
+
+// OMask is a field selection for O.UnmarshalMask.
+// The zero value selects nothing.
+type OMask struct {
+	// whole flags the fields selected in full, by index.
+	whole [39 + 1]bool
+	// sub10 has the selection within field o, if any.
+	sub10 *OMask
+	// sub11 has the selection within field os, if any.
+	sub11 *OMask
+	// sub30 has the selection within field mo, if any.
+	sub30 *OMask
+}
+
+// Add selects fields by their name in the schema. Paths with dots select the
+// fields of nested data structures, which applies to each element in lists,
+// and to each value in maps. Unions can only be selected as a whole.
+func (m *OMask) Add(paths ...string) error {
+	for _, path := range paths {
+		name := path
+		if dot := strings.IndexByte(path, '.'); dot >= 0 {
+			name = path[:dot]
+		}
+
+		switch name {
+		case "b":
+			if name != path {
+				return fmt.Errorf("colfer: field gen.o.b has no fields to select with %q", path)
+			}
+			m.whole[0] = true
+		case "u32":
+			if name != path {
+				return fmt.Errorf("colfer: field gen.o.u32 has no fields to select with %q", path)
+			}
+			m.whole[1] = true
+		case "u64":
+			if name != path {
+				return fmt.Errorf("colfer: field gen.o.u64 has no fields to select with %q", path)
+			}
+			m.whole[2] = true
+		case "i32":
+			if name != path {
+				return fmt.Errorf("colfer: field gen.o.i32 has no fields to select with %q", path)
+			}
+			m.whole[3] = true
+		case "i64":
+			if name != path {
+				return fmt.Errorf("colfer: field gen.o.i64 has no fields to select with %q", path)
+			}
+			m.whole[4] = true
+		case "f32":
+			if name != path {
+				return fmt.Errorf("colfer: field gen.o.f32 has no fields to select with %q", path)
+			}
+			m.whole[5] = true
+		case "f64":
+			if name != path {
+				return fmt.Errorf("colfer: field gen.o.f64 has no fields to select with %q", path)
+			}
+			m.whole[6] = true
+		case "t":
+			if name != path {
+				return fmt.Errorf("colfer: field gen.o.t has no fields to select with %q", path)
+			}
+			m.whole[7] = true
+		case "s":
+			if name != path {
+				return fmt.Errorf("colfer: field gen.o.s has no fields to select with %q", path)
+			}
+			m.whole[8] = true
+		case "a":
+			if name != path {
+				return fmt.Errorf("colfer: field gen.o.a has no fields to select with %q", path)
+			}
+			m.whole[9] = true
+		case "o":
+			if name == path {
+				m.whole[10] = true
+			} else {
+				if m.sub10 == nil {
+					m.sub10 = new(OMask)
+				}
+				if err := m.sub10.Add(path[len(name)+1:]); err != nil {
+					return err
+				}
+			}
+		case "os":
+			if name == path {
+				m.whole[11] = true
+			} else {
+				if m.sub11 == nil {
+					m.sub11 = new(OMask)
+				}
+				if err := m.sub11.Add(path[len(name)+1:]); err != nil {
+					return err
+				}
+			}
+		case "ss":
+			if name != path {
+				return fmt.Errorf("colfer: field gen.o.ss has no fields to select with %q", path)
+			}
+			m.whole[12] = true
+		case "as":
+			if name != path {
+				return fmt.Errorf("colfer: field gen.o.as has no fields to select with %q", path)
+			}
+			m.whole[13] = true
+		case "u8":
+			if name != path {
+				return fmt.Errorf("colfer: field gen.o.u8 has no fields to select with %q", path)
+			}
+			m.whole[14] = true
+		case "u16":
+			if name != path {
+				return fmt.Errorf("colfer: field gen.o.u16 has no fields to select with %q", path)
+			}
+			m.whole[15] = true
+		case "f32s":
+			if name != path {
+				return fmt.Errorf("colfer: field gen.o.f32s has no fields to select with %q", path)
+			}
+			m.whole[16] = true
+		case "f64s":
+			if name != path {
+				return fmt.Errorf("colfer: field gen.o.f64s has no fields to select with %q", path)
+			}
+			m.whole[17] = true
+		case "e":
+			if name != path {
+				return fmt.Errorf("colfer: field gen.o.e has no fields to select with %q", path)
+			}
+			m.whole[18] = true
+		case "bs":
+			if name != path {
+				return fmt.Errorf("colfer: field gen.o.bs has no fields to select with %q", path)
+			}
+			m.whole[19] = true
+		case "u8s":
+			if name != path {
+				return fmt.Errorf("colfer: field gen.o.u8s has no fields to select with %q", path)
+			}
+			m.whole[20] = true
+		case "u16s":
+			if name != path {
+				return fmt.Errorf("colfer: field gen.o.u16s has no fields to select with %q", path)
+			}
+			m.whole[21] = true
+		case "u32s":
+			if name != path {
+				return fmt.Errorf("colfer: field gen.o.u32s has no fields to select with %q", path)
+			}
+			m.whole[22] = true
+		case "u64s":
+			if name != path {
+				return fmt.Errorf("colfer: field gen.o.u64s has no fields to select with %q", path)
+			}
+			m.whole[23] = true
+		case "i32s":
+			if name != path {
+				return fmt.Errorf("colfer: field gen.o.i32s has no fields to select with %q", path)
+			}
+			m.whole[24] = true
+		case "i64s":
+			if name != path {
+				return fmt.Errorf("colfer: field gen.o.i64s has no fields to select with %q", path)
+			}
+			m.whole[25] = true
+		case "es":
+			if name != path {
+				return fmt.Errorf("colfer: field gen.o.es has no fields to select with %q", path)
+			}
+			m.whole[26] = true
+		case "mt":
+			if name != path {
+				return fmt.Errorf("colfer: field gen.o.mt has no fields to select with %q", path)
+			}
+			m.whole[27] = true
+		case "mu":
+			if name != path {
+				return fmt.Errorf("colfer: field gen.o.mu has no fields to select with %q", path)
+			}
+			m.whole[28] = true
+		case "mi":
+			if name != path {
+				return fmt.Errorf("colfer: field gen.o.mi has no fields to select with %q", path)
+			}
+			m.whole[29] = true
+		case "mo":
+			if name == path {
+				m.whole[30] = true
+			} else {
+				if m.sub30 == nil {
+					m.sub30 = new(OMask)
+				}
+				if err := m.sub30.Add(path[len(name)+1:]); err != nil {
+					return err
+				}
+			}
+		case "gap":
+			if name != path {
+				return fmt.Errorf("colfer: field gen.o.gap has no fields to select with %q", path)
+			}
+			m.whole[32] = true
+		case "host":
+			if name != path {
+				return fmt.Errorf("colfer: field gen.o.host has no fields to select with %q", path)
+			}
+			m.whole[33] = true
+		case "tags":
+			if name != path {
+				return fmt.Errorf("colfer: field gen.o.tags has no fields to select with %q", path)
+			}
+			m.whole[34] = true
+		case "ob":
+			if name != path {
+				return fmt.Errorf("colfer: field gen.o.ob has no fields to select with %q", path)
+			}
+			m.whole[35] = true
+		case "ou32":
+			if name != path {
+				return fmt.Errorf("colfer: field gen.o.ou32 has no fields to select with %q", path)
+			}
+			m.whole[36] = true
+		case "oi64":
+			if name != path {
+				return fmt.Errorf("colfer: field gen.o.oi64 has no fields to select with %q", path)
+			}
+			m.whole[37] = true
+		case "of64":
+			if name != path {
+				return fmt.Errorf("colfer: field gen.o.of64 has no fields to select with %q", path)
+			}
+			m.whole[38] = true
+		case "u":
+			if name != path {
+				return fmt.Errorf("colfer: field gen.o.u has no fields to select with %q", path)
+			}
+			m.whole[39] = true
+		default:
+			return fmt.Errorf("colfer: struct gen.o has no field %q", name)
+		}
+	}
+	return nil
+}
+
+// UnmarshalMask decodes the fields selected by mask only, and it returns the
+// number of bytes read. The checks match Unmarshal, with the fields which are
+// not selected skipped without any allocation.
+// The limits are the ColferDefaults.
+// The error return options are io.EOF, gen.ColferError, gen.ColferMax and gen.ColferDepth.
+func (o *O) UnmarshalMask(data []byte, mask *OMask) (int, error) {
+	return o.UnmarshalMaskWith(data, mask, ColferDefaults())
+}
+
+// UnmarshalMaskWith decodes like UnmarshalMask does, with limits instead of
+// the ColferDefaults.
+func (o *O) UnmarshalMaskWith(data []byte, mask *OMask, limits ColferLimits) (int, error) {
+	var v OView
+	n, err := v.UnmarshalWith(data, limits)
+	if err != nil {
+		return 0, err
+	}
+
+	if mask.whole[0] && v.index[0] != 0 {
+		o.B = v.B()
+	}
+
+	if mask.whole[1] && v.index[1] != 0 {
+		o.U32 = v.U32()
+	}
+
+	if mask.whole[2] && v.index[2] != 0 {
+		o.U64 = v.U64()
+	}
+
+	if mask.whole[3] && v.index[3] != 0 {
+		o.I32 = v.I32()
+	}
+
+	if mask.whole[4] && v.index[4] != 0 {
+		o.I64 = v.I64()
+	}
+
+	if mask.whole[5] && v.index[5] != 0 {
+		o.F32 = v.F32()
+	}
+
+	if mask.whole[6] && v.index[6] != 0 {
+		o.F64 = v.F64()
+	}
+
+	if mask.whole[7] && v.index[7] != 0 {
+		o.T = v.T()
+	}
+
+	if mask.whole[8] && v.index[8] != 0 {
+		o.S = v.S()
+	}
+
+	if mask.whole[9] && v.index[9] != 0 {
+		b := v.A()
+		o.A = make([]byte, len(b))
+		copy(o.A, b)
+	}
+
+	if i := v.index[10]; i != 0 && (mask.whole[10] || mask.sub10 != nil) {
+		o.O = new(O)
+		if mask.whole[10] {
+			o.O.UnmarshalWith(v.data[i:], v.limits)
+		} else {
+			o.O.UnmarshalMaskWith(v.data[i:], mask.sub10, v.limits)
+		}
+	}
+
+	if v.index[11] != 0 && (mask.whole[11] || mask.sub11 != nil) {
+		x, i := colferVarint(v.data, v.index[11], false)
+		l := int(x)
+		a := make([]*O, l)
+		malloc := make([]O, l)
+		for ai := range a {
+			e := &malloc[ai]
+			a[ai] = e
+
+			var n int
+			if mask.whole[11] {
+				n, _ = e.UnmarshalWith(v.data[i:], v.limits)
+			} else {
+				n, _ = e.UnmarshalMaskWith(v.data[i:], mask.sub11, v.limits)
+			}
+			i += n
+		}
+		o.Os = a
+	}
+
+	if mask.whole[12] && v.index[12] != 0 {
+		o.Ss = v.Ss()
+	}
+
+	if mask.whole[13] && v.index[13] != 0 {
+		a := v.As()
+		for ai, b := range a {
+			c := make([]byte, len(b))
+			copy(c, b)
+			a[ai] = c
+		}
+		o.As = a
+	}
+
+	if mask.whole[14] && v.index[14] != 0 {
+		o.U8 = v.U8()
+	}
+
+	if mask.whole[15] && v.index[15] != 0 {
+		o.U16 = v.U16()
+	}
+
+	if mask.whole[16] && v.index[16] != 0 {
+		o.F32s = v.F32s()
+	}
+
+	if mask.whole[17] && v.index[17] != 0 {
+		o.F64s = v.F64s()
+	}
+
+	if mask.whole[18] && v.index[18] != 0 {
+		o.E = v.E()
+	}
+
+	if mask.whole[19] && v.index[19] != 0 {
+		o.Bs = v.Bs()
+	}
+
+	if mask.whole[20] && v.index[20] != 0 {
+		o.U8s = v.U8s()
+	}
+
+	if mask.whole[21] && v.index[21] != 0 {
+		o.U16s = v.U16s()
+	}
+
+	if mask.whole[22] && v.index[22] != 0 {
+		o.U32s = v.U32s()
+	}
+
+	if mask.whole[23] && v.index[23] != 0 {
+		o.U64s = v.U64s()
+	}
+
+	if mask.whole[24] && v.index[24] != 0 {
+		o.I32s = v.I32s()
+	}
+
+	if mask.whole[25] && v.index[25] != 0 {
+		o.I64s = v.I64s()
+	}
+
+	if mask.whole[26] && v.index[26] != 0 {
+		o.Es = v.Es()
+	}
+
+	if mask.whole[27] && v.index[27] != 0 {
+		o.Mt = v.Mt()
+	}
 
-				if b < 0x80 || shift == 56 {
-					x |= b << shift
-					break
-				}
-				x |= (b & 0x7f) << shift
-			}
-		}
-		o.I64 = int64(x)
+	if mask.whole[28] && v.index[28] != 0 {
+		o.Mu = v.Mu()
+	}
 
-		header = data[i]
-		i++
-	} else if header == 4|0x80 {
-		if i+1 >= len(data) {
-			i++
-			goto eof
+	if mask.whole[29] && v.index[29] != 0 {
+		m := v.Mi()
+		for k, b := range m {
+			c := make([]byte, len(b))
+			copy(c, b)
+			m[k] = c
 		}
-		x := uint64(data[i])
-		i++
+		o.Mi = m
+	}
 
-		if x >= 0x80 {
-			x &= 0x7f
-			for shift := uint(7); ; shift += 7 {
-				b := uint64(data[i])
-				i++
-				if i >= len(data) {
-					goto eof
-				}
+	if v.index[30] != 0 && (mask.whole[30] || mask.sub30 != nil) {
+		x, i := colferVarint(v.data, v.index[30], false)
+		l := int(x)
+		m := make(map[string]*O, l)
+		for mi := 0; mi < l; mi++ {
+			var k string
+			x, i = colferVarint(v.data, i, false)
+			k = string(v.data[i : i+int(x)])
+			i += int(x)
 
-				if b < 0x80 || shift == 56 {
-					x |= b << shift
-					break
-				}
-				x |= (b & 0x7f) << shift
+			e := new(O)
+			var n int
+			if mask.whole[30] {
+				n, _ = e.UnmarshalWith(v.data[i:], v.limits)
+			} else {
+				n, _ = e.UnmarshalMaskWith(v.data[i:], mask.sub30, v.limits)
 			}
+			i += n
+			m[k] = e
 		}
-		o.I64 = int64(^x + 1)
+		o.Mo = m
+	}
 
-		header = data[i]
-		i++
+	if mask.whole[32] && v.index[32] != 0 {
+		o.Gap = v.Gap()
 	}
 
-	if header == 5 {
-		start := i
-		i += 4
-		if i >= len(data) {
-			goto eof
-		}
-		o.F32 = math.Float32frombits(intconv.Uint32(data[start:]))
-		header = data[i]
-		i++
+	if mask.whole[33] && v.index[33] != 0 {
+		o.Host = v.Host()
 	}
 
-	if header == 6 {
-		start := i
-		i += 8
-		if i >= len(data) {
-			goto eof
+	if mask.whole[34] && v.index[34] != 0 {
+		o.Tags = v.Tags()
+	}
+
+	if mask.whole[35] && v.index[35] != 0 {
+		x, _ := v.Ob()
+		o.Ob = &x
+	}
+
+	if mask.whole[36] && v.index[36] != 0 {
+		x, _ := v.Ou32()
+		o.Ou32 = &x
+	}
+
+	if mask.whole[37] && v.index[37] != 0 {
+		x, _ := v.Oi64()
+		o.Oi64 = &x
+	}
+
+	if mask.whole[38] && v.index[38] != 0 {
+		x, _ := v.Of64()
+		o.Of64 = &x
+	}
+
+	if i := v.index[39]; i != 0 && mask.whole[39] {
+		switch v.data[i] {
+		case 0:
+			e := new(O)
+			e.UnmarshalWith(v.data[i+1:], v.limits)
+			o.U = e
+		case 1:
+			e := new(Point)
+			e.UnmarshalWith(v.data[i+1:], v.limits)
+			o.U = e
 		}
-		o.F64 = math.Float64frombits(intconv.Uint64(data[start:]))
-		header = data[i]
-		i++
 	}
 
-	if header == 7 {
-		start := i
-		i += 8
-		if i >= len(data) {
-			goto eof
+	return n, nil
+}
+
+// Point is a union member.
+type Point struct {
+	X int32
+
+	Y int32
+}
+
+// MarshalTo encodes o as Colfer into buf and returns the number of bytes written.
+// If the buffer is too small, MarshalTo will panic. MarshalTo does no checks on
+// its own. MarshalLen gives the size, and it catches reference cycles.
+func (o *Point) MarshalTo(buf []byte) int {
+	var i int
+
+	if v := o.X; v != 0 {
+		x := uint32(v)
+		if v >= 0 {
+			buf[i] = 0
+		} else {
+			x = ^x + 1
+			buf[i] = 0 | 0x80
 		}
-		o.T = time.Unix(int64(intconv.Uint32(data[start:])), int64(intconv.Uint32(data[start+4:]))).In(time.UTC)
-		header = data[i]
 		i++
-	} else if header == 7|0x80 {
-		start := i
-		i += 12
-		if i >= len(data) {
-			goto eof
+		for x >= 0x80 {
+			buf[i] = byte(x | 0x80)
+			x >>= 7
+			i++
 		}
-		o.T = time.Unix(int64(intconv.Uint64(data[start:])), int64(intconv.Uint32(data[start+8:]))).In(time.UTC)
-		header = data[i]
+		buf[i] = byte(x)
 		i++
 	}
 
-	if header == 8 {
-		if i >= len(data) {
-			goto eof
+	if v := o.Y; v != 0 {
+		x := uint32(v)
+		if v >= 0 {
+			buf[i] = 1
+		} else {
+			x = ^x + 1
+			buf[i] = 1 | 0x80
 		}
-		x := uint(data[i])
 		i++
+		for x >= 0x80 {
+			buf[i] = byte(x | 0x80)
+			x >>= 7
+			i++
+		}
+		buf[i] = byte(x)
+		i++
+	}
 
-		if x >= 0x80 {
-			x &= 0x7f
-			for shift := uint(7); ; shift += 7 {
-				if i >= len(data) {
-					goto eof
-				}
-				b := uint(data[i])
-				i++
+	buf[i] = 0x7f
+	i++
+	return i
+}
 
-				if b < 0x80 {
-					x |= b << shift
-					break
-				}
-				x |= (b & 0x7f) << shift
-			}
-		}
+// MarshalLen returns the Colfer serial byte size.
+// The limits are the ColferDefaults.
+// The error return options are gen.ColferMax and gen.ColferDepth.
+func (o *Point) MarshalLen() (int, error) {
+	return o.MarshalLenWith(ColferDefaults())
+}
+
+// MarshalLenWith returns the Colfer serial byte size like MarshalLen does,
+// with limits instead of the ColferDefaults.
+func (o *Point) MarshalLenWith(limits ColferLimits) (int, error) {
+	if limits.DepthMax < 1 {
+		return 0, ColferDepth("colfer: struct gen.point exceeds the nesting depth limit; check for reference cycles")
+	}
+	l := 1
 
-		if x > uint(ColferSizeMax) {
-			return 0, ColferMax(fmt.Sprintf("colfer: gen.o.s size %d exceeds %d bytes", x, ColferSizeMax))
+	if v := o.X; v != 0 {
+		x := uint32(v)
+		if v < 0 {
+			x = ^x + 1
+		}
+		for l += 2; x >= 0x80; l++ {
+			x >>= 7
 		}
+	}
 
-		start := i
-		i += int(x)
-		if i >= len(data) {
-			goto eof
+	if v := o.Y; v != 0 {
+		x := uint32(v)
+		if v < 0 {
+			x = ^x + 1
 		}
-		o.S = string(data[start:i])
+		for l += 2; x >= 0x80; l++ {
+			x >>= 7
+		}
+	}
 
-		header = data[i]
-		i++
+	if l > limits.SizeMax {
+		return l, ColferMax(fmt.Sprintf("colfer: struct gen.point exceeds %d bytes", limits.SizeMax))
 	}
+	return l, nil
+}
 
-	if header == 9 {
-		if i >= len(data) {
+// MarshalAppend encodes o as Colfer and appends the serial to dst, with
+// growth as with the built-in append.
+// The error return options are gen.ColferMax and
+// gen.ColferDepth, in which case dst is returned as is.
+func (o *Point) MarshalAppend(dst []byte) ([]byte, error) {
+	l, err := o.MarshalLen()
+	if err != nil {
+		return dst, err
+	}
+	offset := len(dst)
+	dst = append(dst, make([]byte, l)...)
+	o.MarshalTo(dst[offset:])
+	return dst, nil
+}
+
+// MarshalBinary encodes o as Colfer conform encoding.BinaryMarshaler.
+// The error return options are gen.ColferMax and gen.ColferDepth.
+func (o *Point) MarshalBinary() (data []byte, err error) {
+	l, err := o.MarshalLen()
+	if err != nil {
+		return nil, err
+	}
+	data = make([]byte, l)
+	o.MarshalTo(data)
+	return data, nil
+}
+
+// Unmarshal decodes data as Colfer and returns the number of bytes read.
+// The limits are the ColferDefaults.
+// The error return options are io.EOF, gen.ColferError, gen.ColferMax and gen.ColferDepth.
+func (o *Point) Unmarshal(data []byte) (int, error) {
+	return o.UnmarshalWith(data, ColferDefaults())
+}
+
+// UnmarshalWith decodes data as Colfer like Unmarshal does, with limits
+// instead of the ColferDefaults.
+func (o *Point) UnmarshalWith(data []byte, limits ColferLimits) (int, error) {
+	if limits.DepthMax < 1 {
+		return 0, ColferDepth("colfer: struct gen.point exceeds the nesting depth limit")
+	}
+	if len(data) == 0 {
+		return 0, io.EOF
+	}
+	header := data[0]
+	i := 1
+
+	if header == 0 {
+		if i+1 >= len(data) {
+			i++
 			goto eof
 		}
-		x := uint(data[i])
+		x := uint32(data[i])
 		i++
 
 		if x >= 0x80 {
 			x &= 0x7f
 			for shift := uint(7); ; shift += 7 {
+				b := uint32(data[i])
+				i++
 				if i >= len(data) {
 					goto eof
 				}
-				b := uint(data[i])
-				i++
 
 				if b < 0x80 {
 					x |= b << shift
@@ -869,57 +8024,26 @@ func (o *O) Unmarshal(data []byte) (int, error) {
 				x |= (b & 0x7f) << shift
 			}
 		}
+		o.X = int32(x)
 
-		if x > uint(ColferSizeMax) {
-			return 0, ColferMax(fmt.Sprintf("colfer: gen.o.a size %d exceeds %d bytes", x, ColferSizeMax))
-		}
-		v := make([]byte, int(x))
-
-		start := i
-		i += len(v)
-		if i >= len(data) {
-			goto eof
-		}
-		copy(v, data[start:i])
-		o.A = v
-
-		header = data[i]
-		i++
-	}
-
-	if header == 10 {
-		o.O = new(O)
-		n, err := o.O.Unmarshal(data[i:])
-		if err != nil {
-			if err == io.EOF && len(data) >= ColferSizeMax {
-				return 0, ColferMax(fmt.Sprintf("colfer: gen.o size exceeds %d bytes", ColferSizeMax))
-			}
-			return 0, err
-		}
-		i += n
-
-		if i >= len(data) {
-			goto eof
-		}
 		header = data[i]
 		i++
-	}
-
-	if header == 11 {
-		if i >= len(data) {
+	} else if header == 0|0x80 {
+		if i+1 >= len(data) {
+			i++
 			goto eof
 		}
-		x := uint(data[i])
+		x := uint32(data[i])
 		i++
 
 		if x >= 0x80 {
 			x &= 0x7f
 			for shift := uint(7); ; shift += 7 {
+				b := uint32(data[i])
+				i++
 				if i >= len(data) {
 					goto eof
 				}
-				b := uint(data[i])
-				i++
 
 				if b < 0x80 {
 					x |= b << shift
@@ -928,51 +8052,28 @@ func (o *O) Unmarshal(data []byte) (int, error) {
 				x |= (b & 0x7f) << shift
 			}
 		}
+		o.X = int32(^x + 1)
 
-		if x > uint(ColferListMax) {
-			return 0, ColferMax(fmt.Sprintf("colfer: gen.o.os length %d exceeds %d elements", x, ColferListMax))
-		}
-
-		l := int(x)
-		a := make([]*O, l)
-		malloc := make([]O, l)
-		for ai := range a {
-			v := &malloc[ai]
-			a[ai] = v
-
-			n, err := v.Unmarshal(data[i:])
-			if err != nil {
-				if err == io.EOF && len(data) >= ColferSizeMax {
-					return 0, ColferMax(fmt.Sprintf("colfer: gen.o size exceeds %d bytes", ColferSizeMax))
-				}
-				return 0, err
-			}
-			i += n
-		}
-		o.Os = a
-
-		if i >= len(data) {
-			goto eof
-		}
 		header = data[i]
 		i++
 	}
 
-	if header == 12 {
-		if i >= len(data) {
+	if header == 1 {
+		if i+1 >= len(data) {
+			i++
 			goto eof
 		}
-		x := uint(data[i])
+		x := uint32(data[i])
 		i++
 
 		if x >= 0x80 {
 			x &= 0x7f
 			for shift := uint(7); ; shift += 7 {
+				b := uint32(data[i])
+				i++
 				if i >= len(data) {
 					goto eof
 				}
-				b := uint(data[i])
-				i++
 
 				if b < 0x80 {
 					x |= b << shift
@@ -981,71 +8082,26 @@ func (o *O) Unmarshal(data []byte) (int, error) {
 				x |= (b & 0x7f) << shift
 			}
 		}
+		o.Y = int32(x)
 
-		if x > uint(ColferListMax) {
-			return 0, ColferMax(fmt.Sprintf("colfer: gen.o.ss length %d exceeds %d elements", x, ColferListMax))
-		}
-		a := make([]string, int(x))
-		o.Ss = a
-
-		for ai := range a {
-			if i >= len(data) {
-				goto eof
-			}
-			x := uint(data[i])
-			i++
-
-			if x >= 0x80 {
-				x &= 0x7f
-				for shift := uint(7); ; shift += 7 {
-					if i >= len(data) {
-						goto eof
-					}
-					b := uint(data[i])
-					i++
-
-					if b < 0x80 {
-						x |= b << shift
-						break
-					}
-					x |= (b & 0x7f) << shift
-				}
-			}
-
-			if x > uint(ColferSizeMax) {
-				return 0, ColferMax(fmt.Sprintf("colfer: gen.o.ss element %d size %d exceeds %d bytes", ai, x, ColferSizeMax))
-			}
-
-			start := i
-			i += int(x)
-			if i >= len(data) {
-				goto eof
-			}
-			a[ai] = string(data[start:i])
-		}
-
-		if i >= len(data) {
-			goto eof
-		}
 		header = data[i]
 		i++
-	}
-
-	if header == 13 {
-		if i >= len(data) {
+	} else if header == 1|0x80 {
+		if i+1 >= len(data) {
+			i++
 			goto eof
 		}
-		x := uint(data[i])
+		x := uint32(data[i])
 		i++
 
 		if x >= 0x80 {
 			x &= 0x7f
 			for shift := uint(7); ; shift += 7 {
+				b := uint32(data[i])
+				i++
 				if i >= len(data) {
 					goto eof
 				}
-				b := uint(data[i])
-				i++
 
 				if b < 0x80 {
 					x |= b << shift
@@ -1054,174 +8110,212 @@ func (o *O) Unmarshal(data []byte) (int, error) {
 				x |= (b & 0x7f) << shift
 			}
 		}
+		o.Y = int32(^x + 1)
 
-		if x > uint(ColferListMax) {
-			return 0, ColferMax(fmt.Sprintf("colfer: gen.o.as length %d exceeds %d elements", x, ColferListMax))
-		}
-		a := make([][]byte, int(x))
-		o.As = a
-		for ai := range a {
-			if i >= len(data) {
-				goto eof
-			}
-			x := uint(data[i])
-			i++
+		header = data[i]
+		i++
+	}
 
-			if x >= 0x80 {
-				x &= 0x7f
-				for shift := uint(7); ; shift += 7 {
-					if i >= len(data) {
-						goto eof
-					}
-					b := uint(data[i])
-					i++
+	if header != 0x7f {
+		return 0, ColferError(i - 1)
+	}
+	if i < limits.SizeMax {
+		return i, nil
+	}
+eof:
+	if i >= limits.SizeMax {
+		return 0, ColferMax(fmt.Sprintf("colfer: struct gen.point size exceeds %d bytes", limits.SizeMax))
+	}
+	return 0, io.EOF
+}
 
-					if b < 0x80 {
-						x |= b << shift
-						break
-					}
-					x |= (b & 0x7f) << shift
-				}
-			}
+// UnmarshalBinary decodes data as Colfer conform encoding.BinaryUnmarshaler.
+// The error return options are io.EOF, gen.ColferError, gen.ColferTail, gen.ColferMax and gen.ColferDepth.
+func (o *Point) UnmarshalBinary(data []byte) error {
+	i, err := o.Unmarshal(data)
+	if i < len(data) && err == nil {
+		return ColferTail(i)
+	}
+	return err
+}
 
-			if x > uint(ColferSizeMax) {
-				return 0, ColferMax(fmt.Sprintf("colfer: gen.o.as element %d size %d exceeds %d bytes", ai, x, ColferSizeMax))
-			}
-			v := make([]byte, int(x))
+// Equal returns whether o and x have the same serial. Hence, nil and empty
+// lists, maps and binaries are equal, and so are nil and empty entries in
+// lists, maps and unions. Either of o and x may be nil.
+func (o *Point) Equal(x *Point) bool {
+	if o == nil || x == nil {
+		return o == x
+	}
 
-			start := i
-			i += len(v)
-			if i >= len(data) {
-				goto eof
-			}
+	if o.X != x.X {
+		return false
+	}
 
-			copy(v, data[start:i])
-			a[ai] = v
-		}
+	if o.Y != x.Y {
+		return false
+	}
 
-		if i >= len(data) {
-			goto eof
-		}
-		header = data[i]
-		i++
+	return true
+}
+
+// Clone returns a deep copy of o, or nil when o is nil.
+func (o *Point) Clone() *Point {
+	if o == nil {
+		return nil
 	}
+	c := *o
 
-	if header == 14 {
-		start := i
-		i++
-		if i >= len(data) {
-			goto eof
-		}
-		o.U8 = data[start]
-		header = data[i]
-		i++
+	return &c
+}
+
+// MarshalJSON encodes o conform the json.Marshaler interface. The JSON object
+// has the fields present in the serial only, in order of appearance, with the
+// names from the schema. Integers beyond 2^53 - 1 in magnitude are written as
+// a JSON string, as JavaScript can not hold them exactly. The mapping matches
+// the generated Java and JavaScript code, and colf(1) decode.
+func (o *Point) MarshalJSON() ([]byte, error) {
+	// each field starts with a comma, of which the first one becomes the
+	// opening brace
+	var buf []byte
+
+	if v := o.X; v != 0 {
+		buf = append(buf, ",\"x\":"...)
+		buf = strconv.AppendInt(buf, int64(v), 10)
 	}
 
-	if header == 15 {
-		start := i
-		i += 2
-		if i >= len(data) {
-			goto eof
+	if v := o.Y; v != 0 {
+		buf = append(buf, ",\"y\":"...)
+		buf = strconv.AppendInt(buf, int64(v), 10)
+	}
+
+	if len(buf) == 0 {
+		return []byte("{}"), nil
+	}
+	buf[0] = '{'
+	return append(buf, '}'), nil
+}
+
+// UnmarshalJSON decodes data conform the json.Unmarshaler interface, with the
+// mapping of MarshalJSON. Fields absent in data, or with a null value, remain
+// as is.
+func (o *Point) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	for name, raw := range fields {
+		if string(raw) == "null" {
+			continue
 		}
-		o.U16 = intconv.Uint16(data[start:])
-		header = data[i]
-		i++
-	} else if header == 15|0x80 {
-		start := i
-		i++
-		if i >= len(data) {
-			goto eof
+
+		switch name {
+		case "x":
+			x, err := colferParseJSONInt(raw, 32)
+			if err != nil {
+				return fmt.Errorf("colfer: field gen.point.x: %s", err)
+			}
+			v := int32(x)
+			o.X = v
+		case "y":
+			x, err := colferParseJSONInt(raw, 32)
+			if err != nil {
+				return fmt.Errorf("colfer: field gen.point.y: %s", err)
+			}
+			v := int32(x)
+			o.Y = v
+		default:
+			return fmt.Errorf("colfer: struct gen.point has no field %q", name)
 		}
-		o.U16 = uint16(data[start])
-		header = data[i]
-		i++
 	}
+	return nil
+}
 
-	if header == 16 {
-		if i >= len(data) {
+// PointView provides read-only access to Point serials.
+// Fields decode on demand. Binary values share memory with the serial.
+// The zero value has all fields absent.
+type PointView struct {
+	// data is the serial.
+	data []byte
+	// index has the position of each field value in data, right after the
+	// header byte, with zero for absent.
+	index [1 + 1]int
+	// limits apply to nested data structures.
+	limits ColferLimits
+}
+
+// Unmarshal indexes data as Colfer and returns the number of bytes read.
+// The checks match Point.Unmarshal, without any allocation. The view
+// uses data as is, so the caller must keep data intact for as long as v (or
+// any of its values) is in use.
+// The limits are the ColferDefaults.
+// The error return options are io.EOF, gen.ColferError, gen.ColferMax and gen.ColferDepth.
+func (v *PointView) Unmarshal(data []byte) (int, error) {
+	return v.UnmarshalWith(data, ColferDefaults())
+}
+
+// UnmarshalWith indexes data as Colfer like Unmarshal does, with limits
+// instead of the ColferDefaults.
+func (v *PointView) UnmarshalWith(data []byte, limits ColferLimits) (int, error) {
+	if limits.DepthMax < 1 {
+		return 0, ColferDepth("colfer: struct gen.point exceeds the nesting depth limit")
+	}
+	if len(data) == 0 {
+		return 0, io.EOF
+	}
+	header := data[0]
+	i := 1
+	var index [1 + 1]int
+
+	if header == 0 || header == 0|0x80 {
+		index[0] = i
+		if i+1 >= len(data) {
+			i++
 			goto eof
 		}
-		x := uint(data[i])
+		x := data[i]
 		i++
 
 		if x >= 0x80 {
-			x &= 0x7f
 			for shift := uint(7); ; shift += 7 {
+				b := data[i]
+				i++
 				if i >= len(data) {
 					goto eof
 				}
-				b := uint(data[i])
-				i++
 
 				if b < 0x80 {
-					x |= b << shift
 					break
 				}
-				x |= (b & 0x7f) << shift
 			}
 		}
 
-		if x > uint(ColferListMax) {
-			return 0, ColferMax(fmt.Sprintf("colfer: gen.o.f32s length %d exceeds %d elements", x, ColferListMax))
-		}
-
-		l := int(x)
-
-		if end := i + l*4; end >= len(data) {
-			i = end
-			goto eof
-		}
-		a := make([]float32, l)
-		for ai := range a {
-			a[ai] = math.Float32frombits(intconv.Uint32(data[i:]))
-			i += 4
-		}
-		o.F32s = a
-
 		header = data[i]
 		i++
 	}
 
-	if header == 17 {
-		if i >= len(data) {
+	if header == 1 || header == 1|0x80 {
+		index[1] = i
+		if i+1 >= len(data) {
+			i++
 			goto eof
 		}
-		x := uint(data[i])
+		x := data[i]
 		i++
 
 		if x >= 0x80 {
-			x &= 0x7f
 			for shift := uint(7); ; shift += 7 {
+				b := data[i]
+				i++
 				if i >= len(data) {
 					goto eof
 				}
-				b := uint(data[i])
-				i++
 
 				if b < 0x80 {
-					x |= b << shift
 					break
 				}
-				x |= (b & 0x7f) << shift
 			}
 		}
 
-		if x > uint(ColferListMax) {
-			return 0, ColferMax(fmt.Sprintf("colfer: gen.o.f64s length %d exceeds %d elements", x, ColferListMax))
-		}
-		l := int(x)
-
-		if end := i + l*8; end >= len(data) {
-			i = end
-			goto eof
-		}
-		a := make([]float64, l)
-		for ai := range a {
-			a[ai] = math.Float64frombits(intconv.Uint64(data[i:]))
-			i += 8
-		}
-		o.F64s = a
-
 		header = data[i]
 		i++
 	}
@@ -1229,22 +8323,105 @@ func (o *O) Unmarshal(data []byte) (int, error) {
 	if header != 0x7f {
 		return 0, ColferError(i - 1)
 	}
-	if i < ColferSizeMax {
+	if i < limits.SizeMax {
+		v.data = data[:i]
+		v.index = index
+		v.limits = limits
 		return i, nil
 	}
 eof:
-	if i >= ColferSizeMax {
-		return 0, ColferMax(fmt.Sprintf("colfer: struct gen.o size exceeds %d bytes", ColferSizeMax))
+	if i >= limits.SizeMax {
+		return 0, ColferMax(fmt.Sprintf("colfer: struct gen.point size exceeds %d bytes", limits.SizeMax))
 	}
 	return 0, io.EOF
 }
 
-// UnmarshalBinary decodes data as Colfer conform encoding.BinaryUnmarshaler.
-// The error return options are io.EOF, gen.ColferError, gen.ColferTail and gen.ColferMax.
-func (o *O) UnmarshalBinary(data []byte) error {
-	i, err := o.Unmarshal(data)
-	if i < len(data) && err == nil {
-		return ColferTail(i)
+// X returns the value of field x, or the zero value when absent.
+func (v *PointView) X() int32 {
+	i := v.index[0]
+	if i == 0 {
+		return 0
 	}
-	return err
+	x, _ := colferVarint(v.data, i, false)
+	if v.data[i-1] != 0 {
+		return int32(^uint32(x) + 1)
+	}
+	return int32(x)
+}
+
+// Y returns the value of field y, or the zero value when absent.
+func (v *PointView) Y() int32 {
+	i := v.index[1]
+	if i == 0 {
+		return 0
+	}
+	x, _ := colferVarint(v.data, i, false)
+	if v.data[i-1] != 1 {
+		return int32(^uint32(x) + 1)
+	}
+	return int32(x)
+}
+
+// PointMask is a field selection for Point.UnmarshalMask.
+// The zero value selects nothing.
+type PointMask struct {
+	// whole flags the fields selected in full, by index.
+	whole [1 + 1]bool
+}
+
+// Add selects fields by their name in the schema. Paths with dots select the
+// fields of nested data structures, which applies to each element in lists,
+// and to each value in maps. Unions can only be selected as a whole.
+func (m *PointMask) Add(paths ...string) error {
+	for _, path := range paths {
+		name := path
+		if dot := strings.IndexByte(path, '.'); dot >= 0 {
+			name = path[:dot]
+		}
+
+		switch name {
+		case "x":
+			if name != path {
+				return fmt.Errorf("colfer: field gen.point.x has no fields to select with %q", path)
+			}
+			m.whole[0] = true
+		case "y":
+			if name != path {
+				return fmt.Errorf("colfer: field gen.point.y has no fields to select with %q", path)
+			}
+			m.whole[1] = true
+		default:
+			return fmt.Errorf("colfer: struct gen.point has no field %q", name)
+		}
+	}
+	return nil
+}
+
+// UnmarshalMask decodes the fields selected by mask only, and it returns the
+// number of bytes read. The checks match Unmarshal, with the fields which are
+// not selected skipped without any allocation.
+// The limits are the ColferDefaults.
+// The error return options are io.EOF, gen.ColferError, gen.ColferMax and gen.ColferDepth.
+func (o *Point) UnmarshalMask(data []byte, mask *PointMask) (int, error) {
+	return o.UnmarshalMaskWith(data, mask, ColferDefaults())
+}
+
+// UnmarshalMaskWith decodes like UnmarshalMask does, with limits instead of
+// the ColferDefaults.
+func (o *Point) UnmarshalMaskWith(data []byte, mask *PointMask, limits ColferLimits) (int, error) {
+	var v PointView
+	n, err := v.UnmarshalWith(data, limits)
+	if err != nil {
+		return 0, err
+	}
+
+	if mask.whole[0] && v.index[0] != 0 {
+		o.X = v.X()
+	}
+
+	if mask.whole[1] && v.index[1] != 0 {
+		o.Y = v.Y()
+	}
+
+	return n, nil
 }
@@ -3,17 +3,22 @@ package testdata
 import (
 	"bytes"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"math"
 	"strings"
 	"testing"
+	"testing/iotest"
 	"time"
 
 	"github.com/pascaldekloe/goe/verify"
 
+	"github.com/pascaldekloe/colfer"
 	"github.com/pascaldekloe/colfer/go/gen"
+	unknown "github.com/pascaldekloe/colfer/go/unknown/gen"
+	zerocopy "github.com/pascaldekloe/colfer/go/zerocopy/gen"
 )
 
 type golden struct {
@@ -22,12 +27,16 @@ type golden struct {
 }
 
 func newGoldenCases() []*golden {
+	yes, no := true, false
+	zeroU32, zeroI64, minusI64, zeroF64 := uint32(0), int64(0), int64(-1), 0.0
+
 	return []*golden{
 		{"7f", gen.O{}},
 		{"007f", gen.O{B: true}},
 		{"01017f", gen.O{U32: 1}},
 		{"01ff017f", gen.O{U32: math.MaxUint8}},
 		{"01ffff037f", gen.O{U32: math.MaxUint16}},
+		{"01ff7f7f", gen.O{U32: 16383}},
 		{"81ffffffff7f", gen.O{U32: math.MaxUint32}},
 		{"02017f", gen.O{U64: 1}},
 		{"02ff017f", gen.O{U64: math.MaxUint8}},
@@ -81,6 +90,34 @@ func newGoldenCases() []*golden {
 		{"0fffff7f", gen.O{U16: math.MaxUint16}},
 		{"1002000000003f8000007f", gen.O{F32s: []float32{0, 1}}},
 		{"11014058c000000000007f", gen.O{F64s: []float64{99}}},
+		{"12017f", gen.O{E: gen.Green}},
+		{"12ff7f", gen.O{E: gen.Color(math.MaxUint8)}},
+		{"13030100017f", gen.O{Bs: []bool{true, false, true}}},
+		{"140200ff7f", gen.O{U8s: []uint8{0, math.MaxUint8}}},
+		{"150201ffff037f", gen.O{U16s: []uint16{1, math.MaxUint16}}},
+		{"1601ffffffff0f7f", gen.O{U32s: []uint32{math.MaxUint32}}},
+		{"170201ffffffffffffffffff7f", gen.O{U64s: []uint64{1, math.MaxUint64}}},
+		{"1501ff7f7f", gen.O{U16s: []uint16{16383}}},
+		{"1602ff7fffff7f7f", gen.O{U32s: []uint32{16383, 2097151}}},
+		{"1702ff7fffffffffffffff0f7f", gen.O{U64s: []uint64{16383, 1<<53 - 1}}},
+		{"18030102ffffffff0f7f", gen.O{I32s: []int32{-1, 1, math.MinInt32}}},
+		{"1902fffffffffffffffffffeffffffffffffffff7f", gen.O{I64s: []int64{math.MinInt64, math.MaxInt64}}},
+		{"1a0201ff7f", gen.O{Es: []gen.Color{gen.Green, gen.Color(math.MaxUint8)}}},
+		{"1b02000178016101627f", gen.O{Mt: map[string]string{"a": "b", "": "x"}}},
+		{"1c020101ffffffff0ffeffffffffffffffff7f", gen.O{Mu: map[uint32]int64{1: -1, math.MaxUint32: math.MaxInt64}}},
+		{"1d020101ff00007f", gen.O{Mi: map[int64][]byte{-1: {0xff}, 0: {}}}},
+		{"1e01016f007f7f", gen.O{Mo: map[string]*gen.O{"o": {B: true}}}},
+		{"207f", gen.O{Gap: true}},
+		{"2104616263647f", gen.O{Host: "abcd"}},
+		{"2202016101627f", gen.O{Tags: []string{"a", "b"}}},
+		{"237f", gen.O{Ob: &yes}},
+		{"a37f", gen.O{Ob: &no}},
+		{"24007f", gen.O{Ou32: &zeroU32}},
+		{"25007f", gen.O{Oi64: &zeroI64}},
+		{"a5017f", gen.O{Oi64: &minusI64}},
+		{"2600000000000000007f", gen.O{Of64: &zeroF64}},
+		{"27007f7f", gen.O{U: &gen.O{}}},
+		{"270100017f7f", gen.O{U: &gen.Point{X: 1}}},
 	}
 }
 
@@ -97,6 +134,50 @@ func TestMarshal(t *testing.T) {
 	}
 }
 
+func TestMarshalAppend(t *testing.T) {
+	buf := make([]byte, 0, 1024)
+	var want []byte
+	for _, gold := range newGoldenCases() {
+		data, err := hex.DecodeString(gold.serial)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want = append(want, data...)
+
+		buf, err = gold.object.MarshalAppend(buf)
+		if err != nil {
+			t.Errorf("0x%s: %s", gold.serial, err)
+			continue
+		}
+		if !bytes.Equal(buf, want) {
+			t.Fatalf("0x%s: got 0x%x, want 0x%x", gold.serial, buf, want)
+		}
+	}
+
+	o := &gen.O{S: "hello"}
+	buf = buf[:0]
+	allocs := testing.AllocsPerRun(100, func() {
+		buf, _ = o.MarshalAppend(buf[:0])
+	})
+	if allocs != 0 {
+		t.Errorf("got %v allocations with sufficient capacity, want none", allocs)
+	}
+
+	orig := gen.ColferSizeMax
+	defer func() {
+		gen.ColferSizeMax = orig
+	}()
+	gen.ColferSizeMax = 4
+	prefix := []byte{1, 2, 3}
+	got, err := o.MarshalAppend(prefix)
+	if _, ok := err.(gen.ColferMax); !ok {
+		t.Errorf("got error %v, want a ColferMax", err)
+	}
+	if !bytes.Equal(got, prefix) {
+		t.Errorf("got 0x%x on error, want dst 0x%x", got, prefix)
+	}
+}
+
 func TestUnmarshal(t *testing.T) {
 	for _, gold := range newGoldenCases() {
 		data, err := hex.DecodeString(gold.serial)
@@ -113,6 +194,565 @@ func TestUnmarshal(t *testing.T) {
 	}
 }
 
+func TestZeroCopy(t *testing.T) {
+	for _, gold := range newGoldenCases() {
+		data, err := hex.DecodeString(gold.serial)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var o zerocopy.O
+		if err := o.UnmarshalBinary(data); err != nil {
+			t.Errorf("0x%s: %s", gold.serial, err)
+			continue
+		}
+		again, err := o.MarshalBinary()
+		if err != nil {
+			t.Errorf("0x%s: %s", gold.serial, err)
+			continue
+		}
+		if got := hex.EncodeToString(again); got != gold.serial {
+			t.Errorf("got 0x%s, want 0x%s", got, gold.serial)
+		}
+	}
+}
+
+func TestZeroCopyAliasing(t *testing.T) {
+	data, err := (&zerocopy.O{
+		S:  "hello",
+		A:  []byte{1, 2, 3},
+		Ss: []string{"world"},
+		As: [][]byte{{4, 5}},
+		Mt: map[string]string{"k": "v"},
+		Mi: map[int64][]byte{7: {6}},
+	}).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var copied gen.O
+	if err := copied.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	var o zerocopy.O
+	if err := o.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+
+	// appends must not overwrite the serial
+	serial := string(data)
+	_ = append(o.A, 0xff)
+	_ = append(o.As[0], 0xff)
+	_ = append(o.Mi[7], 0xff)
+	if string(data) != serial {
+		t.Fatal("append on binary value modified the serial")
+	}
+
+	// values share memory with the serial as long as it is in use
+	for i := range data {
+		data[i] = 'X'
+	}
+	if o.S != "XXXXX" || o.Ss[0] != "XXXXX" {
+		t.Errorf("got text %q and %q after buffer reuse, want the buffer content", o.S, o.Ss[0])
+	}
+	if !bytes.Equal(o.A, []byte("XXX")) || !bytes.Equal(o.As[0], []byte("XX")) || !bytes.Equal(o.Mi[7], []byte("X")) {
+		t.Errorf("got binary %q, %q and %q after buffer reuse, want the buffer content", o.A, o.As[0], o.Mi[7])
+	}
+	if o.Mt["k"] != "X" {
+		t.Errorf("got map %q after buffer reuse, want copied keys with the buffer content as values", o.Mt)
+	}
+
+	// copies are not affected
+	if copied.S != "hello" || copied.Ss[0] != "world" || !bytes.Equal(copied.A, []byte{1, 2, 3}) || copied.Mt["k"] != "v" {
+		t.Errorf("buffer reuse affected the copy: %+v", copied)
+	}
+}
+
+func TestKeepUnknown(t *testing.T) {
+	for _, gold := range newGoldenCases() {
+		data, err := hex.DecodeString(gold.serial)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var o unknown.O
+		if err := o.UnmarshalBinary(data); err != nil {
+			t.Errorf("0x%s: %s", gold.serial, err)
+			continue
+		}
+		if o.ColferUnknown != nil {
+			t.Errorf("0x%s: got unknown fields 0x%x", gold.serial, o.ColferUnknown)
+		}
+		again, err := o.MarshalBinary()
+		if err != nil {
+			t.Errorf("0x%s: %s", gold.serial, err)
+			continue
+		}
+		if got := hex.EncodeToString(again); got != gold.serial {
+			t.Errorf("got 0x%s, want 0x%s", got, gold.serial)
+		}
+	}
+
+	known, err := (&unknown.O{S: "hello", U8: 2}).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	known = known[:len(known)-1]
+	// text field 64 and data structure field 65 from a newer schema
+	fields := []byte{0x40, 0x03, 'a', 'b', 'c', 0x41, 0x00, 0x7f}
+	data := append(append(append([]byte(nil), known...), fields...), 0x7f)
+
+	var o unknown.O
+	if err := o.UnmarshalBinary(data); err != nil {
+		t.Fatalf("0x%x: %s", data, err)
+	}
+	if o.S != "hello" || o.U8 != 2 {
+		t.Errorf("0x%x: got S %q and U8 %d, want \"hello\" and 2", data, o.S, o.U8)
+	}
+	if !bytes.Equal(o.ColferUnknown, fields) {
+		t.Errorf("0x%x: got unknown fields 0x%x, want 0x%x", data, o.ColferUnknown, fields)
+	}
+	again, err := o.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(again, data) {
+		t.Errorf("got 0x%x, want 0x%x", again, data)
+	}
+
+	// the end of the serial is unknown without UnmarshalBinary
+	if _, err := new(unknown.O).Unmarshal(data); err != unknown.ColferError(len(known)) {
+		t.Errorf("0x%x: got Unmarshal error %v, want %v", data, err, unknown.ColferError(len(known)))
+	}
+	if err := new(gen.O).UnmarshalBinary(data); err != gen.ColferError(len(known)) {
+		t.Errorf("0x%x: got error %v without the option, want %v", data, err, gen.ColferError(len(known)))
+	}
+
+	// known fields out of order
+	data = append(append([]byte(nil), known...), 0x00, 0x7f)
+	if err := new(unknown.O).UnmarshalBinary(data); err != unknown.ColferError(len(known)) {
+		t.Errorf("0x%x: got error %v, want %v", data, err, unknown.ColferError(len(known)))
+	}
+	// no serial end
+	data = append(append([]byte(nil), known...), fields[:5]...)
+	if err := new(unknown.O).UnmarshalBinary(data); err != unknown.ColferError(len(known)) {
+		t.Errorf("0x%x: got error %v, want %v", data, err, unknown.ColferError(len(known)))
+	}
+}
+
+func TestView(t *testing.T) {
+	for _, gold := range newGoldenCases() {
+		data, err := hex.DecodeString(gold.serial)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var view gen.OView
+		n, err := view.Unmarshal(data)
+		if err != nil {
+			t.Errorf("0x%s: %s", gold.serial, err)
+			continue
+		}
+		if n != len(data) {
+			t.Errorf("0x%s: got %d bytes read, want %d", gold.serial, n, len(data))
+		}
+		verify.Values(t, fmt.Sprintf("0x%s", gold.serial), viewO(&view), &gold.object)
+	}
+}
+
+// viewO returns an object with all fields from v.
+func viewO(v *gen.OView) *gen.O {
+	o := &gen.O{
+		B: v.B(), U32: v.U32(), U64: v.U64(), I32: v.I32(), I64: v.I64(),
+		F32: v.F32(), F64: v.F64(), T: v.T(), S: v.S(), A: v.A(),
+		Ss: v.Ss(), As: v.As(), U8: v.U8(), U16: v.U16(),
+		F32s: v.F32s(), F64s: v.F64s(), E: v.E(), Bs: v.Bs(), U8s: v.U8s(),
+		U16s: v.U16s(), U32s: v.U32s(), U64s: v.U64s(), I32s: v.I32s(),
+		I64s: v.I64s(), Es: v.Es(), Mt: v.Mt(), Mu: v.Mu(), Mi: v.Mi(),
+		Gap: v.Gap(), Host: v.Host(), Tags: v.Tags(),
+	}
+	if sub, ok := v.O(); ok {
+		o.O = viewO(&sub)
+	}
+	if a := v.Os(); a != nil {
+		o.Os = make([]*gen.O, len(a))
+		for i := range a {
+			o.Os[i] = viewO(&a[i])
+		}
+	}
+	if m := v.Mo(); m != nil {
+		o.Mo = make(map[string]*gen.O, len(m))
+		for k, sub := range m {
+			o.Mo[k] = viewO(&sub)
+		}
+	}
+	if b, ok := v.Ob(); ok {
+		o.Ob = &b
+	}
+	if u, ok := v.Ou32(); ok {
+		o.Ou32 = &u
+	}
+	if i, ok := v.Oi64(); ok {
+		o.Oi64 = &i
+	}
+	if f, ok := v.Of64(); ok {
+		o.Of64 = &f
+	}
+	switch u := v.U().(type) {
+	case *gen.OView:
+		o.U = viewO(u)
+	case *gen.PointView:
+		o.U = &gen.Point{X: u.X(), Y: u.Y()}
+	}
+	return o
+}
+
+// TestViewChecks verifies the View and UnmarshalMask errors to match Unmarshal.
+func TestViewChecks(t *testing.T) {
+	var cases [][]byte
+	for _, gold := range newGoldenCases() {
+		data, err := hex.DecodeString(gold.serial)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range data {
+			cases = append(cases, data[:i])
+		}
+	}
+	for _, serial := range []string{
+		"1b020161000001787f", // descending keys
+		"1c0201010101027f",   // duplicate keys
+		"2702",               // unknown union member
+		"210561626364657f",   // size limit
+		"22030161016201637f", // list limit
+		"7e7f",               // unknown header
+	} {
+		data, err := hex.DecodeString(serial)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cases = append(cases, data)
+	}
+	files, err := ioutil.ReadDir("../testdata/corpus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		data, err := ioutil.ReadFile("../testdata/corpus/" + f.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		cases = append(cases, data)
+	}
+
+	for _, data := range cases {
+		wantN, wantErr := new(gen.O).Unmarshal(data)
+		gotN, gotErr := new(gen.OView).Unmarshal(data)
+		if gotN != wantN || gotErr != wantErr {
+			t.Errorf("0x%x: got (%d, %#v), want (%d, %#v)", data, gotN, gotErr, wantN, wantErr)
+		}
+		gotN, gotErr = new(gen.O).UnmarshalMask(data, new(gen.OMask))
+		if gotN != wantN || gotErr != wantErr {
+			t.Errorf("0x%x: mask got (%d, %#v), want (%d, %#v)", data, gotN, gotErr, wantN, wantErr)
+		}
+	}
+}
+
+func TestUnmarshalMask(t *testing.T) {
+	all := new(gen.OMask)
+	for _, f := range schemaO(t).Fields {
+		if err := all.Add(f.Name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	some := new(gen.OMask)
+	if err := some.Add("s", "o.s", "o.o.b", "os.b", "mo.a", "ob"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, gold := range newGoldenCases() {
+		data, err := hex.DecodeString(gold.serial)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got gen.O
+		if _, err := got.UnmarshalMask(data, all); err != nil {
+			t.Errorf("0x%s: %s", gold.serial, err)
+			continue
+		}
+		verify.Values(t, fmt.Sprintf("0x%s all", gold.serial), got, gold.object)
+
+		got = gen.O{}
+		if _, err := got.UnmarshalMask(data, some); err != nil {
+			t.Errorf("0x%s: %s", gold.serial, err)
+			continue
+		}
+		want := gen.O{S: gold.object.S, Ob: gold.object.Ob}
+		if sub := gold.object.O; sub != nil {
+			want.O = &gen.O{S: sub.S}
+			if sub.O != nil {
+				want.O.O = &gen.O{B: sub.O.B}
+			}
+		}
+		for _, e := range gold.object.Os {
+			want.Os = append(want.Os, &gen.O{B: e.B})
+		}
+		if gold.object.Mo != nil {
+			want.Mo = make(map[string]*gen.O)
+			for k, e := range gold.object.Mo {
+				want.Mo[k] = &gen.O{A: e.A}
+			}
+		}
+		verify.Values(t, fmt.Sprintf("0x%s some", gold.serial), got, want)
+	}
+}
+
+func TestOMaskAdd(t *testing.T) {
+	for _, path := range []string{"x", "b.x", "o.x", "o.", "u.x", ""} {
+		if err := new(gen.OMask).Add(path); err == nil {
+			t.Errorf("%q: no error", path)
+		}
+	}
+}
+
+func TestEqual(t *testing.T) {
+	golden := newGoldenCases()
+	for _, a := range golden {
+		for _, b := range golden {
+			if got, want := a.object.Equal(&b.object), a.serial == b.serial; got != want {
+				t.Errorf("0x%s equal to 0x%s: got %t, want %t", a.serial, b.serial, got, want)
+			}
+		}
+	}
+
+	negZero := math.Copysign(0, -1)
+	for _, pair := range []struct{ a, b *gen.O }{
+		{&gen.O{}, &gen.O{Os: []*gen.O{}, A: []byte{}, Mt: map[string]string{}}},
+		{&gen.O{Os: []*gen.O{nil}}, &gen.O{Os: []*gen.O{{}}}},
+		{&gen.O{Mo: map[string]*gen.O{"": nil}}, &gen.O{Mo: map[string]*gen.O{"": {}}}},
+		{&gen.O{U: (*gen.Point)(nil)}, &gen.O{U: &gen.Point{}}},
+		{&gen.O{F64: negZero}, &gen.O{}},
+		{&gen.O{F64: math.NaN()}, &gen.O{F64: math.NaN()}},
+		{&gen.O{T: time.Unix(1, 2)}, &gen.O{T: time.Unix(1, 2).In(time.UTC)}},
+	} {
+		if !pair.a.Equal(pair.b) || !pair.b.Equal(pair.a) {
+			t.Errorf("%+v and %+v not equal", pair.a, pair.b)
+		}
+	}
+
+	for _, pair := range []struct{ a, b *gen.O }{
+		{&gen.O{}, nil},
+		{&gen.O{}, &gen.O{O: &gen.O{}}},
+		{&gen.O{}, &gen.O{U: &gen.Point{}}},
+		{&gen.O{U: &gen.O{}}, &gen.O{U: &gen.Point{}}},
+		{&gen.O{}, &gen.O{Of64: &negZero}},
+		{&gen.O{Of64: &negZero}, &gen.O{Of64: new(float64)}},
+		{&gen.O{F64s: []float64{0}}, &gen.O{F64s: []float64{negZero}}},
+	} {
+		if pair.a.Equal(pair.b) || pair.b.Equal(pair.a) {
+			t.Errorf("%+v and %+v equal", pair.a, pair.b)
+		}
+	}
+}
+
+func TestClone(t *testing.T) {
+	if (*gen.O)(nil).Clone() != nil {
+		t.Error("clone of nil is not nil")
+	}
+
+	for _, gold := range newGoldenCases() {
+		c := gold.object.Clone()
+		verify.Values(t, fmt.Sprintf("0x%s", gold.serial), c, &gold.object)
+		if !c.Equal(&gold.object) {
+			t.Errorf("0x%s: clone not equal", gold.serial)
+		}
+	}
+
+	o := &gen.O{A: []byte{1}, O: &gen.O{S: "x"}, Os: []*gen.O{{B: true}}, Mi: map[int64][]byte{1: {2}}, Ob: new(bool)}
+	c := o.Clone()
+	c.A[0] = 9
+	c.O.S = "y"
+	c.Os[0].B = false
+	c.Mi[1][0] = 9
+	*c.Ob = true
+	want := &gen.O{A: []byte{1}, O: &gen.O{S: "x"}, Os: []*gen.O{{B: true}}, Mi: map[int64][]byte{1: {2}}, Ob: new(bool)}
+	verify.Values(t, "original after clone change", o, want)
+}
+
+func TestColferDecoder(t *testing.T) {
+	var stream []byte
+	golds := newGoldenCases()
+	for _, gold := range golds {
+		data, err := hex.DecodeString(gold.serial)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stream = append(stream, data...)
+	}
+
+	for _, r := range []io.Reader{bytes.NewReader(stream), iotest.OneByteReader(bytes.NewReader(stream)), iotest.DataErrReader(bytes.NewReader(stream))} {
+		d := gen.NewColferDecoder(r)
+		for _, gold := range golds {
+			var got gen.O
+			if err := d.Decode(&got); err != nil {
+				t.Fatalf("0x%s: %s", gold.serial, err)
+			}
+			verify.Values(t, fmt.Sprintf("0x%s", gold.serial), got, gold.object)
+		}
+		if err := d.Decode(new(gen.O)); err != io.EOF {
+			t.Errorf("got error %v after the last serial, want io.EOF", err)
+		}
+	}
+
+	d := gen.NewColferDecoder(bytes.NewReader(stream[:len(stream)-1]))
+	var err error
+	for err == nil {
+		err = d.Decode(new(gen.O))
+	}
+	if err != io.ErrUnexpectedEOF {
+		t.Errorf("got error %v on truncated stream, want io.ErrUnexpectedEOF", err)
+	}
+}
+
+func TestColferDecoderSizeMax(t *testing.T) {
+	orig, origDepth := gen.ColferSizeMax, gen.ColferDepthMax
+	defer func() {
+		gen.ColferSizeMax = orig
+		gen.ColferDepthMax = origDepth
+	}()
+	gen.ColferSizeMax = 100
+	gen.ColferDepthMax = 1000
+
+	d := gen.NewColferDecoder(bytes.NewReader(append(bytes.Repeat([]byte{0x0a}, 200), 0x7f)))
+	if err := d.Decode(new(gen.O)); err == nil {
+		t.Fatal("no error for serial beyond ColferSizeMax")
+	} else if _, ok := err.(gen.ColferMax); !ok {
+		t.Errorf("got error %T %q, want ColferMax", err, err)
+	}
+}
+
+// rawSerial unmarshals any data which ends with 0x7f. Incomplete data gets
+// io.EOF rather than ColferMax, regardless of its size.
+type rawSerial []byte
+
+func (r *rawSerial) Unmarshal(data []byte) (int, error) {
+	i := bytes.IndexByte(data, 0x7f)
+	if i < 0 {
+		return 0, io.EOF
+	}
+	*r = append((*r)[:0], data[:i+1]...)
+	return i + 1, nil
+}
+
+func TestColferDecoderSizeMaxEOF(t *testing.T) {
+	limits := gen.ColferDefaults()
+	limits.SizeMax = 100
+
+	d := gen.NewColferDecoderWith(bytes.NewReader(append(bytes.Repeat([]byte{1}, 200), 0x7f)), limits)
+	done := make(chan error, 1)
+	go func() {
+		done <- d.Decode(new(rawSerial))
+	}()
+	select {
+	case err := <-done:
+		if _, ok := err.(gen.ColferMax); !ok {
+			t.Errorf("got error %T %q, want ColferMax", err, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("decode did not return")
+	}
+
+	// serials up to the limit pass
+	d = gen.NewColferDecoderWith(bytes.NewReader(append(bytes.Repeat([]byte{1}, 99), 0x7f)), limits)
+	var r rawSerial
+	if err := d.Decode(&r); err != nil {
+		t.Errorf("serial of 100 bytes got error %q", err)
+	} else if len(r) != 100 {
+		t.Errorf("got serial of %d bytes, want 100", len(r))
+	}
+}
+
+func TestColferEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	var want []byte
+	e := gen.NewColferEncoder(&buf)
+	for _, gold := range newGoldenCases() {
+		data, err := hex.DecodeString(gold.serial)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want = append(want, data...)
+		if err := e.Encode(&gold.object); err != nil {
+			t.Fatalf("0x%s: %s", gold.serial, err)
+		}
+	}
+	big := gen.O{A: make([]byte, 8192)}
+	if err := e.Encode(&big); err != nil {
+		t.Fatal(err)
+	}
+	bigData, err := big.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = append(want, bigData...)
+
+	if err := e.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("got %d bytes, want %d bytes", buf.Len(), len(want))
+	}
+}
+
+func TestEnumString(t *testing.T) {
+	if got, want := gen.Blue.String(), "blue"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := gen.Color(math.MaxUint8).String(), "color(255)"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestConsts(t *testing.T) {
+	if got, want := gen.ConstU32, uint32(math.MaxUint32); got != want {
+		t.Errorf("got uint32 constant %d, want %d", got, want)
+	}
+	if got, want := gen.ConstI32, int32(math.MinInt32); got != want {
+		t.Errorf("got int32 constant %d, want %d", got, want)
+	}
+	if got, want := gen.ConstF64, 1e-100; got != want {
+		t.Errorf("got float64 constant %g, want %g", got, want)
+	}
+	if got, want := gen.ConstText, "\"π\"\t??="; got != want {
+		t.Errorf("got text constant %q, want %q", got, want)
+	}
+}
+
+func TestUnmarshalMapOrder(t *testing.T) {
+	for _, gold := range []struct {
+		serial string
+		want   error
+	}{
+		{"1b020161000001787f", gen.ColferError(5)}, // descending keys
+		{"1c0201010101027f", gen.ColferError(4)},   // duplicate keys
+	} {
+		data, err := hex.DecodeString(gold.serial)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := new(gen.O).Unmarshal(data); err != gold.want {
+			t.Errorf("0x%s: got error %#v, want %#v", gold.serial, err, gold.want)
+		}
+	}
+}
+
+func TestUnmarshalUnionMember(t *testing.T) {
+	data := []byte{0x27, 0x02, 0x7f, 0x7f}
+	if _, err := new(gen.O).Unmarshal(data); err != gen.ColferError(1) {
+		t.Errorf("0x%x: got error %#v, want %#v", data, err, gen.ColferError(1))
+	}
+}
+
 func TestUnmarshalEOF(t *testing.T) {
 	for _, gold := range newGoldenCases() {
 		data, err := hex.DecodeString(gold.serial)
@@ -168,6 +808,247 @@ func TestUnmarshalSizeMax(t *testing.T) {
 	}
 }
 
+func TestUnmarshalWith(t *testing.T) {
+	limits := gen.ColferLimits{SizeMax: 16, ListMax: 1, DepthMax: 2}
+
+	for _, gold := range []struct {
+		o     *gen.O
+		field string
+	}{
+		{&gen.O{Os: []*gen.O{{}, {}}}, "gen.o.os"},
+		{&gen.O{O: &gen.O{S: "abcdefghijklmnopq"}}, "gen.o.s"},
+	} {
+		data, err := gold.o.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := new(gen.O).UnmarshalWith(data, gen.ColferDefaults()); err != nil {
+			t.Errorf("0x%x: got error %q with defaults", data, err)
+		}
+
+		_, err = new(gen.O).UnmarshalWith(data, limits)
+		if _, ok := err.(gen.ColferMax); !ok || !strings.Contains(err.Error(), gold.field) {
+			t.Errorf("0x%x: got error %T %q, want ColferMax on %s", data, err, err, gold.field)
+		}
+		_, err = new(gen.OView).UnmarshalWith(data, limits)
+		if _, ok := err.(gen.ColferMax); !ok || !strings.Contains(err.Error(), gold.field) {
+			t.Errorf("0x%x: got view error %T %q, want ColferMax on %s", data, err, err, gold.field)
+		}
+		err = gen.NewColferDecoderWith(bytes.NewReader(data), limits).Decode(new(gen.O))
+		if _, ok := err.(gen.ColferMax); !ok || !strings.Contains(err.Error(), gold.field) {
+			t.Errorf("0x%x: got decoder error %T %q, want ColferMax on %s", data, err, err, gold.field)
+		}
+	}
+}
+
+// newDepthCases returns serials of gen.O, by name of the field used for
+// nesting, which are one level deeper than ColferDepthMax.
+func newDepthCases() map[string][]byte {
+	prefixes := map[string][]byte{
+		"o":  {0x0a},
+		"os": {0x0b, 0x01},
+		"mo": {0x1e, 0x01, 0x01, 'a'},
+		"u":  {0x27, 0x00},
+	}
+	cases := make(map[string][]byte, len(prefixes))
+	for name, prefix := range prefixes {
+		data := bytes.Repeat(prefix, gen.ColferDepthMax)
+		cases[name] = append(data, bytes.Repeat([]byte{0x7f}, gen.ColferDepthMax+1)...)
+	}
+	return cases
+}
+
+func TestDepthMax(t *testing.T) {
+	limits := gen.ColferDefaults()
+	limits.DepthMax++
+
+	for name, data := range newDepthCases() {
+		_, err := new(gen.O).Unmarshal(data)
+		if _, ok := err.(gen.ColferDepth); !ok {
+			t.Errorf("%s: got error %T %q, want ColferDepth", name, err, err)
+		}
+		_, err = new(gen.OView).Unmarshal(data)
+		if _, ok := err.(gen.ColferDepth); !ok {
+			t.Errorf("%s: got view error %T %q, want ColferDepth", name, err, err)
+		}
+		err = gen.NewColferDecoder(bytes.NewReader(data)).Decode(new(gen.O))
+		if _, ok := err.(gen.ColferDepth); !ok {
+			t.Errorf("%s: got decoder error %T %q, want ColferDepth", name, err, err)
+		}
+
+		o := new(gen.O)
+		n, err := o.UnmarshalWith(data, limits)
+		if err != nil || n != len(data) {
+			t.Errorf("%s: got (%d, %v) with one more level, want (%d, nil)", name, n, err, len(data))
+			continue
+		}
+		if _, err := o.MarshalBinary(); err == nil {
+			t.Errorf("%s: marshal got no error", name)
+		} else if _, ok := err.(gen.ColferDepth); !ok {
+			t.Errorf("%s: got marshal error %T %q, want ColferDepth", name, err, err)
+		}
+		if l, err := o.MarshalLenWith(limits); err != nil || l != len(data) {
+			t.Errorf("%s: got marshal length (%d, %v) with one more level, want (%d, nil)", name, l, err, len(data))
+		}
+	}
+}
+
+func TestMarshalCycle(t *testing.T) {
+	self := new(gen.O)
+	self.O = self
+	list := new(gen.O)
+	list.Os = []*gen.O{new(gen.O), list}
+	mapped := new(gen.O)
+	mapped.Mo = map[string]*gen.O{"a": mapped}
+	union := new(gen.O)
+	union.U = union
+	pair := new(gen.O)
+	pair.O = &gen.O{O: pair}
+
+	for name, o := range map[string]*gen.O{
+		"self":  self,
+		"list":  list,
+		"map":   mapped,
+		"union": union,
+		"pair":  pair,
+	} {
+		if _, err := o.MarshalLen(); err == nil {
+			t.Errorf("%s: marshal length got no error", name)
+		} else if _, ok := err.(gen.ColferDepth); !ok {
+			t.Errorf("%s: got marshal length error %T %q, want ColferDepth", name, err, err)
+		}
+		if _, err := o.MarshalBinary(); err == nil {
+			t.Errorf("%s: marshal got no error", name)
+		} else if _, ok := err.(gen.ColferDepth); !ok {
+			t.Errorf("%s: got marshal error %T %q, want ColferDepth", name, err, err)
+		}
+		if _, err := o.MarshalAppend(nil); err == nil {
+			t.Errorf("%s: marshal append got no error", name)
+		} else if _, ok := err.(gen.ColferDepth); !ok {
+			t.Errorf("%s: got marshal append error %T %q, want ColferDepth", name, err, err)
+		}
+	}
+
+	// aliasing without a cycle encodes a copy per reference
+	shared := &gen.O{B: true}
+	o := &gen.O{O: shared, Os: []*gen.O{shared, shared}}
+	data, err := o.MarshalBinary()
+	if err != nil {
+		t.Fatal("marshal shared reference:", err)
+	}
+	got := new(gen.O)
+	if err := got.UnmarshalBinary(data); err != nil {
+		t.Fatal("unmarshal shared reference:", err)
+	}
+	if got.O == got.Os[0] || !got.O.B || !got.Os[0].B || !got.Os[1].B {
+		t.Errorf("shared reference got %+v, want separate copies", got)
+	}
+}
+
+func TestFieldMax(t *testing.T) {
+	for _, gold := range []struct {
+		o     *gen.O
+		field string
+	}{
+		{&gen.O{Host: "abcde"}, "gen.o.host"},
+		{&gen.O{Tags: []string{"a", "b", "c"}}, "gen.o.tags"},
+		{&gen.O{Tags: []string{"ab"}}, "gen.o.tags"},
+	} {
+		_, err := gold.o.MarshalBinary()
+		if _, ok := err.(gen.ColferMax); !ok || !strings.Contains(err.Error(), gold.field) {
+			t.Errorf("%+v: got error %T %q, want ColferMax on %s", gold.o, err, err, gold.field)
+		}
+	}
+
+	for _, gold := range []struct {
+		serial string
+		field  string
+	}{
+		{"210561626364657f", "gen.o.host"},
+		{"22030161016201637f", "gen.o.tags"},
+		{"22010261627f", "gen.o.tags"},
+	} {
+		data, err := hex.DecodeString(gold.serial)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = new(gen.O).Unmarshal(data)
+		if _, ok := err.(gen.ColferMax); !ok || !strings.Contains(err.Error(), gold.field) {
+			t.Errorf("0x%s: got error %T %q, want ColferMax on %s", gold.serial, err, err, gold.field)
+		}
+	}
+}
+
+// schemaO returns the definition of gen.O.
+func schemaO(t *testing.T) *colfer.Struct {
+	packages, err := colfer.ParseFiles([]string{"../testdata/test.colf"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range packages[0].Structs {
+		if s.Name == "o" {
+			return s
+		}
+	}
+	t.Fatal("struct o not found")
+	return nil
+}
+
+// TestJSONGolden verifies the JSON mapping shared with the Java and the
+// ECMAScript tests.
+func TestJSONGolden(t *testing.T) {
+	text, err := ioutil.ReadFile("../testdata/json-golden.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(string(text), "\n") {
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		i := strings.IndexByte(line, ' ')
+		serial, want := line[:i], line[i+1:]
+		data, err := hex.DecodeString(serial)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var o gen.O
+		if err := o.UnmarshalBinary(data); err != nil {
+			t.Errorf("0x%s: got error %q", serial, err)
+			continue
+		}
+		if got, err := o.MarshalJSON(); err != nil {
+			t.Errorf("0x%s: marshal got error %q", serial, err)
+		} else if string(got) != want {
+			t.Errorf("0x%s: got JSON %s, want %s", serial, got, want)
+		}
+		if !json.Valid([]byte(want)) {
+			t.Errorf("0x%s: invalid JSON %s", serial, want)
+		}
+
+		var back gen.O
+		if err := back.UnmarshalJSON([]byte(want)); err != nil {
+			t.Errorf("%s: unmarshal got error %q", want, err)
+		} else if got, _ := back.MarshalJSON(); string(got) != want {
+			t.Errorf("%s: unmarshal and marshal got %s", want, got)
+		}
+	}
+
+	for _, gold := range []struct{ json, want string }{
+		{`{"nope":true}`, "nope"},
+		{`{"u8":256}`, "gen.o.u8"},
+		{`{"e":"purple"}`, "purple"},
+		{`{"u":{"kind":"o2"}}`, "gen.o.u"},
+		{`{"mu":{"x":1}}`, "gen.o.mu"},
+		{`{"t":"yesterday"}`, "gen.o.t"},
+	} {
+		err := new(gen.O).UnmarshalJSON([]byte(gold.json))
+		if err == nil || !strings.Contains(err.Error(), gold.want) {
+			t.Errorf("%s: got error %v, want mention of %s", gold.json, err, gold.want)
+		}
+	}
+}
+
 // TestFuzzSeed updates the initial input corpus for fuzz testing.
 func TestFuzzSeed(t *testing.T) {
 	for _, gold := range newGoldenCases() {
@@ -179,4 +1060,9 @@ func TestFuzzSeed(t *testing.T) {
 			t.Fatal(err)
 		}
 	}
+	for name, data := range newDepthCases() {
+		if err := ioutil.WriteFile("../testdata/corpus/seed-depth-"+name, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
 }
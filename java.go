@@ -2,10 +2,16 @@ package colfer
 
 import (
 	"bytes"
+	"fmt"
+	"go/constant"
+	"math"
 	"os"
 	"path/filepath"
 	"strings"
 	"text/template"
+	"unicode/utf16"
+
+	"github.com/pascaldekloe/name"
 )
 
 const javaKeywords = "abstract assert boolean break byte case catch char class const continue default do double else enum extends final finally float for goto if implements import instanceof int interface long native new package private protected public return short static strictfp super switch synchronized this throw throws transient try void volatile while"
@@ -22,10 +28,34 @@ func IsJavaKeyword(s string) bool {
 
 // GenerateJava writes the code into the respective ".java" files.
 func GenerateJava(basedir string, packages Packages) error {
+	if err := lintJava(packages).Err(); err != nil {
+		return err
+	}
+
 	packageTemplate := template.New("java-package")
 	template.Must(packageTemplate.Parse(javaPackage))
 	codeTemplate := template.New("java-code")
 	template.Must(codeTemplate.Parse(javaCode))
+	enumTemplate := template.New("java-enum")
+	template.Must(enumTemplate.Parse(javaEnum))
+	unionTemplate := template.New("java-union")
+	template.Must(unionTemplate.Parse(javaUnion))
+	constTemplate := template.New("java-const")
+	template.Must(constTemplate.Parse(javaConst))
+	jsonTemplate := template.New("java-json")
+	template.Must(jsonTemplate.Parse(javaJSONHelper))
+	template.Must(codeTemplate.New("limits-arg").Parse(javaLimitsArg))
+	limitsTemplate := template.New("java-limits")
+	template.Must(limitsTemplate.Parse(javaLimits))
+	depthTemplate := template.New("java-depth")
+	template.Must(depthTemplate.Parse(javaDepthException))
+	template.Must(codeTemplate.New("json").Parse(javaJSON))
+	template.Must(codeTemplate.New("json-value").Parse(javaJSONValue))
+	// single fields are one level less nested than list elements and map values
+	template.Must(codeTemplate.New("json-field-value").Parse(strings.Replace(javaJSONValue, "\t\t\t\t", "\t\t\t", -1)))
+	template.Must(codeTemplate.New("json-unsigned").Parse(javaJSONUnsigned))
+	template.Must(codeTemplate.New("json-integer-cast").Parse(javaJSONIntegerCast))
+	template.Must(codeTemplate.New("json-integer-range").Parse(javaJSONIntegerRange))
 
 	for _, p := range packages {
 		var buf bytes.Buffer
@@ -51,6 +81,27 @@ func GenerateJava(basedir string, packages Packages) error {
 			}
 		}
 		p.SuperClassNative = buf.String()
+
+		for _, e := range p.Enums {
+			e.NameNative = e.NameTitle()
+			switch e.Type {
+			case "uint8":
+				e.TypeNative = "byte"
+			case "uint16":
+				e.TypeNative = "short"
+			case "uint32":
+				e.TypeNative = "int"
+			}
+
+			for _, v := range e.Values {
+				v.NameNative = strings.ToUpper(name.SnakeCase(v.Name))
+				if e.TypeNative == "int" {
+					v.ValueNative = fmt.Sprint(v.Value)
+				} else {
+					v.ValueNative = fmt.Sprintf("(%s) %d", e.TypeNative, v.Value)
+				}
+			}
+		}
 	}
 
 	for _, p := range packages {
@@ -71,11 +122,91 @@ func GenerateJava(basedir string, packages Packages) error {
 			}
 		}
 
+		if len(p.Consts) != 0 {
+			for _, c := range p.Consts {
+				c.NameNative = strings.ToUpper(name.SnakeCase(c.Name))
+				c.TypeNative, c.ValueNative = javaConstValue(c)
+			}
+
+			f, err := os.Create(filepath.Join(pkgdir, "Colfer.java"))
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			if err := constTemplate.Execute(f, p); err != nil {
+				return err
+			}
+		}
+
+		if len(p.Structs) != 0 {
+			f, err := os.Create(filepath.Join(pkgdir, "ColferJSON.java"))
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			if err := jsonTemplate.Execute(f, p); err != nil {
+				return err
+			}
+
+			f, err = os.Create(filepath.Join(pkgdir, "ColferLimits.java"))
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			if err := limitsTemplate.Execute(f, p); err != nil {
+				return err
+			}
+
+			f, err = os.Create(filepath.Join(pkgdir, "ColferDepthException.java"))
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			if err := depthTemplate.Execute(f, p); err != nil {
+				return err
+			}
+		}
+
+		for _, e := range p.Enums {
+			f, err := os.Create(filepath.Join(pkgdir, e.NameNative+".java"))
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			if err := enumTemplate.Execute(f, e); err != nil {
+				return err
+			}
+		}
+
+		for _, u := range p.Unions {
+			u.NameNative = u.NameTitle()
+
+			f, err := os.Create(filepath.Join(pkgdir, u.NameNative+".java"))
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			if err := unionTemplate.Execute(f, u); err != nil {
+				return err
+			}
+		}
+
 		for _, s := range p.Structs {
 			for _, f := range s.Fields {
 				switch f.Type {
 				default:
-					if f.TypeRef == nil {
+					if f.TypeUnion != nil {
+						f.TypeNative = f.TypeUnion.NameTitle()
+						if f.TypeUnion.Pkg != p {
+							f.TypeNative = f.TypeUnion.Pkg.NameNative + "." + f.TypeNative
+						}
+					} else if f.TypeRef == nil {
 						f.TypeNative = f.Type
 					} else {
 						f.TypeNative = f.TypeRef.NameTitle()
@@ -105,6 +236,16 @@ func GenerateJava(basedir string, packages Packages) error {
 					f.TypeNative = "byte[]"
 				}
 
+				if f.Optional {
+					// null represents absence
+					f.TypeNative = javaBoxed(f.TypeNative)
+				}
+				if f.TypeKey != "" {
+					// generics require boxed types
+					f.TypeKeyNative = javaBoxed(f.TypeKey)
+					f.TypeNative = javaBoxed(f.TypeNative)
+				}
+
 				f.NameNative = f.Name
 				if IsJavaKeyword(f.NameNative) {
 					f.NameNative += "_"
@@ -125,6 +266,84 @@ func GenerateJava(basedir string, packages Packages) error {
 	return nil
 }
 
+// javaBoxed returns the object representation for a Colfer type or
+// for a Java primitive.
+func javaBoxed(t string) string {
+	switch t {
+	case "bool", "boolean":
+		return "Boolean"
+	case "uint8", "byte":
+		return "Byte"
+	case "uint16", "short":
+		return "Short"
+	case "uint32", "int32", "int":
+		return "Integer"
+	case "uint64", "int64", "long":
+		return "Long"
+	case "float":
+		return "Float"
+	case "double":
+		return "Double"
+	case "text":
+		return "String"
+	}
+	return t
+}
+
+// javaConstValue returns the Java type and expression for c.
+// Unsigned types use the signed representation of the same width.
+func javaConstValue(c *Const) (typ, value string) {
+	switch c.Type {
+	case "bool":
+		return "boolean", c.Value.ExactString()
+	case "uint8":
+		return "byte", "(byte) " + c.Value.ExactString()
+	case "uint16":
+		return "short", "(short) " + c.Value.ExactString()
+	case "uint32":
+		return "int", "(int) " + c.Value.ExactString() + "L"
+	case "int32":
+		return "int", c.Value.ExactString()
+	case "uint64":
+		// hexadecimal literals cover the full 64 bits
+		if x, _ := constant.Uint64Val(c.Value); x > math.MaxInt64 {
+			return "long", fmt.Sprintf("0x%xL", x)
+		}
+		return "long", c.Value.ExactString() + "L"
+	case "int64":
+		return "long", c.Value.ExactString() + "L"
+	case "float32":
+		return "float", floatLiteral(c.Value, 32) + "f"
+	case "float64":
+		return "double", floatLiteral(c.Value, 64)
+	}
+	return "String", javaString(constant.StringVal(c.Value))
+}
+
+// javaString returns a string literal of s. Control characters are escaped
+// in octal, as the compiler translates Unicode escapes before parsing.
+func javaString(s string) string {
+	var buf bytes.Buffer
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			buf.WriteByte('\\')
+			buf.WriteRune(r)
+		case r < ' ' || r == 0x7f:
+			fmt.Fprintf(&buf, "\\%03o", r)
+		case r > 0x7f:
+			for _, u := range utf16.Encode([]rune{r}) {
+				fmt.Fprintf(&buf, "\\u%04x", u)
+			}
+		default:
+			buf.WriteRune(r)
+		}
+	}
+	buf.WriteByte('"')
+	return buf.String()
+}
+
 const javaPackage = `// Code generated by colf(1); DO NOT EDIT.
 // The compiler used schema file {{.SchemaFileList}}.
 
@@ -134,6 +353,204 @@ const javaPackage = `// Code generated by colf(1); DO NOT EDIT.
 package {{.NameNative}};
 `
 
+const javaConst = `package {{.NameNative}};
+
+
+// Code generated by colf(1); DO NOT EDIT.
+// The compiler used schema file {{.SchemaFileList}}.
+
+
+/**
+ * Constant definitions of package {{.Name}}.
+ * @author generated by colf(1)
+ * @see <a href="https://github.com/pascaldekloe/colfer">Colfer's home</a>
+ */
+public final class Colfer {
+
+	private Colfer() {}
+{{range .Consts}}
+{{- if .Docs}}
+	/**
+{{.DocText "\t * "}}
+	 */
+{{- end}}
+	public static final {{.TypeNative}} {{.NameNative}} = {{.ValueNative}};
+{{end}}
+}
+`
+
+const javaLimits = `package {{.NameNative}};
+
+
+// Code generated by colf(1); DO NOT EDIT.
+// The compiler used schema file {{.SchemaFileList}}.
+
+
+/**
+ * Upper boundaries for the serialization and deserialization of the data beans
+ * in package {{.Name}}. The limits apply to nested data structures too,
+ * including those from other packages. Instances are immutable, and thus safe
+ * to share.
+ * @author generated by colf(1)
+ * @see <a href="https://github.com/pascaldekloe/colfer">Colfer's home</a>
+ */
+public final class ColferLimits {
+
+	/** The upper limit for serial byte sizes. */
+	public final int sizeMax;
+
+	/** The upper limit for the number of elements in a list or in a map. */
+	public final int listMax;
+
+	/**
+	 * The upper limit for the nesting depth of data structures, with one
+	 * for a data bean without any nested values.
+	 */
+	public final int depthMax;
+
+	/**
+	 * @param sizeMax the upper limit for serial byte sizes.
+	 * @param listMax the upper limit for the number of elements in a list or in a map.
+	 * @param depthMax the upper limit for the nesting depth of data structures.
+	 */
+	public ColferLimits(int sizeMax, int listMax, int depthMax) {
+		this.sizeMax = sizeMax;
+		this.listMax = listMax;
+		this.depthMax = depthMax;
+	}
+
+}
+`
+
+const javaDepthException = `package {{.NameNative}};
+
+
+// Code generated by colf(1); DO NOT EDIT.
+// The compiler used schema file {{.SchemaFileList}}.
+
+
+/**
+ * Signals a breach of {@link ColferLimits#depthMax} by the data beans in
+ * package {{.Name}}. Marshalling a reference cycle breaches the limit too.
+ * @author generated by colf(1)
+ * @see <a href="https://github.com/pascaldekloe/colfer">Colfer's home</a>
+ */
+public class ColferDepthException extends SecurityException {
+
+	private static final long serialVersionUID = 1L;
+
+	/**
+	 * @param message the detail message.
+	 */
+	public ColferDepthException(String message) {
+		super(message);
+	}
+
+}
+`
+
+const javaLimitsArg = `{{if ne .TypeNative .TypeRef.NameTitle}}new {{.TypeRef.Pkg.NameNative}}.ColferLimits(nested.sizeMax, nested.listMax, nested.depthMax){{else}}nested{{end}}`
+
+const javaEnum = `package {{.Pkg.NameNative}};
+
+
+// Code generated by colf(1); DO NOT EDIT.
+// The compiler used schema file {{.Pkg.SchemaFileList}}.
+
+
+/**
+ * Enumeration of the {{.Type}} values for {{.String}}.
+{{.DocText " * "}}
+ * Data objects hold the numeric {@link #value value} instead of the element,
+ * such that unknown values survive deserialization.
+ * @author generated by colf(1)
+ * @see <a href="https://github.com/pascaldekloe/colfer">Colfer's home</a>
+ */
+public enum {{.NameNative}} {
+{{- range .Values}}
+{{if .Docs}}
+	/**
+{{.DocText "\t * "}}
+	 */
+{{- end}}
+	{{.NameNative}}({{.ValueNative}}, "{{.Name}}"),
+{{- end}}
+	;
+
+	/** The numeric representation. */
+	public final {{.TypeNative}} value;
+
+	/** The name in the schema, as used for JSON. */
+	public final String schemaName;
+
+	private {{.NameNative}}({{.TypeNative}} value, String schemaName) {
+		this.value = value;
+		this.schemaName = schemaName;
+	}
+
+	/**
+	 * Gets the element for a numeric representation.
+	 * @param value the numeric representation.
+	 * @return the first element with {@code value} or {@code null} when unknown.
+	 */
+	public static {{.NameNative}} valueOf({{.TypeNative}} value) {
+		for ({{.NameNative}} e : values())
+			if (e.value == value) return e;
+		return null;
+	}
+
+	/**
+	 * Gets the element for a name in the schema.
+	 * @param schemaName the name in the schema.
+	 * @return the element or {@code null} when unknown.
+	 */
+	public static {{.NameNative}} valueOfSchemaName(String schemaName) {
+		for ({{.NameNative}} e : values())
+			if (e.schemaName.equals(schemaName)) return e;
+		return null;
+	}
+
+}
+`
+
+const javaUnion = `package {{.Pkg.NameNative}};
+
+
+// Code generated by colf(1); DO NOT EDIT.
+// The compiler used schema file {{.Pkg.SchemaFileList}}.
+
+
+import java.io.Serializable;
+
+
+/**
+ * Choice of data beans for {{.String}}.
+{{.DocText " * "}}
+ * The members are{{range $i, $m := .Members}}{{if $i}},{{end}} {@link {{.Struct.NameTitle}}}{{end}}.
+ * @author generated by colf(1)
+ * @see <a href="https://github.com/pascaldekloe/colfer">Colfer's home</a>
+ */
+public interface {{.NameNative}} extends Serializable {
+
+	/**
+	 * Dispatches to the method of the member type.
+	 * @param <R> the result type.
+	 * @param v the member handler.
+	 * @return the result of the respective method.
+	 */
+	<R> R accept(Visitor<R> v);
+
+	/**
+	 * Handler with a method per member of {@link {{.NameNative}}}.
+	 * @param <R> the result type.
+	 */
+	interface Visitor<R> {
+{{range .Members}}		R visit({{.Struct.NameTitle}} value);
+{{end}}	}
+
+}
+`
+
 const javaCode = `package {{.Pkg.NameNative}};
 
 
@@ -163,21 +580,45 @@ import java.nio.BufferUnderflowException;
  * @author generated by colf(1)
  * @see <a href="https://github.com/pascaldekloe/colfer">Colfer's home</a>
  */
-{{$class := .NameTitle}}public class {{$class}}{{if .Pkg.SuperClassNative}} extends {{.Pkg.SuperClassNative}}{{end}} implements Serializable {
+{{$class := .NameTitle}}public class {{$class}}{{if .Pkg.SuperClassNative}} extends {{.Pkg.SuperClassNative}}{{end}} implements Serializable{{range .Unions}}, {{.NameTitle}}{{end}} {
 
-	/** The upper limit for serial byte sizes. */
+	/** The upper limit for serial byte sizes, as applied by {@link #colferDefaults()}. */
 	public static int colferSizeMax = {{.Pkg.SizeMax}};
 {{if .HasList}}
-	/** The upper limit for the number of elements in a list. */
+	/** The upper limit for the number of elements in a list, as applied by {@link #colferDefaults()}. */
 	public static int colferListMax = {{.Pkg.ListMax}};
 {{end}}
+	/** The upper limit for the nesting depth of data structures, as applied by {@link #colferDefaults()}. */
+	public static int colferDepthMax = {{.Pkg.DepthMax}};
+
+	/**
+	 * Gets the limits which apply when none are given. A change to
+	 * {@link #colferSizeMax}, {{if .HasList}}{@link #colferListMax} {{end}}or {@link #colferDepthMax} races with any
+	 * (de)serialization in progress. The methods with explicit limits need no such change.
+	 * @return the limits of {@link #colferSizeMax}, {{if .HasList}}{@link #colferListMax} {{end}}and {@link #colferDepthMax}.
+	 */
+	public static ColferLimits colferDefaults() {
+		return new ColferLimits(colferSizeMax, {{if .HasList}}colferListMax{{else}}{{.Pkg.ListMax}}{{end}}, colferDepthMax);
+	}
 {{- range .Fields}}
-{{if .Docs}}
+{{if .TypeEnum}}
+	/**
+{{- if .Docs}}
+{{.DocText "\t * "}}
+{{- end}}
+	 * @see {{.TypeEnum.Pkg.NameNative}}.{{.TypeEnum.NameNative}}
+	 */
+{{- else if .Docs}}
 	/**
 {{.DocText "\t * "}}
 	 */
 {{- end}}
-	public {{.TypeNative}}{{if .TypeList}}[]{{end}} {{.NameNative}};{{end}}
+	public {{if .TypeKey}}java.util.Map<{{.TypeKeyNative}}, {{.TypeNative}}>{{else}}{{.TypeNative}}{{if .TypeList}}[]{{end}}{{end}} {{.NameNative}};{{end}}
+{{- if .Pkg.KeepUnknown}}
+
+	/** The fields of a newer schema, if any, in serial form. */
+	public byte[] colferUnknown;
+{{- end}}
 
 
 	/** Default constructor */
@@ -196,12 +637,27 @@ import java.nio.BufferUnderflowException;
 	private static final {{.TypeNative}}[] _zero{{.NameTitle}} = new {{.TypeNative}}[0];
  {{- end}}
 {{- end}}
+{{- end}}
+{{- range .Fields}}
+{{- if .TypeKey}}
+	private static final java.util.Comparator<{{.TypeKeyNative}}> _order{{.NameTitle}} =
+ {{- if eq .TypeKey "text"}} {{$class}}::_compareUTF8;
+ {{- else if eq .TypeKey "uint8"}} (a, b) -> (a & 0xff) - (b & 0xff);
+ {{- else if eq .TypeKey "uint16"}} (a, b) -> (a & 0xffff) - (b & 0xffff);
+ {{- else if eq .TypeKey "uint32"}} Integer::compareUnsigned;
+ {{- else if eq .TypeKey "uint64"}} Long::compareUnsigned;
+ {{- else if eq .TypeKey "int32"}} Integer::compare;
+ {{- else}} Long::compare;
+ {{- end}}
+{{- end}}
 {{- end}}
 
 	/** Colfer zero values. */
 	private void init() {
 {{- range .Fields}}
-{{- if eq .Type "binary"}}
+{{- if .TypeKey}}
+		{{.NameNative}} = new java.util.HashMap<>();
+{{- else if eq .Type "binary"}}
   {{- if .TypeList}}
 		{{.NameNative}} = _zeroBinaries;
   {{- else}}
@@ -232,15 +688,28 @@ import java.nio.BufferUnderflowException;
 		/** The {@link #buf buffer}'s data end index, exclusive. */
 		protected int i;
 
+		/** The upper boundaries. */
+		protected final ColferLimits limits;
+
 
 		/**
 		 * @param in the data source or {@code null}.
 		 * @param buf the initial buffer or {@code null}.
 		 */
 		public Unmarshaller(InputStream in, byte[] buf) {
+			this(in, buf, {{$class}}.colferDefaults());
+		}
+
+		/**
+		 * @param in the data source or {@code null}.
+		 * @param buf the initial buffer or {@code null}.
+		 * @param limits the upper boundaries.
+		 */
+		public Unmarshaller(InputStream in, byte[] buf, ColferLimits limits) {
+			this.limits = limits;
 			// TODO: better size estimation
 			if (buf == null || buf.length == 0)
-				buf = new byte[Math.min({{$class}}.colferSizeMax, 2048)];
+				buf = new byte[Math.min(limits.sizeMax, 2048)];
 			this.buf = buf;
 			reset(in);
 		}
@@ -261,7 +730,7 @@ import java.nio.BufferUnderflowException;
 		 * Deserializes the following object.
 		 * @return the result or {@code null} when EOF.
 		 * @throws IOException from the input stream.
-		 * @throws SecurityException on an upper limit breach defined by{{if .HasList}} either{{end}} {@link #colferSizeMax}{{if .HasList}} or {@link #colferListMax}{{end}}.
+		 * @throws SecurityException on an upper limit breach defined by {@link #limits}.
 		 * @throws InputMismatchException when the data does not match this object's schema.
 		 */
 		public {{$class}} next() throws IOException {
@@ -271,7 +740,7 @@ import java.nio.BufferUnderflowException;
 				if (this.i > this.offset) {
 					try {
 						{{$class}} o = new {{$class}}();
-						this.offset = o.unmarshal(this.buf, this.offset, this.i);
+						this.offset = o.unmarshal(this.buf, this.offset, this.i, this.limits);
 						return o;
 					} catch (BufferUnderflowException e) {
 					}
@@ -284,7 +753,7 @@ import java.nio.BufferUnderflowException;
 				} else if (i == buf.length) {
 					byte[] src = this.buf;
 					// TODO: better size estimation
-					if (offset == 0) this.buf = new byte[Math.min({{$class}}.colferSizeMax, this.buf.length * 4)];
+					if (offset == 0) this.buf = new byte[Math.min(this.limits.sizeMax, this.buf.length * 4)];
 					System.arraycopy(src, this.offset, this.buf, 0, this.i - this.offset);
 					this.i -= this.offset;
 					this.offset = 0;
@@ -307,7 +776,9 @@ import java.nio.BufferUnderflowException;
 
 	/**
 	 * Serializes the object.
-{{- range .Fields}}{{if .TypeList}}{{if eq .Type "float32" "float64"}}{{else}}
+{{- range .Fields}}{{if .TypeKey}}
+	 * All {@code null} values in {@link #{{.NameNative}}} will be replaced with {{if eq .Type "bool"}}{@code false}{{else if eq .Type "text"}}{@code ""}{{else if eq .Type "binary"}}an empty byte array{{else if .TypeRef}}a {@code new} value{{else}}zero{{end}}.
+{{- else if .TypeList}}{{if eq .Type "bool" "uint8" "uint16" "uint32" "uint64" "int32" "int64" "float32" "float64"}}{{else}}
 	 * All {@code null} elements in {@link #{{.NameNative}}} will be replaced with {{if eq .Type "text"}}{@code ""}{{else if eq .Type "binary"}}an empty byte array{{else}}a {@code new} value{{end}}.
 {{- end}}{{end}}{{end}}
 	 * @param out the data destination.
@@ -316,6 +787,7 @@ import java.nio.BufferUnderflowException;
 	 *  Otherwise the return is a new buffer, large enough to hold the whole serial.
 	 * @throws IOException from {@code out}.
 	 * @throws IllegalStateException on an upper limit breach defined by{{if .HasList}} either{{end}} {@link #colferSizeMax}{{if .HasList}} or {@link #colferListMax}{{end}}.
+	 * @throws ColferDepthException on an upper limit breach defined by {@link #colferDepthMax}.
 	 */
 	public byte[] marshal(OutputStream out, byte[] buf) throws IOException {
 		// TODO: better size estimation
@@ -338,7 +810,9 @@ import java.nio.BufferUnderflowException;
 
 	/**
 	 * Serializes the object.
-{{- range .Fields}}{{if .TypeList}}{{if eq .Type "float32" "float64"}}{{else}}
+{{- range .Fields}}{{if .TypeKey}}
+	 * All {@code null} values in {@link #{{.NameNative}}} will be replaced with {{if eq .Type "bool"}}{@code false}{{else if eq .Type "text"}}{@code ""}{{else if eq .Type "binary"}}an empty byte array{{else if .TypeRef}}a {@code new} value{{else}}zero{{end}}.
+{{- else if .TypeList}}{{if eq .Type "bool" "uint8" "uint16" "uint32" "uint64" "int32" "int64" "float32" "float64"}}{{else}}
 	 * All {@code null} elements in {@link #{{.NameNative}}} will be replaced with {{if eq .Type "text"}}{@code ""}{{else if eq .Type "binary"}}an empty byte array{{else}}a {@code new} value{{end}}.
 {{- end}}{{end}}{{end}}
 	 * @param buf the data destination.
@@ -346,22 +820,239 @@ import java.nio.BufferUnderflowException;
 	 * @return the final index for {@code buf}, exclusive.
 	 * @throws BufferOverflowException when {@code buf} is too small.
 	 * @throws IllegalStateException on an upper limit breach defined by{{if .HasList}} either{{end}} {@link #colferSizeMax}{{if .HasList}} or {@link #colferListMax}{{end}}.
+	 * @throws ColferDepthException on an upper limit breach defined by {@link #colferDepthMax}.
 	 */
 	public int marshal(byte[] buf, int offset) {
+		return marshal(buf, offset, colferDefaults());
+	}
+
+	/**
+	 * Serializes the object like {@link #marshal(byte[], int)} does,
+	 * with explicit limits instead of the {@link #colferDefaults() defaults}.
+	 * @param buf the data destination.
+	 * @param offset the initial index for {@code buf}, inclusive.
+	 * @param limits the upper boundaries.
+	 * @return the final index for {@code buf}, exclusive.
+	 * @throws BufferOverflowException when {@code buf} is too small.
+	 * @throws IllegalStateException on an upper limit breach.
+	 * @throws ColferDepthException on an upper limit breach defined by {@link ColferLimits#depthMax}.
+	 */
+	public int marshal(byte[] buf, int offset, ColferLimits limits) {
+		if (limits.depthMax < 1)
+			throw new ColferDepthException("colfer: {{.String}} exceeds the nesting depth limit; check for reference cycles");
+{{- if .HasRef}}
+		// nested data structures are one level deeper
+		ColferLimits nested = new ColferLimits(limits.sizeMax, limits.listMax, limits.depthMax - 1);
+{{- end}}
 		int i = offset;
 
 		try {
-{{- range .Fields}}{{if eq .Type "bool"}}
+{{- range .Fields}}{{if .TypeKey}}
+			if (! this.{{.NameNative}}.isEmpty()) {
+				buf[i++] = (byte) {{.Index}};
+				java.util.Map<{{.TypeKeyNative}}, {{.TypeNative}}> m = this.{{.NameNative}};
+
+				int l = m.size();
+				if (l > {{or .ListMax "limits.listMax"}})
+					throw new IllegalStateException(format("colfer: {{.String}} length %d exceeds %d elements", l, {{or .ListMax "limits.listMax"}}));
+				while (l > 0x7f) {
+					buf[i++] = (byte) (l | 0x80);
+					l >>>= 7;
+				}
+				buf[i++] = (byte) l;
+
+				{{.TypeKeyNative}}[] keys = m.keySet().toArray(new {{.TypeKeyNative}}[m.size()]);
+				for ({{.TypeKeyNative}} k : keys)
+					if (k == null) throw new IllegalStateException("colfer: {{.String}} has a null key");
+				java.util.Arrays.sort(keys, _order{{.NameTitle}});
+
+				for ({{.TypeKeyNative}} k : keys) {
+ {{- if eq .TypeKey "text"}}
+					byte[] kb = k.getBytes(StandardCharsets.UTF_8);
+					if (kb.length > {{or .SizeMax "limits.sizeMax"}})
+						throw new IllegalStateException(format("colfer: {{.String}} key size %d exceeds %d UTF-8 bytes", kb.length, {{or .SizeMax "limits.sizeMax"}}));
+					int kx = kb.length;
+					while (kx > 0x7f) {
+						buf[i++] = (byte) (kx | 0x80);
+						kx >>>= 7;
+					}
+					buf[i++] = (byte) kx;
+					int kStart = i;
+					i += kb.length;
+					System.arraycopy(kb, 0, buf, kStart, kb.length);
+ {{- else if eq .TypeKey "uint8"}}
+					buf[i++] = k;
+ {{- else if eq .TypeKey "uint64" "int64"}}
+  {{- if eq .TypeKey "int64"}}
+					long kx = (k << 1) ^ (k >> 63);
+  {{- else}}
+					long kx = k;
+  {{- end}}
+					for (int n = 0; n < 8 && (kx & ~0x7fL) != 0; n++) {
+						buf[i++] = (byte) (kx | 0x80);
+						kx >>>= 7;
+					}
+					buf[i++] = (byte) kx;
+ {{- else}}
+  {{- if eq .TypeKey "int32"}}
+					int kx = (k << 1) ^ (k >> 31);
+  {{- else if eq .TypeKey "uint16"}}
+					int kx = k & 0xffff;
+  {{- else}}
+					int kx = k;
+  {{- end}}
+					while ((kx & ~0x7f) != 0) {
+						buf[i++] = (byte) (kx | 0x80);
+						kx >>>= 7;
+					}
+					buf[i++] = (byte) kx;
+ {{- end}}
+
+					{{.TypeNative}} v = m.get(k);
+					if (v == null) {
+						v = {{if eq .Type "bool"}}false{{else if eq .Type "uint8"}}(byte) 0{{else if eq .Type "uint16"}}(short) 0{{else if eq .Type "uint32" "int32"}}0{{else if eq .Type "uint64" "int64"}}0L{{else if eq .Type "float32"}}0f{{else if eq .Type "float64"}}0.0{{else if eq .Type "text"}}""{{else if eq .Type "binary"}}_zeroBytes{{else}}new {{.TypeNative}}(){{end}};
+						m.put(k, v);
+					}
+ {{- if eq .Type "bool"}}
+					buf[i++] = (byte) (v ? 1 : 0);
+ {{- else if eq .Type "uint8"}}
+					buf[i++] = v;
+ {{- else if eq .Type "uint64" "int64"}}
+  {{- if eq .Type "int64"}}
+					long vx = (v << 1) ^ (v >> 63);
+  {{- else}}
+					long vx = v;
+  {{- end}}
+					for (int n = 0; n < 8 && (vx & ~0x7fL) != 0; n++) {
+						buf[i++] = (byte) (vx | 0x80);
+						vx >>>= 7;
+					}
+					buf[i++] = (byte) vx;
+ {{- else if eq .Type "uint16" "uint32" "int32"}}
+  {{- if eq .Type "int32"}}
+					int vx = (v << 1) ^ (v >> 31);
+  {{- else if eq .Type "uint16"}}
+					int vx = v & 0xffff;
+  {{- else}}
+					int vx = v;
+  {{- end}}
+					while ((vx & ~0x7f) != 0) {
+						buf[i++] = (byte) (vx | 0x80);
+						vx >>>= 7;
+					}
+					buf[i++] = (byte) vx;
+ {{- else if eq .Type "float32"}}
+					int vx = Float.floatToRawIntBits(v);
+					buf[i++] = (byte) (vx >>> 24);
+					buf[i++] = (byte) (vx >>> 16);
+					buf[i++] = (byte) (vx >>> 8);
+					buf[i++] = (byte) (vx);
+ {{- else if eq .Type "float64"}}
+					long vx = Double.doubleToRawLongBits(v);
+					buf[i++] = (byte) (vx >>> 56);
+					buf[i++] = (byte) (vx >>> 48);
+					buf[i++] = (byte) (vx >>> 40);
+					buf[i++] = (byte) (vx >>> 32);
+					buf[i++] = (byte) (vx >>> 24);
+					buf[i++] = (byte) (vx >>> 16);
+					buf[i++] = (byte) (vx >>> 8);
+					buf[i++] = (byte) (vx);
+ {{- else if eq .Type "text" "binary"}}
+  {{- if eq .Type "text"}}
+					byte[] vb = v.getBytes(StandardCharsets.UTF_8);
+  {{- else}}
+					byte[] vb = v;
+  {{- end}}
+					if (vb.length > {{or .SizeMax "limits.sizeMax"}})
+						throw new IllegalStateException(format("colfer: {{.String}} value size %d exceeds %d {{if eq .Type "text"}}UTF-8 {{end}}bytes", vb.length, {{or .SizeMax "limits.sizeMax"}}));
+					int vx = vb.length;
+					while (vx > 0x7f) {
+						buf[i++] = (byte) (vx | 0x80);
+						vx >>>= 7;
+					}
+					buf[i++] = (byte) vx;
+					int vStart = i;
+					i += vb.length;
+					System.arraycopy(vb, 0, buf, vStart, vb.length);
+ {{- else}}
+{{- if eq .TypeRef.String .Struct.String}}
+					if (v == this)
+						throw new ColferDepthException("colfer: {{.String}} makes a reference cycle");
+{{- end}}
+					i = v.marshal(buf, i, {{template "limits-arg" .}});
+ {{- end}}
+				}
+			}
+{{else if and .TypeList (eq .Type "bool" "uint8" "uint16" "uint32" "uint64" "int32" "int64")}}
+			if (this.{{.NameNative}}.length != 0) {
+				buf[i++] = (byte) {{.Index}};
+				{{.TypeNative}}[] a = this.{{.NameNative}};
+
+				int l = a.length;
+				if (l > {{or .ListMax "limits.listMax"}})
+					throw new IllegalStateException(format("colfer: {{.String}} length %d exceeds %d elements", l, {{or .ListMax "limits.listMax"}}));
+				while (l > 0x7f) {
+					buf[i++] = (byte) (l | 0x80);
+					l >>>= 7;
+				}
+				buf[i++] = (byte) l;
+ {{- if eq .Type "bool"}}
+
+				for (boolean b : a) buf[i++] = (byte) (b ? 1 : 0);
+ {{- else if eq .Type "uint8"}}
+
+				int start = i;
+				i += a.length;
+				System.arraycopy(a, 0, buf, start, a.length);
+ {{- else if eq .Type "uint64" "int64"}}
+
+				for (long v : a) {
+  {{- if eq .Type "int64"}}
+					long x = (v << 1) ^ (v >> 63);
+  {{- else}}
+					long x = v;
+  {{- end}}
+					for (int n = 0; n < 8 && (x & ~0x7fL) != 0; n++) {
+						buf[i++] = (byte) (x | 0x80);
+						x >>>= 7;
+					}
+					buf[i++] = (byte) x;
+				}
+ {{- else}}
+
+				for ({{.TypeNative}} v : a) {
+  {{- if eq .Type "int32"}}
+					int x = (v << 1) ^ (v >> 31);
+  {{- else if eq .Type "uint16"}}
+					int x = v & 0xffff;
+  {{- else}}
+					int x = v;
+  {{- end}}
+					while ((x & ~0x7f) != 0) {
+						buf[i++] = (byte) (x | 0x80);
+						x >>>= 7;
+					}
+					buf[i++] = (byte) x;
+				}
+ {{- end}}
+			}
+{{else if eq .Type "bool"}}
+ {{- if .Optional}}
+			if (this.{{.NameNative}} != null) {
+				buf[i++] = (byte) (this.{{.NameNative}} ? {{.Index}} : {{.Index}} | 0x80);
+			}
+ {{- else}}
 			if (this.{{.NameNative}}) {
 				buf[i++] = (byte) {{.Index}};
 			}
+ {{- end}}
 {{else if eq .Type "uint8"}}
-			if (this.{{.NameNative}} != 0) {
+			if (this.{{.NameNative}} != {{if .Optional}}null{{else}}0{{end}}) {
 				buf[i++] = (byte) {{.Index}};
 				buf[i++] = this.{{.NameNative}};
 			}
 {{else if eq .Type "uint16"}}
-			if (this.{{.NameNative}} != 0) {
+			if (this.{{.NameNative}} != {{if .Optional}}null{{else}}0{{end}}) {
 				short x = this.{{.NameNative}};
 				if ((x & (short)0xff00) != 0) {
 					buf[i++] = (byte) {{.Index}};
@@ -372,7 +1063,7 @@ import java.nio.BufferUnderflowException;
 				buf[i++] = (byte) x;
 			}
 {{else if eq .Type "uint32"}}
-			if (this.{{.NameNative}} != 0) {
+			if (this.{{.NameNative}} != {{if .Optional}}null{{else}}0{{end}}) {
 				int x = this.{{.NameNative}};
 				if ((x & ~((1 << 21) - 1)) != 0) {
 					buf[i++] = (byte) ({{.Index}} | 0x80);
@@ -389,7 +1080,7 @@ import java.nio.BufferUnderflowException;
 				buf[i++] = (byte) x;
 			}
 {{else if eq .Type "uint64"}}
-			if (this.{{.NameNative}} != 0) {
+			if (this.{{.NameNative}} != {{if .Optional}}null{{else}}0{{end}}) {
 				long x = this.{{.NameNative}};
 				if ((x & ~((1L << 49) - 1)) != 0) {
 					buf[i++] = (byte) ({{.Index}} | 0x80);
@@ -411,7 +1102,7 @@ import java.nio.BufferUnderflowException;
 				}
 			}
 {{else if eq .Type "int32"}}
-			if (this.{{.NameNative}} != 0) {
+			if (this.{{.NameNative}} != {{if .Optional}}null{{else}}0{{end}}) {
 				int x = this.{{.NameNative}};
 				if (x < 0) {
 					x = -x;
@@ -425,7 +1116,7 @@ import java.nio.BufferUnderflowException;
 				buf[i++] = (byte) x;
 			}
 {{else if eq .Type "int64"}}
-			if (this.{{.NameNative}} != 0) {
+			if (this.{{.NameNative}} != {{if .Optional}}null{{else}}0{{end}}) {
 				long x = this.{{.NameNative}};
 				if (x < 0) {
 					x = -x;
@@ -445,8 +1136,8 @@ import java.nio.BufferUnderflowException;
 				float[] a = this.{{.NameNative}};
 
 				int l = a.length;
-				if (l > {{$class}}.colferListMax)
-					throw new IllegalStateException(format("colfer: {{.String}} length %d exceeds %d elements", l, {{$class}}.colferListMax));
+				if (l > {{or .ListMax "limits.listMax"}})
+					throw new IllegalStateException(format("colfer: {{.String}} length %d exceeds %d elements", l, {{or .ListMax "limits.listMax"}}));
 				while (l > 0x7f) {
 					buf[i++] = (byte) (l | 0x80);
 					l >>>= 7;
@@ -462,7 +1153,7 @@ import java.nio.BufferUnderflowException;
 				}
 			}
  {{- else}}
-			if (this.{{.NameNative}} != 0.0f) {
+			if (this.{{.NameNative}} != {{if .Optional}}null{{else}}0.0f{{end}}) {
 				buf[i++] = (byte) {{.Index}};
 				int x = Float.floatToRawIntBits(this.{{.NameNative}});
 				buf[i++] = (byte) (x >>> 24);
@@ -478,8 +1169,8 @@ import java.nio.BufferUnderflowException;
 				double[] a = this.{{.NameNative}};
 
 				int l = a.length;
-				if (l > {{$class}}.colferListMax)
-					throw new IllegalStateException(format("colfer: {{.String}} length %d exceeds %d elements", l, {{$class}}.colferListMax));
+				if (l > {{or .ListMax "limits.listMax"}})
+					throw new IllegalStateException(format("colfer: {{.String}} length %d exceeds %d elements", l, {{or .ListMax "limits.listMax"}}));
 				while (l > 0x7f) {
 					buf[i++] = (byte) (l | 0x80);
 					l >>>= 7;
@@ -499,7 +1190,7 @@ import java.nio.BufferUnderflowException;
 				}
 			}
  {{- else}}
-			if (this.{{.NameNative}} != 0.0) {
+			if (this.{{.NameNative}} != {{if .Optional}}null{{else}}0.0{{end}}) {
 				buf[i++] = (byte) {{.Index}};
 				long x = Double.doubleToRawLongBits(this.{{.NameNative}});
 				buf[i++] = (byte) (x >>> 56);
@@ -551,8 +1242,8 @@ import java.nio.BufferUnderflowException;
 				String[] a = this.{{.NameNative}};
 
 				int x = a.length;
-				if (x > {{$class}}.colferListMax)
-					throw new IllegalStateException(format("colfer: {{.String}} length %d exceeds %d elements", x, {{$class}}.colferListMax));
+				if (x > {{or .ListMax "limits.listMax"}})
+					throw new IllegalStateException(format("colfer: {{.String}} length %d exceeds %d elements", x, {{or .ListMax "limits.listMax"}}));
 				while (x > 0x7f) {
 					buf[i++] = (byte) (x | 0x80);
 					x >>>= 7;
@@ -592,8 +1283,8 @@ import java.nio.BufferUnderflowException;
 						}
 					}
 					int size = i - start;
-					if (size > {{$class}}.colferSizeMax)
-						throw new IllegalStateException(format("colfer: {{.String}}[%d] size %d exceeds %d UTF-8 bytes", ai, size, {{$class}}.colferSizeMax));
+					if (size > {{or .SizeMax "limits.sizeMax"}})
+						throw new IllegalStateException(format("colfer: {{.String}}[%d] size %d exceeds %d UTF-8 bytes", ai, size, {{or .SizeMax "limits.sizeMax"}}));
 
 					int ii = start - 1;
 					if (size > 0x7f) {
@@ -639,8 +1330,8 @@ import java.nio.BufferUnderflowException;
 					}
 				}
 				int size = i - start;
-				if (size > {{$class}}.colferSizeMax)
-					throw new IllegalStateException(format("colfer: {{.String}} size %d exceeds %d UTF-8 bytes", size, {{$class}}.colferSizeMax));
+				if (size > {{or .SizeMax "limits.sizeMax"}})
+					throw new IllegalStateException(format("colfer: {{.String}} size %d exceeds %d UTF-8 bytes", size, {{or .SizeMax "limits.sizeMax"}}));
 
 				int ii = start - 1;
 				if (size > 0x7f) {
@@ -663,8 +1354,8 @@ import java.nio.BufferUnderflowException;
 				byte[][] a = this.{{.NameNative}};
 
 				int x = a.length;
-				if (x > {{$class}}.colferListMax)
-					throw new IllegalStateException(format("colfer: {{.String}} length %d exceeds %d elements", x, {{$class}}.colferListMax));
+				if (x > {{or .ListMax "limits.listMax"}})
+					throw new IllegalStateException(format("colfer: {{.String}} length %d exceeds %d elements", x, {{or .ListMax "limits.listMax"}}));
 				while (x > 0x7f) {
 					buf[i++] = (byte) (x | 0x80);
 					x >>>= 7;
@@ -677,8 +1368,8 @@ import java.nio.BufferUnderflowException;
 						b = _zeroBytes;
 						a[ai] = b;
 					}
-					if (b.length > {{$class}}.colferSizeMax)
-						throw new IllegalStateException(format("colfer: {{.String}}[%d] size %d exceeds %d bytes", ai, b.length, {{$class}}.colferSizeMax));
+					if (b.length > {{or .SizeMax "limits.sizeMax"}})
+						throw new IllegalStateException(format("colfer: {{.String}}[%d] size %d exceeds %d bytes", ai, b.length, {{or .SizeMax "limits.sizeMax"}}));
 
 					x = b.length;
 					while (x > 0x7f) {
@@ -697,8 +1388,8 @@ import java.nio.BufferUnderflowException;
 				buf[i++] = (byte) {{.Index}};
 
 				int size = this.{{.NameNative}}.length;
-				if (size > {{$class}}.colferSizeMax)
-					throw new IllegalStateException(format("colfer: {{.String}} size %d exceeds %d bytes", size, {{$class}}.colferSizeMax));
+				if (size > {{or .SizeMax "limits.sizeMax"}})
+					throw new IllegalStateException(format("colfer: {{.String}} size %d exceeds %d bytes", size, {{or .SizeMax "limits.sizeMax"}}));
 
 				int x = size;
 				while (x > 0x7f) {
@@ -718,8 +1409,8 @@ import java.nio.BufferUnderflowException;
 				{{.TypeNative}}[] a = this.{{.NameNative}};
 
 				int x = a.length;
-				if (x > {{$class}}.colferListMax)
-					throw new IllegalStateException(format("colfer: {{.String}} length %d exceeds %d elements", x, {{$class}}.colferListMax));
+				if (x > {{or .ListMax "limits.listMax"}})
+					throw new IllegalStateException(format("colfer: {{.String}} length %d exceeds %d elements", x, {{or .ListMax "limits.listMax"}}));
 				while (x > 0x7f) {
 					buf[i++] = (byte) (x | 0x80);
 					x >>>= 7;
@@ -732,20 +1423,51 @@ import java.nio.BufferUnderflowException;
 						o = new {{.TypeNative}}();
 						a[ai] = o;
 					}
-					i = o.marshal(buf, i);
+{{- if eq .TypeRef.String .Struct.String}}
+					if (o == this)
+						throw new ColferDepthException("colfer: {{.String}} makes a reference cycle");
+{{- end}}
+					i = o.marshal(buf, i, {{template "limits-arg" .}});
+				}
+			}
+{{else if .TypeUnion}}
+ {{- $f := .}}{{$pkg := ""}}{{if ne .TypeNative .TypeUnion.NameTitle}}{{$pkg = print .TypeUnion.Pkg.NameNative "."}}{{end}}
+			if (this.{{.NameNative}} != null) {
+				buf[i++] = (byte) {{.Index}};
+ {{- range .TypeUnion.Members}}
+				{{if .Index}}} else {{end}}if (this.{{$f.NameNative}} instanceof {{$pkg}}{{.Struct.NameTitle}}) {
+					buf[i++] = (byte) {{.Index}};
+{{- if eq .Struct.String $f.Struct.String}}
+					if (this.{{$f.NameNative}} == this)
+						throw new ColferDepthException("colfer: {{$f.String}} makes a reference cycle");
+{{- end}}
+					i = (({{$pkg}}{{.Struct.NameTitle}}) this.{{$f.NameNative}}).marshal(buf, i, {{if $pkg}}new {{$pkg}}ColferLimits(nested.sizeMax, nested.listMax, nested.depthMax){{else}}nested{{end}});
+ {{- end}}
+				} else {
+					throw new IllegalStateException(format("colfer: {{.String}} type %s is not a member", this.{{.NameNative}}.getClass().getName()));
 				}
 			}
 {{else}}
 			if (this.{{.NameNative}} != null) {
 				buf[i++] = (byte) {{.Index}};
-				i = this.{{.NameNative}}.marshal(buf, i);
+{{- if eq .TypeRef.String .Struct.String}}
+				if (this.{{.NameNative}} == this)
+					throw new ColferDepthException("colfer: {{.String}} makes a reference cycle");
+{{- end}}
+				i = this.{{.NameNative}}.marshal(buf, i, {{template "limits-arg" .}});
 			}
 {{end}}{{end}}
+{{- if .Pkg.KeepUnknown}}
+			if (this.colferUnknown != null) {
+				System.arraycopy(this.colferUnknown, 0, buf, i, this.colferUnknown.length);
+				i += this.colferUnknown.length;
+			}
+{{end}}
 			buf[i++] = (byte) 0x7f;
 			return i;
 		} catch (ArrayIndexOutOfBoundsException e) {
-			if (i - offset > {{$class}}.colferSizeMax)
-				throw new IllegalStateException(format("colfer: {{.String}} exceeds %d bytes", {{$class}}.colferSizeMax));
+			if (i - offset > limits.sizeMax)
+				throw new IllegalStateException(format("colfer: {{.String}} exceeds %d bytes", limits.sizeMax));
 			if (i > buf.length) throw new BufferOverflowException();
 			throw e;
 		}
@@ -758,6 +1480,7 @@ import java.nio.BufferUnderflowException;
 	 * @return the final index for {@code buf}, exclusive.
 	 * @throws BufferUnderflowException when {@code buf} is incomplete. (EOF)
 	 * @throws SecurityException on an upper limit breach defined by{{if .HasList}} either{{end}} {@link #colferSizeMax}{{if .HasList}} or {@link #colferListMax}{{end}}.
+	 * @throws ColferDepthException on an upper limit breach defined by {@link #colferDepthMax}.
 	 * @throws InputMismatchException when the data does not match this object's schema.
 	 */
 	public int unmarshal(byte[] buf, int offset) {
@@ -766,25 +1489,255 @@ import java.nio.BufferUnderflowException;
 
 	/**
 	 * Deserializes the object.
+{{- if .Pkg.KeepUnknown}}
+	 * Fields beyond the schema are kept with {@link #unmarshalSerial(byte[], int, int)} only.
+{{- end}}
+	 * @param buf the data source.
+	 * @param offset the initial index for {@code buf}, inclusive.
+	 * @param end the index limit for {@code buf}, exclusive.
+	 * @return the final index for {@code buf}, exclusive.
+	 * @throws BufferUnderflowException when {@code buf} is incomplete. (EOF)
+	 * @throws SecurityException on an upper limit breach defined by{{if .HasList}} either{{end}} {@link #colferSizeMax}{{if .HasList}} or {@link #colferListMax}{{end}}.
+	 * @throws ColferDepthException on an upper limit breach defined by {@link #colferDepthMax}.
+	 * @throws InputMismatchException when the data does not match this object's schema.
+{{- if .Pkg.KeepUnknown}}
+	 */
+	public int unmarshal(byte[] buf, int offset, int end) {
+		return _unmarshal(buf, offset, end, colferDefaults(), false);
+	}
+
+	/**
+	 * Deserializes the object like {@link #unmarshal(byte[], int, int)} does,
+	 * with explicit limits instead of the {@link #colferDefaults() defaults}.
 	 * @param buf the data source.
 	 * @param offset the initial index for {@code buf}, inclusive.
 	 * @param end the index limit for {@code buf}, exclusive.
+	 * @param limits the upper boundaries.
+	 * @return the final index for {@code buf}, exclusive.
+	 * @throws BufferUnderflowException when {@code buf} is incomplete. (EOF)
+	 * @throws SecurityException on an upper limit breach.
+	 * @throws ColferDepthException on an upper limit breach defined by {@link ColferLimits#depthMax}.
+	 * @throws InputMismatchException when the data does not match this object's schema.
+	 */
+	public int unmarshal(byte[] buf, int offset, int end, ColferLimits limits) {
+		return _unmarshal(buf, offset, end, limits, false);
+	}
+
+	/**
+	 * Deserializes the object from exactly one serial.
+	 * Any fields with an index beyond {{.IndexMax}} are kept in {@link #colferUnknown}.
+	 * Nested data structures can not keep unknown fields.
+	 * @param buf the data source.
+	 * @param offset the initial index for {@code buf}, inclusive.
+	 * @param end the index of the serial end for {@code buf}, exclusive.
 	 * @return the final index for {@code buf}, exclusive.
 	 * @throws BufferUnderflowException when {@code buf} is incomplete. (EOF)
 	 * @throws SecurityException on an upper limit breach defined by{{if .HasList}} either{{end}} {@link #colferSizeMax}{{if .HasList}} or {@link #colferListMax}{{end}}.
+	 * @throws ColferDepthException on an upper limit breach defined by {@link #colferDepthMax}.
 	 * @throws InputMismatchException when the data does not match this object's schema.
 	 */
+	public int unmarshalSerial(byte[] buf, int offset, int end) {
+		return _unmarshal(buf, offset, end, colferDefaults(), true);
+	}
+
+	private int _unmarshal(byte[] buf, int offset, int end, ColferLimits limits, boolean serial) {
+{{- else}}
+	 */
 	public int unmarshal(byte[] buf, int offset, int end) {
+		return unmarshal(buf, offset, end, colferDefaults());
+	}
+
+	/**
+	 * Deserializes the object like {@link #unmarshal(byte[], int, int)} does,
+	 * with explicit limits instead of the {@link #colferDefaults() defaults}.
+	 * @param buf the data source.
+	 * @param offset the initial index for {@code buf}, inclusive.
+	 * @param end the index limit for {@code buf}, exclusive.
+	 * @param limits the upper boundaries.
+	 * @return the final index for {@code buf}, exclusive.
+	 * @throws BufferUnderflowException when {@code buf} is incomplete. (EOF)
+	 * @throws SecurityException on an upper limit breach.
+	 * @throws ColferDepthException on an upper limit breach defined by {@link ColferLimits#depthMax}.
+	 * @throws InputMismatchException when the data does not match this object's schema.
+	 */
+	public int unmarshal(byte[] buf, int offset, int end, ColferLimits limits) {
+{{- end}}
+		if (limits.depthMax < 1)
+			throw new ColferDepthException("colfer: {{.String}} exceeds the nesting depth limit");
+{{- if .HasRef}}
+		// nested data structures are one level deeper
+		ColferLimits nested = new ColferLimits(limits.sizeMax, limits.listMax, limits.depthMax - 1);
+{{- end}}
 		if (end > buf.length) end = buf.length;
 		int i = offset;
 
-		try {
-			byte header = buf[i++];
-{{range .Fields}}{{if eq .Type "bool"}}
+		try {
+			byte header = buf[i++];
+{{range .Fields}}{{if .TypeKey}}
+			if (header == (byte) {{.Index}}) {
+				int length = 0;
+				for (int shift = 0; true; shift += 7) {
+					byte b = buf[i++];
+					length |= (b & 0x7f) << shift;
+					if (shift == 28 || b >= 0) break;
+				}
+				if (length < 0 || length > {{or .ListMax "limits.listMax"}})
+					throw new SecurityException(format("colfer: {{.String}} length %d exceeds %d elements", length, {{or .ListMax "limits.listMax"}}));
+
+				java.util.Map<{{.TypeKeyNative}}, {{.TypeNative}}> m = new java.util.HashMap<>();
+				{{.TypeKeyNative}} last = null;
+				for (int mi = 0; mi < length; mi++) {
+					int start = i;
+ {{- if eq .TypeKey "uint8"}}
+					{{.TypeKeyNative}} k = buf[i++];
+ {{- else if eq .TypeKey "uint64" "int64"}}
+					long kx = 0;
+					for (int shift = 0; true; shift += 7) {
+						byte b = buf[i++];
+						if (shift == 56 || b >= 0) {
+							kx |= (b & 0xffL) << shift;
+							break;
+						}
+						kx |= (b & 0x7fL) << shift;
+					}
+					{{.TypeKeyNative}} k = {{if eq .TypeKey "int64"}}(kx >>> 1) ^ -(kx & 1){{else}}kx{{end}};
+ {{- else}}
+					int kx = 0;
+					for (int shift = 0; true; shift += 7) {
+						byte b = buf[i++];
+						kx |= (b & 0x7f) << shift;
+						if (shift == 28 || b >= 0) break;
+					}
+  {{- if eq .TypeKey "text"}}
+					if (kx < 0 || kx > {{or .SizeMax "limits.sizeMax"}})
+						throw new SecurityException(format("colfer: {{.String}} key size %d exceeds %d UTF-8 bytes", kx, {{or .SizeMax "limits.sizeMax"}}));
+					int kStart = i;
+					i += kx;
+					String k = new String(buf, kStart, kx, StandardCharsets.UTF_8);
+  {{- else}}
+					{{.TypeKeyNative}} k = {{if eq .TypeKey "int32"}}(kx >>> 1) ^ -(kx & 1){{else if eq .TypeKey "uint16"}}(short) kx{{else}}kx{{end}};
+  {{- end}}
+ {{- end}}
+					if (last != null && _order{{.NameTitle}}.compare(last, k) >= 0)
+						throw new InputMismatchException(format("colfer: {{.String}} key at byte %d out of order", start));
+					last = k;
+{{if eq .Type "bool"}}
+					{{.TypeNative}} v = buf[i++] != 0;
+ {{- else if eq .Type "uint8"}}
+					{{.TypeNative}} v = buf[i++];
+ {{- else if eq .Type "uint64" "int64"}}
+					long vx = 0;
+					for (int shift = 0; true; shift += 7) {
+						byte b = buf[i++];
+						if (shift == 56 || b >= 0) {
+							vx |= (b & 0xffL) << shift;
+							break;
+						}
+						vx |= (b & 0x7fL) << shift;
+					}
+					{{.TypeNative}} v = {{if eq .Type "int64"}}(vx >>> 1) ^ -(vx & 1){{else}}vx{{end}};
+ {{- else if eq .Type "float32"}}
+					int vx = (buf[i++] & 0xff) << 24 | (buf[i++] & 0xff) << 16 | (buf[i++] & 0xff) << 8 | (buf[i++] & 0xff);
+					{{.TypeNative}} v = Float.intBitsToFloat(vx);
+ {{- else if eq .Type "float64"}}
+					long vx = (buf[i++] & 0xffL) << 56 | (buf[i++] & 0xffL) << 48 | (buf[i++] & 0xffL) << 40 | (buf[i++] & 0xffL) << 32
+						| (buf[i++] & 0xffL) << 24 | (buf[i++] & 0xffL) << 16 | (buf[i++] & 0xffL) << 8 | (buf[i++] & 0xffL);
+					{{.TypeNative}} v = Double.longBitsToDouble(vx);
+ {{- else if eq .Type "uint16" "uint32" "int32" "text" "binary"}}
+					int vx = 0;
+					for (int shift = 0; true; shift += 7) {
+						byte b = buf[i++];
+						vx |= (b & 0x7f) << shift;
+						if (shift == 28 || b >= 0) break;
+					}
+  {{- if eq .Type "text" "binary"}}
+					if (vx < 0 || vx > {{or .SizeMax "limits.sizeMax"}})
+						throw new SecurityException(format("colfer: {{.String}} value size %d exceeds %d {{if eq .Type "text"}}UTF-8 {{end}}bytes", vx, {{or .SizeMax "limits.sizeMax"}}));
+					int vStart = i;
+					i += vx;
+   {{- if eq .Type "text"}}
+					String v = new String(buf, vStart, vx, StandardCharsets.UTF_8);
+   {{- else}}
+					byte[] v = new byte[vx];
+					System.arraycopy(buf, vStart, v, 0, vx);
+   {{- end}}
+  {{- else}}
+					{{.TypeNative}} v = {{if eq .Type "int32"}}(vx >>> 1) ^ -(vx & 1){{else if eq .Type "uint16"}}(short) vx{{else}}vx{{end}};
+  {{- end}}
+ {{- else}}
+					{{.TypeNative}} v = new {{.TypeNative}}();
+					i = v.unmarshal(buf, i, end, {{template "limits-arg" .}});
+ {{- end}}
+					m.put(k, v);
+				}
+				this.{{.NameNative}} = m;
+				header = buf[i++];
+			}
+{{else if and .TypeList (eq .Type "bool" "uint8" "uint16" "uint32" "uint64" "int32" "int64")}}
+			if (header == (byte) {{.Index}}) {
+				int length = 0;
+				for (int shift = 0; true; shift += 7) {
+					byte b = buf[i++];
+					length |= (b & 0x7f) << shift;
+					if (shift == 28 || b >= 0) break;
+				}
+				if (length < 0 || length > {{or .ListMax "limits.listMax"}})
+					throw new SecurityException(format("colfer: {{.String}} length %d exceeds %d elements", length, {{or .ListMax "limits.listMax"}}));
+
+				{{.TypeNative}}[] a = new {{.TypeNative}}[length];
+ {{- if eq .Type "bool"}}
+				for (int ai = 0; ai < length; ai++) a[ai] = buf[i++] != 0;
+ {{- else if eq .Type "uint8"}}
+				int start = i;
+				i += length;
+				System.arraycopy(buf, start, a, 0, length);
+ {{- else if eq .Type "uint64" "int64"}}
+				for (int ai = 0; ai < length; ai++) {
+					long x = 0;
+					for (int shift = 0; true; shift += 7) {
+						byte b = buf[i++];
+						if (shift == 56 || b >= 0) {
+							x |= (b & 0xffL) << shift;
+							break;
+						}
+						x |= (b & 0x7fL) << shift;
+					}
+  {{- if eq .Type "int64"}}
+					a[ai] = (x >>> 1) ^ -(x & 1);
+  {{- else}}
+					a[ai] = x;
+  {{- end}}
+				}
+ {{- else}}
+				for (int ai = 0; ai < length; ai++) {
+					int x = 0;
+					for (int shift = 0; true; shift += 7) {
+						byte b = buf[i++];
+						x |= (b & 0x7f) << shift;
+						if (shift == 28 || b >= 0) break;
+					}
+  {{- if eq .Type "int32"}}
+					a[ai] = (x >>> 1) ^ -(x & 1);
+  {{- else if eq .Type "uint16"}}
+					a[ai] = (short) x;
+  {{- else}}
+					a[ai] = x;
+  {{- end}}
+				}
+ {{- end}}
+				this.{{.NameNative}} = a;
+				header = buf[i++];
+			}
+{{else if eq .Type "bool"}}
 			if (header == (byte) {{.Index}}) {
 				this.{{.NameNative}} = true;
 				header = buf[i++];
 			}
+ {{- if .Optional}} else if (header == (byte) ({{.Index}} | 0x80)) {
+				this.{{.NameNative}} = false;
+				header = buf[i++];
+			}
+ {{- end}}
 {{else if eq .Type "uint8"}}
 			if (header == (byte) {{.Index}}) {
 				this.{{.NameNative}} = buf[i++];
@@ -885,8 +1838,8 @@ import java.nio.BufferUnderflowException;
 					length |= (b & 0x7f) << shift;
 					if (shift == 28 || b >= 0) break;
 				}
-				if (length < 0 || length > {{$class}}.colferListMax)
-					throw new SecurityException(format("colfer: {{.String}} length %d exceeds %d elements", length, {{$class}}.colferListMax));
+				if (length < 0 || length > {{or .ListMax "limits.listMax"}})
+					throw new SecurityException(format("colfer: {{.String}} length %d exceeds %d elements", length, {{or .ListMax "limits.listMax"}}));
 
 				float[] a = new float[length];
 				for (int ai = 0; ai < length; ai++) {
@@ -909,8 +1862,8 @@ import java.nio.BufferUnderflowException;
 					length |= (b & 0x7f) << shift;
 					if (shift == 28 || b >= 0) break;
 				}
-				if (length < 0 || length > {{$class}}.colferListMax)
-					throw new SecurityException(format("colfer: {{.String}} length %d exceeds %d elements", length, {{$class}}.colferListMax));
+				if (length < 0 || length > {{or .ListMax "limits.listMax"}})
+					throw new SecurityException(format("colfer: {{.String}} length %d exceeds %d elements", length, {{or .ListMax "limits.listMax"}}));
 
 				double[] a = new double[length];
 				for (int ai = 0; ai < length; ai++) {
@@ -948,8 +1901,8 @@ import java.nio.BufferUnderflowException;
 					length |= (b & 0x7f) << shift;
 					if (shift == 28 || b >= 0) break;
 				}
-				if (length < 0 || length > {{$class}}.colferListMax)
-					throw new SecurityException(format("colfer: {{.String}} length %d exceeds %d elements", length, {{$class}}.colferListMax));
+				if (length < 0 || length > {{or .ListMax "limits.listMax"}})
+					throw new SecurityException(format("colfer: {{.String}} length %d exceeds %d elements", length, {{or .ListMax "limits.listMax"}}));
 
 				{{.TypeNative}}[] a = new {{.TypeNative}}[length];
 				for (int ai = 0; ai < length; ai++) {
@@ -959,8 +1912,8 @@ import java.nio.BufferUnderflowException;
 						size |= (b & 0x7f) << shift;
 						if (shift == 28 || b >= 0) break;
 					}
-					if (size < 0 || size > {{$class}}.colferSizeMax)
-						throw new SecurityException(format("colfer: {{.String}}[%d] size %d exceeds %d UTF-8 bytes", ai, size, {{$class}}.colferSizeMax));
+					if (size < 0 || size > {{or .SizeMax "limits.sizeMax"}})
+						throw new SecurityException(format("colfer: {{.String}}[%d] size %d exceeds %d UTF-8 bytes", ai, size, {{or .SizeMax "limits.sizeMax"}}));
 
 					int start = i;
 					i += size;
@@ -974,8 +1927,8 @@ import java.nio.BufferUnderflowException;
 					size |= (b & 0x7f) << shift;
 					if (shift == 28 || b >= 0) break;
 				}
-				if (size < 0 || size > {{$class}}.colferSizeMax)
-					throw new SecurityException(format("colfer: {{.String}} size %d exceeds %d UTF-8 bytes", size, {{$class}}.colferSizeMax));
+				if (size < 0 || size > {{or .SizeMax "limits.sizeMax"}})
+					throw new SecurityException(format("colfer: {{.String}} size %d exceeds %d UTF-8 bytes", size, {{or .SizeMax "limits.sizeMax"}}));
 
 				int start = i;
 				i += size;
@@ -992,8 +1945,8 @@ import java.nio.BufferUnderflowException;
 					length |= (b & 0x7f) << shift;
 					if (shift == 28 || b >= 0) break;
 				}
-				if (length < 0 || length > {{$class}}.colferListMax)
-					throw new SecurityException(format("colfer: {{.String}} length %d exceeds %d elements", length, {{$class}}.colferListMax));
+				if (length < 0 || length > {{or .ListMax "limits.listMax"}})
+					throw new SecurityException(format("colfer: {{.String}} length %d exceeds %d elements", length, {{or .ListMax "limits.listMax"}}));
 
 				byte[][] a = new byte[length][];
 				for (int ai = 0; ai < length; ai++) {
@@ -1003,8 +1956,8 @@ import java.nio.BufferUnderflowException;
 						size |= (b & 0x7f) << shift;
 						if (shift == 28 || b >= 0) break;
 					}
-					if (size < 0 || size > {{$class}}.colferSizeMax)
-						throw new SecurityException(format("colfer: {{.String}}[%d] size %d exceeds %d bytes", ai, size, {{$class}}.colferSizeMax));
+					if (size < 0 || size > {{or .SizeMax "limits.sizeMax"}})
+						throw new SecurityException(format("colfer: {{.String}}[%d] size %d exceeds %d bytes", ai, size, {{or .SizeMax "limits.sizeMax"}}));
 
 					byte[] e = new byte[size];
 					int start = i;
@@ -1024,8 +1977,8 @@ import java.nio.BufferUnderflowException;
 					size |= (b & 0x7f) << shift;
 					if (shift == 28 || b >= 0) break;
 				}
-				if (size < 0 || size > {{$class}}.colferSizeMax)
-					throw new SecurityException(format("colfer: {{.String}} size %d exceeds %d bytes", size, {{$class}}.colferSizeMax));
+				if (size < 0 || size > {{or .SizeMax "limits.sizeMax"}})
+					throw new SecurityException(format("colfer: {{.String}} size %d exceeds %d bytes", size, {{or .SizeMax "limits.sizeMax"}}));
 
 				this.{{.NameNative}} = new byte[size];
 				int start = i;
@@ -1043,31 +1996,57 @@ import java.nio.BufferUnderflowException;
 					length |= (b & 0x7f) << shift;
 					if (shift == 28 || b >= 0) break;
 				}
-				if (length < 0 || length > {{$class}}.colferListMax)
-					throw new SecurityException(format("colfer: {{.String}} length %d exceeds %d elements", length, {{$class}}.colferListMax));
+				if (length < 0 || length > {{or .ListMax "limits.listMax"}})
+					throw new SecurityException(format("colfer: {{.String}} length %d exceeds %d elements", length, {{or .ListMax "limits.listMax"}}));
 
 				{{.TypeNative}}[] a = new {{.TypeNative}}[length];
 				for (int ai = 0; ai < length; ai++) {
 					{{.TypeNative}} o = new {{.TypeNative}}();
-					i = o.unmarshal(buf, i, end);
+					i = o.unmarshal(buf, i, end, {{template "limits-arg" .}});
 					a[ai] = o;
 				}
 				this.{{.NameNative}} = a;
 				header = buf[i++];
 			}
+{{else if .TypeUnion}}
+ {{- $f := .}}{{$pkg := ""}}{{if ne .TypeNative .TypeUnion.NameTitle}}{{$pkg = print .TypeUnion.Pkg.NameNative "."}}{{end}}
+			if (header == (byte) {{.Index}}) {
+				switch (buf[i++]) {
+ {{- range .TypeUnion.Members}}
+				case {{.Index}}: {
+					{{$pkg}}{{.Struct.NameTitle}} o = new {{$pkg}}{{.Struct.NameTitle}}();
+					i = o.unmarshal(buf, i, end, {{if $pkg}}new {{$pkg}}ColferLimits(nested.sizeMax, nested.listMax, nested.depthMax){{else}}nested{{end}});
+					this.{{$f.NameNative}} = o;
+					break;
+				}
+ {{- end}}
+				default:
+					throw new InputMismatchException(format("colfer: unknown {{.String}} member at byte %d", i - 1));
+				}
+				header = buf[i++];
+			}
 {{else}}
 			if (header == (byte) {{.Index}}) {
 				this.{{.NameNative}} = new {{.TypeNative}}();
-				i = this.{{.NameNative}}.unmarshal(buf, i, end);
+				i = this.{{.NameNative}}.unmarshal(buf, i, end, {{template "limits-arg" .}});
 				header = buf[i++];
 			}
 {{end}}{{end}}
+{{- if .Pkg.KeepUnknown}}
+			if (header != (byte) 0x7f) {
+				if (! serial || i > end{{if .Fields}} || (header & 0x7f) <= {{.IndexMax}}{{end}} || buf[end - 1] != (byte) 0x7f)
+					throw new InputMismatchException(format("colfer: unknown header at byte %d", i - 1));
+				this.colferUnknown = java.util.Arrays.copyOfRange(buf, i - 1, end - 1);
+				i = end;
+			}
+{{- else}}
 			if (header != (byte) 0x7f)
 				throw new InputMismatchException(format("colfer: unknown header at byte %d", i - 1));
+{{- end}}
 		} finally {
-			if (i > end && end - offset < {{$class}}.colferSizeMax) throw new BufferUnderflowException();
-			if (i < 0 || i - offset > {{$class}}.colferSizeMax)
-				throw new SecurityException(format("colfer: {{.String}} exceeds %d bytes", {{$class}}.colferSizeMax));
+			if (i > end && end - offset < limits.sizeMax) throw new BufferUnderflowException();
+			if (i < 0 || i - offset > limits.sizeMax)
+				throw new SecurityException(format("colfer: {{.String}} exceeds %d bytes", limits.sizeMax));
 			if (i > end) throw new BufferUnderflowException();
 		}
 
@@ -1100,19 +2079,20 @@ import java.nio.BufferUnderflowException;
 		int n = in.readInt();
 		byte[] buf = new byte[n];
 		in.readFully(buf);
-		unmarshal(buf, 0);
+		{{if .Pkg.KeepUnknown}}unmarshalSerial(buf, 0, n){{else}}unmarshal(buf, 0){{end}};
 	}
 
 	// {@link Serializable} Colfer extension.
 	private void readObjectNoData() throws ObjectStreamException {
 		init();
 	}
+{{template "json" .}}
 {{range .Fields}}
 	/**
 	 * Gets {{.String}}.
 	 * @return the value.
 	 */
-	public {{.TypeNative}}{{if .TypeList}}[]{{end}} get{{.NameTitle}}() {
+	public {{if .TypeKey}}java.util.Map<{{.TypeKeyNative}}, {{.TypeNative}}>{{else}}{{.TypeNative}}{{if .TypeList}}[]{{end}}{{end}} get{{.NameTitle}}() {
 		return this.{{.NameNative}};
 	}
 
@@ -1120,7 +2100,7 @@ import java.nio.BufferUnderflowException;
 	 * Sets {{.String}}.
 	 * @param value the replacement.
 	 */
-	public void set{{.NameTitle}}({{.TypeNative}}{{if .TypeList}}[]{{end}} value) {
+	public void set{{.NameTitle}}({{if .TypeKey}}java.util.Map<{{.TypeKeyNative}}, {{.TypeNative}}>{{else}}{{.TypeNative}}{{if .TypeList}}[]{{end}}{{end}} value) {
 		this.{{.NameNative}} = value;
 	}
 
@@ -1129,16 +2109,37 @@ import java.nio.BufferUnderflowException;
 	 * @param value the replacement.
 	 * @return {@code this}.
 	 */
-	public {{$class}} with{{.NameTitle}}({{.TypeNative}}{{if .TypeList}}[]{{end}} value) {
+	public {{$class}} with{{.NameTitle}}({{if .TypeKey}}java.util.Map<{{.TypeKeyNative}}, {{.TypeNative}}>{{else}}{{.TypeNative}}{{if .TypeList}}[]{{end}}{{end}} value) {
 		this.{{.NameNative}} = value;
 		return this;
 	}
+{{end}}
+{{- range .Unions}}
+	@Override
+	public <R> R accept({{.NameTitle}}.Visitor<R> v) {
+		return v.visit(this);
+	}
 {{end}}
 	@Override
 	public final int hashCode() {
 		int h = 1;
 {{- range .Fields}}
-{{- if eq .Type "bool"}}
+{{- if .TypeKey}}
+ {{- if eq .Type "binary"}}
+		if (this.{{.NameNative}} != null) {
+			int mh = 0;
+			for (java.util.Map.Entry<{{.TypeKeyNative}}, byte[]> e : this.{{.NameNative}}.entrySet())
+				mh += java.util.Objects.hashCode(e.getKey()) ^ java.util.Arrays.hashCode(e.getValue());
+			h = 31 * h + mh;
+		}
+ {{- else}}
+		if (this.{{.NameNative}} != null) h = 31 * h + this.{{.NameNative}}.hashCode();
+ {{- end}}
+{{- else if and .TypeList (eq .Type "bool" "uint8" "uint16" "uint32" "uint64" "int32" "int64")}}
+		h = 31 * h + java.util.Arrays.hashCode(this.{{.NameNative}});
+{{- else if .Optional}}
+		if (this.{{.NameNative}} != null) h = 31 * h + this.{{.NameNative}}.hashCode();
+{{- else if eq .Type "bool"}}
 		h = 31 * h + (this.{{.NameNative}} ? 1231 : 1237);
 {{- else if eq .Type "uint8"}}
 		h = 31 * h + (this.{{.NameNative}} & 0xff);
@@ -1172,6 +2173,9 @@ import java.nio.BufferUnderflowException;
 {{- else}}
 		if (this.{{.NameNative}} != null) h = 31 * h + this.{{.NameNative}}.hashCode();
 {{- end}}{{end}}
+{{- if .Pkg.KeepUnknown}}
+		h = 31 * h + java.util.Arrays.hashCode(this.colferUnknown);
+{{- end}}
 		return h;
 	}
 
@@ -1185,12 +2189,20 @@ import java.nio.BufferUnderflowException;
 		if (o == this) return true;
 		return o.getClass() == {{$class}}.class
 {{- range .Fields}}
-{{- if .TypeList}}
+{{- if .TypeKey}}
+ {{- if eq .Type "binary"}}
+			&& _equals(this.{{.NameNative}}, o.{{.NameNative}})
+ {{- else}}
+			&& (this.{{.NameNative}} == null ? o.{{.NameNative}} == null : this.{{.NameNative}}.equals(o.{{.NameNative}}))
+ {{- end}}
+{{- else if .TypeList}}
  {{- if eq .Type "binary"}}
 			&& _equals(this.{{.NameNative}}, o.{{.NameNative}})
  {{- else}}
 			&& java.util.Arrays.equals(this.{{.NameNative}}, o.{{.NameNative}})
  {{- end}}
+{{- else if .Optional}}
+			&& java.util.Objects.equals(this.{{.NameNative}}, o.{{.NameNative}})
 {{- else if eq .Type "bool" "uint8" "uint16" "uint32" "uint64" "int32" "int64"}}
 			&& this.{{.NameNative}} == o.{{.NameNative}}
 {{- else if eq .Type "float32" "float64"}}
@@ -1199,7 +2211,10 @@ import java.nio.BufferUnderflowException;
 			&& java.util.Arrays.equals(this.{{.NameNative}}, o.{{.NameNative}})
 {{- else}}
 			&& (this.{{.NameNative}} == null ? o.{{.NameNative}} == null : this.{{.NameNative}}.equals(o.{{.NameNative}}))
-{{- end}}{{end}};
+{{- end}}{{end}}
+{{- if .Pkg.KeepUnknown}}
+			&& java.util.Arrays.equals(this.colferUnknown, o.colferUnknown)
+{{- end}};
 	}
 {{if .HasBinaryList}}
 	private static boolean _equals(byte[][] a, byte[][] b) {
@@ -1213,5 +2228,715 @@ import java.nio.BufferUnderflowException;
 		return true;
 	}
 {{end}}
+{{- if .HasBinaryMap}}
+	private static boolean _equals(java.util.Map<?, byte[]> a, java.util.Map<?, byte[]> b) {
+		if (a == b) return true;
+		if (a == null || b == null || a.size() != b.size()) return false;
+
+		for (java.util.Map.Entry<?, byte[]> e : a.entrySet()) {
+			Object k = e.getKey();
+			if (! b.containsKey(k) || ! java.util.Arrays.equals(e.getValue(), b.get(k))) return false;
+		}
+		return true;
+	}
+{{end}}
+{{- if .HasMap}}
+	// Compares by UTF-8 byte order, which differs from the UTF-16 order of
+	// String.compareTo for the code points beyond U+FFFF.
+	private static int _compareUTF8(String a, String b) {
+		for (int i = 0, n = Math.min(a.length(), b.length()); i < n; i++) {
+			int ca = a.charAt(i), cb = b.charAt(i);
+			if (ca == cb) continue;
+			if (ca >= 0xd800 && cb >= 0xd800) {
+				// surrogates [U+D800, U+DFFF] sort after [U+E000, U+FFFF]
+				ca += ca < 0xe000 ? 0x2000 : -0x800;
+				cb += cb < 0xe000 ? 0x2000 : -0x800;
+			}
+			return ca - cb;
+		}
+		return a.length() - b.length();
+	}
+{{end}}
+}
+`
+
+const javaJSONHelper = `package {{.NameNative}};
+
+
+// Code generated by colf(1); DO NOT EDIT.
+// The compiler used schema file {{.SchemaFileList}}.
+
+
+import static java.lang.String.format;
+import java.math.BigDecimal;
+import java.math.MathContext;
+import java.math.RoundingMode;
+import java.time.Instant;
+import java.time.LocalDateTime;
+import java.time.ZoneOffset;
+import java.util.regex.Matcher;
+import java.util.regex.Pattern;
+
+
+/**
+ * JSON mapping of the data beans in package {{.Name}}. Parsed values are
+ * either a String, a BigDecimal, a Boolean, a List, a Map or {@code null}.
+ * Negative zero parses as a Double to retain its sign.
+ * @author generated by colf(1)
+ * @see <a href="https://github.com/pascaldekloe/colfer">Colfer's home</a>
+ */
+final class ColferJSON {
+
+	private ColferJSON() {}
+
+	private static final String HEX = "0123456789abcdef";
+
+	/**
+	 * Appends a string with quotation marks, backslashes, control characters
+	 * and unpaired surrogates escaped, like ECMAScript's JSON.stringify.
+	 */
+	static StringBuilder appendString(StringBuilder buf, String s) {
+		buf.append('"');
+		for (int i = 0; i < s.length(); i++) {
+			char c = s.charAt(i);
+			switch (c) {
+			case '"':
+				buf.append("\\\"");
+				break;
+			case '\\':
+				buf.append("\\\\");
+				break;
+			case '\b':
+				buf.append("\\b");
+				break;
+			case '\f':
+				buf.append("\\f");
+				break;
+			case '\n':
+				buf.append("\\n");
+				break;
+			case '\r':
+				buf.append("\\r");
+				break;
+			case '\t':
+				buf.append("\\t");
+				break;
+			default:
+				boolean unpaired = Character.isHighSurrogate(c) ?
+					i + 1 >= s.length() || ! Character.isLowSurrogate(s.charAt(i + 1)) :
+					Character.isLowSurrogate(c) && (i == 0 || ! Character.isHighSurrogate(s.charAt(i - 1)));
+				if (c < ' ' || unpaired) {
+					buf.append("\\u");
+					buf.append(HEX.charAt(c >> 12)).append(HEX.charAt(c >> 8 & 15));
+					buf.append(HEX.charAt(c >> 4 & 15)).append(HEX.charAt(c & 15));
+				} else {
+					buf.append(c);
+				}
+			}
+		}
+		return buf.append('"');
+	}
+
+	/**
+	 * Appends a 64-bit integer as a JSON number, or as a JSON string when
+	 * it exceeds the safe integer range of ECMAScript, which is 2^53 - 1 at
+	 * most in magnitude.
+	 */
+	static StringBuilder appendLong(StringBuilder buf, long v, boolean unsigned) {
+		final long safeMax = (1L << 53) - 1;
+		if (unsigned ? v >= 0 && v <= safeMax : v >= -safeMax && v <= safeMax)
+			return buf.append(v);
+		buf.append('"');
+		if (unsigned) buf.append(Long.toUnsignedString(v));
+		else buf.append(v);
+		return buf.append('"');
+	}
+
+	/** Gets the JSON string of s. */
+	static String quote(String s) {
+		return appendString(new StringBuilder(), s).toString();
+	}
+
+	/**
+	 * Appends the shortest decimal which reads back bit-exactly, in the
+	 * notation of ECMAScript's Number toString. NaN and the infinities are
+	 * written as a string, since JSON has no notation for them.
+	 */
+	static StringBuilder appendNumber(StringBuilder buf, double f, boolean float32) {
+		if (Double.isNaN(f)) return buf.append("\"NaN\"");
+		if (Double.isInfinite(f)) return buf.append(f > 0 ? "\"Infinity\"" : "\"-Infinity\"");
+		if (f == 0) return buf.append(1 / f < 0 ? "-0" : "0");
+
+		BigDecimal d = shortest(f, float32);
+		String digits = d.unscaledValue().abs().toString();
+		int k = digits.length();
+		// decimal point position relative to the digits
+		int n = k - d.scale();
+
+		if (f < 0) buf.append('-');
+		if (n > 21 || n <= -6) {
+			buf.append(digits.charAt(0));
+			if (k > 1) buf.append('.').append(digits, 1, k);
+			return buf.append('e').append(n > 0 ? '+' : '-').append(Math.abs(n - 1));
+		}
+		if (n <= 0) {
+			buf.append("0.");
+			for (; n < 0; n++) buf.append('0');
+			return buf.append(digits);
+		}
+		if (n < k) return buf.append(digits, 0, n).append('.').append(digits, n, k);
+		buf.append(digits);
+		for (; n > k; n--) buf.append('0');
+		return buf;
+	}
+
+	private static BigDecimal shortest(double f, boolean float32) {
+		BigDecimal exact = new BigDecimal(f);
+		for (int p = 1; ; p++) {
+			BigDecimal d = exact.round(new MathContext(p, RoundingMode.HALF_EVEN));
+			// the nearest first, and then its neighbours for the
+			// asymmetric rounding interval at powers of two
+			BigDecimal[] candidates = {d, d.subtract(d.ulp()), d.add(d.ulp())};
+			for (BigDecimal c : candidates) {
+				String s = c.toString();
+				if (float32 ? Float.parseFloat(s) == (float) f : Double.parseDouble(s) == f)
+					return c.stripTrailingZeros();
+			}
+		}
+	}
+
+	/**
+	 * Appends RFC 3339 in UTC, with a fraction only when non-zero, without
+	 * trailing zeros. Negative years get a minus sign, as in Go.
+	 */
+	static StringBuilder appendTimestamp(StringBuilder buf, Instant t) {
+		LocalDateTime d = LocalDateTime.ofEpochSecond(t.getEpochSecond(), 0, ZoneOffset.UTC);
+		buf.append('"');
+		int year = d.getYear();
+		if (year < 0) {
+			buf.append('-');
+			year = -year;
+		}
+		appendPadded(buf, year, 4).append('-');
+		appendPadded(buf, d.getMonthValue(), 2).append('-');
+		appendPadded(buf, d.getDayOfMonth(), 2).append('T');
+		appendPadded(buf, d.getHour(), 2).append(':');
+		appendPadded(buf, d.getMinute(), 2).append(':');
+		appendPadded(buf, d.getSecond(), 2);
+
+		int ns = t.getNano();
+		if (ns != 0) {
+			int width = 9;
+			for (; ns % 10 == 0; ns /= 10) width--;
+			appendPadded(buf.append('.'), ns, width);
+		}
+		return buf.append("Z\"");
+	}
+
+	private static StringBuilder appendPadded(StringBuilder buf, int x, int width) {
+		String s = Integer.toString(x);
+		for (int i = s.length(); i < width; i++) buf.append('0');
+		return buf.append(s);
+	}
+
+	/** Appends standard base64 with padding. */
+	static StringBuilder appendBinary(StringBuilder buf, byte[] b) {
+		if (b == null) return buf.append("\"\"");
+		return buf.append('"').append(java.util.Base64.getEncoder().encodeToString(b)).append('"');
+	}
+
+	/**
+	 * Parses a JSON text.
+	 * @throws IllegalArgumentException on malformed content.
+	 */
+	static Object parse(String json) {
+		Parser p = new Parser(json);
+		Object v = p.value();
+		p.skipSpace();
+		if (p.i < json.length()) throw p.error("data after top-level value");
+		return v;
+	}
+
+	private static final class Parser {
+
+		private static final Pattern NUMBER = Pattern.compile("-?(0|[1-9][0-9]*)(\\.[0-9]+)?([eE][+-]?[0-9]+)?");
+
+		final String s;
+		int i;
+
+		Parser(String s) {
+			this.s = s;
+		}
+
+		IllegalArgumentException error(String msg) {
+			return new IllegalArgumentException(format("colfer: JSON %s at character %d", msg, i));
+		}
+
+		void skipSpace() {
+			while (i < s.length() && " \t\n\r".indexOf(s.charAt(i)) >= 0) i++;
+		}
+
+		char peek() {
+			skipSpace();
+			if (i >= s.length()) throw error("end");
+			return s.charAt(i);
+		}
+
+		void expect(char c) {
+			if (peek() != c) throw error("unexpected character");
+			i++;
+		}
+
+		void literal(String word) {
+			if (! s.startsWith(word, i)) throw error("unexpected character");
+			i += word.length();
+		}
+
+		Object value() {
+			switch (peek()) {
+			case '{':
+				i++;
+				java.util.Map<String, Object> object = new java.util.LinkedHashMap<>();
+				if (peek() == '}') {
+					i++;
+					return object;
+				}
+				while (true) {
+					if (peek() != '"') throw error("unexpected character");
+					String name = string();
+					expect(':');
+					object.put(name, value());
+					if (peek() != ',') break;
+					i++;
+				}
+				expect('}');
+				return object;
+			case '[':
+				i++;
+				java.util.List<Object> array = new java.util.ArrayList<>();
+				if (peek() == ']') {
+					i++;
+					return array;
+				}
+				while (true) {
+					array.add(value());
+					if (peek() != ',') break;
+					i++;
+				}
+				expect(']');
+				return array;
+			case '"':
+				return string();
+			case 't':
+				literal("true");
+				return Boolean.TRUE;
+			case 'f':
+				literal("false");
+				return Boolean.FALSE;
+			case 'n':
+				literal("null");
+				return null;
+			default:
+				return number();
+			}
+		}
+
+		String string() {
+			StringBuilder buf = new StringBuilder();
+			for (i++; i < s.length(); ) {
+				char c = s.charAt(i++);
+				if (c == '"') return buf.toString();
+				if (c < ' ') throw error("control character in string");
+				if (c != '\\') {
+					buf.append(c);
+					continue;
+				}
+
+				if (i >= s.length()) break;
+				c = s.charAt(i++);
+				switch (c) {
+				case '"':
+				case '\\':
+				case '/':
+					buf.append(c);
+					break;
+				case 'b':
+					buf.append('\b');
+					break;
+				case 'f':
+					buf.append('\f');
+					break;
+				case 'n':
+					buf.append('\n');
+					break;
+				case 'r':
+					buf.append('\r');
+					break;
+				case 't':
+					buf.append('\t');
+					break;
+				case 'u':
+					int x = 0;
+					for (int end = i + 4; i < end; i++) {
+						int d = i < s.length() ? Character.digit(s.charAt(i), 16) : -1;
+						if (d < 0) throw error("malformed escape");
+						x = x << 4 | d;
+					}
+					buf.append((char) x);
+					break;
+				default:
+					throw error("malformed escape");
+				}
+			}
+			throw error("end in string");
+		}
+
+		Object number() {
+			Matcher m = NUMBER.matcher(s);
+			m.region(i, s.length());
+			if (! m.lookingAt()) throw error("unexpected character");
+			String n = m.group();
+			i = m.end();
+			// retain the sign of negative zero
+			if (n.startsWith("-") && n.matches("-[0.]*([eE].*)?")) return -0.0;
+			try {
+				return new BigDecimal(n);
+			} catch (NumberFormatException e) {
+				throw error("number out of range");
+			}
+		}
+
+	}
+
+	@SuppressWarnings("unchecked")
+	static java.util.Map<String, Object> object(Object x, String what) {
+		if (x == null) return java.util.Collections.emptyMap();
+		if (x instanceof java.util.Map) return (java.util.Map<String, Object>) x;
+		throw new IllegalArgumentException(format("colfer: %s needs a JSON object", what));
+	}
+
+	static java.util.List<?> array(Object x, String what) {
+		if (x instanceof java.util.List) return (java.util.List<?>) x;
+		throw new IllegalArgumentException(format("colfer: %s needs a JSON array", what));
+	}
+
+	static boolean bool(Object x, String what) {
+		if (x instanceof Boolean) return (Boolean) x;
+		throw new IllegalArgumentException(format("colfer: %s needs a JSON boolean", what));
+	}
+
+	/** Gets an integer in range [min, max], from a number or a decimal string. */
+	static long integer(Object x, long min, long max, String what) {
+		if (x instanceof String && ((String) x).matches("-?[0-9]+")) x = new BigDecimal((String) x);
+		if (x instanceof Double && (Double) x == 0) return 0;
+		if (! (x instanceof BigDecimal))
+			throw new IllegalArgumentException(format("colfer: %s needs a JSON number", what));
+		try {
+			long v = ((BigDecimal) x).longValueExact();
+			if (v >= min && v <= max) return v;
+		} catch (ArithmeticException e) {
+		}
+		throw new IllegalArgumentException(format("colfer: %s value %s out of range", what, x));
+	}
+
+	/**
+	 * Gets an unsigned 64-bit integer in its signed representation, from a
+	 * number or a decimal string.
+	 */
+	static long unsigned64(Object x, String what) {
+		if (x instanceof String && ((String) x).matches("-?[0-9]+")) x = new BigDecimal((String) x);
+		if (x instanceof Double && (Double) x == 0) return 0;
+		if (! (x instanceof BigDecimal))
+			throw new IllegalArgumentException(format("colfer: %s needs a JSON number", what));
+		BigDecimal d = (BigDecimal) x;
+		if (d.signum() >= 0 && d.precision() - d.scale() <= 20) try {
+			java.math.BigInteger v = d.toBigIntegerExact();
+			if (v.bitLength() <= 64) return v.longValue();
+		} catch (ArithmeticException e) {
+		}
+		throw new IllegalArgumentException(format("colfer: %s value %s out of range", what, x));
+	}
+
+	/** Gets an integer map key as a number. */
+	static BigDecimal key(String s, String what) {
+		if (s.matches("-?[0-9]+")) return new BigDecimal(s);
+		throw new IllegalArgumentException(format("colfer: %s key %s is not an integer", what, quote(s)));
+	}
+
+	static double number(Object x, boolean float32, String what) {
+		if (x instanceof Double) return (Double) x;
+		if (x instanceof BigDecimal) {
+			String s = x.toString();
+			return float32 ? Float.parseFloat(s) : Double.parseDouble(s);
+		}
+		if ("NaN".equals(x)) return Double.NaN;
+		if ("Infinity".equals(x)) return Double.POSITIVE_INFINITY;
+		if ("-Infinity".equals(x)) return Double.NEGATIVE_INFINITY;
+		throw new IllegalArgumentException(format("colfer: %s needs a JSON number", what));
+	}
+
+	private static final Pattern TIMESTAMP = Pattern.compile("(-?[0-9]{4,})-([0-9]{2})-([0-9]{2})T([0-9]{2}):([0-9]{2}):([0-9]{2})(?:\\.([0-9]{1,9}))?(?:Z|([+-])([0-9]{2}):([0-9]{2}))");
+
+	/** Gets an RFC 3339 timestamp. */
+	static Instant timestamp(Object x, String what) {
+		Matcher m = TIMESTAMP.matcher(x instanceof String ? (String) x : "");
+		if (! m.matches())
+			throw new IllegalArgumentException(format("colfer: %s needs an RFC 3339 timestamp", what));
+
+		long s;
+		try {
+			s = LocalDateTime.of(Integer.parseInt(m.group(1)), Integer.parseInt(m.group(2)), Integer.parseInt(m.group(3)),
+				Integer.parseInt(m.group(4)), Integer.parseInt(m.group(5)), Integer.parseInt(m.group(6)))
+				.toEpochSecond(ZoneOffset.UTC);
+		} catch (java.time.DateTimeException | NumberFormatException e) {
+			throw new IllegalArgumentException(format("colfer: %s timestamp %s out of range", what, x), e);
+		}
+		if (m.group(8) != null) {
+			int offset = (Integer.parseInt(m.group(9)) * 60 + Integer.parseInt(m.group(10))) * 60;
+			s += m.group(8).equals("-") ? offset : -offset;
+		}
+		int ns = 0;
+		if (m.group(7) != null) ns = Integer.parseInt((m.group(7) + "00000000").substring(0, 9));
+		return Instant.ofEpochSecond(s, ns);
+	}
+
+	static String string(Object x, String what) {
+		if (x == null) return "";
+		if (x instanceof String) return (String) x;
+		throw new IllegalArgumentException(format("colfer: %s needs a JSON string", what));
+	}
+
+	/** Gets standard base64. */
+	static byte[] binary(Object x, String what) {
+		if (x == null) return new byte[0];
+		if (x instanceof String) try {
+			return java.util.Base64.getDecoder().decode((String) x);
+		} catch (IllegalArgumentException e) {
+			throw new IllegalArgumentException(format("colfer: %s needs standard base64", what), e);
+		}
+		throw new IllegalArgumentException(format("colfer: %s needs a JSON string", what));
+	}
+
 }
 `
+
+const javaJSON = `
+	/**
+	 * Serializes the object as JSON. The JSON object has the fields present
+	 * in the serial only, in order of appearance, with the names from the
+	 * schema. Integers beyond 2^53 - 1 in magnitude are written as a JSON
+	 * string, as JavaScript can not hold them exactly. The mapping matches
+	 * the generated Go and JavaScript code.
+{{- if .Pkg.KeepUnknown}}
+	 * Any {@link #colferUnknown} content is not included.
+{{- end}}
+	 * @return the JSON text.
+	 * @throws IllegalStateException when a union holds no member, or when a map has a {@code null} key.
+	 */
+	public String marshalJSON() {
+		return marshalJSON(new StringBuilder()).toString();
+	}
+
+	/**
+	 * Serializes the object as JSON, conform {@link #marshalJSON()}.
+	 * @param buf the data destination.
+	 * @return {@code buf}.
+	 * @throws IllegalStateException when a union holds no member, or when a map has a {@code null} key.
+	 */
+	public StringBuilder marshalJSON(StringBuilder buf) {
+		// each field starts with a comma, of which the first one becomes
+		// the opening brace
+		int start = buf.length();
+{{range .Fields}}{{if .TypeKey}}
+		if (! this.{{.NameNative}}.isEmpty()) {
+			buf.append(",\"{{.Name}}\":{");
+			{{.TypeKeyNative}}[] keys = this.{{.NameNative}}.keySet().toArray(new {{.TypeKeyNative}}[0]);
+			for ({{.TypeKeyNative}} k : keys)
+				if (k == null) throw new IllegalStateException("colfer: {{.String}} has a null key");
+			java.util.Arrays.sort(keys, _order{{.NameTitle}});
+			for (int i = 0; i < keys.length; i++) {
+				if (i != 0) buf.append(',');
+ {{- if eq .TypeKey "text"}}
+				ColferJSON.appendString(buf, keys[i]).append(':');
+ {{- else}}
+				buf.append('"').append({{if eq .TypeKey "uint8"}}keys[i] & 0xff{{else if eq .TypeKey "uint16"}}keys[i] & 0xffff{{else if eq .TypeKey "uint32"}}Integer.toUnsignedString(keys[i]){{else if eq .TypeKey "uint64"}}Long.toUnsignedString(keys[i]){{else}}keys[i]{{end}}).append("\":");
+ {{- end}}
+				{{.TypeNative}} v = this.{{.NameNative}}.get(keys[i]);
+ {{- if not (or .TypeRef (eq .Type "text" "binary"))}}
+				if (v == null) v = {{if eq .Type "bool"}}false{{else if eq .Type "uint8"}}(byte) 0{{else if eq .Type "uint16"}}(short) 0{{else if eq .Type "uint32" "int32"}}0{{else if eq .Type "uint64" "int64"}}0L{{else if eq .Type "float32"}}0f{{else}}0.0{{end}};
+ {{- end}}
+{{template "json-value" .}}
+			}
+			buf.append('}');
+		}
+{{else if .TypeList}}
+		if (this.{{.NameNative}}.length != 0) {
+			buf.append(",\"{{.Name}}\":[");
+			for (int i = 0; i < this.{{.NameNative}}.length; i++) {
+				if (i != 0) buf.append(',');
+				{{.TypeNative}} v = this.{{.NameNative}}[i];
+{{template "json-value" .}}
+			}
+			buf.append(']');
+		}
+{{else if .TypeUnion}}
+ {{- $f := .}}{{$pkg := ""}}{{if ne .TypeNative .TypeUnion.NameTitle}}{{$pkg = print .TypeUnion.Pkg.NameNative "."}}{{end}}
+		if (this.{{.NameNative}} != null) {
+			buf.append(",\"{{.Name}}\":{\"kind\":");
+ {{- range .TypeUnion.Members}}
+			{{if .Index}}} else {{end}}if (this.{{$f.NameNative}} instanceof {{$pkg}}{{.Struct.NameTitle}}) {
+				buf.append("\"{{.Name}}\",\"value\":");
+				(({{$pkg}}{{.Struct.NameTitle}}) this.{{$f.NameNative}}).marshalJSON(buf);
+ {{- end}}
+			} else {
+				throw new IllegalStateException(format("colfer: {{.String}} type %s is not a member", this.{{.NameNative}}.getClass().getName()));
+			}
+			buf.append('}');
+		}
+{{else}}
+		if ({{if .TypeRef}}this.{{.NameNative}} != null
+ {{- else if .Optional}}this.{{.NameNative}} != null
+ {{- else if eq .Type "bool"}}this.{{.NameNative}}
+ {{- else if eq .Type "timestamp"}}this.{{.NameNative}} != null && (this.{{.NameNative}}.getEpochSecond() != 0 || this.{{.NameNative}}.getNano() != 0)
+ {{- else if eq .Type "text"}}! this.{{.NameNative}}.isEmpty()
+ {{- else if eq .Type "binary"}}this.{{.NameNative}}.length != 0
+ {{- else if eq .Type "float32"}}this.{{.NameNative}} != 0.0f
+ {{- else if eq .Type "float64"}}this.{{.NameNative}} != 0.0
+ {{- else}}this.{{.NameNative}} != 0{{end}}) {
+			buf.append(",\"{{.Name}}\":");
+			{{.TypeNative}} v = this.{{.NameNative}};
+{{template "json-field-value" .}}
+		}
+{{end}}{{end}}
+		if (buf.length() == start) buf.append('{');
+		else buf.setCharAt(start, '{');
+		return buf.append('}');
+	}
+
+	/**
+	 * Deserializes the object from JSON, with the mapping of {@link #marshalJSON()}.
+	 * Fields absent in the JSON object, or with a {@code null} value, remain as is.
+	 * @param json the JSON text.
+	 * @return {@code this}.
+	 * @throws IllegalArgumentException when the JSON does not match this object's schema.
+	 */
+	public {{$.NameTitle}} unmarshalJSON(String json) {
+		return unmarshalJSON(ColferJSON.object(ColferJSON.parse(json), "{{.String}}"));
+	}
+
+	/**
+	 * Deserializes the object from a parsed JSON object, conform {@link #unmarshalJSON(String)}.
+	 * @param object the fields with a String, a java.math.BigDecimal, a Boolean,
+	 *  a java.util.List, a java.util.Map or {@code null} as value.
+	 * @return {@code this}.
+	 * @throws IllegalArgumentException when the JSON does not match this object's schema.
+	 */
+	public {{$.NameTitle}} unmarshalJSON(java.util.Map<String, ?> object) {
+		for (java.util.Map.Entry<String, ?> field : object.entrySet()) {
+			Object x = field.getValue();
+			if (x == null) continue;
+
+			switch (field.getKey()) {
+{{- range .Fields}}
+			case "{{.Name}}": {
+{{- if .TypeKey}}
+				java.util.Map<{{.TypeKeyNative}}, {{.TypeNative}}> m = new java.util.HashMap<>();
+				for (java.util.Map.Entry<String, Object> e : ColferJSON.object(x, "{{.String}}").entrySet())
+ {{- if eq .TypeKey "text"}}
+					m.put(e.getKey(), _fromJSON{{.NameTitle}}(e.getValue()));
+ {{- else}}
+					m.put({{if eq .TypeKey "uint64"}}ColferJSON.unsigned64(ColferJSON.key(e.getKey(), "{{.String}}"), "{{.String}}")
+						{{- else}}{{template "json-integer-cast" .TypeKey}}ColferJSON.integer(ColferJSON.key(e.getKey(), "{{.String}}"), {{template "json-integer-range" .TypeKey}}, "{{.String}}"){{end}}, _fromJSON{{.NameTitle}}(e.getValue()));
+ {{- end}}
+				this.{{.NameNative}} = m;
+{{- else if .TypeList}}
+				java.util.List<?> a = ColferJSON.array(x, "{{.String}}");
+				{{.TypeNative}}[] l = new {{if eq .Type "binary"}}byte[a.size()][]{{else}}{{.TypeNative}}[a.size()]{{end}};
+				for (int i = 0; i < l.length; i++) l[i] = _fromJSON{{.NameTitle}}(a.get(i));
+				this.{{.NameNative}} = l;
+{{- else if .TypeUnion}}{{$f := .}}{{$pkg := ""}}{{if ne .TypeNative .TypeUnion.NameTitle}}{{$pkg = print .TypeUnion.Pkg.NameNative "."}}{{end}}
+				java.util.Map<String, Object> u = ColferJSON.object(x, "{{.String}}");
+				Object kind = u.get("kind");
+				java.util.Map<String, Object> value = ColferJSON.object(u.get("value"), "{{.String}}");
+ {{- range .TypeUnion.Members}}
+				{{if .Index}}else {{end}}if ("{{.Name}}".equals(kind)) this.{{$f.NameNative}} = new {{$pkg}}{{.Struct.NameTitle}}().unmarshalJSON(value);
+ {{- end}}
+				else throw new IllegalArgumentException(format("colfer: {{.String}} kind %s is not a member of union {{.TypeUnion}}", kind));
+{{- else}}
+				this.{{.NameNative}} = _fromJSON{{.NameTitle}}(x);
+{{- end}}
+				break;
+			}
+{{- end}}
+			default:
+				throw new IllegalArgumentException(format("colfer: struct {{.String}} has no field %s", ColferJSON.quote(field.getKey())));
+			}
+		}
+		return this;
+	}
+{{range .Fields}}{{if not .TypeUnion}}
+	// Gets a {{.String}} value, or element, from JSON.
+	private static {{.TypeNative}} _fromJSON{{.NameTitle}}(Object x) {
+{{- if .TypeRef}}
+		return new {{.TypeNative}}().unmarshalJSON(ColferJSON.object(x, "{{.String}}"));
+{{- else if .TypeEnum}}
+		if (x instanceof String) {
+			{{.TypeEnum.Pkg.NameNative}}.{{.TypeEnum.NameNative}} e = {{.TypeEnum.Pkg.NameNative}}.{{.TypeEnum.NameNative}}.valueOfSchemaName((String) x);
+			if (e == null) throw new IllegalArgumentException(format("colfer: {{.String}} has no enumeration value %s", ColferJSON.quote((String) x)));
+			return e.value;
+		}
+		return {{template "json-integer-cast" .Type}}ColferJSON.integer(x, {{template "json-integer-range" .Type}}, "{{.String}}");
+{{- else if eq .Type "bool"}}
+		return ColferJSON.bool(x, "{{.String}}");
+{{- else if eq .Type "uint64"}}
+		return ColferJSON.unsigned64(x, "{{.String}}");
+{{- else if eq .Type "uint8" "uint16" "uint32" "int32" "int64"}}
+		return {{template "json-integer-cast" .Type}}ColferJSON.integer(x, {{template "json-integer-range" .Type}}, "{{.String}}");
+{{- else if eq .Type "float32"}}
+		return (float) ColferJSON.number(x, true, "{{.String}}");
+{{- else if eq .Type "float64"}}
+		return ColferJSON.number(x, false, "{{.String}}");
+{{- else if eq .Type "timestamp"}}
+		return ColferJSON.timestamp(x, "{{.String}}");
+{{- else if eq .Type "text"}}
+		return ColferJSON.string(x, "{{.String}}");
+{{- else if eq .Type "binary"}}
+		return ColferJSON.binary(x, "{{.String}}");
+{{- end}}
+	}
+{{end}}{{end}}`
+
+// javaJSONValue appends variable v to buf.
+const javaJSONValue = `
+{{- if .TypeRef}}				if (v == null) buf.append("{}");
+				else v.marshalJSON(buf);
+{{- else if .TypeEnum}}				{{.TypeEnum.Pkg.NameNative}}.{{.TypeEnum.NameNative}} e = {{.TypeEnum.Pkg.NameNative}}.{{.TypeEnum.NameNative}}.valueOf(v);
+				if (e != null) ColferJSON.appendString(buf, e.schemaName);
+				else buf.append({{template "json-unsigned" .Type}});
+{{- else if eq .Type "float32"}}				ColferJSON.appendNumber(buf, v, true);
+{{- else if eq .Type "float64"}}				ColferJSON.appendNumber(buf, v, false);
+{{- else if eq .Type "timestamp"}}				ColferJSON.appendTimestamp(buf, v);
+{{- else if eq .Type "text"}}				ColferJSON.appendString(buf, v == null ? "" : v);
+{{- else if eq .Type "binary"}}				ColferJSON.appendBinary(buf, v);
+{{- else if eq .Type "uint64" "int64"}}				ColferJSON.appendLong(buf, v, {{eq .Type "uint64"}});
+{{- else}}				buf.append({{template "json-unsigned" .Type}});
+{{- end}}`
+
+// javaJSONUnsigned is the decimal of variable v, when unsigned.
+const javaJSONUnsigned = `
+{{- if eq . "uint8"}}v & 0xff
+{{- else if eq . "uint16"}}v & 0xffff
+{{- else if eq . "uint32"}}Integer.toUnsignedString(v)
+{{- else if eq . "uint64"}}Long.toUnsignedString(v)
+{{- else}}v
+{{- end}}`
+
+const javaJSONIntegerCast = `{{if eq . "uint8"}}(byte) {{else if eq . "uint16"}}(short) {{else if eq . "uint32" "int32"}}(int) {{end}}`
+
+const javaJSONIntegerRange = `
+{{- if eq . "uint8"}}0, 0xff
+{{- else if eq . "uint16"}}0, 0xffff
+{{- else if eq . "uint32"}}0, 0xffffffffL
+{{- else if eq . "int32"}}Integer.MIN_VALUE, Integer.MAX_VALUE
+{{- else}}Long.MIN_VALUE, Long.MAX_VALUE
+{{- end}}`
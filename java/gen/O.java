@@ -25,14 +25,26 @@ import java.nio.BufferUnderflowException;
  * @author generated by colf(1)
  * @see <a href="https://github.com/pascaldekloe/colfer">Colfer's home</a>
  */
-public class O implements Serializable {
+public class O implements Serializable, Pick {
 
-	/** The upper limit for serial byte sizes. */
+	/** The upper limit for serial byte sizes, as applied by {@link #colferDefaults()}. */
 	public static int colferSizeMax = 16 * 1024 * 1024;
 
-	/** The upper limit for the number of elements in a list. */
+	/** The upper limit for the number of elements in a list, as applied by {@link #colferDefaults()}. */
 	public static int colferListMax = 64 * 1024;
 
+	/** The upper limit for the nesting depth of data structures, as applied by {@link #colferDefaults()}. */
+	public static int colferDepthMax = 100;
+
+	/**
+	 * Gets the limits which apply when none are given. A change to
+	 * {@link #colferSizeMax}, {@link #colferListMax} or {@link #colferDepthMax} races with any
+	 * (de)serialization in progress. The methods with explicit limits need no such change.
+	 * @return the limits of {@link #colferSizeMax}, {@link #colferListMax} and {@link #colferDepthMax}.
+	 */
+	public static ColferLimits colferDefaults() {
+		return new ColferLimits(colferSizeMax, colferListMax, colferDepthMax);
+	}
 
 	/**
 	 * B tests booleans.
@@ -124,6 +136,113 @@ public class O implements Serializable {
 	 */
 	public double[] f64s;
 
+	/**
+	 * E tests enumerations.
+	 * @see gen.Color
+	 */
+	public byte e;
+
+	/**
+	 * Bs tests boolean lists.
+	 */
+	public boolean[] bs;
+
+	/**
+	 * U8s tests unsigned 8-bit integer lists.
+	 */
+	public byte[] u8s;
+
+	/**
+	 * U16s tests unsigned 16-bit integer lists.
+	 */
+	public short[] u16s;
+
+	/**
+	 * U32s tests unsigned 32-bit integer lists.
+	 */
+	public int[] u32s;
+
+	/**
+	 * U64s tests unsigned 64-bit integer lists.
+	 */
+	public long[] u64s;
+
+	/**
+	 * I32s tests signed 32-bit integer lists.
+	 */
+	public int[] i32s;
+
+	/**
+	 * I64s tests signed 64-bit integer lists.
+	 */
+	public long[] i64s;
+
+	/**
+	 * Es tests enumeration lists.
+	 * @see gen.Color
+	 */
+	public byte[] es;
+
+	/**
+	 * Mt tests maps with text keys.
+	 */
+	public java.util.Map<String, String> mt;
+
+	/**
+	 * Mu tests maps with unsigned integer keys.
+	 */
+	public java.util.Map<Integer, Long> mu;
+
+	/**
+	 * Mi tests maps with signed integer keys.
+	 */
+	public java.util.Map<Long, byte[]> mi;
+
+	/**
+	 * Mo tests maps with data structure values.
+	 */
+	public java.util.Map<String, O> mo;
+
+	/**
+	 * Gap tests explicit indexes.
+	 */
+	public boolean gap;
+
+	/**
+	 * Host tests field size limits.
+	 */
+	public String host;
+
+	/**
+	 * Tags tests field list limits.
+	 */
+	public String[] tags;
+
+	/**
+	 * Ob tests optional booleans.
+	 */
+	public Boolean ob;
+
+	/**
+	 * Ou32 tests optional unsigned integers.
+	 */
+	public Integer ou32;
+
+	/**
+	 * Oi64 tests optional signed integers.
+	 */
+	public Long oi64;
+
+	/**
+	 * Of64 tests optional floating points.
+	 */
+	public Double of64;
+
+	/**
+	 * U tests unions.
+	 */
+	public Pick u;
+
 
 	/** Default constructor */
 	public O() {
@@ -136,6 +255,19 @@ public class O implements Serializable {
 	private static final String[] _zeroSs = new String[0];
 	private static final float[] _zeroF32s = new float[0];
 	private static final double[] _zeroF64s = new double[0];
+	private static final boolean[] _zeroBs = new boolean[0];
+	private static final byte[] _zeroU8s = new byte[0];
+	private static final short[] _zeroU16s = new short[0];
+	private static final int[] _zeroU32s = new int[0];
+	private static final long[] _zeroU64s = new long[0];
+	private static final int[] _zeroI32s = new int[0];
+	private static final long[] _zeroI64s = new long[0];
+	private static final byte[] _zeroEs = new byte[0];
+	private static final String[] _zeroTags = new String[0];
+	private static final java.util.Comparator<String> _orderMt = O::_compareUTF8;
+	private static final java.util.Comparator<Integer> _orderMu = Integer::compareUnsigned;
+	private static final java.util.Comparator<Long> _orderMi = Long::compare;
+	private static final java.util.Comparator<String> _orderMo = O::_compareUTF8;
 
 	/** Colfer zero values. */
 	private void init() {
@@ -146,6 +278,20 @@ public class O implements Serializable {
 		as = _zeroBinaries;
 		f32s = _zeroF32s;
 		f64s = _zeroF64s;
+		bs = _zeroBs;
+		u8s = _zeroU8s;
+		u16s = _zeroU16s;
+		u32s = _zeroU32s;
+		u64s = _zeroU64s;
+		i32s = _zeroI32s;
+		i64s = _zeroI64s;
+		es = _zeroEs;
+		mt = new java.util.HashMap<>();
+		mu = new java.util.HashMap<>();
+		mi = new java.util.HashMap<>();
+		mo = new java.util.HashMap<>();
+		host = "";
+		tags = _zeroTags;
 	}
 
 	/**
@@ -165,15 +311,28 @@ public class O implements Serializable {
 		/** The {@link #buf buffer}'s data end index, exclusive. */
 		protected int i;
 
+		/** The upper boundaries. */
+		protected final ColferLimits limits;
+
 
 		/**
 		 * @param in the data source or {@code null}.
 		 * @param buf the initial buffer or {@code null}.
 		 */
 		public Unmarshaller(InputStream in, byte[] buf) {
+			this(in, buf, O.colferDefaults());
+		}
+
+		/**
+		 * @param in the data source or {@code null}.
+		 * @param buf the initial buffer or {@code null}.
+		 * @param limits the upper boundaries.
+		 */
+		public Unmarshaller(InputStream in, byte[] buf, ColferLimits limits) {
+			this.limits = limits;
 			// TODO: better size estimation
 			if (buf == null || buf.length == 0)
-				buf = new byte[Math.min(O.colferSizeMax, 2048)];
+				buf = new byte[Math.min(limits.sizeMax, 2048)];
 			this.buf = buf;
 			reset(in);
 		}
@@ -194,7 +353,7 @@ public class O implements Serializable {
 		 * Deserializes the following object.
 		 * @return the result or {@code null} when EOF.
 		 * @throws IOException from the input stream.
-		 * @throws SecurityException on an upper limit breach defined by either {@link #colferSizeMax} or {@link #colferListMax}.
+		 * @throws SecurityException on an upper limit breach defined by {@link #limits}.
 		 * @throws InputMismatchException when the data does not match this object's schema.
 		 */
 		public O next() throws IOException {
@@ -204,7 +363,7 @@ public class O implements Serializable {
 				if (this.i > this.offset) {
 					try {
 						O o = new O();
-						this.offset = o.unmarshal(this.buf, this.offset, this.i);
+						this.offset = o.unmarshal(this.buf, this.offset, this.i, this.limits);
 						return o;
 					} catch (BufferUnderflowException e) {
 					}
@@ -217,7 +376,7 @@ public class O implements Serializable {
 				} else if (i == buf.length) {
 					byte[] src = this.buf;
 					// TODO: better size estimation
-					if (offset == 0) this.buf = new byte[Math.min(O.colferSizeMax, this.buf.length * 4)];
+					if (offset == 0) this.buf = new byte[Math.min(this.limits.sizeMax, this.buf.length * 4)];
 					System.arraycopy(src, this.offset, this.buf, 0, this.i - this.offset);
 					this.i -= this.offset;
 					this.offset = 0;
@@ -243,12 +402,18 @@ public class O implements Serializable {
 	 * All {@code null} elements in {@link #os} will be replaced with a {@code new} value.
 	 * All {@code null} elements in {@link #ss} will be replaced with {@code ""}.
 	 * All {@code null} elements in {@link #as} will be replaced with an empty byte array.
+	 * All {@code null} values in {@link #mt} will be replaced with {@code ""}.
+	 * All {@code null} values in {@link #mu} will be replaced with zero.
+	 * All {@code null} values in {@link #mi} will be replaced with an empty byte array.
+	 * All {@code null} values in {@link #mo} will be replaced with a {@code new} value.
+	 * All {@code null} elements in {@link #tags} will be replaced with {@code ""}.
 	 * @param out the data destination.
 	 * @param buf the initial buffer or {@code null}.
 	 * @return the final buffer. When the serial fits into {@code buf} then the return is {@code buf}.
 	 *  Otherwise the return is a new buffer, large enough to hold the whole serial.
 	 * @throws IOException from {@code out}.
 	 * @throws IllegalStateException on an upper limit breach defined by either {@link #colferSizeMax} or {@link #colferListMax}.
+	 * @throws ColferDepthException on an upper limit breach defined by {@link #colferDepthMax}.
 	 */
 	public byte[] marshal(OutputStream out, byte[] buf) throws IOException {
 		// TODO: better size estimation
@@ -274,13 +439,38 @@ public class O implements Serializable {
 	 * All {@code null} elements in {@link #os} will be replaced with a {@code new} value.
 	 * All {@code null} elements in {@link #ss} will be replaced with {@code ""}.
 	 * All {@code null} elements in {@link #as} will be replaced with an empty byte array.
+	 * All {@code null} values in {@link #mt} will be replaced with {@code ""}.
+	 * All {@code null} values in {@link #mu} will be replaced with zero.
+	 * All {@code null} values in {@link #mi} will be replaced with an empty byte array.
+	 * All {@code null} values in {@link #mo} will be replaced with a {@code new} value.
+	 * All {@code null} elements in {@link #tags} will be replaced with {@code ""}.
 	 * @param buf the data destination.
 	 * @param offset the initial index for {@code buf}, inclusive.
 	 * @return the final index for {@code buf}, exclusive.
 	 * @throws BufferOverflowException when {@code buf} is too small.
 	 * @throws IllegalStateException on an upper limit breach defined by either {@link #colferSizeMax} or {@link #colferListMax}.
+	 * @throws ColferDepthException on an upper limit breach defined by {@link #colferDepthMax}.
 	 */
 	public int marshal(byte[] buf, int offset) {
+		return marshal(buf, offset, colferDefaults());
+	}
+
+	/**
+	 * Serializes the object like {@link #marshal(byte[], int)} does,
+	 * with explicit limits instead of the {@link #colferDefaults() defaults}.
+	 * @param buf the data destination.
+	 * @param offset the initial index for {@code buf}, inclusive.
+	 * @param limits the upper boundaries.
+	 * @return the final index for {@code buf}, exclusive.
+	 * @throws BufferOverflowException when {@code buf} is too small.
+	 * @throws IllegalStateException on an upper limit breach.
+	 * @throws ColferDepthException on an upper limit breach defined by {@link ColferLimits#depthMax}.
+	 */
+	public int marshal(byte[] buf, int offset, ColferLimits limits) {
+		if (limits.depthMax < 1)
+			throw new ColferDepthException("colfer: gen.o exceeds the nesting depth limit; check for reference cycles");
+		// nested data structures are one level deeper
+		ColferLimits nested = new ColferLimits(limits.sizeMax, limits.listMax, limits.depthMax - 1);
 		int i = offset;
 
 		try {
@@ -438,8 +628,8 @@ public class O implements Serializable {
 					}
 				}
 				int size = i - start;
-				if (size > O.colferSizeMax)
-					throw new IllegalStateException(format("colfer: gen.o.s size %d exceeds %d UTF-8 bytes", size, O.colferSizeMax));
+				if (size > limits.sizeMax)
+					throw new IllegalStateException(format("colfer: gen.o.s size %d exceeds %d UTF-8 bytes", size, limits.sizeMax));
 
 				int ii = start - 1;
 				if (size > 0x7f) {
@@ -459,8 +649,8 @@ public class O implements Serializable {
 				buf[i++] = (byte) 9;
 
 				int size = this.a.length;
-				if (size > O.colferSizeMax)
-					throw new IllegalStateException(format("colfer: gen.o.a size %d exceeds %d bytes", size, O.colferSizeMax));
+				if (size > limits.sizeMax)
+					throw new IllegalStateException(format("colfer: gen.o.a size %d exceeds %d bytes", size, limits.sizeMax));
 
 				int x = size;
 				while (x > 0x7f) {
@@ -476,7 +666,9 @@ public class O implements Serializable {
 
 			if (this.o != null) {
 				buf[i++] = (byte) 10;
-				i = this.o.marshal(buf, i);
+				if (this.o == this)
+					throw new ColferDepthException("colfer: gen.o.o makes a reference cycle");
+				i = this.o.marshal(buf, i, nested);
 			}
 
 			if (this.os.length != 0) {
@@ -484,8 +676,8 @@ public class O implements Serializable {
 				O[] a = this.os;
 
 				int x = a.length;
-				if (x > O.colferListMax)
-					throw new IllegalStateException(format("colfer: gen.o.os length %d exceeds %d elements", x, O.colferListMax));
+				if (x > limits.listMax)
+					throw new IllegalStateException(format("colfer: gen.o.os length %d exceeds %d elements", x, limits.listMax));
 				while (x > 0x7f) {
 					buf[i++] = (byte) (x | 0x80);
 					x >>>= 7;
@@ -498,7 +690,9 @@ public class O implements Serializable {
 						o = new O();
 						a[ai] = o;
 					}
-					i = o.marshal(buf, i);
+					if (o == this)
+						throw new ColferDepthException("colfer: gen.o.os makes a reference cycle");
+					i = o.marshal(buf, i, nested);
 				}
 			}
 
@@ -507,8 +701,8 @@ public class O implements Serializable {
 				String[] a = this.ss;
 
 				int x = a.length;
-				if (x > O.colferListMax)
-					throw new IllegalStateException(format("colfer: gen.o.ss length %d exceeds %d elements", x, O.colferListMax));
+				if (x > limits.listMax)
+					throw new IllegalStateException(format("colfer: gen.o.ss length %d exceeds %d elements", x, limits.listMax));
 				while (x > 0x7f) {
 					buf[i++] = (byte) (x | 0x80);
 					x >>>= 7;
@@ -548,8 +742,8 @@ public class O implements Serializable {
 						}
 					}
 					int size = i - start;
-					if (size > O.colferSizeMax)
-						throw new IllegalStateException(format("colfer: gen.o.ss[%d] size %d exceeds %d UTF-8 bytes", ai, size, O.colferSizeMax));
+					if (size > limits.sizeMax)
+						throw new IllegalStateException(format("colfer: gen.o.ss[%d] size %d exceeds %d UTF-8 bytes", ai, size, limits.sizeMax));
 
 					int ii = start - 1;
 					if (size > 0x7f) {
@@ -571,8 +765,8 @@ public class O implements Serializable {
 				byte[][] a = this.as;
 
 				int x = a.length;
-				if (x > O.colferListMax)
-					throw new IllegalStateException(format("colfer: gen.o.as length %d exceeds %d elements", x, O.colferListMax));
+				if (x > limits.listMax)
+					throw new IllegalStateException(format("colfer: gen.o.as length %d exceeds %d elements", x, limits.listMax));
 				while (x > 0x7f) {
 					buf[i++] = (byte) (x | 0x80);
 					x >>>= 7;
@@ -585,8 +779,8 @@ public class O implements Serializable {
 						b = _zeroBytes;
 						a[ai] = b;
 					}
-					if (b.length > O.colferSizeMax)
-						throw new IllegalStateException(format("colfer: gen.o.as[%d] size %d exceeds %d bytes", ai, b.length, O.colferSizeMax));
+					if (b.length > limits.sizeMax)
+						throw new IllegalStateException(format("colfer: gen.o.as[%d] size %d exceeds %d bytes", ai, b.length, limits.sizeMax));
 
 					x = b.length;
 					while (x > 0x7f) {
@@ -622,8 +816,8 @@ public class O implements Serializable {
 				float[] a = this.f32s;
 
 				int l = a.length;
-				if (l > O.colferListMax)
-					throw new IllegalStateException(format("colfer: gen.o.f32s length %d exceeds %d elements", l, O.colferListMax));
+				if (l > limits.listMax)
+					throw new IllegalStateException(format("colfer: gen.o.f32s length %d exceeds %d elements", l, limits.listMax));
 				while (l > 0x7f) {
 					buf[i++] = (byte) (l | 0x80);
 					l >>>= 7;
@@ -644,8 +838,8 @@ public class O implements Serializable {
 				double[] a = this.f64s;
 
 				int l = a.length;
-				if (l > O.colferListMax)
-					throw new IllegalStateException(format("colfer: gen.o.f64s length %d exceeds %d elements", l, O.colferListMax));
+				if (l > limits.listMax)
+					throw new IllegalStateException(format("colfer: gen.o.f64s length %d exceeds %d elements", l, limits.listMax));
 				while (l > 0x7f) {
 					buf[i++] = (byte) (l | 0x80);
 					l >>>= 7;
@@ -665,842 +859,3242 @@ public class O implements Serializable {
 				}
 			}
 
-			buf[i++] = (byte) 0x7f;
-			return i;
-		} catch (ArrayIndexOutOfBoundsException e) {
-			if (i - offset > O.colferSizeMax)
-				throw new IllegalStateException(format("colfer: gen.o exceeds %d bytes", O.colferSizeMax));
-			if (i > buf.length) throw new BufferOverflowException();
-			throw e;
-		}
-	}
-
-	/**
-	 * Deserializes the object.
-	 * @param buf the data source.
-	 * @param offset the initial index for {@code buf}, inclusive.
-	 * @return the final index for {@code buf}, exclusive.
-	 * @throws BufferUnderflowException when {@code buf} is incomplete. (EOF)
-	 * @throws SecurityException on an upper limit breach defined by either {@link #colferSizeMax} or {@link #colferListMax}.
-	 * @throws InputMismatchException when the data does not match this object's schema.
-	 */
-	public int unmarshal(byte[] buf, int offset) {
-		return unmarshal(buf, offset, buf.length);
-	}
+			if (this.e != 0) {
+				buf[i++] = (byte) 18;
+				buf[i++] = this.e;
+			}
 
-	/**
-	 * Deserializes the object.
-	 * @param buf the data source.
-	 * @param offset the initial index for {@code buf}, inclusive.
-	 * @param end the index limit for {@code buf}, exclusive.
-	 * @return the final index for {@code buf}, exclusive.
-	 * @throws BufferUnderflowException when {@code buf} is incomplete. (EOF)
-	 * @throws SecurityException on an upper limit breach defined by either {@link #colferSizeMax} or {@link #colferListMax}.
-	 * @throws InputMismatchException when the data does not match this object's schema.
-	 */
-	public int unmarshal(byte[] buf, int offset, int end) {
-		if (end > buf.length) end = buf.length;
-		int i = offset;
+			if (this.bs.length != 0) {
+				buf[i++] = (byte) 19;
+				boolean[] a = this.bs;
 
-		try {
-			byte header = buf[i++];
+				int l = a.length;
+				if (l > limits.listMax)
+					throw new IllegalStateException(format("colfer: gen.o.bs length %d exceeds %d elements", l, limits.listMax));
+				while (l > 0x7f) {
+					buf[i++] = (byte) (l | 0x80);
+					l >>>= 7;
+				}
+				buf[i++] = (byte) l;
 
-			if (header == (byte) 0) {
-				this.b = true;
-				header = buf[i++];
+				for (boolean b : a) buf[i++] = (byte) (b ? 1 : 0);
 			}
 
-			if (header == (byte) 1) {
-				int x = 0;
-				for (int shift = 0; true; shift += 7) {
-					byte b = buf[i++];
-					x |= (b & 0x7f) << shift;
-					if (shift == 28 || b >= 0) break;
+			if (this.u8s.length != 0) {
+				buf[i++] = (byte) 20;
+				byte[] a = this.u8s;
+
+				int l = a.length;
+				if (l > limits.listMax)
+					throw new IllegalStateException(format("colfer: gen.o.u8s length %d exceeds %d elements", l, limits.listMax));
+				while (l > 0x7f) {
+					buf[i++] = (byte) (l | 0x80);
+					l >>>= 7;
 				}
-				this.u32 = x;
-				header = buf[i++];
-			} else if (header == (byte) (1 | 0x80)) {
-				this.u32 = (buf[i++] & 0xff) << 24 | (buf[i++] & 0xff) << 16 | (buf[i++] & 0xff) << 8 | (buf[i++] & 0xff);
-				header = buf[i++];
+				buf[i++] = (byte) l;
+
+				int start = i;
+				i += a.length;
+				System.arraycopy(a, 0, buf, start, a.length);
 			}
 
-			if (header == (byte) 2) {
-				long x = 0;
-				for (int shift = 0; true; shift += 7) {
-					byte b = buf[i++];
-					if (shift == 56 || b >= 0) {
-						x |= (b & 0xffL) << shift;
-						break;
+			if (this.u16s.length != 0) {
+				buf[i++] = (byte) 21;
+				short[] a = this.u16s;
+
+				int l = a.length;
+				if (l > limits.listMax)
+					throw new IllegalStateException(format("colfer: gen.o.u16s length %d exceeds %d elements", l, limits.listMax));
+				while (l > 0x7f) {
+					buf[i++] = (byte) (l | 0x80);
+					l >>>= 7;
+				}
+				buf[i++] = (byte) l;
+
+				for (short v : a) {
+					int x = v & 0xffff;
+					while ((x & ~0x7f) != 0) {
+						buf[i++] = (byte) (x | 0x80);
+						x >>>= 7;
 					}
-					x |= (b & 0x7fL) << shift;
+					buf[i++] = (byte) x;
 				}
-				this.u64 = x;
-				header = buf[i++];
-			} else if (header == (byte) (2 | 0x80)) {
-				this.u64 = (buf[i++] & 0xffL) << 56 | (buf[i++] & 0xffL) << 48 | (buf[i++] & 0xffL) << 40 | (buf[i++] & 0xffL) << 32
-					| (buf[i++] & 0xffL) << 24 | (buf[i++] & 0xffL) << 16 | (buf[i++] & 0xffL) << 8 | (buf[i++] & 0xffL);
-				header = buf[i++];
 			}
 
-			if (header == (byte) 3) {
-				int x = 0;
-				for (int shift = 0; true; shift += 7) {
-					byte b = buf[i++];
-					x |= (b & 0x7f) << shift;
-					if (shift == 28 || b >= 0) break;
+			if (this.u32s.length != 0) {
+				buf[i++] = (byte) 22;
+				int[] a = this.u32s;
+
+				int l = a.length;
+				if (l > limits.listMax)
+					throw new IllegalStateException(format("colfer: gen.o.u32s length %d exceeds %d elements", l, limits.listMax));
+				while (l > 0x7f) {
+					buf[i++] = (byte) (l | 0x80);
+					l >>>= 7;
 				}
-				this.i32 = x;
-				header = buf[i++];
-			} else if (header == (byte) (3 | 0x80)) {
-				int x = 0;
-				for (int shift = 0; true; shift += 7) {
-					byte b = buf[i++];
-					x |= (b & 0x7f) << shift;
-					if (shift == 28 || b >= 0) break;
+				buf[i++] = (byte) l;
+
+				for (int v : a) {
+					int x = v;
+					while ((x & ~0x7f) != 0) {
+						buf[i++] = (byte) (x | 0x80);
+						x >>>= 7;
+					}
+					buf[i++] = (byte) x;
 				}
-				this.i32 = -x;
-				header = buf[i++];
 			}
 
-			if (header == (byte) 4) {
-				long x = 0;
-				for (int shift = 0; true; shift += 7) {
-					byte b = buf[i++];
-					if (shift == 56 || b >= 0) {
-						x |= (b & 0xffL) << shift;
-						break;
-					}
-					x |= (b & 0x7fL) << shift;
+			if (this.u64s.length != 0) {
+				buf[i++] = (byte) 23;
+				long[] a = this.u64s;
+
+				int l = a.length;
+				if (l > limits.listMax)
+					throw new IllegalStateException(format("colfer: gen.o.u64s length %d exceeds %d elements", l, limits.listMax));
+				while (l > 0x7f) {
+					buf[i++] = (byte) (l | 0x80);
+					l >>>= 7;
 				}
-				this.i64 = x;
-				header = buf[i++];
-			} else if (header == (byte) (4 | 0x80)) {
-				long x = 0;
-				for (int shift = 0; true; shift += 7) {
-					byte b = buf[i++];
-					if (shift == 56 || b >= 0) {
-						x |= (b & 0xffL) << shift;
-						break;
+				buf[i++] = (byte) l;
+
+				for (long v : a) {
+					long x = v;
+					for (int n = 0; n < 8 && (x & ~0x7fL) != 0; n++) {
+						buf[i++] = (byte) (x | 0x80);
+						x >>>= 7;
 					}
-					x |= (b & 0x7fL) << shift;
+					buf[i++] = (byte) x;
 				}
-				this.i64 = -x;
-				header = buf[i++];
 			}
 
-			if (header == (byte) 5) {
-				int x = (buf[i++] & 0xff) << 24 | (buf[i++] & 0xff) << 16 | (buf[i++] & 0xff) << 8 | (buf[i++] & 0xff);
-				this.f32 = Float.intBitsToFloat(x);
-				header = buf[i++];
-			}
+			if (this.i32s.length != 0) {
+				buf[i++] = (byte) 24;
+				int[] a = this.i32s;
 
-			if (header == (byte) 6) {
-				long x = (buf[i++] & 0xffL) << 56 | (buf[i++] & 0xffL) << 48 | (buf[i++] & 0xffL) << 40 | (buf[i++] & 0xffL) << 32
-					| (buf[i++] & 0xffL) << 24 | (buf[i++] & 0xffL) << 16 | (buf[i++] & 0xffL) << 8 | (buf[i++] & 0xffL);
-				this.f64 = Double.longBitsToDouble(x);
-				header = buf[i++];
+				int l = a.length;
+				if (l > limits.listMax)
+					throw new IllegalStateException(format("colfer: gen.o.i32s length %d exceeds %d elements", l, limits.listMax));
+				while (l > 0x7f) {
+					buf[i++] = (byte) (l | 0x80);
+					l >>>= 7;
+				}
+				buf[i++] = (byte) l;
+
+				for (int v : a) {
+					int x = (v << 1) ^ (v >> 31);
+					while ((x & ~0x7f) != 0) {
+						buf[i++] = (byte) (x | 0x80);
+						x >>>= 7;
+					}
+					buf[i++] = (byte) x;
+				}
 			}
 
-			if (header == (byte) 7) {
-				long s = (buf[i++] & 0xffL) << 24 | (buf[i++] & 0xffL) << 16 | (buf[i++] & 0xffL) << 8 | (buf[i++] & 0xffL);
-				long ns = (buf[i++] & 0xffL) << 24 | (buf[i++] & 0xffL) << 16 | (buf[i++] & 0xffL) << 8 | (buf[i++] & 0xffL);
-				this.t = java.time.Instant.ofEpochSecond(s, ns);
-				header = buf[i++];
-			} else if (header == (byte) (7 | 0x80)) {
-				long s = (buf[i++] & 0xffL) << 56 | (buf[i++] & 0xffL) << 48 | (buf[i++] & 0xffL) << 40 | (buf[i++] & 0xffL) << 32
-					| (buf[i++] & 0xffL) << 24 | (buf[i++] & 0xffL) << 16 | (buf[i++] & 0xffL) << 8 | (buf[i++] & 0xffL);
-				long ns = (buf[i++] & 0xffL) << 24 | (buf[i++] & 0xffL) << 16 | (buf[i++] & 0xffL) << 8 | (buf[i++] & 0xffL);
-				this.t = java.time.Instant.ofEpochSecond(s, ns);
-				header = buf[i++];
-			}
+			if (this.i64s.length != 0) {
+				buf[i++] = (byte) 25;
+				long[] a = this.i64s;
 
-			if (header == (byte) 8) {
-				int size = 0;
-				for (int shift = 0; true; shift += 7) {
-					byte b = buf[i++];
-					size |= (b & 0x7f) << shift;
-					if (shift == 28 || b >= 0) break;
+				int l = a.length;
+				if (l > limits.listMax)
+					throw new IllegalStateException(format("colfer: gen.o.i64s length %d exceeds %d elements", l, limits.listMax));
+				while (l > 0x7f) {
+					buf[i++] = (byte) (l | 0x80);
+					l >>>= 7;
 				}
-				if (size < 0 || size > O.colferSizeMax)
-					throw new SecurityException(format("colfer: gen.o.s size %d exceeds %d UTF-8 bytes", size, O.colferSizeMax));
+				buf[i++] = (byte) l;
 
-				int start = i;
-				i += size;
-				this.s = new String(buf, start, size, StandardCharsets.UTF_8);
-				header = buf[i++];
+				for (long v : a) {
+					long x = (v << 1) ^ (v >> 63);
+					for (int n = 0; n < 8 && (x & ~0x7fL) != 0; n++) {
+						buf[i++] = (byte) (x | 0x80);
+						x >>>= 7;
+					}
+					buf[i++] = (byte) x;
+				}
 			}
 
-			if (header == (byte) 9) {
-				int size = 0;
-				for (int shift = 0; true; shift += 7) {
-					byte b = buf[i++];
-					size |= (b & 0x7f) << shift;
-					if (shift == 28 || b >= 0) break;
+			if (this.es.length != 0) {
+				buf[i++] = (byte) 26;
+				byte[] a = this.es;
+
+				int l = a.length;
+				if (l > limits.listMax)
+					throw new IllegalStateException(format("colfer: gen.o.es length %d exceeds %d elements", l, limits.listMax));
+				while (l > 0x7f) {
+					buf[i++] = (byte) (l | 0x80);
+					l >>>= 7;
 				}
-				if (size < 0 || size > O.colferSizeMax)
-					throw new SecurityException(format("colfer: gen.o.a size %d exceeds %d bytes", size, O.colferSizeMax));
+				buf[i++] = (byte) l;
 
-				this.a = new byte[size];
 				int start = i;
-				i += size;
-				System.arraycopy(buf, start, this.a, 0, size);
-
-				header = buf[i++];
+				i += a.length;
+				System.arraycopy(a, 0, buf, start, a.length);
 			}
 
-			if (header == (byte) 10) {
-				this.o = new O();
-				i = this.o.unmarshal(buf, i, end);
-				header = buf[i++];
-			}
+			if (! this.mt.isEmpty()) {
+				buf[i++] = (byte) 27;
+				java.util.Map<String, String> m = this.mt;
 
-			if (header == (byte) 11) {
-				int length = 0;
-				for (int shift = 0; true; shift += 7) {
-					byte b = buf[i++];
-					length |= (b & 0x7f) << shift;
-					if (shift == 28 || b >= 0) break;
+				int l = m.size();
+				if (l > limits.listMax)
+					throw new IllegalStateException(format("colfer: gen.o.mt length %d exceeds %d elements", l, limits.listMax));
+				while (l > 0x7f) {
+					buf[i++] = (byte) (l | 0x80);
+					l >>>= 7;
 				}
-				if (length < 0 || length > O.colferListMax)
-					throw new SecurityException(format("colfer: gen.o.os length %d exceeds %d elements", length, O.colferListMax));
+				buf[i++] = (byte) l;
 
-				O[] a = new O[length];
-				for (int ai = 0; ai < length; ai++) {
-					O o = new O();
-					i = o.unmarshal(buf, i, end);
-					a[ai] = o;
+				String[] keys = m.keySet().toArray(new String[m.size()]);
+				for (String k : keys)
+					if (k == null) throw new IllegalStateException("colfer: gen.o.mt has a null key");
+				java.util.Arrays.sort(keys, _orderMt);
+
+				for (String k : keys) {
+					byte[] kb = k.getBytes(StandardCharsets.UTF_8);
+					if (kb.length > limits.sizeMax)
+						throw new IllegalStateException(format("colfer: gen.o.mt key size %d exceeds %d UTF-8 bytes", kb.length, limits.sizeMax));
+					int kx = kb.length;
+					while (kx > 0x7f) {
+						buf[i++] = (byte) (kx | 0x80);
+						kx >>>= 7;
+					}
+					buf[i++] = (byte) kx;
+					int kStart = i;
+					i += kb.length;
+					System.arraycopy(kb, 0, buf, kStart, kb.length);
+
+					String v = m.get(k);
+					if (v == null) {
+						v = "";
+						m.put(k, v);
+					}
+					byte[] vb = v.getBytes(StandardCharsets.UTF_8);
+					if (vb.length > limits.sizeMax)
+						throw new IllegalStateException(format("colfer: gen.o.mt value size %d exceeds %d UTF-8 bytes", vb.length, limits.sizeMax));
+					int vx = vb.length;
+					while (vx > 0x7f) {
+						buf[i++] = (byte) (vx | 0x80);
+						vx >>>= 7;
+					}
+					buf[i++] = (byte) vx;
+					int vStart = i;
+					i += vb.length;
+					System.arraycopy(vb, 0, buf, vStart, vb.length);
 				}
-				this.os = a;
-				header = buf[i++];
 			}
 
-			if (header == (byte) 12) {
-				int length = 0;
-				for (int shift = 0; true; shift += 7) {
-					byte b = buf[i++];
-					length |= (b & 0x7f) << shift;
-					if (shift == 28 || b >= 0) break;
+			if (! this.mu.isEmpty()) {
+				buf[i++] = (byte) 28;
+				java.util.Map<Integer, Long> m = this.mu;
+
+				int l = m.size();
+				if (l > limits.listMax)
+					throw new IllegalStateException(format("colfer: gen.o.mu length %d exceeds %d elements", l, limits.listMax));
+				while (l > 0x7f) {
+					buf[i++] = (byte) (l | 0x80);
+					l >>>= 7;
 				}
-				if (length < 0 || length > O.colferListMax)
-					throw new SecurityException(format("colfer: gen.o.ss length %d exceeds %d elements", length, O.colferListMax));
+				buf[i++] = (byte) l;
 
-				String[] a = new String[length];
-				for (int ai = 0; ai < length; ai++) {
-					int size = 0;
-					for (int shift = 0; true; shift += 7) {
-						byte b = buf[i++];
-						size |= (b & 0x7f) << shift;
-						if (shift == 28 || b >= 0) break;
+				Integer[] keys = m.keySet().toArray(new Integer[m.size()]);
+				for (Integer k : keys)
+					if (k == null) throw new IllegalStateException("colfer: gen.o.mu has a null key");
+				java.util.Arrays.sort(keys, _orderMu);
+
+				for (Integer k : keys) {
+					int kx = k;
+					while ((kx & ~0x7f) != 0) {
+						buf[i++] = (byte) (kx | 0x80);
+						kx >>>= 7;
 					}
-					if (size < 0 || size > O.colferSizeMax)
-						throw new SecurityException(format("colfer: gen.o.ss[%d] size %d exceeds %d UTF-8 bytes", ai, size, O.colferSizeMax));
+					buf[i++] = (byte) kx;
 
-					int start = i;
-					i += size;
-					a[ai] = new String(buf, start, size, StandardCharsets.UTF_8);
+					Long v = m.get(k);
+					if (v == null) {
+						v = 0L;
+						m.put(k, v);
+					}
+					long vx = (v << 1) ^ (v >> 63);
+					for (int n = 0; n < 8 && (vx & ~0x7fL) != 0; n++) {
+						buf[i++] = (byte) (vx | 0x80);
+						vx >>>= 7;
+					}
+					buf[i++] = (byte) vx;
 				}
-				this.ss = a;
-				header = buf[i++];
 			}
 
-			if (header == (byte) 13) {
-				int length = 0;
-				for (int shift = 0; true; shift += 7) {
-					byte b = buf[i++];
-					length |= (b & 0x7f) << shift;
-					if (shift == 28 || b >= 0) break;
+			if (! this.mi.isEmpty()) {
+				buf[i++] = (byte) 29;
+				java.util.Map<Long, byte[]> m = this.mi;
+
+				int l = m.size();
+				if (l > limits.listMax)
+					throw new IllegalStateException(format("colfer: gen.o.mi length %d exceeds %d elements", l, limits.listMax));
+				while (l > 0x7f) {
+					buf[i++] = (byte) (l | 0x80);
+					l >>>= 7;
 				}
-				if (length < 0 || length > O.colferListMax)
-					throw new SecurityException(format("colfer: gen.o.as length %d exceeds %d elements", length, O.colferListMax));
+				buf[i++] = (byte) l;
 
-				byte[][] a = new byte[length][];
-				for (int ai = 0; ai < length; ai++) {
-					int size = 0;
-					for (int shift = 0; true; shift += 7) {
-						byte b = buf[i++];
-						size |= (b & 0x7f) << shift;
-						if (shift == 28 || b >= 0) break;
+				Long[] keys = m.keySet().toArray(new Long[m.size()]);
+				for (Long k : keys)
+					if (k == null) throw new IllegalStateException("colfer: gen.o.mi has a null key");
+				java.util.Arrays.sort(keys, _orderMi);
+
+				for (Long k : keys) {
+					long kx = (k << 1) ^ (k >> 63);
+					for (int n = 0; n < 8 && (kx & ~0x7fL) != 0; n++) {
+						buf[i++] = (byte) (kx | 0x80);
+						kx >>>= 7;
 					}
-					if (size < 0 || size > O.colferSizeMax)
-						throw new SecurityException(format("colfer: gen.o.as[%d] size %d exceeds %d bytes", ai, size, O.colferSizeMax));
+					buf[i++] = (byte) kx;
 
-					byte[] e = new byte[size];
-					int start = i;
-					i += size;
-					System.arraycopy(buf, start, e, 0, size);
-					a[ai] = e;
+					byte[] v = m.get(k);
+					if (v == null) {
+						v = _zeroBytes;
+						m.put(k, v);
+					}
+					byte[] vb = v;
+					if (vb.length > limits.sizeMax)
+						throw new IllegalStateException(format("colfer: gen.o.mi value size %d exceeds %d bytes", vb.length, limits.sizeMax));
+					int vx = vb.length;
+					while (vx > 0x7f) {
+						buf[i++] = (byte) (vx | 0x80);
+						vx >>>= 7;
+					}
+					buf[i++] = (byte) vx;
+					int vStart = i;
+					i += vb.length;
+					System.arraycopy(vb, 0, buf, vStart, vb.length);
 				}
-				this.as = a;
-
-				header = buf[i++];
 			}
 
-			if (header == (byte) 14) {
-				this.u8 = buf[i++];
-				header = buf[i++];
+			if (! this.mo.isEmpty()) {
+				buf[i++] = (byte) 30;
+				java.util.Map<String, O> m = this.mo;
+
+				int l = m.size();
+				if (l > limits.listMax)
+					throw new IllegalStateException(format("colfer: gen.o.mo length %d exceeds %d elements", l, limits.listMax));
+				while (l > 0x7f) {
+					buf[i++] = (byte) (l | 0x80);
+					l >>>= 7;
+				}
+				buf[i++] = (byte) l;
+
+				String[] keys = m.keySet().toArray(new String[m.size()]);
+				for (String k : keys)
+					if (k == null) throw new IllegalStateException("colfer: gen.o.mo has a null key");
+				java.util.Arrays.sort(keys, _orderMo);
+
+				for (String k : keys) {
+					byte[] kb = k.getBytes(StandardCharsets.UTF_8);
+					if (kb.length > limits.sizeMax)
+						throw new IllegalStateException(format("colfer: gen.o.mo key size %d exceeds %d UTF-8 bytes", kb.length, limits.sizeMax));
+					int kx = kb.length;
+					while (kx > 0x7f) {
+						buf[i++] = (byte) (kx | 0x80);
+						kx >>>= 7;
+					}
+					buf[i++] = (byte) kx;
+					int kStart = i;
+					i += kb.length;
+					System.arraycopy(kb, 0, buf, kStart, kb.length);
+
+					O v = m.get(k);
+					if (v == null) {
+						v = new O();
+						m.put(k, v);
+					}
+					if (v == this)
+						throw new ColferDepthException("colfer: gen.o.mo makes a reference cycle");
+					i = v.marshal(buf, i, nested);
+				}
 			}
 
-			if (header == (byte) 15) {
-				this.u16 = (short) ((buf[i++] & 0xff) << 8 | (buf[i++] & 0xff));
-				header = buf[i++];
-			} else if (header == (byte) (15 | 0x80)) {
-				this.u16 = (short) (buf[i++] & 0xff);
-				header = buf[i++];
+			if (this.gap) {
+				buf[i++] = (byte) 32;
 			}
 
-			if (header == (byte) 16) {
-				int length = 0;
-				for (int shift = 0; true; shift += 7) {
-					byte b = buf[i++];
-					length |= (b & 0x7f) << shift;
-					if (shift == 28 || b >= 0) break;
+			if (! this.host.isEmpty()) {
+				buf[i++] = (byte) 33;
+				int start = ++i;
+
+				String s = this.host;
+				for (int sIndex = 0, sLength = s.length(); sIndex < sLength; sIndex++) {
+					char c = s.charAt(sIndex);
+					if (c < '\u0080') {
+						buf[i++] = (byte) c;
+					} else if (c < '\u0800') {
+						buf[i++] = (byte) (192 | c >>> 6);
+						buf[i++] = (byte) (128 | c & 63);
+					} else if (c < '\ud800' || c > '\udfff') {
+						buf[i++] = (byte) (224 | c >>> 12);
+						buf[i++] = (byte) (128 | c >>> 6 & 63);
+						buf[i++] = (byte) (128 | c & 63);
+					} else {
+						int cp = 0;
+						if (++sIndex < sLength) cp = Character.toCodePoint(c, s.charAt(sIndex));
+						if ((cp >= 1 << 16) && (cp < 1 << 21)) {
+							buf[i++] = (byte) (240 | cp >>> 18);
+							buf[i++] = (byte) (128 | cp >>> 12 & 63);
+							buf[i++] = (byte) (128 | cp >>> 6 & 63);
+							buf[i++] = (byte) (128 | cp & 63);
+						} else
+							buf[i++] = (byte) '?';
+					}
 				}
-				if (length < 0 || length > O.colferListMax)
-					throw new SecurityException(format("colfer: gen.o.f32s length %d exceeds %d elements", length, O.colferListMax));
+				int size = i - start;
+				if (size > 4)
+					throw new IllegalStateException(format("colfer: gen.o.host size %d exceeds %d UTF-8 bytes", size, 4));
 
-				float[] a = new float[length];
-				for (int ai = 0; ai < length; ai++) {
-					int x = (buf[i++] & 0xff) << 24 | (buf[i++] & 0xff) << 16 | (buf[i++] & 0xff) << 8 | (buf[i++] & 0xff);
-					a[ai] = Float.intBitsToFloat(x);
+				int ii = start - 1;
+				if (size > 0x7f) {
+					i++;
+					for (int x = size; x >= 1 << 14; x >>>= 7) i++;
+					System.arraycopy(buf, start, buf, i - size, size);
+
+					do {
+						buf[ii++] = (byte) (size | 0x80);
+						size >>>= 7;
+					} while (size > 0x7f);
 				}
-				this.f32s = a;
-				header = buf[i++];
+				buf[ii] = (byte) size;
 			}
 
-			if (header == (byte) 17) {
-				int length = 0;
-				for (int shift = 0; true; shift += 7) {
-					byte b = buf[i++];
-					length |= (b & 0x7f) << shift;
-					if (shift == 28 || b >= 0) break;
+			if (this.tags.length != 0) {
+				buf[i++] = (byte) 34;
+				String[] a = this.tags;
+
+				int x = a.length;
+				if (x > 2)
+					throw new IllegalStateException(format("colfer: gen.o.tags length %d exceeds %d elements", x, 2));
+				while (x > 0x7f) {
+					buf[i++] = (byte) (x | 0x80);
+					x >>>= 7;
+				}
+				buf[i++] = (byte) x;
+
+				for (int ai = 0; ai < a.length; ai++) {
+					String s = a[ai];
+					if (s == null) {
+						s = "";
+						a[ai] = s;
+					}
+
+					int start = ++i;
+
+					for (int sIndex = 0, sLength = s.length(); sIndex < sLength; sIndex++) {
+						char c = s.charAt(sIndex);
+						if (c < '\u0080') {
+							buf[i++] = (byte) c;
+						} else if (c < '\u0800') {
+							buf[i++] = (byte) (192 | c >>> 6);
+							buf[i++] = (byte) (128 | c & 63);
+						} else if (c < '\ud800' || c > '\udfff') {
+							buf[i++] = (byte) (224 | c >>> 12);
+							buf[i++] = (byte) (128 | c >>> 6 & 63);
+							buf[i++] = (byte) (128 | c & 63);
+						} else {
+							int cp = 0;
+							if (++sIndex < sLength) cp = Character.toCodePoint(c, s.charAt(sIndex));
+							if ((cp >= 1 << 16) && (cp < 1 << 21)) {
+								buf[i++] = (byte) (240 | cp >>> 18);
+								buf[i++] = (byte) (128 | cp >>> 12 & 63);
+								buf[i++] = (byte) (128 | cp >>> 6 & 63);
+								buf[i++] = (byte) (128 | cp & 63);
+							} else
+								buf[i++] = (byte) '?';
+						}
+					}
+					int size = i - start;
+					if (size > 1)
+						throw new IllegalStateException(format("colfer: gen.o.tags[%d] size %d exceeds %d UTF-8 bytes", ai, size, 1));
+
+					int ii = start - 1;
+					if (size > 0x7f) {
+						i++;
+						for (int y = size; y >= 1 << 14; y >>>= 7) i++;
+						System.arraycopy(buf, start, buf, i - size, size);
+
+						do {
+							buf[ii++] = (byte) (size | 0x80);
+							size >>>= 7;
+						} while (size > 0x7f);
+					}
+					buf[ii] = (byte) size;
 				}
-				if (length < 0 || length > O.colferListMax)
-					throw new SecurityException(format("colfer: gen.o.f64s length %d exceeds %d elements", length, O.colferListMax));
+			}
+
+			if (this.ob != null) {
+				buf[i++] = (byte) (this.ob ? 35 : 35 | 0x80);
+			}
+
+			if (this.ou32 != null) {
+				int x = this.ou32;
+				if ((x & ~((1 << 21) - 1)) != 0) {
+					buf[i++] = (byte) (36 | 0x80);
+					buf[i++] = (byte) (x >>> 24);
+					buf[i++] = (byte) (x >>> 16);
+					buf[i++] = (byte) (x >>> 8);
+				} else {
+					buf[i++] = (byte) 36;
+					while (x > 0x7f) {
+						buf[i++] = (byte) (x | 0x80);
+						x >>>= 7;
+					}
+				}
+				buf[i++] = (byte) x;
+			}
+
+			if (this.oi64 != null) {
+				long x = this.oi64;
+				if (x < 0) {
+					x = -x;
+					buf[i++] = (byte) (37 | 0x80);
+				} else
+					buf[i++] = (byte) 37;
+				for (int n = 0; n < 8 && (x & ~0x7fL) != 0; n++) {
+					buf[i++] = (byte) (x | 0x80);
+					x >>>= 7;
+				}
+				buf[i++] = (byte) x;
+			}
+
+			if (this.of64 != null) {
+				buf[i++] = (byte) 38;
+				long x = Double.doubleToRawLongBits(this.of64);
+				buf[i++] = (byte) (x >>> 56);
+				buf[i++] = (byte) (x >>> 48);
+				buf[i++] = (byte) (x >>> 40);
+				buf[i++] = (byte) (x >>> 32);
+				buf[i++] = (byte) (x >>> 24);
+				buf[i++] = (byte) (x >>> 16);
+				buf[i++] = (byte) (x >>> 8);
+				buf[i++] = (byte) (x);
+			}
+
+			if (this.u != null) {
+				buf[i++] = (byte) 39;
+				if (this.u instanceof O) {
+					buf[i++] = (byte) 0;
+					if (this.u == this)
+						throw new ColferDepthException("colfer: gen.o.u makes a reference cycle");
+					i = ((O) this.u).marshal(buf, i, nested);
+				} else if (this.u instanceof Point) {
+					buf[i++] = (byte) 1;
+					i = ((Point) this.u).marshal(buf, i, nested);
+				} else {
+					throw new IllegalStateException(format("colfer: gen.o.u type %s is not a member", this.u.getClass().getName()));
+				}
+			}
+
+			buf[i++] = (byte) 0x7f;
+			return i;
+		} catch (ArrayIndexOutOfBoundsException e) {
+			if (i - offset > limits.sizeMax)
+				throw new IllegalStateException(format("colfer: gen.o exceeds %d bytes", limits.sizeMax));
+			if (i > buf.length) throw new BufferOverflowException();
+			throw e;
+		}
+	}
+
+	/**
+	 * Deserializes the object.
+	 * @param buf the data source.
+	 * @param offset the initial index for {@code buf}, inclusive.
+	 * @return the final index for {@code buf}, exclusive.
+	 * @throws BufferUnderflowException when {@code buf} is incomplete. (EOF)
+	 * @throws SecurityException on an upper limit breach defined by either {@link #colferSizeMax} or {@link #colferListMax}.
+	 * @throws ColferDepthException on an upper limit breach defined by {@link #colferDepthMax}.
+	 * @throws InputMismatchException when the data does not match this object's schema.
+	 */
+	public int unmarshal(byte[] buf, int offset) {
+		return unmarshal(buf, offset, buf.length);
+	}
+
+	/**
+	 * Deserializes the object.
+	 * @param buf the data source.
+	 * @param offset the initial index for {@code buf}, inclusive.
+	 * @param end the index limit for {@code buf}, exclusive.
+	 * @return the final index for {@code buf}, exclusive.
+	 * @throws BufferUnderflowException when {@code buf} is incomplete. (EOF)
+	 * @throws SecurityException on an upper limit breach defined by either {@link #colferSizeMax} or {@link #colferListMax}.
+	 * @throws ColferDepthException on an upper limit breach defined by {@link #colferDepthMax}.
+	 * @throws InputMismatchException when the data does not match this object's schema.
+	 */
+	public int unmarshal(byte[] buf, int offset, int end) {
+		return unmarshal(buf, offset, end, colferDefaults());
+	}
+
+	/**
+	 * Deserializes the object like {@link #unmarshal(byte[], int, int)} does,
+	 * with explicit limits instead of the {@link #colferDefaults() defaults}.
+	 * @param buf the data source.
+	 * @param offset the initial index for {@code buf}, inclusive.
+	 * @param end the index limit for {@code buf}, exclusive.
+	 * @param limits the upper boundaries.
+	 * @return the final index for {@code buf}, exclusive.
+	 * @throws BufferUnderflowException when {@code buf} is incomplete. (EOF)
+	 * @throws SecurityException on an upper limit breach.
+	 * @throws ColferDepthException on an upper limit breach defined by {@link ColferLimits#depthMax}.
+	 * @throws InputMismatchException when the data does not match this object's schema.
+	 */
+	public int unmarshal(byte[] buf, int offset, int end, ColferLimits limits) {
+		if (limits.depthMax < 1)
+			throw new ColferDepthException("colfer: gen.o exceeds the nesting depth limit");
+		// nested data structures are one level deeper
+		ColferLimits nested = new ColferLimits(limits.sizeMax, limits.listMax, limits.depthMax - 1);
+		if (end > buf.length) end = buf.length;
+		int i = offset;
+
+		try {
+			byte header = buf[i++];
+
+			if (header == (byte) 0) {
+				this.b = true;
+				header = buf[i++];
+			}
+
+			if (header == (byte) 1) {
+				int x = 0;
+				for (int shift = 0; true; shift += 7) {
+					byte b = buf[i++];
+					x |= (b & 0x7f) << shift;
+					if (shift == 28 || b >= 0) break;
+				}
+				this.u32 = x;
+				header = buf[i++];
+			} else if (header == (byte) (1 | 0x80)) {
+				this.u32 = (buf[i++] & 0xff) << 24 | (buf[i++] & 0xff) << 16 | (buf[i++] & 0xff) << 8 | (buf[i++] & 0xff);
+				header = buf[i++];
+			}
+
+			if (header == (byte) 2) {
+				long x = 0;
+				for (int shift = 0; true; shift += 7) {
+					byte b = buf[i++];
+					if (shift == 56 || b >= 0) {
+						x |= (b & 0xffL) << shift;
+						break;
+					}
+					x |= (b & 0x7fL) << shift;
+				}
+				this.u64 = x;
+				header = buf[i++];
+			} else if (header == (byte) (2 | 0x80)) {
+				this.u64 = (buf[i++] & 0xffL) << 56 | (buf[i++] & 0xffL) << 48 | (buf[i++] & 0xffL) << 40 | (buf[i++] & 0xffL) << 32
+					| (buf[i++] & 0xffL) << 24 | (buf[i++] & 0xffL) << 16 | (buf[i++] & 0xffL) << 8 | (buf[i++] & 0xffL);
+				header = buf[i++];
+			}
+
+			if (header == (byte) 3) {
+				int x = 0;
+				for (int shift = 0; true; shift += 7) {
+					byte b = buf[i++];
+					x |= (b & 0x7f) << shift;
+					if (shift == 28 || b >= 0) break;
+				}
+				this.i32 = x;
+				header = buf[i++];
+			} else if (header == (byte) (3 | 0x80)) {
+				int x = 0;
+				for (int shift = 0; true; shift += 7) {
+					byte b = buf[i++];
+					x |= (b & 0x7f) << shift;
+					if (shift == 28 || b >= 0) break;
+				}
+				this.i32 = -x;
+				header = buf[i++];
+			}
+
+			if (header == (byte) 4) {
+				long x = 0;
+				for (int shift = 0; true; shift += 7) {
+					byte b = buf[i++];
+					if (shift == 56 || b >= 0) {
+						x |= (b & 0xffL) << shift;
+						break;
+					}
+					x |= (b & 0x7fL) << shift;
+				}
+				this.i64 = x;
+				header = buf[i++];
+			} else if (header == (byte) (4 | 0x80)) {
+				long x = 0;
+				for (int shift = 0; true; shift += 7) {
+					byte b = buf[i++];
+					if (shift == 56 || b >= 0) {
+						x |= (b & 0xffL) << shift;
+						break;
+					}
+					x |= (b & 0x7fL) << shift;
+				}
+				this.i64 = -x;
+				header = buf[i++];
+			}
+
+			if (header == (byte) 5) {
+				int x = (buf[i++] & 0xff) << 24 | (buf[i++] & 0xff) << 16 | (buf[i++] & 0xff) << 8 | (buf[i++] & 0xff);
+				this.f32 = Float.intBitsToFloat(x);
+				header = buf[i++];
+			}
+
+			if (header == (byte) 6) {
+				long x = (buf[i++] & 0xffL) << 56 | (buf[i++] & 0xffL) << 48 | (buf[i++] & 0xffL) << 40 | (buf[i++] & 0xffL) << 32
+					| (buf[i++] & 0xffL) << 24 | (buf[i++] & 0xffL) << 16 | (buf[i++] & 0xffL) << 8 | (buf[i++] & 0xffL);
+				this.f64 = Double.longBitsToDouble(x);
+				header = buf[i++];
+			}
+
+			if (header == (byte) 7) {
+				long s = (buf[i++] & 0xffL) << 24 | (buf[i++] & 0xffL) << 16 | (buf[i++] & 0xffL) << 8 | (buf[i++] & 0xffL);
+				long ns = (buf[i++] & 0xffL) << 24 | (buf[i++] & 0xffL) << 16 | (buf[i++] & 0xffL) << 8 | (buf[i++] & 0xffL);
+				this.t = java.time.Instant.ofEpochSecond(s, ns);
+				header = buf[i++];
+			} else if (header == (byte) (7 | 0x80)) {
+				long s = (buf[i++] & 0xffL) << 56 | (buf[i++] & 0xffL) << 48 | (buf[i++] & 0xffL) << 40 | (buf[i++] & 0xffL) << 32
+					| (buf[i++] & 0xffL) << 24 | (buf[i++] & 0xffL) << 16 | (buf[i++] & 0xffL) << 8 | (buf[i++] & 0xffL);
+				long ns = (buf[i++] & 0xffL) << 24 | (buf[i++] & 0xffL) << 16 | (buf[i++] & 0xffL) << 8 | (buf[i++] & 0xffL);
+				this.t = java.time.Instant.ofEpochSecond(s, ns);
+				header = buf[i++];
+			}
+
+			if (header == (byte) 8) {
+				int size = 0;
+				for (int shift = 0; true; shift += 7) {
+					byte b = buf[i++];
+					size |= (b & 0x7f) << shift;
+					if (shift == 28 || b >= 0) break;
+				}
+				if (size < 0 || size > limits.sizeMax)
+					throw new SecurityException(format("colfer: gen.o.s size %d exceeds %d UTF-8 bytes", size, limits.sizeMax));
+
+				int start = i;
+				i += size;
+				this.s = new String(buf, start, size, StandardCharsets.UTF_8);
+				header = buf[i++];
+			}
+
+			if (header == (byte) 9) {
+				int size = 0;
+				for (int shift = 0; true; shift += 7) {
+					byte b = buf[i++];
+					size |= (b & 0x7f) << shift;
+					if (shift == 28 || b >= 0) break;
+				}
+				if (size < 0 || size > limits.sizeMax)
+					throw new SecurityException(format("colfer: gen.o.a size %d exceeds %d bytes", size, limits.sizeMax));
+
+				this.a = new byte[size];
+				int start = i;
+				i += size;
+				System.arraycopy(buf, start, this.a, 0, size);
+
+				header = buf[i++];
+			}
+
+			if (header == (byte) 10) {
+				this.o = new O();
+				i = this.o.unmarshal(buf, i, end, nested);
+				header = buf[i++];
+			}
+
+			if (header == (byte) 11) {
+				int length = 0;
+				for (int shift = 0; true; shift += 7) {
+					byte b = buf[i++];
+					length |= (b & 0x7f) << shift;
+					if (shift == 28 || b >= 0) break;
+				}
+				if (length < 0 || length > limits.listMax)
+					throw new SecurityException(format("colfer: gen.o.os length %d exceeds %d elements", length, limits.listMax));
+
+				O[] a = new O[length];
+				for (int ai = 0; ai < length; ai++) {
+					O o = new O();
+					i = o.unmarshal(buf, i, end, nested);
+					a[ai] = o;
+				}
+				this.os = a;
+				header = buf[i++];
+			}
+
+			if (header == (byte) 12) {
+				int length = 0;
+				for (int shift = 0; true; shift += 7) {
+					byte b = buf[i++];
+					length |= (b & 0x7f) << shift;
+					if (shift == 28 || b >= 0) break;
+				}
+				if (length < 0 || length > limits.listMax)
+					throw new SecurityException(format("colfer: gen.o.ss length %d exceeds %d elements", length, limits.listMax));
+
+				String[] a = new String[length];
+				for (int ai = 0; ai < length; ai++) {
+					int size = 0;
+					for (int shift = 0; true; shift += 7) {
+						byte b = buf[i++];
+						size |= (b & 0x7f) << shift;
+						if (shift == 28 || b >= 0) break;
+					}
+					if (size < 0 || size > limits.sizeMax)
+						throw new SecurityException(format("colfer: gen.o.ss[%d] size %d exceeds %d UTF-8 bytes", ai, size, limits.sizeMax));
+
+					int start = i;
+					i += size;
+					a[ai] = new String(buf, start, size, StandardCharsets.UTF_8);
+				}
+				this.ss = a;
+				header = buf[i++];
+			}
+
+			if (header == (byte) 13) {
+				int length = 0;
+				for (int shift = 0; true; shift += 7) {
+					byte b = buf[i++];
+					length |= (b & 0x7f) << shift;
+					if (shift == 28 || b >= 0) break;
+				}
+				if (length < 0 || length > limits.listMax)
+					throw new SecurityException(format("colfer: gen.o.as length %d exceeds %d elements", length, limits.listMax));
+
+				byte[][] a = new byte[length][];
+				for (int ai = 0; ai < length; ai++) {
+					int size = 0;
+					for (int shift = 0; true; shift += 7) {
+						byte b = buf[i++];
+						size |= (b & 0x7f) << shift;
+						if (shift == 28 || b >= 0) break;
+					}
+					if (size < 0 || size > limits.sizeMax)
+						throw new SecurityException(format("colfer: gen.o.as[%d] size %d exceeds %d bytes", ai, size, limits.sizeMax));
+
+					byte[] e = new byte[size];
+					int start = i;
+					i += size;
+					System.arraycopy(buf, start, e, 0, size);
+					a[ai] = e;
+				}
+				this.as = a;
+
+				header = buf[i++];
+			}
+
+			if (header == (byte) 14) {
+				this.u8 = buf[i++];
+				header = buf[i++];
+			}
+
+			if (header == (byte) 15) {
+				this.u16 = (short) ((buf[i++] & 0xff) << 8 | (buf[i++] & 0xff));
+				header = buf[i++];
+			} else if (header == (byte) (15 | 0x80)) {
+				this.u16 = (short) (buf[i++] & 0xff);
+				header = buf[i++];
+			}
+
+			if (header == (byte) 16) {
+				int length = 0;
+				for (int shift = 0; true; shift += 7) {
+					byte b = buf[i++];
+					length |= (b & 0x7f) << shift;
+					if (shift == 28 || b >= 0) break;
+				}
+				if (length < 0 || length > limits.listMax)
+					throw new SecurityException(format("colfer: gen.o.f32s length %d exceeds %d elements", length, limits.listMax));
+
+				float[] a = new float[length];
+				for (int ai = 0; ai < length; ai++) {
+					int x = (buf[i++] & 0xff) << 24 | (buf[i++] & 0xff) << 16 | (buf[i++] & 0xff) << 8 | (buf[i++] & 0xff);
+					a[ai] = Float.intBitsToFloat(x);
+				}
+				this.f32s = a;
+				header = buf[i++];
+			}
+
+			if (header == (byte) 17) {
+				int length = 0;
+				for (int shift = 0; true; shift += 7) {
+					byte b = buf[i++];
+					length |= (b & 0x7f) << shift;
+					if (shift == 28 || b >= 0) break;
+				}
+				if (length < 0 || length > limits.listMax)
+					throw new SecurityException(format("colfer: gen.o.f64s length %d exceeds %d elements", length, limits.listMax));
+
+				double[] a = new double[length];
+				for (int ai = 0; ai < length; ai++) {
+					long x = (buf[i++] & 0xffL) << 56 | (buf[i++] & 0xffL) << 48 | (buf[i++] & 0xffL) << 40 | (buf[i++] & 0xffL) << 32
+						| (buf[i++] & 0xffL) << 24 | (buf[i++] & 0xffL) << 16 | (buf[i++] & 0xffL) << 8 | (buf[i++] & 0xffL);
+					a[ai] = Double.longBitsToDouble(x);
+				}
+				this.f64s = a;
+				header = buf[i++];
+			}
+
+			if (header == (byte) 18) {
+				this.e = buf[i++];
+				header = buf[i++];
+			}
+
+			if (header == (byte) 19) {
+				int length = 0;
+				for (int shift = 0; true; shift += 7) {
+					byte b = buf[i++];
+					length |= (b & 0x7f) << shift;
+					if (shift == 28 || b >= 0) break;
+				}
+				if (length < 0 || length > limits.listMax)
+					throw new SecurityException(format("colfer: gen.o.bs length %d exceeds %d elements", length, limits.listMax));
+
+				boolean[] a = new boolean[length];
+				for (int ai = 0; ai < length; ai++) a[ai] = buf[i++] != 0;
+				this.bs = a;
+				header = buf[i++];
+			}
+
+			if (header == (byte) 20) {
+				int length = 0;
+				for (int shift = 0; true; shift += 7) {
+					byte b = buf[i++];
+					length |= (b & 0x7f) << shift;
+					if (shift == 28 || b >= 0) break;
+				}
+				if (length < 0 || length > limits.listMax)
+					throw new SecurityException(format("colfer: gen.o.u8s length %d exceeds %d elements", length, limits.listMax));
+
+				byte[] a = new byte[length];
+				int start = i;
+				i += length;
+				System.arraycopy(buf, start, a, 0, length);
+				this.u8s = a;
+				header = buf[i++];
+			}
+
+			if (header == (byte) 21) {
+				int length = 0;
+				for (int shift = 0; true; shift += 7) {
+					byte b = buf[i++];
+					length |= (b & 0x7f) << shift;
+					if (shift == 28 || b >= 0) break;
+				}
+				if (length < 0 || length > limits.listMax)
+					throw new SecurityException(format("colfer: gen.o.u16s length %d exceeds %d elements", length, limits.listMax));
+
+				short[] a = new short[length];
+				for (int ai = 0; ai < length; ai++) {
+					int x = 0;
+					for (int shift = 0; true; shift += 7) {
+						byte b = buf[i++];
+						x |= (b & 0x7f) << shift;
+						if (shift == 28 || b >= 0) break;
+					}
+					a[ai] = (short) x;
+				}
+				this.u16s = a;
+				header = buf[i++];
+			}
+
+			if (header == (byte) 22) {
+				int length = 0;
+				for (int shift = 0; true; shift += 7) {
+					byte b = buf[i++];
+					length |= (b & 0x7f) << shift;
+					if (shift == 28 || b >= 0) break;
+				}
+				if (length < 0 || length > limits.listMax)
+					throw new SecurityException(format("colfer: gen.o.u32s length %d exceeds %d elements", length, limits.listMax));
+
+				int[] a = new int[length];
+				for (int ai = 0; ai < length; ai++) {
+					int x = 0;
+					for (int shift = 0; true; shift += 7) {
+						byte b = buf[i++];
+						x |= (b & 0x7f) << shift;
+						if (shift == 28 || b >= 0) break;
+					}
+					a[ai] = x;
+				}
+				this.u32s = a;
+				header = buf[i++];
+			}
+
+			if (header == (byte) 23) {
+				int length = 0;
+				for (int shift = 0; true; shift += 7) {
+					byte b = buf[i++];
+					length |= (b & 0x7f) << shift;
+					if (shift == 28 || b >= 0) break;
+				}
+				if (length < 0 || length > limits.listMax)
+					throw new SecurityException(format("colfer: gen.o.u64s length %d exceeds %d elements", length, limits.listMax));
+
+				long[] a = new long[length];
+				for (int ai = 0; ai < length; ai++) {
+					long x = 0;
+					for (int shift = 0; true; shift += 7) {
+						byte b = buf[i++];
+						if (shift == 56 || b >= 0) {
+							x |= (b & 0xffL) << shift;
+							break;
+						}
+						x |= (b & 0x7fL) << shift;
+					}
+					a[ai] = x;
+				}
+				this.u64s = a;
+				header = buf[i++];
+			}
+
+			if (header == (byte) 24) {
+				int length = 0;
+				for (int shift = 0; true; shift += 7) {
+					byte b = buf[i++];
+					length |= (b & 0x7f) << shift;
+					if (shift == 28 || b >= 0) break;
+				}
+				if (length < 0 || length > limits.listMax)
+					throw new SecurityException(format("colfer: gen.o.i32s length %d exceeds %d elements", length, limits.listMax));
+
+				int[] a = new int[length];
+				for (int ai = 0; ai < length; ai++) {
+					int x = 0;
+					for (int shift = 0; true; shift += 7) {
+						byte b = buf[i++];
+						x |= (b & 0x7f) << shift;
+						if (shift == 28 || b >= 0) break;
+					}
+					a[ai] = (x >>> 1) ^ -(x & 1);
+				}
+				this.i32s = a;
+				header = buf[i++];
+			}
+
+			if (header == (byte) 25) {
+				int length = 0;
+				for (int shift = 0; true; shift += 7) {
+					byte b = buf[i++];
+					length |= (b & 0x7f) << shift;
+					if (shift == 28 || b >= 0) break;
+				}
+				if (length < 0 || length > limits.listMax)
+					throw new SecurityException(format("colfer: gen.o.i64s length %d exceeds %d elements", length, limits.listMax));
+
+				long[] a = new long[length];
+				for (int ai = 0; ai < length; ai++) {
+					long x = 0;
+					for (int shift = 0; true; shift += 7) {
+						byte b = buf[i++];
+						if (shift == 56 || b >= 0) {
+							x |= (b & 0xffL) << shift;
+							break;
+						}
+						x |= (b & 0x7fL) << shift;
+					}
+					a[ai] = (x >>> 1) ^ -(x & 1);
+				}
+				this.i64s = a;
+				header = buf[i++];
+			}
+
+			if (header == (byte) 26) {
+				int length = 0;
+				for (int shift = 0; true; shift += 7) {
+					byte b = buf[i++];
+					length |= (b & 0x7f) << shift;
+					if (shift == 28 || b >= 0) break;
+				}
+				if (length < 0 || length > limits.listMax)
+					throw new SecurityException(format("colfer: gen.o.es length %d exceeds %d elements", length, limits.listMax));
+
+				byte[] a = new byte[length];
+				int start = i;
+				i += length;
+				System.arraycopy(buf, start, a, 0, length);
+				this.es = a;
+				header = buf[i++];
+			}
+
+			if (header == (byte) 27) {
+				int length = 0;
+				for (int shift = 0; true; shift += 7) {
+					byte b = buf[i++];
+					length |= (b & 0x7f) << shift;
+					if (shift == 28 || b >= 0) break;
+				}
+				if (length < 0 || length > limits.listMax)
+					throw new SecurityException(format("colfer: gen.o.mt length %d exceeds %d elements", length, limits.listMax));
+
+				java.util.Map<String, String> m = new java.util.HashMap<>();
+				String last = null;
+				for (int mi = 0; mi < length; mi++) {
+					int start = i;
+					int kx = 0;
+					for (int shift = 0; true; shift += 7) {
+						byte b = buf[i++];
+						kx |= (b & 0x7f) << shift;
+						if (shift == 28 || b >= 0) break;
+					}
+					if (kx < 0 || kx > limits.sizeMax)
+						throw new SecurityException(format("colfer: gen.o.mt key size %d exceeds %d UTF-8 bytes", kx, limits.sizeMax));
+					int kStart = i;
+					i += kx;
+					String k = new String(buf, kStart, kx, StandardCharsets.UTF_8);
+					if (last != null && _orderMt.compare(last, k) >= 0)
+						throw new InputMismatchException(format("colfer: gen.o.mt key at byte %d out of order", start));
+					last = k;
+
+					int vx = 0;
+					for (int shift = 0; true; shift += 7) {
+						byte b = buf[i++];
+						vx |= (b & 0x7f) << shift;
+						if (shift == 28 || b >= 0) break;
+					}
+					if (vx < 0 || vx > limits.sizeMax)
+						throw new SecurityException(format("colfer: gen.o.mt value size %d exceeds %d UTF-8 bytes", vx, limits.sizeMax));
+					int vStart = i;
+					i += vx;
+					String v = new String(buf, vStart, vx, StandardCharsets.UTF_8);
+					m.put(k, v);
+				}
+				this.mt = m;
+				header = buf[i++];
+			}
+
+			if (header == (byte) 28) {
+				int length = 0;
+				for (int shift = 0; true; shift += 7) {
+					byte b = buf[i++];
+					length |= (b & 0x7f) << shift;
+					if (shift == 28 || b >= 0) break;
+				}
+				if (length < 0 || length > limits.listMax)
+					throw new SecurityException(format("colfer: gen.o.mu length %d exceeds %d elements", length, limits.listMax));
+
+				java.util.Map<Integer, Long> m = new java.util.HashMap<>();
+				Integer last = null;
+				for (int mi = 0; mi < length; mi++) {
+					int start = i;
+					int kx = 0;
+					for (int shift = 0; true; shift += 7) {
+						byte b = buf[i++];
+						kx |= (b & 0x7f) << shift;
+						if (shift == 28 || b >= 0) break;
+					}
+					Integer k = kx;
+					if (last != null && _orderMu.compare(last, k) >= 0)
+						throw new InputMismatchException(format("colfer: gen.o.mu key at byte %d out of order", start));
+					last = k;
+
+					long vx = 0;
+					for (int shift = 0; true; shift += 7) {
+						byte b = buf[i++];
+						if (shift == 56 || b >= 0) {
+							vx |= (b & 0xffL) << shift;
+							break;
+						}
+						vx |= (b & 0x7fL) << shift;
+					}
+					Long v = (vx >>> 1) ^ -(vx & 1);
+					m.put(k, v);
+				}
+				this.mu = m;
+				header = buf[i++];
+			}
+
+			if (header == (byte) 29) {
+				int length = 0;
+				for (int shift = 0; true; shift += 7) {
+					byte b = buf[i++];
+					length |= (b & 0x7f) << shift;
+					if (shift == 28 || b >= 0) break;
+				}
+				if (length < 0 || length > limits.listMax)
+					throw new SecurityException(format("colfer: gen.o.mi length %d exceeds %d elements", length, limits.listMax));
+
+				java.util.Map<Long, byte[]> m = new java.util.HashMap<>();
+				Long last = null;
+				for (int mi = 0; mi < length; mi++) {
+					int start = i;
+					long kx = 0;
+					for (int shift = 0; true; shift += 7) {
+						byte b = buf[i++];
+						if (shift == 56 || b >= 0) {
+							kx |= (b & 0xffL) << shift;
+							break;
+						}
+						kx |= (b & 0x7fL) << shift;
+					}
+					Long k = (kx >>> 1) ^ -(kx & 1);
+					if (last != null && _orderMi.compare(last, k) >= 0)
+						throw new InputMismatchException(format("colfer: gen.o.mi key at byte %d out of order", start));
+					last = k;
+
+					int vx = 0;
+					for (int shift = 0; true; shift += 7) {
+						byte b = buf[i++];
+						vx |= (b & 0x7f) << shift;
+						if (shift == 28 || b >= 0) break;
+					}
+					if (vx < 0 || vx > limits.sizeMax)
+						throw new SecurityException(format("colfer: gen.o.mi value size %d exceeds %d bytes", vx, limits.sizeMax));
+					int vStart = i;
+					i += vx;
+					byte[] v = new byte[vx];
+					System.arraycopy(buf, vStart, v, 0, vx);
+					m.put(k, v);
+				}
+				this.mi = m;
+				header = buf[i++];
+			}
+
+			if (header == (byte) 30) {
+				int length = 0;
+				for (int shift = 0; true; shift += 7) {
+					byte b = buf[i++];
+					length |= (b & 0x7f) << shift;
+					if (shift == 28 || b >= 0) break;
+				}
+				if (length < 0 || length > limits.listMax)
+					throw new SecurityException(format("colfer: gen.o.mo length %d exceeds %d elements", length, limits.listMax));
+
+				java.util.Map<String, O> m = new java.util.HashMap<>();
+				String last = null;
+				for (int mi = 0; mi < length; mi++) {
+					int start = i;
+					int kx = 0;
+					for (int shift = 0; true; shift += 7) {
+						byte b = buf[i++];
+						kx |= (b & 0x7f) << shift;
+						if (shift == 28 || b >= 0) break;
+					}
+					if (kx < 0 || kx > limits.sizeMax)
+						throw new SecurityException(format("colfer: gen.o.mo key size %d exceeds %d UTF-8 bytes", kx, limits.sizeMax));
+					int kStart = i;
+					i += kx;
+					String k = new String(buf, kStart, kx, StandardCharsets.UTF_8);
+					if (last != null && _orderMo.compare(last, k) >= 0)
+						throw new InputMismatchException(format("colfer: gen.o.mo key at byte %d out of order", start));
+					last = k;
+
+					O v = new O();
+					i = v.unmarshal(buf, i, end, nested);
+					m.put(k, v);
+				}
+				this.mo = m;
+				header = buf[i++];
+			}
+
+			if (header == (byte) 32) {
+				this.gap = true;
+				header = buf[i++];
+			}
+
+			if (header == (byte) 33) {
+				int size = 0;
+				for (int shift = 0; true; shift += 7) {
+					byte b = buf[i++];
+					size |= (b & 0x7f) << shift;
+					if (shift == 28 || b >= 0) break;
+				}
+				if (size < 0 || size > 4)
+					throw new SecurityException(format("colfer: gen.o.host size %d exceeds %d UTF-8 bytes", size, 4));
+
+				int start = i;
+				i += size;
+				this.host = new String(buf, start, size, StandardCharsets.UTF_8);
+				header = buf[i++];
+			}
+
+			if (header == (byte) 34) {
+				int length = 0;
+				for (int shift = 0; true; shift += 7) {
+					byte b = buf[i++];
+					length |= (b & 0x7f) << shift;
+					if (shift == 28 || b >= 0) break;
+				}
+				if (length < 0 || length > 2)
+					throw new SecurityException(format("colfer: gen.o.tags length %d exceeds %d elements", length, 2));
+
+				String[] a = new String[length];
+				for (int ai = 0; ai < length; ai++) {
+					int size = 0;
+					for (int shift = 0; true; shift += 7) {
+						byte b = buf[i++];
+						size |= (b & 0x7f) << shift;
+						if (shift == 28 || b >= 0) break;
+					}
+					if (size < 0 || size > 1)
+						throw new SecurityException(format("colfer: gen.o.tags[%d] size %d exceeds %d UTF-8 bytes", ai, size, 1));
+
+					int start = i;
+					i += size;
+					a[ai] = new String(buf, start, size, StandardCharsets.UTF_8);
+				}
+				this.tags = a;
+				header = buf[i++];
+			}
+
+			if (header == (byte) 35) {
+				this.ob = true;
+				header = buf[i++];
+			} else if (header == (byte) (35 | 0x80)) {
+				this.ob = false;
+				header = buf[i++];
+			}
+
+			if (header == (byte) 36) {
+				int x = 0;
+				for (int shift = 0; true; shift += 7) {
+					byte b = buf[i++];
+					x |= (b & 0x7f) << shift;
+					if (shift == 28 || b >= 0) break;
+				}
+				this.ou32 = x;
+				header = buf[i++];
+			} else if (header == (byte) (36 | 0x80)) {
+				this.ou32 = (buf[i++] & 0xff) << 24 | (buf[i++] & 0xff) << 16 | (buf[i++] & 0xff) << 8 | (buf[i++] & 0xff);
+				header = buf[i++];
+			}
+
+			if (header == (byte) 37) {
+				long x = 0;
+				for (int shift = 0; true; shift += 7) {
+					byte b = buf[i++];
+					if (shift == 56 || b >= 0) {
+						x |= (b & 0xffL) << shift;
+						break;
+					}
+					x |= (b & 0x7fL) << shift;
+				}
+				this.oi64 = x;
+				header = buf[i++];
+			} else if (header == (byte) (37 | 0x80)) {
+				long x = 0;
+				for (int shift = 0; true; shift += 7) {
+					byte b = buf[i++];
+					if (shift == 56 || b >= 0) {
+						x |= (b & 0xffL) << shift;
+						break;
+					}
+					x |= (b & 0x7fL) << shift;
+				}
+				this.oi64 = -x;
+				header = buf[i++];
+			}
+
+			if (header == (byte) 38) {
+				long x = (buf[i++] & 0xffL) << 56 | (buf[i++] & 0xffL) << 48 | (buf[i++] & 0xffL) << 40 | (buf[i++] & 0xffL) << 32
+					| (buf[i++] & 0xffL) << 24 | (buf[i++] & 0xffL) << 16 | (buf[i++] & 0xffL) << 8 | (buf[i++] & 0xffL);
+				this.of64 = Double.longBitsToDouble(x);
+				header = buf[i++];
+			}
+
+			if (header == (byte) 39) {
+				switch (buf[i++]) {
+				case 0: {
+					O o = new O();
+					i = o.unmarshal(buf, i, end, nested);
+					this.u = o;
+					break;
+				}
+				case 1: {
+					Point o = new Point();
+					i = o.unmarshal(buf, i, end, nested);
+					this.u = o;
+					break;
+				}
+				default:
+					throw new InputMismatchException(format("colfer: unknown gen.o.u member at byte %d", i - 1));
+				}
+				header = buf[i++];
+			}
+
+			if (header != (byte) 0x7f)
+				throw new InputMismatchException(format("colfer: unknown header at byte %d", i - 1));
+		} finally {
+			if (i > end && end - offset < limits.sizeMax) throw new BufferUnderflowException();
+			if (i < 0 || i - offset > limits.sizeMax)
+				throw new SecurityException(format("colfer: gen.o exceeds %d bytes", limits.sizeMax));
+			if (i > end) throw new BufferUnderflowException();
+		}
+
+		return i;
+	}
+
+	// {@link Serializable} version number.
+	private static final long serialVersionUID = 39L;
+
+	// {@link Serializable} Colfer extension.
+	private void writeObject(ObjectOutputStream out) throws IOException {
+		// TODO: better size estimation
+		byte[] buf = new byte[1024];
+		int n;
+		while (true) try {
+			n = marshal(buf, 0);
+			break;
+		} catch (BufferUnderflowException e) {
+			buf = new byte[4 * buf.length];
+		}
+
+		out.writeInt(n);
+		out.write(buf, 0, n);
+	}
+
+	// {@link Serializable} Colfer extension.
+	private void readObject(ObjectInputStream in) throws ClassNotFoundException, IOException {
+		init();
+
+		int n = in.readInt();
+		byte[] buf = new byte[n];
+		in.readFully(buf);
+		unmarshal(buf, 0);
+	}
+
+	// {@link Serializable} Colfer extension.
+	private void readObjectNoData() throws ObjectStreamException {
+		init();
+	}
+
+	/**
+	 * Serializes the object as JSON. The JSON object has the fields present
+	 * in the serial only, in order of appearance, with the names from the
+	 * schema. Integers beyond 2^53 - 1 in magnitude are written as a JSON
+	 * string, as JavaScript can not hold them exactly. The mapping matches
+	 * the generated Go and JavaScript code.
+	 * @return the JSON text.
+	 * @throws IllegalStateException when a union holds no member, or when a map has a {@code null} key.
+	 */
+	public String marshalJSON() {
+		return marshalJSON(new StringBuilder()).toString();
+	}
+
+	/**
+	 * Serializes the object as JSON, conform {@link #marshalJSON()}.
+	 * @param buf the data destination.
+	 * @return {@code buf}.
+	 * @throws IllegalStateException when a union holds no member, or when a map has a {@code null} key.
+	 */
+	public StringBuilder marshalJSON(StringBuilder buf) {
+		// each field starts with a comma, of which the first one becomes
+		// the opening brace
+		int start = buf.length();
+
+		if (this.b) {
+			buf.append(",\"b\":");
+			boolean v = this.b;
+			buf.append(v);
+		}
+
+		if (this.u32 != 0) {
+			buf.append(",\"u32\":");
+			int v = this.u32;
+			buf.append(Integer.toUnsignedString(v));
+		}
+
+		if (this.u64 != 0) {
+			buf.append(",\"u64\":");
+			long v = this.u64;
+			ColferJSON.appendLong(buf, v, true);
+		}
+
+		if (this.i32 != 0) {
+			buf.append(",\"i32\":");
+			int v = this.i32;
+			buf.append(v);
+		}
+
+		if (this.i64 != 0) {
+			buf.append(",\"i64\":");
+			long v = this.i64;
+			ColferJSON.appendLong(buf, v, false);
+		}
+
+		if (this.f32 != 0.0f) {
+			buf.append(",\"f32\":");
+			float v = this.f32;
+			ColferJSON.appendNumber(buf, v, true);
+		}
+
+		if (this.f64 != 0.0) {
+			buf.append(",\"f64\":");
+			double v = this.f64;
+			ColferJSON.appendNumber(buf, v, false);
+		}
+
+		if (this.t != null && (this.t.getEpochSecond() != 0 || this.t.getNano() != 0)) {
+			buf.append(",\"t\":");
+			java.time.Instant v = this.t;
+			ColferJSON.appendTimestamp(buf, v);
+		}
+
+		if (! this.s.isEmpty()) {
+			buf.append(",\"s\":");
+			String v = this.s;
+			ColferJSON.appendString(buf, v == null ? "" : v);
+		}
+
+		if (this.a.length != 0) {
+			buf.append(",\"a\":");
+			byte[] v = this.a;
+			ColferJSON.appendBinary(buf, v);
+		}
+
+		if (this.o != null) {
+			buf.append(",\"o\":");
+			O v = this.o;
+			if (v == null) buf.append("{}");
+			else v.marshalJSON(buf);
+		}
+
+		if (this.os.length != 0) {
+			buf.append(",\"os\":[");
+			for (int i = 0; i < this.os.length; i++) {
+				if (i != 0) buf.append(',');
+				O v = this.os[i];
+				if (v == null) buf.append("{}");
+				else v.marshalJSON(buf);
+			}
+			buf.append(']');
+		}
+
+		if (this.ss.length != 0) {
+			buf.append(",\"ss\":[");
+			for (int i = 0; i < this.ss.length; i++) {
+				if (i != 0) buf.append(',');
+				String v = this.ss[i];
+				ColferJSON.appendString(buf, v == null ? "" : v);
+			}
+			buf.append(']');
+		}
+
+		if (this.as.length != 0) {
+			buf.append(",\"as\":[");
+			for (int i = 0; i < this.as.length; i++) {
+				if (i != 0) buf.append(',');
+				byte[] v = this.as[i];
+				ColferJSON.appendBinary(buf, v);
+			}
+			buf.append(']');
+		}
+
+		if (this.u8 != 0) {
+			buf.append(",\"u8\":");
+			byte v = this.u8;
+			buf.append(v & 0xff);
+		}
+
+		if (this.u16 != 0) {
+			buf.append(",\"u16\":");
+			short v = this.u16;
+			buf.append(v & 0xffff);
+		}
+
+		if (this.f32s.length != 0) {
+			buf.append(",\"f32s\":[");
+			for (int i = 0; i < this.f32s.length; i++) {
+				if (i != 0) buf.append(',');
+				float v = this.f32s[i];
+				ColferJSON.appendNumber(buf, v, true);
+			}
+			buf.append(']');
+		}
+
+		if (this.f64s.length != 0) {
+			buf.append(",\"f64s\":[");
+			for (int i = 0; i < this.f64s.length; i++) {
+				if (i != 0) buf.append(',');
+				double v = this.f64s[i];
+				ColferJSON.appendNumber(buf, v, false);
+			}
+			buf.append(']');
+		}
+
+		if (this.e != 0) {
+			buf.append(",\"e\":");
+			byte v = this.e;
+			gen.Color e = gen.Color.valueOf(v);
+			if (e != null) ColferJSON.appendString(buf, e.schemaName);
+			else buf.append(v & 0xff);
+		}
+
+		if (this.bs.length != 0) {
+			buf.append(",\"bs\":[");
+			for (int i = 0; i < this.bs.length; i++) {
+				if (i != 0) buf.append(',');
+				boolean v = this.bs[i];
+				buf.append(v);
+			}
+			buf.append(']');
+		}
+
+		if (this.u8s.length != 0) {
+			buf.append(",\"u8s\":[");
+			for (int i = 0; i < this.u8s.length; i++) {
+				if (i != 0) buf.append(',');
+				byte v = this.u8s[i];
+				buf.append(v & 0xff);
+			}
+			buf.append(']');
+		}
+
+		if (this.u16s.length != 0) {
+			buf.append(",\"u16s\":[");
+			for (int i = 0; i < this.u16s.length; i++) {
+				if (i != 0) buf.append(',');
+				short v = this.u16s[i];
+				buf.append(v & 0xffff);
+			}
+			buf.append(']');
+		}
+
+		if (this.u32s.length != 0) {
+			buf.append(",\"u32s\":[");
+			for (int i = 0; i < this.u32s.length; i++) {
+				if (i != 0) buf.append(',');
+				int v = this.u32s[i];
+				buf.append(Integer.toUnsignedString(v));
+			}
+			buf.append(']');
+		}
+
+		if (this.u64s.length != 0) {
+			buf.append(",\"u64s\":[");
+			for (int i = 0; i < this.u64s.length; i++) {
+				if (i != 0) buf.append(',');
+				long v = this.u64s[i];
+				ColferJSON.appendLong(buf, v, true);
+			}
+			buf.append(']');
+		}
+
+		if (this.i32s.length != 0) {
+			buf.append(",\"i32s\":[");
+			for (int i = 0; i < this.i32s.length; i++) {
+				if (i != 0) buf.append(',');
+				int v = this.i32s[i];
+				buf.append(v);
+			}
+			buf.append(']');
+		}
+
+		if (this.i64s.length != 0) {
+			buf.append(",\"i64s\":[");
+			for (int i = 0; i < this.i64s.length; i++) {
+				if (i != 0) buf.append(',');
+				long v = this.i64s[i];
+				ColferJSON.appendLong(buf, v, false);
+			}
+			buf.append(']');
+		}
+
+		if (this.es.length != 0) {
+			buf.append(",\"es\":[");
+			for (int i = 0; i < this.es.length; i++) {
+				if (i != 0) buf.append(',');
+				byte v = this.es[i];
+				gen.Color e = gen.Color.valueOf(v);
+				if (e != null) ColferJSON.appendString(buf, e.schemaName);
+				else buf.append(v & 0xff);
+			}
+			buf.append(']');
+		}
+
+		if (! this.mt.isEmpty()) {
+			buf.append(",\"mt\":{");
+			String[] keys = this.mt.keySet().toArray(new String[0]);
+			for (String k : keys)
+				if (k == null) throw new IllegalStateException("colfer: gen.o.mt has a null key");
+			java.util.Arrays.sort(keys, _orderMt);
+			for (int i = 0; i < keys.length; i++) {
+				if (i != 0) buf.append(',');
+				ColferJSON.appendString(buf, keys[i]).append(':');
+				String v = this.mt.get(keys[i]);
+				ColferJSON.appendString(buf, v == null ? "" : v);
+			}
+			buf.append('}');
+		}
+
+		if (! this.mu.isEmpty()) {
+			buf.append(",\"mu\":{");
+			Integer[] keys = this.mu.keySet().toArray(new Integer[0]);
+			for (Integer k : keys)
+				if (k == null) throw new IllegalStateException("colfer: gen.o.mu has a null key");
+			java.util.Arrays.sort(keys, _orderMu);
+			for (int i = 0; i < keys.length; i++) {
+				if (i != 0) buf.append(',');
+				buf.append('"').append(Integer.toUnsignedString(keys[i])).append("\":");
+				Long v = this.mu.get(keys[i]);
+				if (v == null) v = 0L;
+				ColferJSON.appendLong(buf, v, false);
+			}
+			buf.append('}');
+		}
+
+		if (! this.mi.isEmpty()) {
+			buf.append(",\"mi\":{");
+			Long[] keys = this.mi.keySet().toArray(new Long[0]);
+			for (Long k : keys)
+				if (k == null) throw new IllegalStateException("colfer: gen.o.mi has a null key");
+			java.util.Arrays.sort(keys, _orderMi);
+			for (int i = 0; i < keys.length; i++) {
+				if (i != 0) buf.append(',');
+				buf.append('"').append(keys[i]).append("\":");
+				byte[] v = this.mi.get(keys[i]);
+				ColferJSON.appendBinary(buf, v);
+			}
+			buf.append('}');
+		}
+
+		if (! this.mo.isEmpty()) {
+			buf.append(",\"mo\":{");
+			String[] keys = this.mo.keySet().toArray(new String[0]);
+			for (String k : keys)
+				if (k == null) throw new IllegalStateException("colfer: gen.o.mo has a null key");
+			java.util.Arrays.sort(keys, _orderMo);
+			for (int i = 0; i < keys.length; i++) {
+				if (i != 0) buf.append(',');
+				ColferJSON.appendString(buf, keys[i]).append(':');
+				O v = this.mo.get(keys[i]);
+				if (v == null) buf.append("{}");
+				else v.marshalJSON(buf);
+			}
+			buf.append('}');
+		}
+
+		if (this.gap) {
+			buf.append(",\"gap\":");
+			boolean v = this.gap;
+			buf.append(v);
+		}
+
+		if (! this.host.isEmpty()) {
+			buf.append(",\"host\":");
+			String v = this.host;
+			ColferJSON.appendString(buf, v == null ? "" : v);
+		}
+
+		if (this.tags.length != 0) {
+			buf.append(",\"tags\":[");
+			for (int i = 0; i < this.tags.length; i++) {
+				if (i != 0) buf.append(',');
+				String v = this.tags[i];
+				ColferJSON.appendString(buf, v == null ? "" : v);
+			}
+			buf.append(']');
+		}
+
+		if (this.ob != null) {
+			buf.append(",\"ob\":");
+			Boolean v = this.ob;
+			buf.append(v);
+		}
+
+		if (this.ou32 != null) {
+			buf.append(",\"ou32\":");
+			Integer v = this.ou32;
+			buf.append(Integer.toUnsignedString(v));
+		}
+
+		if (this.oi64 != null) {
+			buf.append(",\"oi64\":");
+			Long v = this.oi64;
+			ColferJSON.appendLong(buf, v, false);
+		}
+
+		if (this.of64 != null) {
+			buf.append(",\"of64\":");
+			Double v = this.of64;
+			ColferJSON.appendNumber(buf, v, false);
+		}
+
+		if (this.u != null) {
+			buf.append(",\"u\":{\"kind\":");
+			if (this.u instanceof O) {
+				buf.append("\"o\",\"value\":");
+				((O) this.u).marshalJSON(buf);
+			} else if (this.u instanceof Point) {
+				buf.append("\"point\",\"value\":");
+				((Point) this.u).marshalJSON(buf);
+			} else {
+				throw new IllegalStateException(format("colfer: gen.o.u type %s is not a member", this.u.getClass().getName()));
+			}
+			buf.append('}');
+		}
+
+		if (buf.length() == start) buf.append('{');
+		else buf.setCharAt(start, '{');
+		return buf.append('}');
+	}
+
+	/**
+	 * Deserializes the object from JSON, with the mapping of {@link #marshalJSON()}.
+	 * Fields absent in the JSON object, or with a {@code null} value, remain as is.
+	 * @param json the JSON text.
+	 * @return {@code this}.
+	 * @throws IllegalArgumentException when the JSON does not match this object's schema.
+	 */
+	public O unmarshalJSON(String json) {
+		return unmarshalJSON(ColferJSON.object(ColferJSON.parse(json), "gen.o"));
+	}
+
+	/**
+	 * Deserializes the object from a parsed JSON object, conform {@link #unmarshalJSON(String)}.
+	 * @param object the fields with a String, a java.math.BigDecimal, a Boolean,
+	 *  a java.util.List, a java.util.Map or {@code null} as value.
+	 * @return {@code this}.
+	 * @throws IllegalArgumentException when the JSON does not match this object's schema.
+	 */
+	public O unmarshalJSON(java.util.Map<String, ?> object) {
+		for (java.util.Map.Entry<String, ?> field : object.entrySet()) {
+			Object x = field.getValue();
+			if (x == null) continue;
+
+			switch (field.getKey()) {
+			case "b": {
+				this.b = _fromJSONB(x);
+				break;
+			}
+			case "u32": {
+				this.u32 = _fromJSONU32(x);
+				break;
+			}
+			case "u64": {
+				this.u64 = _fromJSONU64(x);
+				break;
+			}
+			case "i32": {
+				this.i32 = _fromJSONI32(x);
+				break;
+			}
+			case "i64": {
+				this.i64 = _fromJSONI64(x);
+				break;
+			}
+			case "f32": {
+				this.f32 = _fromJSONF32(x);
+				break;
+			}
+			case "f64": {
+				this.f64 = _fromJSONF64(x);
+				break;
+			}
+			case "t": {
+				this.t = _fromJSONT(x);
+				break;
+			}
+			case "s": {
+				this.s = _fromJSONS(x);
+				break;
+			}
+			case "a": {
+				this.a = _fromJSONA(x);
+				break;
+			}
+			case "o": {
+				this.o = _fromJSONO(x);
+				break;
+			}
+			case "os": {
+				java.util.List<?> a = ColferJSON.array(x, "gen.o.os");
+				O[] l = new O[a.size()];
+				for (int i = 0; i < l.length; i++) l[i] = _fromJSONOs(a.get(i));
+				this.os = l;
+				break;
+			}
+			case "ss": {
+				java.util.List<?> a = ColferJSON.array(x, "gen.o.ss");
+				String[] l = new String[a.size()];
+				for (int i = 0; i < l.length; i++) l[i] = _fromJSONSs(a.get(i));
+				this.ss = l;
+				break;
+			}
+			case "as": {
+				java.util.List<?> a = ColferJSON.array(x, "gen.o.as");
+				byte[][] l = new byte[a.size()][];
+				for (int i = 0; i < l.length; i++) l[i] = _fromJSONAs(a.get(i));
+				this.as = l;
+				break;
+			}
+			case "u8": {
+				this.u8 = _fromJSONU8(x);
+				break;
+			}
+			case "u16": {
+				this.u16 = _fromJSONU16(x);
+				break;
+			}
+			case "f32s": {
+				java.util.List<?> a = ColferJSON.array(x, "gen.o.f32s");
+				float[] l = new float[a.size()];
+				for (int i = 0; i < l.length; i++) l[i] = _fromJSONF32s(a.get(i));
+				this.f32s = l;
+				break;
+			}
+			case "f64s": {
+				java.util.List<?> a = ColferJSON.array(x, "gen.o.f64s");
+				double[] l = new double[a.size()];
+				for (int i = 0; i < l.length; i++) l[i] = _fromJSONF64s(a.get(i));
+				this.f64s = l;
+				break;
+			}
+			case "e": {
+				this.e = _fromJSONE(x);
+				break;
+			}
+			case "bs": {
+				java.util.List<?> a = ColferJSON.array(x, "gen.o.bs");
+				boolean[] l = new boolean[a.size()];
+				for (int i = 0; i < l.length; i++) l[i] = _fromJSONBs(a.get(i));
+				this.bs = l;
+				break;
+			}
+			case "u8s": {
+				java.util.List<?> a = ColferJSON.array(x, "gen.o.u8s");
+				byte[] l = new byte[a.size()];
+				for (int i = 0; i < l.length; i++) l[i] = _fromJSONU8s(a.get(i));
+				this.u8s = l;
+				break;
+			}
+			case "u16s": {
+				java.util.List<?> a = ColferJSON.array(x, "gen.o.u16s");
+				short[] l = new short[a.size()];
+				for (int i = 0; i < l.length; i++) l[i] = _fromJSONU16s(a.get(i));
+				this.u16s = l;
+				break;
+			}
+			case "u32s": {
+				java.util.List<?> a = ColferJSON.array(x, "gen.o.u32s");
+				int[] l = new int[a.size()];
+				for (int i = 0; i < l.length; i++) l[i] = _fromJSONU32s(a.get(i));
+				this.u32s = l;
+				break;
+			}
+			case "u64s": {
+				java.util.List<?> a = ColferJSON.array(x, "gen.o.u64s");
+				long[] l = new long[a.size()];
+				for (int i = 0; i < l.length; i++) l[i] = _fromJSONU64s(a.get(i));
+				this.u64s = l;
+				break;
+			}
+			case "i32s": {
+				java.util.List<?> a = ColferJSON.array(x, "gen.o.i32s");
+				int[] l = new int[a.size()];
+				for (int i = 0; i < l.length; i++) l[i] = _fromJSONI32s(a.get(i));
+				this.i32s = l;
+				break;
+			}
+			case "i64s": {
+				java.util.List<?> a = ColferJSON.array(x, "gen.o.i64s");
+				long[] l = new long[a.size()];
+				for (int i = 0; i < l.length; i++) l[i] = _fromJSONI64s(a.get(i));
+				this.i64s = l;
+				break;
+			}
+			case "es": {
+				java.util.List<?> a = ColferJSON.array(x, "gen.o.es");
+				byte[] l = new byte[a.size()];
+				for (int i = 0; i < l.length; i++) l[i] = _fromJSONEs(a.get(i));
+				this.es = l;
+				break;
+			}
+			case "mt": {
+				java.util.Map<String, String> m = new java.util.HashMap<>();
+				for (java.util.Map.Entry<String, Object> e : ColferJSON.object(x, "gen.o.mt").entrySet())
+					m.put(e.getKey(), _fromJSONMt(e.getValue()));
+				this.mt = m;
+				break;
+			}
+			case "mu": {
+				java.util.Map<Integer, Long> m = new java.util.HashMap<>();
+				for (java.util.Map.Entry<String, Object> e : ColferJSON.object(x, "gen.o.mu").entrySet())
+					m.put((int) ColferJSON.integer(ColferJSON.key(e.getKey(), "gen.o.mu"), 0, 0xffffffffL, "gen.o.mu"), _fromJSONMu(e.getValue()));
+				this.mu = m;
+				break;
+			}
+			case "mi": {
+				java.util.Map<Long, byte[]> m = new java.util.HashMap<>();
+				for (java.util.Map.Entry<String, Object> e : ColferJSON.object(x, "gen.o.mi").entrySet())
+					m.put(ColferJSON.integer(ColferJSON.key(e.getKey(), "gen.o.mi"), Long.MIN_VALUE, Long.MAX_VALUE, "gen.o.mi"), _fromJSONMi(e.getValue()));
+				this.mi = m;
+				break;
+			}
+			case "mo": {
+				java.util.Map<String, O> m = new java.util.HashMap<>();
+				for (java.util.Map.Entry<String, Object> e : ColferJSON.object(x, "gen.o.mo").entrySet())
+					m.put(e.getKey(), _fromJSONMo(e.getValue()));
+				this.mo = m;
+				break;
+			}
+			case "gap": {
+				this.gap = _fromJSONGap(x);
+				break;
+			}
+			case "host": {
+				this.host = _fromJSONHost(x);
+				break;
+			}
+			case "tags": {
+				java.util.List<?> a = ColferJSON.array(x, "gen.o.tags");
+				String[] l = new String[a.size()];
+				for (int i = 0; i < l.length; i++) l[i] = _fromJSONTags(a.get(i));
+				this.tags = l;
+				break;
+			}
+			case "ob": {
+				this.ob = _fromJSONOb(x);
+				break;
+			}
+			case "ou32": {
+				this.ou32 = _fromJSONOu32(x);
+				break;
+			}
+			case "oi64": {
+				this.oi64 = _fromJSONOi64(x);
+				break;
+			}
+			case "of64": {
+				this.of64 = _fromJSONOf64(x);
+				break;
+			}
+			case "u": {
+				java.util.Map<String, Object> u = ColferJSON.object(x, "gen.o.u");
+				Object kind = u.get("kind");
+				java.util.Map<String, Object> value = ColferJSON.object(u.get("value"), "gen.o.u");
+				if ("o".equals(kind)) this.u = new O().unmarshalJSON(value);
+				else if ("point".equals(kind)) this.u = new Point().unmarshalJSON(value);
+				else throw new IllegalArgumentException(format("colfer: gen.o.u kind %s is not a member of union gen.pick", kind));
+				break;
+			}
+			default:
+				throw new IllegalArgumentException(format("colfer: struct gen.o has no field %s", ColferJSON.quote(field.getKey())));
+			}
+		}
+		return this;
+	}
+
+	// Gets a gen.o.b value, or element, from JSON.
+	private static boolean _fromJSONB(Object x) {
+		return ColferJSON.bool(x, "gen.o.b");
+	}
+
+	// Gets a gen.o.u32 value, or element, from JSON.
+	private static int _fromJSONU32(Object x) {
+		return (int) ColferJSON.integer(x, 0, 0xffffffffL, "gen.o.u32");
+	}
+
+	// Gets a gen.o.u64 value, or element, from JSON.
+	private static long _fromJSONU64(Object x) {
+		return ColferJSON.unsigned64(x, "gen.o.u64");
+	}
+
+	// Gets a gen.o.i32 value, or element, from JSON.
+	private static int _fromJSONI32(Object x) {
+		return (int) ColferJSON.integer(x, Integer.MIN_VALUE, Integer.MAX_VALUE, "gen.o.i32");
+	}
+
+	// Gets a gen.o.i64 value, or element, from JSON.
+	private static long _fromJSONI64(Object x) {
+		return ColferJSON.integer(x, Long.MIN_VALUE, Long.MAX_VALUE, "gen.o.i64");
+	}
+
+	// Gets a gen.o.f32 value, or element, from JSON.
+	private static float _fromJSONF32(Object x) {
+		return (float) ColferJSON.number(x, true, "gen.o.f32");
+	}
+
+	// Gets a gen.o.f64 value, or element, from JSON.
+	private static double _fromJSONF64(Object x) {
+		return ColferJSON.number(x, false, "gen.o.f64");
+	}
+
+	// Gets a gen.o.t value, or element, from JSON.
+	private static java.time.Instant _fromJSONT(Object x) {
+		return ColferJSON.timestamp(x, "gen.o.t");
+	}
+
+	// Gets a gen.o.s value, or element, from JSON.
+	private static String _fromJSONS(Object x) {
+		return ColferJSON.string(x, "gen.o.s");
+	}
+
+	// Gets a gen.o.a value, or element, from JSON.
+	private static byte[] _fromJSONA(Object x) {
+		return ColferJSON.binary(x, "gen.o.a");
+	}
+
+	// Gets a gen.o.o value, or element, from JSON.
+	private static O _fromJSONO(Object x) {
+		return new O().unmarshalJSON(ColferJSON.object(x, "gen.o.o"));
+	}
+
+	// Gets a gen.o.os value, or element, from JSON.
+	private static O _fromJSONOs(Object x) {
+		return new O().unmarshalJSON(ColferJSON.object(x, "gen.o.os"));
+	}
+
+	// Gets a gen.o.ss value, or element, from JSON.
+	private static String _fromJSONSs(Object x) {
+		return ColferJSON.string(x, "gen.o.ss");
+	}
+
+	// Gets a gen.o.as value, or element, from JSON.
+	private static byte[] _fromJSONAs(Object x) {
+		return ColferJSON.binary(x, "gen.o.as");
+	}
+
+	// Gets a gen.o.u8 value, or element, from JSON.
+	private static byte _fromJSONU8(Object x) {
+		return (byte) ColferJSON.integer(x, 0, 0xff, "gen.o.u8");
+	}
+
+	// Gets a gen.o.u16 value, or element, from JSON.
+	private static short _fromJSONU16(Object x) {
+		return (short) ColferJSON.integer(x, 0, 0xffff, "gen.o.u16");
+	}
+
+	// Gets a gen.o.f32s value, or element, from JSON.
+	private static float _fromJSONF32s(Object x) {
+		return (float) ColferJSON.number(x, true, "gen.o.f32s");
+	}
+
+	// Gets a gen.o.f64s value, or element, from JSON.
+	private static double _fromJSONF64s(Object x) {
+		return ColferJSON.number(x, false, "gen.o.f64s");
+	}
+
+	// Gets a gen.o.e value, or element, from JSON.
+	private static byte _fromJSONE(Object x) {
+		if (x instanceof String) {
+			gen.Color e = gen.Color.valueOfSchemaName((String) x);
+			if (e == null) throw new IllegalArgumentException(format("colfer: gen.o.e has no enumeration value %s", ColferJSON.quote((String) x)));
+			return e.value;
+		}
+		return (byte) ColferJSON.integer(x, 0, 0xff, "gen.o.e");
+	}
+
+	// Gets a gen.o.bs value, or element, from JSON.
+	private static boolean _fromJSONBs(Object x) {
+		return ColferJSON.bool(x, "gen.o.bs");
+	}
+
+	// Gets a gen.o.u8s value, or element, from JSON.
+	private static byte _fromJSONU8s(Object x) {
+		return (byte) ColferJSON.integer(x, 0, 0xff, "gen.o.u8s");
+	}
+
+	// Gets a gen.o.u16s value, or element, from JSON.
+	private static short _fromJSONU16s(Object x) {
+		return (short) ColferJSON.integer(x, 0, 0xffff, "gen.o.u16s");
+	}
+
+	// Gets a gen.o.u32s value, or element, from JSON.
+	private static int _fromJSONU32s(Object x) {
+		return (int) ColferJSON.integer(x, 0, 0xffffffffL, "gen.o.u32s");
+	}
+
+	// Gets a gen.o.u64s value, or element, from JSON.
+	private static long _fromJSONU64s(Object x) {
+		return ColferJSON.unsigned64(x, "gen.o.u64s");
+	}
+
+	// Gets a gen.o.i32s value, or element, from JSON.
+	private static int _fromJSONI32s(Object x) {
+		return (int) ColferJSON.integer(x, Integer.MIN_VALUE, Integer.MAX_VALUE, "gen.o.i32s");
+	}
+
+	// Gets a gen.o.i64s value, or element, from JSON.
+	private static long _fromJSONI64s(Object x) {
+		return ColferJSON.integer(x, Long.MIN_VALUE, Long.MAX_VALUE, "gen.o.i64s");
+	}
+
+	// Gets a gen.o.es value, or element, from JSON.
+	private static byte _fromJSONEs(Object x) {
+		if (x instanceof String) {
+			gen.Color e = gen.Color.valueOfSchemaName((String) x);
+			if (e == null) throw new IllegalArgumentException(format("colfer: gen.o.es has no enumeration value %s", ColferJSON.quote((String) x)));
+			return e.value;
+		}
+		return (byte) ColferJSON.integer(x, 0, 0xff, "gen.o.es");
+	}
+
+	// Gets a gen.o.mt value, or element, from JSON.
+	private static String _fromJSONMt(Object x) {
+		return ColferJSON.string(x, "gen.o.mt");
+	}
+
+	// Gets a gen.o.mu value, or element, from JSON.
+	private static Long _fromJSONMu(Object x) {
+		return ColferJSON.integer(x, Long.MIN_VALUE, Long.MAX_VALUE, "gen.o.mu");
+	}
+
+	// Gets a gen.o.mi value, or element, from JSON.
+	private static byte[] _fromJSONMi(Object x) {
+		return ColferJSON.binary(x, "gen.o.mi");
+	}
+
+	// Gets a gen.o.mo value, or element, from JSON.
+	private static O _fromJSONMo(Object x) {
+		return new O().unmarshalJSON(ColferJSON.object(x, "gen.o.mo"));
+	}
+
+	// Gets a gen.o.gap value, or element, from JSON.
+	private static boolean _fromJSONGap(Object x) {
+		return ColferJSON.bool(x, "gen.o.gap");
+	}
+
+	// Gets a gen.o.host value, or element, from JSON.
+	private static String _fromJSONHost(Object x) {
+		return ColferJSON.string(x, "gen.o.host");
+	}
+
+	// Gets a gen.o.tags value, or element, from JSON.
+	private static String _fromJSONTags(Object x) {
+		return ColferJSON.string(x, "gen.o.tags");
+	}
+
+	// Gets a gen.o.ob value, or element, from JSON.
+	private static Boolean _fromJSONOb(Object x) {
+		return ColferJSON.bool(x, "gen.o.ob");
+	}
+
+	// Gets a gen.o.ou32 value, or element, from JSON.
+	private static Integer _fromJSONOu32(Object x) {
+		return (int) ColferJSON.integer(x, 0, 0xffffffffL, "gen.o.ou32");
+	}
+
+	// Gets a gen.o.oi64 value, or element, from JSON.
+	private static Long _fromJSONOi64(Object x) {
+		return ColferJSON.integer(x, Long.MIN_VALUE, Long.MAX_VALUE, "gen.o.oi64");
+	}
+
+	// Gets a gen.o.of64 value, or element, from JSON.
+	private static Double _fromJSONOf64(Object x) {
+		return ColferJSON.number(x, false, "gen.o.of64");
+	}
+
+
+	/**
+	 * Gets gen.o.b.
+	 * @return the value.
+	 */
+	public boolean getB() {
+		return this.b;
+	}
+
+	/**
+	 * Sets gen.o.b.
+	 * @param value the replacement.
+	 */
+	public void setB(boolean value) {
+		this.b = value;
+	}
+
+	/**
+	 * Sets gen.o.b.
+	 * @param value the replacement.
+	 * @return {@code this}.
+	 */
+	public O withB(boolean value) {
+		this.b = value;
+		return this;
+	}
+
+	/**
+	 * Gets gen.o.u32.
+	 * @return the value.
+	 */
+	public int getU32() {
+		return this.u32;
+	}
+
+	/**
+	 * Sets gen.o.u32.
+	 * @param value the replacement.
+	 */
+	public void setU32(int value) {
+		this.u32 = value;
+	}
+
+	/**
+	 * Sets gen.o.u32.
+	 * @param value the replacement.
+	 * @return {@code this}.
+	 */
+	public O withU32(int value) {
+		this.u32 = value;
+		return this;
+	}
+
+	/**
+	 * Gets gen.o.u64.
+	 * @return the value.
+	 */
+	public long getU64() {
+		return this.u64;
+	}
+
+	/**
+	 * Sets gen.o.u64.
+	 * @param value the replacement.
+	 */
+	public void setU64(long value) {
+		this.u64 = value;
+	}
+
+	/**
+	 * Sets gen.o.u64.
+	 * @param value the replacement.
+	 * @return {@code this}.
+	 */
+	public O withU64(long value) {
+		this.u64 = value;
+		return this;
+	}
+
+	/**
+	 * Gets gen.o.i32.
+	 * @return the value.
+	 */
+	public int getI32() {
+		return this.i32;
+	}
+
+	/**
+	 * Sets gen.o.i32.
+	 * @param value the replacement.
+	 */
+	public void setI32(int value) {
+		this.i32 = value;
+	}
+
+	/**
+	 * Sets gen.o.i32.
+	 * @param value the replacement.
+	 * @return {@code this}.
+	 */
+	public O withI32(int value) {
+		this.i32 = value;
+		return this;
+	}
+
+	/**
+	 * Gets gen.o.i64.
+	 * @return the value.
+	 */
+	public long getI64() {
+		return this.i64;
+	}
+
+	/**
+	 * Sets gen.o.i64.
+	 * @param value the replacement.
+	 */
+	public void setI64(long value) {
+		this.i64 = value;
+	}
+
+	/**
+	 * Sets gen.o.i64.
+	 * @param value the replacement.
+	 * @return {@code this}.
+	 */
+	public O withI64(long value) {
+		this.i64 = value;
+		return this;
+	}
+
+	/**
+	 * Gets gen.o.f32.
+	 * @return the value.
+	 */
+	public float getF32() {
+		return this.f32;
+	}
+
+	/**
+	 * Sets gen.o.f32.
+	 * @param value the replacement.
+	 */
+	public void setF32(float value) {
+		this.f32 = value;
+	}
+
+	/**
+	 * Sets gen.o.f32.
+	 * @param value the replacement.
+	 * @return {@code this}.
+	 */
+	public O withF32(float value) {
+		this.f32 = value;
+		return this;
+	}
+
+	/**
+	 * Gets gen.o.f64.
+	 * @return the value.
+	 */
+	public double getF64() {
+		return this.f64;
+	}
+
+	/**
+	 * Sets gen.o.f64.
+	 * @param value the replacement.
+	 */
+	public void setF64(double value) {
+		this.f64 = value;
+	}
+
+	/**
+	 * Sets gen.o.f64.
+	 * @param value the replacement.
+	 * @return {@code this}.
+	 */
+	public O withF64(double value) {
+		this.f64 = value;
+		return this;
+	}
+
+	/**
+	 * Gets gen.o.t.
+	 * @return the value.
+	 */
+	public java.time.Instant getT() {
+		return this.t;
+	}
+
+	/**
+	 * Sets gen.o.t.
+	 * @param value the replacement.
+	 */
+	public void setT(java.time.Instant value) {
+		this.t = value;
+	}
+
+	/**
+	 * Sets gen.o.t.
+	 * @param value the replacement.
+	 * @return {@code this}.
+	 */
+	public O withT(java.time.Instant value) {
+		this.t = value;
+		return this;
+	}
+
+	/**
+	 * Gets gen.o.s.
+	 * @return the value.
+	 */
+	public String getS() {
+		return this.s;
+	}
+
+	/**
+	 * Sets gen.o.s.
+	 * @param value the replacement.
+	 */
+	public void setS(String value) {
+		this.s = value;
+	}
+
+	/**
+	 * Sets gen.o.s.
+	 * @param value the replacement.
+	 * @return {@code this}.
+	 */
+	public O withS(String value) {
+		this.s = value;
+		return this;
+	}
+
+	/**
+	 * Gets gen.o.a.
+	 * @return the value.
+	 */
+	public byte[] getA() {
+		return this.a;
+	}
+
+	/**
+	 * Sets gen.o.a.
+	 * @param value the replacement.
+	 */
+	public void setA(byte[] value) {
+		this.a = value;
+	}
+
+	/**
+	 * Sets gen.o.a.
+	 * @param value the replacement.
+	 * @return {@code this}.
+	 */
+	public O withA(byte[] value) {
+		this.a = value;
+		return this;
+	}
+
+	/**
+	 * Gets gen.o.o.
+	 * @return the value.
+	 */
+	public O getO() {
+		return this.o;
+	}
+
+	/**
+	 * Sets gen.o.o.
+	 * @param value the replacement.
+	 */
+	public void setO(O value) {
+		this.o = value;
+	}
+
+	/**
+	 * Sets gen.o.o.
+	 * @param value the replacement.
+	 * @return {@code this}.
+	 */
+	public O withO(O value) {
+		this.o = value;
+		return this;
+	}
+
+	/**
+	 * Gets gen.o.os.
+	 * @return the value.
+	 */
+	public O[] getOs() {
+		return this.os;
+	}
+
+	/**
+	 * Sets gen.o.os.
+	 * @param value the replacement.
+	 */
+	public void setOs(O[] value) {
+		this.os = value;
+	}
+
+	/**
+	 * Sets gen.o.os.
+	 * @param value the replacement.
+	 * @return {@code this}.
+	 */
+	public O withOs(O[] value) {
+		this.os = value;
+		return this;
+	}
+
+	/**
+	 * Gets gen.o.ss.
+	 * @return the value.
+	 */
+	public String[] getSs() {
+		return this.ss;
+	}
+
+	/**
+	 * Sets gen.o.ss.
+	 * @param value the replacement.
+	 */
+	public void setSs(String[] value) {
+		this.ss = value;
+	}
+
+	/**
+	 * Sets gen.o.ss.
+	 * @param value the replacement.
+	 * @return {@code this}.
+	 */
+	public O withSs(String[] value) {
+		this.ss = value;
+		return this;
+	}
+
+	/**
+	 * Gets gen.o.as.
+	 * @return the value.
+	 */
+	public byte[][] getAs() {
+		return this.as;
+	}
+
+	/**
+	 * Sets gen.o.as.
+	 * @param value the replacement.
+	 */
+	public void setAs(byte[][] value) {
+		this.as = value;
+	}
+
+	/**
+	 * Sets gen.o.as.
+	 * @param value the replacement.
+	 * @return {@code this}.
+	 */
+	public O withAs(byte[][] value) {
+		this.as = value;
+		return this;
+	}
+
+	/**
+	 * Gets gen.o.u8.
+	 * @return the value.
+	 */
+	public byte getU8() {
+		return this.u8;
+	}
+
+	/**
+	 * Sets gen.o.u8.
+	 * @param value the replacement.
+	 */
+	public void setU8(byte value) {
+		this.u8 = value;
+	}
+
+	/**
+	 * Sets gen.o.u8.
+	 * @param value the replacement.
+	 * @return {@code this}.
+	 */
+	public O withU8(byte value) {
+		this.u8 = value;
+		return this;
+	}
+
+	/**
+	 * Gets gen.o.u16.
+	 * @return the value.
+	 */
+	public short getU16() {
+		return this.u16;
+	}
+
+	/**
+	 * Sets gen.o.u16.
+	 * @param value the replacement.
+	 */
+	public void setU16(short value) {
+		this.u16 = value;
+	}
+
+	/**
+	 * Sets gen.o.u16.
+	 * @param value the replacement.
+	 * @return {@code this}.
+	 */
+	public O withU16(short value) {
+		this.u16 = value;
+		return this;
+	}
+
+	/**
+	 * Gets gen.o.f32s.
+	 * @return the value.
+	 */
+	public float[] getF32s() {
+		return this.f32s;
+	}
+
+	/**
+	 * Sets gen.o.f32s.
+	 * @param value the replacement.
+	 */
+	public void setF32s(float[] value) {
+		this.f32s = value;
+	}
+
+	/**
+	 * Sets gen.o.f32s.
+	 * @param value the replacement.
+	 * @return {@code this}.
+	 */
+	public O withF32s(float[] value) {
+		this.f32s = value;
+		return this;
+	}
+
+	/**
+	 * Gets gen.o.f64s.
+	 * @return the value.
+	 */
+	public double[] getF64s() {
+		return this.f64s;
+	}
+
+	/**
+	 * Sets gen.o.f64s.
+	 * @param value the replacement.
+	 */
+	public void setF64s(double[] value) {
+		this.f64s = value;
+	}
+
+	/**
+	 * Sets gen.o.f64s.
+	 * @param value the replacement.
+	 * @return {@code this}.
+	 */
+	public O withF64s(double[] value) {
+		this.f64s = value;
+		return this;
+	}
 
-				double[] a = new double[length];
-				for (int ai = 0; ai < length; ai++) {
-					long x = (buf[i++] & 0xffL) << 56 | (buf[i++] & 0xffL) << 48 | (buf[i++] & 0xffL) << 40 | (buf[i++] & 0xffL) << 32
-						| (buf[i++] & 0xffL) << 24 | (buf[i++] & 0xffL) << 16 | (buf[i++] & 0xffL) << 8 | (buf[i++] & 0xffL);
-					a[ai] = Double.longBitsToDouble(x);
-				}
-				this.f64s = a;
-				header = buf[i++];
-			}
+	/**
+	 * Gets gen.o.e.
+	 * @return the value.
+	 */
+	public byte getE() {
+		return this.e;
+	}
 
-			if (header != (byte) 0x7f)
-				throw new InputMismatchException(format("colfer: unknown header at byte %d", i - 1));
-		} finally {
-			if (i > end && end - offset < O.colferSizeMax) throw new BufferUnderflowException();
-			if (i < 0 || i - offset > O.colferSizeMax)
-				throw new SecurityException(format("colfer: gen.o exceeds %d bytes", O.colferSizeMax));
-			if (i > end) throw new BufferUnderflowException();
-		}
+	/**
+	 * Sets gen.o.e.
+	 * @param value the replacement.
+	 */
+	public void setE(byte value) {
+		this.e = value;
+	}
 
-		return i;
+	/**
+	 * Sets gen.o.e.
+	 * @param value the replacement.
+	 * @return {@code this}.
+	 */
+	public O withE(byte value) {
+		this.e = value;
+		return this;
 	}
 
-	// {@link Serializable} version number.
-	private static final long serialVersionUID = 18L;
+	/**
+	 * Gets gen.o.bs.
+	 * @return the value.
+	 */
+	public boolean[] getBs() {
+		return this.bs;
+	}
 
-	// {@link Serializable} Colfer extension.
-	private void writeObject(ObjectOutputStream out) throws IOException {
-		// TODO: better size estimation
-		byte[] buf = new byte[1024];
-		int n;
-		while (true) try {
-			n = marshal(buf, 0);
-			break;
-		} catch (BufferUnderflowException e) {
-			buf = new byte[4 * buf.length];
-		}
+	/**
+	 * Sets gen.o.bs.
+	 * @param value the replacement.
+	 */
+	public void setBs(boolean[] value) {
+		this.bs = value;
+	}
 
-		out.writeInt(n);
-		out.write(buf, 0, n);
+	/**
+	 * Sets gen.o.bs.
+	 * @param value the replacement.
+	 * @return {@code this}.
+	 */
+	public O withBs(boolean[] value) {
+		this.bs = value;
+		return this;
 	}
 
-	// {@link Serializable} Colfer extension.
-	private void readObject(ObjectInputStream in) throws ClassNotFoundException, IOException {
-		init();
+	/**
+	 * Gets gen.o.u8s.
+	 * @return the value.
+	 */
+	public byte[] getU8s() {
+		return this.u8s;
+	}
 
-		int n = in.readInt();
-		byte[] buf = new byte[n];
-		in.readFully(buf);
-		unmarshal(buf, 0);
+	/**
+	 * Sets gen.o.u8s.
+	 * @param value the replacement.
+	 */
+	public void setU8s(byte[] value) {
+		this.u8s = value;
 	}
 
-	// {@link Serializable} Colfer extension.
-	private void readObjectNoData() throws ObjectStreamException {
-		init();
+	/**
+	 * Sets gen.o.u8s.
+	 * @param value the replacement.
+	 * @return {@code this}.
+	 */
+	public O withU8s(byte[] value) {
+		this.u8s = value;
+		return this;
 	}
 
 	/**
-	 * Gets gen.o.b.
+	 * Gets gen.o.u16s.
 	 * @return the value.
 	 */
-	public boolean getB() {
-		return this.b;
+	public short[] getU16s() {
+		return this.u16s;
 	}
 
 	/**
-	 * Sets gen.o.b.
+	 * Sets gen.o.u16s.
 	 * @param value the replacement.
 	 */
-	public void setB(boolean value) {
-		this.b = value;
+	public void setU16s(short[] value) {
+		this.u16s = value;
 	}
 
 	/**
-	 * Sets gen.o.b.
+	 * Sets gen.o.u16s.
 	 * @param value the replacement.
 	 * @return {@code this}.
 	 */
-	public O withB(boolean value) {
-		this.b = value;
+	public O withU16s(short[] value) {
+		this.u16s = value;
 		return this;
 	}
 
 	/**
-	 * Gets gen.o.u32.
+	 * Gets gen.o.u32s.
 	 * @return the value.
 	 */
-	public int getU32() {
-		return this.u32;
+	public int[] getU32s() {
+		return this.u32s;
 	}
 
 	/**
-	 * Sets gen.o.u32.
+	 * Sets gen.o.u32s.
 	 * @param value the replacement.
 	 */
-	public void setU32(int value) {
-		this.u32 = value;
+	public void setU32s(int[] value) {
+		this.u32s = value;
 	}
 
 	/**
-	 * Sets gen.o.u32.
+	 * Sets gen.o.u32s.
 	 * @param value the replacement.
 	 * @return {@code this}.
 	 */
-	public O withU32(int value) {
-		this.u32 = value;
+	public O withU32s(int[] value) {
+		this.u32s = value;
 		return this;
 	}
 
 	/**
-	 * Gets gen.o.u64.
+	 * Gets gen.o.u64s.
 	 * @return the value.
 	 */
-	public long getU64() {
-		return this.u64;
+	public long[] getU64s() {
+		return this.u64s;
 	}
 
 	/**
-	 * Sets gen.o.u64.
+	 * Sets gen.o.u64s.
 	 * @param value the replacement.
 	 */
-	public void setU64(long value) {
-		this.u64 = value;
+	public void setU64s(long[] value) {
+		this.u64s = value;
 	}
 
 	/**
-	 * Sets gen.o.u64.
+	 * Sets gen.o.u64s.
 	 * @param value the replacement.
 	 * @return {@code this}.
 	 */
-	public O withU64(long value) {
-		this.u64 = value;
+	public O withU64s(long[] value) {
+		this.u64s = value;
 		return this;
 	}
 
 	/**
-	 * Gets gen.o.i32.
+	 * Gets gen.o.i32s.
 	 * @return the value.
 	 */
-	public int getI32() {
-		return this.i32;
+	public int[] getI32s() {
+		return this.i32s;
 	}
 
 	/**
-	 * Sets gen.o.i32.
+	 * Sets gen.o.i32s.
 	 * @param value the replacement.
 	 */
-	public void setI32(int value) {
-		this.i32 = value;
+	public void setI32s(int[] value) {
+		this.i32s = value;
 	}
 
 	/**
-	 * Sets gen.o.i32.
+	 * Sets gen.o.i32s.
 	 * @param value the replacement.
 	 * @return {@code this}.
 	 */
-	public O withI32(int value) {
-		this.i32 = value;
+	public O withI32s(int[] value) {
+		this.i32s = value;
 		return this;
 	}
 
 	/**
-	 * Gets gen.o.i64.
+	 * Gets gen.o.i64s.
 	 * @return the value.
 	 */
-	public long getI64() {
-		return this.i64;
+	public long[] getI64s() {
+		return this.i64s;
 	}
 
 	/**
-	 * Sets gen.o.i64.
+	 * Sets gen.o.i64s.
 	 * @param value the replacement.
 	 */
-	public void setI64(long value) {
-		this.i64 = value;
+	public void setI64s(long[] value) {
+		this.i64s = value;
 	}
 
 	/**
-	 * Sets gen.o.i64.
+	 * Sets gen.o.i64s.
 	 * @param value the replacement.
 	 * @return {@code this}.
 	 */
-	public O withI64(long value) {
-		this.i64 = value;
+	public O withI64s(long[] value) {
+		this.i64s = value;
 		return this;
 	}
 
 	/**
-	 * Gets gen.o.f32.
+	 * Gets gen.o.es.
 	 * @return the value.
 	 */
-	public float getF32() {
-		return this.f32;
+	public byte[] getEs() {
+		return this.es;
 	}
 
 	/**
-	 * Sets gen.o.f32.
+	 * Sets gen.o.es.
 	 * @param value the replacement.
 	 */
-	public void setF32(float value) {
-		this.f32 = value;
+	public void setEs(byte[] value) {
+		this.es = value;
 	}
 
 	/**
-	 * Sets gen.o.f32.
+	 * Sets gen.o.es.
 	 * @param value the replacement.
 	 * @return {@code this}.
 	 */
-	public O withF32(float value) {
-		this.f32 = value;
+	public O withEs(byte[] value) {
+		this.es = value;
 		return this;
 	}
 
 	/**
-	 * Gets gen.o.f64.
+	 * Gets gen.o.mt.
 	 * @return the value.
 	 */
-	public double getF64() {
-		return this.f64;
+	public java.util.Map<String, String> getMt() {
+		return this.mt;
 	}
 
 	/**
-	 * Sets gen.o.f64.
+	 * Sets gen.o.mt.
 	 * @param value the replacement.
 	 */
-	public void setF64(double value) {
-		this.f64 = value;
+	public void setMt(java.util.Map<String, String> value) {
+		this.mt = value;
 	}
 
 	/**
-	 * Sets gen.o.f64.
+	 * Sets gen.o.mt.
 	 * @param value the replacement.
 	 * @return {@code this}.
 	 */
-	public O withF64(double value) {
-		this.f64 = value;
+	public O withMt(java.util.Map<String, String> value) {
+		this.mt = value;
 		return this;
 	}
 
 	/**
-	 * Gets gen.o.t.
+	 * Gets gen.o.mu.
 	 * @return the value.
 	 */
-	public java.time.Instant getT() {
-		return this.t;
+	public java.util.Map<Integer, Long> getMu() {
+		return this.mu;
 	}
 
 	/**
-	 * Sets gen.o.t.
+	 * Sets gen.o.mu.
 	 * @param value the replacement.
 	 */
-	public void setT(java.time.Instant value) {
-		this.t = value;
+	public void setMu(java.util.Map<Integer, Long> value) {
+		this.mu = value;
 	}
 
 	/**
-	 * Sets gen.o.t.
+	 * Sets gen.o.mu.
 	 * @param value the replacement.
 	 * @return {@code this}.
 	 */
-	public O withT(java.time.Instant value) {
-		this.t = value;
+	public O withMu(java.util.Map<Integer, Long> value) {
+		this.mu = value;
 		return this;
 	}
 
 	/**
-	 * Gets gen.o.s.
+	 * Gets gen.o.mi.
 	 * @return the value.
 	 */
-	public String getS() {
-		return this.s;
+	public java.util.Map<Long, byte[]> getMi() {
+		return this.mi;
 	}
 
 	/**
-	 * Sets gen.o.s.
+	 * Sets gen.o.mi.
 	 * @param value the replacement.
 	 */
-	public void setS(String value) {
-		this.s = value;
+	public void setMi(java.util.Map<Long, byte[]> value) {
+		this.mi = value;
 	}
 
 	/**
-	 * Sets gen.o.s.
+	 * Sets gen.o.mi.
 	 * @param value the replacement.
 	 * @return {@code this}.
 	 */
-	public O withS(String value) {
-		this.s = value;
+	public O withMi(java.util.Map<Long, byte[]> value) {
+		this.mi = value;
 		return this;
 	}
 
 	/**
-	 * Gets gen.o.a.
+	 * Gets gen.o.mo.
 	 * @return the value.
 	 */
-	public byte[] getA() {
-		return this.a;
+	public java.util.Map<String, O> getMo() {
+		return this.mo;
 	}
 
 	/**
-	 * Sets gen.o.a.
+	 * Sets gen.o.mo.
 	 * @param value the replacement.
 	 */
-	public void setA(byte[] value) {
-		this.a = value;
+	public void setMo(java.util.Map<String, O> value) {
+		this.mo = value;
 	}
 
 	/**
-	 * Sets gen.o.a.
+	 * Sets gen.o.mo.
 	 * @param value the replacement.
 	 * @return {@code this}.
 	 */
-	public O withA(byte[] value) {
-		this.a = value;
+	public O withMo(java.util.Map<String, O> value) {
+		this.mo = value;
 		return this;
 	}
 
 	/**
-	 * Gets gen.o.o.
+	 * Gets gen.o.gap.
 	 * @return the value.
 	 */
-	public O getO() {
-		return this.o;
+	public boolean getGap() {
+		return this.gap;
 	}
 
 	/**
-	 * Sets gen.o.o.
+	 * Sets gen.o.gap.
 	 * @param value the replacement.
 	 */
-	public void setO(O value) {
-		this.o = value;
+	public void setGap(boolean value) {
+		this.gap = value;
 	}
 
 	/**
-	 * Sets gen.o.o.
+	 * Sets gen.o.gap.
 	 * @param value the replacement.
 	 * @return {@code this}.
 	 */
-	public O withO(O value) {
-		this.o = value;
+	public O withGap(boolean value) {
+		this.gap = value;
 		return this;
 	}
 
 	/**
-	 * Gets gen.o.os.
+	 * Gets gen.o.host.
 	 * @return the value.
 	 */
-	public O[] getOs() {
-		return this.os;
+	public String getHost() {
+		return this.host;
 	}
 
 	/**
-	 * Sets gen.o.os.
+	 * Sets gen.o.host.
 	 * @param value the replacement.
 	 */
-	public void setOs(O[] value) {
-		this.os = value;
+	public void setHost(String value) {
+		this.host = value;
 	}
 
 	/**
-	 * Sets gen.o.os.
+	 * Sets gen.o.host.
 	 * @param value the replacement.
 	 * @return {@code this}.
 	 */
-	public O withOs(O[] value) {
-		this.os = value;
+	public O withHost(String value) {
+		this.host = value;
 		return this;
 	}
 
 	/**
-	 * Gets gen.o.ss.
+	 * Gets gen.o.tags.
 	 * @return the value.
 	 */
-	public String[] getSs() {
-		return this.ss;
+	public String[] getTags() {
+		return this.tags;
 	}
 
 	/**
-	 * Sets gen.o.ss.
+	 * Sets gen.o.tags.
 	 * @param value the replacement.
 	 */
-	public void setSs(String[] value) {
-		this.ss = value;
+	public void setTags(String[] value) {
+		this.tags = value;
 	}
 
 	/**
-	 * Sets gen.o.ss.
+	 * Sets gen.o.tags.
 	 * @param value the replacement.
 	 * @return {@code this}.
 	 */
-	public O withSs(String[] value) {
-		this.ss = value;
+	public O withTags(String[] value) {
+		this.tags = value;
 		return this;
 	}
 
 	/**
-	 * Gets gen.o.as.
+	 * Gets gen.o.ob.
 	 * @return the value.
 	 */
-	public byte[][] getAs() {
-		return this.as;
+	public Boolean getOb() {
+		return this.ob;
 	}
 
 	/**
-	 * Sets gen.o.as.
+	 * Sets gen.o.ob.
 	 * @param value the replacement.
 	 */
-	public void setAs(byte[][] value) {
-		this.as = value;
+	public void setOb(Boolean value) {
+		this.ob = value;
 	}
 
 	/**
-	 * Sets gen.o.as.
+	 * Sets gen.o.ob.
 	 * @param value the replacement.
 	 * @return {@code this}.
 	 */
-	public O withAs(byte[][] value) {
-		this.as = value;
+	public O withOb(Boolean value) {
+		this.ob = value;
 		return this;
 	}
 
 	/**
-	 * Gets gen.o.u8.
+	 * Gets gen.o.ou32.
 	 * @return the value.
 	 */
-	public byte getU8() {
-		return this.u8;
+	public Integer getOu32() {
+		return this.ou32;
 	}
 
 	/**
-	 * Sets gen.o.u8.
+	 * Sets gen.o.ou32.
 	 * @param value the replacement.
 	 */
-	public void setU8(byte value) {
-		this.u8 = value;
+	public void setOu32(Integer value) {
+		this.ou32 = value;
 	}
 
 	/**
-	 * Sets gen.o.u8.
+	 * Sets gen.o.ou32.
 	 * @param value the replacement.
 	 * @return {@code this}.
 	 */
-	public O withU8(byte value) {
-		this.u8 = value;
+	public O withOu32(Integer value) {
+		this.ou32 = value;
 		return this;
 	}
 
 	/**
-	 * Gets gen.o.u16.
+	 * Gets gen.o.oi64.
 	 * @return the value.
 	 */
-	public short getU16() {
-		return this.u16;
+	public Long getOi64() {
+		return this.oi64;
 	}
 
 	/**
-	 * Sets gen.o.u16.
+	 * Sets gen.o.oi64.
 	 * @param value the replacement.
 	 */
-	public void setU16(short value) {
-		this.u16 = value;
+	public void setOi64(Long value) {
+		this.oi64 = value;
 	}
 
 	/**
-	 * Sets gen.o.u16.
+	 * Sets gen.o.oi64.
 	 * @param value the replacement.
 	 * @return {@code this}.
 	 */
-	public O withU16(short value) {
-		this.u16 = value;
+	public O withOi64(Long value) {
+		this.oi64 = value;
 		return this;
 	}
 
 	/**
-	 * Gets gen.o.f32s.
+	 * Gets gen.o.of64.
 	 * @return the value.
 	 */
-	public float[] getF32s() {
-		return this.f32s;
+	public Double getOf64() {
+		return this.of64;
 	}
 
 	/**
-	 * Sets gen.o.f32s.
+	 * Sets gen.o.of64.
 	 * @param value the replacement.
 	 */
-	public void setF32s(float[] value) {
-		this.f32s = value;
+	public void setOf64(Double value) {
+		this.of64 = value;
 	}
 
 	/**
-	 * Sets gen.o.f32s.
+	 * Sets gen.o.of64.
 	 * @param value the replacement.
 	 * @return {@code this}.
 	 */
-	public O withF32s(float[] value) {
-		this.f32s = value;
+	public O withOf64(Double value) {
+		this.of64 = value;
 		return this;
 	}
 
 	/**
-	 * Gets gen.o.f64s.
+	 * Gets gen.o.u.
 	 * @return the value.
 	 */
-	public double[] getF64s() {
-		return this.f64s;
+	public Pick getU() {
+		return this.u;
 	}
 
 	/**
-	 * Sets gen.o.f64s.
+	 * Sets gen.o.u.
 	 * @param value the replacement.
 	 */
-	public void setF64s(double[] value) {
-		this.f64s = value;
+	public void setU(Pick value) {
+		this.u = value;
 	}
 
 	/**
-	 * Sets gen.o.f64s.
+	 * Sets gen.o.u.
 	 * @param value the replacement.
 	 * @return {@code this}.
 	 */
-	public O withF64s(double[] value) {
-		this.f64s = value;
+	public O withU(Pick value) {
+		this.u = value;
 		return this;
 	}
 
+	@Override
+	public <R> R accept(Pick.Visitor<R> v) {
+		return v.visit(this);
+	}
+
 	@Override
 	public final int hashCode() {
 		int h = 1;
@@ -1523,6 +4117,32 @@ public class O implements Serializable {
 		h = 31 * h + (this.u16 & 0xffff);
 		h = 31 * h + java.util.Arrays.hashCode(this.f32s);
 		h = 31 * h + java.util.Arrays.hashCode(this.f64s);
+		h = 31 * h + (this.e & 0xff);
+		h = 31 * h + java.util.Arrays.hashCode(this.bs);
+		h = 31 * h + java.util.Arrays.hashCode(this.u8s);
+		h = 31 * h + java.util.Arrays.hashCode(this.u16s);
+		h = 31 * h + java.util.Arrays.hashCode(this.u32s);
+		h = 31 * h + java.util.Arrays.hashCode(this.u64s);
+		h = 31 * h + java.util.Arrays.hashCode(this.i32s);
+		h = 31 * h + java.util.Arrays.hashCode(this.i64s);
+		h = 31 * h + java.util.Arrays.hashCode(this.es);
+		if (this.mt != null) h = 31 * h + this.mt.hashCode();
+		if (this.mu != null) h = 31 * h + this.mu.hashCode();
+		if (this.mi != null) {
+			int mh = 0;
+			for (java.util.Map.Entry<Long, byte[]> e : this.mi.entrySet())
+				mh += java.util.Objects.hashCode(e.getKey()) ^ java.util.Arrays.hashCode(e.getValue());
+			h = 31 * h + mh;
+		}
+		if (this.mo != null) h = 31 * h + this.mo.hashCode();
+		h = 31 * h + (this.gap ? 1231 : 1237);
+		if (this.host != null) h = 31 * h + this.host.hashCode();
+		for (String o : this.tags) h = 31 * h + (o == null ? 0 : o.hashCode());
+		if (this.ob != null) h = 31 * h + this.ob.hashCode();
+		if (this.ou32 != null) h = 31 * h + this.ou32.hashCode();
+		if (this.oi64 != null) h = 31 * h + this.oi64.hashCode();
+		if (this.of64 != null) h = 31 * h + this.of64.hashCode();
+		if (this.u != null) h = 31 * h + this.u.hashCode();
 		return h;
 	}
 
@@ -1552,7 +4172,28 @@ public class O implements Serializable {
 			&& this.u8 == o.u8
 			&& this.u16 == o.u16
 			&& java.util.Arrays.equals(this.f32s, o.f32s)
-			&& java.util.Arrays.equals(this.f64s, o.f64s);
+			&& java.util.Arrays.equals(this.f64s, o.f64s)
+			&& this.e == o.e
+			&& java.util.Arrays.equals(this.bs, o.bs)
+			&& java.util.Arrays.equals(this.u8s, o.u8s)
+			&& java.util.Arrays.equals(this.u16s, o.u16s)
+			&& java.util.Arrays.equals(this.u32s, o.u32s)
+			&& java.util.Arrays.equals(this.u64s, o.u64s)
+			&& java.util.Arrays.equals(this.i32s, o.i32s)
+			&& java.util.Arrays.equals(this.i64s, o.i64s)
+			&& java.util.Arrays.equals(this.es, o.es)
+			&& (this.mt == null ? o.mt == null : this.mt.equals(o.mt))
+			&& (this.mu == null ? o.mu == null : this.mu.equals(o.mu))
+			&& _equals(this.mi, o.mi)
+			&& (this.mo == null ? o.mo == null : this.mo.equals(o.mo))
+			&& this.gap == o.gap
+			&& (this.host == null ? o.host == null : this.host.equals(o.host))
+			&& java.util.Arrays.equals(this.tags, o.tags)
+			&& java.util.Objects.equals(this.ob, o.ob)
+			&& java.util.Objects.equals(this.ou32, o.ou32)
+			&& java.util.Objects.equals(this.oi64, o.oi64)
+			&& java.util.Objects.equals(this.of64, o.of64)
+			&& (this.u == null ? o.u == null : this.u.equals(o.u));
 	}
 
 	private static boolean _equals(byte[][] a, byte[][] b) {
@@ -1566,4 +4207,31 @@ public class O implements Serializable {
 		return true;
 	}
 
+	private static boolean _equals(java.util.Map<?, byte[]> a, java.util.Map<?, byte[]> b) {
+		if (a == b) return true;
+		if (a == null || b == null || a.size() != b.size()) return false;
+
+		for (java.util.Map.Entry<?, byte[]> e : a.entrySet()) {
+			Object k = e.getKey();
+			if (! b.containsKey(k) || ! java.util.Arrays.equals(e.getValue(), b.get(k))) return false;
+		}
+		return true;
+	}
+
+	// Compares by UTF-8 byte order, which differs from the UTF-16 order of
+	// String.compareTo for the code points beyond U+FFFF.
+	private static int _compareUTF8(String a, String b) {
+		for (int i = 0, n = Math.min(a.length(), b.length()); i < n; i++) {
+			int ca = a.charAt(i), cb = b.charAt(i);
+			if (ca == cb) continue;
+			if (ca >= 0xd800 && cb >= 0xd800) {
+				// surrogates [U+D800, U+DFFF] sort after [U+E000, U+FFFF]
+				ca += ca < 0xe000 ? 0x2000 : -0x800;
+				cb += cb < 0xe000 ? 0x2000 : -0x800;
+			}
+			return ca - cb;
+		}
+		return a.length() - b.length();
+	}
+
 }
@@ -1,3 +1,6 @@
+import gen.Color;
+import gen.ColferDepthException;
+import gen.ColferLimits;
 import gen.O;
 
 import java.io.ByteArrayOutputStream;
@@ -5,7 +8,10 @@ import java.io.ByteArrayInputStream;
 import java.io.ObjectInputStream;
 import java.io.ObjectOutputStream;
 import java.math.BigInteger;
+import java.nio.BufferUnderflowException;
 import java.nio.ByteBuffer;
+import java.nio.file.Files;
+import java.nio.file.Paths;
 import java.time.Instant;
 import java.util.Arrays;
 import java.util.LinkedHashMap;
@@ -24,6 +30,8 @@ public class test {
 	public static void main(String[] args) {
 		try {
 			identity();
+			enumeration();
+			constants();
 
 			marshal();
 			unmarshal();
@@ -38,8 +46,12 @@ public class test {
 			unmarshalTextMax();
 			unmarshalBinaryMax();
 			unmarshalListMax();
+			unmarshalLimits();
+			depthLimit();
+			marshalCycle();
 
 			serializable();
+			json();
 		} catch (Exception e) {
 			e.printStackTrace();
 			System.exit(1);
@@ -62,6 +74,7 @@ public class test {
 		newCase(goldenCases, "01017f").u32 = 1;
 		newCase(goldenCases, "01ff017f").u32 = 255;
 		newCase(goldenCases, "01ffff037f").u32 = 65535;
+		newCase(goldenCases, "01ff7f7f").u32 = 16383;
 		newCase(goldenCases, "81ffffffff7f").u32 = -1;
 		newCase(goldenCases, "02017f").u64 = 1L;
 		newCase(goldenCases, "02ff017f").u64 = 255L;
@@ -119,6 +132,41 @@ public class test {
 		newCase(goldenCases, "0fffff7f").u16 = -1;
 		newCase(goldenCases, "1002000000003f8000007f").f32s = new float[] {0, 1};
 		newCase(goldenCases, "11014058c000000000007f").f64s = new double[] {99};
+		newCase(goldenCases, "12017f").e = Color.GREEN.value;
+		newCase(goldenCases, "12ff7f").e = (byte) 0xff;
+		newCase(goldenCases, "13030100017f").bs = new boolean[] {true, false, true};
+		newCase(goldenCases, "140200ff7f").u8s = new byte[] {0, (byte) 0xff};
+		newCase(goldenCases, "150201ffff037f").u16s = new short[] {1, -1};
+		newCase(goldenCases, "1601ffffffff0f7f").u32s = new int[] {-1};
+		newCase(goldenCases, "170201ffffffffffffffffff7f").u64s = new long[] {1L, -1L};
+		newCase(goldenCases, "1501ff7f7f").u16s = new short[] {16383};
+		newCase(goldenCases, "1602ff7fffff7f7f").u32s = new int[] {16383, 2097151};
+		newCase(goldenCases, "1702ff7fffffffffffffff0f7f").u64s = new long[] {16383L, (1L << 53) - 1};
+		newCase(goldenCases, "18030102ffffffff0f7f").i32s = new int[] {-1, 1, Integer.MIN_VALUE};
+		newCase(goldenCases, "1902fffffffffffffffffffeffffffffffffffff7f").i64s = new long[] {Long.MIN_VALUE, Long.MAX_VALUE};
+		newCase(goldenCases, "1a0201ff7f").es = new byte[] {Color.GREEN.value, (byte) 0xff};
+
+		O o = newCase(goldenCases, "1b02000178016101627f");
+		o.mt.put("a", "b");
+		o.mt.put("", "x");
+		o = newCase(goldenCases, "1c020101ffffffff0ffeffffffffffffffff7f");
+		o.mu.put(1, -1L);
+		o.mu.put(-1, Long.MAX_VALUE);
+		o = newCase(goldenCases, "1d020101ff00007f");
+		o.mi.put(-1L, new byte[] {(byte) 0xff});
+		o.mi.put(0L, new byte[0]);
+		newCase(goldenCases, "1e01016f007f7f").mo.put("o", new O().withB(true));
+		newCase(goldenCases, "207f").gap = true;
+		newCase(goldenCases, "2104616263647f").host = "abcd";
+		newCase(goldenCases, "2202016101627f").tags = new String[]{"a", "b"};
+		newCase(goldenCases, "237f").ob = true;
+		newCase(goldenCases, "a37f").ob = false;
+		newCase(goldenCases, "24007f").ou32 = 0;
+		newCase(goldenCases, "25007f").oi64 = 0L;
+		newCase(goldenCases, "a5017f").oi64 = -1L;
+		newCase(goldenCases, "2600000000000000007f").of64 = 0.0;
+		newCase(goldenCases, "27007f7f").u = new O();
+		newCase(goldenCases, "270100017f7f").u = new Point().withX(1);
 		return goldenCases;
 	}
 
@@ -143,6 +191,22 @@ public class test {
 			fail("golden cases hash not equal");
 	}
 
+	static void enumeration() {
+		if (Color.valueOf(Color.BLUE.value) != Color.BLUE)
+			fail("enumeration: no match for value %d", Color.BLUE.value);
+		if (Color.valueOf((byte) 0xff) != null)
+			fail("enumeration: match for unknown value 0xff");
+	}
+
+	static void constants() {
+		if (Colfer.CONST_U32 != -1)
+			fail("constants: got uint32 %d, want -1", Colfer.CONST_U32);
+		if (Colfer.CONST_I32 != Integer.MIN_VALUE)
+			fail("constants: got int32 %d, want %d", Colfer.CONST_I32, Integer.MIN_VALUE);
+		if (! Colfer.CONST_TEXT.equals("\"\u03c0\"\t??="))
+			fail("constants: got text %s", Colfer.CONST_TEXT);
+	}
+
 	static void marshal() throws Exception {
 		for (Entry<String, O> e : newGoldenCases().entrySet()) {
 			byte[] buf = new byte[O.colferSizeMax];
@@ -322,6 +386,81 @@ public class test {
 		}
 	}
 
+	static void unmarshalLimits() {
+		ColferLimits limits = new ColferLimits(1024, 9, 2);
+		String[][] cases = {
+			{"0a080a414141", "colfer: gen.o.s size 10 exceeds 9 UTF-8 bytes"},
+			{"0b0a7f7f7f", "colfer: gen.o.os length 10 exceeds 9 elements"},
+		};
+		for (String[] c : cases) {
+			byte[] serial = parseHex(c[0]);
+			try {
+				new O().unmarshal(serial, 0, serial.length, limits);
+				fail("0x%s: no unmarshal limits exception", c[0]);
+			} catch (SecurityException e) {
+				if (! c[1].equals(e.getMessage()))
+					fail("0x%s: unmarshal limits error: %s\nwant: %s", c[0], e.getMessage(), c[1]);
+			} catch (BufferUnderflowException e) {
+				fail("0x%s: unmarshal limits got EOF", c[0]);
+			}
+		}
+	}
+
+	static void depthLimit() throws Exception {
+		ColferLimits limits = new ColferLimits(O.colferSizeMax, O.colferListMax, O.colferDepthMax + 1);
+		for (String name : new String[]{"o", "os", "mo", "u"}) {
+			byte[] serial = Files.readAllBytes(Paths.get("../testdata/corpus/seed-depth-" + name));
+			try {
+				new O().unmarshal(serial, 0);
+				fail("%s: no unmarshal depth exception", name);
+			} catch (ColferDepthException e) {
+			}
+
+			O o = new O();
+			int n = o.unmarshal(serial, 0, serial.length, limits);
+			if (n != serial.length)
+				fail("%s: unmarshal with one more level read %d bytes, want %d", name, n, serial.length);
+			byte[] buf = new byte[serial.length];
+			try {
+				o.marshal(buf, 0);
+				fail("%s: no marshal depth exception", name);
+			} catch (ColferDepthException e) {
+			}
+			n = o.marshal(buf, 0, limits);
+			if (n != serial.length || ! Arrays.equals(buf, serial))
+				fail("%s: marshal with one more level got 0x%s, want 0x%s", name, toHex(Arrays.copyOf(buf, n)), toHex(serial));
+		}
+	}
+
+	static void marshalCycle() {
+		O self = new O();
+		self.o = self;
+		O list = new O();
+		list.os = new O[] {new O(), list};
+		O mapped = new O();
+		mapped.mo = new LinkedHashMap<String, O>();
+		mapped.mo.put("a", mapped);
+		O union = new O();
+		union.u = union;
+		O pair = new O();
+		pair.o = new O();
+		pair.o.o = pair;
+
+		Map<String, O> cases = new LinkedHashMap<String, O>();
+		cases.put("self", self);
+		cases.put("list", list);
+		cases.put("map", mapped);
+		cases.put("union", union);
+		cases.put("pair", pair);
+		for (Entry<String, O> e : cases.entrySet()) {
+			try {
+				e.getValue().marshal(new byte[O.colferSizeMax], 0);
+				fail("%s: no marshal depth exception", e.getKey());
+			} catch (ColferDepthException ok) {
+			}
+		}
+	}
+
 	static void serializable() throws Exception {
 		Set<Entry<String, O>> cases = newGoldenCases().entrySet();
 		ByteArrayOutputStream buf = new ByteArrayOutputStream();
@@ -342,6 +481,32 @@ public class test {
 		}
 	}
 
+	// The JSON golden cases are shared with the Go and ECMAScript tests.
+	static void json() throws Exception {
+		for (String line : Files.readAllLines(Paths.get("../testdata/json-golden.txt"), UTF_8)) {
+			if (line.isEmpty() || line.startsWith("#")) continue;
+			int i = line.indexOf(' ');
+			String hex = line.substring(0, i), json = line.substring(i + 1);
+
+			O o = new O();
+			o.unmarshal(parseHex(hex), 0);
+			String got = o.marshalJSON();
+			if (! got.equals(json))
+				fail("marshalJSON: 0x%s: got %s, want %s", hex, got, json);
+			got = new O().unmarshalJSON(json).marshalJSON();
+			if (! got.equals(json))
+				fail("unmarshalJSON: %s: got %s after marshalJSON", json, got);
+		}
+
+		for (String json : new String[]{"{\"nope\":true}", "{\"u8\":256}", "{\"e\":\"purple\"}", "{\"u\":{\"kind\":\"o2\"}}", "{} {}"}) {
+			try {
+				new O().unmarshalJSON(json);
+				fail("unmarshalJSON: %s: no error", json);
+			} catch (IllegalArgumentException e) {
+			}
+		}
+	}
+
 	static String toHex(byte[] bytes) {
 		String hex = new BigInteger(1, bytes).toString(16);
 		while (bytes.length * 2 > hex.length())
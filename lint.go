@@ -0,0 +1,213 @@
+package colfer
+
+import (
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"strings"
+
+	"github.com/pascaldekloe/name"
+)
+
+// Lint returns the definitions which collide once mapped to the naming of
+// any of the supported languages. Errors are reported as a
+// scanner.ErrorList, sorted by position.
+func Lint(packages Packages) error {
+	var errs scanner.ErrorList
+	for _, lint := range []func(Packages) scanner.ErrorList{lintC, lintGo, lintJava, lintECMA} {
+		errs = append(errs, lint(packages)...)
+	}
+	errs.Sort()
+	return errs.Err()
+}
+
+// nameSpace tracks the names in a scope of generated code.
+type nameSpace struct {
+	// lang labels the errors.
+	lang string
+	// defs has the description and location per name.
+	defs map[string]nameDef
+	errs *scanner.ErrorList
+}
+
+type nameDef struct {
+	desc string
+	pos  token.Position
+}
+
+func newNameSpace(lang string, errs *scanner.ErrorList, reserved ...string) *nameSpace {
+	ns := &nameSpace{lang: lang, defs: make(map[string]nameDef), errs: errs}
+	for _, s := range reserved {
+		ns.defs[s] = nameDef{desc: "a reserved name"}
+	}
+	return ns
+}
+
+// Add registers native as the name for desc.
+func (ns *nameSpace) Add(native, desc string, pos token.Position) {
+	def, ok := ns.defs[native]
+	if !ok {
+		ns.defs[native] = nameDef{desc, pos}
+		return
+	}
+
+	msg := fmt.Sprintf("%s name %s of %s collides with %s", ns.lang, native, desc, def.desc)
+	if def.pos.IsValid() {
+		msg += " at " + def.pos.String()
+	}
+	ns.errs.Add(pos, msg)
+}
+
+// lintC mirrors the naming of GenerateC.
+func lintC(packages Packages) scanner.ErrorList {
+	var errs scanner.ErrorList
+	global := newNameSpace("C", &errs, "colfer_size_max", "colfer_list_max", "colfer_depth_max", "colfer_limits", "colfer_defaults", "colfer_text", "colfer_binary")
+	for _, p := range packages {
+		for _, e := range p.Enums {
+			global.Add(name.SnakeCase(p.Name+"_"+e.Name), "enumeration "+e.String(), e.Pos)
+			for _, v := range e.Values {
+				global.Add(strings.ToUpper(name.SnakeCase(p.Name+"_"+e.Name+"_"+v.Name)), "enumeration value "+v.String(), v.Pos)
+			}
+		}
+		for _, c := range p.Consts {
+			global.Add(strings.ToUpper(name.SnakeCase(p.Name+"_"+c.Name)), "constant "+c.String(), c.Pos)
+		}
+		for _, u := range p.Unions {
+			global.Add(name.SnakeCase(p.Name+"_"+u.Name), "union "+u.String(), u.Pos)
+
+			members := newNameSpace("C", &errs, "type")
+			for _, m := range u.Members {
+				global.Add(strings.ToUpper(name.SnakeCase(p.Name+"_"+u.Name+"_"+m.Name)), "union member "+u.String()+"."+m.Name, u.Pos)
+				members.Add(cName(m.Name), "union member "+u.String()+"."+m.Name, u.Pos)
+			}
+		}
+		for _, s := range p.Structs {
+			native := name.SnakeCase(p.Name + "_" + s.Name)
+			global.Add(native, "struct "+s.String(), s.Pos)
+			for _, suffix := range []string{"_marshal_len", "_marshal_len_with", "_marshal", "_unmarshal", "_unmarshal_with", "_unmarshal_serial", "_unmarshal_fields"} {
+				global.Add(native+suffix, "struct "+s.String(), s.Pos)
+			}
+
+			fields := newNameSpace("C", &errs, "colfer_unknown")
+			for _, f := range s.Fields {
+				native := cName(f.Name)
+				fields.Add(native, "field "+f.String(), f.Pos)
+				if f.Optional {
+					fields.Add("has_"+native, "field "+f.String(), f.Pos)
+				}
+			}
+		}
+	}
+	errs.Sort()
+	return errs
+}
+
+// lintGo mirrors the naming of GenerateGo.
+func lintGo(packages Packages) scanner.ErrorList {
+	var errs scanner.ErrorList
+	for _, p := range packages {
+		pkg := newNameSpace("Go", &errs, "ColferSizeMax", "ColferListMax", "ColferDepthMax", "ColferLimits", "ColferDefaults", "ColferMax", "ColferDepth", "ColferError", "ColferTail", "ColferDecoder", "NewColferDecoder", "NewColferDecoderWith", "ColferEncoder", "NewColferEncoder")
+		for _, e := range p.Enums {
+			pkg.Add(e.NameTitle(), "enumeration "+e.String(), e.Pos)
+			for _, v := range e.Values {
+				pkg.Add(v.NameTitle(), "enumeration value "+v.String(), v.Pos)
+			}
+		}
+		for _, c := range p.Consts {
+			pkg.Add(c.NameTitle(), "constant "+c.String(), c.Pos)
+		}
+		for _, u := range p.Unions {
+			pkg.Add(u.NameTitle(), "union "+u.String(), u.Pos)
+		}
+		for _, s := range p.Structs {
+			pkg.Add(s.NameTitle(), "struct "+s.String(), s.Pos)
+			pkg.Add(s.NameTitle()+"View", "view of struct "+s.String(), s.Pos)
+			pkg.Add(s.NameTitle()+"Mask", "mask of struct "+s.String(), s.Pos)
+
+			// fields and methods share a name space
+			fields := newNameSpace("Go", &errs, "ColferUnknown", "Equal", "Clone", "MarshalTo", "MarshalLen", "MarshalLenWith", "MarshalAppend", "MarshalBinary", "MarshalJSON", "Unmarshal", "UnmarshalWith", "UnmarshalMask", "UnmarshalMaskWith", "UnmarshalBinary", "UnmarshalJSON")
+			for _, f := range s.Fields {
+				fields.Add(f.NameTitle(), "field "+f.String(), f.Pos)
+			}
+		}
+	}
+	errs.Sort()
+	return errs
+}
+
+// lintJava mirrors the naming of GenerateJava.
+func lintJava(packages Packages) scanner.ErrorList {
+	var errs scanner.ErrorList
+	for _, p := range packages {
+		// one class file per name
+		classes := newNameSpace("Java", &errs, "ColferJSON", "ColferLimits", "ColferDepthException")
+		if len(p.Consts) != 0 {
+			classes.Add("Colfer", "the constants of package "+p.Name, p.Consts[0].Pos)
+
+			consts := newNameSpace("Java", &errs)
+			for _, c := range p.Consts {
+				consts.Add(strings.ToUpper(name.SnakeCase(c.Name)), "constant "+c.String(), c.Pos)
+			}
+		}
+		for _, e := range p.Enums {
+			classes.Add(e.NameTitle(), "enumeration "+e.String(), e.Pos)
+
+			values := newNameSpace("Java", &errs)
+			for _, v := range e.Values {
+				values.Add(strings.ToUpper(name.SnakeCase(v.Name)), "enumeration value "+v.String(), v.Pos)
+			}
+		}
+		for _, u := range p.Unions {
+			classes.Add(u.NameTitle(), "union "+u.String(), u.Pos)
+		}
+		for _, s := range p.Structs {
+			classes.Add(s.NameTitle(), "struct "+s.String(), s.Pos)
+
+			fields := newNameSpace("Java", &errs, "colferSizeMax", "colferListMax", "colferDepthMax", "colferUnknown")
+			// set and with methods follow the getter; getClass is final in Object
+			accessors := newNameSpace("Java", &errs, "getClass")
+			for _, f := range s.Fields {
+				native := f.Name
+				if IsJavaKeyword(native) {
+					native += "_"
+				}
+				fields.Add(native, "field "+f.String(), f.Pos)
+				accessors.Add("get"+f.NameTitle(), "field "+f.String(), f.Pos)
+			}
+		}
+	}
+	errs.Sort()
+	return errs
+}
+
+// lintECMA mirrors the naming of GenerateECMA.
+func lintECMA(packages Packages) scanner.ErrorList {
+	var errs scanner.ErrorList
+	for _, p := range packages {
+		pkg := newNameSpace("ECMAScript", &errs)
+		for _, e := range p.Enums {
+			pkg.Add(e.NameTitle(), "enumeration "+e.String(), e.Pos)
+		}
+		for _, c := range p.Consts {
+			pkg.Add(c.Name, "constant "+c.String(), c.Pos)
+		}
+		for _, s := range p.Structs {
+			pkg.Add(s.NameTitle(), "struct "+s.String(), s.Pos)
+
+			// properties may not shadow the prototype
+			fields := newNameSpace("ECMAScript", &errs, "marshal", "unmarshal", "unmarshalSerial", "marshalJSON", "unmarshalJSON", "colferUnknown")
+			for _, f := range s.Fields {
+				native := f.Name
+				if IsECMAKeyword(native) {
+					native += "_"
+				}
+				fields.Add(native, "field "+f.String(), f.Pos)
+				if f.Type == "timestamp" {
+					fields.Add(native+"_ns", "field "+f.String(), f.Pos)
+				}
+			}
+		}
+	}
+	errs.Sort()
+	return errs
+}
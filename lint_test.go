@@ -0,0 +1,73 @@
+package colfer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	for _, gold := range []struct {
+		label, fields string
+		want          []string
+	}{
+		{"C case", "fooBar bool\n\tfoo_bar bool", []string{
+			"test.colf:5:2: C name foo_bar of field gen.a.foo_bar collides with field gen.a.fooBar at test.colf:4:2",
+		}},
+		{"C and Java keyword", "int bool\n\tint_ bool", []string{
+			"test.colf:5:2: C name int_ of field gen.a.int_ collides with field gen.a.int at test.colf:4:2",
+			"test.colf:5:2: Java name int_ of field gen.a.int_ collides with field gen.a.int at test.colf:4:2",
+		}},
+		{"Go case", "x bool\n\tX bool", []string{
+			"test.colf:5:2: C name x of field gen.a.X collides with field gen.a.x at test.colf:4:2",
+			"test.colf:5:2: Go name X of field gen.a.X collides with field gen.a.x at test.colf:4:2",
+			"test.colf:5:2: Java name getX of field gen.a.X collides with field gen.a.x at test.colf:4:2",
+		}},
+		{"Go method", "marshalTo bool\n\tmarshalLen bool", []string{
+			"test.colf:4:2: Go name MarshalTo of field gen.a.marshalTo collides with a reserved name",
+			"test.colf:5:2: Go name MarshalLen of field gen.a.marshalLen collides with a reserved name",
+		}},
+		{"Java keyword", "class bool\n\tclass_ bool", []string{
+			"test.colf:4:2: Java name getClass of field gen.a.class collides with a reserved name",
+			"test.colf:5:2: C name class of field gen.a.class_ collides with field gen.a.class at test.colf:4:2",
+			"test.colf:5:2: ECMAScript name class_ of field gen.a.class_ collides with field gen.a.class at test.colf:4:2",
+			"test.colf:5:2: Java name class_ of field gen.a.class_ collides with field gen.a.class at test.colf:4:2",
+		}},
+		{"ECMAScript keyword", "delete bool\n\tdelete_ bool", []string{
+			"test.colf:5:2: C name delete of field gen.a.delete_ collides with field gen.a.delete at test.colf:4:2",
+			"test.colf:5:2: ECMAScript name delete_ of field gen.a.delete_ collides with field gen.a.delete at test.colf:4:2",
+		}},
+		{"ECMAScript prototype", "marshal bool", []string{
+			"test.colf:4:2: ECMAScript name marshal of field gen.a.marshal collides with a reserved name",
+		}},
+	} {
+		t.Run(gold.label, func(t *testing.T) {
+			schema := "package gen\n\ntype a struct {\n\t" + gold.fields + "\n}\n"
+			verifyErrors(t, schemaErrors(t, schema, Lint), gold.want)
+		})
+	}
+}
+
+// TestLintGoTypes covers the view and the mask types, which exist in Go only.
+func TestLintGoTypes(t *testing.T) {
+	for _, kind := range []string{"View", "Mask"} {
+		schema := "package gen\n\ntype x struct {\n\ta bool\n}\n\ntype x" + kind + " struct {\n\tb bool\n}\n"
+		want := []string{
+			"test.colf:7:6: Go name X" + kind + " of struct gen.x" + kind + " collides with " + strings.ToLower(kind) + " of struct gen.x at test.colf:3:6",
+		}
+		verifyErrors(t, schemaErrors(t, schema, Lint), want)
+	}
+}
+
+// TestLintGoKeyword covers names which the generated code can not have.
+func TestLintGoKeyword(t *testing.T) {
+	// Go keywords fail on the schema syntax already.
+	errs := schemaErrors(t, "package gen\n\ntype a struct {\n\trange bool\n}\n", Lint)
+	if len(errs) == 0 || !strings.HasPrefix(errs[0], "test.colf:4:2: ") {
+		t.Errorf("field range got errors %q, want a syntax error at test.colf:4:2", errs)
+	}
+
+	want := []string{
+		"test.colf:3:6: Go name ColferMax of struct gen.colferMax collides with a reserved name",
+	}
+	verifyErrors(t, schemaErrors(t, "package gen\n\ntype colferMax struct {\n\tx bool\n}\n", Lint), want)
+}
@@ -5,24 +5,65 @@ package internal
 
 import (
 	"encoding/binary"
+	"encoding/json"
 	"fmt"
 	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
 )
 
 var intconv = binary.BigEndian
 
 // Colfer configuration attributes
 var (
-	// ColferSizeMax is the upper limit for serial byte sizes.
+	// ColferSizeMax is the upper limit for serial byte sizes. It is the
+	// SizeMax of ColferDefaults.
 	ColferSizeMax = 16 * 1024 * 1024
+	// ColferDepthMax is the upper limit for the nesting depth of data
+	// structures. It is the DepthMax of ColferDefaults.
+	ColferDepthMax = 100
 )
 
+// ColferLimits has the upper boundaries for marshalling and unmarshalling.
+// The limits apply to nested data structures too, including those from other
+// packages.
+type ColferLimits struct {
+	// SizeMax is the upper limit for serial byte sizes.
+	SizeMax int
+	// ListMax is the upper limit for the number of elements in a list or in
+	// a map.
+	ListMax int
+	// DepthMax is the upper limit for the nesting depth of data structures,
+	// with one for a struct without any nested values.
+	DepthMax int
+}
+
+// ColferDefaults returns the limits which apply when none are given. A
+// change to ColferSizeMax, ColferListMax or ColferDepthMax races with any
+// (un)marshalling in progress. The methods with explicit limits, like
+// UnmarshalWith, need no such change.
+func ColferDefaults() ColferLimits {
+	return ColferLimits{
+		SizeMax:  ColferSizeMax,
+		ListMax:  64 * 1024,
+		DepthMax: ColferDepthMax,
+	}
+}
+
 // ColferMax signals an upper limit breach.
 type ColferMax string
 
 // Error honors the error interface.
 func (m ColferMax) Error() string { return string(m) }
 
+// ColferDepth signals a breach of the nesting depth limit. Marshalling a
+// reference cycle breaches the limit too.
+type ColferDepth string
+
+// Error honors the error interface.
+func (d ColferDepth) Error() string { return string(d) }
+
 // ColferError signals a data mismatch as as a byte index.
 type ColferError int
 
@@ -39,6 +80,248 @@ func (i ColferTail) Error() string {
 	return fmt.Sprintf("colfer: data continuation at byte %d", i)
 }
 
+// colferVarint decodes the varint at data[i] from a validated serial, and
+// it returns the value with the index of the first byte after the varint.
+// The ninth byte has all 8 bits in use when capped, as with 64-bit integers.
+func colferVarint(data []byte, i int, capped bool) (uint64, int) {
+	x := uint64(data[i])
+	i++
+
+	if x >= 0x80 {
+		x &= 0x7f
+		for shift := uint(7); ; shift += 7 {
+			b := uint64(data[i])
+			i++
+
+			if b < 0x80 || capped && shift == 56 {
+				x |= b << shift
+				break
+			}
+			x |= (b & 0x7f) << shift
+		}
+	}
+	return x, i
+}
+
+// colferAppendJSONString appends s as a JSON string. Only quotation marks,
+// backslashes and control characters are escaped, like ECMAScript's
+// JSON.stringify does. Invalid UTF-8 becomes U+FFFD.
+func colferAppendJSONString(buf []byte, s string) []byte {
+	const hex = "0123456789abcdef"
+	buf = append(buf, '"')
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c >= utf8.RuneSelf {
+			r, size := utf8.DecodeRuneInString(s[i:])
+			if r == utf8.RuneError && size == 1 {
+				buf = append(buf, "\uFFFD"...)
+			} else {
+				buf = append(buf, s[i:i+size]...)
+			}
+			i += size
+			continue
+		}
+
+		switch c {
+		case '"', '\\':
+			buf = append(buf, '\\', c)
+		case '\b':
+			buf = append(buf, '\\', 'b')
+		case '\f':
+			buf = append(buf, '\\', 'f')
+		case '\n':
+			buf = append(buf, '\\', 'n')
+		case '\r':
+			buf = append(buf, '\\', 'r')
+		case '\t':
+			buf = append(buf, '\\', 't')
+		default:
+			if c < 0x20 {
+				buf = append(buf, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xf])
+			} else {
+				buf = append(buf, c)
+			}
+		}
+		i++
+	}
+	return append(buf, '"')
+}
+
+// colferAppendJSONUint appends x as a JSON number, or as a JSON string when x
+// exceeds the safe integer range of ECMAScript, which ends at 2^53 - 1.
+func colferAppendJSONUint(buf []byte, x uint64) []byte {
+	if x <= 1<<53-1 {
+		return strconv.AppendUint(buf, x, 10)
+	}
+	buf = strconv.AppendUint(append(buf, '"'), x, 10)
+	return append(buf, '"')
+}
+
+// colferAppendJSONInt appends x as a JSON number, or as a JSON string when x
+// exceeds the safe integer range of ECMAScript, which is 2^53 - 1 at most in
+// magnitude.
+func colferAppendJSONInt(buf []byte, x int64) []byte {
+	if x <= 1<<53-1 && x >= -(1<<53-1) {
+		return strconv.AppendInt(buf, x, 10)
+	}
+	buf = strconv.AppendInt(append(buf, '"'), x, 10)
+	return append(buf, '"')
+}
+
+// colferParseJSONUint reads an unsigned integer from a JSON number, or from a
+// JSON string with a number.
+func colferParseJSONUint(data []byte, bitSize int) (uint64, error) {
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(string(n), 10, bitSize)
+}
+
+// colferParseJSONInt reads a signed integer from a JSON number, or from a
+// JSON string with a number.
+func colferParseJSONInt(data []byte, bitSize int) (int64, error) {
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(string(n), 10, bitSize)
+}
+
+// ColferDecoder reads serials from an input stream.
+type ColferDecoder struct {
+	r      io.Reader
+	limits ColferLimits
+	// buf is the read buffer.
+	buf []byte
+	// offset is the index of the first data byte in buf.
+	offset int
+	// i is the index of the data end (exclusive) in buf.
+	i int
+}
+
+// NewColferDecoder returns a new decoder which reads from r, with the
+// ColferDefaults at the time of the call.
+func NewColferDecoder(r io.Reader) *ColferDecoder {
+	return NewColferDecoderWith(r, ColferDefaults())
+}
+
+// NewColferDecoderWith returns a new decoder which reads from r. The read
+// buffer grows on demand, limited by limits.SizeMax.
+func NewColferDecoderWith(r io.Reader, limits ColferLimits) *ColferDecoder {
+	size := 2048
+	if limits.SizeMax < size {
+		size = limits.SizeMax
+	}
+	return &ColferDecoder{r: r, limits: limits, buf: make([]byte, size)}
+}
+
+// Decode reads the next serial into o. The error return is io.EOF when the
+// stream ends before the serial starts, and io.ErrUnexpectedEOF when the
+// stream ends within the serial. Other error return options come from the
+// Unmarshal method of o, or from the input stream. The limits of d apply to
+// o when it has an UnmarshalWith method with ColferLimits from this package.
+// A serial which does not fit in SizeMax bytes gets a ColferMax error.
+func (d *ColferDecoder) Decode(o interface{ Unmarshal([]byte) (int, error) }) error {
+	for {
+		if d.offset < d.i {
+			var n int
+			var err error
+			if u, ok := o.(interface {
+				UnmarshalWith([]byte, ColferLimits) (int, error)
+			}); ok {
+				n, err = u.UnmarshalWith(d.buf[d.offset:d.i], d.limits)
+			} else {
+				n, err = o.Unmarshal(d.buf[d.offset:d.i])
+			}
+			if err != io.EOF {
+				if err == nil {
+					d.offset += n
+				}
+				return err
+			}
+		}
+		// not enough data
+
+		if d.offset == d.i {
+			d.offset, d.i = 0, 0
+		}
+		if d.i == len(d.buf) {
+			if d.offset == 0 {
+				if len(d.buf) >= d.limits.SizeMax {
+					return ColferMax(fmt.Sprintf("colfer: serial size exceeds %d bytes", d.limits.SizeMax))
+				}
+				// grow
+				size := len(d.buf) * 4
+				if size > d.limits.SizeMax {
+					size = d.limits.SizeMax
+				}
+				bigger := make([]byte, size)
+				copy(bigger, d.buf)
+				d.buf = bigger
+			} else {
+				// move data to start of buffer
+				d.i = copy(d.buf, d.buf[d.offset:d.i])
+				d.offset = 0
+			}
+		}
+
+		n, err := d.r.Read(d.buf[d.i:])
+		d.i += n
+		if n == 0 && err != nil {
+			if err == io.EOF && d.offset < d.i {
+				return io.ErrUnexpectedEOF
+			}
+			return err
+		}
+	}
+}
+
+// ColferEncoder writes serials to an output stream with buffering.
+type ColferEncoder struct {
+	w   io.Writer
+	buf []byte
+}
+
+// NewColferEncoder returns a new encoder which writes to w.
+func NewColferEncoder(w io.Writer) *ColferEncoder {
+	return &ColferEncoder{w: w, buf: make([]byte, 0, 4096)}
+}
+
+// Encode buffers the serial of o. The error return options come from the
+// MarshalLen method of o, or from the output stream on a flush.
+func (e *ColferEncoder) Encode(o interface {
+	MarshalLen() (int, error)
+	MarshalTo([]byte) int
+}) error {
+	l, err := o.MarshalLen()
+	if err != nil {
+		return err
+	}
+	if len(e.buf)+l > cap(e.buf) {
+		if err := e.Flush(); err != nil {
+			return err
+		}
+		if l > cap(e.buf) {
+			e.buf = make([]byte, 0, l)
+		}
+	}
+	n := len(e.buf)
+	e.buf = e.buf[:n+l]
+	o.MarshalTo(e.buf[n:])
+	return nil
+}
+
+// Flush writes any buffered data to the output stream.
+func (e *ColferEncoder) Flush() error {
+	if len(e.buf) == 0 {
+		return nil
+	}
+	_, err := e.w.Write(e.buf)
+	e.buf = e.buf[:0]
+	return err
+}
+
 // Header is a prefix for requests and responses.
 type Header struct {
 	SeqID uint64
@@ -51,7 +334,8 @@ type Header struct {
 }
 
 // MarshalTo encodes o as Colfer into buf and returns the number of bytes written.
-// If the buffer is too small, MarshalTo will panic.
+// If the buffer is too small, MarshalTo will panic. MarshalTo does no checks on
+// its own. MarshalLen gives the size, and it catches reference cycles.
 func (o *Header) MarshalTo(buf []byte) int {
 	var i int
 
@@ -121,8 +405,18 @@ func (o *Header) MarshalTo(buf []byte) int {
 }
 
 // MarshalLen returns the Colfer serial byte size.
-// The error return option is internal.ColferMax.
+// The limits are the ColferDefaults.
+// The error return options are internal.ColferMax and internal.ColferDepth.
 func (o *Header) MarshalLen() (int, error) {
+	return o.MarshalLenWith(ColferDefaults())
+}
+
+// MarshalLenWith returns the Colfer serial byte size like MarshalLen does,
+// with limits instead of the ColferDefaults.
+func (o *Header) MarshalLenWith(limits ColferLimits) (int, error) {
+	if limits.DepthMax < 1 {
+		return 0, ColferDepth("colfer: struct internal.header exceeds the nesting depth limit; check for reference cycles")
+	}
 	l := 1
 
 	if x := o.SeqID; x >= 1<<49 {
@@ -134,8 +428,8 @@ func (o *Header) MarshalLen() (int, error) {
 	}
 
 	if x := len(o.Method); x != 0 {
-		if x > ColferSizeMax {
-			return 0, ColferMax(fmt.Sprintf("colfer: field internal.header.method exceeds %d bytes", ColferSizeMax))
+		if x > limits.SizeMax {
+			return 0, ColferMax(fmt.Sprintf("colfer: field internal.header.method exceeds %d bytes", limits.SizeMax))
 		}
 		for l += x + 2; x >= 0x80; l++ {
 			x >>= 7
@@ -143,8 +437,8 @@ func (o *Header) MarshalLen() (int, error) {
 	}
 
 	if x := len(o.Error); x != 0 {
-		if x > ColferSizeMax {
-			return 0, ColferMax(fmt.Sprintf("colfer: field internal.header.error exceeds %d bytes", ColferSizeMax))
+		if x > limits.SizeMax {
+			return 0, ColferMax(fmt.Sprintf("colfer: field internal.header.error exceeds %d bytes", limits.SizeMax))
 		}
 		for l += x + 2; x >= 0x80; l++ {
 			x >>= 7
@@ -159,14 +453,29 @@ func (o *Header) MarshalLen() (int, error) {
 		}
 	}
 
-	if l > ColferSizeMax {
-		return l, ColferMax(fmt.Sprintf("colfer: struct internal.header exceeds %d bytes", ColferSizeMax))
+	if l > limits.SizeMax {
+		return l, ColferMax(fmt.Sprintf("colfer: struct internal.header exceeds %d bytes", limits.SizeMax))
 	}
 	return l, nil
 }
 
+// MarshalAppend encodes o as Colfer and appends the serial to dst, with
+// growth as with the built-in append.
+// The error return options are internal.ColferMax and
+// internal.ColferDepth, in which case dst is returned as is.
+func (o *Header) MarshalAppend(dst []byte) ([]byte, error) {
+	l, err := o.MarshalLen()
+	if err != nil {
+		return dst, err
+	}
+	offset := len(dst)
+	dst = append(dst, make([]byte, l)...)
+	o.MarshalTo(dst[offset:])
+	return dst, nil
+}
+
 // MarshalBinary encodes o as Colfer conform encoding.BinaryMarshaler.
-// The error return option is internal.ColferMax.
+// The error return options are internal.ColferMax and internal.ColferDepth.
 func (o *Header) MarshalBinary() (data []byte, err error) {
 	l, err := o.MarshalLen()
 	if err != nil {
@@ -178,8 +487,18 @@ func (o *Header) MarshalBinary() (data []byte, err error) {
 }
 
 // Unmarshal decodes data as Colfer and returns the number of bytes read.
-// The error return options are io.EOF, internal.ColferError and internal.ColferMax.
+// The limits are the ColferDefaults.
+// The error return options are io.EOF, internal.ColferError, internal.ColferMax and internal.ColferDepth.
 func (o *Header) Unmarshal(data []byte) (int, error) {
+	return o.UnmarshalWith(data, ColferDefaults())
+}
+
+// UnmarshalWith decodes data as Colfer like Unmarshal does, with limits
+// instead of the ColferDefaults.
+func (o *Header) UnmarshalWith(data []byte, limits ColferLimits) (int, error) {
+	if limits.DepthMax < 1 {
+		return 0, ColferDepth("colfer: struct internal.header exceeds the nesting depth limit")
+	}
 	if len(data) == 0 {
 		return 0, io.EOF
 	}
@@ -249,8 +568,8 @@ func (o *Header) Unmarshal(data []byte) (int, error) {
 			}
 		}
 
-		if x > uint(ColferSizeMax) {
-			return 0, ColferMax(fmt.Sprintf("colfer: internal.header.method size %d exceeds %d bytes", x, ColferSizeMax))
+		if x > uint(limits.SizeMax) {
+			return 0, ColferMax(fmt.Sprintf("colfer: internal.header.method size %d exceeds %d bytes", x, limits.SizeMax))
 		}
 
 		start := i
@@ -288,8 +607,8 @@ func (o *Header) Unmarshal(data []byte) (int, error) {
 			}
 		}
 
-		if x > uint(ColferSizeMax) {
-			return 0, ColferMax(fmt.Sprintf("colfer: internal.header.error size %d exceeds %d bytes", x, ColferSizeMax))
+		if x > uint(limits.SizeMax) {
+			return 0, ColferMax(fmt.Sprintf("colfer: internal.header.error size %d exceeds %d bytes", x, limits.SizeMax))
 		}
 
 		start := i
@@ -345,18 +664,18 @@ func (o *Header) Unmarshal(data []byte) (int, error) {
 	if header != 0x7f {
 		return 0, ColferError(i - 1)
 	}
-	if i < ColferSizeMax {
+	if i < limits.SizeMax {
 		return i, nil
 	}
 eof:
-	if i >= ColferSizeMax {
-		return 0, ColferMax(fmt.Sprintf("colfer: struct internal.header size exceeds %d bytes", ColferSizeMax))
+	if i >= limits.SizeMax {
+		return 0, ColferMax(fmt.Sprintf("colfer: struct internal.header size exceeds %d bytes", limits.SizeMax))
 	}
 	return 0, io.EOF
 }
 
 // UnmarshalBinary decodes data as Colfer conform encoding.BinaryUnmarshaler.
-// The error return options are io.EOF, internal.ColferError, internal.ColferTail and internal.ColferMax.
+// The error return options are io.EOF, internal.ColferError, internal.ColferTail, internal.ColferMax and internal.ColferDepth.
 func (o *Header) UnmarshalBinary(data []byte) error {
 	i, err := o.Unmarshal(data)
 	if i < len(data) && err == nil {
@@ -364,3 +683,450 @@ func (o *Header) UnmarshalBinary(data []byte) error {
 	}
 	return err
 }
+
+// Equal returns whether o and x have the same serial. Hence, nil and empty
+// lists, maps and binaries are equal, and so are nil and empty entries in
+// lists, maps and unions. Either of o and x may be nil.
+func (o *Header) Equal(x *Header) bool {
+	if o == nil || x == nil {
+		return o == x
+	}
+
+	if o.SeqID != x.SeqID {
+		return false
+	}
+
+	if o.Method != x.Method {
+		return false
+	}
+
+	if o.Error != x.Error {
+		return false
+	}
+
+	if o.BodySize != x.BodySize {
+		return false
+	}
+
+	return true
+}
+
+// Clone returns a deep copy of o, or nil when o is nil.
+func (o *Header) Clone() *Header {
+	if o == nil {
+		return nil
+	}
+	c := *o
+
+	return &c
+}
+
+// MarshalJSON encodes o conform the json.Marshaler interface. The JSON object
+// has the fields present in the serial only, in order of appearance, with the
+// names from the schema. Integers beyond 2^53 - 1 in magnitude are written as
+// a JSON string, as JavaScript can not hold them exactly. The mapping matches
+// the generated Java and JavaScript code, and colf(1) decode.
+func (o *Header) MarshalJSON() ([]byte, error) {
+	// each field starts with a comma, of which the first one becomes the
+	// opening brace
+	var buf []byte
+
+	if v := o.SeqID; v != 0 {
+		buf = append(buf, ",\"seqID\":"...)
+		buf = colferAppendJSONUint(buf, v)
+	}
+
+	if v := o.Method; len(v) != 0 {
+		buf = append(buf, ",\"method\":"...)
+		buf = colferAppendJSONString(buf, v)
+	}
+
+	if v := o.Error; len(v) != 0 {
+		buf = append(buf, ",\"error\":"...)
+		buf = colferAppendJSONString(buf, v)
+	}
+
+	if v := o.BodySize; v != 0 {
+		buf = append(buf, ",\"bodySize\":"...)
+		buf = strconv.AppendUint(buf, uint64(v), 10)
+	}
+
+	if len(buf) == 0 {
+		return []byte("{}"), nil
+	}
+	buf[0] = '{'
+	return append(buf, '}'), nil
+}
+
+// UnmarshalJSON decodes data conform the json.Unmarshaler interface, with the
+// mapping of MarshalJSON. Fields absent in data, or with a null value, remain
+// as is.
+func (o *Header) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	for name, raw := range fields {
+		if string(raw) == "null" {
+			continue
+		}
+
+		switch name {
+		case "seqID":
+			x, err := colferParseJSONUint(raw, 64)
+			if err != nil {
+				return fmt.Errorf("colfer: field internal.header.seqID: %s", err)
+			}
+			v := uint64(x)
+			o.SeqID = v
+		case "method":
+			var v string
+			if err := json.Unmarshal(raw, &v); err != nil {
+				return fmt.Errorf("colfer: field internal.header.method: %s", err)
+			}
+			o.Method = v
+		case "error":
+			var v string
+			if err := json.Unmarshal(raw, &v); err != nil {
+				return fmt.Errorf("colfer: field internal.header.error: %s", err)
+			}
+			o.Error = v
+		case "bodySize":
+			x, err := colferParseJSONUint(raw, 32)
+			if err != nil {
+				return fmt.Errorf("colfer: field internal.header.bodySize: %s", err)
+			}
+			v := uint32(x)
+			o.BodySize = v
+		default:
+			return fmt.Errorf("colfer: struct internal.header has no field %q", name)
+		}
+	}
+	return nil
+}
+
+// HeaderView provides read-only access to Header serials.
+// Fields decode on demand. Binary values share memory with the serial.
+// The zero value has all fields absent.
+type HeaderView struct {
+	// data is the serial.
+	data []byte
+	// index has the position of each field value in data, right after the
+	// header byte, with zero for absent.
+	index [3 + 1]int
+	// limits apply to nested data structures.
+	limits ColferLimits
+}
+
+// Unmarshal indexes data as Colfer and returns the number of bytes read.
+// The checks match Header.Unmarshal, without any allocation. The view
+// uses data as is, so the caller must keep data intact for as long as v (or
+// any of its values) is in use.
+// The limits are the ColferDefaults.
+// The error return options are io.EOF, internal.ColferError, internal.ColferMax and internal.ColferDepth.
+func (v *HeaderView) Unmarshal(data []byte) (int, error) {
+	return v.UnmarshalWith(data, ColferDefaults())
+}
+
+// UnmarshalWith indexes data as Colfer like Unmarshal does, with limits
+// instead of the ColferDefaults.
+func (v *HeaderView) UnmarshalWith(data []byte, limits ColferLimits) (int, error) {
+	if limits.DepthMax < 1 {
+		return 0, ColferDepth("colfer: struct internal.header exceeds the nesting depth limit")
+	}
+	if len(data) == 0 {
+		return 0, io.EOF
+	}
+	header := data[0]
+	i := 1
+	var index [3 + 1]int
+
+	if header == 0 {
+		index[0] = i
+		start := i
+		i++
+		if i >= len(data) {
+			goto eof
+		}
+		x := data[start]
+
+		if x >= 0x80 {
+			for shift := uint(7); ; shift += 7 {
+				b := data[i]
+				i++
+				if i >= len(data) {
+					goto eof
+				}
+
+				if b < 0x80 || shift == 56 {
+					break
+				}
+			}
+		}
+
+		header = data[i]
+		i++
+	} else if header == 0|0x80 {
+		index[0] = i
+		i += 8
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 1 {
+		index[1] = i
+		if i >= len(data) {
+			goto eof
+		}
+		x := uint(data[i])
+		i++
+
+		if x >= 0x80 {
+			x &= 0x7f
+			for shift := uint(7); ; shift += 7 {
+				if i >= len(data) {
+					goto eof
+				}
+				b := uint(data[i])
+				i++
+
+				if b < 0x80 {
+					x |= b << shift
+					break
+				}
+				x |= (b & 0x7f) << shift
+			}
+		}
+
+		if x > uint(limits.SizeMax) {
+			return 0, ColferMax(fmt.Sprintf("colfer: internal.header.method size %d exceeds %d bytes", x, limits.SizeMax))
+		}
+
+		i += int(x)
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 2 {
+		index[2] = i
+		if i >= len(data) {
+			goto eof
+		}
+		x := uint(data[i])
+		i++
+
+		if x >= 0x80 {
+			x &= 0x7f
+			for shift := uint(7); ; shift += 7 {
+				if i >= len(data) {
+					goto eof
+				}
+				b := uint(data[i])
+				i++
+
+				if b < 0x80 {
+					x |= b << shift
+					break
+				}
+				x |= (b & 0x7f) << shift
+			}
+		}
+
+		if x > uint(limits.SizeMax) {
+			return 0, ColferMax(fmt.Sprintf("colfer: internal.header.error size %d exceeds %d bytes", x, limits.SizeMax))
+		}
+
+		i += int(x)
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header == 3 {
+		index[3] = i
+		start := i
+		i++
+		if i >= len(data) {
+			goto eof
+		}
+		x := data[start]
+
+		if x >= 0x80 {
+			for shift := uint(7); ; shift += 7 {
+				b := data[i]
+				i++
+				if i >= len(data) {
+					goto eof
+				}
+
+				if b < 0x80 {
+					break
+				}
+			}
+		}
+
+		header = data[i]
+		i++
+	} else if header == 3|0x80 {
+		index[3] = i
+		i += 4
+		if i >= len(data) {
+			goto eof
+		}
+		header = data[i]
+		i++
+	}
+
+	if header != 0x7f {
+		return 0, ColferError(i - 1)
+	}
+	if i < limits.SizeMax {
+		v.data = data[:i]
+		v.index = index
+		v.limits = limits
+		return i, nil
+	}
+eof:
+	if i >= limits.SizeMax {
+		return 0, ColferMax(fmt.Sprintf("colfer: struct internal.header size exceeds %d bytes", limits.SizeMax))
+	}
+	return 0, io.EOF
+}
+
+// SeqID returns the value of field seqID, or the zero value when absent.
+func (v *HeaderView) SeqID() uint64 {
+	i := v.index[0]
+	if i == 0 {
+		return 0
+	}
+	if v.data[i-1] != 0 {
+		return intconv.Uint64(v.data[i:])
+	}
+	x, _ := colferVarint(v.data, i, true)
+	return x
+}
+
+// Method returns the value of field method, or the zero value when absent.
+func (v *HeaderView) Method() string {
+	i := v.index[1]
+	if i == 0 {
+		return ""
+	}
+	x, i := colferVarint(v.data, i, false)
+	end := i + int(x)
+	return string(v.data[i:end])
+}
+
+// Error returns the value of field error, or the zero value when absent.
+func (v *HeaderView) Error() string {
+	i := v.index[2]
+	if i == 0 {
+		return ""
+	}
+	x, i := colferVarint(v.data, i, false)
+	end := i + int(x)
+	return string(v.data[i:end])
+}
+
+// BodySize returns the value of field bodySize, or the zero value when absent.
+func (v *HeaderView) BodySize() uint32 {
+	i := v.index[3]
+	if i == 0 {
+		return 0
+	}
+	if v.data[i-1] != 3 {
+		return intconv.Uint32(v.data[i:])
+	}
+	x, _ := colferVarint(v.data, i, false)
+	return uint32(x)
+}
+
+// HeaderMask is a field selection for Header.UnmarshalMask.
+// The zero value selects nothing.
+type HeaderMask struct {
+	// whole flags the fields selected in full, by index.
+	whole [3 + 1]bool
+}
+
+// Add selects fields by their name in the schema. Paths with dots select the
+// fields of nested data structures, which applies to each element in lists,
+// and to each value in maps. Unions can only be selected as a whole.
+func (m *HeaderMask) Add(paths ...string) error {
+	for _, path := range paths {
+		name := path
+		if dot := strings.IndexByte(path, '.'); dot >= 0 {
+			name = path[:dot]
+		}
+
+		switch name {
+		case "seqID":
+			if name != path {
+				return fmt.Errorf("colfer: field internal.header.seqID has no fields to select with %q", path)
+			}
+			m.whole[0] = true
+		case "method":
+			if name != path {
+				return fmt.Errorf("colfer: field internal.header.method has no fields to select with %q", path)
+			}
+			m.whole[1] = true
+		case "error":
+			if name != path {
+				return fmt.Errorf("colfer: field internal.header.error has no fields to select with %q", path)
+			}
+			m.whole[2] = true
+		case "bodySize":
+			if name != path {
+				return fmt.Errorf("colfer: field internal.header.bodySize has no fields to select with %q", path)
+			}
+			m.whole[3] = true
+		default:
+			return fmt.Errorf("colfer: struct internal.header has no field %q", name)
+		}
+	}
+	return nil
+}
+
+// UnmarshalMask decodes the fields selected by mask only, and it returns the
+// number of bytes read. The checks match Unmarshal, with the fields which are
+// not selected skipped without any allocation.
+// The limits are the ColferDefaults.
+// The error return options are io.EOF, internal.ColferError, internal.ColferMax and internal.ColferDepth.
+func (o *Header) UnmarshalMask(data []byte, mask *HeaderMask) (int, error) {
+	return o.UnmarshalMaskWith(data, mask, ColferDefaults())
+}
+
+// UnmarshalMaskWith decodes like UnmarshalMask does, with limits instead of
+// the ColferDefaults.
+func (o *Header) UnmarshalMaskWith(data []byte, mask *HeaderMask, limits ColferLimits) (int, error) {
+	var v HeaderView
+	n, err := v.UnmarshalWith(data, limits)
+	if err != nil {
+		return 0, err
+	}
+
+	if mask.whole[0] && v.index[0] != 0 {
+		o.SeqID = v.SeqID()
+	}
+
+	if mask.whole[1] && v.index[1] != 0 {
+		o.Method = v.Method()
+	}
+
+	if mask.whole[2] && v.index[2] != 0 {
+		o.Error = v.Error()
+	}
+
+	if mask.whole[3] && v.index[3] != 0 {
+		o.BodySize = v.BodySize()
+	}
+
+	return n, nil
+}
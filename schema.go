@@ -4,13 +4,24 @@ import (
 	"bytes"
 	"fmt"
 	"go/ast"
+	"go/constant"
 	"go/format"
 	"go/parser"
+	"go/scanner"
 	"go/token"
 	"io/ioutil"
+	"math"
 	"path"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 )
 
+// maxIndex is the upper limit for field indexes, as 127 marks the end of
+// a serial.
+const maxIndex = 126
+
 // Format normalizes the file's content.
 // The content of file is expected to be syntactically correct.
 func Format(file string) (changed bool, err error) {
@@ -34,15 +45,24 @@ func Format(file string) (changed bool, err error) {
 	return true, nil
 }
 
-// ParseFiles returns the schema definitions.
+// ParseFiles returns the schema definitions. Syntax and semantic errors are
+// reported as a scanner.ErrorList, sorted by position.
 func ParseFiles(files []string) ([]*Package, error) {
 	var packages []*Package
+	// constant declarations pending type resolution
+	var consts []*constSpec
+	var errs scanner.ErrorList
 
 	fileSet := token.NewFileSet()
 	for _, file := range files {
 		fileAST, err := parser.ParseFile(fileSet, file, nil, parser.ParseComments|parser.AllErrors)
 		if err != nil {
-			return nil, err
+			list, ok := err.(scanner.ErrorList)
+			if !ok {
+				return nil, err
+			}
+			errs = append(errs, list...)
+			continue
 		}
 
 		var pkg *Package
@@ -64,117 +84,665 @@ func ParseFiles(files []string) ([]*Package, error) {
 		for _, decl := range fileAST.Decls {
 			switch decl := decl.(type) {
 			default:
-				return nil, fmt.Errorf("colfer: unsupported declaration type %T", decl)
+				errs.Add(fileSet.Position(decl.Pos()), fmt.Sprintf("unsupported declaration type %T", decl))
 			case *ast.GenDecl:
+				if decl.Tok == token.CONST {
+					consts = append(consts, mapConsts(pkg, decl, fileSet, &errs)...)
+					continue
+				}
 				for _, spec := range decl.Specs {
-					if err := addSpec(pkg, decl, spec, file); err != nil {
-						return nil, err
-					}
+					addSpec(pkg, decl, spec, fileSet, &errs)
 				}
 			}
 		}
 	}
 
 	names := make(map[string]*Struct)
+	enums := make(map[string]*Enum)
+	unions := make(map[string]*Union)
+	// positions of all type definitions
+	defs := make(map[string]token.Position)
 	for _, pkg := range packages {
 		for _, s := range pkg.Structs {
 			qname := s.String()
-			if dupe, ok := names[qname]; ok {
-				return nil, fmt.Errorf("colfer: duplicate struct definition %q in file %s and %s", qname, dupe.SchemaFile, s.SchemaFile)
+			if pos, ok := defs[qname]; ok {
+				errs.Add(s.Pos, fmt.Sprintf("duplicate struct definition %q; first at %s", qname, pos))
+				continue
 			}
+			defs[qname] = s.Pos
 			names[qname] = s
 		}
+		for _, e := range pkg.Enums {
+			qname := e.String()
+			if pos, ok := defs[qname]; ok {
+				errs.Add(e.Pos, fmt.Sprintf("duplicate enumeration definition %q; first at %s", qname, pos))
+				continue
+			}
+			defs[qname] = e.Pos
+			enums[qname] = e
+		}
+		for _, u := range pkg.Unions {
+			qname := u.String()
+			if pos, ok := defs[qname]; ok {
+				errs.Add(u.Pos, fmt.Sprintf("duplicate union definition %q; first at %s", qname, pos))
+				continue
+			}
+			defs[qname] = u.Pos
+			unions[qname] = u
+		}
 	}
 
 	for _, pkg := range packages {
-		for _, s := range pkg.Structs {
-			for _, f := range s.Fields {
-				t := f.Type
-				_, ok := datatypes[t]
-				if ok {
-					if f.TypeList {
-						switch t {
-						case "float32", "float64", "text", "binary":
-						default:
-							return nil, fmt.Errorf("colfer: unsupported lists type %q for field %s", t, f.String())
-						}
-					}
-					continue
-				}
-				if f.TypeRef, ok = names[t]; ok {
+		for _, u := range pkg.Unions {
+			for _, m := range u.Members {
+				s, ok := names[pkg.Name+"."+m.Name]
+				if !ok {
+					errs.Add(u.Pos, fmt.Sprintf("unknown data structure %q for union %s", m.Name, u))
 					continue
 				}
-				if f.TypeRef, ok = names[pkg.Name+"."+t]; ok {
-					continue
+				m.Struct = s
+				s.Unions = append(s.Unions, u)
+			}
+		}
+	}
+
+	constPos := make(map[string]token.Position)
+	for _, c := range consts {
+		qname := c.Pkg.Name + "." + c.Name
+		if pos, ok := constPos[qname]; ok {
+			errs.Add(c.Pos, fmt.Sprintf("duplicate constant definition %s; first at %s", qname, pos))
+			continue
+		}
+		constPos[qname] = c.Pos
+		if pos, ok := defs[qname]; ok {
+			errs.Add(c.Pos, fmt.Sprintf("duplicate definition %q; first at %s", qname, pos))
+			continue
+		}
+
+		if e, ok := enums[c.Pkg.Name+"."+c.Type]; ok {
+			if err := e.addValue(c); err != nil {
+				errs.Add(c.Pos, err.Error())
+			}
+			continue
+		}
+
+		v, err := mapConst(c)
+		if err != nil {
+			errs.Add(c.Pos, err.Error())
+			continue
+		}
+		c.Pkg.Consts = append(c.Pkg.Consts, v)
+	}
+
+	for _, pkg := range packages {
+		for _, s := range pkg.Structs {
+			for _, f := range s.Fields {
+				if err := resolveType(f, names, enums, unions); err != nil {
+					errs.Add(f.Pos, err.Error())
 				}
-				return nil, fmt.Errorf("colfer: unknown datatype %q for field %s", t, f.String())
 			}
 		}
 	}
 
+	errs.Sort()
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
 	return packages, nil
 }
 
-func addSpec(pkg *Package, decl *ast.GenDecl, spec ast.Spec, file string) error {
+// resolveType links the datatype of f to its definition.
+func resolveType(f *Field, names map[string]*Struct, enums map[string]*Enum, unions map[string]*Union) error {
+	var ok bool
+	t := f.Type
+	local := f.Struct.Pkg.Name + "." + t
+	if f.TypeEnum, ok = enums[t]; !ok {
+		f.TypeEnum, ok = enums[local]
+	}
+	if ok {
+		f.Type = f.TypeEnum.Type
+		return nil
+	}
+
+	if f.TypeUnion, ok = unions[t]; !ok {
+		f.TypeUnion, ok = unions[local]
+	}
+	if ok {
+		if f.TypeList || f.TypeKey != "" || f.Optional {
+			return fmt.Errorf("unsupported union nesting for field %s", f)
+		}
+		return nil
+	}
+
+	if _, ok = datatypes[t]; ok {
+		if f.Optional && (t == "timestamp" || t == "text" || t == "binary") {
+			return fmt.Errorf("unsupported optional type %q for field %s", t, f)
+		}
+		if f.TypeList && t == "timestamp" {
+			return fmt.Errorf("unsupported lists type %q for field %s", t, f)
+		}
+		if f.TypeKey != "" && t == "timestamp" {
+			return fmt.Errorf("unsupported map value type %q for field %s", t, f)
+		}
+		return nil
+	}
+
+	if f.TypeRef, ok = names[t]; !ok {
+		f.TypeRef, ok = names[local]
+	}
+	if ok {
+		if f.Optional {
+			return fmt.Errorf("unsupported optional data structure for field %s", f)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown datatype %q for field %s", t, f)
+}
+
+func addSpec(pkg *Package, decl *ast.GenDecl, spec ast.Spec, fileSet *token.FileSet, errs *scanner.ErrorList) {
+	pos := fileSet.Position(spec.Pos())
+	file := path.Base(pos.Filename)
+
 	switch spec := spec.(type) {
 	default:
-		return fmt.Errorf("colfer: unsupported specification type %T", spec)
+		errs.Add(pos, fmt.Sprintf("unsupported specification type %T", spec))
 	case *ast.TypeSpec:
 		switch t := spec.Type.(type) {
 		default:
-			return fmt.Errorf("colfer: unsupported data type %T", t)
+			errs.Add(pos, fmt.Sprintf("unsupported data type %T", t))
+		case *ast.Ident:
+			if t.Name != "uint8" && t.Name != "uint16" && t.Name != "uint32" {
+				errs.Add(pos, fmt.Sprintf("unsupported enumeration type %q for %s.%s", t.Name, pkg.Name, spec.Name.Name))
+				return
+			}
+			e := &Enum{Pkg: pkg, Name: spec.Name.Name, Type: t.Name, SchemaFile: file, Pos: pos}
+			e.Docs = append(docs(decl.Doc), docs(spec.Doc)...)
+			pkg.Enums = append(pkg.Enums, e)
+		case *ast.InterfaceType:
+			u := &Union{Pkg: pkg, Name: spec.Name.Name, SchemaFile: file, Pos: pos}
+			pkg.Unions = append(pkg.Unions, u)
+
+			u.Docs = append(docs(decl.Doc), docs(spec.Doc)...)
+			if err := mapUnion(u, t); err != nil {
+				errs.Add(pos, err.Error())
+			}
 		case *ast.StructType:
-			s := &Struct{Pkg: pkg, Name: spec.Name.Name, SchemaFile: path.Base(file)}
+			s := &Struct{Pkg: pkg, Name: spec.Name.Name, SchemaFile: file, Pos: pos}
 			pkg.Structs = append(pkg.Structs, s)
 
 			s.Docs = append(docs(decl.Doc), docs(spec.Doc)...)
-			if err := mapStruct(s, t); err != nil {
-				return err
+			mapStruct(s, t, fileSet, errs)
+		}
+	}
+}
+
+func mapStruct(dst *Struct, src *ast.StructType, fileSet *token.FileSet, errs *scanner.ErrorList) {
+	indexes := make(map[int]*Field)
+	names := make(map[string]*Field)
+	// indexes continue from the preceding field
+	var next int
+	for _, f := range src.Fields.List {
+		field := &Field{Struct: dst, Index: next, Pos: fileSet.Position(f.Pos())}
+		next++
+		if err := mapField(field, f); err != nil {
+			errs.Add(field.Pos, err.Error())
+			continue
+		}
+		next = field.Index + 1
+
+		if field.Index > maxIndex {
+			errs.Add(field.Pos, fmt.Sprintf("index %d of field %s exceeds %d", field.Index, field, maxIndex))
+			continue
+		}
+		if dupe, ok := indexes[field.Index]; ok {
+			errs.Add(field.Pos, fmt.Sprintf("duplicate index %d for field %s and %s", field.Index, dupe, field))
+			continue
+		}
+		if dupe, ok := names[field.Name]; ok {
+			errs.Add(field.Pos, fmt.Sprintf("duplicate field name %s; first at %s", field, dupe.Pos))
+			continue
+		}
+		indexes[field.Index] = field
+		names[field.Name] = field
+		dst.Fields = append(dst.Fields, field)
+	}
+
+	// serials require ascending indexes
+	sort.SliceStable(dst.Fields, func(i, j int) bool {
+		return dst.Fields[i].Index < dst.Fields[j].Index
+	})
+}
+
+// mapField applies the declaration of src.
+func mapField(field *Field, src *ast.Field) error {
+	if len(src.Names) == 0 {
+		return fmt.Errorf("missing name for field %d of %s", field.Index, field.Struct)
+	}
+	field.Name = src.Names[0].Name
+
+	field.Docs = docs(src.Doc)
+
+	expr := src.Type
+	for {
+		switch t := expr.(type) {
+		case *ast.StarExpr:
+			if field.TypeList || field.TypeKey != "" || field.Optional {
+				return fmt.Errorf("unsupported optional nesting for field %s", field)
+			}
+			expr = t.X
+			field.Optional = true
+			continue
+		case *ast.ArrayType:
+			if field.Optional {
+				return fmt.Errorf("unsupported optional list for field %s", field)
+			}
+			if field.TypeKey != "" {
+				return fmt.Errorf("unsupported list values for map field %s", field)
 			}
+			if field.TypeList {
+				return fmt.Errorf("unsupported list nesting for field %s", field)
+			}
+			expr = t.Elt
+			field.TypeList = true
+			continue
+		case *ast.MapType:
+			if field.Optional {
+				return fmt.Errorf("unsupported optional map for field %s", field)
+			}
+			if field.TypeList || field.TypeKey != "" {
+				return fmt.Errorf("unsupported map nesting for field %s", field)
+			}
+			key, ok := t.Key.(*ast.Ident)
+			if !ok {
+				return fmt.Errorf("unsupported map key declaration %T for field %s", t.Key, field)
+			}
+			switch key.Name {
+			case "text", "uint8", "uint16", "uint32", "uint64", "int32", "int64":
+				field.TypeKey = key.Name
+			default:
+				return fmt.Errorf("unsupported map key type %q for field %s", key.Name, field)
+			}
+			expr = t.Value
+			continue
+		case *ast.Ident:
+			field.Type = t.Name
+		case *ast.SelectorExpr:
+			switch pkgIdent := t.X.(type) {
+			case *ast.Ident:
+				field.Type = pkgIdent.Name + "." + t.Sel.Name
+			default:
+				return fmt.Errorf("unknown datatype selector expression %T for field %s", pkgIdent, field)
+			}
+		default:
+			return fmt.Errorf("unknown datatype declaration %T for field %s", t, field)
 		}
+		break
 	}
 
+	if src.Tag != nil {
+		return mapTag(field, src.Tag)
+	}
 	return nil
 }
 
-func mapStruct(dst *Struct, src *ast.StructType) error {
-	for i, f := range src.Fields.List {
-		field := Field{Struct: dst, Index: i}
-		dst.Fields = append(dst.Fields, &field)
-
-		if len(f.Names) == 0 {
-			return fmt.Errorf("colfer: missing name for field %d", i)
+func mapUnion(dst *Union, src *ast.InterfaceType) error {
+	for i, m := range src.Methods.List {
+		ident, ok := m.Type.(*ast.Ident)
+		if !ok || len(m.Names) != 0 {
+			return fmt.Errorf("unsupported member declaration %d for union %s; want a data structure name from the same package", i, dst)
 		}
-		field.Name = f.Names[0].Name
+		if i > maxIndex {
+			return fmt.Errorf("union %s exceeds %d members", dst, maxIndex+1)
+		}
+		for _, dupe := range dst.Members {
+			if dupe.Name == ident.Name {
+				return fmt.Errorf("duplicate member %s for union %s", ident.Name, dst)
+			}
+		}
+		dst.Members = append(dst.Members, &UnionMember{Union: dst, Index: i, Name: ident.Name})
+	}
+	if len(dst.Members) == 0 {
+		return fmt.Errorf("union %s has no members", dst)
+	}
+	return nil
+}
 
-		field.Docs = docs(f.Doc)
+// mapTag applies the options from a struct tag.
+func mapTag(field *Field, lit *ast.BasicLit) error {
+	tag, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return fmt.Errorf("malformed tag %s for field %s", lit.Value, field.String())
+	}
+	options, ok := reflect.StructTag(tag).Lookup("colfer")
+	if !ok {
+		return nil
+	}
 
-		expr := f.Type
-		for {
-			switch t := expr.(type) {
-			case *ast.ArrayType:
-				expr = t.Elt
-				field.TypeList = true
-				continue
-			case *ast.Ident:
-				field.Type = t.Name
-			case *ast.SelectorExpr:
-				switch pkgIdent := t.X.(type) {
-				case *ast.Ident:
-					field.Type = pkgIdent.Name + "." + t.Sel.Name
-				default:
-					return fmt.Errorf("colfer: unknown datatype selector expression %T for field %s", pkgIdent, field.String())
+	var hasIndex bool
+	for _, option := range strings.Split(options, ",") {
+		if i := strings.IndexByte(option, '='); i >= 0 {
+			key, value := option[:i], option[i+1:]
+			// limits must fit in a signed 32-bit integer
+			n, err := strconv.ParseUint(value, 10, 31)
+			if err != nil || n == 0 {
+				return fmt.Errorf("malformed tag value %q for option %s on field %s", value, key, field.String())
+			}
+
+			switch key {
+			case "max":
+				if field.Type != "text" && field.Type != "binary" && field.TypeKey != "text" {
+					return fmt.Errorf("tag option max requires text or binary for field %s", field.String())
 				}
+				field.SizeMax = value
+			case "list":
+				if !field.TypeList && field.TypeKey == "" {
+					return fmt.Errorf("tag option list requires a list or map for field %s", field.String())
+				}
+				field.ListMax = value
 			default:
-				return fmt.Errorf("colfer: unknown datatype declaration %T for field %s", t, field.String())
+				return fmt.Errorf("unsupported tag option %q for field %s", key, field.String())
 			}
-			break
+			continue
+		}
+
+		i, err := strconv.ParseUint(option, 10, 8)
+		if err != nil {
+			return fmt.Errorf("unsupported tag option %q for field %s", option, field.String())
+		}
+		if hasIndex {
+			return fmt.Errorf("multiple indexes in tag for field %s", field.String())
 		}
+		hasIndex = true
+		field.Index = int(i)
 	}
+	return nil
+}
+
+// constSpec is a constant definition with an unresolved type.
+type constSpec struct {
+	Pkg   *Package
+	Name  string
+	Docs  []string
+	Type  string
+	Value constant.Value
+	// SchemaFile is the source filename.
+	SchemaFile string
+	// Pos is the location of the declaration.
+	Pos token.Position
+}
+
+// mapConsts returns the definitions from a constant declaration group.
+func mapConsts(pkg *Package, decl *ast.GenDecl, fileSet *token.FileSet, errs *scanner.ErrorList) []*constSpec {
+	var specs []*constSpec
+
+	// implicit repetition of the last non-empty expression list
+	var typ ast.Expr
+	var values []ast.Expr
 
+	for iota, spec := range decl.Specs {
+		pos := fileSet.Position(spec.Pos())
+		spec, ok := spec.(*ast.ValueSpec)
+		if !ok {
+			errs.Add(pos, fmt.Sprintf("unsupported specification type %T", spec))
+			continue
+		}
+		if spec.Type != nil || len(spec.Values) != 0 {
+			typ, values = spec.Type, spec.Values
+		}
+
+		for i, ident := range spec.Names {
+			pos := fileSet.Position(ident.Pos())
+			c := &constSpec{Pkg: pkg, Name: ident.Name, SchemaFile: path.Base(pos.Filename), Pos: pos}
+			c.Docs = docs(spec.Doc)
+			if len(decl.Specs) == 1 {
+				c.Docs = append(docs(decl.Doc), c.Docs...)
+			}
+
+			t, ok := typ.(*ast.Ident)
+			if !ok {
+				errs.Add(pos, fmt.Sprintf("missing type for constant %s.%s", pkg.Name, c.Name))
+				continue
+			}
+			c.Type = t.Name
+
+			if i >= len(values) {
+				errs.Add(pos, fmt.Sprintf("missing value for constant %s.%s", pkg.Name, c.Name))
+				continue
+			}
+			v, err := constValue(values[i], iota, specs)
+			if err != nil {
+				errs.Add(pos, fmt.Sprintf("constant %s.%s: %s", pkg.Name, c.Name, err))
+				continue
+			}
+			c.Value = v
+
+			specs = append(specs, c)
+		}
+		if len(values) > len(spec.Names) {
+			errs.Add(pos, fmt.Sprintf("extra value for constant %s.%s", pkg.Name, spec.Names[0].Name))
+		}
+	}
+
+	return specs
+}
+
+// constValue evaluates a constant expression. Identifiers may
+// refer to iota or to any of the preceding definitions in the group.
+func constValue(x ast.Expr, iota int, preceding []*constSpec) (constant.Value, error) {
+	switch x := x.(type) {
+	case *ast.BasicLit:
+		v := constant.MakeFromLiteral(x.Value, x.Kind, 0)
+		switch v.Kind() {
+		case constant.Unknown:
+			return nil, fmt.Errorf("malformed literal %s", x.Value)
+		case constant.Complex:
+			return nil, fmt.Errorf("unsupported literal %s", x.Value)
+		}
+		return v, nil
+
+	case *ast.Ident:
+		switch x.Name {
+		case "iota":
+			return constant.MakeInt64(int64(iota)), nil
+		case "true":
+			return constant.MakeBool(true), nil
+		case "false":
+			return constant.MakeBool(false), nil
+		}
+		for i := len(preceding) - 1; i >= 0; i-- {
+			if preceding[i].Name == x.Name {
+				return preceding[i].Value, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown identifier %q", x.Name)
+
+	case *ast.ParenExpr:
+		return constValue(x.X, iota, preceding)
+
+	case *ast.UnaryExpr:
+		v, err := constValue(x.X, iota, preceding)
+		if err != nil {
+			return nil, err
+		}
+		var ok bool
+		switch x.Op {
+		case token.ADD, token.SUB:
+			ok = isNumeric(v)
+		case token.XOR:
+			ok = v.Kind() == constant.Int
+		case token.NOT:
+			ok = v.Kind() == constant.Bool
+		default:
+			return nil, fmt.Errorf("unsupported operator %s", x.Op)
+		}
+		if !ok {
+			return nil, fmt.Errorf("illegal operation %s%s", x.Op, v)
+		}
+		return constant.UnaryOp(x.Op, v, 0), nil
+
+	case *ast.BinaryExpr:
+		a, err := constValue(x.X, iota, preceding)
+		if err != nil {
+			return nil, err
+		}
+		b, err := constValue(x.Y, iota, preceding)
+		if err != nil {
+			return nil, err
+		}
+
+		switch x.Op {
+		case token.SHL, token.SHR:
+			n := constant.ToInt(b)
+			if a.Kind() != constant.Int || n.Kind() != constant.Int {
+				return nil, fmt.Errorf("illegal shift %s %s %s", a, x.Op, b)
+			}
+			s, ok := constant.Uint64Val(n)
+			if !ok || s > 1024 {
+				return nil, fmt.Errorf("illegal shift %s %s %s", a, x.Op, b)
+			}
+			return constant.Shift(a, x.Op, uint(s)), nil
+
+		case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ:
+			var ok bool
+			switch {
+			case isNumeric(a) && isNumeric(b):
+				ok = true
+			case a.Kind() == constant.String && b.Kind() == constant.String:
+				ok = true
+			case a.Kind() == constant.Bool && b.Kind() == constant.Bool:
+				// booleans are not ordered
+				ok = x.Op == token.EQL || x.Op == token.NEQ
+			}
+			if !ok {
+				return nil, fmt.Errorf("mismatched types %s %s %s", a, x.Op, b)
+			}
+			return constant.MakeBool(constant.Compare(a, x.Op, b)), nil
+		}
+
+		var ok bool
+		switch x.Op {
+		case token.ADD:
+			ok = isNumeric(a) && isNumeric(b) || a.Kind() == constant.String && b.Kind() == constant.String
+		case token.SUB, token.MUL, token.QUO:
+			ok = isNumeric(a) && isNumeric(b)
+		case token.REM, token.AND, token.OR, token.XOR, token.AND_NOT:
+			ok = a.Kind() == constant.Int && b.Kind() == constant.Int
+		case token.LAND, token.LOR:
+			ok = a.Kind() == constant.Bool && b.Kind() == constant.Bool
+		default:
+			return nil, fmt.Errorf("unsupported operator %s", x.Op)
+		}
+		if !ok {
+			return nil, fmt.Errorf("illegal operation %s %s %s", a, x.Op, b)
+		}
+
+		if x.Op == token.QUO || x.Op == token.REM {
+			if constant.Sign(b) == 0 {
+				return nil, fmt.Errorf("division by zero")
+			}
+			if x.Op == token.QUO && a.Kind() == constant.Int && b.Kind() == constant.Int {
+				return constant.BinaryOp(a, token.QUO_ASSIGN, b), nil
+			}
+		}
+		return constant.BinaryOp(a, x.Op, b), nil
+	}
+
+	return nil, fmt.Errorf("unsupported expression %T", x)
+}
+
+// isNumeric returns whether v is an integer or a floating point.
+func isNumeric(v constant.Value) bool {
+	k := v.Kind()
+	return k == constant.Int || k == constant.Float
+}
+
+// addValue appends c as a named value.
+func (e *Enum) addValue(c *constSpec) error {
+	v := constant.ToInt(c.Value)
+	if v.Kind() != constant.Int {
+		return fmt.Errorf("constant %s.%s value %s is not an integer", c.Pkg.Name, c.Name, c.Value)
+	}
+
+	var max uint64
+	switch e.Type {
+	case "uint8":
+		max = math.MaxUint8
+	case "uint16":
+		max = math.MaxUint16
+	default:
+		// C enumeration constants are limited to the range of int
+		max = math.MaxInt32
+	}
+	x, exact := constant.Uint64Val(v)
+	if !exact || x > max {
+		return fmt.Errorf("constant %s.%s value %s overflows enumeration %s (%s)", c.Pkg.Name, c.Name, c.Value, e, e.Type)
+	}
+
+	e.Values = append(e.Values, &EnumValue{Enum: e, Name: c.Name, Docs: c.Docs, Value: x, Pos: c.Pos})
 	return nil
 }
 
+// mapConst returns c as a named value of a Colfer datatype.
+func mapConst(c *constSpec) (*Const, error) {
+	dst := &Const{Pkg: c.Pkg, Name: c.Name, Docs: c.Docs, Type: c.Type, SchemaFile: c.SchemaFile, Pos: c.Pos}
+
+	v := c.Value
+	switch c.Type {
+	default:
+		return nil, fmt.Errorf("unsupported type %q for constant %s", c.Type, dst)
+
+	case "bool":
+		if v.Kind() != constant.Bool {
+			return nil, fmt.Errorf("constant %s value %s is not a boolean", dst, c.Value)
+		}
+
+	case "text":
+		if v.Kind() != constant.String {
+			return nil, fmt.Errorf("constant %s value %s is not text", dst, c.Value)
+		}
+
+	case "float32", "float64":
+		v = constant.ToFloat(v)
+		if v.Kind() != constant.Float {
+			return nil, fmt.Errorf("constant %s value %s is not a number", dst, c.Value)
+		}
+		max := math.MaxFloat64
+		if c.Type == "float32" {
+			max = math.MaxFloat32
+		}
+		if f, _ := constant.Float64Val(v); math.IsInf(f, 0) || math.Abs(f) > max {
+			return nil, fmt.Errorf("constant %s value %s overflows %s", dst, c.Value, c.Type)
+		}
+
+	case "uint8", "uint16", "uint32", "uint64", "int32", "int64":
+		v = constant.ToInt(v)
+		if v.Kind() != constant.Int {
+			return nil, fmt.Errorf("constant %s value %s is not an integer", dst, c.Value)
+		}
+		var min, max constant.Value
+		switch c.Type {
+		case "uint8":
+			min, max = constant.MakeInt64(0), constant.MakeUint64(math.MaxUint8)
+		case "uint16":
+			min, max = constant.MakeInt64(0), constant.MakeUint64(math.MaxUint16)
+		case "uint32":
+			min, max = constant.MakeInt64(0), constant.MakeUint64(math.MaxUint32)
+		case "uint64":
+			min, max = constant.MakeInt64(0), constant.MakeUint64(math.MaxUint64)
+		case "int32":
+			min, max = constant.MakeInt64(math.MinInt32), constant.MakeInt64(math.MaxInt32)
+		case "int64":
+			min, max = constant.MakeInt64(math.MinInt64), constant.MakeInt64(math.MaxInt64)
+		}
+		if constant.Compare(v, token.LSS, min) || constant.Compare(v, token.GTR, max) {
+			return nil, fmt.Errorf("constant %s value %s overflows %s", dst, c.Value, c.Type)
+		}
+	}
+
+	dst.Value = v
+	return dst, nil
+}
+
 func docs(g *ast.CommentGroup) []string {
 	var a []string
 	if g != nil {
@@ -0,0 +1,140 @@
+package colfer
+
+import (
+	"fmt"
+	"go/scanner"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// schemaErrors returns the errors of ParseFiles on schema as text, with the
+// base name of the file in the positions. Check, when not nil, runs on the
+// packages parsed.
+func schemaErrors(t *testing.T, schema string, check func(Packages) error) []string {
+	dir, err := ioutil.TempDir("", "colfer-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "test.colf")
+	if err := ioutil.WriteFile(file, []byte(schema), 0644); err != nil {
+		t.Fatal(err)
+	}
+	packages, err := ParseFiles([]string{file})
+	if err == nil && check != nil {
+		err = check(packages)
+	}
+	if err == nil {
+		return nil
+	}
+	list, ok := err.(scanner.ErrorList)
+	if !ok {
+		t.Fatalf("got error type %T, want a scanner.ErrorList", err)
+	}
+	var errs []string
+	for _, e := range list {
+		msg := strings.Replace(e.Msg, dir+string(filepath.Separator), "", -1)
+		errs = append(errs, fmt.Sprintf("%s:%d:%d: %s", filepath.Base(e.Pos.Filename), e.Pos.Line, e.Pos.Column, msg))
+	}
+	return errs
+}
+
+// verifyErrors compares the error lines in order.
+func verifyErrors(t *testing.T, got, want []string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Errorf("got %d errors, want %d", len(got), len(want))
+	}
+	for i := 0; i < len(got) && i < len(want); i++ {
+		if got[i] != want[i] {
+			t.Errorf("error %d: got %q, want %q", i, got[i], want[i])
+		}
+	}
+	for i := len(want); i < len(got); i++ {
+		t.Errorf("unexpected error %q", got[i])
+	}
+}
+
+func TestConstErrors(t *testing.T) {
+	for _, gold := range []struct{ expr, want string }{
+		{"!1", "test.colf:6:2: constant gen.red: illegal operation !1"},
+		{"^true", "test.colf:6:2: constant gen.red: illegal operation ^true"},
+		{"-\"a\"", "test.colf:6:2: constant gen.red: illegal operation -\"a\""},
+		{"1.5 % 2", "test.colf:6:2: constant gen.red: illegal operation 1.5 % 2"},
+		{"1 << 2.5", "test.colf:6:2: constant gen.red: illegal shift 1 << 2.5"},
+		{"1 << -1", "test.colf:6:2: constant gen.red: illegal shift 1 << -1"},
+		{"\"a\" << 1", "test.colf:6:2: constant gen.red: illegal shift \"a\" << 1"},
+		{"1 && 2", "test.colf:6:2: constant gen.red: illegal operation 1 && 2"},
+		{"\"a\" + 1", "test.colf:6:2: constant gen.red: illegal operation \"a\" + 1"},
+		{"\"a\" * 2", "test.colf:6:2: constant gen.red: illegal operation \"a\" * 2"},
+		{"1 % 0", "test.colf:6:2: constant gen.red: division by zero"},
+		{"1 / 0.0", "test.colf:6:2: constant gen.red: division by zero"},
+		{"true < false", "test.colf:6:2: constant gen.red: mismatched types true < false"},
+		{"1 == \"1\"", "test.colf:6:2: constant gen.red: mismatched types 1 == \"1\""},
+		{"2i", "test.colf:6:2: constant gen.red: unsupported literal 2i"},
+	} {
+		errs := schemaErrors(t, "package gen\n\ntype color uint8\n\nconst (\n\tred color = "+gold.expr+"\n)\n", nil)
+		if len(errs) != 1 || errs[0] != gold.want {
+			t.Errorf("enumeration %s: got errors %q, want %q", gold.expr, errs, gold.want)
+		}
+	}
+
+	// typed constants take the same path
+	for _, gold := range []struct{ expr, want string }{
+		{"!1", "test.colf:4:2: constant gen.c: illegal operation !1"},
+		{"\"a\" + 1", "test.colf:4:2: constant gen.c: illegal operation \"a\" + 1"},
+		{"1 && 2", "test.colf:4:2: constant gen.c: illegal operation 1 && 2"},
+	} {
+		errs := schemaErrors(t, "package gen\n\nconst (\n\tc text = "+gold.expr+"\n)\n", nil)
+		if len(errs) != 1 || errs[0] != gold.want {
+			t.Errorf("constant %s: got errors %q, want %q", gold.expr, errs, gold.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	var many strings.Builder
+	for i := 0; i < 129; i++ {
+		fmt.Fprintf(&many, "\tf%d bool\n", i)
+	}
+
+	schema := `package gen
+
+type a struct {
+	x nope
+	y int32
+	y int64
+	z []timestamp
+	w []map[text]text
+	v [][]text
+	u []p
+}
+
+type p interface {
+	a
+}
+
+type a struct {
+	x bool
+}
+
+type b struct {
+` + many.String() + `}
+`
+	want := []string{
+		`test.colf:4:2: unknown datatype "nope" for field gen.a.x`,
+		`test.colf:6:2: duplicate field name gen.a.y; first at test.colf:5:2`,
+		`test.colf:7:2: unsupported lists type "timestamp" for field gen.a.z`,
+		`test.colf:8:2: unsupported map nesting for field gen.a.w`,
+		`test.colf:9:2: unsupported list nesting for field gen.a.v`,
+		`test.colf:10:2: unsupported union nesting for field gen.a.u`,
+		`test.colf:17:6: duplicate struct definition "gen.a"; first at test.colf:3:6`,
+		`test.colf:149:2: index 127 of field gen.b.f127 exceeds 126`,
+		`test.colf:150:2: index 128 of field gen.b.f128 exceeds 126`,
+	}
+	verifyErrors(t, schemaErrors(t, schema, nil), want)
+}